@@ -0,0 +1,66 @@
+// Package analysis defines the backend intermap's tools run code analysis
+// commands on, and provides the Python sidecar implementation.
+//
+// This package is part of intermap's public Go API and follows semantic
+// versioning: exported identifiers are not removed or changed incompatibly
+// within a major version. The commands a backend accepts, and the shape of
+// their results, are those documented for the Python module.
+package analysis
+
+import (
+	"context"
+
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+)
+
+// Backend runs an analysis command against a project directory and returns
+// its JSON result. Implementations must be safe for concurrent use.
+type Backend interface {
+	Run(ctx context.Context, command, project string, args map[string]any) (map[string]any, error)
+}
+
+// Python is a Backend that runs the intermap Python module in a persistent
+// sidecar process, falling back to one process per command when the
+// sidecar keeps crashing.
+type Python = pybridge.Bridge
+
+var _ Backend = (*Python)(nil)
+
+// NewPython returns a Python backend that imports the intermap package from
+// pythonDir. An empty pythonDir uses DefaultPythonDir.
+func NewPython(pythonDir string) *Python {
+	if pythonDir == "" {
+		pythonDir = DefaultPythonDir()
+	}
+	return pybridge.NewBridge(pythonDir)
+}
+
+// DefaultPythonDir returns the python/ directory of the plugin:
+// $CLAUDE_PLUGIN_ROOT/python, or python/ next to the binary's bin/ directory.
+func DefaultPythonDir() string {
+	return pybridge.DefaultPythonPath()
+}
+
+// Priority is the scheduling class of a command when several are waiting
+// for the same Python sidecar.
+type Priority = pybridge.Priority
+
+const (
+	Background  = pybridge.Background
+	Normal      = pybridge.Normal
+	Interactive = pybridge.Interactive
+)
+
+// WithPriority returns ctx carrying p for the Python backend to schedule by.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return pybridge.WithPriority(ctx, p)
+}
+
+// RecoverableError is a command failure the Python backend survived, such
+// as a parse error, as opposed to a crash or timeout.
+type RecoverableError = pybridge.RecoverableError
+
+// IsRecoverable reports whether err is a RecoverableError.
+func IsRecoverable(err error) bool {
+	return pybridge.IsRecoverable(err)
+}
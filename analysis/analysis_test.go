@@ -0,0 +1,24 @@
+package analysis_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/internal/python/fakesidecar"
+)
+
+func TestBackend_Python(t *testing.T) {
+	fake := fakesidecar.New(t, fakesidecar.Script{
+		"structure": {{Result: map[string]any{"files": []any{}}}},
+	})
+	var b analysis.Backend = fake.Bridge
+	ctx := analysis.WithPriority(context.Background(), analysis.Interactive)
+	result, err := b.Run(ctx, "structure", t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result["files"]; !ok {
+		t.Errorf("result = %v, want files", result)
+	}
+}
@@ -0,0 +1,442 @@
+// Package client is a typed HTTP client for the intermute coordination
+// service.
+//
+// This package is part of intermap's public Go API and follows semantic
+// versioning: exported identifiers are not removed or changed incompatibly
+// within a major version.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mistakeknot/intermap/internal/cache"
+)
+
+// Agent represents an agent registered with intermute.
+type Agent struct {
+	AgentID   string `json:"agent_id"`
+	Name      string `json:"name"`
+	Project   string `json:"project"`
+	Status    string `json:"status"`
+	SessionID string `json:"session_id,omitempty"`
+	LastSeen  string `json:"last_seen,omitempty"`
+	// Host, Capabilities, and Metadata are set by agents that advertise
+	// themselves as services, such as intermap.
+	Host         string            `json:"host,omitempty"`
+	Capabilities []string          `json:"capabilities,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+// Reservation represents a file reservation.
+type Reservation struct {
+	ID        string `json:"id"`
+	AgentID   string `json:"agent_id"`
+	Pattern   string `json:"pattern"`
+	Reason    string `json:"reason"`
+	Project   string `json:"project"`
+	IsActive  bool   `json:"is_active"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// Task is a unit of work an agent has claimed in intermute.
+type Task struct {
+	ID        string `json:"id"`
+	AgentID   string `json:"agent_id"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+	Project   string `json:"project,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// Message is a message sent to or from an agent.
+type Message struct {
+	ID        string `json:"id"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Subject   string `json:"subject,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Event is an entry in intermute's event log.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	AgentID   string          `json:"agent_id,omitempty"`
+	Project   string          `json:"project,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	CreatedAt string          `json:"created_at"`
+}
+
+// Overlay is intermute's combined snapshot of agents, reservations, tasks,
+// and messages, served by /api/overlay on versions that have it.
+type Overlay struct {
+	Agents       []Agent       `json:"agents"`
+	Reservations []Reservation `json:"reservations"`
+	Tasks        []Task        `json:"tasks,omitempty"`
+	Messages     []Message     `json:"messages,omitempty"`
+}
+
+// Registration describes an agent registering itself with intermute.
+type Registration struct {
+	Name         string            `json:"name"`
+	Project      string            `json:"project,omitempty"`
+	SessionID    string            `json:"session_id,omitempty"`
+	Host         string            `json:"host,omitempty"`
+	Capabilities []string          `json:"capabilities,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+// ReservationRequest asks intermute to reserve files matching Pattern.
+type ReservationRequest struct {
+	AgentID string `json:"agent_id"`
+	Pattern string `json:"pattern"`
+	Reason  string `json:"reason,omitempty"`
+	Project string `json:"project,omitempty"`
+	// TTLSeconds, if positive, expires the reservation.
+	TTLSeconds int `json:"ttl_seconds,omitempty"`
+}
+
+// maxResponseBytes bounds how much of a response body is read.
+const maxResponseBytes = 16 << 20
+
+// DefaultCacheEntries is how many GET responses are kept for conditional
+// requests. Cached validators expire after cacheTTL, after which the next
+// request is unconditional.
+const (
+	DefaultCacheEntries = 32
+	cacheTTL            = time.Hour
+)
+
+// cachedResponse is a GET body with the validators to revalidate it.
+type cachedResponse struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// Client wraps the intermute HTTP API.
+//
+// Responses may be bare JSON or wrapped in an envelope ({"data": ...} on
+// success, {"error": {"code", "message"}} on failure). GET responses that
+// carry an ETag or Last-Modified header are cached and revalidated with
+// If-None-Match / If-Modified-Since, so an unchanged list costs intermute
+// a 304 instead of a full encode. Failed requests
+// return an *APIError or a wrapped transport error, classified by
+// ErrNotFound, ErrUnauthorized, ErrUnavailable, and ErrNotSupported.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+	retry   RetryPolicy
+	cache   *cache.Cache[cachedResponse] // nil disables conditional requests
+	// noOverlay remembers that /api/overlay is missing, so callers fall
+	// back to separate requests without probing it every time.
+	noOverlay atomic.Bool
+}
+
+// Option configures the client.
+type Option func(*Client)
+
+// NewClient creates a new intermute client.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		http: &http.Client{
+			Timeout:   DefaultTimeout,
+			Transport: newTransport(DefaultTransportOptions),
+		},
+		retry: DefaultRetryPolicy,
+		cache: cache.New[cachedResponse](cacheTTL, DefaultCacheEntries),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithBaseURL sets the base URL for the intermute API.
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithToken sends token as a bearer token on every request.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithCache keeps up to entries GET responses for conditional requests;
+// 0 disables the cache.
+func WithCache(entries int) Option {
+	return func(c *Client) {
+		if entries <= 0 {
+			c.cache = nil
+			return
+		}
+		c.cache = cache.New[cachedResponse](cacheTTL, entries)
+	}
+}
+
+// Available returns true if the client has a configured URL.
+func (c *Client) Available() bool {
+	return c.baseURL != ""
+}
+
+// ListAgents returns all active agents.
+func (c *Client) ListAgents(ctx context.Context) ([]Agent, error) {
+	if !c.Available() {
+		return nil, nil
+	}
+	var agents []Agent
+	if err := c.do(ctx, "list agents", http.MethodGet, "/api/agents", nil, nil, &agents); err != nil {
+		return nil, err
+	}
+	return agents, nil
+}
+
+// GetAgent returns one agent. An unknown ID yields ErrNotFound.
+func (c *Client) GetAgent(ctx context.Context, id string) (Agent, error) {
+	var agent Agent
+	err := c.do(ctx, "get agent", http.MethodGet, "/api/agents/"+url.PathEscape(id), nil, nil, &agent)
+	return agent, err
+}
+
+// RegisterAgent registers a new agent and returns it with its assigned ID.
+func (c *Client) RegisterAgent(ctx context.Context, reg Registration) (Agent, error) {
+	var agent Agent
+	err := c.do(ctx, "register agent", http.MethodPost, "/api/agents", nil, reg, &agent)
+	return agent, err
+}
+
+// Heartbeat marks an agent as still alive. An agent intermute has
+// forgotten yields ErrNotFound, and should register again.
+func (c *Client) Heartbeat(ctx context.Context, agentID string) error {
+	return c.do(ctx, "heartbeat", http.MethodPost, "/api/agents/"+url.PathEscape(agentID)+"/heartbeat", nil, nil, nil)
+}
+
+// DeregisterAgent removes an agent.
+func (c *Client) DeregisterAgent(ctx context.Context, agentID string) error {
+	return c.do(ctx, "deregister agent", http.MethodDelete, "/api/agents/"+url.PathEscape(agentID), nil, nil, nil)
+}
+
+// GetOverlay fetches agents, reservations, tasks, and messages in one
+// request. It returns ErrNotSupported if intermute has no overlay endpoint;
+// that answer is remembered for the life of the client.
+func (c *Client) GetOverlay(ctx context.Context) (Overlay, error) {
+	if c.noOverlay.Load() {
+		return Overlay{}, ErrNotSupported
+	}
+	var overlay Overlay
+	err := c.do(ctx, "get overlay", http.MethodGet, "/api/overlay", nil, nil, &overlay)
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrNotSupported) {
+		c.noOverlay.Store(true)
+		return Overlay{}, ErrNotSupported
+	}
+	return overlay, err
+}
+
+// ListReservations returns all reservations, optionally filtered by project.
+func (c *Client) ListReservations(ctx context.Context, project string) ([]Reservation, error) {
+	if !c.Available() {
+		return nil, nil
+	}
+	query := url.Values{}
+	if project != "" {
+		query.Set("project", project)
+	}
+	var reservations []Reservation
+	if err := c.do(ctx, "list reservations", http.MethodGet, "/api/reservations", query, nil, &reservations); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// CreateReservation reserves files for an agent.
+func (c *Client) CreateReservation(ctx context.Context, r ReservationRequest) (Reservation, error) {
+	var reservation Reservation
+	err := c.do(ctx, "create reservation", http.MethodPost, "/api/reservations", nil, r, &reservation)
+	return reservation, err
+}
+
+// ReleaseReservation deletes a reservation. It returns ErrNotSupported if
+// intermute does not allow releasing reservations over HTTP.
+func (c *Client) ReleaseReservation(ctx context.Context, id string) error {
+	if !c.Available() {
+		return ErrNotSupported
+	}
+	return c.do(ctx, "release reservation "+id, http.MethodDelete, "/api/reservations/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// ListTasks returns tasks, optionally filtered by agent. An intermute that
+// does not expose tasks (HTTP 404) yields no tasks and no error.
+func (c *Client) ListTasks(ctx context.Context, agentID string) ([]Task, error) {
+	var tasks []Task
+	if err := c.getOptional(ctx, "/api/tasks", "agent_id", agentID, "tasks", &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// ListMessages returns messages, optionally filtered to those sent to or
+// from agent. An intermute that does not expose messages (HTTP 404) yields
+// no messages and no error.
+func (c *Client) ListMessages(ctx context.Context, agentID string) ([]Message, error) {
+	var messages []Message
+	if err := c.getOptional(ctx, "/api/messages", "agent_id", agentID, "messages", &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// ListEvents returns events newer than the event ID since, or all retained
+// events if since is empty. An intermute that does not expose events
+// (HTTP 404) yields no events and no error.
+func (c *Client) ListEvents(ctx context.Context, since string) ([]Event, error) {
+	var events []Event
+	if err := c.getOptional(ctx, "/api/events", "since", since, "events", &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// getOptional decodes GET path[?key=value] into out, treating 404 as an
+// endpoint this intermute does not have.
+func (c *Client) getOptional(ctx context.Context, path, key, value, what string, out any) error {
+	if !c.Available() {
+		return nil
+	}
+	query := url.Values{}
+	if value != "" {
+		query.Set(key, value)
+	}
+	err := c.do(ctx, "list "+what, http.MethodGet, path, query, nil, out)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// do sends one request and decodes the (possibly enveloped) response into
+// out, which may be nil.
+func (c *Client) do(ctx context.Context, op, method, path string, query url.Values, body, out any) error {
+	if !c.Available() {
+		return fmt.Errorf("%s: %w", op, errNotConfigured)
+	}
+
+	reqURL := c.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	var reqBody []byte
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("%s: encode request: %w", op, err)
+		}
+		reqBody = data
+	}
+
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	if body != nil {
+		header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+	// Cached entries are keyed by URL and validated against the token, so
+	// a response is never replayed to different credentials.
+	var cached cachedResponse
+	conditional := false
+	if method == http.MethodGet && c.cache != nil {
+		if cached, conditional = c.cache.Get(reqURL, c.token); conditional {
+			if cached.etag != "" {
+				header.Set("If-None-Match", cached.etag)
+			}
+			if cached.lastModified != "" {
+				header.Set("If-Modified-Since", cached.lastModified)
+			}
+		}
+	}
+
+	resp, data, err := c.send(ctx, method, reqURL, header, reqBody)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s: %w", op, ctx.Err())
+		}
+		return fmt.Errorf("%s: %w: %w", op, ErrUnavailable, err)
+	}
+	status := resp.StatusCode
+	switch {
+	case status == http.StatusNotModified && conditional:
+		data, status = cached.body, http.StatusOK
+	case status == http.StatusOK && method == http.MethodGet && c.cache != nil:
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			c.cache.Put(reqURL, c.token, cachedResponse{etag: etag, lastModified: lastModified, body: data})
+		}
+	}
+	payload, apiErr := unwrapEnvelope(data)
+	if status < 200 || status > 299 {
+		if apiErr == nil {
+			apiErr = &APIError{}
+		}
+		apiErr.Op, apiErr.StatusCode = op, status
+		return apiErr
+	}
+	if apiErr != nil {
+		// A 2xx carrying an error envelope is still a failure.
+		apiErr.Op, apiErr.StatusCode = op, status
+		return apiErr
+	}
+	if out == nil || len(bytes.TrimSpace(payload)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return fmt.Errorf("%s: decode response: %w", op, err)
+	}
+	return nil
+}
+
+// unwrapEnvelope returns the payload of a response body and the error its
+// envelope reports, if any. Bodies that are not envelopes are returned
+// unchanged.
+func unwrapEnvelope(data []byte) (json.RawMessage, *APIError) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return data, nil
+	}
+	var env map[string]json.RawMessage
+	if json.Unmarshal(trimmed, &env) != nil {
+		return data, nil
+	}
+	if raw, ok := env["error"]; ok && string(raw) != "null" {
+		var detail struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		var msg string
+		if json.Unmarshal(raw, &msg) == nil {
+			return nil, &APIError{Message: msg}
+		}
+		if json.Unmarshal(raw, &detail) == nil {
+			return nil, &APIError{Code: detail.Code, Message: detail.Message}
+		}
+		return nil, &APIError{Message: string(raw)}
+	}
+	if payload, ok := env["data"]; ok {
+		return payload, nil
+	}
+	return data, nil
+}
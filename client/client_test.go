@@ -0,0 +1,330 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListAgents(t *testing.T) {
+	agents := []Agent{
+		{AgentID: "a1", Name: "builder", Project: "interlock", Status: "active"},
+		{AgentID: "a2", Name: "reviewer", Project: "clavain", Status: "active"},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/agents" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(agents)
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	got, err := c.ListAgents(context.Background())
+	if err != nil {
+		t.Fatalf("ListAgents: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 agents, got %d", len(got))
+	}
+	if got[0].Name != "builder" {
+		t.Errorf("expected builder, got %s", got[0].Name)
+	}
+}
+
+func TestListReservations(t *testing.T) {
+	reservations := []Reservation{
+		{ID: "r1", AgentID: "a1", Pattern: "internal/tools/*.go", Project: "interlock", IsActive: true},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/reservations" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(reservations)
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	got, err := c.ListReservations(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListReservations: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 reservation, got %d", len(got))
+	}
+	if got[0].Pattern != "internal/tools/*.go" {
+		t.Errorf("expected internal/tools/*.go, got %s", got[0].Pattern)
+	}
+}
+
+func TestClient_Unavailable(t *testing.T) {
+	c := NewClient() // no base URL
+
+	agents, err := c.ListAgents(context.Background())
+	if err != nil {
+		t.Fatalf("expected nil error for unavailable client, got %v", err)
+	}
+	if agents != nil {
+		t.Errorf("expected nil agents for unavailable client")
+	}
+}
+
+func TestClient_ServerDown(t *testing.T) {
+	c := NewClient(WithBaseURL("http://127.0.0.1:1")) // port that's definitely not listening
+
+	_, err := c.ListAgents(context.Background())
+	if err == nil {
+		t.Error("expected error for unreachable server")
+	}
+}
+
+func TestListReservations_WithProject(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/reservations" {
+			http.NotFound(w, r)
+			return
+		}
+		project := r.URL.Query().Get("project")
+		if project != "interlock" {
+			json.NewEncoder(w).Encode([]Reservation{})
+			return
+		}
+		json.NewEncoder(w).Encode([]Reservation{
+			{ID: "r1", AgentID: "a1", Pattern: "*.go", Project: "interlock", IsActive: true},
+		})
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	got, err := c.ListReservations(context.Background(), "interlock")
+	if err != nil {
+		t.Fatalf("ListReservations: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 reservation, got %d", len(got))
+	}
+}
+
+func TestListAgents_HTTPError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	_, err := c.ListAgents(context.Background())
+	if err == nil {
+		t.Error("expected error for HTTP 500")
+	}
+}
+
+func TestListTasksAndMessages(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tasks":
+			if r.URL.Query().Get("agent_id") != "a1" {
+				json.NewEncoder(w).Encode([]Task{})
+				return
+			}
+			json.NewEncoder(w).Encode([]Task{{ID: "t1", AgentID: "a1", Title: "Fix scan", Status: "in_progress"}})
+		case "/api/messages":
+			json.NewEncoder(w).Encode([]Message{{ID: "m1", From: "a1", To: "a2", CreatedAt: "2026-01-02T03:04:05Z"}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	tasks, err := c.ListTasks(context.Background(), "a1")
+	if err != nil || len(tasks) != 1 || tasks[0].Title != "Fix scan" {
+		t.Fatalf("ListTasks = %+v, %v", tasks, err)
+	}
+	messages, err := c.ListMessages(context.Background(), "")
+	if err != nil || len(messages) != 1 || messages[0].From != "a1" {
+		t.Fatalf("ListMessages = %+v, %v", messages, err)
+	}
+}
+
+func TestListTasks_NotExposed(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	tasks, err := NewClient(WithBaseURL(ts.URL)).ListTasks(context.Background(), "")
+	if err != nil || tasks != nil {
+		t.Errorf("ListTasks on missing endpoint = %+v, %v; want nil, nil", tasks, err)
+	}
+}
+
+func TestReleaseReservation(t *testing.T) {
+	var deleted string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		deleted = r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	if err := NewClient(WithBaseURL(ts.URL)).ReleaseReservation(context.Background(), "r1"); err != nil {
+		t.Fatalf("ReleaseReservation: %v", err)
+	}
+	if deleted != "/api/reservations/r1" {
+		t.Errorf("deleted %q", deleted)
+	}
+	if err := NewClient().ReleaseReservation(context.Background(), "r1"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("unavailable client: got %v, want ErrNotSupported", err)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/agents":
+			w.Write([]byte(`{"data":[{"agent_id":"a1","name":"builder"}]}`))
+		case "/api/reservations":
+			w.Write([]byte(`{"error":{"code":"db_locked","message":"try again"}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"bad agent id"}`))
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	agents, err := c.ListAgents(context.Background())
+	if err != nil || len(agents) != 1 || agents[0].AgentID != "a1" {
+		t.Fatalf("ListAgents = %+v, %v", agents, err)
+	}
+
+	_, err = c.ListReservations(context.Background(), "")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "db_locked" || apiErr.Message != "try again" {
+		t.Errorf("2xx error envelope: %v", err)
+	}
+	_, err = c.GetAgent(context.Background(), "x")
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "bad agent id" {
+		t.Errorf("string error envelope: %v", err)
+	}
+	if err.Error() != "get agent: HTTP 400: bad agent id" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func TestErrorClasses(t *testing.T) {
+	for _, tt := range []struct {
+		status int
+		want   error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusServiceUnavailable, ErrUnavailable},
+		{http.StatusNotImplemented, ErrNotSupported},
+	} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+		}))
+		_, err := NewClient(WithBaseURL(ts.URL), WithRetryPolicy(RetryPolicy{})).GetAgent(context.Background(), "a1")
+		ts.Close()
+		if !errors.Is(err, tt.want) {
+			t.Errorf("HTTP %d: got %v, want %v", tt.status, err, tt.want)
+		}
+	}
+
+	if _, err := NewClient(WithBaseURL("http://127.0.0.1:1")).ListAgents(context.Background()); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("connection refused: got %v, want ErrUnavailable", err)
+	}
+	if err := NewClient().Heartbeat(context.Background(), "a1"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("unconfigured heartbeat: got %v, want ErrUnavailable", err)
+	}
+}
+
+func TestRegisterAndHeartbeat(t *testing.T) {
+	var reg Registration
+	var heartbeats []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/agents":
+			json.NewDecoder(r.Body).Decode(&reg)
+			json.NewEncoder(w).Encode(Agent{AgentID: "a9", Name: reg.Name})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/agents/a9/heartbeat":
+			heartbeats = append(heartbeats, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL+"/"), WithToken("secret"))
+	agent, err := c.RegisterAgent(context.Background(), Registration{Name: "intermap", Capabilities: []string{"agent_map"}})
+	if err != nil || agent.AgentID != "a9" {
+		t.Fatalf("RegisterAgent = %+v, %v", agent, err)
+	}
+	if reg.Name != "intermap" || len(reg.Capabilities) != 1 {
+		t.Errorf("registration body = %+v", reg)
+	}
+	if err := c.Heartbeat(context.Background(), "a9"); err != nil || len(heartbeats) != 1 {
+		t.Errorf("Heartbeat: %v (%d)", err, len(heartbeats))
+	}
+	if err := c.Heartbeat(context.Background(), "gone"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Heartbeat for unknown agent: got %v, want ErrNotFound", err)
+	}
+	if _, err := NewClient(WithBaseURL(ts.URL)).ListAgents(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("missing token: got %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	var full, notModified int
+	etag := `"v1"`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", etag)
+		json.NewEncoder(w).Encode([]Agent{{AgentID: "a1", Name: etag}})
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL))
+	for i := 0; i < 3; i++ {
+		agents, err := c.ListAgents(context.Background())
+		if err != nil || len(agents) != 1 || agents[0].Name != `"v1"` {
+			t.Fatalf("ListAgents #%d = %+v, %v", i, agents, err)
+		}
+	}
+	if full != 1 || notModified != 2 {
+		t.Errorf("full=%d notModified=%d, want 1 and 2", full, notModified)
+	}
+
+	etag = `"v2"`
+	agents, _ := c.ListAgents(context.Background())
+	if len(agents) != 1 || agents[0].Name != `"v2"` || full != 2 {
+		t.Errorf("changed list = %+v (full=%d)", agents, full)
+	}
+
+	uncached := NewClient(WithBaseURL(ts.URL), WithCache(0))
+	uncached.ListAgents(context.Background())
+	uncached.ListAgents(context.Background())
+	if full != 4 {
+		t.Errorf("WithCache(0) should not send conditional requests (full=%d)", full)
+	}
+}
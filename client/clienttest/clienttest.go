@@ -0,0 +1,531 @@
+// Package clienttest provides an in-memory intermute server for tests.
+//
+// A Server serves the HTTP API the client package uses (agents,
+// reservations, tasks, messages, events, and the overlay) from state the
+// test controls, with optional latency, injected failures, and a request
+// log, so agent_map and friends can be exercised deterministically:
+//
+//	srv := clienttest.NewServer(t,
+//		clienttest.WithAgents(client.Agent{AgentID: "a1", Name: "builder", Project: "intermap"}),
+//	)
+//	srv.Fail("GET /api/reservations", clienttest.Fault{Status: http.StatusServiceUnavailable, Times: 1})
+//	c := srv.Client()
+package clienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermap/client"
+)
+
+// Routes served, in http.ServeMux pattern syntax. Fault keys use these.
+const (
+	RouteListAgents         = "GET /api/agents"
+	RouteGetAgent           = "GET /api/agents/{id}"
+	RouteRegisterAgent      = "POST /api/agents"
+	RouteHeartbeat          = "POST /api/agents/{id}/heartbeat"
+	RouteDeregisterAgent    = "DELETE /api/agents/{id}"
+	RouteOverlay            = "GET /api/overlay"
+	RouteListReservations   = "GET /api/reservations"
+	RouteCreateReservation  = "POST /api/reservations"
+	RouteReleaseReservation = "DELETE /api/reservations/{id}"
+	RouteListTasks          = "GET /api/tasks"
+	RouteListMessages       = "GET /api/messages"
+	RouteListEvents         = "GET /api/events"
+)
+
+// Fault is an injected failure for one route.
+type Fault struct {
+	// Status is the HTTP status to answer with; default 500.
+	Status int
+	// Code and Message fill the {"error": ...} envelope when set.
+	Code    string
+	Message string
+	// Drop closes the connection without a response, a transport error.
+	Drop bool
+	// Times is how many requests fail before the route recovers; 0 fails
+	// until Clear.
+	Times int
+}
+
+// Request is a request the server received.
+type Request struct {
+	Route  string // the matched route, e.g. RouteListAgents
+	Method string
+	Path   string
+	Query  string
+	Body   []byte
+}
+
+// Server is a fake intermute. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	agents       []client.Agent
+	reservations []client.Reservation
+	tasks        []client.Task
+	messages     []client.Message
+	events       []client.Event
+	faults       map[string]*Fault
+	latency      time.Duration
+	token        string
+	envelope     bool
+	noOverlay    bool
+	nextID       int
+	requests     []Request
+	now          func() time.Time
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithAgents seeds the registered agents.
+func WithAgents(agents ...client.Agent) Option {
+	return func(s *Server) { s.agents = append(s.agents, agents...) }
+}
+
+// WithReservations seeds the reservations.
+func WithReservations(rs ...client.Reservation) Option {
+	return func(s *Server) { s.reservations = append(s.reservations, rs...) }
+}
+
+// WithTasks seeds the tasks.
+func WithTasks(tasks ...client.Task) Option {
+	return func(s *Server) { s.tasks = append(s.tasks, tasks...) }
+}
+
+// WithMessages seeds the messages.
+func WithMessages(msgs ...client.Message) Option {
+	return func(s *Server) { s.messages = append(s.messages, msgs...) }
+}
+
+// WithEvents seeds the event log.
+func WithEvents(events ...client.Event) Option {
+	return func(s *Server) { s.events = append(s.events, events...) }
+}
+
+// WithLatency delays every response by d.
+func WithLatency(d time.Duration) Option {
+	return func(s *Server) { s.latency = d }
+}
+
+// WithToken requires "Authorization: Bearer token", answering 401
+// otherwise.
+func WithToken(token string) Option {
+	return func(s *Server) { s.token = token }
+}
+
+// WithEnvelope wraps successful responses in {"data": ...}, as some
+// intermute versions do.
+func WithEnvelope() Option {
+	return func(s *Server) { s.envelope = true }
+}
+
+// WithoutOverlay serves 404 for /api/overlay, like intermute versions that
+// predate it.
+func WithoutOverlay() Option {
+	return func(s *Server) { s.noOverlay = true }
+}
+
+// WithClock sets the time used for LastSeen and CreatedAt stamps; default
+// time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) { s.now = now }
+}
+
+// NewServer starts a fake intermute that is closed when t ends.
+func NewServer(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+	s := &Server{faults: map[string]*Fault{}, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	mux := http.NewServeMux()
+	for route, h := range map[string]func(*http.Request) (any, int){
+		RouteListAgents:         s.listAgents,
+		RouteGetAgent:           s.getAgent,
+		RouteRegisterAgent:      s.registerAgent,
+		RouteHeartbeat:          s.heartbeat,
+		RouteDeregisterAgent:    s.deregisterAgent,
+		RouteOverlay:            s.overlay,
+		RouteListReservations:   s.listReservations,
+		RouteCreateReservation:  s.createReservation,
+		RouteReleaseReservation: s.releaseReservation,
+		RouteListTasks:          s.listTasks,
+		RouteListMessages:       s.listMessages,
+		RouteListEvents:         s.listEvents,
+	} {
+		mux.HandleFunc(route, s.handle(route, h))
+	}
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Client returns a client for the server; opts are applied after the base
+// URL and the server's token.
+func (s *Server) Client(opts ...client.Option) *client.Client {
+	base := []client.Option{client.WithBaseURL(s.URL)}
+	if s.token != "" {
+		base = append(base, client.WithToken(s.token))
+	}
+	return client.NewClient(append(base, opts...)...)
+}
+
+// SetAgents replaces the registered agents.
+func (s *Server) SetAgents(agents ...client.Agent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.agents = slices.Clone(agents)
+}
+
+// Agents returns the registered agents.
+func (s *Server) Agents() []client.Agent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.agents)
+}
+
+// SetReservations replaces the reservations.
+func (s *Server) SetReservations(rs ...client.Reservation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reservations = slices.Clone(rs)
+}
+
+// Reservations returns the reservations.
+func (s *Server) Reservations() []client.Reservation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.reservations)
+}
+
+// SetTasks replaces the tasks.
+func (s *Server) SetTasks(tasks ...client.Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks = slices.Clone(tasks)
+}
+
+// SetMessages replaces the messages.
+func (s *Server) SetMessages(msgs ...client.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = slices.Clone(msgs)
+}
+
+// AddEvents appends to the event log.
+func (s *Server) AddEvents(events ...client.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, events...)
+}
+
+// SetLatency delays every later response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Fail makes route answer with f until it has failed f.Times times, or
+// until Clear.
+func (s *Server) Fail(route string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[route] = &f
+}
+
+// Clear removes the faults on routes, or on every route if none are given.
+func (s *Server) Clear(routes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(routes) == 0 {
+		clear(s.faults)
+	}
+	for _, r := range routes {
+		delete(s.faults, r)
+	}
+}
+
+// Requests returns the requests received so far, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// handle wraps a route handler with the request log, latency, auth, and
+// faults, and encodes its result.
+func (s *Server) handle(route string, h func(*http.Request) (any, int)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Route: route, Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: body})
+		latency := s.latency
+		var fault *Fault
+		if f, ok := s.faults[route]; ok {
+			copied := *f
+			fault = &copied
+			if f.Times > 0 {
+				if f.Times--; f.Times == 0 {
+					delete(s.faults, route)
+				}
+			}
+		}
+		token, envelope := s.token, s.envelope
+		s.mu.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			writeError(w, http.StatusUnauthorized, "unauthorized", "missing or invalid token")
+			return
+		}
+		if fault != nil {
+			if fault.Drop {
+				if hj, ok := w.(http.Hijacker); ok {
+					if conn, _, err := hj.Hijack(); err == nil {
+						conn.Close()
+						return
+					}
+				}
+			}
+			status := fault.Status
+			if status == 0 {
+				status = http.StatusInternalServerError
+			}
+			writeError(w, status, fault.Code, fault.Message)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		v, status := h(r)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status >= 300 {
+			json.NewEncoder(w).Encode(v)
+			return
+		}
+		if v == nil {
+			return
+		}
+		if envelope {
+			v = map[string]any{"data": v}
+		}
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if code != "" || message != "" {
+		json.NewEncoder(w).Encode(map[string]any{"error": map[string]string{"code": code, "message": message}})
+	}
+}
+
+// notFound is the body of a 404 from a route handler.
+func notFound(what, id string) (any, int) {
+	return map[string]any{"error": map[string]string{"code": "not_found", "message": what + " " + id + " not found"}}, http.StatusNotFound
+}
+
+func (s *Server) id(prefix string) string {
+	s.nextID++
+	return prefix + "-" + strconv.Itoa(s.nextID)
+}
+
+func (s *Server) stamp() string {
+	return s.now().UTC().Format(time.RFC3339)
+}
+
+func (s *Server) listAgents(r *http.Request) (any, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return nonNil(s.agents), http.StatusOK
+}
+
+func (s *Server) getAgent(r *http.Request) (any, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := r.PathValue("id")
+	for _, a := range s.agents {
+		if a.AgentID == id {
+			return a, http.StatusOK
+		}
+	}
+	return notFound("agent", id)
+}
+
+func (s *Server) registerAgent(r *http.Request) (any, int) {
+	var reg client.Registration
+	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+		return map[string]any{"error": err.Error()}, http.StatusBadRequest
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := client.Agent{
+		AgentID:      s.id("agent"),
+		Name:         reg.Name,
+		Project:      reg.Project,
+		Status:       "active",
+		SessionID:    reg.SessionID,
+		LastSeen:     s.stamp(),
+		Host:         reg.Host,
+		Capabilities: reg.Capabilities,
+		Metadata:     reg.Metadata,
+	}
+	s.agents = append(s.agents, a)
+	return a, http.StatusOK
+}
+
+func (s *Server) heartbeat(r *http.Request) (any, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := r.PathValue("id")
+	for i := range s.agents {
+		if s.agents[i].AgentID == id {
+			s.agents[i].LastSeen = s.stamp()
+			return nil, http.StatusOK
+		}
+	}
+	return notFound("agent", id)
+}
+
+func (s *Server) deregisterAgent(r *http.Request) (any, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := r.PathValue("id")
+	for i := range s.agents {
+		if s.agents[i].AgentID == id {
+			s.agents = slices.Delete(s.agents, i, i+1)
+			return nil, http.StatusOK
+		}
+	}
+	return notFound("agent", id)
+}
+
+func (s *Server) overlay(r *http.Request) (any, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.noOverlay {
+		return notFound("endpoint", r.URL.Path)
+	}
+	return client.Overlay{
+		Agents:       nonNil(s.agents),
+		Reservations: nonNil(s.reservations),
+		Tasks:        s.tasks,
+		Messages:     s.messages,
+	}, http.StatusOK
+}
+
+func (s *Server) listReservations(r *http.Request) (any, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	project := r.URL.Query().Get("project")
+	out := []client.Reservation{}
+	for _, res := range s.reservations {
+		if project == "" || res.Project == project {
+			out = append(out, res)
+		}
+	}
+	return out, http.StatusOK
+}
+
+func (s *Server) createReservation(r *http.Request) (any, int) {
+	var req client.ReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return map[string]any{"error": err.Error()}, http.StatusBadRequest
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := client.Reservation{
+		ID:        s.id("res"),
+		AgentID:   req.AgentID,
+		Pattern:   req.Pattern,
+		Reason:    req.Reason,
+		Project:   req.Project,
+		IsActive:  true,
+		CreatedAt: s.stamp(),
+	}
+	s.reservations = append(s.reservations, res)
+	return res, http.StatusOK
+}
+
+func (s *Server) releaseReservation(r *http.Request) (any, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := r.PathValue("id")
+	for i := range s.reservations {
+		if s.reservations[i].ID == id {
+			s.reservations = slices.Delete(s.reservations, i, i+1)
+			return nil, http.StatusOK
+		}
+	}
+	return notFound("reservation", id)
+}
+
+func (s *Server) listTasks(r *http.Request) (any, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	agent := r.URL.Query().Get("agent_id")
+	out := []client.Task{}
+	for _, t := range s.tasks {
+		if agent == "" || t.AgentID == agent {
+			out = append(out, t)
+		}
+	}
+	return out, http.StatusOK
+}
+
+func (s *Server) listMessages(r *http.Request) (any, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	agent := r.URL.Query().Get("agent_id")
+	out := []client.Message{}
+	for _, m := range s.messages {
+		if agent == "" || m.From == agent || m.To == agent {
+			out = append(out, m)
+		}
+	}
+	return out, http.StatusOK
+}
+
+func (s *Server) listEvents(r *http.Request) (any, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	since := r.URL.Query().Get("since")
+	start := 0
+	if since != "" {
+		for i, e := range s.events {
+			if e.ID == since {
+				start = i + 1
+				break
+			}
+		}
+	}
+	return nonNil(s.events[start:]), http.StatusOK
+}
+
+// nonNil keeps empty lists encoding as [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return slices.Clone(s)
+}
@@ -0,0 +1,96 @@
+package clienttest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermap/client"
+	"github.com/mistakeknot/intermap/client/clienttest"
+)
+
+func TestServer_Lifecycle(t *testing.T) {
+	clock := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := clienttest.NewServer(t, clienttest.WithToken("secret"), clienttest.WithEnvelope(),
+		clienttest.WithClock(func() time.Time { return clock }))
+	c := srv.Client()
+	ctx := context.Background()
+
+	agent, err := c.RegisterAgent(ctx, client.Registration{Name: "builder", Project: "intermap"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agent.AgentID == "" || agent.Status != "active" || agent.LastSeen != "2026-01-02T03:04:05Z" {
+		t.Errorf("agent = %+v", agent)
+	}
+	res, err := c.CreateReservation(ctx, client.ReservationRequest{AgentID: agent.AgentID, Pattern: "internal/**", Project: "intermap"})
+	if err != nil || !res.IsActive {
+		t.Fatalf("reservation = %+v, err = %v", res, err)
+	}
+	if got, _ := c.ListReservations(ctx, "other"); len(got) != 0 {
+		t.Errorf("project filter: %+v", got)
+	}
+	overlay, err := c.GetOverlay(ctx)
+	if err != nil || len(overlay.Agents) != 1 || len(overlay.Reservations) != 1 {
+		t.Errorf("overlay = %+v, err = %v", overlay, err)
+	}
+	if err := c.ReleaseReservation(ctx, res.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeregisterAgent(ctx, agent.AgentID); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Heartbeat(ctx, agent.AgentID); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("heartbeat after deregister: %v", err)
+	}
+
+	anon := client.NewClient(client.WithBaseURL(srv.URL))
+	if _, err := anon.ListAgents(ctx); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("without token: %v", err)
+	}
+}
+
+func TestServer_Faults(t *testing.T) {
+	srv := clienttest.NewServer(t,
+		clienttest.WithAgents(client.Agent{AgentID: "a1", Name: "builder"}),
+		clienttest.WithoutOverlay(),
+	)
+	noRetry := srv.Client(client.WithRetryPolicy(client.RetryPolicy{}))
+	ctx := context.Background()
+
+	srv.Fail(clienttest.RouteListAgents, clienttest.Fault{Status: http.StatusServiceUnavailable, Times: 1})
+	if _, err := noRetry.ListAgents(ctx); !errors.Is(err, client.ErrUnavailable) {
+		t.Errorf("injected 503: %v", err)
+	}
+	if agents, err := noRetry.ListAgents(ctx); err != nil || len(agents) != 1 {
+		t.Errorf("after the fault: %v, %v", agents, err)
+	}
+
+	srv.Fail(clienttest.RouteListReservations, clienttest.Fault{Drop: true})
+	if _, err := noRetry.ListReservations(ctx, ""); !errors.Is(err, client.ErrUnavailable) {
+		t.Errorf("dropped connection: %v", err)
+	}
+	srv.Clear()
+
+	if _, err := noRetry.GetOverlay(ctx); !errors.Is(err, client.ErrNotSupported) {
+		t.Errorf("overlay: %v", err)
+	}
+
+	srv.SetLatency(time.Second)
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := noRetry.ListAgents(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("latency: %v", err)
+	}
+
+	var routes []string
+	for _, r := range srv.Requests() {
+		routes = append(routes, r.Route)
+	}
+	// net/http resends a GET once on its own when the connection drops.
+	if len(routes) != 6 || routes[0] != clienttest.RouteListAgents || routes[4] != clienttest.RouteOverlay {
+		t.Errorf("requests = %v", routes)
+	}
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error classes. Every error returned by the client for a failed request
+// matches at most one of these with errors.Is.
+var (
+	// ErrNotFound: the resource or endpoint does not exist (HTTP 404).
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized: credentials are missing or rejected (HTTP 401, 403).
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrUnavailable: intermute is not configured, unreachable, or
+	// temporarily unable to serve (HTTP 429, 502, 503, 504).
+	ErrUnavailable = errors.New("intermute unavailable")
+	// ErrNotSupported: intermute does not offer the operation (HTTP 405, 501).
+	ErrNotSupported = errors.New("not supported by this intermute")
+)
+
+// errNotConfigured is returned by write operations on a client without a
+// base URL.
+var errNotConfigured = fmt.Errorf("%w: INTERMUTE_URL not set", ErrUnavailable)
+
+// APIError is a non-2xx response from intermute.
+type APIError struct {
+	Op         string // e.g. "list agents"
+	StatusCode int
+	// Code and Message come from the response's error envelope, if any.
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s: HTTP %d", e.Op, e.StatusCode)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Unwrap maps the status code to an error class.
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrUnavailable
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return ErrNotSupported
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultTimeout bounds one attempt of a request, including reading the
+// body; large reservation lists need more than a few seconds.
+const DefaultTimeout = 15 * time.Second
+
+// RetryPolicy retries idempotent requests (GET, HEAD, PUT, DELETE) that
+// fail with a transport error or HTTP 429, 502, 503, or 504. Delays grow
+// exponentially from BaseDelay, with jitter, up to MaxDelay; a Retry-After
+// header in seconds is honored up to MaxDelay.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// DefaultRetryPolicy retries twice, starting at 200ms.
+var DefaultRetryPolicy = RetryPolicy{MaxRetries: 2, BaseDelay: 200 * time.Millisecond, MaxDelay: 2 * time.Second}
+
+// TransportOptions tunes connection reuse. Proxies always come from the
+// environment (HTTP_PROXY, HTTPS_PROXY, NO_PROXY).
+type TransportOptions struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DisableKeepAlives   bool
+}
+
+// DefaultTransportOptions keeps enough idle connections to intermute for
+// agent_map's concurrent fetches to reuse them.
+var DefaultTransportOptions = TransportOptions{
+	MaxIdleConns:        32,
+	MaxIdleConnsPerHost: 8,
+	IdleConnTimeout:     90 * time.Second,
+}
+
+// WithTimeout bounds each request attempt; 0 means no timeout, leaving the
+// caller's context in charge.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.http.Timeout = d
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy; a zero policy disables
+// retries.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// WithTransport replaces DefaultTransportOptions.
+func WithTransport(opts TransportOptions) Option {
+	return func(c *Client) {
+		c.http.Transport = newTransport(opts)
+	}
+}
+
+func newTransport(opts TransportOptions) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          opts.MaxIdleConns,
+		MaxIdleConnsPerHost:   opts.MaxIdleConnsPerHost,
+		IdleConnTimeout:       opts.IdleConnTimeout,
+		DisableKeepAlives:     opts.DisableKeepAlives,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// send performs a request, retrying under the client's policy, and returns
+// the final response with its body read (and closed).
+func (c *Client) send(ctx context.Context, method, reqURL string, header http.Header, body []byte) (*http.Response, []byte, error) {
+	retries := 0
+	if idempotent(method) {
+		retries = max(c.retry.MaxRetries, 0)
+	}
+	for attempt := 0; ; attempt++ {
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header = header.Clone()
+
+		resp, err := c.http.Do(req)
+		var data []byte
+		if err == nil {
+			data, err = io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+			resp.Body.Close()
+		}
+		if attempt >= retries || ctx.Err() != nil || !retryable(resp, err) {
+			return resp, data, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, data, err
+		case <-time.After(c.retry.delay(attempt, resp)):
+		}
+	}
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// delay returns the wait before retry attempt+1.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			return p.clamp(time.Duration(secs) * time.Second)
+		}
+	}
+	d := p.clamp(p.BaseDelay << attempt)
+	// Up to 25% jitter spreads retries from concurrent fetches.
+	if d > 0 {
+		d += time.Duration(rand.Int64N(int64(d)/4 + 1))
+	}
+	return d
+}
+
+func (p RetryPolicy) clamp(d time.Duration) time.Duration {
+	if p.MaxDelay > 0 && (d > p.MaxDelay || d < 0) {
+		return p.MaxDelay
+	}
+	return max(d, 0)
+}
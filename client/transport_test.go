@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	var gets, posts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if gets.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode([]Agent{{AgentID: "a1"}})
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL), WithRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}))
+	agents, err := c.ListAgents(context.Background())
+	if err != nil || len(agents) != 1 || gets.Load() != 3 {
+		t.Fatalf("ListAgents = %+v, %v after %d attempts", agents, err, gets.Load())
+	}
+
+	// Registration is not idempotent, so it is not retried.
+	if _, err := c.RegisterAgent(context.Background(), Registration{Name: "x"}); !errors.Is(err, ErrUnavailable) || posts.Load() != 1 {
+		t.Errorf("RegisterAgent: %v after %d attempts", err, posts.Load())
+	}
+
+	gets.Store(-10)
+	if _, err := c.ListAgents(context.Background()); !errors.Is(err, ErrUnavailable) || gets.Load() != -7 {
+		t.Errorf("exhausted retries: %v after %d attempts", err, gets.Load()+10)
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	if d := p.delay(0, nil); d < 100*time.Millisecond || d > 125*time.Millisecond {
+		t.Errorf("first delay = %v", d)
+	}
+	if d := p.delay(5, nil); d < 300*time.Millisecond || d > 375*time.Millisecond {
+		t.Errorf("capped delay = %v", d)
+	}
+	resp := &http.Response{Header: http.Header{"Retry-After": {"60"}}}
+	if d := p.delay(0, resp); d != 300*time.Millisecond {
+		t.Errorf("Retry-After should be capped at MaxDelay, got %v", d)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithBaseURL(ts.URL), WithTimeout(20*time.Millisecond), WithRetryPolicy(RetryPolicy{}),
+		WithTransport(TransportOptions{MaxIdleConnsPerHost: 1, DisableKeepAlives: true}))
+	if _, err := c.ListAgents(context.Background()); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("timed-out request: got %v, want ErrUnavailable", err)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+	"github.com/mistakeknot/intermap/internal/tools"
+)
+
+// runAnnotatePR implements `intermap-mcp annotate-pr`, posting the impact
+// summary comment for a PR and printing it to stdout.
+func runAnnotatePR(args []string) int {
+	fs := flag.NewFlagSet("annotate-pr", flag.ContinueOnError)
+	project := fs.String("project", ".", "project path (a checkout of the PR branch)")
+	number := fs.Int("pr", 0, "pull request or merge request number")
+	remote := fs.String("remote", "origin", "git remote pointing at the forge")
+	language := fs.String("language", "", "programming language (default: detected)")
+	dryRun := fs.Bool("dry-run", false, "print the comment without posting it")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *number <= 0 {
+		fmt.Fprintln(os.Stderr, "intermap-mcp annotate-pr: -pr is required")
+		return 2
+	}
+
+	bridge := pybridge.NewBridge(pybridge.DefaultPythonPath())
+	defer bridge.Close()
+
+	res, err := tools.AnnotatePR(context.Background(), bridge, tools.AnnotateOptions{
+		Project:  *project,
+		Number:   *number,
+		Remote:   *remote,
+		Language: *language,
+		DryRun:   *dryRun,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp annotate-pr: %v\n", err)
+		return 1
+	}
+	fmt.Print(res.Comment)
+	if res.CommentURL != "" {
+		fmt.Fprintf(os.Stderr, "posted %s\n", res.CommentURL)
+	}
+	return 0
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mistakeknot/intermap/internal/ci"
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+	"github.com/mistakeknot/intermap/internal/tools"
+)
+
+// runCI implements `intermap-mcp ci`: change_impact against a base ref,
+// rendered as JSON or JUnit XML, with an exit code pipelines can gate on.
+func runCI(args []string) int {
+	fs := flag.NewFlagSet("ci", flag.ContinueOnError)
+	project := fs.String("project", ".", "project path")
+	base := fs.String("base", "HEAD~1", "git ref to diff against")
+	language := fs.String("language", "", "programming language (default: detected)")
+	format := fs.String("format", "json", "output format: json or junit")
+	failOn := fs.String("fail-on", ci.FailOnMissing, "exit 1 when: missing (impacted tests not in the change), any (any impacted test), none")
+	out := fs.String("out", "", "write the report to this file instead of stdout")
+	policyFile := fs.String("policy", "", "policy rules file, one rule per line (default: the project's .intermap.yaml policy)")
+	if err := fs.Parse(args); err != nil {
+		return ci.ExitUsage
+	}
+
+	rulesText := ""
+	if *policyFile != "" {
+		data, err := os.ReadFile(*policyFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap-mcp ci: %v\n", err)
+			return ci.ExitUsage
+		}
+		rulesText = string(data)
+	}
+	rules, err := tools.ProjectPolicy(*project, rulesText)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp ci: %v\n", err)
+		return ci.ExitUsage
+	}
+
+	bridge := pybridge.NewBridge(pybridge.DefaultPythonPath())
+	defer bridge.Close()
+
+	result, err := tools.ChangeImpact(context.Background(), bridge, *project, *language, *base)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp ci: %v\n", err)
+		return ci.ExitAnalyze
+	}
+	report, err := ci.EvaluateImpact(*project, *base, *failOn, result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp ci: %v\n", err)
+		return ci.ExitUsage
+	}
+	if err := report.ApplyPolicy(rules); err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp ci: %v\n", err)
+		return ci.ExitUsage
+	}
+	data, err := report.Encode(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp ci: %v\n", err)
+		return ci.ExitUsage
+	}
+
+	if *out == "" {
+		os.Stdout.Write(data)
+	} else if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp ci: %v\n", err)
+		return ci.ExitAnalyze
+	}
+	return report.ExitCode
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/intermap/internal/editor"
+	"github.com/mistakeknot/intermap/internal/tools"
+)
+
+// runEditor implements `intermap-mcp editor`: JSON-RPC for editor plugins
+// on stdin and stdout, one message per line.
+func runEditor(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "usage: intermap-mcp editor (JSON-RPC on stdin/stdout)")
+		return 2
+	}
+	s := server.NewMCPServer("intermap", version, server.WithToolCapabilities(true))
+	bridge := tools.RegisterAll(s, coord)
+	defer bridge.Close()
+	if err := editor.New(tools.ServerCaller(s)).Serve(context.Background(), os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp editor: %v\n", err)
+		return 1
+	}
+	return 0
+}
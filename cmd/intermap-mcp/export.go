@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+	"github.com/mistakeknot/intermap/internal/tools"
+)
+
+// runExport implements `intermap-mcp export`, writing the workspace map to
+// stdout or a file without starting the MCP server.
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	root := fs.String("root", ".", "workspace root directory to scan")
+	format := fs.String("format", "json", "output format: json (JGF) or graphml")
+	out := fs.String("out", "", "write to this file instead of stdout")
+	symbols := fs.Bool("symbols", false, "include key symbols per project")
+	top := fs.Int("top", 10, "key symbols per project with -symbols")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	bridge := pybridge.NewBridge(pybridge.DefaultPythonPath())
+	defer bridge.Close()
+	g, err := tools.BuildWorkspaceMap(context.Background(), bridge, coord, tools.ExportOptions{
+		Root:           *root,
+		IncludeSymbols: *symbols,
+		Top:            *top,
+		MaxFiles:       500,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp export: %v\n", err)
+		return 1
+	}
+	data, err := g.Encode(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp export: %v\n", err)
+		return 2
+	}
+
+	if *out == "" {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp export: %v\n", err)
+		return 1
+	}
+	return 0
+}
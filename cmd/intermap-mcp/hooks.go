@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mistakeknot/intermap/internal/ci"
+	"github.com/mistakeknot/intermap/internal/hooks"
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+	"github.com/mistakeknot/intermap/internal/tools"
+)
+
+const hooksUsage = `usage:
+  intermap-mcp hooks install [-repo dir] [-hook pre-commit,pre-push] [-force]
+  intermap-mcp hooks run pre-commit|pre-push   (called by the installed hook)`
+
+// runHooks implements `intermap-mcp hooks`: install git hooks, and run
+// their checks.
+func runHooks(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, hooksUsage)
+		return 2
+	}
+	switch args[0] {
+	case "install":
+		return runHooksInstall(args[1:])
+	case "run":
+		return runHooksRun(args[1:])
+	}
+	fmt.Fprintln(os.Stderr, hooksUsage)
+	return 2
+}
+
+// hooksPolicy is the configured hooks policy with defaults filled in.
+func hooksPolicy() (hooks.Policy, error) {
+	return hooks.Policy{Impact: hooksCfg.Impact, Reservations: hooksCfg.Reservations, Secrets: hooksCfg.Secrets}.WithDefaults()
+}
+
+func runHooksInstall(args []string) int {
+	fs := flag.NewFlagSet("hooks install", flag.ContinueOnError)
+	repo := fs.String("repo", ".", "git working copy")
+	which := fs.String("hook", hooks.PreCommit, "comma-separated hooks to install: pre-commit, pre-push")
+	force := fs.Bool("force", false, "replace existing hooks not installed by intermap")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if _, err := hooksPolicy(); err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp hooks: %v\n", err)
+		return 2
+	}
+	binary, err := os.Executable()
+	if err == nil {
+		binary, err = filepath.EvalSymlinks(binary)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp hooks: %v\n", err)
+		return 1
+	}
+	for _, hook := range strings.Split(*which, ",") {
+		path, err := hooks.Install(context.Background(), *repo, strings.TrimSpace(hook), binary, os.Getenv("CLAUDE_PLUGIN_ROOT"), *force)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap-mcp hooks: %v\n", err)
+			return 1
+		}
+		fmt.Printf("installed %s\n", path)
+	}
+	return 0
+}
+
+func runHooksRun(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, hooksUsage)
+		return 2
+	}
+	hook := args[0]
+	policy, err := hooksPolicy()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap %s: %v\n", hook, err)
+		return 2
+	}
+	ctx := context.Background()
+	repo, err := filepath.Abs(".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap %s: %v\n", hook, err)
+		return 1
+	}
+
+	var changes *hooks.Changes
+	switch hook {
+	case hooks.PreCommit:
+		changes, err = hooks.Staged(ctx, repo)
+	case hooks.PrePush:
+		changes, err = hooks.Pushed(ctx, repo, os.Stdin)
+	default:
+		fmt.Fprintln(os.Stderr, hooksUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap %s: %v\n", hook, err)
+		return 1
+	}
+	if len(changes.Files) == 0 {
+		return 0
+	}
+
+	// A check that cannot run is reported but never blocks.
+	var findings []hooks.Finding
+	if policy.Secrets != hooks.Off {
+		for _, s := range hooks.ScanSecrets(changes.Diff) {
+			findings = append(findings, hooks.Finding{Check: "secrets", Action: policy.Secrets,
+				Message: fmt.Sprintf("%s:%d: possible %s (mark the line %s if intended)", s.File, s.Line, s.Kind, hooks.AllowSecret)})
+		}
+	}
+	if policy.Reservations != hooks.Off && coord != nil && coord.Available() {
+		conflicts, err := tools.ReservationConflicts(ctx, coord, repo, watchCfg.Agent, changes.Files)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap %s: reservations check failed: %v\n", hook, err)
+		}
+		for _, c := range conflicts {
+			findings = append(findings, hooks.Finding{Check: "reservations", Action: policy.Reservations, Message: c.Message()})
+		}
+	}
+	if policy.Impact != hooks.Off {
+		f, err := impactFinding(ctx, repo, changes.Base, policy.Impact)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap %s: impact check failed: %v\n", hook, err)
+		} else if f != nil {
+			findings = append(findings, *f)
+		}
+	}
+
+	return reportFindings(os.Stderr, hook, findings)
+}
+
+// impactFinding flags impacted tests the change does not touch, as
+// `intermap-mcp ci -fail-on missing` would.
+func impactFinding(ctx context.Context, repo, base, action string) (*hooks.Finding, error) {
+	bridge := pybridge.NewBridge(pybridge.DefaultPythonPath())
+	defer bridge.Close()
+	result, err := tools.ChangeImpact(ctx, bridge, repo, "", base)
+	if err != nil {
+		return nil, err
+	}
+	r, err := ci.EvaluateImpact(repo, base, ci.FailOnMissing, result)
+	if err != nil || len(r.MissingTests) == 0 {
+		return nil, err
+	}
+	const shown = 5
+	msg := fmt.Sprintf("%d impacted tests are not in this change: %s", len(r.MissingTests),
+		strings.Join(r.MissingTests[:min(shown, len(r.MissingTests))], ", "))
+	if len(r.MissingTests) > shown {
+		msg += fmt.Sprintf(" and %d more", len(r.MissingTests)-shown)
+	}
+	if r.TestCommand != "" {
+		msg += "; run: " + r.TestCommand
+	}
+	return &hooks.Finding{Check: "impact", Action: action, Message: msg}, nil
+}
+
+// reportFindings prints findings and returns the hook's exit code.
+func reportFindings(w io.Writer, hook string, findings []hooks.Finding) int {
+	for _, f := range findings {
+		fmt.Fprintf(w, "intermap %s: %s %s: %s\n", hook, f.Action, f.Check, f.Message)
+	}
+	if hooks.Blocked(findings) {
+		fmt.Fprintf(w, "intermap %s: blocked; fix the findings above, change the hooks policy, or bypass with --no-verify\n", hook)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/intermap/internal/access"
+	"github.com/mistakeknot/intermap/internal/config"
+	"github.com/mistakeknot/intermap/internal/rest"
+)
+
+// mcpPath is where the HTTP transport serves MCP.
+const mcpPath = "/mcp"
+
+// serveHTTP serves s over streamable HTTP at hc.Addr, and with hc.REST as
+// a JSON API beside it. With keys configured, each request needs one as a
+// bearer token and is scoped to the key's projects.
+func serveHTTP(s *server.MCPServer, hc config.HTTPConfig) error {
+	keys := make([]access.Key, 0, len(hc.Keys))
+	for _, k := range hc.Keys {
+		secret := os.Getenv(k.KeyEnv)
+		if secret == "" {
+			fmt.Fprintf(os.Stderr, "intermap-mcp: ignoring http key %q: $%s is unset\n", k.Name, k.KeyEnv)
+			continue
+		}
+		keys = append(keys, access.Key{Name: k.Name, Secret: secret, Projects: k.Projects, Intermute: k.Intermute})
+	}
+	if len(hc.Keys) > 0 && len(keys) == 0 {
+		return fmt.Errorf("http: no usable keys; refusing to serve without authentication")
+	}
+	mux := http.NewServeMux()
+	if hc.REST {
+		mux.Handle("/", rest.Handler(s))
+	}
+	mux.Handle(mcpPath, server.NewStreamableHTTPServer(s))
+	h, err := access.New(mux, keys, coord)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Addr: hc.Addr, Handler: h, ReadHeaderTimeout: 10 * time.Second}
+	fmt.Fprintf(os.Stderr, "intermap-mcp: serving MCP on http://%s%s\n", hc.Addr, mcpPath)
+	if hc.REST {
+		fmt.Fprintf(os.Stderr, "intermap-mcp: serving the JSON API on http://%s (see /openapi.json)\n", hc.Addr)
+	}
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/mistakeknot/intermap/internal/install"
+	"github.com/mistakeknot/intermap/python"
+)
+
+// runInstallCheck implements `intermap-mcp install-check`, verifying the
+// plugin layout this binary runs from and optionally repairing it.
+func runInstallCheck(args []string) int {
+	fs := flag.NewFlagSet("install-check", flag.ContinueOnError)
+	root := fs.String("root", "", "plugin root (default $CLAUDE_PLUGIN_ROOT, else the parent of the binary's directory)")
+	repair := fs.Bool("repair", false, "re-extract embedded Python sources, install this binary, and fix permissions")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp install-check: %v\n", err)
+		return 1
+	}
+	if *root == "" {
+		*root = os.Getenv("CLAUDE_PLUGIN_ROOT")
+	}
+	if *root == "" {
+		*root = filepath.Dir(filepath.Dir(exe))
+	}
+	if abs, err := filepath.Abs(*root); err == nil {
+		*root = abs
+	}
+
+	report := install.Verify(*root, install.Options{
+		Version:    version,
+		Python:     python.Files,
+		Executable: exe,
+		Repair:     *repair,
+	})
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, c := range report.Checks {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", c.Status, c.Name, c.Detail)
+		}
+		w.Flush()
+	}
+	if !report.OK() {
+		if !*repair {
+			fmt.Fprintln(os.Stderr, "intermap-mcp install-check: problems found; rerun with -repair to fix what can be fixed")
+		}
+		return 1
+	}
+	return 0
+}
@@ -3,31 +3,371 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/mistakeknot/interbase/go/mcputil"
-	"github.com/mistakeknot/intermap/internal/client"
+	"github.com/mistakeknot/intermap/client"
+	"github.com/mistakeknot/intermap/internal/config"
+	"github.com/mistakeknot/intermap/internal/coordination"
+	"github.com/mistakeknot/intermap/internal/graphsink"
+	"github.com/mistakeknot/intermap/internal/license"
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+	"github.com/mistakeknot/intermap/internal/redact"
+	"github.com/mistakeknot/intermap/internal/spill"
 	"github.com/mistakeknot/intermap/internal/tools"
+	"github.com/mistakeknot/intermap/internal/webhook"
+	"github.com/mistakeknot/intermap/registry"
 )
 
+// subcommands run one-shot CLI modes instead of the MCP server.
+var subcommands = map[string]func(args []string) int{
+	"export":        runExport,
+	"report":        runReport,
+	"watch":         runWatch,
+	"hooks":         runHooks,
+	"editor":        runEditor,
+	"ci":            runCI,
+	"annotate-pr":   runAnnotatePR,
+	"sbom":          runSBOM,
+	"install-check": runInstallCheck,
+}
+
+// version is reported to MCP clients and in the agent registration.
+const version = "0.1.7"
+
+// coord is the coordination provider selected by config; set in main
+// before any subcommand runs.
+var coord coordination.Provider
+
+// watchCfg is the watch section of the config; set in main before any
+// subcommand runs.
+var watchCfg config.WatchConfig
+
+// hooksCfg is the hooks section of the config; set in main before any
+// subcommand runs.
+var hooksCfg config.HooksConfig
+
 func main() {
-	c := client.NewClient(
-		client.WithBaseURL(os.Getenv("INTERMUTE_URL")),
-	)
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp: %v (using defaults)\n", err)
+	}
+	flush := applyConfig(cfg)
+	defer flush()
+	coord = coordinationProvider(cfg.Coordination)
+	watchCfg = cfg.Watch
+	hooksCfg = cfg.Hooks
+
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			code := run(os.Args[2:])
+			flush()
+			os.Exit(code)
+		}
+	}
 
 	metrics := mcputil.NewMetrics()
 	s := server.NewMCPServer(
 		"intermap",
-		"0.1.0",
+		version,
 		server.WithToolCapabilities(true),
 		server.WithToolHandlerMiddleware(metrics.Instrument()),
 	)
 
-	bridge := tools.RegisterAll(s, c)
+	bridge := tools.RegisterAll(s, coord)
 	defer bridge.Close()
+	stopHistory := tools.StartAgentHistory(bridge, coord)
+	defer stopHistory()
+	stopReindex := tools.StartReindex(bridge, func(d tools.Drift) {
+		s.SendNotificationToAllClients("notifications/message", map[string]any{
+			"level":  "warning",
+			"logger": "intermap.drift",
+			"data":   d,
+		})
+	})
+	defer stopReindex()
+	stopHeartbeat := announce(cfg.Coordination, s)
+	defer stopHeartbeat()
 
-	if err := server.ServeStdio(s); err != nil {
+	if cfg.HTTP.Addr != "" {
+		err = serveHTTP(s, cfg.HTTP)
+	} else {
+		err = server.ServeStdio(s)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "intermap-mcp: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// applyConfig pushes user configuration into the packages that consume it
+// and returns a function that flushes pending graph sink writes and webhook
+// deliveries and removes spilled results.
+func applyConfig(cfg *config.Config) func() {
+	markers := make([]registry.Marker, 0, len(cfg.Languages.Markers))
+	for _, m := range cfg.Languages.Markers {
+		markers = append(markers, registry.Marker{File: m.File, Language: m.Language})
+	}
+	registry.SetLanguageConfig(markers, cfg.Languages.Extensions)
+	registry.SetScanWorkers(cfg.Registry.ScanWorkers)
+	registry.SetMaxDepth(cfg.Registry.MaxDepth)
+
+	sink := graphsink.New(
+		graphsink.WithEndpoint(cfg.GraphSink.URL),
+		graphsink.WithDatabase(cfg.GraphSink.Database),
+		graphsink.WithBasicAuth(cfg.GraphSink.User, cfg.GraphSink.Password),
+	)
+	tools.SetGraphSink(sink)
+
+	hooks := make([]webhook.Hook, 0, len(cfg.Webhooks))
+	for _, h := range cfg.Webhooks {
+		hooks = append(hooks, webhook.Hook{URL: h.URL, Events: h.Events, Headers: h.Headers, Secret: h.Secret})
+	}
+	notifier := webhook.New(hooks)
+	tools.SetWebhooks(notifier)
+	tools.SetRedactor(redact.FromEnv())
+	tools.SetAgentHistory(agentHistory(cfg.AgentHistory))
+	tools.SetReindex(reindexJobs(cfg.Reindex))
+	tools.SetLicensePolicy(license.Policy{
+		Allow:      cfg.Licenses.Allow,
+		Deny:       cfg.Licenses.Deny,
+		Exceptions: cfg.Licenses.Exceptions,
+		Unknown:    cfg.Licenses.Unknown,
+	})
+	tools.SetSemanticSearch(tools.SemanticSearch{
+		DB:        cfg.Semantic.DB,
+		Provider:  cfg.Semantic.Provider,
+		Endpoint:  cfg.Semantic.Endpoint,
+		Model:     cfg.Semantic.Model,
+		APIKeyEnv: cfg.Semantic.APIKeyEnv,
+	})
+	tools.SetResultCache(tools.ResultCache{
+		TTLs:       resultTTLs(cfg.ResultCache),
+		MaxEntries: cfg.ResultCache.MaxEntries,
+		SharedDir:  cfg.ResultCache.SharedDir,
+		Validator:  cfg.ResultCache.Validator,
+	})
+	tools.SetBudgets(tools.Budgets{
+		Calls:         cfg.Budgets.Calls,
+		CPUSeconds:    cfg.Budgets.CPUSeconds,
+		FilesParsed:   cfg.Budgets.FilesParsed,
+		BytesReturned: cfg.Budgets.BytesReturned,
+	})
+	tools.SetPriorities(priorities(cfg.Priority))
+	applyWorkspaceConfig()
+	if err := tools.SetPipeline(tools.Pipeline{Enable: cfg.Middleware.Enable, RateLimit: cfg.Middleware.RateLimit}); err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp: ignoring %v\n", err)
+	}
+	tools.SetStrictSchemas(cfg.Schemas.Strict)
+	results := spill.FromEnv()
+	tools.SetSpillStore(results)
+
+	return func() {
+		sink.Close()
+		notifier.Close()
+		results.Close()
+	}
+}
+
+// resultTTLs parses the result_cache TTL overrides, skipping invalid ones.
+func resultTTLs(rc config.ResultCacheConfig) map[string]time.Duration {
+	out := make(map[string]time.Duration, len(rc.TTL))
+	for tool, v := range rc.TTL {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap-mcp: ignoring result_cache.ttl.%s %q: %v\n", tool, v, err)
+			continue
+		}
+		out[tool] = d
+	}
+	return out
+}
+
+// applyWorkspaceConfig installs the tool argument presets from the
+// workspace's .intermap.yaml (under INTERMAP_WORKSPACE_ROOT, or the working
+// directory).
+func applyWorkspaceConfig() {
+	root := os.Getenv("INTERMAP_WORKSPACE_ROOT")
+	if root == "" {
+		root = "."
+	}
+	ws, err := config.LoadWorkspace(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp: %v (ignoring %s)\n", err, config.WorkspaceFile)
+		return
+	}
+	if err := tools.SetToolDefaults(ws.ToolDefaults); err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp: ignoring %v\n", err)
+	}
+}
+
+// priorities parses the priority config section, skipping invalid class
+// names.
+func priorities(pc config.PriorityConfig) tools.Priorities {
+	out := tools.Priorities{
+		Tools:   make(map[string]pybridge.Priority, len(pc.Tools)),
+		Weights: make(map[pybridge.Priority]int, len(pc.Weights)),
+	}
+	for tool, name := range pc.Tools {
+		p, err := pybridge.ParsePriority(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap-mcp: ignoring priority.tools.%s: %v\n", tool, err)
+			continue
+		}
+		out.Tools[tool] = p
+	}
+	for name, w := range pc.Weights {
+		p, err := pybridge.ParsePriority(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap-mcp: ignoring priority.weights: %v\n", err)
+			continue
+		}
+		out.Weights[p] = w
+	}
+	return out
+}
+
+// agentHistory converts the agent_history config section, falling back to
+// defaults for unset or invalid values.
+func agentHistory(h config.AgentHistoryConfig) tools.AgentHistory {
+	out := tools.AgentHistory{DB: h.DB, RetainDays: h.RetainDays}
+	if out.RetainDays == 0 {
+		out.RetainDays = 30
+	}
+	if h.Interval != "" {
+		d, err := time.ParseDuration(h.Interval)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap-mcp: ignoring agent_history.interval %q: %v\n", h.Interval, err)
+		}
+		out.Interval = d
+	}
+	return out
+}
+
+// reindexJobs converts the reindex config section, skipping projects with
+// a bad path or schedule.
+func reindexJobs(rc config.ReindexConfig) tools.Reindex {
+	r := tools.Reindex{FanIn: rc.FanIn, Dir: rc.Dir}
+	for _, p := range rc.Projects {
+		every := p.Every
+		if every == "" {
+			every = "@hourly"
+		}
+		d, err := tools.ParseSchedule(every)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap-mcp: ignoring reindex project %s: %v\n", p.Path, err)
+			continue
+		}
+		abs, err := filepath.Abs(p.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap-mcp: ignoring reindex project %s: %v\n", p.Path, err)
+			continue
+		}
+		r.Jobs = append(r.Jobs, tools.ReindexJob{Project: abs, Language: p.Language, Every: d})
+	}
+	return r
+}
+
+// coordinationProvider builds the provider named by the coordination config
+// section. Unknown providers fall back to intermute.
+func coordinationProvider(cc config.CoordinationConfig) coordination.Provider {
+	var interval time.Duration
+	if cc.PollInterval != "" {
+		d, err := time.ParseDuration(cc.PollInterval)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap-mcp: ignoring coordination.poll_interval %q: %v\n", cc.PollInterval, err)
+		}
+		interval = d
+	}
+
+	switch cc.Provider {
+	case "file":
+		dir := cc.Dir
+		if dir == "" {
+			dir = filepath.Join(".intermap", "coordination")
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		f := coordination.NewFile(dir)
+		f.PollInterval = interval
+		return f
+	case "", "intermute":
+	default:
+		fmt.Fprintf(os.Stderr, "intermap-mcp: unknown coordination.provider %q, using intermute\n", cc.Provider)
+	}
+	i := coordination.NewIntermute(client.NewClient(intermuteOptions(cc)...))
+	i.PollInterval = interval
+	return i
+}
+
+// intermuteOptions configures the intermute client from the environment
+// and the coordination config section.
+func intermuteOptions(cc config.CoordinationConfig) []client.Option {
+	opts := []client.Option{
+		client.WithBaseURL(os.Getenv("INTERMUTE_URL")),
+		client.WithToken(os.Getenv("INTERMUTE_TOKEN")),
+	}
+	if cc.Timeout != "" {
+		if d, err := time.ParseDuration(cc.Timeout); err != nil {
+			fmt.Fprintf(os.Stderr, "intermap-mcp: ignoring coordination.timeout %q: %v\n", cc.Timeout, err)
+		} else {
+			opts = append(opts, client.WithTimeout(d))
+		}
+	}
+	if cc.Retries != 0 {
+		policy := client.DefaultRetryPolicy
+		policy.MaxRetries = max(cc.Retries, 0)
+		opts = append(opts, client.WithRetryPolicy(policy))
+	}
+	if cc.MaxIdleConns > 0 {
+		transport := client.DefaultTransportOptions
+		transport.MaxIdleConnsPerHost = cc.MaxIdleConns
+		transport.MaxIdleConns = max(transport.MaxIdleConns, cc.MaxIdleConns)
+		opts = append(opts, client.WithTransport(transport))
+	}
+	return opts
+}
+
+// announce registers this server with the coordination provider when
+// coordination.announce is set, advertising its tools as capabilities.
+func announce(cc config.CoordinationConfig, s *server.MCPServer) (stop func()) {
+	if !cc.Announce {
+		return func() {}
+	}
+	var interval time.Duration
+	if cc.HeartbeatInterval != "" {
+		d, err := time.ParseDuration(cc.HeartbeatInterval)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap-mcp: ignoring coordination.heartbeat_interval %q: %v\n", cc.HeartbeatInterval, err)
+		}
+		interval = d
+	}
+
+	host, _ := os.Hostname()
+	root := os.Getenv("INTERMAP_WORKSPACE_ROOT")
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+	capabilities := make([]string, 0, len(s.ListTools()))
+	for name := range s.ListTools() {
+		capabilities = append(capabilities, name)
+	}
+	sort.Strings(capabilities)
+
+	return coordination.StartHeartbeat(coord, coordination.Registration{
+		Name:         "intermap",
+		Project:      filepath.Base(root),
+		Host:         host,
+		Capabilities: capabilities,
+		Metadata: map[string]string{
+			"service":        "map",
+			"version":        version,
+			"workspace_root": root,
+			"pid":            fmt.Sprint(os.Getpid()),
+		},
+	}, interval)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+	"github.com/mistakeknot/intermap/internal/report"
+	"github.com/mistakeknot/intermap/internal/tools"
+)
+
+// runReport implements `intermap-mcp report`, rendering the workspace map
+// as a static HTML page for people who won't use the MCP tools.
+func runReport(args []string) int {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	root := fs.String("root", ".", "workspace root directory to scan")
+	out := fs.String("out", "intermap-report.html", "write the report to this file (- for stdout)")
+	title := fs.String("title", "", "page title (default: Workspace map: <root name>)")
+	symbols := fs.Bool("symbols", false, "list key symbols on each project card")
+	top := fs.Int("top", 5, "key symbols per project with -symbols")
+	since := fs.String("since", "30 days ago", "git window for hotspots")
+	hotspots := fs.Int("hotspots", 20, "hotspot files to list")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	absRoot, err := filepath.Abs(*root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp report: %v\n", err)
+		return 2
+	}
+
+	bridge := pybridge.NewBridge(pybridge.DefaultPythonPath())
+	defer bridge.Close()
+	r, err := tools.BuildReport(context.Background(), bridge, coord, tools.ReportOptions{
+		ExportOptions: tools.ExportOptions{
+			Root:           absRoot,
+			IncludeSymbols: *symbols,
+			Top:            *top,
+			MaxFiles:       500,
+		},
+		Title:    *title,
+		Since:    *since,
+		Hotspots: *hotspots,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp report: %v\n", err)
+		return 1
+	}
+	var buf bytes.Buffer
+	if err := report.Render(&buf, r); err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp report: %v\n", err)
+		return 1
+	}
+
+	if *out == "-" {
+		os.Stdout.Write(buf.Bytes())
+		return 0
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp report: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "intermap-mcp report: wrote %s (%d projects)\n", *out, len(r.Projects))
+	return 0
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mistakeknot/intermap/internal/tools"
+)
+
+// runSBOM implements `intermap-mcp sbom`, writing a CycloneDX or SPDX
+// document for the workspace or one project.
+func runSBOM(args []string) int {
+	fs := flag.NewFlagSet("sbom", flag.ContinueOnError)
+	root := fs.String("root", ".", "workspace root directory to scan")
+	project := fs.String("project", "", "describe only this project (name or path)")
+	format := fs.String("format", "cyclonedx", "output format: cyclonedx or spdx")
+	out := fs.String("out", "", "write to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	bom, err := tools.BuildSBOM(*root, *project)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp sbom: %v\n", err)
+		return 1
+	}
+	data, err := bom.Encode(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp sbom: %v\n", err)
+		return 2
+	}
+
+	if *out == "" {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp sbom: %v\n", err)
+		return 1
+	}
+	return 0
+}
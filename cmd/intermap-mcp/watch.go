@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mistakeknot/intermap/internal/tools"
+)
+
+// runWatch implements `intermap-mcp watch`: poll local edits and active
+// reservations, and warn as soon as a file another agent has reserved is
+// edited.
+func runWatch(args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	projects := fs.String("project", ".", "comma-separated git working copies to watch")
+	interval := fs.String("interval", watchCfg.Interval, "time between checks (default 3s)")
+	agent := fs.String("agent", watchCfg.Agent, "your intermute agent ID or name; your own reservations never alert")
+	once := fs.Bool("once", false, "check once, exiting 1 if any conflict is found")
+	asJSON := fs.Bool("json", false, "print alerts as JSON lines")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	every := 3 * time.Second
+	if *interval != "" {
+		d, err := time.ParseDuration(*interval)
+		if err != nil || d <= 0 {
+			fmt.Fprintf(os.Stderr, "intermap-mcp watch: bad interval %q\n", *interval)
+			return 2
+		}
+		every = d
+	}
+	if coord == nil || !coord.Available() {
+		fmt.Fprintln(os.Stderr, "intermap-mcp watch: no coordination provider is available (set INTERMUTE_URL or coordination.provider)")
+		return 1
+	}
+	w := &tools.Watcher{Self: *agent, Provider: coord}
+	for _, p := range strings.Split(*projects, ",") {
+		abs, err := filepath.Abs(strings.TrimSpace(p))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap-mcp watch: %v\n", err)
+			return 2
+		}
+		w.Projects = append(w.Projects, abs)
+	}
+
+	alert := func(a tools.WatchAlert) {
+		if *asJSON {
+			line, _ := json.Marshal(a)
+			fmt.Println(string(line))
+		} else {
+			fmt.Printf("%s  %s: %s\n", time.Now().Format("15:04:05"), filepath.Base(a.Project), a.Message())
+		}
+		notify(a)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *once {
+		alerts, err := w.Check(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap-mcp watch: %v\n", err)
+			return 1
+		}
+		for _, a := range alerts {
+			alert(a)
+		}
+		if len(alerts) > 0 {
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "intermap-mcp watch: watching %s every %s\n", strings.Join(w.Projects, ", "), every)
+	w.Run(ctx, every, alert, func(err error) {
+		fmt.Fprintf(os.Stderr, "intermap-mcp watch: %v\n", err)
+	})
+	return 0
+}
+
+// notify runs the configured watch.notify command for a.
+func notify(a tools.WatchAlert) {
+	if len(watchCfg.Notify) == 0 {
+		return
+	}
+	var agents []string
+	for _, r := range a.Reservations {
+		name := r.AgentName
+		if name == "" {
+			name = r.AgentID
+		}
+		agents = append(agents, name)
+	}
+	replacer := strings.NewReplacer(
+		"{project}", a.Project,
+		"{file}", a.File,
+		"{status}", a.Status,
+		"{agents}", strings.Join(agents, ", "),
+		"{message}", a.Message(),
+	)
+	argv := make([]string, len(watchCfg.Notify))
+	for i, arg := range watchCfg.Notify {
+		argv[i] = replacer.Replace(arg)
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "intermap-mcp watch: notify: %v\n", err)
+	}
+}
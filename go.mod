@@ -5,6 +5,8 @@ go 1.23.0
 require (
 	github.com/mark3labs/mcp-go v0.43.2
 	github.com/mistakeknot/interbase/go v0.1.1
+	golang.org/x/tools v0.31.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -16,5 +18,6 @@ require (
 	github.com/spf13/cast v1.7.1 // indirect
 	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/mod v0.24.0 // indirect
+	golang.org/x/sync v0.12.0 // indirect
 )
@@ -0,0 +1,176 @@
+// Package access authenticates MCP requests served over HTTP by API key
+// and binds each to the projects its key may use, as a tools.Scope in the
+// request context. The key's identity is in the context too (Identity).
+package access
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/intermap/internal/coordination"
+	"github.com/mistakeknot/intermap/internal/tools"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// AgentHeader names the intermute agent a request acts for, by ID or name.
+const AgentHeader = "X-Intermute-Agent"
+
+// Key is an API key and the projects its sessions may use.
+type Key struct {
+	// Name identifies the key in errors; it is not secret.
+	Name   string
+	Secret string
+	// Projects are project names, group/name paths, globs over either, or
+	// absolute project paths. Empty allows every project, unless Intermute
+	// is set.
+	Projects []string
+	// Intermute restricts sessions to the project of the intermute agent
+	// named in AgentHeader, which must also be among Projects if any are
+	// given.
+	Intermute bool
+}
+
+type identityKey struct{}
+
+// Identity returns the key name, or key/agent for intermute keys, that
+// authenticated ctx's request, or "" when the server has no keys.
+func Identity(ctx context.Context) string {
+	id, _ := ctx.Value(identityKey{}).(string)
+	return id
+}
+
+// AgentLister lists coordination agents; coordination.Provider is one.
+type AgentLister interface {
+	ListAgents(ctx context.Context) ([]coordination.Agent, error)
+}
+
+// Handler checks each request's bearer token against its keys before
+// passing it on. With no keys, every request passes unscoped.
+type Handler struct {
+	next   http.Handler
+	keys   []Key
+	agents AgentLister
+
+	mu sync.Mutex
+	// sessions binds MCP session IDs to the identity that first used them,
+	// so a session cannot be reused under another key or agent.
+	sessions map[string]string
+}
+
+// New returns a Handler serving next for requests carrying one of keys.
+// agents is needed only for keys with Intermute set.
+func New(next http.Handler, keys []Key, agents AgentLister) (*Handler, error) {
+	for _, k := range keys {
+		if k.Secret == "" {
+			return nil, fmt.Errorf("access key %q has no secret", k.Name)
+		}
+		if k.Intermute && agents == nil {
+			return nil, fmt.Errorf("access key %q uses intermute identity but no coordination provider is configured", k.Name)
+		}
+	}
+	return &Handler{next: next, keys: keys, agents: agents, sessions: map[string]string{}}, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if len(h.keys) == 0 {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	key := h.match(r)
+	if key == nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="intermap"`)
+		http.Error(w, "missing or unknown API key", http.StatusUnauthorized)
+		return
+	}
+	scope, err := h.scope(r, key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	identity := key.Name
+	if scope != nil {
+		identity = scope.Name
+	}
+	if err := h.bind(r, identity); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	ctx := context.WithValue(r.Context(), identityKey{}, identity)
+	if scope != nil {
+		ctx = tools.WithScope(ctx, scope)
+	}
+	h.next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// match returns the key whose secret is the request's bearer token.
+func (h *Handler) match(r *http.Request) *Key {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil
+	}
+	for i := range h.keys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.keys[i].Secret)) == 1 {
+			return &h.keys[i]
+		}
+	}
+	return nil
+}
+
+// scope returns the projects key allows for r, or nil for all of them.
+func (h *Handler) scope(r *http.Request, key *Key) (*tools.Scope, error) {
+	if !key.Intermute {
+		if len(key.Projects) == 0 {
+			return nil, nil
+		}
+		return &tools.Scope{Name: key.Name, Projects: key.Projects}, nil
+	}
+	name := r.Header.Get(AgentHeader)
+	if name == "" {
+		return nil, fmt.Errorf("key %s requires the %s header", key.Name, AgentHeader)
+	}
+	agents, err := h.agents.ListAgents(r.Context())
+	if err != nil {
+		return nil, fmt.Errorf("looking up agent %s: %v", name, err)
+	}
+	for _, a := range agents {
+		if a.AgentID != name && a.Name != name {
+			continue
+		}
+		if a.Project == "" {
+			return nil, fmt.Errorf("agent %s has no project", name)
+		}
+		if len(key.Projects) > 0 {
+			keyScope := &tools.Scope{Projects: key.Projects}
+			if !keyScope.Allows(registry.Project{Name: a.Project, Path: a.Project}) {
+				return nil, fmt.Errorf("agent %s works on %s, which key %s does not allow", name, a.Project, key.Name)
+			}
+		}
+		return &tools.Scope{Name: key.Name + "/" + a.Name, Projects: []string{a.Project}}, nil
+	}
+	return nil, fmt.Errorf("unknown agent %s", name)
+}
+
+// bind ties r's MCP session to identity, or refuses a session first used
+// by another identity. Deleting a session releases it.
+func (h *Handler) bind(r *http.Request, identity string) error {
+	id := r.Header.Get(server.HeaderKeySessionID)
+	if id == "" {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if owner, ok := h.sessions[id]; ok && owner != identity {
+		return fmt.Errorf("session belongs to another key or agent")
+	}
+	if r.Method == http.MethodDelete {
+		delete(h.sessions, id)
+	} else {
+		h.sessions[id] = identity
+	}
+	return nil
+}
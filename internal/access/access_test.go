@@ -0,0 +1,81 @@
+package access
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/intermap/internal/coordination"
+	"github.com/mistakeknot/intermap/internal/tools"
+)
+
+type agents []coordination.Agent
+
+func (a agents) ListAgents(context.Context) ([]coordination.Agent, error) { return a, nil }
+
+func TestHandler(t *testing.T) {
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s := tools.ScopeFrom(r.Context()); s != nil {
+			w.Write([]byte(s.Name + ":" + strings.Join(s.Projects, ",")))
+		} else {
+			w.Write([]byte("unscoped"))
+		}
+	})
+	h, err := New(echo, []Key{
+		{Name: "admin", Secret: "a-secret"},
+		{Name: "web", Secret: "w-secret", Projects: []string{"web", "ui/*"}},
+		{Name: "agents", Secret: "g-secret", Projects: []string{"web", "api"}, Intermute: true},
+	}, agents{
+		{AgentID: "a1", Name: "builder", Project: "web"},
+		{AgentID: "a2", Name: "fixer", Project: "billing"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name, token, agent, session string
+		code                        int
+		body                        string
+	}{
+		{name: "no key", code: http.StatusUnauthorized},
+		{name: "wrong key", token: "nope", code: http.StatusUnauthorized},
+		{name: "unrestricted key", token: "a-secret", code: http.StatusOK, body: "unscoped"},
+		{name: "project key", token: "w-secret", code: http.StatusOK, body: "web:web,ui/*"},
+		{name: "agent by name", token: "g-secret", agent: "builder", code: http.StatusOK, body: "agents/builder:web"},
+		{name: "agent by id", token: "g-secret", agent: "a1", code: http.StatusOK, body: "agents/builder:web"},
+		{name: "agent outside key", token: "g-secret", agent: "fixer", code: http.StatusForbidden},
+		{name: "unknown agent", token: "g-secret", agent: "ghost", code: http.StatusForbidden},
+		{name: "missing agent", token: "g-secret", code: http.StatusForbidden},
+		{name: "session bound", token: "w-secret", session: "s1", code: http.StatusOK, body: "web:web,ui/*"},
+		{name: "session reused", token: "a-secret", session: "s1", code: http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		if tt.agent != "" {
+			req.Header.Set(AgentHeader, tt.agent)
+		}
+		if tt.session != "" {
+			req.Header.Set(server.HeaderKeySessionID, tt.session)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.code || tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("%s: got %d %q, want %d %q", tt.name, rec.Code, rec.Body.String(), tt.code, tt.body)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(http.NotFoundHandler(), []Key{{Name: "empty"}}, nil); err == nil {
+		t.Error("key without a secret accepted")
+	}
+	if _, err := New(http.NotFoundHandler(), []Key{{Name: "g", Secret: "s", Intermute: true}}, nil); err == nil {
+		t.Error("intermute key without agents accepted")
+	}
+}
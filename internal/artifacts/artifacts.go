@@ -0,0 +1,649 @@
+// Package artifacts maps a project's buildable artifacts — Go main
+// packages, Python console scripts, npm bins, and Docker images — to the
+// source files each one is built from, so a set of changed files can be
+// turned into the list of artifacts that need rebuilding.
+//
+// Source sets are found statically and err on the side of inclusion: Go
+// build tags are ignored, Python and JavaScript imports are matched
+// line-by-line, and Docker COPY/ADD sources are kept as build-context paths
+// without applying .dockerignore.
+package artifacts
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Artifact kinds.
+const (
+	KindGoBinary     = "go_binary"
+	KindPythonScript = "python_script"
+	KindNpmBin       = "npm_bin"
+	KindDockerImage  = "docker_image"
+)
+
+// Artifact is one buildable output. All paths are project-relative with
+// forward slashes.
+type Artifact struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Entry is the main package directory, script module, bin file, or
+	// Dockerfile.
+	Entry string `json:"entry"`
+	// Files are the source files the artifact is built from, sorted.
+	Files []string `json:"files,omitempty"`
+	// Paths are Docker build-context paths or globs copied into the image;
+	// a directory covers everything beneath it.
+	Paths []string `json:"paths,omitempty"`
+}
+
+// Rebuild is an artifact affected by a change, with the changed files that
+// triggered it.
+type Rebuild struct {
+	Name         string   `json:"name"`
+	Kind         string   `json:"kind"`
+	Entry        string   `json:"entry"`
+	ChangedFiles []string `json:"changed_files"`
+}
+
+// skipDirs are never descended into.
+var skipDirs = map[string]bool{
+	"vendor": true, "node_modules": true, "__pycache__": true, "venv": true,
+	"testdata": true, "dist": true, "target": true,
+}
+
+// Scan finds the artifacts of the project rooted at root, sorted by kind
+// then name.
+func Scan(root string) ([]Artifact, error) {
+	t, err := walk(root)
+	if err != nil {
+		return nil, err
+	}
+	var arts []Artifact
+	arts = append(arts, t.goBinaries()...)
+	arts = append(arts, t.pythonScripts()...)
+	arts = append(arts, t.npmBins()...)
+	arts = append(arts, t.dockerImages()...)
+	sort.Slice(arts, func(i, j int) bool {
+		if arts[i].Kind != arts[j].Kind {
+			return arts[i].Kind < arts[j].Kind
+		}
+		if arts[i].Name != arts[j].Name {
+			return arts[i].Name < arts[j].Name
+		}
+		return arts[i].Entry < arts[j].Entry
+	})
+	return arts, nil
+}
+
+// Includes reports whether the project-relative file is part of the
+// artifact's sources.
+func (a Artifact) Includes(file string) bool {
+	file = path.Clean(filepath.ToSlash(file))
+	if i := sort.SearchStrings(a.Files, file); i < len(a.Files) && a.Files[i] == file {
+		return true
+	}
+	for _, p := range a.Paths {
+		if pathCovers(p, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// Affected returns the artifacts that include at least one changed file.
+func Affected(arts []Artifact, changed []string) []Rebuild {
+	out := []Rebuild{}
+	for _, a := range arts {
+		var hits []string
+		for _, f := range changed {
+			if a.Includes(f) {
+				hits = append(hits, f)
+			}
+		}
+		if len(hits) > 0 {
+			out = append(out, Rebuild{Name: a.Name, Kind: a.Kind, Entry: a.Entry, ChangedFiles: hits})
+		}
+	}
+	return out
+}
+
+// pathCovers reports whether a build-context path or glob covers file.
+func pathCovers(p, file string) bool {
+	if p == "." {
+		return true
+	}
+	if strings.ContainsAny(p, "*?[") {
+		for f := file; f != "."; f = path.Dir(f) {
+			if ok, _ := path.Match(p, f); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return file == p || strings.HasPrefix(file, p+"/")
+}
+
+// tree is the set of files found under a project root.
+type tree struct {
+	root  string
+	files map[string]bool // project-relative, forward slashes
+	// goDirs maps a directory to its non-test Go files.
+	goDirs map[string][]string
+}
+
+func walk(root string) (*tree, error) {
+	t := &tree{root: root, files: make(map[string]bool), goDirs: make(map[string][]string)}
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != root && (strings.HasPrefix(name, ".") || skipDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		t.files[rel] = true
+		if strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			dir := path.Dir(rel)
+			t.goDirs[dir] = append(t.goDirs[dir], rel)
+		}
+		return nil
+	})
+	return t, err
+}
+
+func (t *tree) read(rel string) string {
+	data, err := os.ReadFile(filepath.Join(t.root, filepath.FromSlash(rel)))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// filesNamed returns the project files with the given base name, sorted.
+func (t *tree) filesNamed(base string) []string {
+	var out []string
+	for f := range t.files {
+		if path.Base(f) == base {
+			out = append(out, f)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sortedKeys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// --- Go ---
+
+var goModuleLine = regexp.MustCompile(`(?m)^module\s+"?([^\s"]+)"?`)
+
+type goModule struct {
+	path string // import path
+	dir  string // project-relative directory
+}
+
+func (t *tree) goBinaries() []Artifact {
+	var mods []goModule
+	for _, f := range t.filesNamed("go.mod") {
+		if m := goModuleLine.FindStringSubmatch(t.read(f)); m != nil {
+			mods = append(mods, goModule{path: m[1], dir: path.Dir(f)})
+		}
+	}
+	if len(mods) == 0 {
+		return nil
+	}
+	// Longest module path first so nested modules win.
+	sort.Slice(mods, func(i, j int) bool { return len(mods[i].path) > len(mods[j].path) })
+
+	type goPkg struct {
+		name    string
+		imports []string
+	}
+	pkgs := make(map[string]goPkg)
+	fset := token.NewFileSet()
+	for dir, files := range t.goDirs {
+		var pkg goPkg
+		for _, f := range files {
+			af, err := parser.ParseFile(fset, filepath.Join(t.root, filepath.FromSlash(f)), nil, parser.ImportsOnly)
+			if err != nil {
+				continue
+			}
+			pkg.name = af.Name.Name
+			for _, imp := range af.Imports {
+				if p, err := strconv.Unquote(imp.Path.Value); err == nil {
+					pkg.imports = append(pkg.imports, p)
+				}
+			}
+		}
+		pkgs[dir] = pkg
+	}
+
+	moduleOf := func(dir string) *goModule {
+		var best *goModule
+		for i := range mods {
+			m := &mods[i]
+			if m.dir == "." || dir == m.dir || strings.HasPrefix(dir, m.dir+"/") {
+				if best == nil || dirDepth(m.dir) > dirDepth(best.dir) {
+					best = m
+				}
+			}
+		}
+		return best
+	}
+	resolve := func(imp string) (string, bool) {
+		for _, m := range mods {
+			if imp == m.path || strings.HasPrefix(imp, m.path+"/") {
+				return path.Join(m.dir, strings.TrimPrefix(imp, m.path)), true
+			}
+		}
+		return "", false
+	}
+
+	var arts []Artifact
+	for dir, pkg := range pkgs {
+		if pkg.name != "main" {
+			continue
+		}
+		mod := moduleOf(dir)
+		if mod == nil {
+			continue
+		}
+		files := make(map[string]bool)
+		seen := map[string]bool{dir: true}
+		queue := []string{dir}
+		for len(queue) > 0 {
+			d := queue[0]
+			queue = queue[1:]
+			for _, f := range t.goDirs[d] {
+				files[f] = true
+			}
+			if m := moduleOf(d); m != nil {
+				for _, f := range []string{"go.mod", "go.sum"} {
+					if p := path.Join(m.dir, f); t.files[p] {
+						files[p] = true
+					}
+				}
+			}
+			for _, imp := range pkgs[d].imports {
+				if next, ok := resolve(imp); ok && !seen[next] {
+					seen[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+		name := path.Base(dir)
+		if dir == mod.dir {
+			name = path.Base(mod.path)
+		}
+		arts = append(arts, Artifact{Name: name, Kind: KindGoBinary, Entry: dir, Files: sortedKeys(files)})
+	}
+	return arts
+}
+
+// dirDepth is the number of path elements in a project-relative directory.
+func dirDepth(dir string) int {
+	if dir == "." {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
+
+// --- Python ---
+
+var (
+	tomlSection  = regexp.MustCompile(`^\s*\[([^\]]+)\]\s*$`)
+	scriptEntry  = regexp.MustCompile(`^\s*"?([\w.-]+)"?\s*=\s*"?([\w.]+)(?::[\w.]+)?"?`)
+	pyImport     = regexp.MustCompile(`(?m)^\s*import\s+([\w.]+(?:\s+as\s+\w+)?(?:\s*,\s*[\w.]+(?:\s+as\s+\w+)?)*)`)
+	pyFromImport = regexp.MustCompile(`(?m)^\s*from\s+(\.*)([\w.]*)\s+import\s+(?:\(([^)]*)\)|([\w \t,]+))`)
+)
+
+// scriptSections are the manifest sections that declare console scripts,
+// keyed by manifest file name.
+var scriptSections = map[string][]string{
+	"pyproject.toml": {"project.scripts", "tool.poetry.scripts"},
+	"setup.cfg":      {"options.entry_points"},
+}
+
+func (t *tree) pythonScripts() []Artifact {
+	var arts []Artifact
+	for manifest, sections := range scriptSections {
+		for _, f := range t.filesNamed(manifest) {
+			dir := path.Dir(f)
+			roots := []string{dir, path.Join(dir, "src")}
+			for name, module := range parseScripts(t.read(f), sections) {
+				entry, ok := t.resolvePyModule(roots, module)
+				if !ok {
+					continue
+				}
+				files := t.pyClosure(roots, module)
+				files[f] = true
+				arts = append(arts, Artifact{Name: name, Kind: KindPythonScript, Entry: entry, Files: sortedKeys(files)})
+			}
+		}
+	}
+	return arts
+}
+
+// parseScripts extracts name -> module from the given INI/TOML sections.
+// In setup.cfg only the console_scripts key of entry_points is read.
+func parseScripts(data string, sections []string) map[string]string {
+	out := make(map[string]string)
+	in := false
+	inConsole := false
+	for _, line := range strings.Split(data, "\n") {
+		if m := tomlSection.FindStringSubmatch(line); m != nil {
+			in = false
+			for _, s := range sections {
+				if strings.TrimSpace(m[1]) == s {
+					in = true
+				}
+			}
+			inConsole = false
+			continue
+		}
+		if !in || strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if sections[0] == "options.entry_points" {
+			// console_scripts =
+			//     name = pkg.mod:func
+			if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+				key, _, _ := strings.Cut(line, "=")
+				inConsole = strings.TrimSpace(key) == "console_scripts"
+				continue
+			}
+			if !inConsole {
+				continue
+			}
+		}
+		if m := scriptEntry.FindStringSubmatch(line); m != nil {
+			out[m[1]] = m[2]
+		}
+	}
+	return out
+}
+
+// resolvePyModule finds the file for a dotted module under the first
+// matching source root.
+func (t *tree) resolvePyModule(roots []string, module string) (string, bool) {
+	rel := strings.ReplaceAll(module, ".", "/")
+	for _, r := range roots {
+		for _, c := range []string{path.Join(r, rel+".py"), path.Join(r, rel, "__init__.py")} {
+			if t.files[c] {
+				return c, true
+			}
+		}
+	}
+	return "", false
+}
+
+// pyClosure returns the local files transitively imported from module,
+// including the __init__.py of each enclosing package.
+func (t *tree) pyClosure(roots []string, module string) map[string]bool {
+	files := make(map[string]bool)
+	var queue []string
+	add := func(module string) {
+		parts := strings.Split(module, ".")
+		for i := 1; i <= len(parts); i++ {
+			if f, ok := t.resolvePyModule(roots, strings.Join(parts[:i], ".")); ok && !files[f] {
+				files[f] = true
+				queue = append(queue, f)
+			}
+		}
+	}
+	add(module)
+	for len(queue) > 0 {
+		f := queue[0]
+		queue = queue[1:]
+		src := t.read(f)
+		for _, m := range pyImport.FindAllStringSubmatch(src, -1) {
+			for _, item := range strings.Split(m[1], ",") {
+				add(strings.Fields(item)[0])
+			}
+		}
+		for _, m := range pyFromImport.FindAllStringSubmatch(src, -1) {
+			base := m[2]
+			if dots := len(m[1]); dots > 0 {
+				// Relative import: resolve against f's package directory.
+				pkg := path.Dir(f)
+				for i := 1; i < dots; i++ {
+					pkg = path.Dir(pkg)
+				}
+				var ok bool
+				if base, ok = relModule(roots, pkg, base); !ok {
+					continue
+				}
+			}
+			if base != "" {
+				add(base)
+			}
+			for _, name := range strings.Split(m[3]+m[4], ",") {
+				if fields := strings.Fields(name); len(fields) > 0 {
+					add(joinModule(base, fields[0]))
+				}
+			}
+		}
+	}
+	return files
+}
+
+// relModule converts a package directory plus a relative module suffix into
+// a dotted module under one of roots. The result is empty for the root
+// package itself.
+func relModule(roots []string, pkgDir, suffix string) (string, bool) {
+	for _, r := range roots {
+		var mod string
+		switch {
+		case pkgDir == r:
+		case r == ".":
+			mod = pkgDir
+		case strings.HasPrefix(pkgDir, r+"/"):
+			mod = strings.TrimPrefix(pkgDir, r+"/")
+		default:
+			continue
+		}
+		return joinModule(strings.ReplaceAll(mod, "/", "."), suffix), true
+	}
+	return "", false
+}
+
+func joinModule(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+	return a + "." + b
+}
+
+// --- npm ---
+
+var (
+	jsImport  = regexp.MustCompile(`(?:from|import|require\s*\(|import\s*\()\s*['"](\.[^'"]+)['"]`)
+	jsExtends = []string{"", ".js", ".mjs", ".cjs", ".ts", ".tsx", ".jsx", "/index.js", "/index.ts", "/index.mjs"}
+)
+
+func (t *tree) npmBins() []Artifact {
+	var arts []Artifact
+	for _, f := range t.filesNamed("package.json") {
+		var pkg struct {
+			Name string          `json:"name"`
+			Bin  json.RawMessage `json:"bin"`
+		}
+		if json.Unmarshal([]byte(t.read(f)), &pkg) != nil || len(pkg.Bin) == 0 {
+			continue
+		}
+		bins := make(map[string]string)
+		var single string
+		if json.Unmarshal(pkg.Bin, &single) == nil {
+			// A string bin is named after the package, minus any scope.
+			name := pkg.Name
+			if i := strings.LastIndex(name, "/"); i >= 0 {
+				name = name[i+1:]
+			}
+			bins[name] = single
+		} else if json.Unmarshal(pkg.Bin, &bins) != nil {
+			continue
+		}
+		dir := path.Dir(f)
+		for name, bin := range bins {
+			entry, ok := t.resolveJS(path.Join(dir, bin))
+			if !ok {
+				continue
+			}
+			files := t.jsClosure(entry)
+			files[f] = true
+			arts = append(arts, Artifact{Name: name, Kind: KindNpmBin, Entry: entry, Files: sortedKeys(files)})
+		}
+	}
+	return arts
+}
+
+func (t *tree) resolveJS(p string) (string, bool) {
+	for _, ext := range jsExtends {
+		if t.files[p+ext] {
+			return p + ext, true
+		}
+	}
+	return "", false
+}
+
+// jsClosure returns the local files transitively reached from entry via
+// relative import/require specifiers.
+func (t *tree) jsClosure(entry string) map[string]bool {
+	files := map[string]bool{entry: true}
+	queue := []string{entry}
+	for len(queue) > 0 {
+		f := queue[0]
+		queue = queue[1:]
+		for _, m := range jsImport.FindAllStringSubmatch(t.read(f), -1) {
+			if next, ok := t.resolveJS(path.Join(path.Dir(f), m[1])); ok && !files[next] {
+				files[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return files
+}
+
+// --- Docker ---
+
+// isDockerfile matches Dockerfile, Dockerfile.<variant>, and
+// <variant>.Dockerfile.
+func isDockerfile(base string) bool {
+	lower := strings.ToLower(base)
+	return lower == "dockerfile" || strings.HasPrefix(lower, "dockerfile.") || strings.HasSuffix(lower, ".dockerfile")
+}
+
+func (t *tree) dockerImages() []Artifact {
+	var arts []Artifact
+	for f := range t.files {
+		base := path.Base(f)
+		if !isDockerfile(base) {
+			continue
+		}
+		// The build context is assumed to be the Dockerfile's directory.
+		ctx := path.Dir(f)
+		name := path.Base(ctx)
+		if ctx == "." {
+			name = filepath.Base(t.root)
+		}
+		if variant := dockerVariant(base); variant != "" {
+			name += "-" + variant
+		}
+		paths := make(map[string]bool)
+		for _, src := range copySources(t.read(f)) {
+			paths[path.Join(ctx, src)] = true
+		}
+		files := []string{f}
+		if ignore := path.Join(ctx, ".dockerignore"); t.files[ignore] {
+			files = append(files, ignore)
+			sort.Strings(files)
+		}
+		arts = append(arts, Artifact{Name: name, Kind: KindDockerImage, Entry: f, Files: files, Paths: sortedKeys(paths)})
+	}
+	return arts
+}
+
+func dockerVariant(base string) string {
+	lower := strings.ToLower(base)
+	switch {
+	case strings.HasPrefix(lower, "dockerfile."):
+		return base[len("dockerfile."):]
+	case strings.HasSuffix(lower, ".dockerfile"):
+		return base[:len(base)-len(".dockerfile")]
+	}
+	return ""
+}
+
+// copySources returns the build-context sources of the COPY and ADD
+// instructions in a Dockerfile. Copies from other stages (--from) and
+// remote ADD URLs are skipped.
+func copySources(data string) []string {
+	var out []string
+	joined := strings.ReplaceAll(strings.ReplaceAll(data, "\\\r\n", " "), "\\\n", " ")
+	for _, line := range strings.Split(joined, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		instr := strings.ToUpper(fields[0])
+		if instr != "COPY" && instr != "ADD" {
+			continue
+		}
+		args := fields[1:]
+		fromStage := false
+		for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+			if strings.HasPrefix(args[0], "--from") {
+				fromStage = true
+			}
+			args = args[1:]
+		}
+		if fromStage {
+			continue
+		}
+		rest := strings.Join(args, " ")
+		if strings.HasPrefix(rest, "[") {
+			var list []string
+			if json.Unmarshal([]byte(rest), &list) != nil {
+				continue
+			}
+			args = list
+		}
+		if len(args) < 2 {
+			continue
+		}
+		for _, src := range args[:len(args)-1] {
+			if strings.Contains(src, "://") {
+				continue
+			}
+			out = append(out, path.Clean(strings.TrimPrefix(src, "/")))
+		}
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func write(t *testing.T, root, rel, content string) {
+	t.Helper()
+	p := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func byName(t *testing.T, arts []Artifact, kind, name string) Artifact {
+	t.Helper()
+	for _, a := range arts {
+		if a.Kind == kind && a.Name == name {
+			return a
+		}
+	}
+	t.Fatalf("no %s artifact %q in %+v", kind, name, arts)
+	return Artifact{}
+}
+
+func TestScan_Go(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "go.mod", "module example.com/app\n\ngo 1.23\n")
+	write(t, root, "cmd/server/main.go", "package main\n\nimport (\n\t\"fmt\"\n\t\"example.com/app/internal/api\"\n)\n\nfunc main() { fmt.Println(api.X) }\n")
+	write(t, root, "cmd/tool/main.go", "package main\n\nfunc main() {}\n")
+	write(t, root, "internal/api/api.go", "package api\n\nimport \"example.com/app/internal/store\"\n\nvar X = store.Y\n")
+	write(t, root, "internal/api/api_test.go", "package api\n")
+	write(t, root, "internal/store/store.go", "package store\n\nvar Y = 1\n")
+	write(t, root, "internal/unused/unused.go", "package unused\n")
+
+	arts, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := byName(t, arts, KindGoBinary, "server")
+	want := []string{"cmd/server/main.go", "go.mod", "internal/api/api.go", "internal/store/store.go"}
+	if !reflect.DeepEqual(server.Files, want) {
+		t.Errorf("server files = %v, want %v", server.Files, want)
+	}
+	tool := byName(t, arts, KindGoBinary, "tool")
+	if tool.Includes("internal/store/store.go") {
+		t.Error("tool should not include store.go")
+	}
+
+	got := Affected(arts, []string{"internal/store/store.go"})
+	if len(got) != 1 || got[0].Name != "server" {
+		t.Errorf("Affected = %+v, want only server", got)
+	}
+}
+
+func TestScan_Python(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "pyproject.toml", "[project]\nname = \"app\"\n\n[project.scripts]\napp-cli = \"app.cli:main\"\n\n[tool.other]\nx = 1\n")
+	write(t, root, "src/app/__init__.py", "")
+	write(t, root, "src/app/cli.py", "import sys\nfrom . import config\nfrom app.core import run\n")
+	write(t, root, "src/app/config.py", "")
+	write(t, root, "src/app/core.py", "from .util import helper\n")
+	write(t, root, "src/app/util.py", "")
+	write(t, root, "src/app/other.py", "")
+
+	arts, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli := byName(t, arts, KindPythonScript, "app-cli")
+	want := []string{"pyproject.toml", "src/app/__init__.py", "src/app/cli.py", "src/app/config.py", "src/app/core.py", "src/app/util.py"}
+	if !reflect.DeepEqual(cli.Files, want) {
+		t.Errorf("files = %v, want %v", cli.Files, want)
+	}
+}
+
+func TestScan_NpmBin(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "package.json", `{"name": "@acme/tool", "bin": "bin/cli.js"}`)
+	write(t, root, "bin/cli.js", "const run = require('../lib/run');\n")
+	write(t, root, "lib/run.js", "import { fmt } from './fmt';\n")
+	write(t, root, "lib/fmt.ts", "")
+	write(t, root, "lib/unused.js", "")
+
+	arts, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := byName(t, arts, KindNpmBin, "tool")
+	want := []string{"bin/cli.js", "lib/fmt.ts", "lib/run.js", "package.json"}
+	if !reflect.DeepEqual(bin.Files, want) {
+		t.Errorf("files = %v, want %v", bin.Files, want)
+	}
+}
+
+func TestScan_Docker(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "deploy/api/Dockerfile", "FROM golang AS build\nCOPY go.mod go.sum ./\nCOPY src/ \\\n  /app/src\nADD https://example.com/x.tgz /tmp/\nFROM alpine\nCOPY --from=build /out /bin/app\nCOPY [\"conf/*.yaml\", \"/etc/app/\"]\n")
+	write(t, root, "deploy/api/src/main.go", "")
+
+	arts, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := byName(t, arts, KindDockerImage, "api")
+	want := []string{"deploy/api/conf/*.yaml", "deploy/api/go.mod", "deploy/api/go.sum", "deploy/api/src"}
+	if !reflect.DeepEqual(img.Paths, want) {
+		t.Errorf("paths = %v, want %v", img.Paths, want)
+	}
+	for file, want := range map[string]bool{
+		"deploy/api/Dockerfile":      true,
+		"deploy/api/src/main.go":     true,
+		"deploy/api/conf/prod.yaml":  true,
+		"deploy/api/README.md":       false,
+		"deploy/api/srcfoo/other.go": false,
+	} {
+		if got := img.Includes(file); got != want {
+			t.Errorf("Includes(%s) = %v, want %v", file, got, want)
+		}
+	}
+}
@@ -14,10 +14,18 @@ type Cache[T any] struct {
 }
 
 type entry[T any] struct {
-	value     T
-	cachedAt  time.Time
-	mtimeHash string
-	lastUsed  time.Time
+	value    T
+	cachedAt time.Time
+	hashes   Hashes
+	lastUsed time.Time
+}
+
+// Hashes are the validators stored with an entry: a hash of source file
+// mtimes, which is cheap to recompute, and optionally a hash of their
+// contents, which survives mtime churn.
+type Hashes struct {
+	Mtime   string
+	Content string
 }
 
 // New creates a cache with the given TTL and max entries.
@@ -34,6 +42,14 @@ func New[T any](ttl time.Duration, maxSize int) *Cache[T] {
 
 // Get returns the cached value if the key matches, mtime matches, and TTL hasn't expired.
 func (c *Cache[T]) Get(key string, mtimeHash string) (T, bool) {
+	return c.GetValid(key, func(h *Hashes) bool { return h.Mtime == mtimeHash })
+}
+
+// GetValid returns the cached value if the TTL hasn't expired and valid
+// accepts the entry's stored hashes. valid may update them, for example to
+// record a new mtime hash after a content hash confirmed the entry.
+// Rejected entries are removed.
+func (c *Cache[T]) GetValid(key string, valid func(h *Hashes) bool) (T, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -42,7 +58,7 @@ func (c *Cache[T]) Get(key string, mtimeHash string) (T, bool) {
 		var zero T
 		return zero, false
 	}
-	if e.mtimeHash != mtimeHash || time.Since(e.cachedAt) > c.ttl {
+	if time.Since(e.cachedAt) > c.ttl || !valid(&e.hashes) {
 		delete(c.entries, key)
 		var zero T
 		return zero, false
@@ -53,6 +69,12 @@ func (c *Cache[T]) Get(key string, mtimeHash string) (T, bool) {
 
 // Put stores a value, evicting the LRU entry if at capacity.
 func (c *Cache[T]) Put(key string, mtimeHash string, value T) {
+	c.PutHashes(key, Hashes{Mtime: mtimeHash}, value)
+}
+
+// PutHashes stores a value with its validators, evicting the LRU entry if
+// at capacity.
+func (c *Cache[T]) PutHashes(key string, hashes Hashes, value T) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -64,10 +86,10 @@ func (c *Cache[T]) Put(key string, mtimeHash string, value T) {
 	}
 
 	c.entries[key] = &entry[T]{
-		value:     value,
-		cachedAt:  now,
-		mtimeHash: mtimeHash,
-		lastUsed:  now,
+		value:    value,
+		cachedAt: now,
+		hashes:   hashes,
+		lastUsed: now,
 	}
 }
 
@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// StaleLock is how old a lock file may get before it is assumed to belong
+// to a process that died while holding it.
+const StaleLock = 2 * time.Minute
+
+// lockPoll is how often Lock rechecks a lock held by another process.
+const lockPoll = 50 * time.Millisecond
+
+// Shared is a cache directory several processes read and write, so
+// concurrent server instances reuse each other's results. Each key is
+// stored as <sha256(key)>.json, written to a temp file and renamed into
+// place so readers never see a partial entry. <sha256(key)>.lock, created
+// exclusively, marks a key being computed.
+type Shared[T any] struct {
+	dir string
+}
+
+type sharedEntry[T any] struct {
+	Key      string    `json:"key"`
+	CachedAt time.Time `json:"cached_at"`
+	Value    T         `json:"value"`
+}
+
+// NewShared opens dir as a shared cache, creating it if needed.
+func NewShared[T any](dir string) (*Shared[T], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Shared[T]{dir: dir}, nil
+}
+
+// Dir returns the cache directory.
+func (s *Shared[T]) Dir() string {
+	return s.dir
+}
+
+func (s *Shared[T]) path(key, ext string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+ext)
+}
+
+// Get returns the value stored for key if it is younger than ttl.
+func (s *Shared[T]) Get(key string, ttl time.Duration) (T, bool) {
+	var zero T
+	data, err := os.ReadFile(s.path(key, ".json"))
+	if err != nil {
+		return zero, false
+	}
+	var e sharedEntry[T]
+	// The stored key guards against hash collisions and foreign files.
+	if err := json.Unmarshal(data, &e); err != nil || e.Key != key || time.Since(e.CachedAt) > ttl {
+		return zero, false
+	}
+	return e.Value, true
+}
+
+// Put stores value for key.
+func (s *Shared[T]) Put(key string, value T) error {
+	data, err := json.Marshal(sharedEntry[T]{Key: key, CachedAt: time.Now(), Value: value})
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), s.path(key, ".json")); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// Lock takes key's lock file, waiting up to wait while another process
+// holds it. The returned release func is always safe to call; acquired is
+// false when the wait ran out or ctx was cancelled, in which case the
+// caller computes the value without the lock.
+func (s *Shared[T]) Lock(ctx context.Context, key string, wait time.Duration) (release func(), acquired bool) {
+	lock := s.path(key, ".lock")
+	deadline := time.Now().Add(wait)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }, true
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return func() {}, false
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > StaleLock {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return func() {}, false
+		}
+		select {
+		case <-ctx.Done():
+			return func() {}, false
+		case <-time.After(lockPoll):
+		}
+	}
+}
+
+// Prune removes entries and temp files older than maxAge and stale locks.
+func (s *Shared[T]) Prune(maxAge time.Duration) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		limit := maxAge
+		switch {
+		case strings.HasSuffix(name, ".lock"):
+			limit = StaleLock
+		case strings.HasSuffix(name, ".json"), strings.HasPrefix(name, ".tmp-"):
+		default:
+			continue
+		}
+		if info, err := e.Info(); err == nil && time.Since(info.ModTime()) > limit {
+			os.Remove(filepath.Join(s.dir, name))
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestShared_GetPut(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewShared[map[string]any](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.Get("k", time.Minute); ok {
+		t.Error("expected miss on empty cache")
+	}
+	if err := a.Put("k", map[string]any{"n": 1.0}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second handle on the same directory stands in for another process.
+	b, _ := NewShared[map[string]any](dir)
+	if v, ok := b.Get("k", time.Minute); !ok || v["n"] != 1.0 {
+		t.Errorf("Get = %v, %v", v, ok)
+	}
+	if _, ok := b.Get("k", 0); ok {
+		t.Error("expected miss past the TTL")
+	}
+	if _, ok := b.Get("other", time.Minute); ok {
+		t.Error("expected miss for another key")
+	}
+}
+
+func TestShared_Lock(t *testing.T) {
+	s, _ := NewShared[string](t.TempDir())
+	ctx := context.Background()
+
+	release, ok := s.Lock(ctx, "k", 0)
+	if !ok {
+		t.Fatal("first Lock not acquired")
+	}
+	if _, ok := s.Lock(ctx, "k", 120*time.Millisecond); ok {
+		t.Error("second Lock acquired while the first is held")
+	}
+	release()
+	if release, ok := s.Lock(ctx, "k", 0); !ok {
+		t.Error("Lock not acquired after release")
+	} else {
+		release()
+	}
+
+	// A lock left behind by a dead process is broken once it is stale.
+	lock := s.path("k", ".lock")
+	if err := os.WriteFile(lock, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * StaleLock)
+	os.Chtimes(lock, old, old)
+	if release, ok := s.Lock(ctx, "k", 0); !ok {
+		t.Error("stale lock not broken")
+	} else {
+		release()
+	}
+}
+
+func TestShared_Prune(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := NewShared[string](dir)
+	s.Put("old", "a")
+	s.Put("new", "b")
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(s.path("old", ".json"), old, old)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o644)
+	os.Chtimes(filepath.Join(dir, "notes.txt"), old, old)
+
+	s.Prune(time.Minute)
+	if _, ok := s.Get("old", time.Hour*2); ok {
+		t.Error("old entry survived Prune")
+	}
+	if _, ok := s.Get("new", time.Minute); !ok {
+		t.Error("new entry pruned")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Error("Prune removed a file it does not own")
+	}
+}
@@ -0,0 +1,221 @@
+// Package ci turns a change_impact result into a merge-gate verdict with
+// JSON or JUnit XML output, for `intermap-mcp ci`.
+package ci
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mistakeknot/intermap/internal/policy"
+	"github.com/mistakeknot/intermap/internal/stats"
+)
+
+// Exit codes returned by `intermap-mcp ci`.
+const (
+	ExitOK      = 0 // gate passed
+	ExitImpact  = 1 // gate failed: impacted tests per FailOn
+	ExitUsage   = 2 // bad flags
+	ExitAnalyze = 3 // analysis could not run
+)
+
+// Gate policies for FailOn.
+const (
+	FailOnMissing = "missing" // impacted tests exist that the change did not touch
+	FailOnAny     = "any"     // any impacted test at all
+	FailOnNone    = "none"    // report only
+)
+
+// Report is the gate verdict.
+type Report struct {
+	Project       string   `json:"project"`
+	Base          string   `json:"base"`
+	FailOn        string   `json:"fail_on"`
+	ChangedFiles  []string `json:"changed_files"`
+	AffectedTests []string `json:"affected_tests"`
+	ChangedTests  []string `json:"changed_tests"`
+	// MissingTests are affected tests that are not in the changed test set.
+	MissingTests []string `json:"missing_tests"`
+	TestCommand  string   `json:"test_command,omitempty"`
+	// Policy are the policy rules the change violates; see ApplyPolicy.
+	Policy   []policy.Violation `json:"policy,omitempty"`
+	ExitCode int                `json:"exit_code"`
+}
+
+// Evaluate classifies affected tests against the changed files and applies
+// the failOn policy.
+func Evaluate(project, base, failOn string, changedFiles, affectedTests []string, testCommand string) (*Report, error) {
+	switch failOn {
+	case "":
+		failOn = FailOnMissing
+	case FailOnMissing, FailOnAny, FailOnNone:
+	default:
+		return nil, fmt.Errorf("unknown fail-on policy %q (want missing, any, or none)", failOn)
+	}
+
+	r := &Report{
+		Project:       project,
+		Base:          base,
+		FailOn:        failOn,
+		ChangedFiles:  nonNil(changedFiles),
+		AffectedTests: nonNil(affectedTests),
+		ChangedTests:  []string{},
+		MissingTests:  []string{},
+		TestCommand:   testCommand,
+	}
+	changed := make(map[string]bool)
+	for _, f := range changedFiles {
+		f = filepath.ToSlash(filepath.Clean(f))
+		if stats.IsTestFile(f) {
+			changed[f] = true
+			r.ChangedTests = append(r.ChangedTests, f)
+		}
+	}
+	for _, t := range affectedTests {
+		if !changed[filepath.ToSlash(filepath.Clean(t))] {
+			r.MissingTests = append(r.MissingTests, t)
+		}
+	}
+	sort.Strings(r.ChangedTests)
+	sort.Strings(r.MissingTests)
+
+	switch {
+	case failOn == FailOnAny && len(r.AffectedTests) > 0,
+		failOn == FailOnMissing && len(r.MissingTests) > 0:
+		r.ExitCode = ExitImpact
+	}
+	return r, nil
+}
+
+// EvaluateImpact is Evaluate over a raw change_impact result.
+func EvaluateImpact(project, base, failOn string, impact map[string]any) (*Report, error) {
+	cmd, ok := impact["test_command"].(string)
+	if !ok {
+		cmd = strings.Join(stringSlice(impact["test_command"]), " ")
+	}
+	return Evaluate(project, base, failOn, stringSlice(impact["changed_files"]), stringSlice(impact["affected_tests"]), cmd)
+}
+
+// ApplyPolicy evaluates rules against the report's changed files and
+// affected tests. A violated block rule fails the gate regardless of
+// FailOn.
+func (r *Report) ApplyPolicy(rules []policy.Rule) error {
+	violations, err := policy.Evaluate(rules, policy.Facts{Changed: r.ChangedFiles, Affected: r.AffectedTests})
+	if err != nil {
+		return err
+	}
+	r.Policy = violations
+	if policy.Blocked(violations) {
+		r.ExitCode = ExitImpact
+	}
+	return nil
+}
+
+// stringSlice converts a decoded JSON array to strings, dropping non-strings.
+func stringSlice(v any) []string {
+	items, _ := v.([]any)
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Encode renders the report as "json" or "junit".
+func (r *Report) Encode(format string) ([]byte, error) {
+	switch format {
+	case "", "json":
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "junit":
+		return r.JUnit()
+	default:
+		return nil, fmt.Errorf("unknown output format %q (want json or junit)", format)
+	}
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+// JUnit renders one testcase per affected test. Under the active policy a
+// test that trips the gate is a failure; with FailOnNone every test is
+// reported as skipped (informational). Violated policy rules follow as
+// testcases of class "policy": block rules fail, warn rules are skipped.
+func (r *Report) JUnit() ([]byte, error) {
+	missing := make(map[string]bool, len(r.MissingTests))
+	for _, t := range r.MissingTests {
+		missing[t] = true
+	}
+	suite := junitSuite{Name: "intermap.change_impact", Tests: len(r.AffectedTests)}
+	for _, t := range r.AffectedTests {
+		c := junitCase{Name: t, Classname: "change_impact"}
+		switch {
+		case r.FailOn == FailOnNone:
+			c.Skipped = &struct{}{}
+			suite.Skipped++
+		case r.FailOn == FailOnAny:
+			c.Failure = &junitFailure{Message: "impacted by change", Text: fmt.Sprintf("%s is impacted by changes since %s", t, r.Base)}
+			suite.Failures++
+		case missing[t]:
+			c.Failure = &junitFailure{Message: "impacted test not updated", Text: fmt.Sprintf("%s is impacted by changes since %s but was not modified", t, r.Base)}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	for _, v := range r.Policy {
+		c := junitCase{Name: v.Name, Classname: "policy"}
+		if v.Action == policy.Block {
+			c.Failure = &junitFailure{Message: "policy violated", Text: v.Rule + "\n" + strings.Join(v.Files, "\n")}
+			suite.Failures++
+		} else {
+			c.Skipped = &struct{}{}
+			suite.Skipped++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return nil, fmt.Errorf("encode junit: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
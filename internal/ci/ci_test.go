@@ -0,0 +1,90 @@
+package ci
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermap/internal/policy"
+)
+
+func TestEvaluate(t *testing.T) {
+	changed := []string{"pkg/parse.py", "tests/test_parse.py"}
+	affected := []string{"tests/test_parse.py", "tests/test_cli.py"}
+
+	for policy, want := range map[string]int{
+		FailOnMissing: ExitImpact,
+		FailOnAny:     ExitImpact,
+		FailOnNone:    ExitOK,
+	} {
+		r, err := Evaluate(".", "main", policy, changed, affected, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.ExitCode != want {
+			t.Errorf("%s: exit %d, want %d", policy, r.ExitCode, want)
+		}
+		if len(r.MissingTests) != 1 || r.MissingTests[0] != "tests/test_cli.py" {
+			t.Errorf("%s: unexpected missing tests %v", policy, r.MissingTests)
+		}
+	}
+
+	r, _ := Evaluate(".", "main", "", changed, []string{"tests/test_parse.py"}, "")
+	if r.ExitCode != ExitOK {
+		t.Errorf("all impacted tests changed: exit %d, want 0", r.ExitCode)
+	}
+
+	if _, err := Evaluate(".", "main", "sometimes", nil, nil, ""); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestJUnit(t *testing.T) {
+	r, _ := Evaluate(".", "main", FailOnMissing,
+		[]string{"tests/test_parse.py"}, []string{"tests/test_parse.py", "tests/test_cli.py"}, "")
+	data, err := r.Encode("junit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite junitSuite
+	if err := xml.Unmarshal(data, &suite); err != nil {
+		t.Fatalf("invalid JUnit XML: %v", err)
+	}
+	if suite.Tests != 2 || suite.Failures != 1 {
+		t.Errorf("expected 2 tests/1 failure, got %d/%d", suite.Tests, suite.Failures)
+	}
+	if !strings.Contains(string(data), `name="tests/test_cli.py"`) {
+		t.Errorf("missing testcase for test_cli.py:\n%s", data)
+	}
+}
+
+func TestApplyPolicy(t *testing.T) {
+	rules, err := policy.Parse([]string{
+		`block "parser needs tests": changed("pkg/**") and not tests("tests/test_parse*")`,
+		`warn "cli impacted": affected("tests/test_cli.py")`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := Evaluate(".", "main", FailOnNone, []string{"pkg/parse.py"}, []string{"tests/test_cli.py"}, "")
+	if err := r.ApplyPolicy(rules); err != nil {
+		t.Fatal(err)
+	}
+	if r.ExitCode != ExitImpact || len(r.Policy) != 2 {
+		t.Errorf("exit %d, policy %+v", r.ExitCode, r.Policy)
+	}
+	data, _ := r.Encode("junit")
+	var suite junitSuite
+	if err := xml.Unmarshal(data, &suite); err != nil {
+		t.Fatal(err)
+	}
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Skipped != 2 {
+		t.Errorf("tests/failures/skipped = %d/%d/%d:\n%s", suite.Tests, suite.Failures, suite.Skipped, data)
+	}
+
+	// Only warnings: the gate passes.
+	r, _ = Evaluate(".", "main", FailOnNone, []string{"pkg/parse.py", "tests/test_parse.py"}, []string{"tests/test_cli.py"}, "")
+	if err := r.ApplyPolicy(rules); err != nil || r.ExitCode != ExitOK || len(r.Policy) != 1 {
+		t.Errorf("warn only: exit %d, policy %+v, %v", r.ExitCode, r.Policy, err)
+	}
+}
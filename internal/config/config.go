@@ -0,0 +1,313 @@
+// Package config loads optional user configuration for intermap.
+//
+// Configuration is a single JSON file. Its location is taken from
+// INTERMAP_CONFIG, falling back to <user config dir>/intermap/config.json.
+// A missing file is not an error: every field has a usable zero value.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Config is the top-level intermap configuration.
+type Config struct {
+	Languages    LanguageConfig     `json:"languages"`
+	Registry     RegistryConfig     `json:"registry"`
+	GraphSink    GraphSinkConfig    `json:"graph_sink"`
+	Webhooks     []Webhook          `json:"webhooks,omitempty"`
+	AgentHistory AgentHistoryConfig `json:"agent_history"`
+	Coordination CoordinationConfig `json:"coordination"`
+	Licenses     LicenseConfig      `json:"licenses"`
+	Semantic     SemanticConfig     `json:"semantic_search"`
+	ResultCache  ResultCacheConfig  `json:"result_cache"`
+	Budgets      BudgetConfig       `json:"budgets"`
+	Priority     PriorityConfig     `json:"priority"`
+	Middleware   MiddlewareConfig   `json:"middleware"`
+	Schemas      SchemaConfig       `json:"schemas"`
+	HTTP         HTTPConfig         `json:"http"`
+	Watch        WatchConfig        `json:"watch"`
+	Hooks        HooksConfig        `json:"hooks"`
+	Reindex      ReindexConfig      `json:"reindex"`
+}
+
+// WatchConfig tunes `intermap-mcp watch`.
+type WatchConfig struct {
+	// Interval between checks as a Go duration; default "3s".
+	Interval string `json:"interval,omitempty"`
+	// Agent is the watching developer's or agent's intermute ID or name;
+	// its own reservations never alert.
+	Agent string `json:"agent,omitempty"`
+	// Notify is a command run for each alert, e.g. ["notify-send",
+	// "intermap", "{message}"]. Arguments may use {project}, {file},
+	// {status}, {agents}, and {message}.
+	Notify []string `json:"notify,omitempty"`
+}
+
+// ReindexConfig schedules background re-indexing of hot projects, with
+// drift detection between runs.
+type ReindexConfig struct {
+	Projects []ReindexProject `json:"projects,omitempty"`
+	// FanIn is the caller count at which a symbol counts as high fan-in;
+	// default 10.
+	FanIn int `json:"fan_in,omitempty"`
+	// Dir holds the previous snapshots; default <user cache>/intermap/drift.
+	Dir string `json:"dir,omitempty"`
+}
+
+// ReindexProject is one scheduled project.
+type ReindexProject struct {
+	Path string `json:"path"`
+	// Language defaults to the detected project language.
+	Language string `json:"language,omitempty"`
+	// Every is a Go duration or @hourly, @daily, or @weekly; default
+	// @hourly.
+	Every string `json:"every,omitempty"`
+}
+
+// HooksConfig is the policy for the git hooks `intermap-mcp hooks
+// install` writes. Each check is "block", "warn", or "off".
+type HooksConfig struct {
+	// Impact flags impacted tests the change does not touch; default warn.
+	Impact string `json:"impact,omitempty"`
+	// Reservations flags changed files other agents have reserved;
+	// default block.
+	Reservations string `json:"reservations,omitempty"`
+	// Secrets flags credentials in added lines; default block.
+	Secrets string `json:"secrets,omitempty"`
+}
+
+// HTTPConfig serves MCP over streamable HTTP instead of stdio, for teams
+// sharing one server.
+type HTTPConfig struct {
+	// Addr is the listen address, e.g. "127.0.0.1:8765". Empty serves stdio.
+	Addr string `json:"addr,omitempty"`
+	// Keys are the API keys clients must present. With none, every client
+	// may use every project.
+	Keys []AccessKey `json:"keys,omitempty"`
+	// REST also serves the tools as a plain JSON API (internal/rest), with
+	// the same keys and scopes.
+	REST bool `json:"rest,omitempty"`
+}
+
+// AccessKey is an API key and the projects its sessions may use.
+type AccessKey struct {
+	Name string `json:"name"`
+	// KeyEnv names the environment variable holding the key, so secrets
+	// stay out of the config file.
+	KeyEnv string `json:"key_env"`
+	// Projects are project names, group/name paths, globs over either, or
+	// absolute project paths. Empty allows every project.
+	Projects []string `json:"projects,omitempty"`
+	// Intermute restricts each session to the project of the intermute
+	// agent named in its X-Intermute-Agent header.
+	Intermute bool `json:"intermute,omitempty"`
+}
+
+// MiddlewareConfig tunes the pipeline every tool handler runs through.
+type MiddlewareConfig struct {
+	// Enable turns stages on or off by name ({"audit": true}). Audit and
+	// provenance are off by default; the rest are on.
+	Enable map[string]bool `json:"enable,omitempty"`
+	// RateLimit caps each session's tool calls per minute; 0 is unlimited.
+	RateLimit int `json:"rate_limit_per_minute,omitempty"`
+}
+
+// SchemaConfig controls how sidecar results are checked against their
+// commands' schemas.
+type SchemaConfig struct {
+	// Strict fails a call whose result does not match; by default the
+	// first mismatch per command is logged and the result returned.
+	Strict bool `json:"strict,omitempty"`
+}
+
+// PriorityConfig tunes how queued analysis commands share the Python
+// sidecar. Classes are "interactive", "normal", and "background".
+type PriorityConfig struct {
+	// Tools assigns tools to classes ({"code_search": "background"}),
+	// overriding the built-in assignments.
+	Tools map[string]string `json:"tools,omitempty"`
+	// Weights is each class's share of turns while several are waiting;
+	// default interactive 8, normal 4, background 1.
+	Weights map[string]int `json:"weights,omitempty"`
+}
+
+// BudgetConfig caps the analysis cost each MCP session may incur, so one
+// agent cannot monopolize a shared server. Zero fields are unlimited.
+type BudgetConfig struct {
+	Calls int `json:"calls,omitempty"`
+	// CPUSeconds is CPU time spent in the Python sidecar.
+	CPUSeconds    float64 `json:"cpu_seconds,omitempty"`
+	FilesParsed   int     `json:"files_parsed,omitempty"`
+	BytesReturned int     `json:"bytes_returned,omitempty"`
+}
+
+// ResultCacheConfig tunes the cache of sidecar analysis results, which are
+// reused while a project's source file mtimes are unchanged.
+type ResultCacheConfig struct {
+	// TTL overrides per-tool lifetimes as Go durations keyed by tool name
+	// ({"code_structure": "1h"}); "0" disables caching for that tool.
+	TTL map[string]string `json:"ttl,omitempty"`
+	// MaxEntries bounds the results kept per tool; default 32.
+	MaxEntries int `json:"max_entries,omitempty"`
+	// SharedDir is a directory shared by intermap processes (one per agent
+	// session) so they reuse each other's results; empty keeps each
+	// process's cache private.
+	SharedDir string `json:"shared_dir,omitempty"`
+	// Validator decides when a cached result is stale: "mtime" (default),
+	// "content" (hash file contents), or "auto" (mtimes, then contents).
+	Validator string `json:"validator,omitempty"`
+}
+
+// SemanticConfig enables the semantic_search tool, which keeps code
+// embeddings in a local SQLite database. An empty DB leaves it disabled.
+type SemanticConfig struct {
+	DB string `json:"db,omitempty"`
+	// Provider is "openai" (the default: any OpenAI-compatible embeddings
+	// endpoint, including Ollama's) or "local" (sentence-transformers in
+	// the Python sidecar).
+	Provider string `json:"provider,omitempty"`
+	// Endpoint is the embeddings URL; default
+	// "http://localhost:11434/v1/embeddings" (a local Ollama).
+	Endpoint string `json:"endpoint,omitempty"`
+	// Model is the embedding model; default "nomic-embed-text" for openai
+	// and "all-MiniLM-L6-v2" for local.
+	Model string `json:"model,omitempty"`
+	// APIKeyEnv names the environment variable holding the endpoint's
+	// bearer token.
+	APIKeyEnv string `json:"api_key_env,omitempty"`
+}
+
+// LicenseConfig is the policy license_check applies to dependencies.
+// Entries are SPDX license identifiers; see license.Policy for matching.
+type LicenseConfig struct {
+	// Allow, when set, is the only licenses permitted.
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+	// Exceptions exempts packages by name ("github.com/org/lib", "left-pad").
+	Exceptions []string `json:"exceptions,omitempty"`
+	// Unknown is "warn" (default), "deny", or "allow" for packages whose
+	// license cannot be determined.
+	Unknown string `json:"unknown,omitempty"`
+}
+
+// CoordinationConfig selects where agents and reservations come from.
+type CoordinationConfig struct {
+	// Provider is "intermute" (the default, at INTERMUTE_URL) or "file".
+	Provider string `json:"provider,omitempty"`
+	// Dir is the file provider's directory; default ".intermap/coordination"
+	// under the working directory.
+	Dir string `json:"dir,omitempty"`
+	// PollInterval paces change subscriptions as a Go duration; default "10s".
+	PollInterval string `json:"poll_interval,omitempty"`
+	// Announce registers the intermap server itself as an agent and keeps
+	// it listed with a heartbeat every HeartbeatInterval (default "30s").
+	Announce          bool   `json:"announce,omitempty"`
+	HeartbeatInterval string `json:"heartbeat_interval,omitempty"`
+
+	// Intermute HTTP tuning. Timeout bounds each request attempt as a Go
+	// duration (default "15s"). Retries is how many times idempotent
+	// requests are retried when intermute is unavailable; default 2,
+	// negative disables. MaxIdleConns bounds pooled connections per host;
+	// default 8.
+	Timeout      string `json:"timeout,omitempty"`
+	Retries      int    `json:"retries,omitempty"`
+	MaxIdleConns int    `json:"max_idle_conns,omitempty"`
+}
+
+// AgentHistoryConfig enables periodic agent_map snapshots in a local SQLite
+// database for the agent_timeline tool. An empty DB leaves it disabled.
+type AgentHistoryConfig struct {
+	DB string `json:"db,omitempty"`
+	// Interval between snapshots as a Go duration; default "5m".
+	Interval string `json:"interval,omitempty"`
+	// RetainDays prunes older snapshots; default 30, negative keeps all.
+	RetainDays int `json:"retain_days,omitempty"`
+}
+
+// RegistryConfig tunes workspace scanning.
+type RegistryConfig struct {
+	// ScanWorkers bounds how many directories are read concurrently during
+	// a scan; 0 uses the built-in default and 1 scans serially.
+	ScanWorkers int `json:"scan_workers,omitempty"`
+	// MaxDepth is how many directory levels below the root are searched
+	// for projects; 0 uses the built-in default (4).
+	MaxDepth int `json:"max_depth,omitempty"`
+}
+
+// Webhook subscribes a URL to analysis-completion events.
+type Webhook struct {
+	URL string `json:"url"`
+	// Events limits delivery to these event names ("change_impact",
+	// "index_update", "reservation_conflict"); empty means all events.
+	Events  []string          `json:"events,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Secret, if set, signs each payload with HMAC-SHA256.
+	Secret string `json:"secret,omitempty"`
+}
+
+// GraphSinkConfig enables mirroring analysis results into Neo4j. An empty
+// URL leaves the sink disabled.
+type GraphSinkConfig struct {
+	// URL is the Neo4j HTTP endpoint, e.g. "http://localhost:7474".
+	URL      string `json:"url,omitempty"`
+	Database string `json:"database,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// LanguageConfig extends project language detection.
+type LanguageConfig struct {
+	// Markers are checked before the built-in marker table. File may be an
+	// exact file name ("mix.exs") or a glob ("*.tf").
+	Markers []Marker `json:"markers,omitempty"`
+
+	// Extensions maps file extensions (".ex") to languages and is used as a
+	// fallback when no marker matches.
+	Extensions map[string]string `json:"extensions,omitempty"`
+}
+
+// Marker maps a marker file (or glob) in a project root to a language.
+type Marker struct {
+	File     string `json:"file"`
+	Language string `json:"language"`
+}
+
+// Path returns the config file location.
+func Path() string {
+	if p := os.Getenv("INTERMAP_CONFIG"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "intermap", "config.json")
+}
+
+// Load reads the config from Path(). A missing file yields an empty Config.
+func Load() (*Config, error) {
+	return LoadFile(Path())
+}
+
+// LoadFile reads the config from path. A missing file yields an empty Config.
+func LoadFile(path string) (*Config, error) {
+	cfg := &Config{}
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("read config: %w", err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return &Config{}, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFile_Missing(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "nope.json"))
+	if err != nil {
+		t.Fatalf("expected no error for missing file, got %v", err)
+	}
+	if len(cfg.Languages.Markers) != 0 {
+		t.Errorf("expected empty config, got %+v", cfg)
+	}
+}
+
+func TestLoadFile_Languages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"languages":{"markers":[{"file":"mix.exs","language":"elixir"}],"extensions":{".tf":"terraform"}}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if len(cfg.Languages.Markers) != 1 || cfg.Languages.Markers[0].Language != "elixir" {
+		t.Errorf("unexpected markers: %+v", cfg.Languages.Markers)
+	}
+	if cfg.Languages.Extensions[".tf"] != "terraform" {
+		t.Errorf("unexpected extensions: %+v", cfg.Languages.Extensions)
+	}
+}
+
+func TestLoadFile_Integrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"registry":{"scan_workers":4,"max_depth":3},"agent_history":{"db":"/tmp/h.db","interval":"1m"},"coordination":{"provider":"file","dir":"/ws/.coord","announce":true},"graph_sink":{"url":"http://localhost:7474","user":"neo4j"},"webhooks":[{"url":"http://ci/hook","events":["change_impact"]}],"licenses":{"deny":["GPL-3.0","AGPL-*"],"unknown":"deny"},"semantic_search":{"db":"/tmp/v.db","model":"nomic-embed-text","api_key_env":"EMBED_KEY"}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if cfg.GraphSink.URL != "http://localhost:7474" || cfg.GraphSink.User != "neo4j" {
+		t.Errorf("unexpected graph sink: %+v", cfg.GraphSink)
+	}
+	if len(cfg.Webhooks) != 1 || cfg.Webhooks[0].Events[0] != "change_impact" {
+		t.Errorf("unexpected webhooks: %+v", cfg.Webhooks)
+	}
+	if cfg.AgentHistory.DB != "/tmp/h.db" || cfg.AgentHistory.Interval != "1m" {
+		t.Errorf("unexpected agent history config: %+v", cfg.AgentHistory)
+	}
+	if cfg.Coordination.Provider != "file" || cfg.Coordination.Dir != "/ws/.coord" || !cfg.Coordination.Announce {
+		t.Errorf("unexpected coordination config: %+v", cfg.Coordination)
+	}
+	if cfg.Registry.ScanWorkers != 4 {
+		t.Errorf("unexpected registry config: %+v", cfg.Registry)
+	}
+	if len(cfg.Licenses.Deny) != 2 || cfg.Licenses.Unknown != "deny" {
+		t.Errorf("unexpected licenses config: %+v", cfg.Licenses)
+	}
+	if cfg.Semantic.DB != "/tmp/v.db" || cfg.Semantic.APIKeyEnv != "EMBED_KEY" {
+		t.Errorf("unexpected semantic search config: %+v", cfg.Semantic)
+	}
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestPath_EnvOverride(t *testing.T) {
+	t.Setenv("INTERMAP_CONFIG", "/tmp/custom.json")
+	if got := Path(); got != "/tmp/custom.json" {
+		t.Errorf("expected env override, got %s", got)
+	}
+}
+
+func TestLoadWorkspace(t *testing.T) {
+	root := t.TempDir()
+	ws, err := LoadWorkspace(root)
+	if err != nil || len(ws.ToolDefaults) != 0 {
+		t.Fatalf("missing file: %+v, %v", ws, err)
+	}
+
+	data := "tool_defaults:\n  impact_analysis:\n    max_depth: 5\n  code_structure:\n    language: go\n    exclude: [vendor, testdata]\npolicy:\n  - 'block \"needs tests\": changed(\"api/**\") and not tests()'\n"
+	if err := os.WriteFile(filepath.Join(root, WorkspaceFile), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ws, err = LoadWorkspace(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := ws.ToolDefaults["impact_analysis"]["max_depth"]; d != 5.0 {
+		t.Errorf("max_depth = %#v, want float64 5", d)
+	}
+	if ex, ok := ws.ToolDefaults["code_structure"]["exclude"].([]any); !ok || len(ex) != 2 {
+		t.Errorf("exclude = %#v", ws.ToolDefaults["code_structure"]["exclude"])
+	}
+	if len(ws.Policy) != 1 || ws.Policy[0] != `block "needs tests": changed("api/**") and not tests()` {
+		t.Errorf("policy = %q", ws.Policy)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, WorkspaceFile), []byte("tool_defaults: [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadWorkspace(root); err == nil {
+		t.Error("invalid YAML: no error")
+	}
+}
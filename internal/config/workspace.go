@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkspaceFile is the per-workspace configuration file, read from the
+// workspace root. Unlike the user config it is meant to be checked in, so a
+// team shares it.
+const WorkspaceFile = ".intermap.yaml"
+
+// Workspace is the configuration in WorkspaceFile.
+type Workspace struct {
+	// ToolDefaults are per-tool argument presets, keyed by tool then
+	// argument name ({"impact_analysis": {"max_depth": 5}}). Explicit
+	// arguments override them.
+	ToolDefaults map[string]map[string]any `yaml:"tool_defaults" json:"tool_defaults"`
+	// Policy are change-gating rules (internal/policy), one per entry,
+	// for evaluate_policy and `intermap-mcp ci`.
+	Policy []string `yaml:"policy" json:"policy"`
+}
+
+// LoadWorkspace reads WorkspaceFile from root. A missing file yields an
+// empty Workspace. Values are normalized to what JSON decoding produces
+// (float64 numbers, []any, map[string]any), as tool arguments are.
+func LoadWorkspace(root string) (*Workspace, error) {
+	ws := &Workspace{}
+	path := filepath.Join(root, WorkspaceFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ws, nil
+		}
+		return ws, fmt.Errorf("read workspace config: %w", err)
+	}
+	var raw Workspace
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return ws, fmt.Errorf("parse %s: %w", path, err)
+	}
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return ws, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := json.Unmarshal(normalized, ws); err != nil {
+		return &Workspace{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return ws, nil
+}
@@ -0,0 +1,488 @@
+// Package containers builds the deployment topology of a workspace from
+// Dockerfiles, docker-compose files, and Kubernetes workload manifests, and
+// links each service to the project whose code it packages.
+//
+// Only environment variable names are reported, never values, since
+// manifests routinely carry credentials.
+package containers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mistakeknot/intermap/registry"
+	"gopkg.in/yaml.v3"
+)
+
+// Service sources.
+const (
+	SourceCompose    = "compose"
+	SourceKubernetes = "kubernetes"
+)
+
+// maxManifestSize bounds the YAML files probed for Kubernetes manifests.
+const maxManifestSize = 1 << 20
+
+// Service is one deployable unit: a compose service or a Kubernetes
+// container. Paths are relative to the scanned root.
+type Service struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	// Kind is "service" for compose, or the Kubernetes workload kind.
+	Kind  string `json:"kind"`
+	File  string `json:"file"`
+	Image string `json:"image,omitempty"`
+	// BuildContext and Dockerfile are set for compose services with a build
+	// section.
+	BuildContext string   `json:"build_context,omitempty"`
+	Dockerfile   string   `json:"dockerfile,omitempty"`
+	Ports        []string `json:"ports,omitempty"`
+	Env          []string `json:"env,omitempty"`
+	// EnvFrom lists env files (compose) or configMapRef/secretRef sources
+	// (Kubernetes).
+	EnvFrom   []string `json:"env_from,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"`
+	Project   string   `json:"project,omitempty"`
+}
+
+// Dockerfile summarizes one Dockerfile.
+type Dockerfile struct {
+	File       string   `json:"file"`
+	BaseImages []string `json:"base_images"`
+	Expose     []string `json:"expose,omitempty"`
+	Env        []string `json:"env,omitempty"`
+	Args       []string `json:"args,omitempty"`
+	Project    string   `json:"project,omitempty"`
+}
+
+// Map is the container topology of a root directory.
+type Map struct {
+	Services    []Service    `json:"services"`
+	Dockerfiles []Dockerfile `json:"dockerfiles"`
+}
+
+// skipDirs are never descended into.
+var skipDirs = map[string]bool{
+	"vendor": true, "node_modules": true, "__pycache__": true, "venv": true,
+	"testdata": true, "dist": true, "target": true,
+}
+
+// Scan walks root for Dockerfiles, compose files, and Kubernetes manifests.
+// Files that fail to parse (templated Helm charts, for one) are skipped.
+func Scan(root string) (*Map, error) {
+	m := &Map{Services: []Service{}, Dockerfiles: []Dockerfile{}}
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != root && (strings.HasPrefix(name, ".") || skipDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		switch {
+		case isDockerfile(name):
+			if data, err := os.ReadFile(p); err == nil {
+				m.Dockerfiles = append(m.Dockerfiles, parseDockerfile(rel, data))
+			}
+		case isComposeFile(name):
+			if data, err := os.ReadFile(p); err == nil {
+				m.Services = append(m.Services, parseCompose(rel, data)...)
+			}
+		case strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml"):
+			if info, err := d.Info(); err != nil || info.Size() > maxManifestSize {
+				return nil
+			}
+			if data, err := os.ReadFile(p); err == nil {
+				m.Services = append(m.Services, parseKubernetes(rel, data)...)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", root, err)
+	}
+	sort.Slice(m.Services, func(i, j int) bool {
+		if m.Services[i].File != m.Services[j].File {
+			return m.Services[i].File < m.Services[j].File
+		}
+		return m.Services[i].Name < m.Services[j].Name
+	})
+	sort.Slice(m.Dockerfiles, func(i, j int) bool { return m.Dockerfiles[i].File < m.Dockerfiles[j].File })
+	return m, nil
+}
+
+// Link sets Project on services and Dockerfiles. Dockerfiles and built
+// compose services belong to the project containing their Dockerfile;
+// image-only services are matched by image repository, first against images
+// built by compose services, then by repository base name against project
+// names.
+func (m *Map) Link(root string, projects []registry.Project) {
+	byName := make(map[string]string, len(projects))
+	for _, p := range projects {
+		byName[p.Name] = p.Name
+	}
+	owner := func(rel string) string {
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		best, bestLen := "", -1
+		for _, p := range projects {
+			if (abs == p.Path || strings.HasPrefix(abs, p.Path+string(filepath.Separator))) && len(p.Path) > bestLen {
+				best, bestLen = p.Name, len(p.Path)
+			}
+		}
+		return best
+	}
+
+	for i := range m.Dockerfiles {
+		m.Dockerfiles[i].Project = owner(m.Dockerfiles[i].File)
+	}
+	built := make(map[string]string) // image repository -> project
+	for i := range m.Services {
+		s := &m.Services[i]
+		switch {
+		case s.Dockerfile != "":
+			s.Project = owner(s.Dockerfile)
+		case s.BuildContext != "":
+			s.Project = owner(s.BuildContext)
+		}
+		if s.Project != "" && s.Image != "" {
+			built[ImageRepo(s.Image)] = s.Project
+		}
+	}
+	for i := range m.Services {
+		s := &m.Services[i]
+		if s.Project != "" || s.Image == "" {
+			continue
+		}
+		repo := ImageRepo(s.Image)
+		if p, ok := built[repo]; ok {
+			s.Project = p
+		} else if p, ok := byName[path.Base(repo)]; ok {
+			s.Project = p
+		}
+	}
+}
+
+// BuiltImages maps the image repository of each linked service that builds
+// its own image to the service's project. Call after Link.
+func (m *Map) BuiltImages() map[string]string {
+	built := make(map[string]string)
+	for _, s := range m.Services {
+		if s.Project != "" && s.Image != "" && (s.Dockerfile != "" || s.BuildContext != "") {
+			built[ImageRepo(s.Image)] = s.Project
+		}
+	}
+	return built
+}
+
+// ImageRepo strips the tag and digest from an image reference.
+func ImageRepo(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// --- Dockerfile ---
+
+// isDockerfile matches Dockerfile, Dockerfile.<variant>, and
+// <variant>.Dockerfile.
+func isDockerfile(base string) bool {
+	lower := strings.ToLower(base)
+	return lower == "dockerfile" || strings.HasPrefix(lower, "dockerfile.") || strings.HasSuffix(lower, ".dockerfile")
+}
+
+func parseDockerfile(rel string, data []byte) Dockerfile {
+	df := Dockerfile{File: rel, BaseImages: []string{}}
+	stages := make(map[string]bool)
+	env := make(map[string]bool)
+	args := make(map[string]bool)
+	joined := strings.ReplaceAll(strings.ReplaceAll(string(data), "\\\r\n", " "), "\\\n", " ")
+	for _, line := range strings.Split(joined, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		rest := fields[1:]
+		switch strings.ToUpper(fields[0]) {
+		case "FROM":
+			for len(rest) > 0 && strings.HasPrefix(rest[0], "--") {
+				rest = rest[1:]
+			}
+			if len(rest) == 0 {
+				continue
+			}
+			// FROM <stage> reuses an earlier stage rather than an image.
+			if !stages[strings.ToLower(rest[0])] {
+				df.BaseImages = append(df.BaseImages, rest[0])
+			}
+			if len(rest) >= 3 && strings.EqualFold(rest[1], "AS") {
+				stages[strings.ToLower(rest[2])] = true
+			}
+		case "EXPOSE":
+			df.Expose = append(df.Expose, rest...)
+		case "ENV":
+			if len(rest) >= 2 && !strings.Contains(rest[0], "=") {
+				env[rest[0]] = true // legacy "ENV KEY value"
+				continue
+			}
+			for _, kv := range rest {
+				if k, _, ok := strings.Cut(kv, "="); ok && k != "" {
+					env[k] = true
+				}
+			}
+		case "ARG":
+			k, _, _ := strings.Cut(rest[0], "=")
+			args[k] = true
+		}
+	}
+	df.Env = sortedKeys(env)
+	df.Args = sortedKeys(args)
+	return df
+}
+
+// --- compose ---
+
+func isComposeFile(base string) bool {
+	lower := strings.ToLower(base)
+	ext := path.Ext(lower)
+	if ext != ".yml" && ext != ".yaml" {
+		return false
+	}
+	stem := strings.TrimSuffix(lower, ext)
+	return stem == "compose" || stem == "docker-compose" ||
+		strings.HasPrefix(stem, "compose.") || strings.HasPrefix(stem, "docker-compose.")
+}
+
+func parseCompose(rel string, data []byte) []Service {
+	var doc struct {
+		Services map[string]map[string]any `yaml:"services"`
+	}
+	if yaml.Unmarshal(data, &doc) != nil {
+		return nil
+	}
+	dir := path.Dir(rel)
+	var out []Service
+	for name, spec := range doc.Services {
+		s := Service{Name: name, Source: SourceCompose, Kind: "service", File: rel}
+		s.Image, _ = spec["image"].(string)
+		switch b := spec["build"].(type) {
+		case string:
+			s.BuildContext = path.Join(dir, b)
+		case map[string]any:
+			ctx, _ := b["context"].(string)
+			s.BuildContext = path.Join(dir, ctx)
+			if df, ok := b["dockerfile"].(string); ok {
+				s.Dockerfile = path.Join(s.BuildContext, df)
+			}
+		}
+		if s.BuildContext != "" && s.Dockerfile == "" {
+			s.Dockerfile = path.Join(s.BuildContext, "Dockerfile")
+		}
+		for _, p := range anyList(spec["ports"]) {
+			if long, ok := p.(map[string]any); ok {
+				port := scalar(long["target"])
+				if pub := scalar(long["published"]); pub != "" {
+					port = pub + ":" + port
+				}
+				s.Ports = append(s.Ports, port)
+			} else {
+				s.Ports = append(s.Ports, scalar(p))
+			}
+		}
+		for _, p := range anyList(spec["expose"]) {
+			s.Ports = append(s.Ports, scalar(p))
+		}
+		s.Env = envNames(spec["environment"])
+		for _, f := range anyList(spec["env_file"]) {
+			if long, ok := f.(map[string]any); ok {
+				f = long["path"]
+			}
+			if f := scalar(f); f != "" {
+				s.EnvFrom = append(s.EnvFrom, path.Join(dir, f))
+			}
+		}
+		switch deps := spec["depends_on"].(type) {
+		case []any:
+			for _, d := range deps {
+				s.DependsOn = append(s.DependsOn, scalar(d))
+			}
+		case map[string]any:
+			for d := range deps {
+				s.DependsOn = append(s.DependsOn, d)
+			}
+			sort.Strings(s.DependsOn)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// envNames returns variable names from a compose environment section, which
+// is either a list of KEY=value strings or a mapping.
+func envNames(v any) []string {
+	names := make(map[string]bool)
+	switch env := v.(type) {
+	case []any:
+		for _, e := range env {
+			k, _, _ := strings.Cut(scalar(e), "=")
+			if k != "" {
+				names[k] = true
+			}
+		}
+	case map[string]any:
+		for k := range env {
+			names[k] = true
+		}
+	}
+	return sortedKeys(names)
+}
+
+// --- Kubernetes ---
+
+// podSpecPaths locates the pod spec within each workload kind.
+var podSpecPaths = map[string][]string{
+	"Pod":         {"spec"},
+	"Deployment":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"ReplicaSet":  {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+func parseKubernetes(rel string, data []byte) []Service {
+	var out []Service
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc map[string]any
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return out
+		}
+		if _, ok := doc["apiVersion"].(string); !ok {
+			continue
+		}
+		kind, _ := doc["kind"].(string)
+		specPath, ok := podSpecPaths[kind]
+		if !ok {
+			continue
+		}
+		name, _ := dig(doc, "metadata", "name").(string)
+		podSpec, _ := dig(doc, specPath...).(map[string]any)
+		containers := anyList(podSpec["containers"])
+		for _, c := range containers {
+			cm, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			s := Service{Name: name, Source: SourceKubernetes, Kind: kind, File: rel}
+			if cname, _ := cm["name"].(string); len(containers) > 1 && cname != "" {
+				s.Name = name + "/" + cname
+			}
+			s.Image, _ = cm["image"].(string)
+			for _, p := range anyList(cm["ports"]) {
+				if pm, ok := p.(map[string]any); ok {
+					port := scalar(pm["containerPort"])
+					if proto, _ := pm["protocol"].(string); proto != "" && proto != "TCP" {
+						port += "/" + strings.ToLower(proto)
+					}
+					s.Ports = append(s.Ports, port)
+				}
+			}
+			names := make(map[string]bool)
+			for _, e := range anyList(cm["env"]) {
+				if em, ok := e.(map[string]any); ok {
+					if n, _ := em["name"].(string); n != "" {
+						names[n] = true
+					}
+				}
+			}
+			s.Env = sortedKeys(names)
+			for _, e := range anyList(cm["envFrom"]) {
+				em, _ := e.(map[string]any)
+				for _, ref := range []string{"configMapRef", "secretRef"} {
+					if n, _ := dig(em, ref, "name").(string); n != "" {
+						s.EnvFrom = append(s.EnvFrom, ref+":"+n)
+					}
+				}
+			}
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// --- helpers ---
+
+func dig(v any, keys ...string) any {
+	for _, k := range keys {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[k]
+	}
+	return v
+}
+
+// anyList returns v as a list, wrapping a lone scalar.
+func anyList(v any) []any {
+	switch l := v.(type) {
+	case nil:
+		return nil
+	case []any:
+		return l
+	default:
+		return []any{l}
+	}
+}
+
+// scalar renders a YAML scalar as a string.
+func scalar(v any) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case int:
+		return strconv.Itoa(s)
+	case nil:
+		return ""
+	default:
+		data, _ := json.Marshal(s)
+		return string(data)
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
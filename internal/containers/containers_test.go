@@ -0,0 +1,133 @@
+package containers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mistakeknot/intermap/registry"
+)
+
+func write(t *testing.T, root, rel, content string) {
+	t.Helper()
+	p := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func service(t *testing.T, m *Map, name string) Service {
+	t.Helper()
+	for _, s := range m.Services {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("no service %q in %+v", name, m.Services)
+	return Service{}
+}
+
+const compose = `services:
+  api:
+    build:
+      context: ../apps/api
+    image: acme/api:1.2
+    ports: ["8080:80", {target: 9090, published: 19090}]
+    environment:
+      DATABASE_URL: postgres://secret@db/app
+      LOG_LEVEL: debug
+    env_file: .env
+    depends_on: [db]
+  db:
+    image: postgres:16
+    environment: ["POSTGRES_PASSWORD=hunter2"]
+`
+
+const deployment = `apiVersion: v1
+kind: ConfigMap
+metadata: {name: cfg}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      containers:
+        - name: api
+          image: registry.example.com/acme/api:1.2
+          ports: [{containerPort: 80}, {containerPort: 53, protocol: UDP}]
+          env: [{name: LOG_LEVEL, value: info}]
+          envFrom: [{secretRef: {name: api-secrets}}]
+`
+
+func TestScanAndLink(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "apps/api/Dockerfile", "FROM golang:1.23 AS build\nARG VERSION=dev\nFROM build AS test\nFROM alpine:3.20\nENV PORT=80 MODE=prod\nENV LEGACY value with spaces\nEXPOSE 80 9090\n")
+	write(t, root, "deploy/docker-compose.yml", compose)
+	write(t, root, "deploy/k8s/api.yaml", deployment)
+	write(t, root, "deploy/k8s/chart.yaml", "image: {{ .Values.image }}\n")
+
+	m, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Link(root, []registry.Project{{Name: "api", Path: filepath.Join(root, "apps", "api")}})
+
+	if len(m.Dockerfiles) != 1 {
+		t.Fatalf("expected 1 Dockerfile, got %+v", m.Dockerfiles)
+	}
+	df := m.Dockerfiles[0]
+	if want := []string{"golang:1.23", "alpine:3.20"}; !reflect.DeepEqual(df.BaseImages, want) {
+		t.Errorf("base images = %v, want %v", df.BaseImages, want)
+	}
+	if want := []string{"LEGACY", "MODE", "PORT"}; !reflect.DeepEqual(df.Env, want) {
+		t.Errorf("env = %v, want %v", df.Env, want)
+	}
+	if df.Project != "api" || !reflect.DeepEqual(df.Args, []string{"VERSION"}) {
+		t.Errorf("unexpected Dockerfile summary %+v", df)
+	}
+
+	api := service(t, m, "api")
+	if api.Source != SourceCompose || api.Dockerfile != "apps/api/Dockerfile" || api.Project != "api" {
+		t.Errorf("compose api = %+v", api)
+	}
+	if want := []string{"8080:80", "19090:9090"}; !reflect.DeepEqual(api.Ports, want) {
+		t.Errorf("ports = %v, want %v", api.Ports, want)
+	}
+	if want := []string{"DATABASE_URL", "LOG_LEVEL"}; !reflect.DeepEqual(api.Env, want) {
+		t.Errorf("env = %v, want %v", api.Env, want)
+	}
+	if !reflect.DeepEqual(api.EnvFrom, []string{"deploy/.env"}) || !reflect.DeepEqual(api.DependsOn, []string{"db"}) {
+		t.Errorf("env_from/depends_on = %v/%v", api.EnvFrom, api.DependsOn)
+	}
+
+	db := service(t, m, "db")
+	if db.Project != "" || !reflect.DeepEqual(db.Env, []string{"POSTGRES_PASSWORD"}) {
+		t.Errorf("compose db = %+v", db)
+	}
+
+	var k8s Service
+	for _, s := range m.Services {
+		if s.Source == SourceKubernetes {
+			k8s = s
+		}
+	}
+	if k8s.Kind != "Deployment" || k8s.Project != "api" {
+		t.Errorf("k8s service = %+v", k8s)
+	}
+	if want := []string{"80", "53/udp"}; !reflect.DeepEqual(k8s.Ports, want) {
+		t.Errorf("k8s ports = %v, want %v", k8s.Ports, want)
+	}
+	if !reflect.DeepEqual(k8s.EnvFrom, []string{"secretRef:api-secrets"}) {
+		t.Errorf("k8s env_from = %v", k8s.EnvFrom)
+	}
+	if len(m.Services) != 3 {
+		t.Errorf("expected 3 services, got %d", len(m.Services))
+	}
+}
@@ -0,0 +1,210 @@
+// Package coordination abstracts the backend that tells intermap which
+// agents are running and which files they have reserved.
+//
+// Two providers exist: Intermute, backed by the intermute HTTP API, and
+// File, backed by JSON files and lockfiles in a shared directory for teams
+// that do not run intermute. Tasks, messages, and releasing reservations
+// are optional capabilities a provider may add (TaskLister, MessageLister,
+// Releaser), as is registering intermap itself as an agent (Registrar).
+package coordination
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/mistakeknot/intermap/client"
+)
+
+// The provider data model is intermute's.
+type (
+	Agent        = client.Agent
+	Reservation  = client.Reservation
+	Task         = client.Task
+	Message      = client.Message
+	Registration = client.Registration
+	Overlay      = client.Overlay
+)
+
+// ErrNotSupported is returned when a provider does not offer an operation.
+var ErrNotSupported = client.ErrNotSupported
+
+// ErrNotFound is returned for an agent or reservation the provider does
+// not know.
+var ErrNotFound = client.ErrNotFound
+
+// ErrNotConfigured is returned by Subscribe on a provider that is not
+// Available.
+var ErrNotConfigured = errors.New("coordination provider not configured")
+
+// DefaultPollInterval is how often Subscribe re-lists agents and
+// reservations.
+const DefaultPollInterval = 10 * time.Second
+
+// Provider lists agents and reservations from a coordination backend.
+type Provider interface {
+	// Name identifies the backend in results and errors ("intermute", "file").
+	Name() string
+	// Available reports whether the backend is configured. An unavailable
+	// provider lists nothing.
+	Available() bool
+	ListAgents(ctx context.Context) ([]Agent, error)
+	// ListReservations returns all reservations, or only those for project.
+	ListReservations(ctx context.Context, project string) ([]Reservation, error)
+	// Subscribe streams changes until ctx is done, then closes the channel.
+	Subscribe(ctx context.Context) (<-chan Event, error)
+}
+
+// TaskLister is implemented by providers that track agents' tasks.
+type TaskLister interface {
+	ListTasks(ctx context.Context, agentID string) ([]Task, error)
+}
+
+// MessageLister is implemented by providers that track agent messages.
+type MessageLister interface {
+	ListMessages(ctx context.Context, agentID string) ([]Message, error)
+}
+
+// Releaser is implemented by providers that can release a reservation.
+type Releaser interface {
+	ReleaseReservation(ctx context.Context, id string) error
+}
+
+// OverlaySource is implemented by providers that can return agents,
+// reservations, tasks, and messages in one call. GetOverlay returns
+// ErrNotSupported when the backend turns out not to offer it.
+type OverlaySource interface {
+	GetOverlay(ctx context.Context) (Overlay, error)
+}
+
+// Registrar is implemented by providers that let a service register itself
+// as an agent and stay listed by heartbeating.
+type Registrar interface {
+	RegisterAgent(ctx context.Context, reg Registration) (Agent, error)
+	// Heartbeat returns an error matching ErrNotFound once the provider has
+	// forgotten the agent.
+	Heartbeat(ctx context.Context, agentID string) error
+	DeregisterAgent(ctx context.Context, agentID string) error
+}
+
+// Event kinds sent by Subscribe.
+const (
+	AgentJoined         = "agent_joined"
+	AgentUpdated        = "agent_updated" // name, project, status, or session changed
+	AgentLeft           = "agent_left"
+	ReservationCreated  = "reservation_created"
+	ReservationReleased = "reservation_released"
+)
+
+// Event is one change observed by Subscribe. Exactly one of Agent and
+// Reservation is set.
+type Event struct {
+	Kind        string       `json:"kind"`
+	Time        time.Time    `json:"time"`
+	Agent       *Agent       `json:"agent,omitempty"`
+	Reservation *Reservation `json:"reservation,omitempty"`
+}
+
+// Poll implements Subscribe for providers without a push channel: it lists
+// p every interval and sends the differences. The first poll reports every
+// existing agent and active reservation as joined or created. A poll that
+// fails is skipped, so a transient outage does not look like every agent
+// leaving.
+func Poll(ctx context.Context, p Provider, interval time.Duration) <-chan Event {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		agents := map[string]Agent{}
+		reservations := map[string]Reservation{}
+		for {
+			a, errA := p.ListAgents(ctx)
+			r, errR := p.ListReservations(ctx, "")
+			if errA == nil && errR == nil {
+				nextAgents, nextReservations := indexAgents(a), indexReservations(r)
+				for _, e := range diff(agents, nextAgents, reservations, nextReservations, time.Now()) {
+					select {
+					case events <- e:
+					case <-ctx.Done():
+						return
+					}
+				}
+				agents, reservations = nextAgents, nextReservations
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return events
+}
+
+func indexAgents(agents []Agent) map[string]Agent {
+	m := make(map[string]Agent, len(agents))
+	for _, a := range agents {
+		m[a.AgentID] = a
+	}
+	return m
+}
+
+// indexReservations keeps only active reservations, so deactivating one
+// reads as a release.
+func indexReservations(reservations []Reservation) map[string]Reservation {
+	m := make(map[string]Reservation, len(reservations))
+	for _, r := range reservations {
+		if r.IsActive {
+			m[r.ID] = r
+		}
+	}
+	return m
+}
+
+// diff returns the events that turn the before maps into the after maps,
+// agents first, each group ordered by ID.
+func diff(agentsBefore, agentsAfter map[string]Agent, resBefore, resAfter map[string]Reservation, now time.Time) []Event {
+	var events []Event
+	for _, id := range sortedKeys(agentsBefore, agentsAfter) {
+		old, had := agentsBefore[id]
+		cur, has := agentsAfter[id]
+		switch {
+		case !had:
+			events = append(events, Event{Kind: AgentJoined, Time: now, Agent: &cur})
+		case !has:
+			events = append(events, Event{Kind: AgentLeft, Time: now, Agent: &old})
+		case old.Name != cur.Name || old.Project != cur.Project || old.Status != cur.Status || old.SessionID != cur.SessionID:
+			events = append(events, Event{Kind: AgentUpdated, Time: now, Agent: &cur})
+		}
+	}
+	for _, id := range sortedKeys(resBefore, resAfter) {
+		old, had := resBefore[id]
+		cur, has := resAfter[id]
+		switch {
+		case !had:
+			events = append(events, Event{Kind: ReservationCreated, Time: now, Reservation: &cur})
+		case !has:
+			events = append(events, Event{Kind: ReservationReleased, Time: now, Reservation: &old})
+		}
+	}
+	return events
+}
+
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
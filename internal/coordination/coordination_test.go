@@ -0,0 +1,176 @@
+package coordination
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermap/client"
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFile_List(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "agents", "a1.json"), `{"name":"builder","project":"intermap","status":"active"}`)
+	writeFile(t, filepath.Join(dir, "agents", "a2.json"), `{"agent_id":"a2","name":"reviewer","last_seen":"2026-01-02T03:04:05Z"}`)
+	writeFile(t, filepath.Join(dir, "agents", "partial.json"), `{"name":`)
+	writeFile(t, filepath.Join(dir, "agents", "notes.txt"), `{"name":"ignored"}`)
+	writeFile(t, filepath.Join(dir, "reservations", "r1.lock"), `{"agent_id":"a1","pattern":"internal/*.go","project":"intermap"}`)
+	writeFile(t, filepath.Join(dir, "reservations", "r2.json"), `{"agent_id":"a2","pattern":"docs/*","project":"clavain","is_active":false}`)
+	writeFile(t, filepath.Join(dir, "reservations", "empty.lock"), ``)
+
+	f := NewFile(dir)
+	ctx := context.Background()
+	agents, err := f.ListAgents(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(agents) != 2 || agents[0].AgentID != "a1" || agents[1].LastSeen != "2026-01-02T03:04:05Z" {
+		t.Fatalf("agents = %+v", agents)
+	}
+	if _, err := time.Parse(time.RFC3339, agents[0].LastSeen); err != nil {
+		t.Errorf("last_seen should default to mtime, got %q", agents[0].LastSeen)
+	}
+
+	all, err := f.ListReservations(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].ID != "r1" || !all[0].IsActive || all[1].IsActive {
+		t.Fatalf("reservations = %+v", all)
+	}
+	mine, _ := f.ListReservations(ctx, "intermap")
+	if len(mine) != 1 || mine[0].ID != "r1" {
+		t.Errorf("project filter = %+v", mine)
+	}
+
+	if err := f.ReleaseReservation(ctx, "r1"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "reservations", "r1.lock")); !os.IsNotExist(err) {
+		t.Errorf("lockfile still present: %v", err)
+	}
+	if err := f.ReleaseReservation(ctx, "r1"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("second release: %v", err)
+	}
+}
+
+func TestFile_Unavailable(t *testing.T) {
+	f := NewFile("")
+	agents, err := f.ListAgents(context.Background())
+	if f.Available() || err != nil || agents != nil {
+		t.Errorf("empty dir: available=%v agents=%v err=%v", f.Available(), agents, err)
+	}
+	if _, err := f.Subscribe(context.Background()); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("Subscribe: %v", err)
+	}
+
+	missing := NewFile(filepath.Join(t.TempDir(), "nope"))
+	if agents, err := missing.ListAgents(context.Background()); err != nil || len(agents) != 0 {
+		t.Errorf("missing dir: %v, %v", agents, err)
+	}
+}
+
+// scripted returns a fixed sequence of snapshots, repeating the last.
+type scripted struct {
+	mu     sync.Mutex
+	agents [][]Agent
+	res    [][]Reservation
+	fail   []bool
+	n      int
+}
+
+func (s *scripted) Name() string    { return "scripted" }
+func (s *scripted) Available() bool { return true }
+func (s *scripted) Subscribe(ctx context.Context) (<-chan Event, error) {
+	return Poll(ctx, s, time.Millisecond), nil
+}
+
+func (s *scripted) ListAgents(ctx context.Context) ([]Agent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := min(s.n, len(s.agents)-1)
+	if s.fail[i] {
+		return nil, errors.New("down")
+	}
+	return s.agents[i], nil
+}
+
+func (s *scripted) ListReservations(ctx context.Context, project string) ([]Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := min(s.n, len(s.res)-1)
+	s.n++
+	return s.res[i], nil
+}
+
+func TestPoll(t *testing.T) {
+	p := &scripted{
+		agents: [][]Agent{
+			{{AgentID: "a1", Status: "active"}},
+			nil,
+			{{AgentID: "a1", Status: "idle"}, {AgentID: "a2"}},
+			{{AgentID: "a2", LastSeen: "later"}},
+		},
+		res: [][]Reservation{
+			{{ID: "r1", IsActive: true}},
+			nil,
+			{{ID: "r1", IsActive: false}, {ID: "r2", IsActive: true}},
+			{{ID: "r2", IsActive: true}},
+		},
+		fail: []bool{false, true, false, false},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, _ := p.Subscribe(ctx)
+
+	want := []string{
+		AgentJoined + " a1", ReservationCreated + " r1",
+		// the failed poll is skipped
+		AgentUpdated + " a1", AgentJoined + " a2", ReservationReleased + " r1", ReservationCreated + " r2",
+		// a last_seen change alone is not an update
+		AgentLeft + " a1",
+	}
+	for i, w := range want {
+		select {
+		case e := <-events:
+			got := e.Kind + " "
+			if e.Agent != nil {
+				got += e.Agent.AgentID
+			} else {
+				got += e.Reservation.ID
+			}
+			if got != w {
+				t.Fatalf("event %d = %q, want %q", i, got, w)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %q", w)
+		}
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestIntermute_Unavailable(t *testing.T) {
+	i := NewIntermute(client.NewClient())
+	if i.Available() {
+		t.Error("client without a URL should be unavailable")
+	}
+	if _, err := i.Subscribe(context.Background()); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("Subscribe: %v", err)
+	}
+}
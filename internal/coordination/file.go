@@ -0,0 +1,215 @@
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// File is the provider backed by a shared directory:
+//
+//	<dir>/agents/<agent_id>.json        one Agent object per agent
+//	<dir>/reservations/<id>.json|.lock  one Reservation object per reservation
+//
+// Missing fields default from the file: agent_id and id from the file name,
+// last_seen and created_at from its modification time, and is_active to
+// true. An agent heartbeats by touching its file; a reservation is released
+// by deleting its file. Lockfiles let agents take a reservation atomically
+// by creating the file with O_EXCL.
+//
+// Files that cannot be read or parsed are skipped, since agents may be
+// mid-write; writers should write a temporary file and rename it.
+type File struct {
+	Dir string
+	// PollInterval paces Subscribe; zero uses DefaultPollInterval.
+	PollInterval time.Duration
+}
+
+// NewFile returns a provider reading dir. An empty dir is unavailable.
+func NewFile(dir string) *File {
+	return &File{Dir: dir}
+}
+
+// Name returns "file".
+func (f *File) Name() string { return "file" }
+
+// Available reports whether a directory is configured. The directory need
+// not exist yet: a missing one has no agents.
+func (f *File) Available() bool { return f.Dir != "" }
+
+// ListAgents reads <dir>/agents.
+func (f *File) ListAgents(ctx context.Context) ([]Agent, error) {
+	var agents []Agent
+	err := f.walk("agents", []string{".json"}, func(path, id string, info os.FileInfo, data []byte) {
+		var a Agent
+		if json.Unmarshal(data, &a) != nil {
+			return
+		}
+		if a.AgentID == "" {
+			a.AgentID = id
+		}
+		if a.LastSeen == "" {
+			a.LastSeen = info.ModTime().UTC().Format(time.RFC3339)
+		}
+		agents = append(agents, a)
+	})
+	return agents, err
+}
+
+// ListReservations reads <dir>/reservations, keeping only project's when
+// project is set.
+func (f *File) ListReservations(ctx context.Context, project string) ([]Reservation, error) {
+	var reservations []Reservation
+	err := f.walk("reservations", []string{".json", ".lock"}, func(path, id string, info os.FileInfo, data []byte) {
+		r, ok := parseReservation(id, info, data)
+		if ok && (project == "" || r.Project == project) {
+			reservations = append(reservations, r)
+		}
+	})
+	return reservations, err
+}
+
+// ReleaseReservation deletes the file holding reservation id.
+func (f *File) ReleaseReservation(ctx context.Context, id string) error {
+	var found string
+	err := f.walk("reservations", []string{".json", ".lock"}, func(path, name string, info os.FileInfo, data []byte) {
+		if r, ok := parseReservation(name, info, data); ok && r.ID == id && found == "" {
+			found = path
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if found == "" {
+		return fmt.Errorf("release reservation %s: %w", id, os.ErrNotExist)
+	}
+	if err := os.Remove(found); err != nil {
+		return fmt.Errorf("release reservation %s: %w", id, err)
+	}
+	return nil
+}
+
+// RegisterAgent writes <dir>/agents/<id>.json, where id is derived from
+// the name, host, and process ID.
+func (f *File) RegisterAgent(ctx context.Context, reg Registration) (Agent, error) {
+	if !f.Available() {
+		return Agent{}, ErrNotConfigured
+	}
+	agent := Agent{
+		AgentID:      fmt.Sprintf("%s-%s-%d", reg.Name, reg.Host, os.Getpid()),
+		Name:         reg.Name,
+		Project:      reg.Project,
+		Status:       "active",
+		SessionID:    reg.SessionID,
+		Host:         reg.Host,
+		Capabilities: reg.Capabilities,
+		Metadata:     reg.Metadata,
+	}
+	agent.AgentID = strings.Trim(strings.Map(func(r rune) rune {
+		if r == '/' || r == os.PathSeparator || r < ' ' {
+			return '_'
+		}
+		return r
+	}, agent.AgentID), ".")
+	data, err := json.MarshalIndent(agent, "", "  ")
+	if err != nil {
+		return Agent{}, err
+	}
+	path := f.agentPath(agent.AgentID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return Agent{}, fmt.Errorf("register agent: %w", err)
+	}
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return Agent{}, fmt.Errorf("register agent: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return Agent{}, fmt.Errorf("register agent: %w", err)
+	}
+	return agent, nil
+}
+
+// Heartbeat touches the agent's file.
+func (f *File) Heartbeat(ctx context.Context, agentID string) error {
+	now := time.Now()
+	if err := os.Chtimes(f.agentPath(agentID), now, now); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("heartbeat %s: %w", agentID, ErrNotFound)
+		}
+		return fmt.Errorf("heartbeat %s: %w", agentID, err)
+	}
+	return nil
+}
+
+// DeregisterAgent deletes the agent's file.
+func (f *File) DeregisterAgent(ctx context.Context, agentID string) error {
+	if err := os.Remove(f.agentPath(agentID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("deregister agent %s: %w", agentID, err)
+	}
+	return nil
+}
+
+func (f *File) agentPath(agentID string) string {
+	return filepath.Join(f.Dir, "agents", agentID+".json")
+}
+
+// Subscribe polls the directory for changes.
+func (f *File) Subscribe(ctx context.Context) (<-chan Event, error) {
+	if !f.Available() {
+		return nil, ErrNotConfigured
+	}
+	return Poll(ctx, f, f.PollInterval), nil
+}
+
+func parseReservation(id string, info os.FileInfo, data []byte) (Reservation, bool) {
+	r := Reservation{IsActive: true}
+	if json.Unmarshal(data, &r) != nil || r.Pattern == "" {
+		return Reservation{}, false
+	}
+	if r.ID == "" {
+		r.ID = id
+	}
+	if r.CreatedAt == "" {
+		r.CreatedAt = info.ModTime().UTC().Format(time.RFC3339)
+	}
+	return r, true
+}
+
+// walk calls fn, in file name order, for each regular file in <dir>/sub
+// with one of exts. id is the file name without its extension.
+func (f *File) walk(sub string, exts []string, fn func(path, id string, info os.FileInfo, data []byte)) error {
+	if !f.Available() {
+		return nil
+	}
+	dir := filepath.Join(f.Dir, sub)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") || !slices.Contains(exts, ext) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		fn(path, strings.TrimSuffix(e.Name(), ext), info, data)
+	}
+	return nil
+}
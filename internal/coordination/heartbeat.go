@@ -0,0 +1,91 @@
+package coordination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultHeartbeatInterval is how often StartHeartbeat refreshes the
+// registration.
+const DefaultHeartbeatInterval = 30 * time.Second
+
+// StartHeartbeat registers reg with p and heartbeats every interval until
+// the returned stop function is called, which deregisters it. Registration
+// is retried each interval while the provider is unreachable, and redone if
+// the provider forgets the agent. It does nothing if p is unavailable or
+// cannot register agents. Failures are logged to stderr when they change.
+func StartHeartbeat(p Provider, reg Registration, interval time.Duration) (stop func()) {
+	r, ok := p.(Registrar)
+	if !ok || !p.Available() {
+		return func() {}
+	}
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	var agentID string
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		lastErr := ""
+		for {
+			err := beat(ctx, r, reg, &agentID)
+			if msg := errString(err); msg != lastErr && ctx.Err() == nil {
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "intermap: %s heartbeat: %v\n", p.Name(), err)
+				} else if lastErr != "" {
+					fmt.Fprintf(os.Stderr, "intermap: %s heartbeat recovered (agent %s)\n", p.Name(), agentID)
+				}
+				lastErr = msg
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+		if agentID == "" {
+			return
+		}
+		dctx, dcancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer dcancel()
+		r.DeregisterAgent(dctx, agentID)
+	}
+}
+
+// beat registers if *agentID is empty, and heartbeats otherwise.
+func beat(ctx context.Context, r Registrar, reg Registration, agentID *string) error {
+	if *agentID != "" {
+		err := r.Heartbeat(ctx, *agentID)
+		if !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		*agentID = ""
+	}
+	agent, err := r.RegisterAgent(ctx, reg)
+	if err != nil {
+		return err
+	}
+	if agent.AgentID == "" {
+		return errors.New("registration returned no agent_id")
+	}
+	*agentID = agent.AgentID
+	return nil
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
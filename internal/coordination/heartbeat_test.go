@@ -0,0 +1,56 @@
+package coordination
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStartHeartbeat_File(t *testing.T) {
+	f := NewFile(t.TempDir())
+	reg := Registration{Name: "intermap", Host: "box", Capabilities: []string{"agent_map", "code_structure"}}
+	stop := StartHeartbeat(f, reg, 10*time.Millisecond)
+
+	var agents []Agent
+	waitFor(t, "registration", func() bool {
+		agents, _ = f.ListAgents(context.Background())
+		return len(agents) == 1
+	})
+	a := agents[0]
+	if a.Name != "intermap" || a.Host != "box" || len(a.Capabilities) != 2 || a.Status != "active" {
+		t.Fatalf("registered agent = %+v", a)
+	}
+
+	// A provider that forgets the agent gets a fresh registration.
+	path := filepath.Join(f.Dir, "agents", a.AgentID+".json")
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "re-registration", func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	})
+
+	stop()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("agent file should be removed on stop: %v", err)
+	}
+}
+
+func TestStartHeartbeat_Unavailable(t *testing.T) {
+	StartHeartbeat(NewFile(""), Registration{Name: "intermap"}, time.Millisecond)()
+	StartHeartbeat(&scripted{}, Registration{Name: "intermap"}, time.Millisecond)()
+}
@@ -0,0 +1,32 @@
+package coordination
+
+import (
+	"context"
+	"time"
+
+	"github.com/mistakeknot/intermap/client"
+)
+
+// Intermute is the provider backed by the intermute HTTP API. It also
+// offers tasks, messages, and releasing reservations.
+type Intermute struct {
+	*client.Client
+	// PollInterval paces Subscribe; zero uses DefaultPollInterval.
+	PollInterval time.Duration
+}
+
+// NewIntermute wraps an intermute client.
+func NewIntermute(c *client.Client) *Intermute {
+	return &Intermute{Client: c}
+}
+
+// Name returns "intermute".
+func (i *Intermute) Name() string { return "intermute" }
+
+// Subscribe polls intermute for changes.
+func (i *Intermute) Subscribe(ctx context.Context) (<-chan Event, error) {
+	if !i.Available() {
+		return nil, ErrNotConfigured
+	}
+	return Poll(ctx, i, i.PollInterval), nil
+}
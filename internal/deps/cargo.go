@@ -0,0 +1,108 @@
+package deps
+
+import "strings"
+
+// scanCargo reads Cargo.lock, where packages without a source are the
+// project's own crates and their dependencies are the direct ones. Without
+// a lockfile, Cargo.toml gives the direct dependencies, unversioned since
+// Cargo requirements are ranges.
+func scanCargo(dir string) []Component {
+	manifest, hasManifest := read(dir, "Cargo.toml")
+	runtimeDeps, devDeps := cargoManifestDeps(manifest)
+
+	if src, ok := read(dir, "Cargo.lock"); ok {
+		var local, third []lockPackage
+		for _, p := range parseLockPackages(src) {
+			if p.Source == "" {
+				local = append(local, p)
+			} else {
+				third = append(third, p)
+			}
+		}
+		direct := make(map[string]bool)
+		for _, p := range local {
+			for _, dep := range p.Deps {
+				name, _, _ := strings.Cut(dep, " ")
+				direct[name] = true
+			}
+		}
+		dev := make(map[string]bool)
+		for _, name := range devDeps {
+			if !contains(runtimeDeps, name) {
+				dev[name] = true
+			}
+		}
+		return lockComponents(third, Cargo, "Cargo.lock", direct, dev, identity)
+	}
+
+	if !hasManifest {
+		return nil
+	}
+	var out []Component
+	for _, name := range runtimeDeps {
+		out = append(out, Component{Name: name, Ecosystem: Cargo, Direct: true, Source: "Cargo.toml"})
+	}
+	for _, name := range devDeps {
+		if !contains(runtimeDeps, name) {
+			out = append(out, Component{Name: name, Ecosystem: Cargo, Direct: true, Dev: true, Source: "Cargo.toml"})
+		}
+	}
+	return out
+}
+
+// cargoManifestDeps lists the crates Cargo.toml depends on, as written in
+// [dependencies] and [build-dependencies] (runtime) and [dev-dependencies],
+// including [dependencies.name] tables and target-specific sections. A
+// renamed dependency (alias = { package = "crate" }) is listed by alias.
+func cargoManifestDeps(src string) (runtime, dev []string) {
+	section := ""
+	for _, line := range strings.Split(src, "\n") {
+		t := strings.TrimSpace(line)
+		if strings.HasPrefix(t, "[") {
+			header := strings.Trim(t, "[] ")
+			if i := strings.Index(header, "."); strings.HasPrefix(header, "target.") && i >= 0 {
+				// [target.'cfg(unix)'.dependencies]
+				if j := strings.LastIndex(header, "."); j > i {
+					header = header[j+1:]
+				}
+			}
+			section = ""
+			kind, name, sub := strings.Cut(header, ".")
+			switch kind {
+			case "dependencies", "build-dependencies", "dev-dependencies":
+				if sub {
+					addCrate(kind, strings.Trim(name, `"`), &runtime, &dev)
+				} else {
+					section = kind
+				}
+			}
+			continue
+		}
+		if section == "" || t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		if key, _, ok := strings.Cut(t, "="); ok {
+			addCrate(section, strings.Trim(strings.TrimSpace(key), `"`), &runtime, &dev)
+		}
+	}
+	return runtime, dev
+}
+
+func addCrate(kind, name string, runtime, dev *[]string) {
+	if kind == "dev-dependencies" {
+		*dev = append(*dev, name)
+	} else if !contains(*runtime, name) {
+		*runtime = append(*runtime, name)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func identity(s string) string { return s }
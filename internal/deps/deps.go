@@ -0,0 +1,222 @@
+// Package deps inventories the third-party dependencies of a project from
+// its manifests and lockfiles: go.mod, package-lock.json (or package.json),
+// Cargo.lock (or Cargo.toml), and uv.lock, poetry.lock, pyproject.toml, and
+// requirements.txt. Lockfiles give exact versions, transitive dependencies,
+// and the edges between them; a bare manifest gives only direct
+// dependencies, with versions where they are pinned.
+package deps
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Ecosystems, named as in package URLs.
+const (
+	Go    = "golang"
+	NPM   = "npm"
+	PyPI  = "pypi"
+	Cargo = "cargo"
+)
+
+// Component is one third-party package a project depends on.
+type Component struct {
+	Name      string `json:"name"`
+	Version   string `json:"version,omitempty"`
+	Ecosystem string `json:"ecosystem"`
+	// Direct is set for dependencies the project's manifest declares.
+	Direct bool `json:"direct"`
+	// Dev is set for dependencies only needed to develop or test the project.
+	Dev bool `json:"dev,omitempty"`
+	// Requires lists the PURLs of the components this one depends on, when
+	// a lockfile records them.
+	Requires []string `json:"requires,omitempty"`
+	// Source is the manifest or lockfile it was read from.
+	Source string `json:"source"`
+	// License is an SPDX expression, from the lockfile or, after
+	// ResolveLicenses, the installed package.
+	License string `json:"license,omitempty"`
+}
+
+// PURL returns the component's package URL (pkg:type/name@version).
+func (c Component) PURL() string {
+	name := c.Name
+	switch c.Ecosystem {
+	case NPM:
+		name = strings.Replace(name, "@", "%40", 1)
+	case PyPI:
+		name = NormalizePyPI(name)
+	}
+	p := "pkg:" + c.Ecosystem + "/" + name
+	if c.Version != "" {
+		p += "@" + url.PathEscape(c.Version)
+	}
+	return p
+}
+
+// NormalizePyPI normalizes a Python distribution name (PEP 503).
+func NormalizePyPI(name string) string {
+	return strings.ToLower(pyNameSep.ReplaceAllString(name, "-"))
+}
+
+var (
+	pyNameSep = regexp.MustCompile(`[-_.]+`)
+	// exactVersion matches a version spec that pins one release.
+	exactVersion = regexp.MustCompile(`^=?=?v?\d+(\.\d+)*([-+.][\w.+-]*)?$`)
+)
+
+// maxFileSize bounds the manifests and lockfiles read.
+const maxFileSize = 16 << 20
+
+func read(dir, name string) (string, bool) {
+	p := filepath.Join(dir, name)
+	info, err := os.Stat(p)
+	if err != nil || info.IsDir() || info.Size() > maxFileSize {
+		return "", false
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// Scan inventories the dependencies of the project at dir, sorted by
+// ecosystem, name, and version. Files that fail to parse are skipped.
+func Scan(dir string) []Component {
+	var out []Component
+	out = append(out, scanGo(dir)...)
+	out = append(out, scanNPM(dir)...)
+	out = append(out, scanCargo(dir)...)
+	out = append(out, scanPython(dir)...)
+	for i := range out {
+		sort.Strings(out[i].Requires)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+	return out
+}
+
+// dedupe drops repeated PURLs, keeping the first occurrence.
+func dedupe(in []string) []string {
+	seen := make(map[string]bool, len(in))
+	out := in[:0]
+	for _, s := range in {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// lockComponents turns the third-party packages of a TOML lockfile into
+// components. direct names the project's direct dependencies, and dev
+// those only needed for development; a package is dev when no runtime
+// direct dependency reaches it. norm maps names to their comparable form.
+func lockComponents(pkgs []lockPackage, ecosystem, source string, direct, dev map[string]bool, norm func(string) string) []Component {
+	byName := make(map[string][]int)
+	for i, p := range pkgs {
+		byName[norm(p.Name)] = append(byName[norm(p.Name)], i)
+	}
+	// resolve finds the package a dependency entry ("name" or
+	// "name version") refers to.
+	resolve := func(dep string) (int, bool) {
+		name, version, _ := strings.Cut(dep, " ")
+		candidates := byName[norm(name)]
+		for _, i := range candidates {
+			if version == "" || pkgs[i].Version == version {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	out := make([]Component, len(pkgs))
+	edges := make([][]int, len(pkgs))
+	for i, p := range pkgs {
+		out[i] = Component{Name: p.Name, Version: p.Version, Ecosystem: ecosystem, Direct: direct[norm(p.Name)], Source: source}
+		for _, dep := range p.Deps {
+			if j, ok := resolve(dep); ok {
+				edges[i] = append(edges[i], j)
+				out[i].Requires = append(out[i].Requires, Component{Name: pkgs[j].Name, Version: pkgs[j].Version, Ecosystem: ecosystem}.PURL())
+			}
+		}
+		out[i].Requires = dedupe(out[i].Requires)
+	}
+
+	if len(dev) == 0 {
+		for i, p := range pkgs {
+			out[i].Dev = p.Category == "dev"
+		}
+		return out
+	}
+	runtime := make([]bool, len(pkgs))
+	var stack []int
+	for i, p := range pkgs {
+		if direct[norm(p.Name)] && !dev[norm(p.Name)] {
+			runtime[i] = true
+			stack = append(stack, i)
+		}
+	}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, j := range edges[i] {
+			if !runtime[j] {
+				runtime[j] = true
+				stack = append(stack, j)
+			}
+		}
+	}
+	for i := range out {
+		out[i].Dev = !runtime[i]
+	}
+	return out
+}
+
+// Chains returns, for each component's PURL, the shortest path of PURLs
+// from one of the project's direct dependencies to it. A component no
+// direct dependency reaches through recorded edges maps to itself alone.
+func Chains(cs []Component) map[string][]string {
+	byPURL := make(map[string]Component, len(cs))
+	for _, c := range cs {
+		byPURL[c.PURL()] = c
+	}
+	chains := make(map[string][]string, len(cs))
+	var queue []string
+	for _, c := range cs {
+		if p := c.PURL(); c.Direct && chains[p] == nil {
+			chains[p] = []string{p}
+			queue = append(queue, p)
+		}
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, req := range byPURL[p].Requires {
+			if _, ok := byPURL[req]; ok && chains[req] == nil {
+				chains[req] = append(append([]string(nil), chains[p]...), req)
+				queue = append(queue, req)
+			}
+		}
+	}
+	for p := range byPURL {
+		if chains[p] == nil {
+			chains[p] = []string{p}
+		}
+	}
+	return chains
+}
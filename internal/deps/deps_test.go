@@ -0,0 +1,386 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func write(t *testing.T, root, rel, content string) {
+	t.Helper()
+	p := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func find(t *testing.T, cs []Component, ecosystem, name string) Component {
+	t.Helper()
+	for _, c := range cs {
+		if c.Ecosystem == ecosystem && c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("no %s component %q in %+v", ecosystem, name, cs)
+	return Component{}
+}
+
+func TestPURL(t *testing.T) {
+	for _, tc := range []struct {
+		c    Component
+		want string
+	}{
+		{Component{Name: "github.com/mark3labs/mcp-go", Version: "v0.43.2", Ecosystem: Go}, "pkg:golang/github.com/mark3labs/mcp-go@v0.43.2"},
+		{Component{Name: "@babel/core", Version: "7.24.0", Ecosystem: NPM}, "pkg:npm/%40babel/core@7.24.0"},
+		{Component{Name: "Typing_Extensions", Version: "4.12.2", Ecosystem: PyPI}, "pkg:pypi/typing-extensions@4.12.2"},
+		{Component{Name: "serde", Ecosystem: Cargo}, "pkg:cargo/serde"},
+	} {
+		if got := tc.c.PURL(); got != tc.want {
+			t.Errorf("PURL(%+v) = %q, want %q", tc.c, got, tc.want)
+		}
+	}
+}
+
+func TestScanGo(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "go.mod", "module example.com/m\n\ngo 1.23\n\nrequire gopkg.in/yaml.v3 v3.0.1\n\nrequire (\n\tgithub.com/a/b v1.2.0\n\tgithub.com/c/d v0.1.0 // indirect\n)\n\nreplace github.com/a/b => ../b\n")
+
+	cs := Scan(dir)
+	if len(cs) != 3 {
+		t.Fatalf("components = %+v", cs)
+	}
+	if c := find(t, cs, Go, "github.com/c/d"); c.Direct || c.Version != "v0.1.0" {
+		t.Errorf("indirect = %+v", c)
+	}
+	if c := find(t, cs, Go, "gopkg.in/yaml.v3"); !c.Direct || c.Source != "go.mod" {
+		t.Errorf("single-line require = %+v", c)
+	}
+}
+
+func TestScanNPM(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "package.json", `{"dependencies": {"express": "^4.18.0"}, "devDependencies": {"jest": "29.7.0"}}`)
+	write(t, dir, "package-lock.json", `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"express": "^4.18.0"}, "devDependencies": {"jest": "29.7.0"}},
+    "node_modules/express": {"version": "4.18.2", "dependencies": {"debug": "2.6.9", "@types/node": "*"}},
+    "node_modules/express/node_modules/debug": {"version": "2.6.9"},
+    "node_modules/debug": {"version": "4.3.4", "dev": true},
+    "node_modules/@types/node": {"version": "20.1.0"},
+    "node_modules/jest": {"version": "29.7.0", "dev": true, "dependencies": {"debug": "^4"}},
+    "node_modules/shared": {"resolved": "packages/shared", "link": true},
+    "packages/shared": {"version": "1.0.0"}
+  }
+}`)
+
+	cs := Scan(dir)
+	if len(cs) != 5 {
+		t.Fatalf("components = %+v", cs)
+	}
+	express := find(t, cs, NPM, "express")
+	if !express.Direct || express.Dev || express.Version != "4.18.2" {
+		t.Errorf("express = %+v", express)
+	}
+	if want := []string{"pkg:npm/%40types/node@20.1.0", "pkg:npm/debug@2.6.9"}; !reflect.DeepEqual(express.Requires, want) {
+		t.Errorf("express requires = %v, want %v", express.Requires, want)
+	}
+	if jest := find(t, cs, NPM, "jest"); !jest.Direct || !jest.Dev || !reflect.DeepEqual(jest.Requires, []string{"pkg:npm/debug@4.3.4"}) {
+		t.Errorf("jest = %+v", jest)
+	}
+	for _, c := range cs {
+		if c.Name == "debug" && c.Direct {
+			t.Errorf("transitive debug marked direct: %+v", c)
+		}
+	}
+
+	// Without a lockfile only exact versions are kept.
+	os.Remove(filepath.Join(dir, "package-lock.json"))
+	cs = Scan(dir)
+	if c := find(t, cs, NPM, "express"); c.Version != "" || !c.Direct {
+		t.Errorf("manifest express = %+v", c)
+	}
+	if c := find(t, cs, NPM, "jest"); c.Version != "29.7.0" || !c.Dev {
+		t.Errorf("manifest jest = %+v", c)
+	}
+}
+
+func TestScanCargo(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "Cargo.toml", "[package]\nname = \"app\"\n\n[dependencies]\nserde = { version = \"1\", features = [\"derive\"] }\n\n[dependencies.log]\nversion = \"0.4\"\n\n[dev-dependencies]\ninsta = \"1\"\n")
+	write(t, dir, "Cargo.lock", `version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = [
+ "insta",
+ "log",
+ "serde",
+]
+
+[[package]]
+name = "insta"
+version = "1.39.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+dependencies = [
+ "similar",
+]
+
+[[package]]
+name = "log"
+version = "0.4.21"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "serde"
+version = "1.0.200"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+dependencies = ["serde_derive 1.0.200 (registry+https://github.com/rust-lang/crates.io-index)"]
+
+[[package]]
+name = "serde_derive"
+version = "1.0.200"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "similar"
+version = "2.5.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`)
+
+	cs := Scan(dir)
+	if len(cs) != 5 {
+		t.Fatalf("components = %+v", cs)
+	}
+	if c := find(t, cs, Cargo, "serde"); !c.Direct || c.Dev || !reflect.DeepEqual(c.Requires, []string{"pkg:cargo/serde_derive@1.0.200"}) {
+		t.Errorf("serde = %+v", c)
+	}
+	if c := find(t, cs, Cargo, "serde_derive"); c.Direct || c.Dev {
+		t.Errorf("serde_derive = %+v", c)
+	}
+	if c := find(t, cs, Cargo, "similar"); c.Direct || !c.Dev {
+		t.Errorf("similar (only under a dev-dependency) = %+v", c)
+	}
+	if c := find(t, cs, Cargo, "log"); !c.Direct {
+		t.Errorf("log from a [dependencies.log] table = %+v", c)
+	}
+}
+
+func TestScanPython(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "pyproject.toml", "[project]\nname = \"svc\"\ndependencies = [\n  \"requests[socks]>=2.31\",\n  \"PyYAML==6.0.1\",\n]\n\n[dependency-groups]\ndev = [\"pytest\"]\n")
+	write(t, dir, "requirements.txt", "# pinned\nrequests==2.31.0\n-e ./vendor/lib\n")
+
+	cs := Scan(dir)
+	if len(cs) != 3 {
+		t.Fatalf("declared = %+v", cs)
+	}
+	if c := find(t, cs, PyPI, "requests"); c.Version != "2.31.0" || c.Dev {
+		t.Errorf("requests = %+v", c)
+	}
+	if c := find(t, cs, PyPI, "pytest"); !c.Dev || !c.Direct {
+		t.Errorf("pytest = %+v", c)
+	}
+
+	write(t, dir, "uv.lock", `version = 1
+
+[[package]]
+name = "certifi"
+version = "2024.2.2"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "pytest"
+version = "8.1.1"
+source = { registry = "https://pypi.org/simple" }
+dependencies = [
+    { name = "pluggy" },
+]
+
+[[package]]
+name = "pluggy"
+version = "1.4.0"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "requests"
+version = "2.31.0"
+source = { registry = "https://pypi.org/simple" }
+dependencies = [
+    { name = "certifi" },
+]
+wheels = [
+    { url = "https://files.example/requests.whl", hash = "sha256:00" },
+]
+
+[[package]]
+name = "svc"
+version = "0.1.0"
+source = { editable = "." }
+dependencies = [
+    { name = "requests", extra = ["socks"] },
+]
+
+[package.dev-dependencies]
+dev = [
+    { name = "pytest" },
+]
+
+[package.metadata]
+requires-dist = [{ name = "requests", specifier = ">=2.31" }]
+`)
+	cs = Scan(dir)
+	if len(cs) != 4 {
+		t.Fatalf("uv components = %+v", cs)
+	}
+	if c := find(t, cs, PyPI, "requests"); !c.Direct || c.Dev || c.Source != "uv.lock" || !reflect.DeepEqual(c.Requires, []string{"pkg:pypi/certifi@2024.2.2"}) {
+		t.Errorf("requests = %+v", c)
+	}
+	if c := find(t, cs, PyPI, "pluggy"); c.Direct || !c.Dev {
+		t.Errorf("pluggy = %+v", c)
+	}
+	if c := find(t, cs, PyPI, "certifi"); c.Direct || c.Dev {
+		t.Errorf("certifi = %+v", c)
+	}
+}
+
+func TestScanPoetry(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "pyproject.toml", "[tool.poetry.dependencies]\npython = \"^3.11\"\nhttpx = \"^0.27\"\n\n[tool.poetry.group.test.dependencies]\npytest = \"^8\"\n")
+	write(t, dir, "poetry.lock", `[[package]]
+name = "httpx"
+version = "0.27.0"
+optional = false
+
+[package.dependencies]
+anyio = "*"
+
+[[package]]
+name = "anyio"
+version = "4.3.0"
+
+[[package]]
+name = "pytest"
+version = "8.1.1"
+
+[metadata]
+lock-version = "2.0"
+`)
+
+	cs := Scan(dir)
+	if len(cs) != 3 {
+		t.Fatalf("components = %+v", cs)
+	}
+	if c := find(t, cs, PyPI, "httpx"); !c.Direct || c.Dev || !reflect.DeepEqual(c.Requires, []string{"pkg:pypi/anyio@4.3.0"}) {
+		t.Errorf("httpx = %+v", c)
+	}
+	if c := find(t, cs, PyPI, "pytest"); !c.Direct || !c.Dev {
+		t.Errorf("pytest = %+v", c)
+	}
+}
+
+func TestChains(t *testing.T) {
+	cs := []Component{
+		{Name: "a", Version: "1", Ecosystem: NPM, Direct: true, Requires: []string{"pkg:npm/b@1"}},
+		{Name: "b", Version: "1", Ecosystem: NPM, Requires: []string{"pkg:npm/c@1"}},
+		{Name: "c", Version: "1", Ecosystem: NPM},
+		{Name: "d", Version: "1", Ecosystem: NPM, Direct: true, Requires: []string{"pkg:npm/c@1"}},
+		{Name: "orphan", Version: "1", Ecosystem: NPM},
+	}
+	chains := Chains(cs)
+	if want := []string{"pkg:npm/a@1", "pkg:npm/b@1"}; !reflect.DeepEqual(chains["pkg:npm/b@1"], want) {
+		t.Errorf("chain to b = %v, want %v", chains["pkg:npm/b@1"], want)
+	}
+	// c is reached through a and b, but d is the shorter way in.
+	if want := []string{"pkg:npm/d@1", "pkg:npm/c@1"}; !reflect.DeepEqual(chains["pkg:npm/c@1"], want) {
+		t.Errorf("chain to c = %v, want %v", chains["pkg:npm/c@1"], want)
+	}
+	if want := []string{"pkg:npm/orphan@1"}; !reflect.DeepEqual(chains["pkg:npm/orphan@1"], want) {
+		t.Errorf("chain to orphan = %v", chains["pkg:npm/orphan@1"])
+	}
+}
+
+func TestResolveLicenses(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GOMODCACHE", filepath.Join(dir, "modcache"))
+	t.Setenv("CARGO_HOME", filepath.Join(dir, "cargo"))
+	write(t, dir, "modcache/github.com/!burnt!sushi/toml@v1.3.2/COPYING", "The MIT License (MIT)\n\nPermission is hereby granted, free of charge, to any person")
+	write(t, dir, "vendor/example.com/vendored/LICENSE", "Apache License\nVersion 2.0, January 2004")
+	write(t, dir, "node_modules/@scope/pkg/package.json", `{"name": "@scope/pkg", "licenses": [{"type": "MIT"}, {"type": "Apache-2.0"}]}`)
+	write(t, dir, ".venv/lib/python3.12/site-packages/PyYAML-6.0.1.dist-info/METADATA", "Metadata-Version: 2.1\nName: PyYAML\nLicense: MIT\nClassifier: License :: OSI Approved :: MIT License\n\nGPL text in the description")
+	write(t, dir, ".venv/lib/python3.12/site-packages/typing_extensions-4.12.2.dist-info/METADATA", "Metadata-Version: 2.4\nName: typing_extensions\nLicense-Expression: PSF-2.0\n")
+	write(t, dir, "cargo/registry/src/index.crates.io-6f17d22bba15001f/serde-1.0.200/Cargo.toml", "[package]\nname = \"serde\"\nlicense = \"MIT OR Apache-2.0\"\n")
+
+	cs := []Component{
+		{Name: "github.com/BurntSushi/toml", Version: "v1.3.2", Ecosystem: Go},
+		{Name: "example.com/vendored", Version: "v0.1.0", Ecosystem: Go},
+		{Name: "example.com/missing", Version: "v0.1.0", Ecosystem: Go},
+		{Name: "@scope/pkg", Version: "1.0.0", Ecosystem: NPM},
+		{Name: "pyyaml", Version: "6.0.1", Ecosystem: PyPI},
+		{Name: "typing-extensions", Version: "4.12.2", Ecosystem: PyPI},
+		{Name: "serde", Version: "1.0.200", Ecosystem: Cargo},
+		{Name: "locked", Version: "1.0.0", Ecosystem: NPM, License: "ISC"},
+	}
+	ResolveLicenses(dir, cs)
+	want := []string{"MIT", "Apache-2.0", "", "MIT OR Apache-2.0", "MIT", "PSF-2.0", "MIT OR Apache-2.0", "ISC"}
+	for i, c := range cs {
+		if c.License != want[i] {
+			t.Errorf("%s license = %q, want %q", c.Name, c.License, want[i])
+		}
+	}
+}
+
+func TestGoWorkAndReplaces(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "go.work", "go 1.23\n\nuse (\n\t./api\n\t./lib // shared\n)\n\nreplace example.com/x v1.0.0 => example.com/y v1.1.0\n")
+	write(t, dir, "lib/go.mod", "module example.com/lib\n\ngo 1.23\n")
+	write(t, dir, "api/go.mod", "module example.com/api\n\nreplace example.com/lib => ../lib\n\nreplace (\n\t// old\n\texample.com/z => /abs/z\n)\n")
+
+	w, ok := GoWork(dir)
+	if !ok {
+		t.Fatal("go.work not found")
+	}
+	if want := []string{filepath.Join(dir, "api"), filepath.Join(dir, "lib")}; !reflect.DeepEqual(w.Use, want) {
+		t.Errorf("use = %v, want %v", w.Use, want)
+	}
+	if want := []Replace{{Old: "example.com/x", OldVersion: "v1.0.0", New: "example.com/y", NewVersion: "v1.1.0"}}; !reflect.DeepEqual(w.Replace, want) {
+		t.Errorf("work replace = %+v", w.Replace)
+	}
+	if !w.Replace[0].Applies("example.com/x", "v1.0.0") || w.Replace[0].Applies("example.com/x", "v1.0.1") {
+		t.Error("versioned replace applies to the wrong versions")
+	}
+
+	want := []Replace{
+		{Old: "example.com/lib", New: "../lib", Local: true},
+		{Old: "example.com/z", New: "/abs/z", Local: true},
+	}
+	if got := GoReplaces(filepath.Join(dir, "api")); !reflect.DeepEqual(got, want) {
+		t.Errorf("replaces = %+v, want %+v", got, want)
+	}
+	if got := GoModulePath(filepath.Join(dir, "lib")); got != "example.com/lib" {
+		t.Errorf("module path = %q", got)
+	}
+	if _, ok := GoWork(filepath.Join(dir, "lib")); ok {
+		t.Error("GoWork found a go.work in lib")
+	}
+}
+
+func TestVendoredGo(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "vendor/modules.txt", "# github.com/a/b v1.2.0\n## explicit; go 1.21\ngithub.com/a/b\n# github.com/c/d v0.1.0\ngithub.com/c/d\n# example.com/lib => ../lib\n")
+	cs := VendoredGo(dir)
+	if len(cs) != 2 {
+		t.Fatalf("vendored = %+v", cs)
+	}
+	if c := cs[0]; c.Name != "github.com/a/b" || c.Version != "v1.2.0" || !c.Direct || c.Source != "vendor/modules.txt" {
+		t.Errorf("explicit = %+v", c)
+	}
+	if cs[1].Direct {
+		t.Errorf("implicit = %+v", cs[1])
+	}
+}
@@ -0,0 +1,45 @@
+package deps
+
+import "strings"
+
+// scanGo reads go.mod requirements. go.mod records no edges between
+// modules, and a requirement marked // indirect is not direct.
+func scanGo(dir string) []Component {
+	src, ok := read(dir, "go.mod")
+	if !ok {
+		return nil
+	}
+	var out []Component
+	inBlock := false
+	for _, line := range strings.Split(src, "\n") {
+		t := strings.TrimSpace(line)
+		indirect := strings.Contains(t, "// indirect")
+		if i := strings.Index(t, "//"); i >= 0 {
+			t = strings.TrimSpace(t[:i])
+		}
+		switch {
+		case t == "require (":
+			inBlock = true
+			continue
+		case inBlock && t == ")":
+			inBlock = false
+			continue
+		case strings.HasPrefix(t, "require "):
+			t = strings.TrimSpace(strings.TrimPrefix(t, "require"))
+		case !inBlock:
+			continue
+		}
+		fields := strings.Fields(t)
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "v") {
+			continue
+		}
+		out = append(out, Component{
+			Name:      strings.Trim(fields[0], `"`),
+			Version:   fields[1],
+			Ecosystem: Go,
+			Direct:    !indirect,
+			Source:    "go.mod",
+		})
+	}
+	return out
+}
@@ -0,0 +1,167 @@
+package deps
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Replace is a replace directive from go.mod or go.work.
+type Replace struct {
+	Old        string `json:"old"`
+	OldVersion string `json:"old_version,omitempty"`
+	New        string `json:"new"`
+	NewVersion string `json:"new_version,omitempty"`
+	// Local is set when New is a filesystem path rather than a module.
+	Local bool `json:"local,omitempty"`
+}
+
+// Target renders the replacement as written after "=>".
+func (r Replace) Target() string {
+	if r.NewVersion != "" {
+		return r.New + " " + r.NewVersion
+	}
+	return r.New
+}
+
+// Applies reports whether the directive replaces module at version.
+func (r Replace) Applies(module, version string) bool {
+	return r.Old == module && (r.OldVersion == "" || r.OldVersion == version)
+}
+
+// WorkFile is the part of a go.work file that decides which module
+// versions a workspace build uses.
+type WorkFile struct {
+	// Dir is the directory holding go.work.
+	Dir string `json:"dir"`
+	// Use lists the absolute directories of the workspace modules.
+	Use     []string  `json:"use"`
+	Replace []Replace `json:"replace"`
+}
+
+// GoModulePath returns the module path declared in dir's go.mod.
+func GoModulePath(dir string) string {
+	src, ok := read(dir, "go.mod")
+	if !ok {
+		return ""
+	}
+	for _, args := range directives(src, "module") {
+		if len(args) == 1 {
+			return strings.Trim(args[0], `"`)
+		}
+	}
+	return ""
+}
+
+// GoReplaces returns the replace directives of dir's go.mod.
+func GoReplaces(dir string) []Replace {
+	src, ok := read(dir, "go.mod")
+	if !ok {
+		return nil
+	}
+	return replaces(src)
+}
+
+// GoWork parses dir's go.work, reporting false when there is none.
+func GoWork(dir string) (WorkFile, bool) {
+	src, ok := read(dir, "go.work")
+	if !ok {
+		return WorkFile{}, false
+	}
+	w := WorkFile{Dir: dir, Use: []string{}, Replace: replaces(src)}
+	for _, args := range directives(src, "use") {
+		if len(args) == 1 {
+			w.Use = append(w.Use, filepath.Join(dir, strings.Trim(args[0], `"`)))
+		}
+	}
+	if w.Replace == nil {
+		w.Replace = []Replace{}
+	}
+	return w, true
+}
+
+// VendoredGo reads the modules copied into dir's vendor directory from
+// vendor/modules.txt.
+func VendoredGo(dir string) []Component {
+	src, ok := read(dir, filepath.Join("vendor", "modules.txt"))
+	if !ok {
+		return nil
+	}
+	var out []Component
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		// "# path version" starts a module; "# path => replacement" lines
+		// carry no version. A following "## explicit" marks a requirement
+		// of the main module.
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "#" || !strings.HasPrefix(fields[2], "v") {
+			continue
+		}
+		out = append(out, Component{
+			Name:      fields[1],
+			Version:   fields[2],
+			Ecosystem: Go,
+			Direct:    i+1 < len(lines) && strings.HasPrefix(lines[i+1], "## explicit"),
+			Source:    "vendor/modules.txt",
+		})
+	}
+	return out
+}
+
+func replaces(src string) []Replace {
+	var out []Replace
+	for _, args := range directives(src, "replace") {
+		i := indexOf(args, "=>")
+		if i < 1 || i > 2 || len(args)-i-1 < 1 || len(args)-i-1 > 2 {
+			continue
+		}
+		r := Replace{Old: strings.Trim(args[0], `"`), New: strings.Trim(args[i+1], `"`)}
+		if i == 2 {
+			r.OldVersion = args[1]
+		}
+		if len(args) == i+3 {
+			r.NewVersion = args[i+2]
+		}
+		r.Local = r.NewVersion == "" && (strings.HasPrefix(r.New, ".") || filepath.IsAbs(r.New))
+		out = append(out, r)
+	}
+	return out
+}
+
+// directives returns the arguments of each use of keyword in a go.mod or
+// go.work file, in both the single-line and the parenthesized block form.
+func directives(src, keyword string) [][]string {
+	var out [][]string
+	inBlock := false
+	for _, line := range strings.Split(src, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case inBlock:
+			out = append(out, fields)
+			continue
+		case fields[0] != keyword:
+			continue
+		case len(fields) == 2 && fields[1] == "(":
+			inBlock = true
+			continue
+		}
+		out = append(out, fields[1:])
+	}
+	return out
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
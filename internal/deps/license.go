@@ -0,0 +1,261 @@
+package deps
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/mistakeknot/intermap/internal/license"
+)
+
+// ResolveLicenses fills in License for components whose lockfile did not
+// record one, from the installed package: vendor/ and the module cache for
+// Go, node_modules for npm, a .venv, venv, or env virtualenv for Python,
+// and vendor/ and the Cargo registry cache for Cargo. Declared metadata
+// wins over the text of a LICENSE file. Packages that are not installed
+// keep an empty License.
+func ResolveLicenses(dir string, cs []Component) {
+	var sitePackages []string
+	for i := range cs {
+		c := &cs[i]
+		if c.License != "" {
+			continue
+		}
+		switch c.Ecosystem {
+		case Go:
+			c.License = goLicense(dir, *c)
+		case NPM:
+			c.License = npmInstalledLicense(filepath.Join(dir, "node_modules", filepath.FromSlash(c.Name)))
+		case PyPI:
+			if sitePackages == nil {
+				sitePackages = findSitePackages(dir)
+			}
+			c.License = pythonLicense(sitePackages, *c)
+		case Cargo:
+			c.License = cargoLicense(dir, *c)
+		}
+	}
+}
+
+// licenseFileRe matches the names of license files in a package directory.
+var licenseFileRe = regexp.MustCompile(`(?i)^(licen[cs]e|copying)([-._].*)?$`)
+
+// licenseFromFiles identifies the license from the first recognized
+// license file in dir.
+func licenseFromFiles(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		if e.IsDir() || !licenseFileRe.MatchString(e.Name()) {
+			continue
+		}
+		if text, ok := read(dir, e.Name()); ok {
+			if id := license.Detect(text); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// --- Go ---
+
+func goLicense(dir string, c Component) string {
+	if id := licenseFromFiles(filepath.Join(dir, "vendor", filepath.FromSlash(c.Name))); id != "" {
+		return id
+	}
+	cache := goModCache()
+	if cache == "" {
+		return ""
+	}
+	return licenseFromFiles(filepath.Join(cache, filepath.FromSlash(escapeModulePath(c.Name))+"@"+c.Version))
+}
+
+func goModCache() string {
+	if d := os.Getenv("GOMODCACHE"); d != "" {
+		return d
+	}
+	if gopath := filepath.SplitList(os.Getenv("GOPATH")); len(gopath) > 0 && gopath[0] != "" {
+		return filepath.Join(gopath[0], "pkg", "mod")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, "go", "pkg", "mod")
+	}
+	return ""
+}
+
+// escapeModulePath applies the module cache's case encoding: each upper
+// case letter becomes "!" and its lower case form.
+func escapeModulePath(p string) string {
+	var b strings.Builder
+	for _, r := range p {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// --- npm ---
+
+// npmLicense reads package.json's "license", a string or an old-style
+// {"type": ...} object.
+func npmLicense(v any) string {
+	switch l := v.(type) {
+	case string:
+		return license.Normalize(l)
+	case map[string]any:
+		if t, ok := l["type"].(string); ok {
+			return license.Normalize(t)
+		}
+	}
+	return ""
+}
+
+func npmInstalledLicense(pkgDir string) string {
+	if src, ok := read(pkgDir, "package.json"); ok {
+		var pkg struct {
+			License  any   `json:"license"`
+			Licenses []any `json:"licenses"`
+		}
+		if json.Unmarshal([]byte(src), &pkg) == nil {
+			if id := npmLicense(pkg.License); id != "" {
+				return id
+			}
+			// The deprecated "licenses" array lists alternatives.
+			var ids []string
+			for _, l := range pkg.Licenses {
+				if id := npmLicense(l); id != "" {
+					ids = append(ids, id)
+				}
+			}
+			if len(ids) > 0 {
+				return strings.Join(ids, " OR ")
+			}
+		}
+	}
+	return licenseFromFiles(pkgDir)
+}
+
+// --- Python ---
+
+func findSitePackages(dir string) []string {
+	var out []string
+	for _, venv := range []string{".venv", "venv", "env"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, venv, "lib", "python*", "site-packages"))
+		out = append(out, matches...)
+		if win := filepath.Join(dir, venv, "Lib", "site-packages"); isDir(win) {
+			out = append(out, win)
+		}
+	}
+	if out == nil {
+		out = []string{}
+	}
+	return out
+}
+
+func isDir(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
+
+func pythonLicense(sitePackages []string, c Component) string {
+	want := NormalizePyPI(c.Name)
+	for _, sp := range sitePackages {
+		entries, err := os.ReadDir(sp)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			base, ok := strings.CutSuffix(e.Name(), ".dist-info")
+			if !ok {
+				continue
+			}
+			name, _, _ := strings.Cut(base, "-")
+			if NormalizePyPI(name) == want {
+				return distInfoLicense(filepath.Join(sp, e.Name()))
+			}
+		}
+	}
+	return ""
+}
+
+// distInfoLicense reads a wheel's METADATA: License-Expression (PEP 639),
+// then License classifiers, then a short License field, then the bundled
+// license files.
+func distInfoLicense(dir string) string {
+	src, _ := read(dir, "METADATA")
+	var field string
+	var classifiers []string
+	for _, line := range strings.Split(src, "\n") {
+		if line == "" || line == "\r" {
+			break // the description body follows the headers
+		}
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		val = strings.TrimSpace(val)
+		switch key {
+		case "License-Expression":
+			return license.Normalize(val)
+		case "License":
+			field = val
+		case "Classifier":
+			if id := license.FromClassifier(val); id != "" {
+				classifiers = append(classifiers, id)
+			}
+		}
+	}
+	if len(classifiers) > 0 {
+		return strings.Join(classifiers, " OR ")
+	}
+	// Some packages put the whole license text in License.
+	if field != "" && len(field) < 64 {
+		if id := license.Normalize(field); id != "" {
+			return id
+		}
+	}
+	if id := licenseFromFiles(filepath.Join(dir, "licenses")); id != "" {
+		return id
+	}
+	return licenseFromFiles(dir)
+}
+
+// --- Cargo ---
+
+var cargoLicenseField = regexp.MustCompile(`(?m)^\s*license\s*=\s*"([^"]+)"`)
+
+func cargoLicense(dir string, c Component) string {
+	candidates := []string{filepath.Join(dir, "vendor", c.Name)}
+	home := os.Getenv("CARGO_HOME")
+	if home == "" {
+		if h, err := os.UserHomeDir(); err == nil {
+			home = filepath.Join(h, ".cargo")
+		}
+	}
+	if home != "" {
+		matches, _ := filepath.Glob(filepath.Join(home, "registry", "src", "*", c.Name+"-"+c.Version))
+		candidates = append(candidates, matches...)
+	}
+	for _, crate := range candidates {
+		src, ok := read(crate, "Cargo.toml")
+		if !ok {
+			continue
+		}
+		if m := cargoLicenseField.FindStringSubmatch(src); m != nil {
+			return license.Normalize(m[1])
+		}
+		if id := licenseFromFiles(crate); id != "" {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+package deps
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+)
+
+type npmPackage struct {
+	Version              string            `json:"version"`
+	Dev                  bool              `json:"dev"`
+	Link                 bool              `json:"link"`
+	License              any               `json:"license"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// scanNPM reads package-lock.json (lockfile version 2 or 3, or the nested
+// version 1 layout), falling back to the dependencies package.json
+// declares, with versions only where they are exact.
+func scanNPM(dir string) []Component {
+	manifest := npmPackage{}
+	if src, ok := read(dir, "package.json"); ok {
+		json.Unmarshal([]byte(src), &manifest)
+	}
+	if src, ok := read(dir, "package-lock.json"); ok {
+		var lock struct {
+			Packages     map[string]npmPackage `json:"packages"`
+			Dependencies map[string]npmLockV1  `json:"dependencies"`
+		}
+		if json.Unmarshal([]byte(src), &lock) == nil {
+			if len(lock.Packages) > 0 {
+				return npmLockPackages(lock.Packages)
+			}
+			if len(lock.Dependencies) > 0 {
+				return npmLockV1Packages(lock.Dependencies, manifest)
+			}
+		}
+	}
+
+	var out []Component
+	add := func(deps map[string]string, dev bool) {
+		for name, spec := range deps {
+			c := Component{Name: name, Ecosystem: NPM, Direct: true, Dev: dev, Source: "package.json"}
+			if exactVersion.MatchString(spec) {
+				c.Version = strings.TrimPrefix(spec, "=")
+			}
+			out = append(out, c)
+		}
+	}
+	add(manifest.Dependencies, false)
+	add(manifest.OptionalDependencies, false)
+	add(manifest.DevDependencies, true)
+	return out
+}
+
+// npmLockPackages reads the "packages" map of lockfile version 2 and 3,
+// keyed by install path ("node_modules/a/node_modules/b"); "" is the
+// project itself.
+func npmLockPackages(pkgs map[string]npmPackage) []Component {
+	root := pkgs[""]
+	direct := make(map[string]bool)
+	for _, deps := range []map[string]string{root.Dependencies, root.DevDependencies, root.OptionalDependencies} {
+		for name := range deps {
+			direct[name] = true
+		}
+	}
+
+	// Node resolves a dependency from the nearest node_modules up the
+	// install path.
+	resolve := func(from, name string) (string, bool) {
+		dir := from
+		for {
+			key := "node_modules/" + name
+			if dir != "" {
+				key = dir + "/" + key
+			}
+			if p, ok := pkgs[key]; ok && !p.Link {
+				return key, true
+			}
+			if dir == "" {
+				return "", false
+			}
+			if i := strings.LastIndex(dir, "/node_modules/"); i >= 0 {
+				dir = dir[:i]
+			} else {
+				dir = ""
+			}
+		}
+	}
+
+	var out []Component
+	for key, p := range pkgs {
+		if key == "" || p.Link || !strings.Contains(key, "node_modules/") {
+			continue // the project, workspace links, and workspace members
+		}
+		name := npmName(key)
+		c := Component{
+			Name:      name,
+			Version:   p.Version,
+			Ecosystem: NPM,
+			Direct:    direct[name] && !strings.Contains(strings.TrimPrefix(key, "node_modules/"), "node_modules/"),
+			Dev:       p.Dev,
+			Source:    "package-lock.json",
+			License:   npmLicense(p.License),
+		}
+		for _, deps := range []map[string]string{p.Dependencies, p.OptionalDependencies} {
+			for dep := range deps {
+				if k, ok := resolve(key, dep); ok {
+					c.Requires = append(c.Requires, Component{Name: dep, Version: pkgs[k].Version, Ecosystem: NPM}.PURL())
+				}
+			}
+		}
+		c.Requires = dedupe(c.Requires)
+		out = append(out, c)
+	}
+	return out
+}
+
+// npmName is the package name at the end of an install path.
+func npmName(key string) string {
+	i := strings.LastIndex(key, "node_modules/")
+	name := key[i+len("node_modules/"):]
+	if !strings.HasPrefix(name, "@") {
+		return path.Base(name)
+	}
+	return name
+}
+
+type npmLockV1 struct {
+	Version      string               `json:"version"`
+	Dev          bool                 `json:"dev"`
+	Requires     map[string]string    `json:"requires"`
+	Dependencies map[string]npmLockV1 `json:"dependencies"`
+}
+
+// npmLockV1Packages reads lockfile version 1, where packages installed
+// below another nest in its "dependencies".
+func npmLockV1Packages(deps map[string]npmLockV1, manifest npmPackage) []Component {
+	direct := make(map[string]bool)
+	for _, m := range []map[string]string{manifest.Dependencies, manifest.DevDependencies, manifest.OptionalDependencies} {
+		for name := range m {
+			direct[name] = true
+		}
+	}
+	var out []Component
+	var walk func(deps map[string]npmLockV1, scopes []map[string]npmLockV1, top bool)
+	walk = func(deps map[string]npmLockV1, scopes []map[string]npmLockV1, top bool) {
+		scopes = append([]map[string]npmLockV1{deps}, scopes...)
+		for name, d := range deps {
+			c := Component{Name: name, Version: d.Version, Ecosystem: NPM, Direct: top && direct[name], Dev: d.Dev, Source: "package-lock.json"}
+			inner := append([]map[string]npmLockV1{d.Dependencies}, scopes...)
+			for req := range d.Requires {
+				for _, scope := range inner {
+					if r, ok := scope[req]; ok {
+						c.Requires = append(c.Requires, Component{Name: req, Version: r.Version, Ecosystem: NPM}.PURL())
+						break
+					}
+				}
+			}
+			out = append(out, c)
+			if len(d.Dependencies) > 0 {
+				walk(d.Dependencies, scopes, false)
+			}
+		}
+	}
+	walk(deps, nil, true)
+	return out
+}
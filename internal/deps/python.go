@@ -0,0 +1,128 @@
+package deps
+
+import (
+	"regexp"
+	"strings"
+)
+
+// requirement matches the name, and an exact == pin, of a PEP 508
+// requirement ("requests[socks] == 2.31.0 ; python_version > '3.8'").
+var requirement = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(?:===?\s*([^\s;,#*]+)\s*(?:[;#].*)?$)?`)
+
+// scanPython reads uv.lock or poetry.lock for the full dependency tree.
+// Direct dependencies come from the lockfile's own project entries (uv)
+// or from what pyproject.toml and requirements files declare (poetry, and
+// the fallback without a lockfile).
+func scanPython(dir string) []Component {
+	declared := pythonDeclared(dir)
+
+	if src, ok := read(dir, "uv.lock"); ok {
+		var local, third []lockPackage
+		for _, p := range parseLockPackages(src) {
+			if strings.Contains(p.Source, "editable") || strings.Contains(p.Source, "virtual") || strings.Contains(p.Source, "directory") {
+				local = append(local, p)
+			} else {
+				third = append(third, p)
+			}
+		}
+		direct, dev := make(map[string]bool), make(map[string]bool)
+		for _, p := range local {
+			for _, name := range p.Deps {
+				direct[NormalizePyPI(name)] = true
+			}
+		}
+		for _, p := range local {
+			for _, name := range p.DevDeps {
+				if n := NormalizePyPI(name); !direct[n] {
+					direct[n], dev[n] = true, true
+				}
+			}
+		}
+		return lockComponents(third, PyPI, "uv.lock", direct, dev, NormalizePyPI)
+	}
+
+	if src, ok := read(dir, "poetry.lock"); ok {
+		direct, dev := make(map[string]bool), make(map[string]bool)
+		for _, c := range declared {
+			n := NormalizePyPI(c.Name)
+			direct[n] = true
+			if c.Dev {
+				dev[n] = true
+			}
+		}
+		return lockComponents(parseLockPackages(src), PyPI, "poetry.lock", direct, dev, NormalizePyPI)
+	}
+	return declared
+}
+
+// pythonDeclared lists the dependencies pyproject.toml (PEP 621 and PEP
+// 735 tables, or Poetry's) and requirements.txt declare. A name declared
+// for both runtime and development counts as runtime.
+func pythonDeclared(dir string) []Component {
+	var out []Component
+	index := make(map[string]int)
+	add := func(spec, source string, dev bool) {
+		m := requirement.FindStringSubmatch(spec)
+		if m == nil || strings.EqualFold(m[1], "python") {
+			return
+		}
+		n := NormalizePyPI(m[1])
+		if i, ok := index[n]; ok {
+			out[i].Dev = out[i].Dev && dev
+			if out[i].Version == "" {
+				out[i].Version = m[2]
+			}
+			return
+		}
+		index[n] = len(out)
+		out = append(out, Component{Name: m[1], Version: m[2], Ecosystem: PyPI, Direct: true, Dev: dev, Source: source})
+	}
+
+	if src, ok := read(dir, "pyproject.toml"); ok {
+		for _, spec := range tomlArray(src, "project", "dependencies") {
+			add(spec, "pyproject.toml", false)
+		}
+		for _, name := range tomlSectionKeys(src, "tool.poetry.dependencies") {
+			add(name, "pyproject.toml", false)
+		}
+		for _, group := range tomlSectionKeys(src, "dependency-groups") {
+			for _, spec := range tomlArray(src, "dependency-groups", group) {
+				add(spec, "pyproject.toml", true)
+			}
+		}
+		for _, name := range tomlSectionKeys(src, "tool.poetry.dev-dependencies") {
+			add(name, "pyproject.toml", true)
+		}
+		for _, section := range poetryGroups(src) {
+			for _, name := range tomlSectionKeys(src, section) {
+				add(name, "pyproject.toml", true)
+			}
+		}
+	}
+	for _, file := range []string{"requirements.txt", "requirements-dev.txt"} {
+		src, ok := read(dir, file)
+		if !ok {
+			continue
+		}
+		for _, line := range strings.Split(src, "\n") {
+			t := strings.TrimSpace(line)
+			if t == "" || strings.HasPrefix(t, "#") || strings.HasPrefix(t, "-") {
+				continue
+			}
+			add(t, file, file != "requirements.txt")
+		}
+	}
+	return out
+}
+
+// poetryGroups lists the [tool.poetry.group.<name>.dependencies] sections.
+func poetryGroups(src string) []string {
+	var out []string
+	for _, line := range strings.Split(src, "\n") {
+		t := strings.Trim(strings.TrimSpace(line), "[]")
+		if strings.HasPrefix(t, "tool.poetry.group.") && strings.HasSuffix(t, ".dependencies") {
+			out = append(out, t)
+		}
+	}
+	return out
+}
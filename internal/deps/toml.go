@@ -0,0 +1,198 @@
+package deps
+
+import (
+	"regexp"
+	"strings"
+)
+
+// lockPackage is one [[package]] entry of a TOML lockfile (Cargo.lock,
+// uv.lock, poetry.lock).
+type lockPackage struct {
+	Name    string
+	Version string
+	// Source is the raw source value; Cargo and uv leave it out or point
+	// it at the project tree for workspace members.
+	Source string
+	// Deps are dependency names, with a version where the lockfile gives
+	// one to tell same-named packages apart ("name 1.0.0" in Cargo.lock).
+	Deps []string
+	// DevDeps are uv's [package.dev-dependencies] groups.
+	DevDeps  []string
+	Category string // poetry's "main" or "dev", in older lockfiles
+}
+
+var (
+	tomlString    = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+	tomlNameField = regexp.MustCompile(`\bname\s*=\s*"([^"]+)"`)
+)
+
+// parseLockPackages reads the [[package]] entries of a lockfile. It only
+// understands the regular layout lockfile generators write: one key per
+// line, with arrays of strings or inline tables that may span lines.
+func parseLockPackages(src string) []lockPackage {
+	var (
+		out   []lockPackage
+		cur   *lockPackage
+		table string    // the current [table] header inside a package
+		into  *[]string // where a multi-line array being read goes
+		array strings.Builder
+	)
+	for _, line := range strings.Split(src, "\n") {
+		t := strings.TrimSpace(line)
+		if into != nil {
+			array.WriteString(t + "\n")
+			if strings.HasPrefix(t, "]") {
+				*into = append(*into, lockDeps(array.String())...)
+				into = nil
+				array.Reset()
+			}
+			continue
+		}
+		if t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		if t == "[[package]]" {
+			out = append(out, lockPackage{})
+			cur = &out[len(out)-1]
+			table = "package"
+			continue
+		}
+		if strings.HasPrefix(t, "[") {
+			table = strings.Trim(t, "[]")
+			if !strings.HasPrefix(table, "package.") {
+				cur = nil
+			}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		key, val, ok := strings.Cut(t, "=")
+		if !ok {
+			continue
+		}
+		key, val = strings.Trim(strings.TrimSpace(key), `"`), strings.TrimSpace(val)
+		var deps *[]string
+		switch {
+		case table == "package.dependencies":
+			cur.Deps = append(cur.Deps, key) // poetry: name = "spec"
+			continue
+		case table == "package.dev-dependencies":
+			deps = &cur.DevDeps
+		case table == "package" && key == "dependencies":
+			deps = &cur.Deps
+		}
+		if deps != nil {
+			if strings.HasPrefix(val, "[") && !strings.HasSuffix(val, "]") {
+				into = deps
+				array.WriteString(val + "\n")
+			} else {
+				*deps = append(*deps, lockDeps(val)...)
+			}
+			continue
+		}
+		if table != "package" {
+			continue
+		}
+		switch key {
+		case "name":
+			cur.Name = unquote(val)
+		case "version":
+			cur.Version = unquote(val)
+		case "source":
+			cur.Source = val
+		case "category":
+			cur.Category = unquote(val)
+		}
+	}
+	return out
+}
+
+// lockDeps reads a dependencies array: strings ("serde", "serde 1.0.1")
+// or inline tables ({ name = "idna" }).
+func lockDeps(val string) []string {
+	var out []string
+	if m := tomlNameField.FindAllStringSubmatch(val, -1); len(m) > 0 {
+		for _, n := range m {
+			out = append(out, n[1])
+		}
+		return out
+	}
+	for _, s := range tomlString.FindAllStringSubmatch(val, -1) {
+		// Cargo may add the source: "name version (registry+...)".
+		fields := strings.Fields(s[1])
+		if len(fields) > 2 {
+			fields = fields[:2]
+		}
+		out = append(out, strings.Join(fields, " "))
+	}
+	return out
+}
+
+func unquote(val string) string {
+	if m := tomlString.FindStringSubmatch(val); m != nil {
+		return m[1]
+	}
+	return val
+}
+
+// tomlSectionKeys returns the keys of the named table ("dependencies",
+// "tool.poetry.dependencies"), one per line.
+func tomlSectionKeys(src, section string) []string {
+	var out []string
+	in := false
+	for _, line := range strings.Split(src, "\n") {
+		t := strings.TrimSpace(line)
+		if strings.HasPrefix(t, "[") {
+			in = strings.Trim(t, "[] ") == section
+			continue
+		}
+		if !in || t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		if key, _, ok := strings.Cut(t, "="); ok {
+			out = append(out, strings.Trim(strings.TrimSpace(key), `"`))
+		}
+	}
+	return out
+}
+
+// tomlArray returns the strings of the array assigned to key in the named
+// table ("project" for key "dependencies").
+func tomlArray(src, section, key string) []string {
+	in := section == ""
+	var buf strings.Builder
+	reading := false
+	for _, line := range strings.Split(src, "\n") {
+		t := strings.TrimSpace(line)
+		if reading {
+			buf.WriteString(t + "\n")
+			if strings.HasPrefix(t, "]") {
+				break
+			}
+			continue
+		}
+		if strings.HasPrefix(t, "[") && !strings.Contains(t, "=") {
+			in = strings.Trim(t, "[] ") == section
+			continue
+		}
+		if !in {
+			continue
+		}
+		k, v, ok := strings.Cut(t, "=")
+		if !ok || strings.TrimSpace(k) != key {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		buf.WriteString(v + "\n")
+		if !strings.HasPrefix(v, "[") || strings.HasSuffix(v, "]") {
+			break
+		}
+		reading = true
+	}
+	var out []string
+	for _, s := range tomlString.FindAllStringSubmatch(buf.String(), -1) {
+		out = append(out, s[1])
+	}
+	return out
+}
@@ -0,0 +1,347 @@
+// Package editor serves JSON-RPC 2.0 for editor plugins (VS Code, Neovim),
+// one message per line, so developers see the same map agents do: which
+// project the buffer belongs to, which agents touch the file, and the
+// impact of the symbol under the cursor. Requests run through the
+// registered tools, middleware included.
+package editor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mistakeknot/intermap/internal/tools"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// JSON-RPC error codes.
+const (
+	codeParse          = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	// codeTool is a tool error; its data is the structured tool error.
+	codeTool = -32000
+)
+
+// Methods lists the supported methods, as reported by initialize.
+var Methods = []string{"initialize", "project/resolve", "file/agents", "symbol/impact", "tools/call", "shutdown"}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// FileParams name a file, absolute or relative to the server's working
+// directory.
+type FileParams struct {
+	File string `json:"file"`
+}
+
+// PositionParams are a cursor position: zero-based line and character
+// (rune) offsets, as in LSP.
+type PositionParams struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Character int    `json:"character"`
+	// MaxDepth bounds the caller tree (default 3).
+	MaxDepth int `json:"max_depth,omitempty"`
+}
+
+// CallParams run any tool.
+type CallParams struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// Server answers requests by calling tools.
+type Server struct {
+	call tools.Caller
+	mu   sync.Mutex
+	out  *json.Encoder
+}
+
+// New returns a Server calling tools through call.
+func New(call tools.Caller) *Server {
+	return &Server{call: call}
+}
+
+// Serve reads requests from r and writes responses to w until r ends,
+// shutdown is requested, or ctx is done. Requests are answered
+// concurrently, so responses may arrive out of order; notifications (no
+// id) get no response.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.out = json.NewEncoder(w)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16<<20)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for sc.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var req request
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			s.reply(nil, nil, &rpcError{Code: codeParse, Message: err.Error()})
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			s.reply(req.ID, nil, &rpcError{Code: codeInvalidRequest, Message: `want {"jsonrpc": "2.0", "method": ...}`})
+			continue
+		}
+		if req.Method == "shutdown" {
+			s.reply(req.ID, map[string]any{}, nil)
+			return nil
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := s.handle(ctx, req)
+			if req.ID == nil {
+				return
+			}
+			var rerr *rpcError
+			if err != nil && !errors.As(err, &rerr) {
+				rerr = &rpcError{Code: codeTool, Message: err.Error()}
+			}
+			s.reply(req.ID, result, rerr)
+		}()
+	}
+	return sc.Err()
+}
+
+func (s *Server) reply(id json.RawMessage, result any, err *rpcError) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := response{JSONRPC: "2.0", ID: id, Error: err}
+	if err == nil {
+		resp.Result = result
+	}
+	s.out.Encode(resp)
+}
+
+func (s *Server) handle(ctx context.Context, req request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		return map[string]any{"name": "intermap", "methods": Methods}, nil
+	case "project/resolve":
+		var p FileParams
+		if err := params(req, &p); err != nil {
+			return nil, err
+		}
+		if err := needFile(p.File); err != nil {
+			return nil, err
+		}
+		return s.tool(ctx, "resolve_project", map[string]any{"path": p.File})
+	case "file/agents":
+		var p FileParams
+		if err := params(req, &p); err != nil {
+			return nil, err
+		}
+		if err := needFile(p.File); err != nil {
+			return nil, err
+		}
+		return s.fileAgents(ctx, p.File)
+	case "symbol/impact":
+		var p PositionParams
+		if err := params(req, &p); err != nil {
+			return nil, err
+		}
+		if err := needFile(p.File); err != nil {
+			return nil, err
+		}
+		return s.symbolImpact(ctx, p)
+	case "tools/call":
+		var p CallParams
+		if err := params(req, &p); err != nil {
+			return nil, err
+		}
+		if p.Tool == "" {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "tool is required"}
+		}
+		return s.tool(ctx, p.Tool, p.Arguments)
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "unknown method " + req.Method}
+}
+
+// params decodes req's params into v.
+func params(req request, v any) error {
+	if len(req.Params) == 0 || json.Unmarshal(req.Params, v) != nil {
+		return &rpcError{Code: codeInvalidParams, Message: "params must be an object of the method's fields"}
+	}
+	return nil
+}
+
+// needFile rejects params without a file.
+func needFile(file string) error {
+	if file == "" {
+		return &rpcError{Code: codeInvalidParams, Message: "file is required"}
+	}
+	return nil
+}
+
+// tool runs a tool and returns its decoded JSON result.
+func (s *Server) tool(ctx context.Context, name string, args map[string]any) (any, error) {
+	res, err := s.call(ctx, name, args)
+	if err != nil {
+		return nil, err
+	}
+	text := ""
+	if res != nil && len(res.Content) > 0 {
+		if tc, ok := res.Content[0].(mcp.TextContent); ok {
+			text = tc.Text
+		}
+	}
+	var v any
+	if json.Unmarshal([]byte(text), &v) != nil {
+		v = text
+	}
+	if res != nil && res.IsError {
+		msg := text
+		if m, ok := v.(map[string]any); ok {
+			if te, ok := m["message"].(string); ok {
+				msg = te
+			}
+		}
+		return nil, &rpcError{Code: codeTool, Message: msg, Data: v}
+	}
+	return v, nil
+}
+
+// resolve returns the project holding file, and file relative to it.
+func (s *Server) resolve(ctx context.Context, file string) (registry.Project, string, error) {
+	var p registry.Project
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return p, "", err
+	}
+	v, err := s.tool(ctx, "resolve_project", map[string]any{"path": abs})
+	if err != nil {
+		return p, "", err
+	}
+	data, _ := json.Marshal(v)
+	if err := json.Unmarshal(data, &p); err != nil || p.Path == "" {
+		return p, "", fmt.Errorf("resolve_project gave no project for %s", file)
+	}
+	rel, err := filepath.Rel(p.Path, abs)
+	if err != nil {
+		return p, "", err
+	}
+	return p, filepath.ToSlash(rel), nil
+}
+
+func (s *Server) fileAgents(ctx context.Context, file string) (any, error) {
+	p, rel, err := s.resolve(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.tool(ctx, "who_touches", map[string]any{"project": p.Path, "path": rel})
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{"project": p, "file": rel}
+	if m, ok := v.(map[string]any); ok {
+		out["agents_available"] = m["agents_available"]
+		if e, ok := m["agents_error"]; ok {
+			out["agents_error"] = e
+		}
+		if files, ok := m["files"].([]any); ok && len(files) > 0 {
+			out["touch"] = files[0]
+		}
+	}
+	return out, nil
+}
+
+func (s *Server) symbolImpact(ctx context.Context, pos PositionParams) (any, error) {
+	data, err := os.ReadFile(pos.File)
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	symbol := identifierAt(string(data), pos.Line, pos.Character)
+	if symbol == "" {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("no identifier at %d:%d", pos.Line, pos.Character)}
+	}
+	p, _, err := s.resolve(ctx, pos.File)
+	if err != nil {
+		return nil, err
+	}
+	depth := pos.MaxDepth
+	if depth <= 0 {
+		depth = 3
+	}
+	args := map[string]any{"project": p.Path, "target": symbol, "max_depth": depth}
+	if p.Language != "" && p.Language != "unknown" {
+		args["language"] = p.Language
+	}
+	v, err := s.tool(ctx, "impact_analysis", args)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"symbol": symbol, "project": p, "impact": v}, nil
+}
+
+// identifierAt returns the identifier spanning the zero-based line and
+// rune offset, or "" when the cursor is not on one. A cursor just past
+// an identifier's end counts as on it.
+func identifierAt(text string, line, char int) string {
+	lines := strings.Split(text, "\n")
+	if line < 0 || line >= len(lines) || char < 0 {
+		return ""
+	}
+	runes := []rune(strings.TrimRight(lines[line], "\r"))
+	if char > len(runes) {
+		return ""
+	}
+	isIdent := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	start, end := char, char
+	if end == len(runes) || !isIdent(runes[end]) {
+		if start == 0 || !isIdent(runes[start-1]) {
+			return ""
+		}
+	}
+	for start > 0 && isIdent(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && isIdent(runes[end]) {
+		end++
+	}
+	ident := string(runes[start:end])
+	if r, _ := utf8.DecodeRuneInString(ident); unicode.IsDigit(r) {
+		return ""
+	}
+	return ident
+}
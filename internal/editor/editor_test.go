@@ -0,0 +1,116 @@
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mistakeknot/interbase/go/mcputil"
+)
+
+func TestIdentifierAt(t *testing.T) {
+	text := "package app\n\nfunc run() {\n\tx := parseArgs(os.Args)\n}\n"
+	for _, tt := range []struct {
+		line, char int
+		want       string
+	}{
+		{3, 6, "parseArgs"},
+		{3, 15, "parseArgs"}, // just past the end
+		{3, 1, "x"},
+		{3, 3, ""},
+		{2, 5, "run"},
+		{9, 0, ""},
+		{3, 99, ""},
+	} {
+		if got := identifierAt(text, tt.line, tt.char); got != tt.want {
+			t.Errorf("identifierAt(%d, %d) = %q, want %q", tt.line, tt.char, got, tt.want)
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n\nfunc main() { serve() }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	call := func(ctx context.Context, tool string, args map[string]any) (*mcp.CallToolResult, error) {
+		switch tool {
+		case "resolve_project":
+			b, _ := json.Marshal(map[string]any{"name": "app", "path": dir, "language": "go"})
+			return mcp.NewToolResultText(string(b)), nil
+		case "who_touches":
+			if args["path"] != "main.go" {
+				t.Errorf("who_touches path = %v", args["path"])
+			}
+			return mcp.NewToolResultText(`{"agents_available": true, "files": [{"file": "main.go", "reservations": [{"agent_id": "a1", "pattern": "*.go"}]}]}`), nil
+		case "impact_analysis":
+			if args["target"] != "serve" || args["language"] != "go" {
+				t.Errorf("impact_analysis args = %v", args)
+			}
+			return mcp.NewToolResultText(`{"targets": {"serve": {"function": "serve", "callers": []}}}`), nil
+		}
+		return mcputil.NotFoundError("unknown tool %s", tool)
+	}
+
+	in := strings.Join([]string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize"}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "file/agents", "params": {"file": "` + file + `"}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "symbol/impact", "params": {"file": "` + file + `", "line": 2, "character": 16}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "tools/call", "params": {"tool": "nope"}}`,
+		`{"jsonrpc": "2.0", "id": 5, "method": "symbol/impact", "params": {"file": "` + file + `", "line": 1, "character": 0}}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "bogus"}`,
+		`{"jsonrpc": "2.0", "method": "file/agents", "params": {"file": "` + file + `"}}`,
+		`not json`,
+		`{"jsonrpc": "2.0", "id": 7, "method": "project/resolve", "params": {}}`,
+		`{"jsonrpc": "2.0", "id": 8, "method": "shutdown"}`,
+		`{"jsonrpc": "2.0", "id": 9, "method": "initialize"}`,
+	}, "\n")
+	var out strings.Builder
+	if err := New(call).Serve(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	byID := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var resp map[string]any
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("bad response %q: %v", line, err)
+		}
+		id, _ := json.Marshal(resp["id"])
+		byID[string(id)] = resp
+	}
+	if len(byID) != 9 {
+		t.Errorf("got %d responses, want 9 (the notification and anything after shutdown unanswered):\n%s", len(byID), out.String())
+	}
+	result := func(id string) map[string]any {
+		r, _ := byID[id]["result"].(map[string]any)
+		return r
+	}
+	code := func(id string) float64 {
+		e, _ := byID[id]["error"].(map[string]any)
+		c, _ := e["code"].(float64)
+		return c
+	}
+	if touch, _ := result("2")["touch"].(map[string]any); touch["file"] != "main.go" || result("2")["file"] != "main.go" {
+		t.Errorf("file/agents = %v", byID["2"])
+	}
+	if r := result("3"); r["symbol"] != "serve" || r["impact"] == nil {
+		t.Errorf("symbol/impact = %v", byID["3"])
+	}
+	for id, want := range map[string]float64{"4": codeTool, "5": codeInvalidParams, "6": codeMethodNotFound, "null": codeParse, "7": codeInvalidParams} {
+		if got := code(id); got != want {
+			t.Errorf("response %s error code = %v, want %v: %v", id, got, want, byID[id])
+		}
+	}
+	if e, _ := byID["4"]["error"].(map[string]any); e["message"] != "unknown tool nope" {
+		t.Errorf("tool error = %v", e)
+	}
+	if byID["9"] != nil {
+		t.Error("request after shutdown was answered")
+	}
+}
@@ -0,0 +1,206 @@
+// Package export serializes the workspace map as a property graph in
+// JSON Graph Format (JGF) or GraphML, for loading into Neo4j, Gephi, or
+// custom dashboards.
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+)
+
+// Node types used in the workspace map.
+const (
+	NodeProject = "project"
+	NodeSymbol  = "symbol"
+	NodeAgent   = "agent"
+)
+
+// Edge relations used in the workspace map.
+const (
+	RelDependsOn = "depends_on"
+	RelDefines   = "defines"
+	RelWorksOn   = "works_on"
+)
+
+// Node is a vertex in the workspace graph.
+type Node struct {
+	ID       string         `json:"-"`
+	Label    string         `json:"label"`
+	Type     string         `json:"type"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// Edge is a directed, labelled edge in the workspace graph.
+type Edge struct {
+	Source   string         `json:"source"`
+	Target   string         `json:"target"`
+	Relation string         `json:"relation"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// Graph is the workspace map.
+type Graph struct {
+	Label string
+	Nodes []Node
+	Edges []Edge
+}
+
+// Formats lists the supported output formats.
+var Formats = []string{"json", "graphml"}
+
+// Encode renders the graph in the named format ("json" for JGF, or "graphml").
+func (g *Graph) Encode(format string) ([]byte, error) {
+	switch format {
+	case "", "json", "jgf":
+		return g.JGF()
+	case "graphml":
+		return g.GraphML()
+	default:
+		return nil, fmt.Errorf("unknown export format %q (want json or graphml)", format)
+	}
+}
+
+// JGF renders the graph as JSON Graph Format v2.
+func (g *Graph) JGF() ([]byte, error) {
+	nodes := make(map[string]Node, len(g.Nodes))
+	for _, n := range g.Nodes {
+		nodes[n.ID] = n
+	}
+	doc := map[string]any{
+		"graph": map[string]any{
+			"label":    g.Label,
+			"directed": true,
+			"nodes":    nodes,
+			"edges":    g.Edges,
+		},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+type gmlKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type gmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type gmlNode struct {
+	ID   string    `xml:"id,attr"`
+	Data []gmlData `xml:"data"`
+}
+
+type gmlEdge struct {
+	Source string    `xml:"source,attr"`
+	Target string    `xml:"target,attr"`
+	Data   []gmlData `xml:"data"`
+}
+
+type gmlGraph struct {
+	ID          string    `xml:"id,attr"`
+	EdgeDefault string    `xml:"edgedefault,attr"`
+	Nodes       []gmlNode `xml:"node"`
+	Edges       []gmlEdge `xml:"edge"`
+}
+
+type gmlDoc struct {
+	XMLName xml.Name `xml:"graphml"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	Keys    []gmlKey `xml:"key"`
+	Graph   gmlGraph `xml:"graph"`
+}
+
+// GraphML renders the graph as GraphML. Label, type, and relation become
+// typed keys; metadata values are flattened to string keys prefixed "n_"
+// (nodes) or "e_" (edges).
+func (g *Graph) GraphML() ([]byte, error) {
+	var nodeMaps, edgeMaps []map[string]any
+	for _, n := range g.Nodes {
+		nodeMaps = append(nodeMaps, n.Metadata)
+	}
+	for _, e := range g.Edges {
+		edgeMaps = append(edgeMaps, e.Metadata)
+	}
+	nodeMeta, edgeMeta := metaKeys(nodeMaps), metaKeys(edgeMaps)
+
+	doc := gmlDoc{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []gmlKey{
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "type", For: "node", AttrName: "type", AttrType: "string"},
+			{ID: "relation", For: "edge", AttrName: "relation", AttrType: "string"},
+		},
+		Graph: gmlGraph{ID: g.Label, EdgeDefault: "directed"},
+	}
+	for _, k := range nodeMeta {
+		doc.Keys = append(doc.Keys, gmlKey{ID: "n_" + k, For: "node", AttrName: k, AttrType: "string"})
+	}
+	for _, k := range edgeMeta {
+		doc.Keys = append(doc.Keys, gmlKey{ID: "e_" + k, For: "edge", AttrName: k, AttrType: "string"})
+	}
+
+	for _, n := range g.Nodes {
+		gn := gmlNode{ID: n.ID, Data: []gmlData{{Key: "label", Value: n.Label}, {Key: "type", Value: n.Type}}}
+		gn.Data = append(gn.Data, metaData("n_", nodeMeta, n.Metadata)...)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gn)
+	}
+	for _, e := range g.Edges {
+		ge := gmlEdge{Source: e.Source, Target: e.Target, Data: []gmlData{{Key: "relation", Value: e.Relation}}}
+		ge.Data = append(ge.Data, metaData("e_", edgeMeta, e.Metadata)...)
+		doc.Graph.Edges = append(doc.Graph.Edges, ge)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("encode graphml: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// metaKeys returns the sorted union of keys across metadata maps.
+func metaKeys(maps []map[string]any) []string {
+	seen := make(map[string]bool)
+	for _, m := range maps {
+		for k := range m {
+			seen[k] = true
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// metaData flattens metadata values to strings, JSON-encoding non-strings.
+func metaData(prefix string, keys []string, m map[string]any) []gmlData {
+	var out []gmlData
+	for _, k := range keys {
+		v, ok := m[k]
+		if !ok || v == nil {
+			continue
+		}
+		var s string
+		switch val := v.(type) {
+		case string:
+			s = val
+		default:
+			b, _ := json.Marshal(val)
+			s = string(b)
+		}
+		out = append(out, gmlData{Key: prefix + k, Value: s})
+	}
+	return out
+}
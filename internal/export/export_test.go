@@ -0,0 +1,69 @@
+package export
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func testGraph() *Graph {
+	return &Graph{
+		Label: "workspace",
+		Nodes: []Node{
+			{ID: "project:a", Label: "a", Type: NodeProject, Metadata: map[string]any{"language": "go"}},
+			{ID: "project:b", Label: "b", Type: NodeProject, Metadata: map[string]any{"language": "python"}},
+			{ID: "agent:x", Label: "builder", Type: NodeAgent, Metadata: map[string]any{"reservations": []string{"*.go"}}},
+		},
+		Edges: []Edge{
+			{Source: "project:a", Target: "project:b", Relation: RelDependsOn, Metadata: map[string]any{"type": "go_module"}},
+			{Source: "agent:x", Target: "project:a", Relation: RelWorksOn},
+		},
+	}
+}
+
+func TestJGF(t *testing.T) {
+	data, err := testGraph().Encode("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Graph struct {
+			Directed bool                       `json:"directed"`
+			Nodes    map[string]json.RawMessage `json:"nodes"`
+			Edges    []Edge                     `json:"edges"`
+		} `json:"graph"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JGF: %v", err)
+	}
+	if !doc.Graph.Directed || len(doc.Graph.Nodes) != 3 || len(doc.Graph.Edges) != 2 {
+		t.Errorf("unexpected JGF shape: %s", data)
+	}
+}
+
+func TestGraphML(t *testing.T) {
+	data, err := testGraph().Encode("graphml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc gmlDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid GraphML: %v", err)
+	}
+	if len(doc.Graph.Nodes) != 3 || len(doc.Graph.Edges) != 2 {
+		t.Errorf("unexpected GraphML shape: %s", data)
+	}
+	if !strings.Contains(string(data), `attr.name="language"`) {
+		t.Errorf("expected metadata key for language:\n%s", data)
+	}
+	if !strings.Contains(string(data), `[&#34;*.go&#34;]`) {
+		t.Errorf("expected JSON-encoded slice metadata:\n%s", data)
+	}
+}
+
+func TestEncode_UnknownFormat(t *testing.T) {
+	if _, err := testGraph().Encode("dot"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
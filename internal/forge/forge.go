@@ -0,0 +1,297 @@
+// Package forge is a minimal GitHub/GitLab REST client for reading pull
+// request metadata and posting summary comments.
+//
+// Tokens come from GITHUB_TOKEN or GITLAB_TOKEN. API base URLs default to
+// api.github.com for github.com remotes, https://<host>/api/v3 for GitHub
+// Enterprise, and https://<host>/api/v4 for GitLab; GITHUB_API_URL and
+// GITLAB_API_URL override them. The remote's host comes from the
+// repository, so a token is only sent to a derived URL when the host is
+// github.com, gitlab.com, or listed in INTERMAP_FORGE_HOSTS
+// (comma-separated).
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Forge kinds.
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+)
+
+// Remote identifies a repository on a forge.
+type Remote struct {
+	Kind string
+	Host string
+	// Path is "owner/repo" on GitHub and the full namespace path on GitLab.
+	Path string
+}
+
+// PullRequest is the subset of PR/MR metadata intermap needs.
+type PullRequest struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	BaseRef string `json:"base_ref"`
+	HeadRef string `json:"head_ref"`
+	HeadSHA string `json:"head_sha"`
+	URL     string `json:"url"`
+}
+
+// ParseRemote parses a git remote URL (https or scp-style ssh). The forge
+// kind is inferred from the host: hosts containing "gitlab" are GitLab,
+// everything else is GitHub.
+func ParseRemote(raw string) (Remote, error) {
+	raw = strings.TrimSpace(raw)
+	var host, path string
+	switch {
+	case strings.Contains(raw, "://"):
+		u, err := url.Parse(raw)
+		if err != nil {
+			return Remote{}, fmt.Errorf("parse remote %q: %w", raw, err)
+		}
+		host, path = u.Hostname(), u.Path
+	case strings.Contains(raw, ":"):
+		// git@host:owner/repo.git
+		at := strings.Index(raw, "@")
+		colon := strings.Index(raw, ":")
+		host, path = raw[at+1:colon], raw[colon+1:]
+	default:
+		return Remote{}, fmt.Errorf("unrecognized remote %q", raw)
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || strings.Count(path, "/") < 1 {
+		return Remote{}, fmt.Errorf("unrecognized remote %q", raw)
+	}
+	kind := GitHub
+	if strings.Contains(host, "gitlab") {
+		kind = GitLab
+	}
+	return Remote{Kind: kind, Host: host, Path: path}, nil
+}
+
+// Client talks to one repository's forge API.
+type Client struct {
+	remote Remote
+	apiURL string
+	token  string
+	http   *http.Client
+	// chosen reports that the API URL was configured, not derived from
+	// the remote.
+	chosen bool
+}
+
+// Option configures the client.
+type Option func(*Client)
+
+// WithAPIURL overrides the API base URL.
+func WithAPIURL(u string) Option {
+	return func(c *Client) {
+		c.apiURL = strings.TrimRight(u, "/")
+		c.chosen = true
+	}
+}
+
+// WithToken overrides the token read from the environment.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// NewClient creates a client for remote. It fails if no token is available.
+func NewClient(remote Remote, opts ...Option) (*Client, error) {
+	c := &Client{
+		remote: remote,
+		http:   &http.Client{Timeout: 15 * time.Second},
+	}
+	switch remote.Kind {
+	case GitHub:
+		c.token = os.Getenv("GITHUB_TOKEN")
+		c.apiURL = os.Getenv("GITHUB_API_URL")
+		c.chosen = c.apiURL != ""
+		if c.apiURL == "" {
+			c.apiURL = "https://api.github.com"
+			if remote.Host != "github.com" {
+				c.apiURL = "https://" + remote.Host + "/api/v3"
+			}
+		}
+	case GitLab:
+		c.token = os.Getenv("GITLAB_TOKEN")
+		c.apiURL = os.Getenv("GITLAB_API_URL")
+		c.chosen = c.apiURL != ""
+		if c.apiURL == "" {
+			c.apiURL = "https://" + remote.Host + "/api/v4"
+		}
+	default:
+		return nil, fmt.Errorf("unsupported forge %q", remote.Kind)
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.token == "" {
+		return nil, fmt.Errorf("no %s token (set %s_TOKEN)", remote.Kind, strings.ToUpper(remote.Kind))
+	}
+	if !c.chosen && !TrustedHost(remote.Host) {
+		return nil, fmt.Errorf("refusing to send the %s token to %s: set %s_API_URL or add the host to INTERMAP_FORGE_HOSTS",
+			remote.Kind, remote.Host, strings.ToUpper(remote.Kind))
+	}
+	c.apiURL = strings.TrimRight(c.apiURL, "/")
+	return c, nil
+}
+
+// TrustedHost reports whether a token may be sent to the API of a remote
+// on host: github.com, gitlab.com, or a host in INTERMAP_FORGE_HOSTS.
+func TrustedHost(host string) bool {
+	if host == "github.com" || host == "gitlab.com" {
+		return true
+	}
+	for _, h := range strings.Split(os.Getenv("INTERMAP_FORGE_HOSTS"), ",") {
+		if strings.EqualFold(strings.TrimSpace(h), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// PullRequest fetches PR (GitHub) or MR (GitLab) metadata.
+func (c *Client) PullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	if c.remote.Kind == GitLab {
+		var mr struct {
+			IID          int    `json:"iid"`
+			Title        string `json:"title"`
+			TargetBranch string `json:"target_branch"`
+			SourceBranch string `json:"source_branch"`
+			SHA          string `json:"sha"`
+			WebURL       string `json:"web_url"`
+		}
+		if err := c.do(ctx, "GET", fmt.Sprintf("%s/merge_requests/%d", c.gitlabProject(), number), nil, &mr); err != nil {
+			return nil, err
+		}
+		return &PullRequest{Number: mr.IID, Title: mr.Title, BaseRef: mr.TargetBranch, HeadRef: mr.SourceBranch, HeadSHA: mr.SHA, URL: mr.WebURL}, nil
+	}
+
+	var pr struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+		Base    struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+		Head struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := c.do(ctx, "GET", fmt.Sprintf("/repos/%s/pulls/%d", c.remote.Path, number), nil, &pr); err != nil {
+		return nil, err
+	}
+	return &PullRequest{Number: pr.Number, Title: pr.Title, BaseRef: pr.Base.Ref, HeadRef: pr.Head.Ref, HeadSHA: pr.Head.SHA, URL: pr.HTMLURL}, nil
+}
+
+// UpsertComment posts body as a PR comment, or edits the existing comment
+// that contains marker so repeated runs don't pile up. It returns the
+// comment URL when the forge provides one.
+func (c *Client) UpsertComment(ctx context.Context, number int, marker, body string) (string, error) {
+	if !strings.Contains(body, marker) {
+		body = marker + "\n" + body
+	}
+	if c.remote.Kind == GitLab {
+		return c.upsertGitLabNote(ctx, number, marker, body)
+	}
+
+	var comments []struct {
+		ID      int64  `json:"id"`
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+	}
+	listPath := fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=100", c.remote.Path, number)
+	if err := c.do(ctx, "GET", listPath, nil, &comments); err != nil {
+		return "", err
+	}
+	var out struct {
+		HTMLURL string `json:"html_url"`
+	}
+	for _, cm := range comments {
+		if strings.Contains(cm.Body, marker) {
+			err := c.do(ctx, "PATCH", fmt.Sprintf("/repos/%s/issues/comments/%d", c.remote.Path, cm.ID), map[string]string{"body": body}, &out)
+			return out.HTMLURL, err
+		}
+	}
+	err := c.do(ctx, "POST", fmt.Sprintf("/repos/%s/issues/%d/comments", c.remote.Path, number), map[string]string{"body": body}, &out)
+	return out.HTMLURL, err
+}
+
+func (c *Client) upsertGitLabNote(ctx context.Context, number int, marker, body string) (string, error) {
+	base := fmt.Sprintf("%s/merge_requests/%d/notes", c.gitlabProject(), number)
+	var notes []struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+	}
+	if err := c.do(ctx, "GET", base+"?per_page=100", nil, &notes); err != nil {
+		return "", err
+	}
+	for _, n := range notes {
+		if strings.Contains(n.Body, marker) {
+			return "", c.do(ctx, "PUT", fmt.Sprintf("%s/%d", base, n.ID), map[string]string{"body": body}, nil)
+		}
+	}
+	return "", c.do(ctx, "POST", base, map[string]string{"body": body}, nil)
+}
+
+func (c *Client) gitlabProject() string {
+	return "/projects/" + url.PathEscape(c.remote.Path)
+}
+
+// do performs an authenticated JSON request against the API.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL+path, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.remote.Kind == GitLab {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: HTTP %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("decode %s %s: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseRemote(t *testing.T) {
+	for raw, want := range map[string]Remote{
+		"https://github.com/mistakeknot/intermap.git":     {Kind: GitHub, Host: "github.com", Path: "mistakeknot/intermap"},
+		"git@github.com:mistakeknot/intermap.git":         {Kind: GitHub, Host: "github.com", Path: "mistakeknot/intermap"},
+		"ssh://git@gitlab.example.com/group/sub/repo.git": {Kind: GitLab, Host: "gitlab.example.com", Path: "group/sub/repo"},
+	} {
+		got, err := ParseRemote(raw)
+		if err != nil {
+			t.Errorf("ParseRemote(%q): %v", raw, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseRemote(%q) = %+v, want %+v", raw, got, want)
+		}
+	}
+	if _, err := ParseRemote("/local/path"); err == nil {
+		t.Error("expected error for local path remote")
+	}
+}
+
+func TestNewClient_NoToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	if _, err := NewClient(Remote{Kind: GitHub, Host: "github.com", Path: "a/b"}); err == nil {
+		t.Error("expected error without token")
+	}
+}
+
+func TestNewClient_UntrustedHost(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "tok")
+	t.Setenv("GITHUB_API_URL", "")
+	t.Setenv("GITLAB_TOKEN", "tok")
+	t.Setenv("GITLAB_API_URL", "")
+	t.Setenv("INTERMAP_FORGE_HOSTS", "ghe.example.com, gitlab.example.com")
+	for _, tt := range []struct {
+		remote Remote
+		ok     bool
+	}{
+		{Remote{Kind: GitHub, Host: "github.com", Path: "a/b"}, true},
+		{Remote{Kind: GitHub, Host: "ghe.example.com", Path: "a/b"}, true},
+		{Remote{Kind: GitHub, Host: "git.evil.example", Path: "a/b"}, false},
+		{Remote{Kind: GitLab, Host: "gitlab.example.com", Path: "a/b"}, true},
+		{Remote{Kind: GitLab, Host: "gitlab.evil.example", Path: "a/b"}, false},
+	} {
+		if _, err := NewClient(tt.remote); (err == nil) != tt.ok {
+			t.Errorf("NewClient(%s) error = %v, want ok %v", tt.remote.Host, err, tt.ok)
+		}
+	}
+
+	t.Setenv("GITHUB_API_URL", "https://ghe.internal/api/v3")
+	if _, err := NewClient(Remote{Kind: GitHub, Host: "git.evil.example", Path: "a/b"}); err != nil {
+		t.Errorf("configured API URL: %v", err)
+	}
+}
+
+func TestGitHub_PullRequestAndUpsert(t *testing.T) {
+	var patched, posted bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/o/r/pulls/7":
+			w.Write([]byte(`{"number":7,"title":"Fix","html_url":"u","base":{"ref":"main"},"head":{"ref":"fix","sha":"abc"}}`))
+		case r.Method == "GET" && r.URL.Path == "/repos/o/r/issues/7/comments":
+			w.Write([]byte(`[{"id":1,"body":"lgtm"},{"id":2,"body":"<!-- m -->\nold"}]`))
+		case r.Method == "PATCH" && r.URL.Path == "/repos/o/r/issues/comments/2":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			patched = strings.Contains(body["body"], "new")
+			w.Write([]byte(`{"html_url":"c2"}`))
+		case r.Method == "POST":
+			posted = true
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(Remote{Kind: GitHub, Host: "github.com", Path: "o/r"}, WithAPIURL(ts.URL), WithToken("tok"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := c.PullRequest(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("PullRequest: %v", err)
+	}
+	if pr.BaseRef != "main" || pr.HeadSHA != "abc" {
+		t.Errorf("unexpected PR: %+v", pr)
+	}
+
+	u, err := c.UpsertComment(context.Background(), 7, "<!-- m -->", "new")
+	if err != nil {
+		t.Fatalf("UpsertComment: %v", err)
+	}
+	if !patched || posted || u != "c2" {
+		t.Errorf("expected existing comment to be edited (patched=%v posted=%v url=%q)", patched, posted, u)
+	}
+}
+
+func TestGitLab_PullRequest(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "tok" || r.URL.EscapedPath() != "/projects/g%2Fr/merge_requests/3" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"iid":3,"target_branch":"main","source_branch":"feat","sha":"def"}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(Remote{Kind: GitLab, Host: "gitlab.com", Path: "g/r"}, WithAPIURL(ts.URL), WithToken("tok"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := c.PullRequest(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("PullRequest: %v", err)
+	}
+	if pr.Number != 3 || pr.BaseRef != "main" {
+		t.Errorf("unexpected MR: %+v", pr)
+	}
+}
@@ -0,0 +1,388 @@
+// Package gocalls builds sound Go call graphs with golang.org/x/tools (CHA
+// or RTA over SSA), for impact_analysis with precision "precise".
+//
+// Unlike the sidecar's text heuristics, these graphs see calls through
+// interfaces, method values, and function values.
+package gocalls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Call graph algorithms.
+const (
+	CHA = "cha" // class hierarchy analysis: every method matching an interface call
+	RTA = "rta" // rapid type analysis: only types instantiated from main or tests
+)
+
+// Func is a function or method of the analyzed project. Closures, bound
+// method values, and interface thunks are folded into the function that
+// declares them.
+type Func struct {
+	Name     string // bare name, as the sidecar's Go extractor reports it
+	Receiver string // receiver type name for methods, without * or type arguments
+	File     string // slash path relative to the project root
+}
+
+// Qualified returns Receiver.Name for methods and Name otherwise.
+func (f Func) Qualified() string {
+	if f.Receiver != "" {
+		return f.Receiver + "." + f.Name
+	}
+	return f.Name
+}
+
+// Edge is a call from Caller to Callee. Dynamic calls go through an
+// interface or a function value.
+type Edge struct {
+	Caller  Func
+	Callee  Func
+	Line    int // line of the first call site in Caller.File
+	Dynamic bool
+}
+
+// BuildContext selects the files the packages are loaded from. Empty GOOS
+// or GOARCH leaves the go command's default.
+type BuildContext struct {
+	GOOS   string
+	GOARCH string
+	Tags   []string
+}
+
+// Build loads every package of the Go module in dir, including tests,
+// under bc, and returns the edges of its algo call graph between the
+// project's own functions. Calls into or out of other modules and the
+// standard library are dropped.
+func Build(ctx context.Context, dir, algo string, bc BuildContext) ([]Edge, error) {
+	switch algo {
+	case "":
+		algo = CHA
+	case CHA, RTA:
+	default:
+		return nil, fmt.Errorf("unknown call graph algorithm %q (want %s or %s)", algo, CHA, RTA)
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &packages.Config{
+		Mode:    packages.LoadAllSyntax,
+		Context: ctx,
+		Dir:     root,
+		Tests:   true,
+		Env:     os.Environ(),
+	}
+	if bc.GOOS != "" {
+		cfg.Env = append(cfg.Env, "GOOS="+bc.GOOS)
+	}
+	if bc.GOARCH != "" {
+		cfg.Env = append(cfg.Env, "GOARCH="+bc.GOARCH)
+	}
+	if len(bc.Tags) > 0 {
+		cfg.BuildFlags = []string{"-tags=" + strings.Join(bc.Tags, ",")}
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("load packages: %w", err)
+	}
+	if err := firstError(pkgs); err != nil {
+		return nil, fmt.Errorf("load packages: %w", err)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	prog, ssaPkgs := ssautil.AllPackages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+
+	var cg *callgraph.Graph
+	if algo == CHA {
+		cg = cha.CallGraph(prog)
+	} else {
+		var roots []*ssa.Function
+		for _, main := range ssautil.MainPackages(ssaPkgs) {
+			roots = append(roots, main.Func("init"), main.Func("main"))
+		}
+		if len(roots) == 0 {
+			return nil, errors.New("rta needs a main package or tests")
+		}
+		cg = rta.Analyze(roots, true).CallGraph
+	}
+	cg.DeleteSyntheticNodes()
+
+	var set edgeSet
+	err = callgraph.GraphVisitEdges(cg, func(e *callgraph.Edge) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		site := prog.Fset.Position(e.Pos())
+		set.add(root, rawEdge{
+			caller:     e.Caller.Func.String(),
+			callee:     e.Callee.Func.String(),
+			calleeFile: prog.Fset.Position(e.Callee.Func.Pos()).Filename,
+			siteFile:   site.Filename,
+			line:       site.Line,
+			dynamic:    e.Site != nil && e.Site.Common().StaticCallee() == nil,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return set.edges, nil
+}
+
+// firstError returns the first error loading pkgs or their dependencies.
+func firstError(pkgs []*packages.Package) error {
+	var first error
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		if first == nil && len(p.Errors) > 0 {
+			first = p.Errors[0]
+		}
+	})
+	return first
+}
+
+// rawEdge is one call graph edge as SSA names it: caller and callee
+// function names, the callee's definition file, and the call site.
+type rawEdge struct {
+	caller, callee string
+	calleeFile     string
+	siteFile       string
+	line           int
+	dynamic        bool
+}
+
+// edgeSet keeps the edges whose caller and callee are both defined under
+// a root, merged per caller-callee pair.
+type edgeSet struct {
+	index map[[2]Func]int
+	edges []Edge
+}
+
+func (s *edgeSet) add(root string, r rawEdge) {
+	callerFile, ok := relFile(root, r.siteFile)
+	if !ok {
+		return
+	}
+	calleeFile, ok := relFile(root, r.calleeFile)
+	if !ok {
+		return
+	}
+	caller, ok := parseFunc(r.caller, callerFile)
+	if !ok {
+		return
+	}
+	callee, ok := parseFunc(r.callee, calleeFile)
+	if !ok || caller == callee {
+		return
+	}
+
+	key := [2]Func{caller, callee}
+	if i, seen := s.index[key]; seen {
+		e := &s.edges[i]
+		e.Dynamic = e.Dynamic || r.dynamic
+		if r.line > 0 && (e.Line == 0 || r.line < e.Line) {
+			e.Line = r.line
+		}
+		return
+	}
+	if s.index == nil {
+		s.index = map[[2]Func]int{}
+	}
+	s.index[key] = len(s.edges)
+	s.edges = append(s.edges, Edge{Caller: caller, Callee: callee, Line: r.line, Dynamic: r.dynamic})
+}
+
+// relFile returns path relative to root in slash form, if it is inside root.
+func relFile(root, path string) (string, bool) {
+	if path == "" || !filepath.IsAbs(path) {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// parseFunc parses an SSA function name such as pkg/path.F,
+// (*pkg/path.T).M, (pkg/path.T[K]).M$bound, or pkg/path.F$1. Synthetic
+// functions without a Go name (<root>, package initializers) are rejected.
+func parseFunc(name, file string) (Func, bool) {
+	f := Func{File: file}
+	if strings.HasPrefix(name, "(") {
+		end := strings.Index(name, ").")
+		if end < 0 {
+			return Func{}, false
+		}
+		recv := strings.TrimPrefix(name[1:end], "*")
+		f.Receiver = shortName(recv)
+		name = name[end+2:]
+	} else {
+		name = shortName(name)
+	}
+	if i := strings.IndexByte(name, '$'); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		name = name[:i]
+	}
+	if name == "" || name == "init" || strings.HasPrefix(name, "<") || strings.HasPrefix(name, "init#") {
+		return Func{}, false
+	}
+	f.Name = name
+	return f, true
+}
+
+// shortName strips the package path and type arguments from a qualified
+// name: gopkg.in/yaml.v3.Node[T] becomes Node.
+func shortName(s string) string {
+	if i := strings.IndexByte(s, '['); i >= 0 {
+		s = s[:i]
+	}
+	// Package path elements may contain dots, but the last element cannot
+	// contain a slash, so the name follows the last dot.
+	if i := strings.LastIndexByte(s, '.'); i >= 0 {
+		s = s[i+1:]
+	}
+	return s
+}
+
+// Node is one function of a caller tree, in the shape the sidecar's
+// impact_analysis returns.
+type Node struct {
+	Function    string  `json:"function"`
+	Qualified   string  `json:"qualified"`
+	File        string  `json:"file"`
+	Line        int     `json:"line,omitempty"`    // call site line in File, for callers
+	Dynamic     bool    `json:"dynamic,omitempty"` // called through an interface or function value
+	CallerCount int     `json:"caller_count"`
+	Callers     []*Node `json:"callers"`
+	Truncated   bool    `json:"truncated"`
+}
+
+// Target selects the functions to build caller trees for. Name is a bare
+// or receiver-qualified name (M or T.M); File, when set, must equal or end
+// the function's file; Package, when set, is the function's directory
+// relative to the project root ("" is not a filter; use "." for the root).
+type Target struct {
+	Name    string
+	File    string
+	Package string
+}
+
+// ParseTarget accepts the forms impact_analysis takes: name, T.name,
+// file:name, and a symbol ID package#T.name@sighash.
+func ParseTarget(s string) Target {
+	var t Target
+	if pkg, rest, ok := strings.Cut(s, "#"); ok {
+		t.Package = pkg
+		if t.Package == "" {
+			t.Package = "."
+		}
+		s, _, _ = strings.Cut(rest, "@")
+	} else if file, name, ok := strings.Cut(s, ":"); ok {
+		t.File = filepath.ToSlash(file)
+		s = name
+	}
+	t.Name = s
+	return t
+}
+
+func (t Target) matches(f Func) bool {
+	if t.Name != f.Name && t.Name != f.Qualified() {
+		return false
+	}
+	if t.File != "" && f.File != t.File && !strings.HasSuffix(f.File, "/"+t.File) {
+		return false
+	}
+	if t.Package != "" {
+		dir := filepath.ToSlash(filepath.Dir(f.File))
+		if dir != t.Package {
+			return false
+		}
+	}
+	return true
+}
+
+// Impact builds the caller tree of every function matching target, up to
+// maxDepth levels, keyed by "file:name" as in the sidecar's results. As
+// there, each function is expanded once per tree; repeats are truncated.
+func Impact(edges []Edge, target Target, maxDepth int) map[string]*Node {
+	callers := map[Func][]Edge{}
+	funcs := map[Func]bool{}
+	for _, e := range edges {
+		callers[e.Callee] = append(callers[e.Callee], e)
+		funcs[e.Callee] = true
+	}
+	for f, es := range callers {
+		sort.Slice(es, func(i, j int) bool {
+			a, b := es[i].Caller, es[j].Caller
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			if es[i].Line != es[j].Line {
+				return es[i].Line < es[j].Line
+			}
+			return a.Qualified() < b.Qualified()
+		})
+		callers[f] = es
+	}
+
+	var matches []Func
+	for f := range funcs {
+		if target.matches(f) {
+			matches = append(matches, f)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Qualified() < b.Qualified()
+	})
+	result := map[string]*Node{}
+	for _, f := range matches {
+		result[f.File+":"+f.Qualified()] = callerTree(f, callers, maxDepth, map[Func]bool{})
+	}
+	return result
+}
+
+func callerTree(f Func, callers map[Func][]Edge, depth int, visited map[Func]bool) *Node {
+	in := callers[f]
+	n := &Node{
+		Function:    f.Name,
+		Qualified:   f.Qualified(),
+		File:        f.File,
+		CallerCount: len(in),
+		Callers:     []*Node{},
+	}
+	if depth <= 0 || visited[f] {
+		n.Truncated = true
+		return n
+	}
+	visited[f] = true
+	for _, e := range in {
+		child := callerTree(e.Caller, callers, depth-1, visited)
+		child.Line = e.Line
+		child.Dynamic = e.Dynamic
+		n.Callers = append(n.Callers, child)
+	}
+	return n
+}
@@ -0,0 +1,238 @@
+package gocalls
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseFunc(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		name     string
+		receiver string
+		ok       bool
+	}{
+		{"example.com/m/pkg.Run", "Run", "", true},
+		{"(*example.com/m/pkg.Server).Serve", "Serve", "Server", true},
+		{"(example.com/m/pkg.Set[K]).Add$bound", "Add", "Set", true},
+		{"example.com/m/pkg.Run$1$2", "Run", "", true},
+		{"gopkg.in/yaml.v3.Marshal", "Marshal", "", true},
+		{"example.com/m/pkg.Map[int]", "Map", "", true},
+		{"example.com/m/pkg.init", "", "", false},
+		{"example.com/m/pkg.init#1", "", "", false},
+		{"<root>", "", "", false},
+	} {
+		f, ok := parseFunc(tc.in, "pkg/a.go")
+		if ok != tc.ok || f.Name != tc.name || f.Receiver != tc.receiver {
+			t.Errorf("parseFunc(%q) = %+v, %v; want %s/%s, %v", tc.in, f, ok, tc.receiver, tc.name, tc.ok)
+		}
+	}
+}
+
+// graph is a call graph of a project at /p: main calls Store.Save through
+// an interface and helper directly, a closure in helper calls Save too, and
+// everything reaches into the standard library.
+var graph = []rawEdge{
+	{"example.com/m.main", "(*example.com/m/store.Disk).Save", "/p/store/disk.go", "/p/main.go", 12, true},
+	{"example.com/m.main", "example.com/m.helper", "/p/main.go", "/p/main.go", 13, false},
+	{"example.com/m.helper$1", "(*example.com/m/store.Disk).Save", "/p/store/disk.go", "/p/main.go", 25, false},
+	{"example.com/m.helper", "example.com/m.helper$1", "/p/main.go", "/p/main.go", 24, false},
+	{"example.com/m.main", "fmt.Println", "/usr/lib/go/src/fmt/print.go", "/p/main.go", 14, false},
+	{"os.Exit", "example.com/m.main", "/p/main.go", "/usr/lib/go/src/os/proc.go", 60, false},
+	{"example.com/m.main", "(*example.com/m/store.Disk).Save", "/p/store/disk.go", "/p/main.go", 9, true},
+}
+
+func graphEdges() []Edge {
+	var set edgeSet
+	for _, r := range graph {
+		set.add("/p", r)
+	}
+	return set.edges
+}
+
+func TestEdgeSet(t *testing.T) {
+	edges := graphEdges()
+	if len(edges) != 3 {
+		t.Fatalf("edges = %+v, want 3 (stdlib dropped, closure folded, duplicates merged)", edges)
+	}
+	first := edges[0]
+	if first.Caller.Name != "main" || first.Callee.Qualified() != "Disk.Save" || first.Callee.File != "store/disk.go" {
+		t.Errorf("first edge = %+v", first)
+	}
+	if !first.Dynamic || first.Line != 9 {
+		t.Errorf("merged edge Dynamic = %v, Line = %d; want true, 9", first.Dynamic, first.Line)
+	}
+}
+
+func TestImpact(t *testing.T) {
+	edges := graphEdges()
+
+	for _, target := range []string{"Save", "Disk.Save", "store/disk.go:Save", "disk.go:Save", "store#Disk.Save@abcd1234"} {
+		trees := Impact(edges, ParseTarget(target), 3)
+		tree, ok := trees["store/disk.go:Disk.Save"]
+		if len(trees) != 1 || !ok {
+			t.Errorf("Impact(%q) = %v", target, trees)
+			continue
+		}
+		if tree.CallerCount != 2 || len(tree.Callers) != 2 {
+			t.Fatalf("Impact(%q) callers = %+v", target, tree.Callers)
+		}
+		// Callers sort by file, then call site line.
+		if c := tree.Callers[0]; c.Function != "main" || !c.Dynamic || c.Line != 9 {
+			t.Errorf("first caller = %+v", c)
+		}
+		if c := tree.Callers[1]; c.Function != "helper" || c.Dynamic || len(c.Callers) != 1 {
+			t.Errorf("second caller = %+v", c)
+		}
+	}
+
+	for _, target := range []string{"Load", "Other.Save", "main.go:Save", "#Disk.Save"} {
+		if trees := Impact(edges, ParseTarget(target), 3); len(trees) != 0 {
+			t.Errorf("Impact(%q) = %v, want none", target, trees)
+		}
+	}
+
+	tree := Impact(edges, ParseTarget("Save"), 1)["store/disk.go:Disk.Save"]
+	if c := tree.Callers[1]; !c.Truncated || len(c.Callers) != 0 || c.CallerCount != 1 {
+		t.Errorf("depth-limited caller = %+v", c)
+	}
+}
+
+func TestImpactTargetsAreIndependent(t *testing.T) {
+	// Both Save methods share the caller run, which must be expanded in
+	// each tree, not only in whichever target is built first.
+	var set edgeSet
+	for _, r := range []rawEdge{
+		{"m.run", "(*m.A).Save", "/p/a.go", "/p/main.go", 3, true},
+		{"m.run", "(*m.B).Save", "/p/b.go", "/p/main.go", 4, true},
+		{"m.main", "m.run", "/p/main.go", "/p/main.go", 8, false},
+	} {
+		set.add("/p", r)
+	}
+	for range 20 {
+		trees := Impact(set.edges, ParseTarget("Save"), 3)
+		for _, key := range []string{"a.go:A.Save", "b.go:B.Save"} {
+			tree := trees[key]
+			if tree == nil || len(tree.Callers) != 1 || tree.Callers[0].Truncated || len(tree.Callers[0].Callers) != 1 {
+				t.Fatalf("%s = %+v", key, tree)
+			}
+		}
+	}
+}
+
+// writeModule writes a module at dir from path → content.
+func writeModule(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, text := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+	project := t.TempDir()
+	writeModule(t, project, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.21\n",
+		"main.go": `package main
+
+import "example.com/m/store"
+
+func main() {
+	var s store.Saver = &store.Disk{}
+	save(s)
+}
+
+func save(s store.Saver) { s.Save() }
+`,
+		"store/store.go": `package store
+
+type Saver interface{ Save() }
+
+type Disk struct{}
+
+func (d *Disk) Save() { flush() }
+
+type Mem struct{}
+
+func (m *Mem) Save() {}
+
+func flush() {}
+`,
+		"store/e2e.go":         "//go:build e2e\n\npackage store\n\nfunc init() { flush() }\n\nfunc Drain() { flush() }\n",
+		"store/win_windows.go": "package store\n\nfunc Sync() { flush() }\n",
+	})
+
+	callersOf := func(edges []Edge, callee string) []string {
+		var out []string
+		for _, e := range edges {
+			if e.Callee.Qualified() == callee {
+				out = append(out, e.Caller.Qualified())
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	cha, err := Build(context.Background(), project, CHA, BuildContext{GOOS: "linux"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// CHA reaches every Save through the interface call.
+	for _, callee := range []string{"Disk.Save", "Mem.Save"} {
+		if got := callersOf(cha, callee); !reflect.DeepEqual(got, []string{"save"}) {
+			t.Errorf("CHA callers of %s = %v", callee, got)
+		}
+	}
+	for _, e := range cha {
+		if e.Caller.Name == "save" && (!e.Dynamic || e.Line != 10 || e.Caller.File != "main.go") {
+			t.Errorf("interface call edge = %+v", e)
+		}
+	}
+
+	// RTA only reaches types created from main.
+	rta, err := Build(context.Background(), project, RTA, BuildContext{GOOS: "linux"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := callersOf(rta, "Mem.Save"); len(got) != 0 {
+		t.Errorf("RTA callers of Mem.Save = %v", got)
+	}
+	if got := callersOf(rta, "Disk.Save"); !reflect.DeepEqual(got, []string{"save"}) {
+		t.Errorf("RTA callers of Disk.Save = %v", got)
+	}
+
+	// Tags and GOOS select files.
+	if got := callersOf(cha, "flush"); !reflect.DeepEqual(got, []string{"Disk.Save"}) {
+		t.Errorf("default build callers of flush = %v", got)
+	}
+	bc := BuildContext{GOOS: "windows", GOARCH: "arm64", Tags: []string{"e2e"}}
+	tagged, err := Build(context.Background(), project, CHA, bc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := callersOf(tagged, "flush"); !reflect.DeepEqual(got, []string{"Disk.Save", "Drain", "Sync"}) {
+		t.Errorf("tagged build callers of flush = %v", got)
+	}
+
+	if _, err := Build(context.Background(), project, "vta", BuildContext{}); err == nil {
+		t.Error("Build accepted an unknown algorithm")
+	}
+	writeModule(t, project, map[string]string{"broken.go": "package main\n\nfunc broken() { undefined() }\n"})
+	if _, err := Build(context.Background(), project, CHA, BuildContext{}); err == nil || !strings.Contains(err.Error(), "undefined") {
+		t.Errorf("Build of a broken package = %v", err)
+	}
+}
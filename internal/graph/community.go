@@ -0,0 +1,136 @@
+package graph
+
+import "sort"
+
+// Communities partitions the graph into clusters by greedy modularity
+// maximisation (Clauset–Newman–Moore) over the undirected view of the graph:
+// starting from singletons, the pair of connected clusters whose merge most
+// increases modularity is merged until no merge helps. Ties are broken by
+// node order, so results are deterministic. Each community is sorted;
+// communities are ordered largest first.
+func (g *Graph) Communities() [][]string {
+	n := len(g.ids)
+	if n == 0 {
+		return nil
+	}
+
+	adj := make([]map[int]float64, n)
+	deg := make([]float64, n)
+	total := 0.0
+	for i := 0; i < n; i++ {
+		adj[i] = make(map[int]float64)
+	}
+	for i := 0; i < n; i++ {
+		for j := range g.out[i] {
+			adj[i][j]++
+			adj[j][i]++
+			deg[i]++
+			deg[j]++
+			total += 2
+		}
+	}
+
+	members := make([][]int, n)
+	alive := make([]bool, n)
+	for i := range members {
+		members[i] = []int{i}
+		alive[i] = true
+	}
+
+	if total > 0 {
+		for {
+			bestI, bestJ, bestDQ := -1, -1, 0.0
+			for i := 0; i < n; i++ {
+				if !alive[i] {
+					continue
+				}
+				for j, w := range adj[i] {
+					if j <= i {
+						continue
+					}
+					dq := 2 * (w/total - (deg[i]/total)*(deg[j]/total))
+					if dq > bestDQ+1e-12 || (dq > bestDQ-1e-12 && bestI >= 0 && (i < bestI || (i == bestI && j < bestJ))) {
+						bestI, bestJ, bestDQ = i, j, dq
+					}
+				}
+			}
+			if bestI < 0 {
+				break
+			}
+			for k, w := range adj[bestJ] {
+				delete(adj[k], bestJ)
+				if k == bestI {
+					continue
+				}
+				adj[bestI][k] += w
+				adj[k][bestI] += w
+			}
+			adj[bestJ] = nil
+			deg[bestI] += deg[bestJ]
+			members[bestI] = append(members[bestI], members[bestJ]...)
+			alive[bestJ] = false
+		}
+	}
+
+	var out [][]string
+	for i := 0; i < n; i++ {
+		if !alive[i] {
+			continue
+		}
+		ids := make([]string, 0, len(members[i]))
+		for _, m := range members[i] {
+			ids = append(ids, g.ids[m])
+		}
+		sort.Strings(ids)
+		out = append(out, ids)
+	}
+	sort.Slice(out, func(a, b int) bool {
+		if len(out[a]) != len(out[b]) {
+			return len(out[a]) > len(out[b])
+		}
+		return out[a][0] < out[b][0]
+	})
+	return out
+}
+
+// ClusterMetrics describes how self-contained a set of nodes is.
+type ClusterMetrics struct {
+	InternalEdges int `json:"internal_edges"`
+	IncomingEdges int `json:"incoming_edges"`
+	OutgoingEdges int `json:"outgoing_edges"`
+	// Cohesion is internal edges over all edges touching the cluster (0–1).
+	Cohesion float64 `json:"cohesion"`
+	// Coupling is external edges over all edges touching the cluster (0–1).
+	Coupling float64 `json:"coupling"`
+}
+
+// Metrics computes cohesion and coupling for the given member set.
+func (g *Graph) Metrics(members []string) ClusterMetrics {
+	in := make(map[int]bool, len(members))
+	for _, m := range members {
+		if i, ok := g.index[m]; ok {
+			in[i] = true
+		}
+	}
+	var m ClusterMetrics
+	for i := range in {
+		for j := range g.out[i] {
+			if in[j] {
+				m.InternalEdges++
+			} else {
+				m.OutgoingEdges++
+			}
+		}
+		for j := range g.in[i] {
+			if !in[j] {
+				m.IncomingEdges++
+			}
+		}
+	}
+	total := m.InternalEdges + m.IncomingEdges + m.OutgoingEdges
+	if total > 0 {
+		m.Cohesion = float64(m.InternalEdges) / float64(total)
+		m.Coupling = float64(m.IncomingEdges+m.OutgoingEdges) / float64(total)
+	}
+	return m
+}
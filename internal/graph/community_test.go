@@ -0,0 +1,47 @@
+package graph
+
+import "testing"
+
+func TestCommunities_TwoClusters(t *testing.T) {
+	g := New()
+	// Dense triangle a-b-c, dense triangle x-y-z, one bridge c → x.
+	for _, e := range [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}, {"x", "y"}, {"y", "z"}, {"z", "x"}, {"c", "x"}} {
+		g.AddEdge(e[0], e[1])
+	}
+
+	comms := g.Communities()
+	if len(comms) != 2 {
+		t.Fatalf("expected 2 communities, got %d: %v", len(comms), comms)
+	}
+	for _, c := range comms {
+		if len(c) != 3 {
+			t.Errorf("expected community of 3, got %v", c)
+		}
+	}
+}
+
+func TestCommunities_Isolated(t *testing.T) {
+	g := New()
+	g.AddNode("solo")
+	g.AddEdge("a", "b")
+	comms := g.Communities()
+	if len(comms) != 2 {
+		t.Errorf("expected 2 communities, got %v", comms)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	g := New()
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "a")
+	g.AddEdge("a", "x")
+	g.AddEdge("y", "b")
+
+	m := g.Metrics([]string{"a", "b"})
+	if m.InternalEdges != 2 || m.OutgoingEdges != 1 || m.IncomingEdges != 1 {
+		t.Errorf("unexpected metrics: %+v", m)
+	}
+	if m.Cohesion != 0.5 || m.Coupling != 0.5 {
+		t.Errorf("expected cohesion/coupling 0.5/0.5, got %f/%f", m.Cohesion, m.Coupling)
+	}
+}
@@ -0,0 +1,64 @@
+package graph
+
+import "sort"
+
+// Cycles returns the strongly connected components with more than one node
+// (Tarjan's algorithm). Each component is sorted; components are ordered by
+// their first node ID.
+func (g *Graph) Cycles() [][]string {
+	n := len(g.ids)
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+	var stack []int
+	var out [][]string
+	counter := 0
+
+	var strongConnect func(v int)
+	strongConnect = func(v int) {
+		index[v] = counter
+		low[v] = counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for w := range g.out[v] {
+			if index[w] < 0 {
+				strongConnect(w)
+				if low[w] < low[v] {
+					low[v] = low[w]
+				}
+			} else if onStack[w] && index[w] < low[v] {
+				low[v] = index[w]
+			}
+		}
+
+		if low[v] == index[v] {
+			var comp []string
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				comp = append(comp, g.ids[w])
+				if w == v {
+					break
+				}
+			}
+			if len(comp) > 1 {
+				sort.Strings(comp)
+				out = append(out, comp)
+			}
+		}
+	}
+
+	for v := 0; v < n; v++ {
+		if index[v] < 0 {
+			strongConnect(v)
+		}
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a][0] < out[b][0] })
+	return out
+}
@@ -0,0 +1,25 @@
+package graph
+
+import "testing"
+
+func TestCycles(t *testing.T) {
+	g := New()
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "c")
+	g.AddEdge("c", "a")
+	g.AddEdge("c", "d")
+	g.AddEdge("d", "e")
+
+	cycles := g.Cycles()
+	if len(cycles) != 1 {
+		t.Fatalf("expected 1 cycle, got %v", cycles)
+	}
+	if len(cycles[0]) != 3 || cycles[0][0] != "a" {
+		t.Errorf("expected [a b c], got %v", cycles[0])
+	}
+
+	g.AddEdge("e", "d")
+	if got := g.Cycles(); len(got) != 2 {
+		t.Errorf("expected 2 cycles after adding e → d, got %v", got)
+	}
+}
@@ -0,0 +1,189 @@
+// Package graph provides small directed-graph algorithms used by the
+// analysis tools: centrality ranking over call and import graphs.
+//
+// Nodes are identified by strings (e.g. "path/to/file.go:FuncName" or a
+// file path). Edges are deduplicated; self-loops are ignored.
+package graph
+
+import "sort"
+
+// Graph is a directed graph with string node IDs.
+type Graph struct {
+	ids   []string
+	index map[string]int
+	out   []map[int]bool
+	in    []map[int]bool
+	edges int
+}
+
+// New creates an empty graph.
+func New() *Graph {
+	return &Graph{index: make(map[string]int)}
+}
+
+// AddNode adds a node if it does not exist and returns its index.
+func (g *Graph) AddNode(id string) int {
+	if i, ok := g.index[id]; ok {
+		return i
+	}
+	i := len(g.ids)
+	g.ids = append(g.ids, id)
+	g.index[id] = i
+	g.out = append(g.out, make(map[int]bool))
+	g.in = append(g.in, make(map[int]bool))
+	return i
+}
+
+// AddEdge adds a directed edge src → dst, creating nodes as needed.
+func (g *Graph) AddEdge(src, dst string) {
+	s, d := g.AddNode(src), g.AddNode(dst)
+	if s == d || g.out[s][d] {
+		return
+	}
+	g.out[s][d] = true
+	g.in[d][s] = true
+	g.edges++
+}
+
+// Has reports whether id is a node in the graph.
+func (g *Graph) Has(id string) bool {
+	_, ok := g.index[id]
+	return ok
+}
+
+// Len returns the number of nodes.
+func (g *Graph) Len() int { return len(g.ids) }
+
+// EdgeCount returns the number of distinct edges.
+func (g *Graph) EdgeCount() int { return g.edges }
+
+// Nodes returns all node IDs in insertion order.
+func (g *Graph) Nodes() []string {
+	return append([]string(nil), g.ids...)
+}
+
+// InDegree returns the number of distinct predecessors of id.
+func (g *Graph) InDegree(id string) int {
+	if i, ok := g.index[id]; ok {
+		return len(g.in[i])
+	}
+	return 0
+}
+
+// OutDegree returns the number of distinct successors of id.
+func (g *Graph) OutDegree(id string) int {
+	if i, ok := g.index[id]; ok {
+		return len(g.out[i])
+	}
+	return 0
+}
+
+// Successors returns the sorted successors of id.
+func (g *Graph) Successors(id string) []string {
+	i, ok := g.index[id]
+	if !ok {
+		return nil
+	}
+	return g.names(g.out[i])
+}
+
+// Predecessors returns the sorted predecessors of id.
+func (g *Graph) Predecessors(id string) []string {
+	i, ok := g.index[id]
+	if !ok {
+		return nil
+	}
+	return g.names(g.in[i])
+}
+
+func (g *Graph) names(set map[int]bool) []string {
+	out := make([]string, 0, len(set))
+	for j := range set {
+		out = append(out, g.ids[j])
+	}
+	sort.Strings(out)
+	return out
+}
+
+// PageRank computes PageRank scores with the given damping factor (typically
+// 0.85), iterating until the L1 change drops below 1e-9 or maxIter is reached.
+// Rank mass flows along edges, so heavily depended-upon nodes score highest.
+// Dangling nodes redistribute their mass uniformly. Scores sum to 1.
+func (g *Graph) PageRank(damping float64, maxIter int) map[string]float64 {
+	n := len(g.ids)
+	scores := make(map[string]float64, n)
+	if n == 0 {
+		return scores
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+	next := make([]float64, n)
+
+	for iter := 0; iter < maxIter; iter++ {
+		dangling := 0.0
+		for i := 0; i < n; i++ {
+			if len(g.out[i]) == 0 {
+				dangling += rank[i]
+			}
+		}
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for i := 0; i < n; i++ {
+			if len(g.out[i]) == 0 {
+				continue
+			}
+			share := damping * rank[i] / float64(len(g.out[i]))
+			for j := range g.out[i] {
+				next[j] += share
+			}
+		}
+
+		delta := 0.0
+		for i := range rank {
+			d := next[i] - rank[i]
+			if d < 0 {
+				d = -d
+			}
+			delta += d
+		}
+		rank, next = next, rank
+		if delta < 1e-9 {
+			break
+		}
+	}
+
+	for i, id := range g.ids {
+		scores[id] = rank[i]
+	}
+	return scores
+}
+
+// Ranked is a node with its score.
+type Ranked struct {
+	ID    string
+	Score float64
+}
+
+// Top returns the n highest-scoring entries, ties broken by ID.
+// n <= 0 returns all entries.
+func Top(scores map[string]float64, n int) []Ranked {
+	out := make([]Ranked, 0, len(scores))
+	for id, s := range scores {
+		out = append(out, Ranked{ID: id, Score: s})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].ID < out[j].ID
+	})
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
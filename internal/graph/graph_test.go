@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGraph_AddEdgeDedup(t *testing.T) {
+	g := New()
+	g.AddEdge("a", "b")
+	g.AddEdge("a", "b")
+	g.AddEdge("a", "a")
+	if g.Len() != 2 {
+		t.Errorf("expected 2 nodes, got %d", g.Len())
+	}
+	if g.EdgeCount() != 1 {
+		t.Errorf("expected 1 edge, got %d", g.EdgeCount())
+	}
+	if g.InDegree("b") != 1 || g.OutDegree("a") != 1 {
+		t.Errorf("unexpected degrees: in(b)=%d out(a)=%d", g.InDegree("b"), g.OutDegree("a"))
+	}
+}
+
+func TestPageRank_HubScoresHighest(t *testing.T) {
+	g := New()
+	for _, caller := range []string{"a", "b", "c", "d"} {
+		g.AddEdge(caller, "hub")
+	}
+	g.AddEdge("hub", "leaf")
+
+	scores := g.PageRank(0.85, 100)
+	sum := 0.0
+	for _, s := range scores {
+		sum += s
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		t.Errorf("expected scores to sum to 1, got %f", sum)
+	}
+
+	top := Top(scores, 2)
+	if top[0].ID != "leaf" && top[0].ID != "hub" {
+		t.Errorf("expected hub or leaf on top, got %s", top[0].ID)
+	}
+	if scores["hub"] <= scores["a"] {
+		t.Errorf("expected hub (%f) > a (%f)", scores["hub"], scores["a"])
+	}
+}
+
+func TestPageRank_Empty(t *testing.T) {
+	if got := New().PageRank(0.85, 10); len(got) != 0 {
+		t.Errorf("expected empty scores, got %v", got)
+	}
+}
+
+func TestTop_Limit(t *testing.T) {
+	scores := map[string]float64{"a": 0.1, "b": 0.5, "c": 0.5}
+	top := Top(scores, 2)
+	if len(top) != 2 || top[0].ID != "b" || top[1].ID != "c" {
+		t.Errorf("unexpected top: %+v", top)
+	}
+}
@@ -0,0 +1,252 @@
+// Package graphsink mirrors analysis results into a Neo4j graph database so
+// large workspaces can be queried with Cypher.
+//
+// Writes go through Neo4j's HTTP transactional endpoint
+// (POST /db/<database>/tx/commit) and are applied asynchronously by a single
+// background worker, so tool calls never wait on the database. Every write is
+// an idempotent MERGE; pushing a project's symbols also removes symbols from
+// earlier pushes that no longer exist.
+//
+// A nil *Sink, or one without an endpoint, is a valid no-op sink.
+package graphsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Symbol is a definition node. ID is unique within its project.
+type Symbol struct {
+	ID   string `json:"id"`
+	File string `json:"file"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Line int    `json:"line"`
+}
+
+// Call is a caller → callee edge between symbol IDs of one project.
+type Call struct {
+	Src string `json:"src"`
+	Dst string `json:"dst"`
+}
+
+// Dependency is a cross-project dependency edge.
+type Dependency struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+	Via  string `json:"via"`
+}
+
+// statement is one Cypher statement in a transactional request.
+type statement struct {
+	Statement  string         `json:"statement"`
+	Parameters map[string]any `json:"parameters,omitempty"`
+}
+
+const (
+	cypherSymbols = `UNWIND $rows AS r
+MERGE (p:Project {name: $project})
+MERGE (s:Symbol {project: $project, id: r.id})
+SET s.name = r.name, s.file = r.file, s.kind = r.kind, s.line = r.line, s.run = $run
+MERGE (p)-[:DEFINES]->(s)`
+
+	cypherCalls = `UNWIND $rows AS r
+MATCH (a:Symbol {project: $project, id: r.src}), (b:Symbol {project: $project, id: r.dst})
+MERGE (a)-[:CALLS]->(b)`
+
+	cypherPrune = `MATCH (s:Symbol {project: $project}) WHERE s.run <> $run DETACH DELETE s`
+
+	cypherDeps = `UNWIND $rows AS r
+MERGE (a:Project {name: r.from})
+MERGE (b:Project {name: r.to})
+MERGE (a)-[d:DEPENDS_ON {type: r.type}]->(b)
+SET d.via = r.via`
+)
+
+// Sink pushes graph updates to Neo4j.
+type Sink struct {
+	endpoint string
+	database string
+	user     string
+	password string
+	http     *http.Client
+
+	queue chan []statement
+	wg    sync.WaitGroup
+	once  sync.Once
+
+	mu      sync.Mutex
+	lastErr error
+}
+
+// Option configures the sink.
+type Option func(*Sink)
+
+// New creates a sink and starts its worker. Without WithEndpoint the sink is
+// disabled and every push is dropped.
+func New(opts ...Option) *Sink {
+	s := &Sink{
+		database: "neo4j",
+		http:     &http.Client{Timeout: 30 * time.Second},
+		queue:    make(chan []statement, 64),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.Enabled() {
+		s.wg.Add(1)
+		go s.run()
+	}
+	return s
+}
+
+// WithEndpoint sets the Neo4j HTTP base URL (e.g. http://localhost:7474).
+func WithEndpoint(url string) Option {
+	return func(s *Sink) {
+		s.endpoint = strings.TrimRight(url, "/")
+	}
+}
+
+// WithDatabase sets the target database (default "neo4j").
+func WithDatabase(db string) Option {
+	return func(s *Sink) {
+		if db != "" {
+			s.database = db
+		}
+	}
+}
+
+// WithBasicAuth sets credentials for the HTTP endpoint.
+func WithBasicAuth(user, password string) Option {
+	return func(s *Sink) {
+		s.user = user
+		s.password = password
+	}
+}
+
+// Enabled reports whether the sink has an endpoint.
+func (s *Sink) Enabled() bool {
+	return s != nil && s.endpoint != ""
+}
+
+// PushSymbols queues a full snapshot of one project's symbols and call edges.
+// Symbols absent from the snapshot are removed.
+func (s *Sink) PushSymbols(project string, symbols []Symbol, calls []Call) {
+	if !s.Enabled() {
+		return
+	}
+	run := time.Now().UnixNano()
+	s.enqueue([]statement{
+		{Statement: cypherSymbols, Parameters: map[string]any{"project": project, "run": run, "rows": symbols}},
+		{Statement: cypherCalls, Parameters: map[string]any{"project": project, "rows": calls}},
+		{Statement: cypherPrune, Parameters: map[string]any{"project": project, "run": run}},
+	})
+}
+
+// PushDependencies queues cross-project dependency edges.
+func (s *Sink) PushDependencies(deps []Dependency) {
+	if !s.Enabled() || len(deps) == 0 {
+		return
+	}
+	s.enqueue([]statement{
+		{Statement: cypherDeps, Parameters: map[string]any{"rows": deps}},
+	})
+}
+
+// LastError returns the most recent write failure, if any.
+func (s *Sink) LastError() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastErr
+}
+
+// Close flushes queued writes and stops the worker.
+func (s *Sink) Close() {
+	if !s.Enabled() {
+		return
+	}
+	s.once.Do(func() {
+		close(s.queue)
+		s.wg.Wait()
+	})
+}
+
+// enqueue hands a batch to the worker, dropping it if the queue is full so a
+// slow database never blocks analysis.
+func (s *Sink) enqueue(batch []statement) {
+	select {
+	case s.queue <- batch:
+	default:
+		s.setErr(fmt.Errorf("graph sink queue full, dropped %d statements", len(batch)))
+	}
+}
+
+func (s *Sink) run() {
+	defer s.wg.Done()
+	for batch := range s.queue {
+		if err := s.commit(context.Background(), batch); err != nil {
+			s.setErr(err)
+		}
+	}
+}
+
+func (s *Sink) setErr(err error) {
+	s.mu.Lock()
+	s.lastErr = err
+	s.mu.Unlock()
+	fmt.Fprintf(os.Stderr, "intermap-mcp: graph sink: %v\n", err)
+}
+
+// commit runs statements in a single transaction.
+func (s *Sink) commit(ctx context.Context, statements []statement) error {
+	body, err := json.Marshal(map[string]any{"statements": statements})
+	if err != nil {
+		return fmt.Errorf("marshal statements: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", s.endpoint+"/db/"+s.database+"/tx/commit", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if s.user != "" {
+		req.SetBasicAuth(s.user, s.password)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("commit: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("commit: %s: %s", result.Errors[0].Code, result.Errors[0].Message)
+	}
+	return nil
+}
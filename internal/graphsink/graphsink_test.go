@@ -0,0 +1,96 @@
+package graphsink
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recorder struct {
+	mu       sync.Mutex
+	requests [][]statement
+	paths    []string
+	users    []string
+}
+
+func (rec *recorder) handler(reply string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Statements []statement `json:"statements"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		user, _, _ := r.BasicAuth()
+		rec.mu.Lock()
+		rec.requests = append(rec.requests, body.Statements)
+		rec.paths = append(rec.paths, r.URL.Path)
+		rec.users = append(rec.users, user)
+		rec.mu.Unlock()
+		w.Write([]byte(reply))
+	}
+}
+
+func TestPushSymbols(t *testing.T) {
+	rec := &recorder{}
+	ts := httptest.NewServer(rec.handler(`{"results":[],"errors":[]}`))
+	defer ts.Close()
+
+	s := New(WithEndpoint(ts.URL+"/"), WithDatabase("code"), WithBasicAuth("neo4j", "secret"))
+	s.PushSymbols("intermap",
+		[]Symbol{{ID: "a#f@1", File: "a.go", Name: "f"}, {ID: "a#g@2", File: "a.go", Name: "g"}},
+		[]Call{{Src: "a#f@1", Dst: "a#g@2"}},
+	)
+	s.PushDependencies([]Dependency{{From: "intermap", To: "interbase", Type: "go_module"}})
+	s.Close()
+
+	if len(rec.requests) != 2 {
+		t.Fatalf("expected 2 transactions, got %d", len(rec.requests))
+	}
+	if rec.paths[0] != "/db/code/tx/commit" {
+		t.Errorf("unexpected path %q", rec.paths[0])
+	}
+	if rec.users[0] != "neo4j" {
+		t.Errorf("expected basic auth user neo4j, got %q", rec.users[0])
+	}
+	if len(rec.requests[0]) != 3 {
+		t.Fatalf("expected symbols, calls, and prune statements, got %d", len(rec.requests[0]))
+	}
+	if !strings.Contains(rec.requests[0][2].Statement, "DETACH DELETE") {
+		t.Errorf("expected prune statement last, got %q", rec.requests[0][2].Statement)
+	}
+	if !strings.Contains(rec.requests[1][0].Statement, "DEPENDS_ON") {
+		t.Errorf("expected dependency statement, got %q", rec.requests[1][0].Statement)
+	}
+	if err := s.LastError(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCommit_CypherError(t *testing.T) {
+	rec := &recorder{}
+	ts := httptest.NewServer(rec.handler(`{"results":[],"errors":[{"code":"Neo.ClientError.Statement.SyntaxError","message":"bad"}]}`))
+	defer ts.Close()
+
+	s := New(WithEndpoint(ts.URL))
+	s.PushDependencies([]Dependency{{From: "a", To: "b"}})
+	s.Close()
+
+	if err := s.LastError(); err == nil || !strings.Contains(err.Error(), "SyntaxError") {
+		t.Errorf("expected Cypher error, got %v", err)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	var nilSink *Sink
+	nilSink.PushSymbols("p", nil, nil)
+	nilSink.Close()
+
+	s := New()
+	if s.Enabled() {
+		t.Error("sink without endpoint should be disabled")
+	}
+	s.PushDependencies([]Dependency{{From: "a", To: "b"}})
+	s.Close()
+}
@@ -0,0 +1,249 @@
+// Package hooks installs git hooks that run intermap's checks before a
+// commit or push, and gathers the changes those checks look at: the
+// staged changes for pre-commit, and the commits being pushed for
+// pre-push. The checks themselves live in `intermap-mcp hooks run`.
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Hook names.
+const (
+	PreCommit = "pre-commit"
+	PrePush   = "pre-push"
+)
+
+// Policy actions.
+const (
+	Block = "block" // fail the hook
+	Warn  = "warn"  // print and continue
+	Off   = "off"   // skip the check
+)
+
+// Policy sets the action for each check.
+type Policy struct {
+	// Impact flags impacted tests the change does not touch (default warn).
+	Impact string
+	// Reservations flags changed files other agents have reserved
+	// (default block).
+	Reservations string
+	// Secrets flags credentials in added lines (default block).
+	Secrets string
+}
+
+// WithDefaults fills unset actions and rejects unknown ones.
+func (p Policy) WithDefaults() (Policy, error) {
+	for _, f := range []struct {
+		name string
+		v    *string
+		def  string
+	}{
+		{"impact", &p.Impact, Warn},
+		{"reservations", &p.Reservations, Block},
+		{"secrets", &p.Secrets, Block},
+	} {
+		switch *f.v {
+		case "":
+			*f.v = f.def
+		case Block, Warn, Off:
+		default:
+			return p, fmt.Errorf("hooks.%s: unknown action %q (want block, warn, or off)", f.name, *f.v)
+		}
+	}
+	return p, nil
+}
+
+// Finding is one check's complaint.
+type Finding struct {
+	Check   string `json:"check"`
+	Action  string `json:"action"`
+	Message string `json:"message"`
+}
+
+// Blocked reports whether any finding blocks the hook.
+func Blocked(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Action == Block {
+			return true
+		}
+	}
+	return false
+}
+
+// marker identifies hooks written by Install, which may be overwritten.
+const marker = "# Installed by intermap-mcp hooks install."
+
+// Script returns the hook script running `binary hooks run hook`. A
+// non-empty pluginRoot is exported as CLAUDE_PLUGIN_ROOT so the hook
+// finds the Python sidecar outside the plugin environment.
+func Script(hook, binary, pluginRoot string) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString(marker + "\n")
+	b.WriteString("# Bypass once with --no-verify.\n")
+	if pluginRoot != "" {
+		fmt.Fprintf(&b, "CLAUDE_PLUGIN_ROOT=%s\nexport CLAUDE_PLUGIN_ROOT\n", shellQuote(pluginRoot))
+	}
+	fmt.Fprintf(&b, "exec %s hooks run %s \"$@\"\n", shellQuote(binary), hook)
+	return b.String()
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Install writes the hook script into repo's hooks directory (honouring
+// core.hooksPath) and returns its path. An existing hook that Install did
+// not write is kept unless force is set.
+func Install(ctx context.Context, repo, hook, binary, pluginRoot string, force bool) (string, error) {
+	if hook != PreCommit && hook != PrePush {
+		return "", fmt.Errorf("unknown hook %q (want %s or %s)", hook, PreCommit, PrePush)
+	}
+	dir, err := git(ctx, repo, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("%s is not a git working copy: %w", repo, err)
+	}
+	hooksDir := strings.TrimSpace(string(dir))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(repo, hooksDir)
+	}
+	path := filepath.Join(hooksDir, hook)
+	if old, err := os.ReadFile(path); err == nil && !force && !bytes.Contains(old, []byte(marker)) {
+		return "", fmt.Errorf("%s already exists and was not installed by intermap; use -force to replace it", path)
+	}
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(Script(hook, binary, pluginRoot)), 0o755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Changes are what a hook checks.
+type Changes struct {
+	// Base is the ref the changes are against, for change_impact.
+	Base string
+	// Files maps changed paths, relative to the repository root, to their
+	// git status letter.
+	Files map[string]string
+	// Diff is the zero-context unified diff of the changes, without
+	// a/ and b/ prefixes.
+	Diff []byte
+}
+
+// emptyTree is git's well-known empty tree object.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// Staged returns the changes staged for commit in repo.
+func Staged(ctx context.Context, repo string) (*Changes, error) {
+	base := "HEAD"
+	if _, err := git(ctx, repo, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		base = emptyTree // first commit
+	}
+	return diff(ctx, repo, base, "--cached", base)
+}
+
+// Pushed returns the changes in the commits being pushed, given the
+// pre-push hook's standard input: one "<local ref> <local sha> <remote
+// ref> <remote sha>" line per ref. Deleted refs are skipped. For a new
+// remote ref, the commits not on any remote are checked. Base is the
+// first updated ref's.
+func Pushed(ctx context.Context, repo string, stdin io.Reader) (*Changes, error) {
+	all := &Changes{Files: map[string]string{}}
+	sc := bufio.NewScanner(stdin)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 4 || isZero(fields[1]) {
+			continue
+		}
+		local, remote := fields[1], fields[3]
+		base := remote
+		if isZero(remote) {
+			var err error
+			if base, err = newBase(ctx, repo, local); err != nil {
+				return nil, err
+			}
+			if base == "" {
+				continue // everything is already on a remote
+			}
+		}
+		c, err := diff(ctx, repo, base, base, local)
+		if err != nil {
+			return nil, err
+		}
+		if all.Base == "" {
+			all.Base = base
+		}
+		for f, s := range c.Files {
+			all.Files[f] = s
+		}
+		all.Diff = append(all.Diff, c.Diff...)
+	}
+	return all, sc.Err()
+}
+
+// newBase returns the parent of the oldest commit reachable from local
+// but from no remote, the empty tree when that commit is a root, or ""
+// when there is no such commit.
+func newBase(ctx context.Context, repo, local string) (string, error) {
+	out, err := git(ctx, repo, "rev-list", "--reverse", local, "--not", "--remotes")
+	if err != nil {
+		return "", err
+	}
+	oldest, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if oldest == "" {
+		return "", nil
+	}
+	parent, err := git(ctx, repo, "rev-parse", "--verify", "--quiet", oldest+"^")
+	if err != nil {
+		return emptyTree, nil
+	}
+	return strings.TrimSpace(string(parent)), nil
+}
+
+func isZero(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
+
+// diff runs git diff with args for both the changed files and the patch.
+func diff(ctx context.Context, repo, base string, args ...string) (*Changes, error) {
+	names, err := git(ctx, repo, append([]string{"diff", "--name-status", "-z", "--no-renames"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	c := &Changes{Base: base, Files: map[string]string{}}
+	fields := strings.Split(string(names), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		c.Files[fields[i+1]] = fields[i]
+	}
+	c.Diff, err = git(ctx, repo, append([]string{"diff", "-U0", "--no-color", "--no-ext-diff", "--no-prefix", "--no-renames"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
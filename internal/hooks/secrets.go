@@ -0,0 +1,88 @@
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AllowSecret on a line suppresses secret findings for it, for test
+// fixtures and documented example keys.
+const AllowSecret = "intermap:allow-secret"
+
+// Secret is a likely credential on an added line. The value itself is
+// never reported.
+type Secret struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Kind string `json:"kind"`
+}
+
+var secretPatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN ((RSA|EC|DSA|OPENSSH|ENCRYPTED|PGP) )?PRIVATE KEY( BLOCK)?-----`)},
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"hardcoded credential", regexp.MustCompile(`(?i)\b(api[_-]?key|secret|password|passwd|token|access[_-]?key)["']?\s*[:=]\s*["']([^"'\s]{12,})["']`)},
+}
+
+// placeholder matches credential values that are obviously not real.
+var placeholder = regexp.MustCompile(`(?i)example|placeholder|changeme|dummy|xxxx|\$\{|\{\{|<[a-z_-]+>`)
+
+// ScanSecrets finds likely credentials on the added lines of a
+// zero-context unified diff made with --no-prefix.
+func ScanSecrets(diff []byte) []Secret {
+	var out []Secret
+	file, line := "", 0
+	sc := bufio.NewScanner(bytes.NewReader(diff))
+	sc.Buffer(make([]byte, 64*1024), 16<<20)
+	for sc.Scan() {
+		text := sc.Text()
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(text, "+++ ")
+			if file == "/dev/null" {
+				file = ""
+			}
+		case strings.HasPrefix(text, "@@ "):
+			line = hunkStart(text)
+		case strings.HasPrefix(text, "+"):
+			if file != "" && !strings.Contains(text, AllowSecret) {
+				if kind := secretKind(text[1:]); kind != "" {
+					out = append(out, Secret{File: file, Line: line, Kind: kind})
+				}
+			}
+			line++
+		}
+	}
+	return out
+}
+
+func secretKind(text string) string {
+	for _, p := range secretPatterns {
+		m := p.re.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		if p.kind == "hardcoded credential" && placeholder.MatchString(m[2]) {
+			continue
+		}
+		return p.kind
+	}
+	return ""
+}
+
+// hunkStart returns the new-file start line of a "@@ -a,b +c,d @@" header.
+func hunkStart(header string) int {
+	_, rest, _ := strings.Cut(header, " +")
+	rest, _, _ = strings.Cut(rest, " ")
+	rest, _, _ = strings.Cut(rest, ",")
+	n, _ := strconv.Atoi(rest)
+	return n
+}
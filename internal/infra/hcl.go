@@ -0,0 +1,244 @@
+package infra
+
+import "strings"
+
+// block is an HCL block, or a whole file body with an empty Type. Only
+// what infra_map reads is kept: string literals, objects, and lists;
+// other expressions parse as empty values.
+type block struct {
+	Type   string
+	Labels []string
+	Attrs  map[string]value
+	Blocks []*block
+}
+
+type value struct {
+	Str    string
+	IsStr  bool
+	Object map[string]value
+	List   []value
+}
+
+type token struct {
+	kind byte // 'i' identifier, 's' string, '\n', a punctuation byte, or 'o' other
+	text string
+}
+
+// parseHCL parses Terraform source leniently: anything it does not
+// understand is skipped rather than failing the file.
+func parseHCL(src string) *block {
+	p := &parser{toks: lexHCL(src)}
+	return p.body(0)
+}
+
+func lexHCL(src string) []token {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '\n':
+			toks = append(toks, token{kind: '\n'})
+			i++
+		case c == '#' || strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return toks
+			}
+			i += end + 4
+		case c == '"':
+			s, n := lexString(src[i+1:])
+			toks = append(toks, token{kind: 's', text: s})
+			i += n + 1
+		case strings.HasPrefix(src[i:], "<<"):
+			// Heredoc: skip through the line holding only its delimiter.
+			j := i + 2
+			if j < len(src) && src[j] == '-' {
+				j++
+			}
+			nl := strings.IndexByte(src[j:], '\n')
+			if nl < 0 {
+				return toks
+			}
+			delim := strings.TrimSpace(src[j : j+nl])
+			i = j + nl + 1
+			for i < len(src) {
+				line := src[i:]
+				if nl := strings.IndexByte(line, '\n'); nl >= 0 {
+					line = line[:nl]
+					i += nl + 1
+				} else {
+					i = len(src)
+				}
+				if strings.TrimSpace(line) == delim {
+					break
+				}
+			}
+			toks = append(toks, token{kind: 'o'}, token{kind: '\n'})
+		case strings.IndexByte("{}[]()=,:", c) >= 0:
+			toks = append(toks, token{kind: c})
+			i++
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(src) && isIdentByte(src[j]) {
+				j++
+			}
+			toks = append(toks, token{kind: 'i', text: src[i:j]})
+			i = j
+		default:
+			toks = append(toks, token{kind: 'o', text: string(c)})
+			i++
+		}
+	}
+	return toks
+}
+
+// lexString reads a quoted string body up to its closing quote, keeping
+// ${...} interpolations verbatim, and returns it with the bytes consumed.
+func lexString(s string) (string, int) {
+	var b strings.Builder
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(s[i])
+			}
+		case c == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			b.WriteString("${")
+			i++
+		case c == '}' && depth > 0:
+			depth--
+			b.WriteByte(c)
+		case c == '"' && depth == 0:
+			return b.String(), i + 1
+		case c == '\n' && depth == 0:
+			return b.String(), i
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), len(s)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentByte(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9' || c == '-' || c == '.'
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() byte {
+	if p.pos >= len(p.toks) {
+		return 0
+	}
+	return p.toks[p.pos].kind
+}
+
+// body parses attributes and blocks until the end byte ('}' or 0 for end
+// of input), consuming it.
+func (p *parser) body(end byte) *block {
+	b := &block{Attrs: map[string]value{}}
+	for {
+		switch k := p.peek(); {
+		case k == '\n' || k == ',':
+			p.pos++
+		case k == end || k == 0:
+			p.pos++
+			return b
+		case k == 'i' || k == 's':
+			name := p.toks[p.pos].text
+			p.pos++
+			if k := p.peek(); k == '=' || k == ':' {
+				p.pos++
+				b.Attrs[name] = p.expr()
+				continue
+			}
+			var labels []string
+			for k := p.peek(); k == 'i' || k == 's'; k = p.peek() {
+				labels = append(labels, p.toks[p.pos].text)
+				p.pos++
+			}
+			if p.peek() == '{' {
+				p.pos++
+				child := p.body('}')
+				child.Type, child.Labels = name, labels
+				b.Blocks = append(b.Blocks, child)
+			}
+		default:
+			p.pos++
+		}
+	}
+}
+
+// expr parses an attribute value, stopping before a newline, comma, or
+// closing bracket at its own nesting level.
+func (p *parser) expr() value {
+	switch p.peek() {
+	case '{':
+		p.pos++
+		return value{Object: p.body('}').Attrs}
+	case '[':
+		p.pos++
+		var list []value
+		for {
+			switch p.peek() {
+			case '\n', ',':
+				p.pos++
+				continue
+			case ']', 0:
+				p.pos++
+				return value{List: list}
+			}
+			start := p.pos
+			list = append(list, p.expr())
+			if p.pos == start {
+				p.pos++ // a stray closing bracket
+			}
+		}
+	case 's':
+		if p.pos+1 >= len(p.toks) || strings.IndexByte("\n,}]", p.toks[p.pos+1].kind) >= 0 {
+			v := value{Str: p.toks[p.pos].text, IsStr: true}
+			p.pos++
+			return v
+		}
+	}
+	depth := 0
+	for {
+		switch p.peek() {
+		case 0:
+			return value{}
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				return value{}
+			}
+			depth--
+		case '\n', ',':
+			if depth == 0 {
+				return value{}
+			}
+		}
+		p.pos++
+	}
+}
@@ -0,0 +1,465 @@
+// Package infra maps the infrastructure-as-code in a workspace: Terraform
+// modules with their providers, module calls, and resources, and Helm charts
+// with their dependencies. Each is linked to the application projects it
+// deploys, found through the container images and local paths it names.
+//
+// Terraform is read with a lenient HCL subset parser: only literal strings
+// count, so values built from variables or functions are not followed.
+package infra
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mistakeknot/intermap/internal/containers"
+	"github.com/mistakeknot/intermap/registry"
+	"gopkg.in/yaml.v3"
+)
+
+// maxFileSize bounds the Terraform and Helm files read.
+const maxFileSize = 1 << 20
+
+// Provider is a Terraform provider a module requires or configures.
+type Provider struct {
+	Name    string `json:"name"`
+	Source  string `json:"source,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// ModuleCall is a module block.
+type ModuleCall struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	Version string `json:"version,omitempty"`
+	// Dir is the called module's directory relative to the scanned root,
+	// for local sources.
+	Dir string `json:"dir,omitempty"`
+}
+
+// Deployment is an application project that a module or chart deploys.
+// Via says how it was found: "image <ref>", "path <dir>", or "chart <dir>".
+type Deployment struct {
+	Project string `json:"project"`
+	Via     string `json:"via"`
+}
+
+// Module is one Terraform module: a directory of .tf files. Paths are
+// relative to the scanned root.
+type Module struct {
+	Dir       string       `json:"dir"`
+	Providers []Provider   `json:"providers"`
+	Modules   []ModuleCall `json:"modules,omitempty"`
+	// Resources lists resource addresses (type.name).
+	Resources []string `json:"resources,omitempty"`
+	// Charts lists local chart directories installed by helm_release.
+	Charts  []string     `json:"charts,omitempty"`
+	Images  []string     `json:"images,omitempty"`
+	Project string       `json:"project,omitempty"`
+	Deploys []Deployment `json:"deploys"`
+
+	// paths are local directories and files the module references
+	// (archive sources, build contexts, Lambda packages).
+	paths []string
+}
+
+// ChartDependency is an entry of a chart's dependencies list.
+type ChartDependency struct {
+	Name       string `json:"name"`
+	Version    string `json:"version,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	// Dir is the dependency's chart directory for file:// repositories.
+	Dir string `json:"dir,omitempty"`
+}
+
+// Chart is one Helm chart: a directory with Chart.yaml.
+type Chart struct {
+	Dir          string            `json:"dir"`
+	Name         string            `json:"name"`
+	Version      string            `json:"version,omitempty"`
+	AppVersion   string            `json:"app_version,omitempty"`
+	Dependencies []ChartDependency `json:"dependencies,omitempty"`
+	// Images are the image repositories set in values.yaml.
+	Images  []string     `json:"images,omitempty"`
+	Project string       `json:"project,omitempty"`
+	Deploys []Deployment `json:"deploys"`
+}
+
+// Map is the infrastructure of a root directory.
+type Map struct {
+	Modules []Module `json:"modules"`
+	Charts  []Chart  `json:"charts"`
+}
+
+// skipDirs are never descended into. Hidden directories, including
+// .terraform provider caches, are skipped too.
+var skipDirs = map[string]bool{
+	"vendor": true, "node_modules": true, "__pycache__": true, "venv": true,
+	"testdata": true, "dist": true, "target": true,
+}
+
+// Scan walks root for Terraform modules and Helm charts.
+func Scan(root string) (*Map, error) {
+	tfFiles := make(map[string][]string) // module dir -> .tf files
+	var chartDirs []string
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != root && (strings.HasPrefix(name, ".") || skipDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		switch {
+		case strings.HasSuffix(name, ".tf"):
+			tfFiles[path.Dir(rel)] = append(tfFiles[path.Dir(rel)], rel)
+		case name == "Chart.yaml":
+			chartDirs = append(chartDirs, path.Dir(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Map{Modules: []Module{}, Charts: []Chart{}}
+	for dir, files := range tfFiles {
+		sort.Strings(files)
+		m.Modules = append(m.Modules, parseModule(root, dir, files))
+	}
+	for _, dir := range chartDirs {
+		if c, ok := parseChart(root, dir); ok {
+			m.Charts = append(m.Charts, c)
+		}
+	}
+	sort.Slice(m.Modules, func(i, j int) bool { return m.Modules[i].Dir < m.Modules[j].Dir })
+	sort.Slice(m.Charts, func(i, j int) bool { return m.Charts[i].Dir < m.Charts[j].Dir })
+	return m, nil
+}
+
+// --- Terraform ---
+
+// pathAttrs name attributes whose value is a local path worth following.
+var pathAttrs = map[string]bool{
+	"source_dir": true, "source_file": true, "filename": true,
+	"context": true, "build_context": true, "dockerfile": true, "path": true,
+}
+
+// imageRe finds image references anywhere in a .tf file, including
+// jsonencode() objects and heredoc task definitions the parser skips.
+var imageRe = regexp.MustCompile(`"?\b(?:image|image_uri|image_name)"?\s*[=:]\s*"([^"$\s]+)"`)
+
+func readSmall(p string) (string, bool) {
+	info, err := os.Stat(p)
+	if err != nil || info.Size() > maxFileSize {
+		return "", false
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func parseModule(root, dir string, files []string) Module {
+	m := Module{Dir: dir, Providers: []Provider{}, Deploys: []Deployment{}}
+	providers := make(map[string]*Provider)
+	addProvider := func(name string) *Provider {
+		if p, ok := providers[name]; ok {
+			return p
+		}
+		providers[name] = &Provider{Name: name}
+		return providers[name]
+	}
+	images := make(map[string]bool)
+	paths := make(map[string]bool)
+	charts := make(map[string]bool)
+
+	for _, rel := range files {
+		src, ok := readSmall(filepath.Join(root, filepath.FromSlash(rel)))
+		if !ok {
+			continue
+		}
+		for _, match := range imageRe.FindAllStringSubmatch(src, -1) {
+			images[match[1]] = true
+		}
+		for _, b := range parseHCL(src).Blocks {
+			switch b.Type {
+			case "terraform":
+				for _, rp := range b.Blocks {
+					if rp.Type != "required_providers" {
+						continue
+					}
+					for name, v := range rp.Attrs {
+						p := addProvider(name)
+						if v.IsStr {
+							p.Version = v.Str // pre-0.13 "name = version"
+						} else {
+							p.Source = v.Object["source"].Str
+							p.Version = v.Object["version"].Str
+						}
+					}
+				}
+			case "provider":
+				if len(b.Labels) > 0 {
+					addProvider(b.Labels[0])
+				}
+			case "module":
+				if len(b.Labels) == 0 {
+					continue
+				}
+				call := ModuleCall{Name: b.Labels[0], Source: b.Attrs["source"].Str, Version: b.Attrs["version"].Str}
+				if local, ok := localPath(dir, call.Source); ok {
+					call.Dir = local
+				}
+				m.Modules = append(m.Modules, call)
+			case "resource", "data":
+				if len(b.Labels) < 2 {
+					continue
+				}
+				if b.Type == "resource" {
+					m.Resources = append(m.Resources, b.Labels[0]+"."+b.Labels[1])
+				}
+				if b.Labels[0] == "helm_release" {
+					if local, ok := localPath(dir, b.Attrs["chart"].Str); ok {
+						charts[local] = true
+					}
+				}
+				collectPaths(b, dir, paths)
+			}
+		}
+	}
+
+	for _, p := range providers {
+		m.Providers = append(m.Providers, *p)
+	}
+	sort.Slice(m.Providers, func(i, j int) bool { return m.Providers[i].Name < m.Providers[j].Name })
+	sort.Slice(m.Modules, func(i, j int) bool { return m.Modules[i].Name < m.Modules[j].Name })
+	sort.Strings(m.Resources)
+	m.Charts = sortedKeys(charts)
+	m.Images = sortedKeys(images)
+	m.paths = sortedKeys(paths)
+	return m
+}
+
+// collectPaths adds the local paths named by path attributes anywhere in b.
+func collectPaths(b *block, dir string, out map[string]bool) {
+	for name, v := range b.Attrs {
+		if pathAttrs[name] {
+			if local, ok := localPath(dir, v.Str); ok {
+				out[local] = true
+			}
+		}
+	}
+	for _, child := range b.Blocks {
+		collectPaths(child, dir, out)
+	}
+}
+
+// localPath resolves a relative path written in module dir, where
+// ${path.module}, ${path.root}, and ${path.cwd} are taken as dir itself.
+// Other interpolations, registry and remote sources, and paths leaving the
+// scanned root are rejected.
+func localPath(dir, s string) (string, bool) {
+	for _, ref := range []string{"${path.module}", "${path.root}", "${path.cwd}"} {
+		s = strings.ReplaceAll(s, ref, ".")
+	}
+	if strings.Contains(s, "${") || !(s == "." || strings.HasPrefix(s, "./") || strings.HasPrefix(s, "../")) {
+		return "", false
+	}
+	p := path.Join(dir, s)
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return "", false
+	}
+	return p, true
+}
+
+// --- Helm ---
+
+func parseChart(root, dir string) (Chart, bool) {
+	src, ok := readSmall(filepath.Join(root, filepath.FromSlash(dir), "Chart.yaml"))
+	if !ok {
+		return Chart{}, false
+	}
+	var doc struct {
+		Name         string `yaml:"name"`
+		Version      string `yaml:"version"`
+		AppVersion   string `yaml:"appVersion"`
+		Dependencies []struct {
+			Name       string `yaml:"name"`
+			Version    string `yaml:"version"`
+			Repository string `yaml:"repository"`
+		} `yaml:"dependencies"`
+	}
+	if yaml.Unmarshal([]byte(src), &doc) != nil || doc.Name == "" {
+		return Chart{}, false
+	}
+	c := Chart{Dir: dir, Name: doc.Name, Version: doc.Version, AppVersion: doc.AppVersion, Deploys: []Deployment{}}
+	for _, d := range doc.Dependencies {
+		dep := ChartDependency{Name: d.Name, Version: d.Version, Repository: d.Repository}
+		if rest, ok := strings.CutPrefix(d.Repository, "file://"); ok {
+			if !strings.HasPrefix(rest, ".") {
+				rest = "./" + rest
+			}
+			dep.Dir, _ = localPath(dir, rest)
+		}
+		c.Dependencies = append(c.Dependencies, dep)
+	}
+
+	if values, ok := readSmall(filepath.Join(root, filepath.FromSlash(dir), "values.yaml")); ok {
+		var v any
+		if yaml.Unmarshal([]byte(values), &v) == nil {
+			images := make(map[string]bool)
+			collectValueImages(v, images)
+			c.Images = sortedKeys(images)
+		}
+	}
+	return c, true
+}
+
+// collectValueImages finds images in chart values: an "image" string, or
+// an "image" map with a repository (and optional registry).
+func collectValueImages(v any, out map[string]bool) {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			if k == "image" {
+				switch img := child.(type) {
+				case string:
+					if img != "" {
+						out[img] = true
+					}
+				case map[string]any:
+					repo, _ := img["repository"].(string)
+					if reg, _ := img["registry"].(string); reg != "" && repo != "" {
+						repo = reg + "/" + repo
+					}
+					if repo != "" {
+						out[repo] = true
+					}
+				}
+			}
+			collectValueImages(child, out)
+		}
+	case []any:
+		for _, child := range t {
+			collectValueImages(child, out)
+		}
+	}
+}
+
+// --- linking ---
+
+// Link sets Project and Deploys on modules and charts. A module or chart
+// belongs to the project containing it. It deploys the projects whose
+// images it names, matched first against images built from a project
+// (built, as from containers.Map.BuiltImages) and then by repository base
+// name against project names. Modules also deploy the projects holding
+// the local paths they reference, and whatever the local charts they
+// install deploy.
+func (m *Map) Link(root string, projects []registry.Project, built map[string]string) {
+	byName := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		byName[p.Name] = true
+	}
+	owner := func(rel string) string {
+		abs := filepath.Join(root, filepath.FromSlash(rel))
+		best, bestLen := "", -1
+		for _, p := range projects {
+			if (abs == p.Path || strings.HasPrefix(abs, p.Path+string(filepath.Separator))) && len(p.Path) > bestLen {
+				best, bestLen = p.Name, len(p.Path)
+			}
+		}
+		return best
+	}
+	imageProject := func(image string) string {
+		repo := containers.ImageRepo(image)
+		if p, ok := built[repo]; ok {
+			return p
+		}
+		if byName[path.Base(repo)] {
+			return path.Base(repo)
+		}
+		return ""
+	}
+
+	chartDeploys := make(map[string][]Deployment)
+	for i := range m.Charts {
+		c := &m.Charts[i]
+		c.Project = owner(c.Dir)
+		var d deploys
+		for _, img := range c.Images {
+			d.add(imageProject(img), "image "+img)
+		}
+		c.Deploys = d.list()
+		chartDeploys[c.Dir] = c.Deploys
+	}
+
+	for i := range m.Modules {
+		mod := &m.Modules[i]
+		mod.Project = owner(mod.Dir)
+		var d deploys
+		for _, img := range mod.Images {
+			d.add(imageProject(img), "image "+img)
+		}
+		for _, p := range mod.paths {
+			if p != mod.Dir && !strings.HasPrefix(p, mod.Dir+"/") {
+				d.add(owner(p), "path "+p)
+			}
+		}
+		for _, dir := range mod.Charts {
+			for _, cd := range chartDeploys[dir] {
+				d.add(cd.Project, "chart "+dir)
+			}
+		}
+		mod.Deploys = d.list()
+	}
+}
+
+// deploys collects deployments, keeping the first reason per project.
+type deploys struct {
+	seen map[string]bool
+	out  []Deployment
+}
+
+func (d *deploys) add(project, via string) {
+	if project == "" || d.seen[project] {
+		return
+	}
+	if d.seen == nil {
+		d.seen = make(map[string]bool)
+	}
+	d.seen[project] = true
+	d.out = append(d.out, Deployment{Project: project, Via: via})
+}
+
+func (d *deploys) list() []Deployment {
+	if d.out == nil {
+		return []Deployment{}
+	}
+	sort.Slice(d.out, func(i, j int) bool { return d.out[i].Project < d.out[j].Project })
+	return d.out
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
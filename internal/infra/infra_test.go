@@ -0,0 +1,191 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mistakeknot/intermap/registry"
+)
+
+func write(t *testing.T, root, rel, content string) {
+	t.Helper()
+	p := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseHCL(t *testing.T) {
+	src := `# comment
+terraform {
+  required_providers {
+    aws = { source = "hashicorp/aws", version = "~> 5.0" }
+    legacy = "1.2"
+  }
+}
+
+/* block
+   comment */
+resource "aws_ecs_task_definition" "api" {
+  family = "api-${var.env}"
+  container_definitions = <<EOF
+[{"image": "skipped"}]
+EOF
+  tags = [for t in var.tags : upper(t)]
+  cpu  = max(256, var.cpu)
+  dynamic "volume" {
+    content { name = "data" }
+  }
+}
+`
+	f := parseHCL(src)
+	if len(f.Blocks) != 2 {
+		t.Fatalf("blocks = %+v", f.Blocks)
+	}
+	rp := f.Blocks[0].Blocks[0]
+	if aws := rp.Attrs["aws"]; aws.Object["source"].Str != "hashicorp/aws" || aws.Object["version"].Str != "~> 5.0" {
+		t.Errorf("aws provider = %+v", aws)
+	}
+	if legacy := rp.Attrs["legacy"]; !legacy.IsStr || legacy.Str != "1.2" {
+		t.Errorf("legacy provider = %+v", legacy)
+	}
+	res := f.Blocks[1]
+	if res.Type != "resource" || !reflect.DeepEqual(res.Labels, []string{"aws_ecs_task_definition", "api"}) {
+		t.Errorf("resource = %s %v", res.Type, res.Labels)
+	}
+	if fam := res.Attrs["family"]; fam.Str != "api-${var.env}" {
+		t.Errorf("family = %+v", fam)
+	}
+	if cpu := res.Attrs["cpu"]; cpu.IsStr {
+		t.Errorf("function call parsed as string: %+v", cpu)
+	}
+	if len(res.Blocks) != 1 || res.Blocks[0].Blocks[0].Attrs["name"].Str != "data" {
+		t.Errorf("nested blocks = %+v", res.Blocks)
+	}
+}
+
+func TestScanAndLink(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "apps/api/main.go", "package main\n")
+	write(t, root, "apps/worker/main.py", "")
+	write(t, root, "apps/web/index.js", "")
+	write(t, root, "infra/main.tf", `
+terraform {
+  required_providers {
+    aws  = { source = "hashicorp/aws", version = "~> 5.0" }
+    helm = { source = "hashicorp/helm" }
+  }
+}
+
+provider "aws" { region = "us-east-1" }
+provider "google" {}
+
+module "network" {
+  source = "./modules/network"
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+}
+
+resource "aws_ecs_task_definition" "api" {
+  container_definitions = jsonencode([{ name = "api", image = "registry.example.com/acme/api:1.4" }])
+}
+
+data "archive_file" "worker" {
+  type        = "zip"
+  source_dir  = "${path.module}/../apps/worker"
+  output_path = "${path.module}/worker.zip"
+}
+
+resource "helm_release" "web" {
+  chart = "../charts/web"
+}
+`)
+	write(t, root, "infra/modules/network/main.tf", `resource "aws_vpc" "main" { cidr_block = "10.0.0.0/16" }`)
+	write(t, root, "infra/.terraform/modules/cached/main.tf", `provider "ignored" {}`)
+	write(t, root, "charts/web/Chart.yaml", `apiVersion: v2
+name: web
+version: 0.3.0
+appVersion: "2.1"
+dependencies:
+  - name: common
+    version: 1.x
+    repository: file://../common
+  - name: redis
+    version: 18.0.0
+    repository: https://charts.bitnami.com/bitnami
+`)
+	write(t, root, "charts/web/values.yaml", "image:\n  registry: ghcr.io\n  repository: acme/web\n  tag: latest\nsidecar:\n  image: busybox:1.36\n")
+	write(t, root, "charts/common/Chart.yaml", "name: common\nversion: 1.0.0\n")
+
+	m, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var projects []registry.Project
+	for _, name := range []string{"api", "worker", "web"} {
+		projects = append(projects, registry.Project{Name: name, Path: filepath.Join(root, "apps", name)})
+	}
+	m.Link(root, projects, map[string]string{"ghcr.io/acme/web": "web"})
+
+	if len(m.Modules) != 2 || m.Modules[0].Dir != "infra" || m.Modules[1].Dir != "infra/modules/network" {
+		t.Fatalf("modules = %+v", m.Modules)
+	}
+	mod := m.Modules[0]
+	wantProviders := []Provider{
+		{Name: "aws", Source: "hashicorp/aws", Version: "~> 5.0"},
+		{Name: "google"},
+		{Name: "helm", Source: "hashicorp/helm"},
+	}
+	if !reflect.DeepEqual(mod.Providers, wantProviders) {
+		t.Errorf("providers = %+v", mod.Providers)
+	}
+	wantCalls := []ModuleCall{
+		{Name: "network", Source: "./modules/network", Dir: "infra/modules/network"},
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0"},
+	}
+	if !reflect.DeepEqual(mod.Modules, wantCalls) {
+		t.Errorf("module calls = %+v", mod.Modules)
+	}
+	if want := []string{"aws_ecs_task_definition.api", "helm_release.web"}; !reflect.DeepEqual(mod.Resources, want) {
+		t.Errorf("resources = %v", mod.Resources)
+	}
+	if !reflect.DeepEqual(mod.Charts, []string{"charts/web"}) {
+		t.Errorf("charts = %v", mod.Charts)
+	}
+	wantDeploys := []Deployment{
+		{Project: "api", Via: "image registry.example.com/acme/api:1.4"},
+		{Project: "web", Via: "chart charts/web"},
+		{Project: "worker", Via: "path apps/worker"},
+	}
+	if !reflect.DeepEqual(mod.Deploys, wantDeploys) {
+		t.Errorf("deploys = %+v", mod.Deploys)
+	}
+	if len(m.Modules[1].Deploys) != 0 {
+		t.Errorf("network module deploys = %+v", m.Modules[1].Deploys)
+	}
+
+	if len(m.Charts) != 2 {
+		t.Fatalf("charts = %+v", m.Charts)
+	}
+	web := m.Charts[1]
+	if web.Name != "web" || web.Version != "0.3.0" || web.AppVersion != "2.1" {
+		t.Errorf("web chart = %+v", web)
+	}
+	if len(web.Dependencies) != 2 || web.Dependencies[0].Dir != "charts/common" || web.Dependencies[1].Dir != "" {
+		t.Errorf("chart dependencies = %+v", web.Dependencies)
+	}
+	if want := []string{"busybox:1.36", "ghcr.io/acme/web"}; !reflect.DeepEqual(web.Images, want) {
+		t.Errorf("chart images = %v", web.Images)
+	}
+	if want := []Deployment{{Project: "web", Via: "image ghcr.io/acme/web"}}; !reflect.DeepEqual(web.Deploys, want) {
+		t.Errorf("chart deploys = %+v", web.Deploys)
+	}
+}
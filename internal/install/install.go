@@ -0,0 +1,347 @@
+// Package install verifies an installed intermap plugin: the layout
+// Claude Code runs from CLAUDE_PLUGIN_ROOT, the binary and Python package
+// in it, and that they come from the same release. It can repair what the
+// running binary carries a copy of.
+package install
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Check statuses.
+const (
+	StatusOK       = "ok"
+	StatusWarn     = "warn"
+	StatusFail     = "fail"
+	StatusRepaired = "repaired"
+)
+
+// Layout paths, relative to the plugin root.
+const (
+	ManifestPath = ".claude-plugin/plugin.json"
+	BinaryPath   = "bin/intermap-mcp"
+	LauncherPath = "bin/launch-mcp.sh"
+	PythonDir    = "python"
+)
+
+// rootVar is the variable Claude Code expands in plugin.json.
+const rootVar = "${CLAUDE_PLUGIN_ROOT}"
+
+// Options configure Verify.
+type Options struct {
+	// Version is the running binary's version; plugin.json must match it.
+	Version string
+	// Python holds the Python sources the installed python/ directory must
+	// match, rooted like python/ (intermap/__main__.py, ...).
+	Python fs.FS
+	// Executable is the running binary, copied to bin/intermap-mcp on
+	// repair when that is missing. Empty disables that repair.
+	Executable string
+	// Repair fixes what it can: re-extracts Python sources that are missing
+	// or differ, installs Executable, and restores execute permissions.
+	Repair bool
+}
+
+// Check is the outcome of one verification step.
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Report is the result of Verify.
+type Report struct {
+	Root   string  `json:"root"`
+	Checks []Check `json:"checks"`
+}
+
+// OK reports whether no check failed.
+func (r *Report) OK() bool {
+	for _, c := range r.Checks {
+		if c.Status == StatusFail {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Report) add(name, status, format string, args ...any) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Detail: fmt.Sprintf(format, args...)})
+}
+
+// manifest is the part of plugin.json the checks read.
+type manifest struct {
+	Version    string `json:"version"`
+	MCPServers map[string]struct {
+		Command string            `json:"command"`
+		Args    []string          `json:"args"`
+		Env     map[string]string `json:"env"`
+	} `json:"mcpServers"`
+}
+
+// Verify checks the plugin installed at root.
+func Verify(root string, opts Options) *Report {
+	r := &Report{Root: root}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		r.add("root", StatusFail, "plugin root %s is not a directory", root)
+		return r
+	}
+	r.add("root", StatusOK, "%s", root)
+
+	m, err := readManifest(root)
+	if err != nil {
+		r.add("manifest", StatusFail, "%v", err)
+	} else {
+		r.add("manifest", StatusOK, "%s", ManifestPath)
+	}
+
+	checkBinary(r, root, opts)
+	checkExecutable(r, "launcher", filepath.Join(root, LauncherPath), opts.Repair)
+	checkPython(r, root, opts)
+	if m != nil {
+		checkVersion(r, m, opts.Version)
+		checkServers(r, root, m)
+	}
+	checkModule(r, root)
+	return r
+}
+
+func readManifest(root string) (*manifest, error) {
+	data, err := os.ReadFile(filepath.Join(root, ManifestPath))
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestPath, err)
+	}
+	return &m, nil
+}
+
+// checkBinary requires bin/intermap-mcp, installing the running binary in
+// its place on repair.
+func checkBinary(r *Report, root string, opts Options) {
+	path := filepath.Join(root, BinaryPath)
+	if _, err := os.Stat(path); err == nil {
+		checkExecutable(r, "binary", path, opts.Repair)
+		return
+	}
+	if !opts.Repair || opts.Executable == "" {
+		r.add("binary", StatusFail, "%s missing; the launcher will try `go build`", BinaryPath)
+		return
+	}
+	if err := copyFile(opts.Executable, path, 0o755); err != nil {
+		r.add("binary", StatusFail, "%s missing and install failed: %v", BinaryPath, err)
+		return
+	}
+	r.add("binary", StatusRepaired, "installed %s from %s", BinaryPath, opts.Executable)
+}
+
+// checkExecutable requires path to exist with an execute bit, restoring
+// the bits on repair.
+func checkExecutable(r *Report, name, path string, repair bool) {
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		r.add(name, StatusFail, "%v", err)
+	case info.Mode()&0o111 != 0:
+		r.add(name, StatusOK, "%s", path)
+	case !repair:
+		r.add(name, StatusFail, "%s is not executable", path)
+	default:
+		if err := os.Chmod(path, info.Mode()|0o755); err != nil {
+			r.add(name, StatusFail, "%s is not executable: %v", path, err)
+			return
+		}
+		r.add(name, StatusRepaired, "made %s executable", path)
+	}
+}
+
+// checkPython compares python/ with the embedded sources. Missing or
+// changed files are rewritten on repair; extra files are left alone.
+func checkPython(r *Report, root string, opts Options) {
+	if opts.Python == nil {
+		if _, err := os.Stat(filepath.Join(root, PythonDir, "intermap", "__main__.py")); err != nil {
+			r.add("python", StatusFail, "%v", err)
+		} else {
+			r.add("python", StatusOK, "%s/intermap present", PythonDir)
+		}
+		return
+	}
+
+	var missing, changed []string
+	var embedded int
+	err := fs.WalkDir(opts.Python, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		embedded++
+		want, err := fs.ReadFile(opts.Python, name)
+		if err != nil {
+			return err
+		}
+		got, err := os.ReadFile(filepath.Join(root, PythonDir, filepath.FromSlash(name)))
+		switch {
+		case err != nil:
+			missing = append(missing, name)
+		case !bytes.Equal(got, want):
+			changed = append(changed, name)
+		}
+		return nil
+	})
+	if err != nil {
+		r.add("python", StatusFail, "reading embedded sources: %v", err)
+		return
+	}
+	if len(missing)+len(changed) == 0 {
+		r.add("python", StatusOK, "%d files match this binary", embedded)
+		return
+	}
+
+	detail := describeDrift(missing, changed)
+	if !opts.Repair {
+		r.add("python", StatusFail, "%s differs from this binary: %s", PythonDir, detail)
+		return
+	}
+	for _, name := range append(missing, changed...) {
+		data, _ := fs.ReadFile(opts.Python, name)
+		dst := filepath.Join(root, PythonDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			r.add("python", StatusFail, "repair failed: %v", err)
+			return
+		}
+		if err := os.WriteFile(dst, data, 0o644); err != nil {
+			r.add("python", StatusFail, "repair failed: %v", err)
+			return
+		}
+	}
+	r.add("python", StatusRepaired, "re-extracted %s", detail)
+}
+
+func describeDrift(missing, changed []string) string {
+	var parts []string
+	for _, l := range []struct {
+		what  string
+		names []string
+	}{{"missing", missing}, {"changed", changed}} {
+		if len(l.names) == 0 {
+			continue
+		}
+		sort.Strings(l.names)
+		shown := l.names[:min(len(l.names), 3)]
+		more := ""
+		if len(l.names) > len(shown) {
+			more = fmt.Sprintf(" (+%d more)", len(l.names)-len(shown))
+		}
+		parts = append(parts, fmt.Sprintf("%d %s: %s%s", len(l.names), l.what, strings.Join(shown, ", "), more))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func checkVersion(r *Report, m *manifest, version string) {
+	switch {
+	case version == "":
+		r.add("version", StatusWarn, "binary version unknown; plugin.json says %s", m.Version)
+	case m.Version != version:
+		r.add("version", StatusFail, "plugin.json is %s but the binary is %s", m.Version, version)
+	default:
+		r.add("version", StatusOK, "%s", version)
+	}
+}
+
+// checkServers expands CLAUDE_PLUGIN_ROOT in each MCP server's command and
+// environment, as Claude Code does, and requires the paths to exist.
+func checkServers(r *Report, root string, m *manifest) {
+	names := make([]string, 0, len(m.MCPServers))
+	for name := range m.MCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		srv := m.MCPServers[name]
+		var bad []string
+		if path := expand(srv.Command, root); !exists(path) {
+			bad = append(bad, "command "+path)
+		}
+		for _, k := range sortedKeys(srv.Env) {
+			if !strings.Contains(srv.Env[k], rootVar) {
+				continue
+			}
+			for _, path := range filepath.SplitList(expand(srv.Env[k], root)) {
+				if !exists(path) {
+					bad = append(bad, k+" "+path)
+				}
+			}
+		}
+		if len(bad) > 0 {
+			r.add("mcp_server:"+name, StatusFail, "unresolvable from CLAUDE_PLUGIN_ROOT: %s", strings.Join(bad, ", "))
+		} else {
+			r.add("mcp_server:"+name, StatusOK, "%s", expand(srv.Command, root))
+		}
+	}
+}
+
+// checkModule warns when go.mod is missing: the launcher's `go build`
+// fallback then fails, which matters only if the binary is missing too.
+func checkModule(r *Report, root string) {
+	if exists(filepath.Join(root, "go.mod")) {
+		r.add("go_module", StatusOK, "go.mod present")
+		return
+	}
+	r.add("go_module", StatusWarn, "go.mod missing; the launcher cannot rebuild %s", BinaryPath)
+}
+
+func expand(s, root string) string {
+	return strings.ReplaceAll(s, rootVar, root)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// copyFile writes src to dst through a temporary file, so a running binary
+// at dst is replaced rather than truncated.
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".intermap-mcp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
@@ -0,0 +1,102 @@
+package install
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+const testManifest = `{
+  "version": "1.2.3",
+  "mcpServers": {
+    "intermap": {
+      "command": "${CLAUDE_PLUGIN_ROOT}/bin/launch-mcp.sh",
+      "env": {"PYTHONPATH": "${CLAUDE_PLUGIN_ROOT}/python", "MCP_TOOL_PROFILE": "core"}
+    }
+  }
+}`
+
+func writeFile(t *testing.T, path, content string, perm os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func statuses(r *Report) map[string]string {
+	m := map[string]string{}
+	for _, c := range r.Checks {
+		m[c.Name] = c.Status
+	}
+	return m
+}
+
+func TestVerify(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ManifestPath), testManifest, 0o644)
+	writeFile(t, filepath.Join(root, LauncherPath), "#!/bin/sh\n", 0o644)
+	writeFile(t, filepath.Join(root, PythonDir, "intermap", "__main__.py"), "old\n", 0o644)
+	exe := filepath.Join(t.TempDir(), "intermap-mcp")
+	writeFile(t, exe, "binary", 0o755)
+
+	opts := Options{
+		Version: "1.2.3",
+		Python: fstest.MapFS{
+			"intermap/__main__.py":        {Data: []byte("new\n")},
+			"intermap/vendor/__init__.py": {Data: []byte("")},
+		},
+		Executable: exe,
+	}
+
+	r := Verify(root, opts)
+	want := map[string]string{
+		"root": StatusOK, "manifest": StatusOK, "binary": StatusFail, "launcher": StatusFail,
+		"python": StatusFail, "version": StatusOK, "mcp_server:intermap": StatusOK, "go_module": StatusWarn,
+	}
+	if got := statuses(r); !maps.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if r.OK() {
+		t.Error("OK() = true with failures")
+	}
+
+	opts.Repair = true
+	r = Verify(root, opts)
+	want["binary"], want["launcher"], want["python"] = StatusRepaired, StatusRepaired, StatusRepaired
+	if got := statuses(r); !maps.Equal(got, want) {
+		t.Errorf("after repair: statuses = %v, want %v", got, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, PythonDir, "intermap", "__main__.py")); string(data) != "new\n" {
+		t.Errorf("__main__.py = %q after repair", data)
+	}
+	if _, err := os.Stat(filepath.Join(root, PythonDir, "intermap", "vendor", "__init__.py")); err != nil {
+		t.Error(err)
+	}
+
+	opts.Repair = false
+	opts.Version = "2.0.0"
+	r = Verify(root, opts)
+	if got := statuses(r); got["binary"] != StatusOK || got["python"] != StatusOK || got["version"] != StatusFail {
+		t.Errorf("after repair, new version: statuses = %v", got)
+	}
+}
+
+func TestVerify_UnresolvableServer(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ManifestPath), testManifest, 0o644)
+	r := Verify(root, Options{Version: "1.2.3"})
+	for _, c := range r.Checks {
+		if c.Name == "mcp_server:intermap" {
+			if c.Status != StatusFail {
+				t.Errorf("mcp_server status = %s, want fail", c.Status)
+			}
+			return
+		}
+	}
+	t.Error("no mcp_server check")
+}
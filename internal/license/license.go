@@ -0,0 +1,113 @@
+// Package license identifies software licenses and checks dependencies
+// against an allow/deny policy. Licenses are SPDX identifiers, combined in
+// SPDX expressions ("MIT OR Apache-2.0", "GPL-2.0-only WITH
+// Classpath-exception-2.0").
+package license
+
+import (
+	"regexp"
+	"strings"
+)
+
+// textMarkers identify a license from the text of a LICENSE file, in
+// order: the more specific GPL family variants come before plain GPL.
+var textMarkers = []struct {
+	id  string
+	all []string
+}{
+	{"AGPL-3.0", []string{"GNU AFFERO GENERAL PUBLIC LICENSE", "VERSION 3"}},
+	{"LGPL-3.0", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "VERSION 3"}},
+	{"LGPL-2.1", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "VERSION 2.1"}},
+	{"GPL-3.0", []string{"GNU GENERAL PUBLIC LICENSE", "VERSION 3"}},
+	{"GPL-2.0", []string{"GNU GENERAL PUBLIC LICENSE", "VERSION 2"}},
+	{"SSPL-1.0", []string{"SERVER SIDE PUBLIC LICENSE"}},
+	{"BUSL-1.1", []string{"BUSINESS SOURCE LICENSE"}},
+	{"MPL-2.0", []string{"MOZILLA PUBLIC LICENSE", "2.0"}},
+	{"EPL-2.0", []string{"ECLIPSE PUBLIC LICENSE", "2.0"}},
+	{"Apache-2.0", []string{"APACHE LICENSE", "VERSION 2.0"}},
+	{"BSD-3-Clause", []string{"REDISTRIBUTION AND USE IN SOURCE AND BINARY FORMS", "NEITHER THE NAME"}},
+	{"BSD-2-Clause", []string{"REDISTRIBUTION AND USE IN SOURCE AND BINARY FORMS"}},
+	{"ISC", []string{"PERMISSION TO USE, COPY, MODIFY, AND/OR DISTRIBUTE THIS SOFTWARE FOR ANY PURPOSE"}},
+	{"MIT", []string{"PERMISSION IS HEREBY GRANTED, FREE OF CHARGE"}},
+	{"Unlicense", []string{"THIS IS FREE AND UNENCUMBERED SOFTWARE RELEASED INTO THE PUBLIC DOMAIN"}},
+}
+
+var space = regexp.MustCompile(`\s+`)
+
+// Detect identifies the license in the text of a LICENSE file, or returns
+// "" if it is not one it recognizes.
+func Detect(text string) string {
+	t := space.ReplaceAllString(strings.ToUpper(text), " ")
+	for _, m := range textMarkers {
+		found := true
+		for _, s := range m.all {
+			if !strings.Contains(t, s) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return m.id
+		}
+	}
+	return ""
+}
+
+// aliases maps informal license names found in package metadata to SPDX
+// identifiers; keys are upper-cased with spaces collapsed.
+var aliases = map[string]string{
+	"APACHE 2.0":                            "Apache-2.0",
+	"APACHE-2":                              "Apache-2.0",
+	"APACHE LICENSE 2.0":                    "Apache-2.0",
+	"APACHE SOFTWARE LICENSE":               "Apache-2.0",
+	"MIT LICENSE":                           "MIT",
+	"BSD LICENSE":                           "BSD-3-Clause",
+	"ISC LICENSE (ISCL)":                    "ISC",
+	"GPLV2":                                 "GPL-2.0",
+	"GPLV3":                                 "GPL-3.0",
+	"LGPLV3":                                "LGPL-3.0",
+	"AGPLV3":                                "AGPL-3.0",
+	"PUBLIC DOMAIN":                         "LicenseRef-Public-Domain",
+	"OTHER/PROPRIETARY LICENSE":             "LicenseRef-Proprietary",
+	"THE UNLICENSE (UNLICENSE)":             "Unlicense",
+	"MOZILLA PUBLIC LICENSE 2.0 (MPL 2.0)":  "MPL-2.0",
+	"PYTHON SOFTWARE FOUNDATION LICENSE":    "PSF-2.0",
+	"GNU GENERAL PUBLIC LICENSE V2 (GPLV2)": "GPL-2.0",
+	"GNU GENERAL PUBLIC LICENSE V3 (GPLV3)": "GPL-3.0",
+	"GNU GENERAL PUBLIC LICENSE V3 OR LATER (GPLV3+)":         "GPL-3.0-or-later",
+	"GNU LESSER GENERAL PUBLIC LICENSE V3 (LGPLV3)":           "LGPL-3.0",
+	"GNU LESSER GENERAL PUBLIC LICENSE V2 OR LATER (LGPLV2+)": "LGPL-2.0-or-later",
+	"GNU AFFERO GENERAL PUBLIC LICENSE V3":                    "AGPL-3.0",
+}
+
+// FromClassifier maps a PyPI trove classifier ("License :: OSI Approved
+// :: MIT License") to an SPDX identifier, or "" if it names none.
+func FromClassifier(classifier string) string {
+	parts := strings.Split(classifier, "::")
+	if len(parts) < 2 || strings.TrimSpace(parts[0]) != "License" {
+		return ""
+	}
+	return Normalize(strings.TrimSpace(parts[len(parts)-1]))
+}
+
+// Normalize turns a license declaration from package metadata into an SPDX
+// expression: identifiers are kept, known informal names are mapped, and
+// Cargo's legacy "MIT/Apache-2.0" becomes an OR. It returns "" for empty,
+// NOASSERTION, and UNKNOWN declarations and for npm's "SEE LICENSE IN".
+func Normalize(s string) string {
+	s = strings.TrimSpace(s)
+	key := space.ReplaceAllString(strings.ToUpper(s), " ")
+	switch {
+	case key == "UNLICENSED":
+		return "LicenseRef-Proprietary" // npm's marker for "not licensed for use"
+	case key == "" || key == "NOASSERTION" || key == "NONE" || key == "UNKNOWN" || key == "OTHER" || strings.HasPrefix(key, "SEE LICENSE IN"):
+		return ""
+	}
+	if id, ok := aliases[key]; ok {
+		return id
+	}
+	if strings.Contains(s, "/") && !strings.ContainsAny(s, " ()") {
+		return strings.Join(strings.Split(s, "/"), " OR ")
+	}
+	return s
+}
@@ -0,0 +1,73 @@
+package license
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	for _, tc := range []struct{ text, want string }{
+		{"MIT License\n\nPermission is hereby granted, free of charge, to any person", "MIT"},
+		{"                                 Apache License\n                           Version 2.0, January 2004", "Apache-2.0"},
+		{"Redistribution and use in source and binary forms, with or without\nmodification... Neither the name of Google Inc.", "BSD-3-Clause"},
+		{"Redistribution and use in source and binary forms are permitted", "BSD-2-Clause"},
+		{"GNU LESSER GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007", "LGPL-3.0"},
+		{"GNU GENERAL PUBLIC LICENSE\n   Version 2, June 1991", "GPL-2.0"},
+		{"GNU AFFERO GENERAL PUBLIC LICENSE\nVersion 3, 19 November 2007", "AGPL-3.0"},
+		{"All rights reserved.", ""},
+	} {
+		if got := Detect(tc.text); got != tc.want {
+			t.Errorf("Detect(%.30q) = %q, want %q", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[string]string{
+		"MIT":                                "MIT",
+		"(MIT OR Apache-2.0)":                "(MIT OR Apache-2.0)",
+		"MIT/Apache-2.0":                     "MIT OR Apache-2.0",
+		"Apache 2.0":                         "Apache-2.0",
+		"UNLICENSED":                         "LicenseRef-Proprietary",
+		"SEE LICENSE IN LICENSE.md":          "",
+		"UNKNOWN":                            "",
+		"  ":                                 "",
+		"Python Software Foundation License": "PSF-2.0",
+	} {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := FromClassifier("License :: OSI Approved :: BSD License"); got != "BSD-3-Clause" {
+		t.Errorf("FromClassifier(BSD) = %q", got)
+	}
+	if got := FromClassifier("Programming Language :: Python"); got != "" {
+		t.Errorf("FromClassifier(non-license) = %q", got)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	deny := Policy{Deny: []string{"GPL-3.0", "AGPL-*"}}
+	allow := Policy{Allow: []string{"MIT", "Apache-2.0", "BSD-3-Clause"}, Deny: []string{"GPL-3.0"}}
+	for _, tc := range []struct {
+		policy Policy
+		expr   string
+		want   Verdict
+	}{
+		{deny, "MIT", Allowed},
+		{deny, "GPL-3.0-only", Denied},
+		{deny, "gpl-3.0+", Denied},
+		{deny, "AGPL-3.0-or-later", Denied},
+		{deny, "LGPL-3.0", Allowed},
+		{deny, "MIT OR GPL-3.0", Allowed},
+		{deny, "MIT AND GPL-3.0", Denied},
+		{deny, "(MIT AND GPL-3.0) OR Apache-2.0", Allowed},
+		{allow, "ISC", NotAllowed},
+		{allow, "GPL-3.0 OR ISC", NotAllowed},
+		{allow, "GPL-3.0 AND ISC", Denied},
+		{allow, "Apache-2.0 WITH LLVM-exception", Allowed},
+		{allow, "(MIT", NotAllowed},
+		{allow, "MIT", Allowed},
+	} {
+		if got := tc.policy.Evaluate(tc.expr); got != tc.want {
+			t.Errorf("%+v.Evaluate(%q) = %v, want %v", tc.policy, tc.expr, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package license
+
+import "strings"
+
+// Verdict is a policy decision for a license expression, ordered from
+// worst to best.
+type Verdict int
+
+const (
+	// Denied means the license is on the deny list.
+	Denied Verdict = iota
+	// NotAllowed means an allow list is set and the license is not on it.
+	NotAllowed
+	// Allowed means the license passes the policy.
+	Allowed
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Denied:
+		return "denied"
+	case NotAllowed:
+		return "not_allowed"
+	default:
+		return "allowed"
+	}
+}
+
+// Policy decides which licenses dependencies may use. Entries are SPDX
+// identifiers matched case-insensitively, ignoring "-only", "-or-later",
+// and "+" suffixes so "GPL-3.0" covers every GPL-3.0 variant; an entry
+// ending in "*" matches by prefix ("AGPL-*").
+type Policy struct {
+	// Allow, when set, is the only licenses permitted.
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+	// Exceptions exempts packages by name from the policy.
+	Exceptions []string `json:"exceptions,omitempty"`
+	// Unknown is what to do with packages whose license is not known:
+	// "warn" (the default), "deny", or "allow".
+	Unknown string `json:"unknown,omitempty"`
+}
+
+// Exempt reports whether the named package is excepted from the policy.
+func (p Policy) Exempt(name string) bool {
+	for _, e := range p.Exceptions {
+		if strings.EqualFold(e, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate applies the policy to an SPDX license expression. An OR is
+// as good as its best alternative and an AND as bad as its worst term;
+// WITH exceptions are judged by the license they modify.
+func (p Policy) Evaluate(expr string) Verdict {
+	toks := tokenize(expr)
+	e := &evaluator{toks: toks, policy: p}
+	v := e.or()
+	if e.pos != len(toks) {
+		// Not a well-formed expression: judge it as one identifier.
+		return p.evaluateID(expr)
+	}
+	return v
+}
+
+func (p Policy) evaluateID(id string) Verdict {
+	if matchAny(p.Deny, id) {
+		return Denied
+	}
+	if len(p.Allow) == 0 || matchAny(p.Allow, id) {
+		return Allowed
+	}
+	return NotAllowed
+}
+
+func canonical(id string) string {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	id = strings.TrimSuffix(id, "+")
+	id = strings.TrimSuffix(id, "-ONLY")
+	return strings.TrimSuffix(id, "-OR-LATER")
+}
+
+func matchAny(entries []string, id string) bool {
+	c := canonical(id)
+	for _, e := range entries {
+		if prefix, ok := strings.CutSuffix(strings.ToUpper(strings.TrimSpace(e)), "*"); ok {
+			if strings.HasPrefix(c, prefix) {
+				return true
+			}
+		} else if canonical(e) == c {
+			return true
+		}
+	}
+	return false
+}
+
+func tokenize(expr string) []string {
+	expr = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expr)
+	return strings.Fields(expr)
+}
+
+// evaluator is a recursive-descent evaluator over the grammar
+//
+//	or   = and { "OR" and }
+//	and  = with { "AND" with }
+//	with = atom [ "WITH" id ]
+//	atom = id | "(" or ")"
+type evaluator struct {
+	toks   []string
+	pos    int
+	policy Policy
+}
+
+func (e *evaluator) peek() string {
+	if e.pos < len(e.toks) {
+		return e.toks[e.pos]
+	}
+	return ""
+}
+
+func (e *evaluator) or() Verdict {
+	v := e.and()
+	for strings.EqualFold(e.peek(), "OR") {
+		e.pos++
+		v = max(v, e.and())
+	}
+	return v
+}
+
+func (e *evaluator) and() Verdict {
+	v := e.with()
+	for strings.EqualFold(e.peek(), "AND") {
+		e.pos++
+		v = min(v, e.with())
+	}
+	return v
+}
+
+func (e *evaluator) with() Verdict {
+	v := e.atom()
+	if strings.EqualFold(e.peek(), "WITH") {
+		e.pos += 2
+		if e.pos > len(e.toks) {
+			e.pos = len(e.toks) + 1 // malformed
+		}
+	}
+	return v
+}
+
+func (e *evaluator) atom() Verdict {
+	switch t := e.peek(); t {
+	case "":
+		e.pos = len(e.toks) + 1 // malformed
+		return NotAllowed
+	case "(":
+		e.pos++
+		v := e.or()
+		if e.peek() != ")" {
+			e.pos = len(e.toks) + 1
+			return v
+		}
+		e.pos++
+		return v
+	default:
+		e.pos++
+		return e.policy.evaluateID(t)
+	}
+}
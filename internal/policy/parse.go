@@ -0,0 +1,373 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("column %d: unterminated string", i+1)
+			}
+			s, err := strconv.Unquote(src[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("column %d: %v", i+1, err)
+			}
+			toks = append(toks, token{tokString, s, i})
+			i = j + 1
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(src) && (unicode.IsDigit(rune(src[j])) || src[j] == '.') {
+				j++
+			}
+			toks = append(toks, token{tokNumber, src[i:j], i})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(src) && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])) || src[j] == '_') {
+				j++
+			}
+			toks = append(toks, token{tokIdent, src[i:j], i})
+			i = j
+		default:
+			op := src[i : i+1]
+			if i+1 < len(src) && oneOf(src[i:i+2], "==", "!=", "<=", ">=") {
+				op = src[i : i+2]
+			}
+			if !oneOf(op, "(", ")", ",", ":", "==", "!=", "<", "<=", ">", ">=") {
+				return nil, fmt.Errorf("column %d: unexpected %q", i+1, op)
+			}
+			toks = append(toks, token{tokPunct, op, i})
+			i += len(op)
+		}
+	}
+	return append(toks, token{tokEOF, "", len(src)}), nil
+}
+
+func oneOf(s string, options ...string) bool {
+	for _, o := range options {
+		if s == o {
+			return true
+		}
+	}
+	return false
+}
+
+type parser struct {
+	toks []token
+	i    int
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return (t.kind == tokPunct || t.kind == tokIdent) && t.text == text
+}
+
+func (p *parser) expect(text string) error {
+	if !p.is(text) {
+		return p.errorf("expected %q", text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	t := p.peek()
+	at := "end of rule"
+	if t.kind != tokEOF {
+		at = fmt.Sprintf("%q", t.text)
+	}
+	return fmt.Errorf("column %d: %s, found %s", t.pos+1, fmt.Sprintf(format, args...), at)
+}
+
+// parseRule parses `action "name": condition`.
+func parseRule(src string) (Rule, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return Rule{}, err
+	}
+	p := &parser{toks: toks}
+	r := Rule{Source: src}
+	switch t := p.next(); {
+	case t.kind == tokIdent && (t.text == Block || t.text == Warn):
+		r.Action = t.text
+	default:
+		p.i--
+		return r, p.errorf("expected block or warn")
+	}
+	if p.peek().kind != tokString {
+		return r, p.errorf("expected the rule's quoted name")
+	}
+	r.Name = p.next().text
+	if err := p.expect(":"); err != nil {
+		return r, err
+	}
+	if r.cond, err = p.or(); err != nil {
+		return r, err
+	}
+	if p.peek().kind != tokEOF {
+		return r, p.errorf("expected and, or, or the end of the rule")
+	}
+	return r, nil
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	for err == nil && p.is("or") {
+		p.next()
+		var right node
+		if right, err = p.and(); err == nil {
+			left = logical{"or", left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	for err == nil && p.is("and") {
+		p.next()
+		var right node
+		if right, err = p.not(); err == nil {
+			left = logical{"and", left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) not() (node, error) {
+	if p.is("not") {
+		p.next()
+		x, err := p.not()
+		return not{x}, err
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (node, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokPunct && oneOf(t.text, "==", "!=", "<", "<=", ">", ">=") {
+		p.next()
+		right, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		return compare{t.text, left, right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokString:
+		p.next()
+		return literal{t.text}, nil
+	case tokNumber:
+		p.next()
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column %d: bad number %q", t.pos+1, t.text)
+		}
+		return literal{n}, nil
+	case tokPunct:
+		if t.text == "(" {
+			p.next()
+			x, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			p.next()
+			return literal{t.text == "true"}, nil
+		case "changed", "tests", "affected", "missing":
+			p.next()
+			return p.listCall(t.text)
+		case "count":
+			p.next()
+			if err := p.expect("("); err != nil {
+				return nil, err
+			}
+			x, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			return count{x}, p.expect(")")
+		}
+		return nil, fmt.Errorf("column %d: unknown function %q", t.pos+1, t.text)
+	}
+	return nil, p.errorf("expected a condition")
+}
+
+// listCall parses the glob arguments of a list function.
+func (p *parser) listCall(fn string) (node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	call := listCall{fn: fn}
+	for !p.is(")") {
+		if len(call.globs) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		if p.peek().kind != tokString {
+			return nil, p.errorf("%s takes quoted globs", fn)
+		}
+		call.globs = append(call.globs, compileGlob(strings.TrimPrefix(p.next().text, "./")))
+	}
+	p.next()
+	return call, nil
+}
+
+// node is a parsed condition.
+type node interface {
+	eval(e *env) (any, error)
+}
+
+type literal struct{ v any }
+
+func (l literal) eval(*env) (any, error) { return l.v, nil }
+
+type listCall struct {
+	fn    string
+	globs []*regexp.Regexp
+}
+
+func (c listCall) eval(e *env) (any, error) { return e.list(c.fn, c.globs), nil }
+
+type count struct{ x node }
+
+func (c count) eval(e *env) (any, error) {
+	v, err := c.x.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	l, ok := v.([]string)
+	if !ok {
+		return nil, fmt.Errorf("count needs a list, got %v", v)
+	}
+	return float64(len(l)), nil
+}
+
+type not struct{ x node }
+
+func (n not) eval(e *env) (any, error) {
+	e.negated++
+	defer func() { e.negated-- }()
+	v, err := n.x.eval(e)
+	return !truthy(v), err
+}
+
+type logical struct {
+	op          string
+	left, right node
+}
+
+// eval evaluates both sides, so evidence covers every list that counts.
+func (l logical) eval(e *env) (any, error) {
+	a, err := l.left.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	b, err := l.right.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if l.op == "and" {
+		return truthy(a) && truthy(b), nil
+	}
+	return truthy(a) || truthy(b), nil
+}
+
+type compare struct {
+	op          string
+	left, right node
+}
+
+func (c compare) eval(e *env) (any, error) {
+	a, err := c.left.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.right.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if l, ok := a.([]string); ok {
+		a = float64(len(l))
+	}
+	if l, ok := b.([]string); ok {
+		b = float64(len(l))
+	}
+	switch c.op {
+	case "==":
+		return a == b, nil
+	case "!=":
+		return a != b, nil
+	}
+	x, ok1 := a.(float64)
+	y, ok2 := b.(float64)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("%s compares numbers, got %v and %v", c.op, a, b)
+	}
+	switch c.op {
+	case "<":
+		return x < y, nil
+	case "<=":
+		return x <= y, nil
+	case ">":
+		return x > y, nil
+	}
+	return x >= y, nil
+}
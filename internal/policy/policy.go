@@ -0,0 +1,203 @@
+// Package policy is a small rule language for gating changes, evaluated
+// against change analysis. A rule is an action, a quoted name, and a
+// condition that triggers it:
+//
+//	block "client changes need tests": changed("internal/client/**") and not tests("internal/client/**")
+//	warn "untested impact": missing()
+//	warn "large change": count(changed()) > 40
+//
+// Conditions combine and, or, not, parentheses, and the comparisons ==,
+// !=, <, <=, >, >= over these functions:
+//
+//	changed(glob...)   changed files matching any glob (all with none)
+//	tests(glob...)     changed test files matching any glob
+//	affected(glob...)  tests the change impacts, matching any glob
+//	missing(glob...)   impacted tests the change does not touch
+//	count(list)        the number of items in list
+//
+// Globs match project-relative paths: `*` within a path segment, `**`
+// across segments, and `?` one character. A list is true when non-empty,
+// a number when non-zero.
+package policy
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mistakeknot/intermap/internal/stats"
+)
+
+// Actions.
+const (
+	Block = "block"
+	Warn  = "warn"
+)
+
+// Rule is a parsed rule.
+type Rule struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	// Source is the rule as written.
+	Source string `json:"rule"`
+	cond   node
+}
+
+// Facts are what rules are evaluated against.
+type Facts struct {
+	// Changed are the changed files, relative to the project.
+	Changed []string
+	// Affected are the tests the change impacts.
+	Affected []string
+}
+
+// Violation is a rule whose condition held.
+type Violation struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Rule   string `json:"rule"`
+	// Files are the files the condition's lists matched, as evidence.
+	Files []string `json:"files,omitempty"`
+}
+
+// Blocked reports whether any violation blocks.
+func Blocked(violations []Violation) bool {
+	return slices.ContainsFunc(violations, func(v Violation) bool { return v.Action == Block })
+}
+
+// Parse parses rules, one per entry. Blank entries and entries starting
+// with # are skipped.
+func Parse(rules []string) ([]Rule, error) {
+	var out []Rule
+	for i, src := range rules {
+		src = strings.TrimSpace(src)
+		if src == "" || strings.HasPrefix(src, "#") {
+			continue
+		}
+		r, err := parseRule(src)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// ParseText parses rules written one per line.
+func ParseText(text string) ([]Rule, error) {
+	return Parse(strings.Split(text, "\n"))
+}
+
+// Evaluate returns the rules whose conditions hold for facts, in rule
+// order.
+func Evaluate(rules []Rule, facts Facts) ([]Violation, error) {
+	env := &env{facts: facts, changedTests: map[string]bool{}}
+	for _, f := range facts.Changed {
+		if stats.IsTestFile(filepath.ToSlash(f)) {
+			env.changedTests[filepath.ToSlash(filepath.Clean(f))] = true
+		}
+	}
+	violations := []Violation{}
+	for _, r := range rules {
+		env.evidence = map[string]bool{}
+		v, err := r.cond.eval(env)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		if !truthy(v) {
+			continue
+		}
+		files := make([]string, 0, len(env.evidence))
+		for f := range env.evidence {
+			files = append(files, f)
+		}
+		slices.Sort(files)
+		violations = append(violations, Violation{Name: r.Name, Action: r.Action, Rule: r.Source, Files: files})
+	}
+	return violations, nil
+}
+
+// env evaluates one rule. evidence collects the files of lists that
+// count toward the condition, i.e. not under a not.
+type env struct {
+	facts        Facts
+	changedTests map[string]bool
+	evidence     map[string]bool
+	negated      int
+}
+
+func (e *env) list(fn string, globs []*regexp.Regexp) []string {
+	var from []string
+	switch fn {
+	case "changed":
+		from = e.facts.Changed
+	case "tests":
+		for _, f := range e.facts.Changed {
+			if e.changedTests[filepath.ToSlash(filepath.Clean(f))] {
+				from = append(from, f)
+			}
+		}
+	case "affected":
+		from = e.facts.Affected
+	case "missing":
+		for _, t := range e.facts.Affected {
+			if !e.changedTests[filepath.ToSlash(filepath.Clean(t))] {
+				from = append(from, t)
+			}
+		}
+	}
+	out := []string{}
+	for _, f := range from {
+		f = filepath.ToSlash(f)
+		if len(globs) == 0 || slices.ContainsFunc(globs, func(re *regexp.Regexp) bool { return re.MatchString(f) }) {
+			out = append(out, f)
+		}
+	}
+	if e.negated%2 == 0 {
+		for _, f := range out {
+			e.evidence[f] = true
+		}
+	}
+	return out
+}
+
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []string:
+		return len(v) > 0
+	}
+	return false
+}
+
+// compileGlob translates a glob to a regexp: `*` matches within a path
+// segment, `**` across segments, and `?` one character.
+func compileGlob(p string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
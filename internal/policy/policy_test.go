@@ -0,0 +1,106 @@
+package policy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	rules, err := Parse([]string{
+		`# comments and blank lines are skipped`,
+		``,
+		`block "client changes need tests": changed("internal/client/**") and not tests("internal/client/**")`,
+		`warn "untested impact": missing()`,
+		`warn "large change": count(changed()) > 2`,
+		`block "docs only": changed() == changed("docs/**", "*.md")`,
+		`warn "python": changed("**/*.py") or affected("tests/**")`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 5 || rules[0].Name != "client changes need tests" || rules[0].Action != Block {
+		t.Fatalf("rules = %+v", rules)
+	}
+
+	got, err := Evaluate(rules, Facts{
+		Changed:  []string{"internal/client/client.go", "internal/client/retry.go", "README.md"},
+		Affected: []string{"internal/client/client_test.go", "internal/api/api_test.go"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Violation{
+		{Name: "client changes need tests", Action: Block, Rule: rules[0].Source,
+			Files: []string{"internal/client/client.go", "internal/client/retry.go"}},
+		{Name: "untested impact", Action: Warn, Rule: rules[1].Source,
+			Files: []string{"internal/api/api_test.go", "internal/client/client_test.go"}},
+		{Name: "large change", Action: Warn, Rule: rules[2].Source,
+			Files: []string{"README.md", "internal/client/client.go", "internal/client/retry.go"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+	if !Blocked(got) || Blocked(got[1:]) {
+		t.Error("Blocked is wrong")
+	}
+
+	// Touching the client tests satisfies the first two rules.
+	got, err = Evaluate(rules[:2], Facts{
+		Changed:  []string{"internal/client/client.go", "internal/client/client_test.go"},
+		Affected: []string{"internal/client/client_test.go"},
+	})
+	if err != nil || len(got) != 0 {
+		t.Errorf("got %+v, %v", got, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		`deny "x": changed()`:              "expected block or warn",
+		`block x: changed()`:               "expected the rule's quoted name",
+		`block "x" changed()`:              `expected ":"`,
+		`block "x": changed(`:              "changed takes quoted globs, found end of rule",
+		`block "x": changed() and`:         "expected a condition",
+		`block "x": touched()`:             `unknown function "touched"`,
+		`block "x": changed() changed()`:   "expected and, or, or the end of the rule",
+		`block "x": changed("a) `:          "unterminated string",
+		`block "x": changed() & tests()`:   `unexpected "&"`,
+		`block "x": (changed() or tests()`: `expected ")"`,
+	} {
+		_, err := Parse([]string{"", src})
+		if err == nil || !strings.Contains(err.Error(), want) || !strings.HasPrefix(err.Error(), "rule 2: ") {
+			t.Errorf("Parse(%s) = %v, want %q", src, err, want)
+		}
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	rules, err := ParseText(`warn "bad": count("x") > 1` + "\n" + `warn "worse": changed() < "x"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rules {
+		if _, err := Evaluate([]Rule{r}, Facts{Changed: []string{"a"}}); err == nil {
+			t.Errorf("%s: no error", r.Name)
+		}
+	}
+}
+
+func TestGlob(t *testing.T) {
+	for _, tt := range []struct {
+		glob, path string
+		want       bool
+	}{
+		{"internal/client/**", "internal/client/a/b.go", true},
+		{"internal/client/**", "internal/clientx/b.go", false},
+		{"**/*.py", "a.py", true},
+		{"**/*.py", "x/y/a.py", true},
+		{"*.md", "docs/a.md", false},
+		{"cmd/?.go", "cmd/a.go", true},
+	} {
+		if got := compileGlob(tt.glob).MatchString(tt.path); got != tt.want {
+			t.Errorf("%s ~ %s = %v", tt.glob, tt.path, got)
+		}
+	}
+}
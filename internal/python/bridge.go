@@ -4,21 +4,24 @@
 // dead code detection, etc.) to Python via a persistent sidecar subprocess.
 //
 // The sidecar runs `python3 -u -m intermap --sidecar` and communicates via
-// newline-delimited JSON on stdin/stdout. If the sidecar crashes, it is
-// automatically respawned (up to 3 times in 10 seconds before falling back
-// to single-shot subprocess mode).
+// JSON frames on stdin/stdout, newline-delimited until the bridge upgrades
+// to length-prefixed frames during the ready handshake (see conn.go). If the
+// sidecar crashes, it is automatically respawned (up to 3 times in 10
+// seconds before falling back to single-shot subprocess mode).
 package python
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -26,27 +29,121 @@ import (
 
 // Bridge calls the Python analysis module via a persistent sidecar subprocess.
 type Bridge struct {
-	pythonPath string
-	timeout    time.Duration
+	command Command
+	timeout time.Duration
 
-	mu      sync.Mutex
-	proc    *exec.Cmd
-	stdin   io.WriteCloser
-	scanner *bufio.Scanner
-	nextID  atomic.Int64
+	mu     sync.Mutex
+	proc   *exec.Cmd
+	stdin  io.WriteCloser
+	conn   *conn
+	stderr *stderrTail // stderr of the current or last sidecar
+	nextID atomic.Int64
 
 	// Crash tracking for fallback
 	crashTimes []time.Time
 	fallback   bool // true = use single-shot mode (sidecar too unstable)
+
+	onUsage func(ctx context.Context, command string, u Usage)
+	sched   *scheduler
+	logger  *slog.Logger
+
+	// Responses over handoffBytes are passed through a file in handoffDir.
+	handoffBytes int
+	handoffDir   string
+}
+
+// DefaultHandoffBytes is the response size above which the sidecar hands
+// results off through a file instead of the pipe.
+const DefaultHandoffBytes = 8 << 20
+
+// Usage is the cost of one analysis command, as measured by the sidecar.
+type Usage struct {
+	CPUSeconds  float64 `json:"cpu_seconds"`
+	FilesParsed int     `json:"files_parsed"`
+}
+
+// SetUsageHook registers fn to be called with the cost of every command
+// that reached Python, including failed ones. Call it before the first Run.
+func (b *Bridge) SetUsageHook(fn func(ctx context.Context, command string, u Usage)) {
+	b.onUsage = fn
+}
+
+func (b *Bridge) reportUsage(ctx context.Context, command string, u *Usage) {
+	if b.onUsage != nil && u != nil {
+		b.onUsage(ctx, command, *u)
+	}
 }
 
 // NewBridge creates a Bridge. pythonPath should be the directory containing
 // the intermap Python package (e.g., <plugin-root>/python).
 func NewBridge(pythonPath string) *Bridge {
-	return &Bridge{
-		pythonPath: pythonPath,
-		timeout:    60 * time.Second,
+	return NewBridgeCommand(Command{
+		Path: "python3",
+		Args: []string{"-u", "-m", "intermap"},
+		Env:  []string{"PYTHONPATH=" + pythonPath},
+	})
+}
+
+// Command is the program a Bridge runs. It is started with "--sidecar"
+// appended for the persistent sidecar, or with "--command", "--project",
+// and "--args" for single-shot calls.
+type Command struct {
+	Path string
+	Args []string
+	// Env is added to the server's environment.
+	Env []string
+}
+
+// NewBridgeCommand creates a Bridge that runs cmd instead of the Python
+// module, e.g. a fake sidecar in tests (see package fakesidecar).
+func NewBridgeCommand(cmd Command) *Bridge {
+	b := &Bridge{
+		command:      cmd,
+		timeout:      60 * time.Second,
+		sched:        newScheduler(),
+		logger:       slog.Default(),
+		handoffBytes: DefaultHandoffBytes,
+		handoffDir:   os.TempDir(),
+	}
+	if v := os.Getenv("INTERMAP_HANDOFF_BYTES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			b.handoffBytes = n
+		} else {
+			fmt.Fprintf(os.Stderr, "intermap: ignoring INTERMAP_HANDOFF_BYTES=%q: %v\n", v, err)
+		}
+	}
+	if v := os.Getenv("INTERMAP_HANDOFF_DIR"); v != "" {
+		b.handoffDir = v
 	}
+	return b
+}
+
+// build returns the process for one sidecar or single-shot run.
+func (c Command) build(ctx context.Context, mode ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, c.Path, append(slices.Clone(c.Args), mode...)...)
+	cmd.Env = append(os.Environ(), c.Env...)
+	return cmd
+}
+
+// SetLogger sets where sidecar log records go; default slog.Default().
+func (b *Bridge) SetLogger(l *slog.Logger) {
+	b.logger = l
+}
+
+// SetHandoff sets the response size in bytes above which the sidecar writes
+// a result to a file in dir and sends only its path; 0 disables handoff. A
+// memory-backed dir such as /dev/shm keeps large results off the disk. The
+// defaults are DefaultHandoffBytes and os.TempDir(), or INTERMAP_HANDOFF_BYTES
+// and INTERMAP_HANDOFF_DIR. Call it before the first Run.
+func (b *Bridge) SetHandoff(minBytes int, dir string) {
+	b.handoffBytes = minBytes
+	b.handoffDir = dir
+}
+
+// SetPriorityWeights sets the share of sidecar turns each Priority gets
+// while several have commands waiting; see DefaultWeights.
+func (b *Bridge) SetPriorityWeights(w map[Priority]int) {
+	b.sched.setWeights(w)
 }
 
 // sidecarRequest is the JSON request sent to the Python sidecar.
@@ -57,16 +154,56 @@ type sidecarRequest struct {
 	Args    map[string]any `json:"args"`
 }
 
-// sidecarResponse is the JSON response from the Python sidecar.
+// sidecarResponse is a frame from the Python sidecar: a response to a
+// request, a reference to a handed-off response when Handoff is set, or a
+// log frame when Log is set.
 type sidecarResponse struct {
-	ID     int64          `json:"id"`
-	Result map[string]any `json:"result,omitempty"`
-	Error  *sidecarError  `json:"error,omitempty"`
+	Log     *sidecarLog    `json:"log,omitempty"`
+	ID      int64          `json:"id"`
+	Handoff *handoffRef    `json:"handoff,omitempty"`
+	Result  map[string]any `json:"result,omitempty"`
+	Error   *sidecarError  `json:"error,omitempty"`
+	Usage   *Usage         `json:"usage,omitempty"`
+}
+
+// sidecarLog is a Python logging record forwarded by the sidecar.
+type sidecarLog struct {
+	Level     string `json:"level"`
+	Logger    string `json:"logger"`
+	Message   string `json:"message"`
+	RequestID *int64 `json:"request_id"`
+	Command   string `json:"command"`
+	Exception string `json:"exception,omitempty"`
+}
+
+// slogLevel maps a Python level name to a slog level.
+func (l *sidecarLog) slogLevel() slog.Level {
+	switch l.Level {
+	case "debug":
+		return slog.LevelDebug
+	case "warning":
+		return slog.LevelWarn
+	case "error", "critical":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// emit writes the record to logger, tagged with its origin.
+func (l *sidecarLog) emit(ctx context.Context, logger *slog.Logger) {
+	attrs := []slog.Attr{slog.String("logger", l.Logger), slog.String("command", l.Command)}
+	if l.RequestID != nil {
+		attrs = append(attrs, slog.Int64("request_id", *l.RequestID))
+	}
+	if l.Exception != "" {
+		attrs = append(attrs, slog.String("exception", l.Exception))
+	}
+	logger.LogAttrs(ctx, l.slogLevel(), "sidecar: "+l.Message, attrs...)
 }
 
 type sidecarError struct {
-	Type        string `json:"type"`        // Legacy field (backward compat)
-	Code        string `json:"code"`        // Structured error code
+	Type        string `json:"type"` // Legacy field (backward compat)
+	Code        string `json:"code"` // Structured error code
 	Message     string `json:"message"`
 	Recoverable *bool  `json:"recoverable"` // Pointer to detect absence
 }
@@ -105,11 +242,17 @@ func IsRecoverable(err error) bool {
 }
 
 // Run executes a Python analysis command and returns the parsed JSON result.
+// Commands queued behind a busy sidecar are served by the Priority carried
+// in ctx (see WithPriority).
 func (b *Bridge) Run(ctx context.Context, command, project string, args map[string]any) (map[string]any, error) {
 	if b.fallback {
 		return b.runSingleShot(ctx, command, project, args)
 	}
 
+	if err := b.sched.acquire(ctx, PriorityFrom(ctx)); err != nil {
+		return nil, err
+	}
+	defer b.sched.release()
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -135,8 +278,9 @@ func (b *Bridge) Run(ctx context.Context, command, project string, args map[stri
 		// Retry with fresh sidecar
 		result, err = b.runSidecar(ctx, command, project, args)
 		if err != nil {
+			// Stopping waits for the process, so its stderr is complete.
 			b.stopLocked()
-			return nil, fmt.Errorf("python sidecar %s (retry failed): %w", command, err)
+			return nil, fmt.Errorf("python sidecar %s (retry failed): %w", command, b.stderr.wrap(err))
 		}
 	}
 
@@ -161,22 +305,50 @@ func (b *Bridge) runSidecar(ctx context.Context, command, project string, args m
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	// Write request
-	if _, err := b.stdin.Write(append(reqBytes, '\n')); err != nil {
+	// Snapshot conn into a local to avoid racing with stopLocked().
+	c := b.conn
+	if err := c.write(reqBytes); err != nil {
 		return nil, fmt.Errorf("write to sidecar: %w", err)
 	}
 
-	// Read response with timeout.
-	// Snapshot scanner into a local to avoid racing with stopLocked().
-	scanner := b.scanner
+	// Read response with timeout, logging any log frames that precede it.
+	logger := b.logger
 	type scanResult struct {
-		line string
-		ok   bool
+		resp sidecarResponse
+		err  error
 	}
 	ch := make(chan scanResult, 1)
 	go func() {
-		ok := scanner.Scan()
-		ch <- scanResult{line: scanner.Text(), ok: ok}
+		for {
+			frame, err := c.read()
+			if err != nil {
+				ch <- scanResult{err: err}
+				return
+			}
+			var resp sidecarResponse
+			if err := json.Unmarshal(frame, &resp); err != nil {
+				ch <- scanResult{err: fmt.Errorf("parse sidecar response: %w", err)}
+				return
+			}
+			if resp.Log != nil {
+				resp.Log.emit(ctx, logger)
+				continue
+			}
+			if resp.Handoff != nil {
+				if frame, err = c.take(*resp.Handoff); err == nil {
+					resp = sidecarResponse{}
+					if err = json.Unmarshal(frame, &resp); err != nil {
+						err = fmt.Errorf("parse sidecar response: %w", err)
+					}
+				}
+				if err != nil {
+					ch <- scanResult{err: err}
+					return
+				}
+			}
+			ch <- scanResult{resp: resp}
+			return
+		}
 	}()
 
 	deadline := b.timeout
@@ -188,13 +360,11 @@ func (b *Bridge) runSidecar(ctx context.Context, command, project string, args m
 
 	select {
 	case sr := <-ch:
-		if !sr.ok {
-			return nil, fmt.Errorf("sidecar EOF (process crashed)")
-		}
-		var resp sidecarResponse
-		if err := json.Unmarshal([]byte(sr.line), &resp); err != nil {
-			return nil, fmt.Errorf("parse sidecar response: %w", err)
+		if sr.err != nil {
+			return nil, sr.err
 		}
+		resp := sr.resp
+		b.reportUsage(ctx, command, resp.Usage)
 		if resp.Error != nil {
 			if resp.Error.isRecoverable() {
 				return nil, &RecoverableError{
@@ -220,8 +390,7 @@ func (b *Bridge) ensureStarted() error {
 		return nil
 	}
 
-	cmd := exec.Command("python3", "-u", "-m", "intermap", "--sidecar")
-	cmd.Env = append(os.Environ(), "PYTHONPATH="+b.pythonPath)
+	cmd := b.command.build(context.Background(), "--sidecar")
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
@@ -234,33 +403,25 @@ func (b *Bridge) ensureStarted() error {
 		return fmt.Errorf("create stdout pipe: %w", err)
 	}
 
-	cmd.Stderr = os.Stderr // Forward Python errors to Go's stderr
+	// Forward Python errors to Go's stderr, keeping the tail for errors.
+	b.stderr = newStderrTail(os.Stderr)
+	cmd.Stderr = b.stderr
 
 	if err := cmd.Start(); err != nil {
 		stdin.Close()
 		return fmt.Errorf("start sidecar: %w", err)
 	}
 
-	scanner := bufio.NewScanner(stdout)
-	scanner.Buffer(make([]byte, 0, 4*1024*1024), 4*1024*1024) // 4MB buffer for large results
-
-	// Wait for ready signal
-	if !scanner.Scan() {
+	c := newConn(stdin, stdout)
+	if err := c.handshake(b.handoffBytes, b.handoffDir); err != nil {
 		cmd.Process.Kill()
 		cmd.Wait()
-		return fmt.Errorf("sidecar failed to send ready signal")
-	}
-
-	var ready map[string]any
-	if err := json.Unmarshal([]byte(scanner.Text()), &ready); err != nil || ready["status"] != "ready" {
-		cmd.Process.Kill()
-		cmd.Wait()
-		return fmt.Errorf("sidecar ready signal invalid: %s", scanner.Text())
+		return err
 	}
 
 	b.proc = cmd
 	b.stdin = stdin
-	b.scanner = scanner
+	b.conn = c
 	return nil
 }
 
@@ -282,9 +443,12 @@ func (b *Bridge) stopLocked() {
 		b.proc.Process.Kill()
 		<-done
 	}
+	if b.conn.handoff != "" {
+		removeHandoffs(b.conn.handoff, b.proc.Process.Pid)
+	}
 	b.proc = nil
 	b.stdin = nil
-	b.scanner = nil
+	b.conn = nil
 }
 
 // recordCrash tracks crash times and switches to fallback if too many.
@@ -318,14 +482,19 @@ func (b *Bridge) runSingleShot(ctx context.Context, command, project string, arg
 	ctx, cancel := context.WithTimeout(ctx, b.timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "python3", "-m", "intermap",
+	cmd := b.command.build(ctx,
 		"--command", command,
 		"--project", project,
 		"--args", string(argsJSON),
 	)
-	cmd.Env = append(os.Environ(), "PYTHONPATH="+b.pythonPath)
 
 	stdout, err := cmd.Output()
+	if cmd.ProcessState != nil {
+		// Single-shot processes don't count parsed files; CPU time is the
+		// whole process's.
+		cpu := cmd.ProcessState.UserTime() + cmd.ProcessState.SystemTime()
+		b.reportUsage(ctx, command, &Usage{CPUSeconds: cpu.Seconds()})
+	}
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			var pyErr map[string]any
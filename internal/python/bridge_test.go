@@ -2,9 +2,12 @@ package python
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -103,7 +106,7 @@ func TestBridge_CrashRecovery(t *testing.T) {
 	}
 	b.proc = nil
 	b.stdin = nil
-	b.scanner = nil
+	b.conn = nil
 	b.mu.Unlock()
 
 	// Next request should auto-respawn
@@ -158,6 +161,137 @@ func TestBridge_ContextTimeout(t *testing.T) {
 	}
 }
 
+func TestBridge_LengthFraming(t *testing.T) {
+	pyPath := testPythonPath(t)
+	b := NewBridge(pyPath)
+	defer b.Close()
+
+	if _, err := b.Run(context.Background(), "structure", filepath.Join(pyPath, ".."), map[string]any{
+		"language":    "python",
+		"max_results": float64(1),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if b.conn.framing != framingLength {
+		t.Errorf("framing = %s, want %s", b.conn.framing, framingLength)
+	}
+}
+
+func TestBridge_Handoff(t *testing.T) {
+	pyPath := testPythonPath(t)
+	b := NewBridge(pyPath)
+	dir := t.TempDir()
+	b.SetHandoff(100, dir)
+
+	result, err := b.Run(context.Background(), "structure", filepath.Join(pyPath, ".."), map[string]any{
+		"language":    "python",
+		"max_results": float64(2),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result["files"]; !ok {
+		t.Errorf("result = %v", result)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("handoff files left after read: %v", files)
+	}
+
+	// Files the sidecar leaves unread are removed when it stops.
+	stale := filepath.Join(dir, fmt.Sprintf("%s%d-stale.json", handoffPrefix, b.proc.Process.Pid))
+	if err := os.WriteFile(stale, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	b.Close()
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale handoff file not removed: %v", err)
+	}
+}
+
+func TestConn_TakeOutsideHandoffDir(t *testing.T) {
+	c := &conn{handoff: t.TempDir()}
+	outside := filepath.Join(t.TempDir(), handoffPrefix+"1-x.json")
+	os.WriteFile(outside, []byte("{}"), 0o600)
+	if _, err := c.take(handoffRef{Path: outside, Bytes: 2}); err == nil {
+		t.Error("take read a file outside the handoff directory")
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("file outside the handoff directory was touched: %v", err)
+	}
+}
+
+func TestConn_ReadRejectsBadFrames(t *testing.T) {
+	for name, stream := range map[string]string{
+		"oversized": "2000000000\n{}",
+		"negative":  "-1\n",
+		"garbled":   "{\"id\": 1}\n",
+		"no header": strings.Repeat("x", 100<<10),
+	} {
+		c := newConn(nil, strings.NewReader(stream))
+		c.framing = framingLength
+		if _, err := c.read(); !errors.Is(err, errBadFrame) {
+			t.Errorf("%s: err = %v, want a protocol error", name, err)
+		}
+	}
+
+	c := newConn(nil, strings.NewReader("2\n{}12"))
+	c.framing = framingLength
+	if msg, err := c.read(); err != nil || string(msg) != "{}" {
+		t.Fatalf("read = %q, %v", msg, err)
+	}
+	if _, err := c.read(); err != errSidecarEOF {
+		t.Errorf("truncated header: err = %v, want EOF", err)
+	}
+}
+
+func TestBridge_BadFrameRestartsSidecar(t *testing.T) {
+	dir := t.TempDir()
+	pkg := filepath.Join(dir, "intermap")
+	if err := os.Mkdir(pkg, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// The first sidecar answers with an absurd length header; the one that
+	// replaces it answers normally.
+	marker := filepath.Join(dir, "garbled")
+	src := "import json, os, sys\n" +
+		"out, inp = sys.stdout.buffer, sys.stdin.buffer\n" +
+		"def send(obj):\n" +
+		"    data = json.dumps(obj).encode()\n" +
+		"    out.write(b'%d\\n' % len(data) + data)\n" +
+		"    out.flush()\n" +
+		"print(json.dumps({'status': 'ready', 'framing': ['length']}), flush=True)\n" +
+		"inp.readline()\n" +
+		"send({'framing': 'length'})\n" +
+		"while True:\n" +
+		"    header = inp.readline()\n" +
+		"    if not header:\n" +
+		"        break\n" +
+		"    req = json.loads(inp.read(int(header)))\n" +
+		fmt.Sprintf("    if not os.path.exists(%q):\n", marker) +
+		fmt.Sprintf("        open(%q, 'w').close()\n", marker) +
+		"        out.write(b'99999999999\\n')\n" +
+		"        out.flush()\n" +
+		"        continue\n" +
+		"    send({'id': req['id'], 'result': {'pid': os.getpid()}})\n"
+	if err := os.WriteFile(filepath.Join(pkg, "__main__.py"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(pkg, "__init__.py"), nil, 0o644)
+
+	b := NewBridge(dir)
+	defer b.Close()
+	result, err := b.Run(context.Background(), "structure", ".", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatal("first sidecar never sent the bad frame")
+	}
+	if pid, _ := result["pid"].(float64); int(pid) != b.proc.Process.Pid || len(b.crashTimes) != 1 {
+		t.Errorf("result from pid %v, sidecar %d, crashes %d", result["pid"], b.proc.Process.Pid, len(b.crashTimes))
+	}
+}
+
 func TestBridge_FallbackMode(t *testing.T) {
 	pyPath := testPythonPath(t)
 	b := NewBridge(pyPath)
@@ -229,3 +363,98 @@ func TestSidecarError_BackwardCompat(t *testing.T) {
 		t.Error("expected structured fatal error to not be recoverable")
 	}
 }
+
+// stubSidecar writes a fake intermap package whose sidecar runs body for
+// each request (with req bound), returning its python path.
+func stubSidecar(t *testing.T, body string) string {
+	t.Helper()
+	dir := t.TempDir()
+	pkg := filepath.Join(dir, "intermap")
+	if err := os.Mkdir(pkg, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := "import json, sys\n" +
+		"print(json.dumps({'status': 'ready'}), flush=True)\n" +
+		"for line in sys.stdin:\n" +
+		"    req = json.loads(line)\n" +
+		body
+	if err := os.WriteFile(filepath.Join(pkg, "__main__.py"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(pkg, "__init__.py"), nil, 0o644)
+	return dir
+}
+
+func TestBridge_LogFrames(t *testing.T) {
+	b := NewBridge(stubSidecar(t,
+		"    print(json.dumps({'log': {'level': 'warning', 'logger': 'intermap.vcs', 'message': 'no repo', 'request_id': req['id'], 'command': req['command']}}), flush=True)\n"+
+			"    print(json.dumps({'id': req['id'], 'result': {'ok': True}}), flush=True)\n"))
+	defer b.Close()
+	var buf strings.Builder
+	b.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	result, err := b.Run(context.Background(), "live_changes", ".", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["ok"] != true {
+		t.Errorf("result = %v", result)
+	}
+	got := buf.String()
+	for _, want := range []string{"level=WARN", `msg="sidecar: no repo"`, "logger=intermap.vcs", "command=live_changes", "request_id=1"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log %q missing %s", got, want)
+		}
+	}
+}
+
+func TestBridge_NDJSONWithoutLimit(t *testing.T) {
+	// The stub's ready frame offers no framing, so the bridge stays on
+	// ndjson; lines are no longer capped at 4MB.
+	b := NewBridge(stubSidecar(t,
+		"    print(json.dumps({'id': req['id'], 'result': {'blob': 'x' * (5 << 20)}}), flush=True)\n"))
+	defer b.Close()
+
+	result, err := b.Run(context.Background(), "structure", ".", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blob, _ := result["blob"].(string); len(blob) != 5<<20 {
+		t.Errorf("blob has %d bytes", len(blob))
+	}
+	if b.conn.framing != framingNDJSON {
+		t.Errorf("framing = %s, want %s", b.conn.framing, framingNDJSON)
+	}
+}
+
+func TestBridge_CrashIncludesStderr(t *testing.T) {
+	b := NewBridge(stubSidecar(t,
+		"    sys.stderr.write('Traceback (most recent call last):\\nMemoryError: out of memory\\n')\n"+
+			"    sys.exit(1)\n"))
+	defer b.Close()
+
+	_, err := b.Run(context.Background(), "structure", ".", nil)
+	if err == nil {
+		t.Fatal("expected an error from a crashing sidecar")
+	}
+	if !strings.Contains(err.Error(), "sidecar EOF") || !strings.Contains(err.Error(), "MemoryError: out of memory") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestStderrTail(t *testing.T) {
+	var out strings.Builder
+	tail := newStderrTail(&out)
+	for i := range stderrTailLines + 5 {
+		fmt.Fprintf(tail, "line %d\n", i)
+	}
+	tail.Write([]byte("partial " + strings.Repeat("x", 2*stderrMaxLine)))
+
+	lines := strings.Split(tail.String(), "\n")
+	if len(lines) != stderrTailLines+1 || lines[0] != "line 5" || len(lines[stderrTailLines]) != stderrMaxLine {
+		t.Errorf("tail has %d lines, first %q", len(lines), lines[0])
+	}
+	if !strings.HasPrefix(out.String(), "line 0\n") {
+		t.Error("stderr not forwarded")
+	}
+}
@@ -0,0 +1,186 @@
+package python
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Framings are how JSON messages are delimited on the sidecar's stdin and
+// stdout. Every sidecar starts with ndjson, one message per line. A sidecar
+// that lists "length" in its ready frame is switched to length frames: the
+// payload's byte count in decimal, a newline, then the payload. A length
+// frame over maxFrameBytes, or a header that is not a byte count, is a
+// protocol error: the stream can no longer be trusted, so the bridge stops
+// the sidecar and starts a fresh one. ndjson lines are not capped, since
+// they only grow with what the sidecar actually writes.
+const (
+	framingNDJSON = "ndjson"
+	framingLength = "length"
+)
+
+// maxFrameBytes bounds a length frame's payload, which read allocates up
+// front. Larger results should be handed off through a file.
+const maxFrameBytes = 1 << 30
+
+// maxHeaderBytes bounds a length frame's header line.
+const maxHeaderBytes = 32
+
+// errBadFrame reports a length frame that cannot be read.
+var errBadFrame = errors.New("sidecar protocol error")
+
+// Results too large for the pipe can be handed off through a file. A
+// sidecar that can do so says "handoff": true in its ready frame, and the
+// bridge passes {"dir", "min_bytes"} with its framing choice. A response
+// whose JSON exceeds min_bytes is then written to a file named
+// handoffPrefix<pid>-*.json in dir, and the frame carries only
+// {"id", "handoff": {"path", "bytes"}}. The bridge reads and deletes the
+// file; files of a sidecar that dies before they are read are removed when
+// it is stopped. Handoff needs length frames.
+const handoffPrefix = "intermap-result-"
+
+// handoffRef is the file a handed-off response was written to.
+type handoffRef struct {
+	Path  string `json:"path"`
+	Bytes int    `json:"bytes"`
+}
+
+// errSidecarEOF reports that the sidecar closed stdout mid-conversation.
+var errSidecarEOF = errors.New("sidecar EOF (process crashed)")
+
+// conn is the framed stdin/stdout of a running sidecar.
+type conn struct {
+	w       io.Writer
+	r       *bufio.Reader
+	framing string
+	handoff string // directory of handed-off responses; "" if off
+}
+
+func newConn(w io.Writer, r io.Reader) *conn {
+	return &conn{w: w, r: bufio.NewReaderSize(r, 64*1024), framing: framingNDJSON}
+}
+
+// handshake waits for the sidecar's ready frame and upgrades to length
+// frames when the sidecar supports them, enabling handoff of responses over
+// handoffBytes to dir if it supports that too and handoffBytes > 0. The
+// sidecar acknowledges the switch in the new framing; older sidecars
+// advertise nothing and stay on ndjson.
+func (c *conn) handshake(handoffBytes int, dir string) error {
+	frame, err := c.read()
+	if err != nil {
+		return fmt.Errorf("sidecar failed to send ready signal")
+	}
+	var ready struct {
+		Status  string   `json:"status"`
+		Framing []string `json:"framing"`
+		Handoff bool     `json:"handoff"`
+	}
+	if err := json.Unmarshal(frame, &ready); err != nil || ready.Status != "ready" {
+		return fmt.Errorf("sidecar ready signal invalid: %s", frame)
+	}
+	if !slices.Contains(ready.Framing, framingLength) {
+		return nil
+	}
+
+	sel := map[string]any{"framing": framingLength}
+	if ready.Handoff && handoffBytes > 0 {
+		sel["handoff"] = map[string]any{"dir": dir, "min_bytes": handoffBytes}
+	}
+	msg, _ := json.Marshal(sel)
+	if err := c.write(msg); err != nil {
+		return fmt.Errorf("select sidecar framing: %w", err)
+	}
+	c.framing = framingLength
+	frame, err = c.read()
+	if err != nil {
+		return fmt.Errorf("select sidecar framing: %w", err)
+	}
+	var ack struct {
+		Framing string `json:"framing"`
+	}
+	if err := json.Unmarshal(frame, &ack); err != nil || ack.Framing != framingLength {
+		return fmt.Errorf("sidecar did not acknowledge %s framing: %s", framingLength, frame)
+	}
+	if sel["handoff"] != nil {
+		c.handoff = dir
+	}
+	return nil
+}
+
+// take reads and deletes the file a response was handed off to. Only files
+// the sidecar may have written, under the handoff directory, are read.
+func (c *conn) take(ref handoffRef) ([]byte, error) {
+	if c.handoff == "" || filepath.Dir(ref.Path) != filepath.Clean(c.handoff) ||
+		!strings.HasPrefix(filepath.Base(ref.Path), handoffPrefix) {
+		return nil, fmt.Errorf("read sidecar handoff: unexpected file %q", ref.Path)
+	}
+	data, err := os.ReadFile(ref.Path)
+	os.Remove(ref.Path)
+	if err != nil {
+		return nil, fmt.Errorf("read sidecar handoff: %w", err)
+	}
+	if len(data) != ref.Bytes {
+		return nil, fmt.Errorf("read sidecar handoff: %s has %d bytes, want %d", ref.Path, len(data), ref.Bytes)
+	}
+	return data, nil
+}
+
+// removeHandoffs deletes the handoff files left by sidecar pid.
+func removeHandoffs(dir string, pid int) {
+	files, _ := filepath.Glob(filepath.Join(dir, fmt.Sprintf("%s%d-*.json", handoffPrefix, pid)))
+	for _, f := range files {
+		os.Remove(f)
+	}
+}
+
+// write sends one message.
+func (c *conn) write(msg []byte) error {
+	var frame []byte
+	if c.framing == framingLength {
+		frame = strconv.AppendInt(nil, int64(len(msg)), 10)
+		frame = append(frame, '\n')
+		frame = append(frame, msg...)
+	} else {
+		frame = append(msg[:len(msg):len(msg)], '\n')
+	}
+	_, err := c.w.Write(frame)
+	return err
+}
+
+// read returns the next message.
+func (c *conn) read() ([]byte, error) {
+	if c.framing != framingLength {
+		line, err := c.r.ReadBytes('\n')
+		if err != nil {
+			return nil, errSidecarEOF
+		}
+		return bytes.TrimSuffix(line, []byte("\n")), nil
+	}
+	header, err := c.r.ReadSlice('\n')
+	if err != nil && err != bufio.ErrBufferFull {
+		return nil, errSidecarEOF
+	}
+	if err != nil || len(header) > maxHeaderBytes {
+		return nil, fmt.Errorf("%w: frame header %.32q... is too long", errBadFrame, header)
+	}
+	n, err := strconv.Atoi(string(header[:len(header)-1]))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("%w: bad frame header %q", errBadFrame, header)
+	}
+	if n > maxFrameBytes {
+		return nil, fmt.Errorf("%w: frame of %d bytes exceeds the %d byte limit", errBadFrame, n, maxFrameBytes)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(c.r, msg); err != nil {
+		return nil, errSidecarEOF
+	}
+	return msg, nil
+}
@@ -0,0 +1,381 @@
+// Package fakesidecar is a scripted stand-in for the Python sidecar, so the
+// bridge and the tools built on it can be tested without Python.
+//
+// New returns a Bridge whose "sidecar" is the test binary itself,
+// re-executed with an environment variable that makes this package's init
+// serve the sidecar protocol from a Script instead of running tests.
+// Importing the package is all a test binary needs; no TestMain is
+// required.
+//
+//	fake := fakesidecar.New(t, fakesidecar.Script{
+//		"structure": {{Crash: true, Stderr: "Traceback ..."}, {Result: map[string]any{"files": []any{}}}},
+//	})
+//	result, err := fake.Bridge.Run(ctx, "structure", dir, nil)
+//
+// Each command's responses are used in order across sidecar restarts, and
+// the last one repeats.
+package fakesidecar
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+)
+
+// envDir names the fixture directory in the re-executed test binary.
+const envDir = "INTERMAP_FAKESIDECAR_DIR"
+
+const (
+	scriptFile   = "script.json"
+	requestsFile = "requests.jsonl"
+)
+
+// Script maps command names to the responses the fake gives, in order.
+// The key "*" answers commands that have no entry. An unscripted command
+// gets a recoverable "unscripted" error.
+type Script map[string][]Response
+
+// Response is one scripted reply. Fields apply in this order: Delay,
+// Stderr, Logs, then Crash, Hang, Raw, or the response frame.
+type Response struct {
+	// Delay is slept before answering.
+	Delay time.Duration `json:"delay,omitempty"`
+	// Stderr is written to the sidecar's stderr.
+	Stderr string `json:"stderr,omitempty"`
+	// Logs are sent as log frames before the response.
+	Logs []Log `json:"logs,omitempty"`
+	// Crash exits the process with ExitCode (default 1) without answering.
+	Crash    bool `json:"crash,omitempty"`
+	ExitCode int  `json:"exit_code,omitempty"`
+	// Hang never answers this or any later request on the same process.
+	Hang bool `json:"hang,omitempty"`
+	// Raw is written as the response frame's payload verbatim, e.g.
+	// malformed JSON.
+	Raw string `json:"raw,omitempty"`
+
+	Result map[string]any  `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+	Usage  *pybridge.Usage `json:"usage,omitempty"`
+}
+
+// Error is a sidecar error frame.
+type Error struct {
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	Recoverable bool   `json:"recoverable"`
+}
+
+// Log is a sidecar log frame. The request ID and command are filled in.
+type Log struct {
+	Level   string `json:"level"`
+	Logger  string `json:"logger"`
+	Message string `json:"message"`
+}
+
+// Request is a call the fake received.
+type Request struct {
+	ID      int64          `json:"id"`
+	Command string         `json:"command"`
+	Project string         `json:"project"`
+	Args    map[string]any `json:"args"`
+	// SingleShot is set for calls made in the bridge's fallback mode.
+	SingleShot bool `json:"single_shot,omitempty"`
+}
+
+// Fake is a scripted sidecar and the Bridge that talks to it.
+type Fake struct {
+	Bridge *pybridge.Bridge
+	dir    string
+}
+
+// New writes script to a temporary fixture directory and returns a Fake
+// whose Bridge runs against it. The Bridge is closed when t ends.
+func New(t testing.TB, script Script) *Fake {
+	t.Helper()
+	dir := t.TempDir()
+	data, err := json.Marshal(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, scriptFile), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := pybridge.NewBridgeCommand(pybridge.Command{Path: exe, Env: []string{envDir + "=" + dir}})
+	t.Cleanup(b.Close)
+	return &Fake{Bridge: b, dir: dir}
+}
+
+// Requests returns the calls received so far, oldest first.
+func (f *Fake) Requests() []Request {
+	reqs, _ := readRequests(f.dir)
+	return reqs
+}
+
+// CallTool invokes tool's handler the way the MCP server would, failing t
+// on a Go error. Tool errors are returned as results with IsError set.
+func CallTool(t testing.TB, tool server.ServerTool, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	var req mcp.CallToolRequest
+	req.Params.Name = tool.Tool.Name
+	req.Params.Arguments = args
+	res, err := tool.Handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("%s: %v", tool.Tool.Name, err)
+	}
+	return res
+}
+
+// DecodeResult unmarshals the JSON text of res into v, failing t if res is
+// an error or not JSON.
+func DecodeResult(t testing.TB, res *mcp.CallToolResult, v any) {
+	t.Helper()
+	if len(res.Content) == 0 {
+		t.Fatal("empty tool result")
+	}
+	text, ok := res.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("tool result is %T, not text", res.Content[0])
+	}
+	if res.IsError {
+		t.Fatalf("tool error: %s", text.Text)
+	}
+	if err := json.Unmarshal([]byte(text.Text), v); err != nil {
+		t.Fatalf("decode %q: %v", text.Text, err)
+	}
+}
+
+func init() {
+	dir := os.Getenv(envDir)
+	if dir == "" {
+		return
+	}
+	os.Exit(serve(dir, os.Args[1:]))
+}
+
+// serve runs the fake in the re-executed process and returns its exit
+// code.
+func serve(dir string, args []string) int {
+	data, err := os.ReadFile(filepath.Join(dir, scriptFile))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fakesidecar: %v\n", err)
+		return 2
+	}
+	var script Script
+	if err := json.Unmarshal(data, &script); err != nil {
+		fmt.Fprintf(os.Stderr, "fakesidecar: %v\n", err)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("fakesidecar", flag.ContinueOnError)
+	sidecar := fs.Bool("sidecar", false, "")
+	command := fs.String("command", "", "")
+	project := fs.String("project", "", "")
+	argsJSON := fs.String("args", "{}", "")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if !*sidecar {
+		req := Request{Command: *command, Project: *project, SingleShot: true}
+		json.Unmarshal([]byte(*argsJSON), &req.Args)
+		return singleShot(dir, script, req)
+	}
+
+	// Like the Python sidecar, the fake offers length frames and switches
+	// when the bridge selects them.
+	out := bufio.NewWriter(os.Stdout)
+	length := false
+	writeFrame := func(data []byte) {
+		if length {
+			fmt.Fprintf(out, "%d\n", len(data))
+			out.Write(data)
+		} else {
+			out.Write(append(data, '\n'))
+		}
+		out.Flush()
+	}
+	writeLine := func(v any) {
+		data, _ := json.Marshal(v)
+		writeFrame(data)
+	}
+	writeLine(map[string]any{"status": "ready", "framing": []string{"length", "ndjson"}})
+
+	// Requests are read in the background so a delay or hang ends as soon
+	// as the bridge closes stdin, as a real sidecar exits on EOF.
+	lines := make(chan []byte, 64)
+	eof := make(chan struct{})
+	go func() {
+		defer close(eof)
+		in := bufio.NewReader(os.Stdin)
+		inLength := false
+		for {
+			header, err := in.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+			line := bytes.TrimSuffix(header, []byte("\n"))
+			if inLength {
+				n, err := strconv.Atoi(string(line))
+				if err != nil {
+					return
+				}
+				line = make([]byte, n)
+				if _, err := io.ReadFull(in, line); err != nil {
+					return
+				}
+			}
+			var sel struct {
+				Framing string `json:"framing"`
+			}
+			if json.Unmarshal(line, &sel) == nil && sel.Framing == "length" {
+				inLength = true
+			}
+			lines <- line
+		}
+	}()
+	for {
+		var line []byte
+		select {
+		case line = <-lines:
+		case <-eof:
+			return 0
+		}
+		var sel struct {
+			Framing string `json:"framing"`
+		}
+		if json.Unmarshal(line, &sel) == nil && sel.Framing == "length" {
+			length = true
+			writeLine(sel)
+			continue
+		}
+		var req Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			writeLine(map[string]any{"id": nil, "error": map[string]any{"type": "InvalidJSON", "message": err.Error()}})
+			continue
+		}
+		r := next(dir, script, req)
+		select {
+		case <-time.After(r.Delay):
+		case <-eof:
+			return 0
+		}
+		os.Stderr.WriteString(r.Stderr)
+		for _, l := range r.Logs {
+			writeLine(map[string]any{"log": map[string]any{
+				"level": l.Level, "logger": l.Logger, "message": l.Message,
+				"request_id": req.ID, "command": req.Command,
+			}})
+		}
+		switch {
+		case r.Crash:
+			return max(r.ExitCode, 1)
+		case r.Hang:
+			<-eof
+			return 0
+		case r.Raw != "":
+			writeFrame([]byte(r.Raw))
+		default:
+			resp := map[string]any{"id": req.ID}
+			if r.Error != nil {
+				resp["error"] = r.Error
+			} else {
+				resp["result"] = r.Result
+			}
+			if r.Usage != nil {
+				resp["usage"] = r.Usage
+			}
+			writeLine(resp)
+		}
+	}
+}
+
+// singleShot answers one fallback-mode call like `python3 -m intermap
+// --command`: the result on stdout, or an error on stderr with exit 1.
+func singleShot(dir string, script Script, req Request) int {
+	r := next(dir, script, req)
+	time.Sleep(r.Delay)
+	os.Stderr.WriteString(r.Stderr)
+	switch {
+	case r.Crash:
+		return max(r.ExitCode, 1)
+	case r.Hang:
+		// The bridge's timeout kills the process.
+		time.Sleep(time.Hour)
+	case r.Raw != "":
+		fmt.Println(r.Raw)
+	case r.Error != nil:
+		data, _ := json.Marshal(map[string]string{"error": r.Error.Code, "message": r.Error.Message})
+		os.Stderr.Write(data)
+		return 1
+	default:
+		data, _ := json.Marshal(r.Result)
+		fmt.Println(string(data))
+	}
+	return 0
+}
+
+// next records req and picks its scripted response. Earlier calls of the
+// same command are counted from the request log, so the sequence survives
+// sidecar restarts.
+func next(dir string, script Script, req Request) Response {
+	prior, _ := readRequests(dir)
+	f, err := os.OpenFile(filepath.Join(dir, requestsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err == nil {
+		data, _ := json.Marshal(req)
+		f.Write(append(data, '\n'))
+		f.Close()
+	}
+
+	responses, ok := script[req.Command]
+	if !ok {
+		responses, ok = script["*"]
+	}
+	if !ok || len(responses) == 0 {
+		return Response{Error: &Error{
+			Code:        "unscripted",
+			Message:     fmt.Sprintf("fakesidecar: no response scripted for %q", req.Command),
+			Recoverable: true,
+		}}
+	}
+	n := 0
+	for _, p := range prior {
+		if p.Command == req.Command {
+			n++
+		}
+	}
+	return responses[min(n, len(responses)-1)]
+}
+
+func readRequests(dir string) ([]Request, error) {
+	f, err := os.Open(filepath.Join(dir, requestsFile))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var reqs []Request
+	in := bufio.NewScanner(f)
+	in.Buffer(make([]byte, 0, 1<<20), 1<<26)
+	for in.Scan() {
+		var r Request
+		if json.Unmarshal(in.Bytes(), &r) == nil {
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs, in.Err()
+}
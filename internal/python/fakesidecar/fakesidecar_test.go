@@ -0,0 +1,113 @@
+package fakesidecar_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+	"github.com/mistakeknot/intermap/internal/python/fakesidecar"
+)
+
+func TestFake_Run(t *testing.T) {
+	fake := fakesidecar.New(t, fakesidecar.Script{
+		"structure": {{Result: map[string]any{"files": []any{"a.py"}}, Usage: &pybridge.Usage{FilesParsed: 1}}},
+	})
+	var usage pybridge.Usage
+	fake.Bridge.SetUsageHook(func(ctx context.Context, command string, u pybridge.Usage) { usage = u })
+
+	result, err := fake.Bridge.Run(context.Background(), "structure", "/p", map[string]any{"language": "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := result["files"].([]any); len(files) != 1 || usage.FilesParsed != 1 {
+		t.Errorf("result = %v, usage = %+v", result, usage)
+	}
+	reqs := fake.Requests()
+	if len(reqs) != 1 || reqs[0].Project != "/p" || reqs[0].Args["language"] != "go" {
+		t.Errorf("requests = %+v", reqs)
+	}
+
+	_, err = fake.Bridge.Run(context.Background(), "impact", "/p", nil)
+	var re *pybridge.RecoverableError
+	if !errors.As(err, &re) || re.Code != "unscripted" {
+		t.Errorf("unscripted command: %v", err)
+	}
+}
+
+func TestFake_Timeout(t *testing.T) {
+	fake := fakesidecar.New(t, fakesidecar.Script{"structure": {{Hang: true}}})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := fake.Bridge.Run(ctx, "structure", ".", nil); err == nil {
+		t.Fatal("expected a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("timeout took %s", elapsed)
+	}
+}
+
+func TestFake_Cancel(t *testing.T) {
+	fake := fakesidecar.New(t, fakesidecar.Script{"structure": {{Delay: 5 * time.Second}}})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err := fake.Bridge.Run(ctx, "structure", ".", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestFake_LargeResult(t *testing.T) {
+	blob := strings.Repeat("x", 6<<20)
+	fake := fakesidecar.New(t, fakesidecar.Script{"structure": {{Result: map[string]any{"blob": blob}}}})
+	result, err := fake.Bridge.Run(context.Background(), "structure", ".", nil)
+	if err != nil || result["blob"] != blob {
+		t.Errorf("result has %d bytes, err = %v", len(fmt.Sprint(result["blob"])), err)
+	}
+}
+
+func TestFake_MalformedFrame(t *testing.T) {
+	fake := fakesidecar.New(t, fakesidecar.Script{
+		"structure": {{Raw: "{not json"}, {Result: map[string]any{"ok": true}}},
+		"impact":    {{Raw: "garbage"}},
+	})
+	// The bad frame kills the sidecar; the retry on a fresh one succeeds.
+	if result, err := fake.Bridge.Run(context.Background(), "structure", ".", nil); err != nil || result["ok"] != true {
+		t.Errorf("result = %v, err = %v", result, err)
+	}
+	if _, err := fake.Bridge.Run(context.Background(), "impact", ".", nil); err == nil || !strings.Contains(err.Error(), "parse sidecar response") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestFake_CrashRecovery(t *testing.T) {
+	fake := fakesidecar.New(t, fakesidecar.Script{
+		"structure": {
+			{Crash: true, Stderr: "Traceback (most recent call last):\nMemoryError\n"},
+			{Result: map[string]any{"ok": true}},
+			{Crash: true, Stderr: "Segmentation fault\n"},
+			{Crash: true, Stderr: "Segmentation fault\n"},
+			{Crash: true},
+			{Result: map[string]any{"ok": "single-shot"}},
+		},
+	})
+	ctx := context.Background()
+	if result, err := fake.Bridge.Run(ctx, "structure", ".", nil); err != nil || result["ok"] != true {
+		t.Fatalf("respawn: result = %v, err = %v", result, err)
+	}
+	_, err := fake.Bridge.Run(ctx, "structure", ".", nil)
+	if err == nil || !strings.Contains(err.Error(), "Segmentation fault") {
+		t.Fatalf("err = %v, want the sidecar's stderr", err)
+	}
+	// The third respawn in 10s switches the bridge to single-shot mode.
+	result, err := fake.Bridge.Run(ctx, "structure", ".", nil)
+	if err != nil || result["ok"] != "single-shot" {
+		t.Fatalf("fallback: result = %v, err = %v", result, err)
+	}
+	if reqs := fake.Requests(); !reqs[len(reqs)-1].SingleShot {
+		t.Error("last request was not single-shot")
+	}
+}
@@ -0,0 +1,166 @@
+package python
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Priority is the scheduling class of a sidecar command. The sidecar runs
+// one command at a time, so when several are waiting the bridge picks the
+// next by class: higher classes go first in proportion to their weights,
+// and lower classes still make progress.
+type Priority int
+
+const (
+	Background Priority = iota
+	Normal
+	Interactive
+	numPriorities
+)
+
+var priorityNames = [numPriorities]string{"background", "normal", "interactive"}
+
+func (p Priority) String() string {
+	if p < 0 || p >= numPriorities {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// ParsePriority parses "background", "normal", or "interactive".
+func ParsePriority(s string) (Priority, error) {
+	for p, name := range priorityNames {
+		if s == name {
+			return Priority(p), nil
+		}
+	}
+	return Normal, fmt.Errorf("unknown priority %q (want background, normal, or interactive)", s)
+}
+
+// DefaultWeights are the shares of sidecar turns each class gets while all
+// three have commands waiting.
+var DefaultWeights = map[Priority]int{Interactive: 8, Normal: 4, Background: 1}
+
+type priorityKey struct{}
+
+// WithPriority returns ctx carrying p for Run to schedule by.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFrom returns the priority carried by ctx, or Normal.
+func PriorityFrom(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok && p >= 0 && p < numPriorities {
+		return p
+	}
+	return Normal
+}
+
+// scheduler hands the sidecar to one command at a time using stride
+// scheduling: each class has a pass value that advances by 1/weight each
+// time it is served, and the waiting class with the lowest pass goes next.
+// A class that was idle rejoins at the current pass rather than with the
+// credit it would have built up.
+type scheduler struct {
+	mu      sync.Mutex
+	busy    bool
+	waiters [numPriorities][]*waiter
+	weights [numPriorities]int
+	pass    [numPriorities]float64
+	now     float64 // pass of the class served last
+}
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+func newScheduler() *scheduler {
+	s := &scheduler{}
+	s.setWeights(DefaultWeights)
+	return s
+}
+
+// setWeights replaces the class weights; missing or non-positive weights
+// keep their defaults.
+func (s *scheduler) setWeights(w map[Priority]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for p := range numPriorities {
+		s.weights[p] = DefaultWeights[p]
+		if w[p] > 0 {
+			s.weights[p] = w[p]
+		}
+	}
+}
+
+// acquire waits until the caller may use the sidecar.
+func (s *scheduler) acquire(ctx context.Context, p Priority) error {
+	s.mu.Lock()
+	if !s.busy {
+		s.busy = true
+		s.serve(p)
+		s.mu.Unlock()
+		return nil
+	}
+	w := &waiter{ready: make(chan struct{})}
+	if len(s.waiters[p]) == 0 {
+		s.pass[p] = max(s.pass[p], s.now)
+	}
+	s.waiters[p] = append(s.waiters[p], w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if w.granted {
+			// Handed the sidecar as we gave up; pass it on.
+			s.releaseLocked()
+		} else {
+			q := s.waiters[p]
+			for i := range q {
+				if q[i] == w {
+					s.waiters[p] = append(q[:i], q[i+1:]...)
+					break
+				}
+			}
+		}
+		return ctx.Err()
+	}
+}
+
+// release hands the sidecar to the next waiter.
+func (s *scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releaseLocked()
+}
+
+func (s *scheduler) releaseLocked() {
+	next := Priority(-1)
+	for p := range numPriorities {
+		if len(s.waiters[p]) > 0 && (next < 0 || s.pass[p] < s.pass[next] || s.pass[p] == s.pass[next] && p > next) {
+			next = p
+		}
+	}
+	if next < 0 {
+		s.busy = false
+		return
+	}
+	w := s.waiters[next][0]
+	s.waiters[next] = s.waiters[next][1:]
+	s.serve(next)
+	w.granted = true
+	close(w.ready)
+}
+
+// serve charges class p one turn.
+func (s *scheduler) serve(p Priority) {
+	s.pass[p] = max(s.pass[p], s.now)
+	s.now = s.pass[p]
+	s.pass[p] += 1 / float64(s.weights[p])
+}
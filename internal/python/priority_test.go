@@ -0,0 +1,109 @@
+package python
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// queue adds n waiters of class p directly, as acquire would while busy.
+func (s *scheduler) queue(p Priority, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for range n {
+		if len(s.waiters[p]) == 0 {
+			s.pass[p] = max(s.pass[p], s.now)
+		}
+		s.waiters[p] = append(s.waiters[p], &waiter{ready: make(chan struct{})})
+	}
+}
+
+// next releases the sidecar and reports which class got it.
+func (s *scheduler) next() Priority {
+	s.mu.Lock()
+	before := [numPriorities]int{}
+	for p := range numPriorities {
+		before[p] = len(s.waiters[p])
+	}
+	s.releaseLocked()
+	defer s.mu.Unlock()
+	for p := range numPriorities {
+		if len(s.waiters[p]) < before[p] {
+			return p
+		}
+	}
+	return -1
+}
+
+func TestScheduler_Weights(t *testing.T) {
+	s := newScheduler()
+	if err := s.acquire(context.Background(), Normal); err != nil {
+		t.Fatal(err)
+	}
+	s.queue(Background, 100)
+	s.queue(Interactive, 100)
+
+	served := map[Priority]int{}
+	for range 90 {
+		served[s.next()]++
+	}
+	// 8:1 shares over 90 turns.
+	if served[Interactive] != 80 || served[Background] != 10 {
+		t.Errorf("served = %v", served)
+	}
+
+	// A class that was idle does not get a burst for the turns it missed.
+	s.queue(Normal, 10)
+	served = map[Priority]int{}
+	for range 13 {
+		served[s.next()]++
+	}
+	if served[Normal] > 5 {
+		t.Errorf("idle class burst: %v", served)
+	}
+}
+
+func TestScheduler_Cancel(t *testing.T) {
+	s := newScheduler()
+	s.acquire(context.Background(), Normal)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.acquire(ctx, Interactive); err == nil {
+		t.Fatal("acquire succeeded while busy")
+	}
+	done := make(chan struct{})
+	go func() {
+		s.acquire(context.Background(), Background)
+		close(done)
+	}()
+	for {
+		s.mu.Lock()
+		n := len(s.waiters[Background])
+		s.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.release()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("cancelled waiter still queued ahead of background")
+	}
+	if len(s.waiters[Interactive]) != 0 {
+		t.Error("cancelled waiter left in queue")
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for _, p := range []Priority{Background, Normal, Interactive} {
+		if got, err := ParsePriority(p.String()); err != nil || got != p {
+			t.Errorf("ParsePriority(%q) = %v, %v", p, got, err)
+		}
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Error("ParsePriority accepted an unknown class")
+	}
+}
@@ -0,0 +1,89 @@
+package python
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	// stderrTailLines is how many trailing sidecar stderr lines are kept
+	// for error messages.
+	stderrTailLines = 40
+	// stderrMaxLine truncates long lines so the tail stays small.
+	stderrMaxLine = 1024
+)
+
+// stderrTail forwards a sidecar's stderr to out while keeping its last
+// lines in a ring buffer, so a crash can be reported with the traceback
+// that preceded it.
+type stderrTail struct {
+	out io.Writer
+
+	mu      sync.Mutex
+	lines   [stderrTailLines]string
+	next    int // ring index of the next line
+	n       int // lines held
+	partial []byte
+}
+
+func newStderrTail(out io.Writer) *stderrTail {
+	return &stderrTail{out: out}
+}
+
+func (t *stderrTail) Write(p []byte) (int, error) {
+	t.out.Write(p)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	data := p
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			if len(t.partial) < stderrMaxLine {
+				t.partial = append(t.partial, data[:min(len(data), stderrMaxLine-len(t.partial))]...)
+			}
+			return len(p), nil
+		}
+		if len(t.partial) < stderrMaxLine {
+			t.partial = append(t.partial, data[:min(i, stderrMaxLine-len(t.partial))]...)
+		}
+		t.push(string(t.partial))
+		t.partial = t.partial[:0]
+		data = data[i+1:]
+	}
+}
+
+func (t *stderrTail) push(line string) {
+	t.lines[t.next] = line
+	t.next = (t.next + 1) % stderrTailLines
+	t.n = min(t.n+1, stderrTailLines)
+}
+
+// String returns the kept lines, oldest first, including an unterminated
+// last line.
+func (t *stderrTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]string, 0, t.n+1)
+	for i := range t.n {
+		out = append(out, t.lines[(t.next-t.n+i+stderrTailLines)%stderrTailLines])
+	}
+	if len(t.partial) > 0 {
+		out = append(out, string(t.partial))
+	}
+	return strings.Join(out, "\n")
+}
+
+// wrap appends the kept stderr to err, if there is any.
+func (t *stderrTail) wrap(err error) error {
+	if t == nil {
+		return err
+	}
+	tail := strings.TrimSpace(t.String())
+	if tail == "" {
+		return err
+	}
+	return fmt.Errorf("%w\nsidecar stderr:\n%s", err, tail)
+}
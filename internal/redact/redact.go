@@ -0,0 +1,153 @@
+// Package redact rewrites absolute paths in tool results so transcripts do
+// not leak the user's directory layout. Paths under the workspace root
+// become root-relative, and other paths under the home directory start
+// with "~".
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Modes for INTERMAP_REDACT_PATHS.
+const (
+	ModeOff      = "off"      // leave results untouched
+	ModeRelative = "relative" // rewrite absolute paths
+	// ModeBoth rewrites paths and, for object fields, keeps the original
+	// value in a sibling "<field>_abs" key.
+	ModeBoth = "both"
+)
+
+// Redactor rewrites paths in JSON results. The nil Redactor and ModeOff
+// leave results unchanged.
+type Redactor struct {
+	mode string
+	root string
+	home string
+}
+
+// New returns a Redactor for mode, relativizing to root. Unknown modes are
+// treated as ModeRelative; an empty root disables root-relative rewriting.
+func New(mode, root string) *Redactor {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", "0", "false", ModeOff:
+		return &Redactor{mode: ModeOff}
+	case ModeBoth:
+		mode = ModeBoth
+	default:
+		mode = ModeRelative
+	}
+	r := &Redactor{mode: mode}
+	if root != "" {
+		if abs, err := filepath.Abs(root); err == nil && abs != string(filepath.Separator) {
+			r.root = abs
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != string(filepath.Separator) {
+		r.home = filepath.Clean(home)
+	}
+	return r
+}
+
+// FromEnv builds a Redactor from INTERMAP_REDACT_PATHS, relativizing to
+// INTERMAP_WORKSPACE_ROOT or, if unset, the working directory.
+func FromEnv() *Redactor {
+	root := os.Getenv("INTERMAP_WORKSPACE_ROOT")
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+	return New(os.Getenv("INTERMAP_REDACT_PATHS"), root)
+}
+
+// Enabled reports whether r rewrites anything.
+func (r *Redactor) Enabled() bool {
+	return r != nil && r.mode != ModeOff
+}
+
+// Mode returns the active mode.
+func (r *Redactor) Mode() string {
+	if r == nil {
+		return ModeOff
+	}
+	return r.mode
+}
+
+// JSON rewrites paths in an encoded JSON document. Input that does not
+// decode is returned unchanged.
+func (r *Redactor) JSON(data []byte) []byte {
+	if !r.Enabled() {
+		return data
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return data
+	}
+	out, err := json.Marshal(r.value(v))
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+func (r *Redactor) value(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.String(v)
+	case []any:
+		for i := range v {
+			v[i] = r.value(v[i])
+		}
+		return v
+	case map[string]any:
+		// Collect first: adding _abs keys while ranging would revisit them.
+		abs := make(map[string]string)
+		for k, item := range v {
+			if s, ok := item.(string); ok {
+				if rs := r.String(s); rs != s {
+					v[k] = rs
+					abs[k+"_abs"] = s
+				}
+				continue
+			}
+			v[k] = r.value(item)
+		}
+		if r.mode == ModeBoth {
+			for k, s := range abs {
+				if _, taken := v[k]; !taken {
+					v[k] = s
+				}
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// String rewrites the absolute paths in s: the workspace root itself becomes
+// ".", paths under it lose the root prefix, and remaining paths under the
+// home directory start with "~".
+func (r *Redactor) String(s string) string {
+	if !r.Enabled() {
+		return s
+	}
+	sep := string(filepath.Separator)
+	if r.root != "" {
+		if s == r.root {
+			return "."
+		}
+		s = strings.ReplaceAll(s, r.root+sep, "")
+	}
+	if r.home != "" {
+		if s == r.home {
+			return "~"
+		}
+		s = strings.ReplaceAll(s, r.home+sep, "~"+sep)
+	}
+	return s
+}
@@ -0,0 +1,64 @@
+package redact
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	t.Setenv("HOME", "/home/ada")
+	r := New(ModeRelative, "/home/ada/ws")
+
+	for in, want := range map[string]string{
+		"/home/ada/ws":                   ".",
+		"/home/ada/ws/core/api/main.go":  "core/api/main.go",
+		"scan /home/ada/ws/core: denied": "scan core: denied",
+		"/home/ada/other/x.go":           "~/other/x.go",
+		"/home/ada/wsx/y.go":             "~/wsx/y.go",
+		"/home/ada":                      "~",
+		"/opt/tools/bin":                 "/opt/tools/bin",
+		"internal/tools/tools.go":        "internal/tools/tools.go",
+	} {
+		if got := r.String(in); got != want {
+			t.Errorf("String(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	t.Setenv("HOME", "/home/ada")
+	doc := []byte(`{"path":"/home/ada/ws/core/api","count":3,"ratio":0.25,"files":["/home/ada/ws/a.go"],"nested":{"root":"/home/ada/ws"}}`)
+
+	var got map[string]any
+	if err := json.Unmarshal(New(ModeRelative, "/home/ada/ws").JSON(doc), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["path"] != "core/api" || got["files"].([]any)[0] != "a.go" || got["nested"].(map[string]any)["root"] != "." {
+		t.Errorf("relative: %v", got)
+	}
+	if got["count"] != 3.0 || got["ratio"] != 0.25 {
+		t.Errorf("numbers changed: %v", got)
+	}
+	if _, ok := got["path_abs"]; ok {
+		t.Error("relative mode should not add _abs keys")
+	}
+
+	got = nil
+	if err := json.Unmarshal(New(ModeBoth, "/home/ada/ws").JSON(doc), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["path"] != "core/api" || got["path_abs"] != "/home/ada/ws/core/api" {
+		t.Errorf("both: %v", got)
+	}
+	if got["nested"].(map[string]any)["root_abs"] != "/home/ada/ws" {
+		t.Errorf("both nested: %v", got["nested"])
+	}
+
+	if out := New(ModeOff, "/home/ada/ws").JSON(doc); string(out) != string(doc) {
+		t.Errorf("off mode changed output: %s", out)
+	}
+	var nilR *Redactor
+	if out := nilR.JSON(doc); string(out) != string(doc) {
+		t.Error("nil redactor changed output")
+	}
+}
@@ -0,0 +1,228 @@
+// Package report renders the workspace map as a single static HTML page:
+// project cards, the dependency graph, change hotspots, and a snapshot of
+// the agent overlay, for people who won't use the MCP tools. The page has
+// no external assets; the graph is inline SVG laid out here, and its
+// Mermaid source is included for pasting into docs.
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Report is everything the page shows.
+type Report struct {
+	Title     string
+	Root      string
+	Generated time.Time
+	Projects  []Project
+	Deps      []Dep
+	// Since is the git window Hotspots cover, e.g. "30 days ago".
+	Since    string
+	Hotspots []Hotspot
+	// Coordination reports whether a coordination provider was reachable
+	// for the agent overlay.
+	Coordination bool
+	Agents       []Agent
+}
+
+// Project is one project card.
+type Project struct {
+	Name     string
+	Group    string
+	Language string
+	Path     string
+	Branch   string
+	// DependsOn and Dependents count distinct projects on either end of
+	// its dependency edges.
+	DependsOn  int
+	Dependents int
+	// Commits is the project's commit count within Since.
+	Commits int
+	Symbols []string
+	Agents  []string
+}
+
+// Dep is a dependency edge: From depends on To.
+type Dep struct {
+	From string
+	To   string
+	Type string
+	Via  string
+}
+
+// Hotspot is a file changed often within Since.
+type Hotspot struct {
+	Project string
+	File    string
+	Commits int
+}
+
+// Agent is an agent in the overlay snapshot.
+type Agent struct {
+	Name         string
+	Status       string
+	LastSeen     string
+	Project      string
+	Reservations []string
+}
+
+//go:embed report.html.tmpl
+var pageSource string
+
+var page = template.Must(template.New("report").Parse(pageSource))
+
+// Render writes r as an HTML page.
+func Render(w io.Writer, r *Report) error {
+	layout := Layout(r.Projects, r.Deps)
+	return page.Execute(w, struct {
+		*Report
+		Graph   Graph
+		Mermaid string
+	}{r, layout, Mermaid(r.Projects, r.Deps)})
+}
+
+// Graph is the dependency graph laid out for SVG.
+type Graph struct {
+	Width, Height         int
+	NodeWidth, NodeHeight int
+	// TextY is the baseline of a label within its box.
+	TextY int
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// GraphNode is a project box at X, Y (its top left corner).
+type GraphNode struct {
+	Name     string
+	Language string
+	X, Y     int
+}
+
+// GraphEdge is a dependency drawn as the SVG path D.
+type GraphEdge struct {
+	From, To string
+	D        string
+}
+
+const (
+	nodeWidth  = 160
+	nodeHeight = 28
+	colGap     = 90
+	rowGap     = 14
+	margin     = 10
+)
+
+// Layout places projects in columns by dependency depth: projects nothing
+// depends on at the left, and each project left of everything it depends
+// on. Edges closing a cycle are drawn but do not affect depth.
+func Layout(projects []Project, deps []Dep) Graph {
+	known := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		known[p.Name] = true
+	}
+	out := map[string][]string{}
+	for _, d := range deps {
+		if known[d.From] && known[d.To] && d.From != d.To && !slices.Contains(out[d.From], d.To) {
+			out[d.From] = append(out[d.From], d.To)
+		}
+	}
+
+	// height is the longest dependency chain below a project.
+	height := map[string]int{}
+	visiting := map[string]bool{}
+	var visit func(string) int
+	visit = func(name string) int {
+		if h, ok := height[name]; ok {
+			return h
+		}
+		if visiting[name] {
+			return 0
+		}
+		visiting[name] = true
+		h := 0
+		for _, to := range out[name] {
+			h = max(h, visit(to)+1)
+		}
+		visiting[name] = false
+		height[name] = h
+		return h
+	}
+	maxHeight := 0
+	for _, p := range projects {
+		maxHeight = max(maxHeight, visit(p.Name))
+	}
+
+	columns := make([][]Project, maxHeight+1)
+	for _, p := range projects {
+		col := maxHeight - height[p.Name]
+		columns[col] = append(columns[col], p)
+	}
+	g := Graph{NodeWidth: nodeWidth, NodeHeight: nodeHeight, TextY: nodeHeight / 2}
+	pos := map[string]GraphNode{}
+	rows := 0
+	for col, ps := range columns {
+		slices.SortFunc(ps, func(a, b Project) int { return strings.Compare(a.Name, b.Name) })
+		for row, p := range ps {
+			n := GraphNode{
+				Name:     p.Name,
+				Language: p.Language,
+				X:        margin + col*(nodeWidth+colGap),
+				Y:        margin + row*(nodeHeight+rowGap),
+			}
+			pos[p.Name] = n
+			g.Nodes = append(g.Nodes, n)
+		}
+		rows = max(rows, len(ps))
+	}
+	for _, p := range projects {
+		for _, to := range out[p.Name] {
+			a, b := pos[p.Name], pos[to]
+			x1, y1 := a.X+nodeWidth, a.Y+nodeHeight/2
+			x2, y2 := b.X, b.Y+nodeHeight/2
+			if b.X <= a.X {
+				// A cycle edge runs back left; leave from the box's left side.
+				x1 = a.X
+				x2 = b.X + nodeWidth
+			}
+			mid := (x1 + x2) / 2
+			g.Edges = append(g.Edges, GraphEdge{
+				From: p.Name,
+				To:   to,
+				D:    fmt.Sprintf("M%d,%d C%d,%d %d,%d %d,%d", x1, y1, mid, y1, mid, y2, x2, y2),
+			})
+		}
+	}
+	g.Width = 2*margin + len(columns)*nodeWidth + max(len(columns)-1, 0)*colGap
+	g.Height = 2*margin + rows*nodeHeight + max(rows-1, 0)*rowGap
+	return g
+}
+
+// Mermaid renders the dependency graph as a Mermaid flowchart.
+func Mermaid(projects []Project, deps []Dep) string {
+	ids := make(map[string]string, len(projects))
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, p := range projects {
+		ids[p.Name] = fmt.Sprintf("p%d", i)
+		fmt.Fprintf(&b, "  p%d[%q]\n", i, p.Name)
+	}
+	for _, d := range deps {
+		from, ok1 := ids[d.From]
+		to, ok2 := ids[d.To]
+		if !ok1 || !ok2 {
+			continue
+		}
+		if d.Type != "" {
+			fmt.Fprintf(&b, "  %s -->|%s| %s\n", from, d.Type, to)
+		} else {
+			fmt.Fprintf(&b, "  %s --> %s\n", from, to)
+		}
+	}
+	return b.String()
+}
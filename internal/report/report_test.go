@@ -0,0 +1,76 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLayout(t *testing.T) {
+	projects := []Project{{Name: "app"}, {Name: "lib"}, {Name: "core"}, {Name: "tool"}}
+	deps := []Dep{
+		{From: "app", To: "lib"},
+		{From: "lib", To: "core"},
+		{From: "app", To: "core"},
+		{From: "tool", To: "core"},
+		{From: "core", To: "lib"}, // closes a cycle
+		{From: "app", To: "missing"},
+	}
+	g := Layout(projects, deps)
+	x := map[string]int{}
+	for _, n := range g.Nodes {
+		x[n.Name] = n.X
+	}
+	if !(x["app"] < x["lib"] && x["tool"] < x["core"]) {
+		t.Errorf("dependents should sit left of their dependencies: %v", x)
+	}
+	if len(g.Edges) != 5 {
+		t.Errorf("edges = %d, want 5 (the unknown project dropped)", len(g.Edges))
+	}
+	for _, n := range g.Nodes {
+		if n.X+g.NodeWidth > g.Width || n.Y+g.NodeHeight > g.Height {
+			t.Errorf("%s at (%d,%d) outside %dx%d", n.Name, n.X, n.Y, g.Width, g.Height)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	r := &Report{
+		Title:    "Map",
+		Root:     "/ws",
+		Projects: []Project{{Name: "web", Language: "go", Symbols: []string{"<Handler>"}}, {Name: "api"}},
+		Deps:     []Dep{{From: "web", To: "api", Type: "go_module"}},
+		Since:    "30 days ago",
+		Hotspots: []Hotspot{{Project: "web", File: "main.go", Commits: 4}},
+	}
+	var buf bytes.Buffer
+	if err := Render(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	for _, want := range []string{
+		`data-project="web"`,
+		`data-from="web" data-to="api"`,
+		"&lt;Handler&gt;",
+		"p0 --&gt;|go_module| p1",
+		`<td>main.go</td><td class="num">4</td>`,
+		"No coordination provider was reachable",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %q", want)
+		}
+	}
+	if strings.Contains(page, "<Handler>") {
+		t.Error("symbol not escaped")
+	}
+
+	r.Coordination = true
+	r.Agents = []Agent{{Name: "builder", Project: "web", Reservations: []string{"a/**", "b/**"}}}
+	buf.Reset()
+	if err := Render(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "<td>builder</td>") || !strings.Contains(buf.String(), "a/**, b/**") {
+		t.Error("agent overlay missing")
+	}
+}
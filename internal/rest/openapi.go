@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mistakeknot/intermap/internal/tools"
+)
+
+// OpenAPIPath is where the rendered OpenAPI document lives, relative to
+// the repository root.
+const OpenAPIPath = "openapi/intermap.json"
+
+// OpenAPI renders the API as an OpenAPI 3.1 document derived from
+// tools.Specs, covering the tools keep reports true for; a nil keep
+// covers every tool. Tool schemas are JSON Schema, so they are used as is,
+// including output schemas for the tools that publish one.
+func OpenAPI(keep func(name string) bool) []byte {
+	paths := map[string]any{}
+	byName := map[string]mcp.Tool{}
+	for _, s := range tools.Specs {
+		if keep != nil && !keep(s.Name) {
+			continue
+		}
+		tool := s.New(tools.Deps{}).Tool
+		byName[s.Name] = tool
+		paths["/tools/"+s.Name] = map[string]any{
+			"post": operation(s.Name, s.Summary, tool.Description, string(s.Cluster), http.MethodPost, tool),
+		}
+	}
+	for _, rt := range Routes {
+		tool, ok := byName[rt.Tool]
+		if !ok {
+			continue
+		}
+		desc := "Shortcut for POST /tools/" + rt.Tool + "."
+		paths[rt.Path] = map[string]any{
+			strings.ToLower(rt.Method): operation(strings.TrimPrefix(rt.Path, "/"), "", desc, "shortcuts", rt.Method, tool),
+		}
+	}
+	doc := map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "intermap",
+			"version":     "1",
+			"description": "The intermap MCP tools as a JSON API. Each tool takes its MCP arguments and returns its JSON result; errors are structured tool errors.",
+		},
+		"security": []any{map[string]any{}, map[string]any{"bearer": []any{}}},
+		"paths":    paths,
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer", "description": "An http.keys API key, when any are configured"},
+			},
+			"schemas": map[string]any{
+				"Error": map[string]any{
+					"type":     "object",
+					"required": []string{"type", "message", "recoverable"},
+					"properties": map[string]any{
+						"type":        map[string]any{"type": "string", "enum": []string{"VALIDATION", "PERMISSION", "NOT_FOUND", "CONFLICT", "TRANSIENT", "INTERNAL"}},
+						"message":     map[string]any{"type": "string"},
+						"recoverable": map[string]any{"type": "boolean"},
+						"data":        map[string]any{"type": "object"},
+					},
+				},
+			},
+		},
+	}
+	b, _ := json.MarshalIndent(doc, "", "  ")
+	return append(b, '\n')
+}
+
+// operation describes one call of tool, with its arguments as query
+// parameters for GET and a JSON body otherwise.
+func operation(id, summary, desc, tag, method string, tool mcp.Tool) map[string]any {
+	schema := tool.InputSchema
+	var result any = map[string]any{}
+	if tool.RawOutputSchema != nil {
+		result = tool.RawOutputSchema
+	}
+	op := map[string]any{
+		"operationId": id,
+		"description": desc,
+		"tags":        []string{tag},
+		"responses": map[string]any{
+			"200": map[string]any{
+				"description": "The tool's JSON result",
+				"content":     map[string]any{"application/json": map[string]any{"schema": result}},
+			},
+			"default": map[string]any{
+				"description": "A tool error; the status follows its type",
+				"content": map[string]any{"application/json": map[string]any{
+					"schema": map[string]any{"$ref": "#/components/schemas/Error"},
+				}},
+			},
+		},
+	}
+	if summary != "" {
+		op["summary"] = summary
+	}
+	if method != http.MethodGet {
+		body := map[string]any{"type": "object", "properties": schema.Properties}
+		if len(schema.Required) > 0 {
+			body["required"] = schema.Required
+		}
+		op["requestBody"] = map[string]any{
+			"content": map[string]any{"application/json": map[string]any{"schema": body}},
+		}
+		return op
+	}
+	params := []any{}
+	for _, name := range slices.Sorted(maps.Keys(schema.Properties)) {
+		prop, _ := schema.Properties[name].(map[string]any)
+		param := map[string]any{
+			"name":     name,
+			"in":       "query",
+			"required": slices.Contains(schema.Required, name),
+			"schema":   prop,
+		}
+		if desc, _ := prop["description"].(string); desc != "" {
+			param["description"] = desc
+		}
+		params = append(params, param)
+	}
+	op["parameters"] = params
+	return op
+}
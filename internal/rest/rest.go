@@ -0,0 +1,223 @@
+// Package rest serves the MCP tools as a plain JSON API, for dashboards and
+// scripts that don't speak MCP. Calls go through the registered tools, so
+// the middleware pipeline (validation, scope, cache, and the rest) applies
+// as it does over MCP.
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/toolerror"
+	"github.com/mistakeknot/intermap/internal/access"
+)
+
+// Route is a shortcut path for one tool. GET routes take arguments as
+// query parameters; POST routes take a JSON object body.
+type Route struct {
+	Method string
+	Path   string
+	Tool   string
+}
+
+// Routes are the shortcut paths. Every tool is also served at
+// POST /tools/{name}.
+var Routes = []Route{
+	{Method: http.MethodGet, Path: "/projects", Tool: "project_registry"},
+	{Method: http.MethodPost, Path: "/impact", Tool: "impact_analysis"},
+	{Method: http.MethodGet, Path: "/deps", Tool: "cross_project_deps"},
+}
+
+// maxBody bounds a POST body.
+const maxBody = 1 << 20
+
+// session is the MCP session a call runs in, so usage, budgets, and rate
+// limits are charged per caller as they are over MCP. Callers are told
+// apart by API key: "rest:<key>", or "rest:<key>/<agent>" for intermute
+// keys. Without keys every call is in session "rest". It takes no
+// notifications.
+type session string
+
+func (s session) Initialize()                                         {}
+func (s session) Initialized() bool                                   { return false }
+func (s session) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s session) SessionID() string                                   { return string(s) }
+
+// sessionFor returns the session of r's caller.
+func sessionFor(r *http.Request) session {
+	if id := access.Identity(r.Context()); id != "" {
+		return session("rest:" + id)
+	}
+	return "rest"
+}
+
+// Handler returns the API over s's tools, including the OpenAPI document
+// at /openapi.json.
+func Handler(s *server.MCPServer) http.Handler {
+	mux := http.NewServeMux()
+	for _, rt := range Routes {
+		mux.HandleFunc(rt.Method+" "+rt.Path, func(w http.ResponseWriter, r *http.Request) {
+			call(w, r, s, rt.Tool)
+		})
+	}
+	mux.HandleFunc("POST /tools/{name}", func(w http.ResponseWriter, r *http.Request) {
+		call(w, r, s, r.PathValue("name"))
+	})
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(OpenAPI(func(name string) bool { return s.GetTool(name) != nil }))
+	})
+	return mux
+}
+
+// call runs tool with r's arguments and writes its result.
+func call(w http.ResponseWriter, r *http.Request, s *server.MCPServer, tool string) {
+	t := s.GetTool(tool)
+	if t == nil {
+		writeError(w, toolerror.New(toolerror.ErrNotFound, "unknown tool %s", tool))
+		return
+	}
+	var args map[string]any
+	var err error
+	if r.Method == http.MethodGet {
+		args, err = queryArgs(r.URL.Query(), t.Tool.InputSchema)
+	} else {
+		args, err = bodyArgs(r.Body)
+	}
+	if err != nil {
+		writeError(w, toolerror.New(toolerror.ErrValidation, "%v", err))
+		return
+	}
+	req := mcp.CallToolRequest{}
+	req.Params.Name = tool
+	req.Params.Arguments = args
+	res, err := t.Handler(s.WithContext(r.Context(), sessionFor(r)), req)
+	if err != nil {
+		writeError(w, toolerror.Wrap(err))
+		return
+	}
+	text := ""
+	if res != nil && len(res.Content) > 0 {
+		if tc, ok := res.Content[0].(mcp.TextContent); ok {
+			text = tc.Text
+		}
+	}
+	if res != nil && res.IsError {
+		var te toolerror.ToolError
+		if json.Unmarshal([]byte(text), &te) != nil || te.Type == "" {
+			te = *toolerror.New(toolerror.ErrInternal, "%s", text)
+		}
+		writeError(w, &te)
+		return
+	}
+	if !json.Valid([]byte(text)) {
+		b, _ := json.Marshal(map[string]string{"text": text})
+		text = string(b)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, text)
+}
+
+// bodyArgs decodes a JSON object body; an empty body is no arguments.
+func bodyArgs(body io.Reader) (map[string]any, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxBody+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading body: %w", err)
+	}
+	if len(data) > maxBody {
+		return nil, fmt.Errorf("body exceeds %d bytes", maxBody)
+	}
+	args := map[string]any{}
+	if strings.TrimSpace(string(data)) == "" {
+		return args, nil
+	}
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, fmt.Errorf("body must be a JSON object of arguments: %w", err)
+	}
+	return args, nil
+}
+
+// queryArgs converts query parameters to arguments of the types schema
+// declares. Array arguments take repeated or comma-separated values;
+// object arguments take JSON.
+func queryArgs(q url.Values, schema mcp.ToolInputSchema) (map[string]any, error) {
+	args := make(map[string]any, len(q))
+	for name, values := range q {
+		prop, ok := schema.Properties[name].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unknown argument %s", name)
+		}
+		typ, _ := prop["type"].(string)
+		if typ == "array" {
+			items, _ := prop["items"].(map[string]any)
+			itemType, _ := items["type"].(string)
+			list := []any{}
+			for _, v := range values {
+				for _, part := range strings.Split(v, ",") {
+					if part = strings.TrimSpace(part); part == "" {
+						continue
+					}
+					item, err := queryValue(itemType, part)
+					if err != nil {
+						return nil, fmt.Errorf("argument %s: %w", name, err)
+					}
+					list = append(list, item)
+				}
+			}
+			args[name] = list
+			continue
+		}
+		if len(values) > 1 {
+			return nil, fmt.Errorf("argument %s given %d times", name, len(values))
+		}
+		v, err := queryValue(typ, values[0])
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", name, err)
+		}
+		args[name] = v
+	}
+	return args, nil
+}
+
+func queryValue(typ, s string) (any, error) {
+	switch typ {
+	case "number", "integer":
+		return strconv.ParseFloat(s, 64)
+	case "boolean":
+		return strconv.ParseBool(s)
+	case "object":
+		var v map[string]any
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, fmt.Errorf("want a JSON object: %w", err)
+		}
+		return v, nil
+	}
+	return s, nil
+}
+
+// statuses maps tool error types to HTTP statuses.
+var statuses = map[string]int{
+	toolerror.ErrValidation: http.StatusBadRequest,
+	toolerror.ErrPermission: http.StatusForbidden,
+	toolerror.ErrNotFound:   http.StatusNotFound,
+	toolerror.ErrConflict:   http.StatusConflict,
+	toolerror.ErrTransient:  http.StatusServiceUnavailable,
+}
+
+// writeError writes te as JSON with the status for its type.
+func writeError(w http.ResponseWriter, te *toolerror.ToolError) {
+	status, ok := statuses[te.Type]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	io.WriteString(w, te.JSON())
+}
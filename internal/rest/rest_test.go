@@ -0,0 +1,142 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/access"
+)
+
+var update = flag.Bool("update", false, "rewrite the OpenAPI document")
+
+func TestHandler(t *testing.T) {
+	s := server.NewMCPServer("test", "0")
+	echo := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+		if args["fail"] == true {
+			return mcputil.NotFoundError("no project %v", args["name"])
+		}
+		b, _ := json.Marshal(map[string]any{"tool": req.Params.Name, "args": args})
+		return mcp.NewToolResultText(string(b)), nil
+	}
+	s.AddTool(mcp.NewTool("project_registry",
+		mcp.WithString("name"),
+		mcp.WithNumber("limit"),
+		mcp.WithBoolean("fail"),
+		mcp.WithArray("fields", mcp.WithStringItems()),
+	), echo)
+	s.AddTool(mcp.NewTool("impact_analysis", mcp.WithString("target")), echo)
+	ts := httptest.NewServer(Handler(s))
+	defer ts.Close()
+
+	do := func(method, path, body string) (int, map[string]any) {
+		t.Helper()
+		req, _ := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var out map[string]any
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	code, out := do("GET", "/projects?name=web&limit=3&fields=name,path&fields=language", "")
+	args, _ := out["args"].(map[string]any)
+	if code != 200 || out["tool"] != "project_registry" || args["name"] != "web" || args["limit"] != 3.0 ||
+		len(args["fields"].([]any)) != 3 {
+		t.Errorf("GET /projects = %d %v", code, out)
+	}
+	if code, out = do("POST", "/impact", `{"target": "parse"}`); code != 200 || out["args"].(map[string]any)["target"] != "parse" {
+		t.Errorf("POST /impact = %d %v", code, out)
+	}
+	if code, out = do("POST", "/tools/project_registry", ""); code != 200 || out["tool"] != "project_registry" {
+		t.Errorf("POST /tools/project_registry = %d %v", code, out)
+	}
+	for _, tt := range []struct {
+		method, path, body string
+		code               int
+		typ                string
+	}{
+		{"GET", "/projects?fail=true&name=x", "", 404, "NOT_FOUND"},
+		{"GET", "/projects?limit=lots", "", 400, "VALIDATION"},
+		{"GET", "/projects?bogus=1", "", 400, "VALIDATION"},
+		{"POST", "/impact", `["parse"]`, 400, "VALIDATION"},
+		{"POST", "/tools/missing", "{}", 404, "NOT_FOUND"},
+		// cross_project_deps is not registered on this server.
+		{"GET", "/deps", "", 404, "NOT_FOUND"},
+	} {
+		if code, out := do(tt.method, tt.path, tt.body); code != tt.code || out["type"] != tt.typ {
+			t.Errorf("%s %s = %d %v, want %d %s", tt.method, tt.path, code, out, tt.code, tt.typ)
+		}
+	}
+
+	code, out = do("GET", "/openapi.json", "")
+	paths, _ := out["paths"].(map[string]any)
+	if code != 200 || paths["/projects"] == nil || paths["/tools/impact_analysis"] == nil || paths["/deps"] != nil ||
+		paths["/tools/cross_project_deps"] != nil {
+		t.Errorf("GET /openapi.json = %d, paths %v", code, paths)
+	}
+}
+
+func TestHandler_SessionPerKey(t *testing.T) {
+	s := server.NewMCPServer("test", "0")
+	s.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		b, _ := json.Marshal(map[string]any{"session": server.ClientSessionFromContext(ctx).SessionID()})
+		return mcp.NewToolResultText(string(b)), nil
+	})
+	keyed, err := access.New(Handler(s), []access.Key{{Name: "ci", Secret: "s1"}, {Name: "dash", Secret: "s2"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		h      http.Handler
+		secret string
+		want   string
+	}{
+		{keyed, "s1", "rest:ci"},
+		{keyed, "s2", "rest:dash"},
+		{Handler(s), "", "rest"},
+	} {
+		req := httptest.NewRequest("POST", "/tools/whoami", strings.NewReader("{}"))
+		if tt.secret != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.secret)
+		}
+		rec := httptest.NewRecorder()
+		tt.h.ServeHTTP(rec, req)
+		var out map[string]any
+		json.Unmarshal(rec.Body.Bytes(), &out)
+		if rec.Code != 200 || out["session"] != tt.want {
+			t.Errorf("key %q: %d %s, want session %s", tt.secret, rec.Code, rec.Body, tt.want)
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	path := filepath.Join("..", "..", OpenAPIPath)
+	want := OpenAPI(nil)
+	if got, err := os.ReadFile(path); err == nil && string(got) == string(want) {
+		return
+	}
+	if !*update {
+		t.Fatalf("%s is out of date; run go test ./internal/rest -run TestOpenAPI -update", OpenAPIPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, want, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
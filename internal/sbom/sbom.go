@@ -0,0 +1,346 @@
+// Package sbom renders dependency inventories as software bills of
+// materials: CycloneDX 1.5 or SPDX 2.3, both in JSON. A document covers one
+// project, or a whole workspace with each project as an application
+// component (CycloneDX) or package (SPDX) that depends on its own
+// dependencies.
+package sbom
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/mistakeknot/intermap/internal/deps"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{"cyclonedx", "spdx"}
+
+// Project is one application and the components it depends on.
+type Project struct {
+	Name       string
+	Components []deps.Component
+}
+
+// BOM is the input to a document.
+type BOM struct {
+	// Name is the workspace name for multi-project documents.
+	Name     string
+	Projects []Project
+	Created  time.Time
+	// Serial identifies the document (a UUID); one is generated if empty.
+	Serial string
+}
+
+// Encode renders the BOM in the named format.
+func (b *BOM) Encode(format string) ([]byte, error) {
+	if b.Serial == "" {
+		b.Serial = newUUID()
+	}
+	if b.Created.IsZero() {
+		b.Created = time.Now()
+	}
+	switch format {
+	case "", "cyclonedx":
+		return b.CycloneDX()
+	case "spdx":
+		return b.SPDX()
+	default:
+		return nil, fmt.Errorf("unknown format %q (want cyclonedx or spdx)", format)
+	}
+}
+
+// subject is the application a document describes: the only project, or
+// the workspace.
+func (b *BOM) subject() string {
+	if len(b.Projects) == 1 {
+		return b.Projects[0].Name
+	}
+	return b.Name
+}
+
+// components merges the projects' components by PURL. A component is dev
+// only when every project using it has it as dev.
+func (b *BOM) components() []deps.Component {
+	byPURL := make(map[string]int)
+	var out []deps.Component
+	for _, p := range b.Projects {
+		for _, c := range p.Components {
+			key := c.PURL()
+			if i, ok := byPURL[key]; ok {
+				out[i].Dev = out[i].Dev && c.Dev
+				continue
+			}
+			byPURL[key] = len(out)
+			c.Requires = append([]string(nil), c.Requires...)
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PURL() < out[j].PURL() })
+	return out
+}
+
+// directPURLs are the PURLs of a project's direct dependencies.
+func directPURLs(p Project) []string {
+	var out []string
+	for _, c := range p.Components {
+		if c.Direct {
+			out = append(out, c.PURL())
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// --- CycloneDX ---
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type       string        `json:"type"`
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Scope      string        `json:"scope,omitempty"`
+	Licenses   []cdxLicense  `json:"licenses,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxLicense struct {
+	Expression string `json:"expression"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func projectRef(name string) string { return "project:" + name }
+
+// CycloneDX renders the BOM as a CycloneDX 1.5 JSON document.
+func (b *BOM) CycloneDX() ([]byte, error) {
+	subject := b.subject()
+	doc := cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + b.Serial,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: b.Created.UTC().Format(time.RFC3339),
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: "intermap"}}},
+			Component: cdxComponent{Type: "application", BOMRef: projectRef(subject), Name: subject},
+		},
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{},
+	}
+
+	if len(b.Projects) == 1 {
+		doc.Dependencies = append(doc.Dependencies, cdxDependency{Ref: projectRef(subject), DependsOn: nonNil(directPURLs(b.Projects[0]))})
+	} else {
+		var refs []string
+		for _, p := range b.Projects {
+			refs = append(refs, projectRef(p.Name))
+			doc.Components = append(doc.Components, cdxComponent{Type: "application", BOMRef: projectRef(p.Name), Name: p.Name})
+			doc.Dependencies = append(doc.Dependencies, cdxDependency{Ref: projectRef(p.Name), DependsOn: nonNil(directPURLs(p))})
+		}
+		doc.Dependencies = append([]cdxDependency{{Ref: projectRef(subject), DependsOn: nonNil(refs)}}, doc.Dependencies...)
+	}
+
+	for _, c := range b.components() {
+		purl := c.PURL()
+		comp := cdxComponent{Type: "library", BOMRef: purl, Name: c.Name, Version: c.Version, PURL: purl, Scope: "required"}
+		if spdxExpression.MatchString(c.License) {
+			comp.Licenses = []cdxLicense{{Expression: c.License}}
+		}
+		if c.Dev {
+			comp.Scope = "optional"
+			comp.Properties = append(comp.Properties, cdxProperty{Name: "intermap:dev", Value: "true"})
+		}
+		comp.Properties = append(comp.Properties, cdxProperty{Name: "intermap:source", Value: c.Source})
+		doc.Components = append(doc.Components, comp)
+		doc.Dependencies = append(doc.Dependencies, cdxDependency{Ref: purl, DependsOn: nonNil(c.Requires)})
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// --- SPDX ---
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string       `json:"SPDXID"`
+	Name             string       `json:"name"`
+	VersionInfo      string       `json:"versionInfo,omitempty"`
+	DownloadLocation string       `json:"downloadLocation"`
+	FilesAnalyzed    bool         `json:"filesAnalyzed"`
+	LicenseConcluded string       `json:"licenseConcluded"`
+	LicenseDeclared  string       `json:"licenseDeclared"`
+	CopyrightText    string       `json:"copyrightText"`
+	PrimaryPurpose   string       `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs     []spdxExtRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExtRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+var (
+	spdxIDUnsafe = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+	// spdxExpression accepts what may be an SPDX license expression;
+	// free-form license names from package metadata are left out.
+	spdxExpression = regexp.MustCompile(`^[A-Za-z0-9.+()-]+( +[A-Za-z0-9.+()-]+)*$`)
+)
+
+// SPDX renders the BOM as an SPDX 2.3 JSON document.
+func (b *BOM) SPDX() ([]byte, error) {
+	subject := b.subject()
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              subject,
+		DocumentNamespace: "https://spdx.org/spdxdocs/intermap-" + spdxIDUnsafe.ReplaceAllString(subject, "-") + "-" + b.Serial,
+		CreationInfo: spdxCreationInfo{
+			Created:  b.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: intermap"},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+
+	used := make(map[string]bool)
+	newID := func(name string) string {
+		base := "SPDXRef-" + spdxIDUnsafe.ReplaceAllString(name, "-")
+		id := base
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[id] = true
+		return id
+	}
+	application := func(name string) string {
+		id := newID("Application-" + name)
+		doc.Packages = append(doc.Packages, spdxPackage{
+			SPDXID: id, Name: name, DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION", LicenseDeclared: "NOASSERTION", CopyrightText: "NOASSERTION",
+			PrimaryPurpose: "APPLICATION",
+		})
+		return id
+	}
+
+	projectIDs := make(map[string]string)
+	if len(b.Projects) == 1 {
+		projectIDs[subject] = application(subject)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{"SPDXRef-DOCUMENT", "DESCRIBES", projectIDs[subject]})
+	} else {
+		root := application(subject)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{"SPDXRef-DOCUMENT", "DESCRIBES", root})
+		for _, p := range b.Projects {
+			projectIDs[p.Name] = application(p.Name)
+			doc.Relationships = append(doc.Relationships, spdxRelationship{root, "CONTAINS", projectIDs[p.Name]})
+		}
+	}
+
+	ids := make(map[string]string)
+	components := b.components()
+	for _, c := range components {
+		purl := c.PURL()
+		ids[purl] = newID("Package-" + c.Name + "-" + c.Version)
+		declared := "NOASSERTION"
+		if spdxExpression.MatchString(c.License) {
+			declared = c.License
+		}
+		doc.Packages = append(doc.Packages, spdxPackage{
+			SPDXID: ids[purl], Name: c.Name, VersionInfo: c.Version, DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION", LicenseDeclared: declared, CopyrightText: "NOASSERTION",
+			PrimaryPurpose: "LIBRARY",
+			ExternalRefs:   []spdxExtRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: purl}},
+		})
+	}
+	for _, p := range b.Projects {
+		for _, c := range p.Components {
+			if !c.Direct {
+				continue
+			}
+			// SPDX states dev dependencies from the dependency's side.
+			if c.Dev {
+				doc.Relationships = append(doc.Relationships, spdxRelationship{ids[c.PURL()], "DEV_DEPENDENCY_OF", projectIDs[p.Name]})
+			} else {
+				doc.Relationships = append(doc.Relationships, spdxRelationship{projectIDs[p.Name], "DEPENDS_ON", ids[c.PURL()]})
+			}
+		}
+	}
+	for _, c := range components {
+		for _, req := range c.Requires {
+			if id, ok := ids[req]; ok {
+				doc.Relationships = append(doc.Relationships, spdxRelationship{ids[c.PURL()], "DEPENDS_ON", id})
+			}
+		}
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var u [16]byte
+	rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
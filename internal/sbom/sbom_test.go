@@ -0,0 +1,125 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermap/internal/deps"
+)
+
+func testBOM(projects ...Project) *BOM {
+	return &BOM{
+		Name:     "ws",
+		Projects: projects,
+		Created:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Serial:   "00000000-0000-4000-8000-000000000000",
+	}
+}
+
+var (
+	api = Project{Name: "api", Components: []deps.Component{
+		{Name: "express", Version: "4.18.2", Ecosystem: deps.NPM, Direct: true, Requires: []string{"pkg:npm/debug@2.6.9"}, Source: "package-lock.json", License: "MIT"},
+		{Name: "debug", Version: "2.6.9", Ecosystem: deps.NPM, Source: "package-lock.json"},
+		{Name: "jest", Version: "29.7.0", Ecosystem: deps.NPM, Direct: true, Dev: true, Source: "package-lock.json"},
+	}}
+	web = Project{Name: "web", Components: []deps.Component{
+		{Name: "debug", Version: "2.6.9", Ecosystem: deps.NPM, Direct: true, Source: "package-lock.json"},
+	}}
+)
+
+func TestCycloneDX(t *testing.T) {
+	data, err := testBOM(api).Encode("cyclonedx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc cdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.BOMFormat != "CycloneDX" || doc.SpecVersion != "1.5" || doc.Metadata.Component.Name != "api" || doc.Metadata.Timestamp != "2026-01-02T03:04:05Z" {
+		t.Errorf("header = %+v", doc)
+	}
+	if len(doc.Components) != 3 {
+		t.Fatalf("components = %+v", doc.Components)
+	}
+	for _, c := range doc.Components {
+		if c.Name == "jest" && c.Scope != "optional" {
+			t.Errorf("dev component scope = %q", c.Scope)
+		}
+		if c.Name == "express" && (len(c.Licenses) != 1 || c.Licenses[0].Expression != "MIT") {
+			t.Errorf("express licenses = %+v", c.Licenses)
+		}
+	}
+	root := doc.Dependencies[0]
+	if root.Ref != "project:api" || len(root.DependsOn) != 2 {
+		t.Errorf("root dependencies = %+v", root)
+	}
+	for _, d := range doc.Dependencies {
+		if d.Ref == "pkg:npm/express@4.18.2" && (len(d.DependsOn) != 1 || d.DependsOn[0] != "pkg:npm/debug@2.6.9") {
+			t.Errorf("express dependencies = %+v", d)
+		}
+	}
+}
+
+func TestCycloneDXWorkspace(t *testing.T) {
+	data, err := testBOM(api, web).Encode("cyclonedx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc cdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Metadata.Component.Name != "ws" {
+		t.Errorf("subject = %+v", doc.Metadata.Component)
+	}
+	// Two applications and three libraries; debug is shared.
+	if len(doc.Components) != 5 {
+		t.Errorf("components = %+v", doc.Components)
+	}
+	if root := doc.Dependencies[0]; root.Ref != "project:ws" || len(root.DependsOn) != 2 {
+		t.Errorf("workspace dependencies = %+v", root)
+	}
+}
+
+func TestSPDX(t *testing.T) {
+	data, err := testBOM(api, web).Encode("spdx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc spdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.DocumentNamespace != "https://spdx.org/spdxdocs/intermap-ws-00000000-0000-4000-8000-000000000000" {
+		t.Errorf("header = %+v", doc)
+	}
+	ids := make(map[string]bool)
+	for _, p := range doc.Packages {
+		if ids[p.SPDXID] {
+			t.Errorf("duplicate SPDXID %s", p.SPDXID)
+		}
+		ids[p.SPDXID] = true
+	}
+	if len(doc.Packages) != 6 {
+		t.Errorf("packages = %+v", doc.Packages)
+	}
+	counts := make(map[string]int)
+	for _, r := range doc.Relationships {
+		if !ids[r.Related] || (r.Element != "SPDXRef-DOCUMENT" && !ids[r.Element]) {
+			t.Errorf("dangling relationship %+v", r)
+		}
+		counts[r.Type]++
+	}
+	// express and debug for api and web, plus express -> debug.
+	if counts["DESCRIBES"] != 1 || counts["CONTAINS"] != 2 || counts["DEPENDS_ON"] != 3 || counts["DEV_DEPENDENCY_OF"] != 1 {
+		t.Errorf("relationships = %v", counts)
+	}
+}
+
+func TestEncodeUnknownFormat(t *testing.T) {
+	if _, err := testBOM(api).Encode("swid"); err == nil {
+		t.Error("Encode accepted an unknown format")
+	}
+}
@@ -0,0 +1,146 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/mistakeknot/intermap/analysis"
+)
+
+// results maps each sidecar command the tools run to its result type.
+// Commands not listed (the debugging commands) are not checked.
+var results = map[string]reflect.Type{
+	"structure":            reflect.TypeFor[Structure](),
+	"impact":               reflect.TypeFor[Impact](),
+	"change_impact":        reflect.TypeFor[ChangeImpact](),
+	"bench_impact":         reflect.TypeFor[BenchImpact](),
+	"doc_coverage":         reflect.TypeFor[DocCoverage](),
+	"api_surface":          reflect.TypeFor[APISurface](),
+	"message_inventory":    reflect.TypeFor[MessageInventory](),
+	"agent_history_record": reflect.TypeFor[AgentHistoryRecord](),
+	"agent_timeline":       reflect.TypeFor[AgentTimeline](),
+	"cross_project_deps":   reflect.TypeFor[CrossProjectDeps](),
+	"consumers":            reflect.TypeFor[Consumers](),
+	"deprecations":         reflect.TypeFor[Deprecations](),
+	"codemod_plan":         reflect.TypeFor[CodemodPlan](),
+	"apply_rename":         reflect.TypeFor[Rename](),
+	"script_map":           reflect.TypeFor[ScriptMap](),
+	"boundary_map":         reflect.TypeFor[BoundaryMap](),
+	"service_graph":        reflect.TypeFor[ServiceGraph](),
+	"release_impact":       reflect.TypeFor[ReleaseImpact](),
+	"name_collisions":      reflect.TypeFor[NameCollisions](),
+	"code_search":          reflect.TypeFor[CodeSearch](),
+	"semantic_search":      reflect.TypeFor[SemanticSearch](),
+	"describe_symbol":      reflect.TypeFor[DescribeSymbol](),
+	"effects_analysis":     reflect.TypeFor[EffectsAnalysis](),
+	"taint_paths":          reflect.TypeFor[TaintPaths](),
+	"error_flow":           reflect.TypeFor[ErrorFlow](),
+	"detect_patterns":      reflect.TypeFor[DetectPatterns](),
+	"live_changes":         reflect.TypeFor[LiveChanges](),
+	"structure_of_diff":    reflect.TypeFor[DiffStructure](),
+	"reference_edges":      reflect.TypeFor[ReferenceEdges](),
+	"index_update":         reflect.TypeFor[IndexUpdate](),
+}
+
+// Result returns the result type of command.
+func Result(command string) (reflect.Type, bool) {
+	t, ok := results[command]
+	return t, ok
+}
+
+// Commands lists the commands with a result type.
+func Commands() []string {
+	out := make([]string, 0, len(results))
+	for c := range results {
+		out = append(out, c)
+	}
+	return out
+}
+
+// MismatchError reports a sidecar result that does not match its command's
+// schema.
+type MismatchError struct {
+	Command  string
+	Problems []string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("%s result does not match its schema: %s", e.Command, strings.Join(e.Problems, "; "))
+}
+
+// Check validates result against command's type. Skipped and error results
+// are valid for every command, and commands without a type are not
+// checked.
+func Check(command string, result map[string]any) error {
+	t, ok := results[command]
+	if !ok {
+		return nil
+	}
+	if _, ok := result["skipped"]; ok {
+		return mismatch(command, result, reflect.TypeFor[Skipped]())
+	}
+	if _, ok := result["error"]; ok {
+		return mismatch(command, result, reflect.TypeFor[Failed]())
+	}
+	return mismatch(command, result, t)
+}
+
+func mismatch(command string, result map[string]any, t reflect.Type) error {
+	if problems := Validate(result, t); len(problems) > 0 {
+		return &MismatchError{Command: command, Problems: problems}
+	}
+	return nil
+}
+
+// Output returns the JSON Schema of a tool that returns command's result:
+// the result, a skipped or error result, or one of the alternatives (such
+// as a spilled result). It panics if command has no type.
+func Output(command string, alternatives ...reflect.Type) json.RawMessage {
+	t, ok := results[command]
+	if !ok {
+		panic("schema: no result type for command " + command)
+	}
+	variants := []any{For(t), For(reflect.TypeFor[Skipped]()), For(reflect.TypeFor[Failed]())}
+	for _, alt := range alternatives {
+		variants = append(variants, For(alt))
+	}
+	out, err := json.Marshal(map[string]any{"type": "object", "anyOf": variants})
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// Checked is a Backend that checks every result of the wrapped backend
+// against its command's schema. A mismatch is logged once per command, or
+// with Strict, returned as a *MismatchError.
+type Checked struct {
+	Backend analysis.Backend
+	Strict  bool
+
+	logged sync.Map // command → struct{}
+}
+
+var _ analysis.Backend = (*Checked)(nil)
+
+// Run runs command on the wrapped backend and checks its result.
+func (c *Checked) Run(ctx context.Context, command, project string, args map[string]any) (map[string]any, error) {
+	result, err := c.Backend.Run(ctx, command, project, args)
+	if err != nil {
+		return result, err
+	}
+	if err := Check(command, result); err != nil {
+		if c.Strict {
+			return nil, err
+		}
+		if _, seen := c.logged.LoadOrStore(command, struct{}{}); !seen {
+			slog.Warn("sidecar result does not match schema", "command", command, "error", err)
+		}
+	}
+	return result, nil
+}
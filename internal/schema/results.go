@@ -0,0 +1,856 @@
+package schema
+
+// Skipped is the result of any command whose path is missing from a sparse
+// checkout or partial clone.
+type Skipped struct {
+	Skipped     bool           `json:"skipped"`
+	Reason      string         `json:"reason"`
+	MissingPath string         `json:"missing_path"`
+	Checkout    map[string]any `json:"checkout"`
+}
+
+// Failed is a result reporting that the command could not answer, such as
+// an impact target that is not in the call graph.
+type Failed struct {
+	Error   string `json:"error"`
+	Message string `json:"message,omitempty"`
+}
+
+// Structure is the result of structure (code_structure).
+type Structure struct {
+	Root     string          `json:"root"`
+	Language string          `json:"language"`
+	Files    []StructureFile `json:"files"`
+	// Sampled results add the coverage of the files picked.
+	Sampled  bool           `json:"sampled,omitempty"`
+	Coverage map[string]any `json:"coverage,omitempty"`
+}
+
+// StructureFile is one file's definitions and imports.
+type StructureFile struct {
+	Path      string   `json:"path"`
+	Functions []string `json:"functions"`
+	Classes   []string `json:"classes"`
+	Imports   []string `json:"imports"`
+	Reason    string   `json:"reason,omitempty"`
+	FanIn     int      `json:"fan_in,omitempty"`
+}
+
+// Impact is the result of impact (impact_analysis): a caller tree per
+// function matching the target.
+type Impact struct {
+	Targets      map[string]ImpactNode `json:"targets"`
+	TotalTargets int                   `json:"total_targets"`
+}
+
+// ImpactNode is a function and the callers found within the depth limit.
+type ImpactNode struct {
+	Function    string       `json:"function"`
+	Qualified   string       `json:"qualified,omitempty"`
+	File        string       `json:"file"`
+	ID          string       `json:"id,omitempty"`
+	CallerCount int          `json:"caller_count"`
+	Callers     []ImpactNode `json:"callers"`
+	Truncated   bool         `json:"truncated"`
+}
+
+// ChangeImpact is the result of change_impact.
+type ChangeImpact struct {
+	ChangedFiles     []string `json:"changed_files"`
+	ChangedFunctions []string `json:"changed_functions"`
+	AffectedTests    []string `json:"affected_tests"`
+	AffectedCount    int      `json:"affected_count"`
+	SkippedCount     int      `json:"skipped_count"`
+	TotalTests       int      `json:"total_tests"`
+	// TestCommand is null for languages without a default runner.
+	TestCommand    *[]string                 `json:"test_command"`
+	Source         string                    `json:"source"`
+	Message        string                    `json:"message,omitempty"`
+	SkippedMissing []string                  `json:"skipped_missing,omitempty"`
+	TestMetadata   map[string]map[string]any `json:"test_metadata,omitempty"`
+	SuggestedOrder []string                  `json:"suggested_order,omitempty"`
+	TestSelection  *TestSelection            `json:"test_selection,omitempty"`
+}
+
+// TestSelection is a set of tests formatted for one runner.
+type TestSelection struct {
+	Runner       string     `json:"runner"`
+	Selectors    []string   `json:"selectors"`
+	Commands     [][]string `json:"commands"`
+	CommandLines []string   `json:"command_lines"`
+}
+
+// BenchImpact is the result of bench_impact.
+type BenchImpact struct {
+	ChangedFiles     []string    `json:"changed_files"`
+	ChangedFunctions []string    `json:"changed_functions"`
+	Benchmarks       []Benchmark `json:"benchmarks"`
+	BenchmarkCount   int         `json:"benchmark_count"`
+	Commands         [][]string  `json:"commands"`
+	CommandLines     []string    `json:"command_lines"`
+}
+
+// Benchmark is a benchmark that exercises changed code; Via lists the
+// affected functions it calls.
+type Benchmark struct {
+	File     string   `json:"file"`
+	Function string   `json:"function"`
+	Via      []string `json:"via"`
+}
+
+// DocCoverage is the result of doc_coverage.
+type DocCoverage struct {
+	Language          string                  `json:"language"`
+	Total             int                     `json:"total"`
+	Documented        int                     `json:"documented"`
+	Coverage          float64                 `json:"coverage"`
+	Files             map[string]FileCoverage `json:"files"`
+	Undocumented      []Undocumented          `json:"undocumented"`
+	UndocumentedCount int                     `json:"undocumented_count"`
+}
+
+// FileCoverage counts one file's public symbols.
+type FileCoverage struct {
+	Total      int `json:"total"`
+	Documented int `json:"documented"`
+}
+
+// Undocumented is a public symbol without documentation.
+type Undocumented struct {
+	File      string `json:"file"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Line      int    `json:"line"`
+	Callers   int    `json:"callers"`
+	CallSites int    `json:"call_sites"`
+}
+
+// APISurface is the result of api_surface.
+type APISurface struct {
+	Language string       `json:"language"`
+	Packages []APIPackage `json:"packages"`
+	Count    int          `json:"count"`
+}
+
+// APIPackage is the public symbols of one package.
+type APIPackage struct {
+	Package string      `json:"package"`
+	Symbols []APISymbol `json:"symbols"`
+}
+
+// APISymbol is one public symbol; Members lists the exported fields and
+// methods of Go structs and interfaces.
+type APISymbol struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"`
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+	Signature string   `json:"signature"`
+	ID        string   `json:"id"`
+	Members   []string `json:"members,omitempty"`
+}
+
+// MessageInventory is the result of message_inventory.
+type MessageInventory struct {
+	Messages   []Message          `json:"messages"`
+	Count      int                `json:"count"`
+	ByKind     map[string]int     `json:"by_kind"`
+	Duplicates []DuplicateMessage `json:"duplicates"`
+	Truncated  bool               `json:"truncated"`
+}
+
+// Message is one user-facing string.
+type Message struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+}
+
+// DuplicateMessage is a text found at more than one location.
+type DuplicateMessage struct {
+	Kind      string   `json:"kind"`
+	Text      string   `json:"text"`
+	Locations []string `json:"locations"`
+}
+
+// AgentHistoryRecord is the result of agent_history_record.
+type AgentHistoryRecord struct {
+	SnapshotID int `json:"snapshot_id"`
+	Agents     int `json:"agents"`
+	Pruned     int `json:"pruned"`
+}
+
+// AgentTimeline is the result of agent_timeline.
+type AgentTimeline struct {
+	Since     string           `json:"since"`
+	Until     string           `json:"until"`
+	Snapshots int              `json:"snapshots"`
+	Agents    []AgentSegments  `json:"agents"`
+	Events    []map[string]any `json:"events"`
+}
+
+// AgentSegments is one agent's runs of unchanged snapshots.
+type AgentSegments struct {
+	AgentID  string         `json:"agent_id"`
+	Name     *string        `json:"name"`
+	Segments []AgentSegment `json:"segments"`
+}
+
+// AgentSegment is a run of consecutive snapshots in which an agent kept
+// the same project, task, and reservations.
+type AgentSegment struct {
+	From         string   `json:"from"`
+	To           string   `json:"to"`
+	Snapshots    int      `json:"snapshots"`
+	Project      *string  `json:"project"`
+	ProjectPath  *string  `json:"project_path"`
+	Status       *string  `json:"status"`
+	CurrentTask  *string  `json:"current_task"`
+	Reservations []string `json:"reservations"`
+}
+
+// CrossProjectDeps is the result of cross_project_deps.
+type CrossProjectDeps struct {
+	Root          string        `json:"root"`
+	Projects      []ProjectDeps `json:"projects"`
+	Groups        []GroupDeps   `json:"groups"`
+	TotalProjects int           `json:"total_projects"`
+	TotalEdges    int           `json:"total_edges"`
+}
+
+// ProjectDeps is one project and the projects it depends on.
+type ProjectDeps struct {
+	Project   string       `json:"project"`
+	Path      string       `json:"path"`
+	Group     string       `json:"group"`
+	DependsOn []Dependency `json:"depends_on"`
+}
+
+// Dependency is an edge to another project; Type is how it was found and
+// Via the evidence.
+type Dependency struct {
+	Project string `json:"project"`
+	Type    string `json:"type"`
+	Via     string `json:"via"`
+}
+
+// GroupDeps rolls project edges up to a group.
+type GroupDeps struct {
+	Group         string      `json:"group"`
+	Projects      int         `json:"projects"`
+	InternalEdges int         `json:"internal_edges"`
+	DependsOn     []GroupEdge `json:"depends_on"`
+}
+
+// GroupEdge counts the edges from one group to another.
+type GroupEdge struct {
+	Group string `json:"group"`
+	Edges int    `json:"edges"`
+}
+
+// Consumers is the result of consumers.
+type Consumers struct {
+	Project      string             `json:"project"`
+	Names        []string           `json:"names"`
+	Packages     []ConsumedPackage  `json:"packages"`
+	Projects     []ConsumingProject `json:"projects"`
+	TotalFiles   int                `json:"total_files"`
+	DeclaredOnly []string           `json:"declared_only"`
+}
+
+// ConsumedPackage is one package of the project and the imports of it.
+type ConsumedPackage struct {
+	Package   string       `json:"package"`
+	Consumers []ImportSite `json:"consumers"`
+}
+
+// ImportSite is one import of a package.
+type ImportSite struct {
+	Project string `json:"project"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Import  string `json:"import"`
+}
+
+// ConsumingProject summarizes one project's imports of the target.
+type ConsumingProject struct {
+	Project  string   `json:"project"`
+	Files    int      `json:"files"`
+	Packages []string `json:"packages"`
+}
+
+// Deprecations is the result of deprecations.
+type Deprecations struct {
+	Root           string               `json:"root"`
+	Symbols        []DeprecatedSymbol   `json:"symbols"`
+	Projects       []DeprecationBacklog `json:"projects"`
+	TotalSymbols   int                  `json:"total_symbols"`
+	TotalRemaining int                  `json:"total_remaining"`
+}
+
+// DeprecatedSymbol is a deprecated symbol and its remaining uses.
+type DeprecatedSymbol struct {
+	Name      string         `json:"name"`
+	Kind      string         `json:"kind"`
+	Project   string         `json:"project"`
+	File      string         `json:"file"`
+	Line      int            `json:"line"`
+	Message   string         `json:"message"`
+	Remaining int            `json:"remaining"`
+	Projects  map[string]int `json:"projects"`
+	Sites     []ProjectSite  `json:"sites"`
+}
+
+// ProjectSite is a line in a project file.
+type ProjectSite struct {
+	Project string `json:"project"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+// DeprecationBacklog is one project's remaining uses of deprecated symbols.
+type DeprecationBacklog struct {
+	Project   string           `json:"project"`
+	Remaining int              `json:"remaining"`
+	Symbols   []map[string]any `json:"symbols"`
+}
+
+// CodemodPlan is the result of codemod_plan.
+type CodemodPlan struct {
+	Root       string           `json:"root"`
+	Match      string           `json:"match"`
+	Rewrite    *string          `json:"rewrite"`
+	Projects   []CodemodProject `json:"projects"`
+	Owners     []CodemodOwner   `json:"owners"`
+	Steps      []CodemodStep    `json:"steps"`
+	TotalSites int              `json:"total_sites"`
+	TotalFiles int              `json:"total_files"`
+	Truncated  bool             `json:"truncated"`
+}
+
+// CodemodProject is one project's matches and the projects it depends on.
+type CodemodProject struct {
+	Project   string           `json:"project"`
+	Path      string           `json:"path"`
+	Step      int              `json:"step"`
+	Owners    []string         `json:"owners"`
+	Files     int              `json:"files"`
+	Sites     []map[string]any `json:"sites"`
+	DependsOn []string         `json:"depends_on"`
+}
+
+// CodemodOwner is the matches one owner must review.
+type CodemodOwner struct {
+	Owner    string   `json:"owner"`
+	Projects []string `json:"projects"`
+	Sites    int      `json:"sites"`
+}
+
+// CodemodStep is the projects that can be migrated together.
+type CodemodStep struct {
+	Step     int      `json:"step"`
+	Projects []string `json:"projects"`
+	Cycle    bool     `json:"cycle"`
+}
+
+// Rename is the result of apply_rename.
+type Rename struct {
+	Kind      string          `json:"kind"`
+	Old       string          `json:"old"`
+	New       string          `json:"new"`
+	Language  string          `json:"language"`
+	Safe      bool            `json:"safe"`
+	Conflicts []RenameProblem `json:"conflicts"`
+	Edits     []RenameEdit    `json:"edits"`
+	Files     []string        `json:"files"`
+	EditCount int             `json:"edit_count"`
+	Diff      string          `json:"diff"`
+	Applied   bool            `json:"applied"`
+}
+
+// RenameProblem is why a rename is unsafe at a location.
+type RenameProblem struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// RenameEdit is the start of one replaced span.
+type RenameEdit struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// ScriptMap is the result of script_map.
+type ScriptMap struct {
+	Root       string            `json:"root"`
+	Files      []ScriptFile      `json:"files"`
+	Edges      []ScriptEdge      `json:"edges"`
+	Binaries   map[string]string `json:"binaries"`
+	TotalFiles int               `json:"total_files"`
+	TotalEdges int               `json:"total_edges"`
+}
+
+// ScriptFile is a script or Makefile and the project that owns it.
+type ScriptFile struct {
+	File    string `json:"file"`
+	Kind    string `json:"kind"`
+	Project string `json:"project"`
+}
+
+// ScriptEdge is a script line invoking another script or binary.
+type ScriptEdge struct {
+	From        string `json:"from"`
+	FromProject string `json:"from_project"`
+	Line        int    `json:"line"`
+	Kind        string `json:"kind"`
+	To          string `json:"to"`
+	ToProject   string `json:"to_project"`
+}
+
+// BoundaryMap is the result of boundary_map.
+type BoundaryMap struct {
+	Root            string            `json:"root"`
+	Boundaries      []Boundary        `json:"boundaries"`
+	Extensions      []Extension       `json:"extensions"`
+	Services        map[string]string `json:"services"`
+	Ports           map[string]string `json:"ports"`
+	ByKind          map[string]int    `json:"by_kind"`
+	TotalBoundaries int               `json:"total_boundaries"`
+}
+
+// Boundary is a call crossing a language or process boundary.
+type Boundary struct {
+	File      string `json:"file"`
+	Project   string `json:"project"`
+	Line      int    `json:"line"`
+	Kind      string `json:"kind"`
+	Target    string `json:"target"`
+	ToProject string `json:"to_project"`
+}
+
+// Extension is a native extension module and the project that builds it.
+type Extension struct {
+	Module  string `json:"module"`
+	Binding string `json:"binding"`
+	File    string `json:"file"`
+	Project string `json:"project"`
+}
+
+// ServiceGraph is the result of service_graph.
+type ServiceGraph struct {
+	Root            string        `json:"root"`
+	Services        []Service     `json:"services"`
+	Edges           []ServiceEdge `json:"edges"`
+	TotalDeployable int           `json:"total_deployable"`
+	TotalEdges      int           `json:"total_edges"`
+}
+
+// Service is one project as a runtime service.
+type Service struct {
+	Project       string   `json:"project"`
+	Deployable    bool     `json:"deployable"`
+	Ports         []string `json:"ports"`
+	Binaries      []string `json:"binaries"`
+	HasDockerfile bool     `json:"has_dockerfile"`
+	Calls         []string `json:"calls"`
+	CalledBy      []string `json:"called_by"`
+}
+
+// ServiceEdge is one service calling another.
+type ServiceEdge struct {
+	From      string        `json:"from"`
+	To        string        `json:"to"`
+	Protocols []string      `json:"protocols"`
+	Sites     []ServiceCall `json:"sites"`
+}
+
+// ServiceCall is the code behind a service edge.
+type ServiceCall struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Protocol string `json:"protocol"`
+	Via      string `json:"via"`
+}
+
+// ReleaseImpact is the result of release_impact.
+type ReleaseImpact struct {
+	Project     string        `json:"project"`
+	Path        string        `json:"path"`
+	Language    string        `json:"language"`
+	Since       *string       `json:"since"`
+	PublishedAs []Publication `json:"published_as"`
+	// API is null when the project has no release tag.
+	API          *APIDiff          `json:"api"`
+	Consumers    []ReleaseConsumer `json:"consumers"`
+	DeclaredOnly []string          `json:"declared_only"`
+}
+
+// Publication is a name the project is published under.
+type Publication struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+// APIDiff is the public API change since a release.
+type APIDiff struct {
+	Removed  []APIChange `json:"removed"`
+	Changed  []APIChange `json:"changed"`
+	Extended []APIChange `json:"extended"`
+	Added    []APIChange `json:"added"`
+	Breaking bool        `json:"breaking"`
+}
+
+// APIChange is one symbol added, removed, or changed; changes carry the
+// signatures before and after.
+type APIChange struct {
+	Package   string `json:"package"`
+	Symbol    string `json:"symbol"`
+	Kind      string `json:"kind"`
+	Signature string `json:"signature,omitempty"`
+	Before    string `json:"before,omitempty"`
+	After     string `json:"after,omitempty"`
+}
+
+// ReleaseConsumer is a project importing the released one.
+type ReleaseConsumer struct {
+	Project      string        `json:"project"`
+	Path         string        `json:"path"`
+	Files        int           `json:"files"`
+	Packages     []string      `json:"packages"`
+	BreakingUses []BreakingUse `json:"breaking_uses"`
+}
+
+// BreakingUse is a consumer line naming a removed or changed symbol.
+type BreakingUse struct {
+	Symbol  string `json:"symbol"`
+	Package string `json:"package"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+// NameCollisions is the result of name_collisions.
+type NameCollisions struct {
+	Root       string          `json:"root"`
+	Collisions []NameCollision `json:"collisions"`
+	Projects   []string        `json:"projects"`
+	Total      int             `json:"total"`
+}
+
+// NameCollision is an exported name defined by more than one project.
+type NameCollision struct {
+	Name     string              `json:"name"`
+	Kind     string              `json:"kind"`
+	Relation string              `json:"relation"`
+	Projects []CollidingProject  `json:"projects"`
+	Pairs    []CollisionRelation `json:"pairs"`
+}
+
+// CollidingProject is one project's definitions of a colliding name.
+type CollidingProject struct {
+	Project     string           `json:"project"`
+	Language    string           `json:"language"`
+	Definitions []map[string]any `json:"definitions"`
+}
+
+// CollisionRelation is how two defining projects are linked by imports.
+type CollisionRelation struct {
+	A         string   `json:"a"`
+	B         string   `json:"b"`
+	Relation  string   `json:"relation"`
+	Importers []string `json:"importers"`
+}
+
+// CodeSearch is the result of code_search.
+type CodeSearch struct {
+	Root          string        `json:"root"`
+	Mode          string        `json:"mode"`
+	Pattern       string        `json:"pattern"`
+	Matches       []SearchMatch `json:"matches"`
+	Count         int           `json:"count"`
+	FilesSearched int           `json:"files_searched"`
+	Truncated     bool          `json:"truncated"`
+}
+
+// SearchMatch is one match with its enclosing symbol, if any.
+type SearchMatch struct {
+	File    string         `json:"file"`
+	Project string         `json:"project"`
+	Line    int            `json:"line"`
+	Column  int            `json:"column"`
+	EndLine int            `json:"end_line"`
+	Text    string         `json:"text"`
+	Symbol  *SymbolRef     `json:"symbol"`
+	Holes   map[string]any `json:"holes"`
+}
+
+// SymbolRef locates a definition.
+type SymbolRef struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Line int    `json:"line"`
+	ID   string `json:"id"`
+}
+
+// SemanticSearch is the result of semantic_search.
+type SemanticSearch struct {
+	Root    string           `json:"root"`
+	Query   string           `json:"query"`
+	Model   string           `json:"model"`
+	Index   SemanticIndex    `json:"index"`
+	Results []SemanticResult `json:"results"`
+}
+
+// SemanticIndex reports the state of the vector index after the call.
+type SemanticIndex struct {
+	Files    int `json:"files"`
+	Chunks   int `json:"chunks"`
+	Embedded int `json:"embedded"`
+	Pending  int `json:"pending"`
+}
+
+// SemanticResult is one ranked chunk; module-level chunks have no symbol.
+type SemanticResult struct {
+	File     string  `json:"file"`
+	Project  string  `json:"project"`
+	Line     int     `json:"line"`
+	EndLine  int     `json:"end_line"`
+	Symbol   *string `json:"symbol"`
+	SymbolID *string `json:"symbol_id"`
+	Kind     string  `json:"kind"`
+	Score    float64 `json:"score"`
+	Preview  string  `json:"preview"`
+}
+
+// DescribeSymbol is the result of describe_symbol.
+type DescribeSymbol struct {
+	Symbols   []SymbolSummary `json:"symbols"`
+	Truncated bool            `json:"truncated"`
+}
+
+// SymbolSummary describes one definition. The language-specific details
+// (params, returns, raises, effects, sources, sinks, complexity) are not
+// listed here.
+type SymbolSummary struct {
+	Symbol    string   `json:"symbol"`
+	ID        string   `json:"id"`
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+	Kind      string   `json:"kind"`
+	Signature string   `json:"signature"`
+	EndLine   int      `json:"end_line"`
+	Doc       string   `json:"doc"`
+	Callees   Callees  `json:"callees"`
+	Callers   []string `json:"callers"`
+	Cached    bool     `json:"cached"`
+}
+
+// Callees splits a symbol's calls into project functions (file:name) and
+// external names.
+type Callees struct {
+	Project  []string `json:"project"`
+	External []string `json:"external"`
+}
+
+// EffectsAnalysis is the result of effects_analysis.
+type EffectsAnalysis struct {
+	Functions    []FunctionEffects       `json:"functions"`
+	ByCapability map[string]EffectCounts `json:"by_capability"`
+	Analyzed     int                     `json:"analyzed"`
+	Truncated    bool                    `json:"truncated"`
+}
+
+// FunctionEffects is a function's capabilities, with the calls that use
+// each directly and the call chains that reach the rest.
+type FunctionEffects struct {
+	Symbol       string                      `json:"symbol"`
+	File         string                      `json:"file"`
+	Line         int                         `json:"line"`
+	ID           string                      `json:"id"`
+	Capabilities []string                    `json:"capabilities"`
+	Direct       map[string][]string         `json:"direct"`
+	Transitive   map[string]TransitiveEffect `json:"transitive"`
+}
+
+// TransitiveEffect is a call chain (file:function) to the callee that uses
+// a capability, and the APIs it calls.
+type TransitiveEffect struct {
+	Via  []string `json:"via"`
+	APIs []string `json:"apis"`
+}
+
+// EffectCounts counts the functions with a capability.
+type EffectCounts struct {
+	Direct     int `json:"direct"`
+	Transitive int `json:"transitive"`
+}
+
+// TaintPaths is the result of taint_paths.
+type TaintPaths struct {
+	Flows     []TaintFlow    `json:"flows"`
+	ByKind    map[string]int `json:"by_kind"`
+	Count     int            `json:"count"`
+	Truncated bool           `json:"truncated"`
+}
+
+// TaintFlow is a call path from an input source to a sink.
+type TaintFlow struct {
+	Source TaintEnd `json:"source"`
+	Sink   TaintEnd `json:"sink"`
+	Path   []string `json:"path"`
+	Length int      `json:"length"`
+}
+
+// TaintEnd is the function at one end of a flow and the source or sink
+// kinds it touches.
+type TaintEnd struct {
+	Function string              `json:"function"`
+	File     string              `json:"file"`
+	Line     int                 `json:"line"`
+	ID       string              `json:"id"`
+	Kinds    map[string][]string `json:"kinds"`
+}
+
+// ErrorFlow is the result of error_flow.
+type ErrorFlow struct {
+	Sites       []ErrorSite    `json:"sites"`
+	Swallowed   []ErrorSite    `json:"swallowed"`
+	ByKind      map[string]int `json:"by_kind"`
+	EntryPoints int            `json:"entry_points"`
+	Truncated   bool           `json:"truncated"`
+}
+
+// ErrorSite is where an error is created, wrapped, swallowed, or turned
+// into a panic. Swallowed sites add reachability from an entry point.
+type ErrorSite struct {
+	Kind      string   `json:"kind"`
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+	Function  string   `json:"function"`
+	Detail    string   `json:"detail"`
+	Reachable bool     `json:"reachable,omitempty"`
+	Path      []string `json:"path,omitempty"`
+}
+
+// DetectPatterns is the result of detect_patterns.
+type DetectPatterns struct {
+	Project       string    `json:"project"`
+	Language      string    `json:"language"`
+	Patterns      []Pattern `json:"patterns"`
+	TotalPatterns int       `json:"total_patterns"`
+}
+
+// Pattern is one detected architectural pattern.
+type Pattern struct {
+	Type        string  `json:"type"`
+	Location    string  `json:"location"`
+	Confidence  float64 `json:"confidence"`
+	Description string  `json:"description"`
+}
+
+// LiveChanges is the result of live_changes.
+type LiveChanges struct {
+	Project              string       `json:"project"`
+	Baseline             string       `json:"baseline"`
+	Changes              []FileChange `json:"changes"`
+	TotalFiles           int          `json:"total_files"`
+	TotalSymbolsAffected int          `json:"total_symbols_affected"`
+}
+
+// FileChange is one changed file and the symbols its hunks touch.
+type FileChange struct {
+	File            string           `json:"file"`
+	Status          string           `json:"status"`
+	Hunks           []Hunk           `json:"hunks"`
+	SymbolsAffected []map[string]any `json:"symbols_affected"`
+}
+
+// Hunk is a changed line range.
+type Hunk struct {
+	OldStart int `json:"old_start"`
+	OldCount int `json:"old_count"`
+	NewStart int `json:"new_start"`
+	NewCount int `json:"new_count"`
+}
+
+// DiffStructure is the result of structure_of_diff.
+type DiffStructure struct {
+	Project             string     `json:"project"`
+	Base                string     `json:"base"`
+	Head                string     `json:"head"`
+	Files               []DiffFile `json:"files"`
+	TotalFiles          int        `json:"total_files"`
+	TotalChangedSymbols int        `json:"total_changed_symbols"`
+	Truncated           bool       `json:"truncated"`
+}
+
+// DiffFile is the outline of one file in a diff.
+type DiffFile struct {
+	Path           string       `json:"path"`
+	Status         string       `json:"status"`
+	Hunks          []Hunk       `json:"hunks"`
+	Symbols        []DiffSymbol `json:"symbols"`
+	ChangedSymbols []string     `json:"changed_symbols"`
+	Approximate    bool         `json:"approximate"`
+}
+
+// DiffSymbol is a definition in a diffed file.
+type DiffSymbol struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Line    int    `json:"line"`
+	End     int    `json:"end"`
+	Changed bool   `json:"changed"`
+}
+
+// ReferenceEdges is the result of reference_edges.
+type ReferenceEdges struct {
+	Definitions  []Definition    `json:"definitions"`
+	Edges        []ReferenceEdge `json:"edges"`
+	FileEdges    []FileEdge      `json:"file_edges"`
+	FilesScanned int             `json:"files_scanned"`
+	Language     string          `json:"language"`
+	EdgeCount    int             `json:"edge_count"`
+}
+
+// Definition is one definition tag.
+type Definition struct {
+	File  string `json:"file"`
+	Name  string `json:"name"`
+	Line  int    `json:"line"`
+	Kind  string `json:"kind"`
+	Scope string `json:"scope"`
+	ID    string `json:"id,omitempty"`
+}
+
+// ReferenceEdge is one caller → callee edge.
+type ReferenceEdge struct {
+	SrcFile   string `json:"src_file"`
+	SrcSymbol string `json:"src_symbol"`
+	DstFile   string `json:"dst_file"`
+	DstSymbol string `json:"dst_symbol"`
+	SrcID     string `json:"src_id,omitempty"`
+	DstID     string `json:"dst_id,omitempty"`
+}
+
+// FileEdge is one importer → imported file edge.
+type FileEdge struct {
+	SrcFile string `json:"src_file"`
+	DstFile string `json:"dst_file"`
+}
+
+// IndexUpdate is the result of index_update.
+type IndexUpdate struct {
+	// Mode is unchanged, incremental, or full.
+	Mode         string   `json:"mode"`
+	Reparsed     []string `json:"reparsed"`
+	EdgesAdded   int      `json:"edges_added"`
+	EdgesRemoved int      `json:"edges_removed"`
+	Drift        []string `json:"drift"`
+	Project      string   `json:"project"`
+	Language     string   `json:"language"`
+	Files        int      `json:"files"`
+	EdgeCount    int      `json:"edge_count"`
+}
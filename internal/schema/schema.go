@@ -0,0 +1,211 @@
+// Package schema defines the results of the Python sidecar's analysis
+// commands as Go types. The JSON Schema for each command is derived from
+// its type, published as the output schema of the tools that return it,
+// and used to check every sidecar response, so a Python change that drops,
+// renames, or retypes a field is caught at the bridge instead of in a
+// client.
+//
+// Types map to JSON Schema as follows. A struct is an object whose
+// properties are its json-tagged fields; fields without omitempty are
+// required, and unlisted properties are allowed, so the sidecar can add
+// fields without breaking the schema. A pointer is its element or null, a
+// slice an array, a map[string]T an object of Ts, and an interface any JSON
+// value. A struct nested in itself, like an impact caller tree, is just an
+// object where it recurs; Validate still checks every level.
+package schema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// For returns the JSON Schema of values of type t.
+func For(t reflect.Type) map[string]any {
+	return forType(t, map[reflect.Type]bool{})
+}
+
+// forType is For, with outer the struct types being described.
+func forType(t reflect.Type, outer map[reflect.Type]bool) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := forType(t.Elem(), outer)
+		if typ, ok := s["type"].(string); ok {
+			s["type"] = []any{typ, "null"}
+		}
+		return s
+	case reflect.Struct:
+		if outer[t] {
+			return map[string]any{"type": "object"}
+		}
+		outer[t] = true
+		defer delete(outer, t)
+		props := map[string]any{}
+		required := []any{}
+		for _, f := range fields(t) {
+			props[f.name] = forType(f.typ, outer)
+			if !f.optional {
+				required = append(required, f.name)
+			}
+		}
+		s := map[string]any{"type": "object", "properties": props}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": forType(t.Elem(), outer)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": forType(t.Elem(), outer)}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{}
+}
+
+// field is one JSON property of a struct type.
+type field struct {
+	name     string
+	typ      reflect.Type
+	optional bool
+}
+
+// fields lists the JSON properties of struct type t in declaration order.
+func fields(t reflect.Type) []field {
+	var out []field
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		out = append(out, field{name: name, typ: f.Type, optional: strings.Contains(opts, "omitempty")})
+	}
+	return out
+}
+
+// maxProblems bounds how many mismatches Validate reports.
+const maxProblems = 10
+
+// Validate checks a decoded JSON value against the schema of type t and
+// describes each mismatch, as "path: problem", up to maxProblems.
+func Validate(v any, t reflect.Type) []string {
+	var c checker
+	c.check("$", v, t)
+	return c.problems
+}
+
+type checker struct {
+	problems []string
+}
+
+func (c *checker) fail(path, format string, args ...any) {
+	if len(c.problems) < maxProblems {
+		c.problems = append(c.problems, path+": "+fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *checker) check(path string, v any, t reflect.Type) {
+	if len(c.problems) >= maxProblems {
+		return
+	}
+	if t.Kind() == reflect.Interface {
+		return
+	}
+	if v == nil {
+		if t.Kind() != reflect.Pointer {
+			c.fail(path, "is null, want %s", jsonKind(t))
+		}
+		return
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		c.check(path, v, t.Elem())
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			c.fail(path, "is %s, want object", describe(v))
+			return
+		}
+		for _, f := range fields(t) {
+			fv, ok := m[f.name]
+			if !ok {
+				if !f.optional {
+					c.fail(path+"."+f.name, "missing")
+				}
+				continue
+			}
+			c.check(path+"."+f.name, fv, f.typ)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := v.([]any)
+		if !ok {
+			c.fail(path, "is %s, want array", describe(v))
+			return
+		}
+		for i, item := range items {
+			c.check(fmt.Sprintf("%s[%d]", path, i), item, t.Elem())
+		}
+	case reflect.Map:
+		m, ok := v.(map[string]any)
+		if !ok {
+			c.fail(path, "is %s, want object", describe(v))
+			return
+		}
+		for k, item := range m {
+			c.check(fmt.Sprintf("%s[%q]", path, k), item, t.Elem())
+		}
+	default:
+		if got, want := describe(v), jsonKind(t); got != want && !(want == "number" && got == "integer") {
+			c.fail(path, "is %s, want %s", got, want)
+		}
+	}
+}
+
+// jsonKind names the JSON type values of t take.
+func jsonKind(t reflect.Type) string {
+	s, _ := For(t)["type"].(string)
+	if s == "" {
+		return "any"
+	}
+	return s
+}
+
+// describe names the JSON type of a decoded value.
+func describe(v any) string {
+	switch n := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if n == math.Trunc(n) && !math.IsInf(n, 0) {
+			return "integer"
+		}
+		return "number"
+	case int, int64:
+		return "integer"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,180 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermap/analysis"
+)
+
+type node struct {
+	Name     string  `json:"name"`
+	Note     *string `json:"note"`
+	Children []node  `json:"children"`
+	Depth    int     `json:"depth,omitempty"`
+	Score    float64 `json:"score,omitempty"`
+}
+
+func TestFor(t *testing.T) {
+	s := For(reflect.TypeFor[node]())
+	if got := s["required"]; !reflect.DeepEqual(got, []any{"name", "note", "children"}) {
+		t.Errorf("required = %v", got)
+	}
+	props := s["properties"].(map[string]any)
+	if got := props["note"].(map[string]any)["type"]; !reflect.DeepEqual(got, []any{"string", "null"}) {
+		t.Errorf("pointer type = %v", got)
+	}
+	items := props["children"].(map[string]any)["items"]
+	if !reflect.DeepEqual(items, map[string]any{"type": "object"}) {
+		t.Errorf("recursive items = %v", items)
+	}
+	if got := For(reflect.TypeFor[map[string][]int]()); !reflect.DeepEqual(got, map[string]any{
+		"type":                 "object",
+		"additionalProperties": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+	}) {
+		t.Errorf("map schema = %v", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	var v any
+	json.Unmarshal([]byte(`{"name": "a", "note": null, "score": 3, "extra": true,
+		"children": [{"name": "b", "note": "x", "children": [{"name": 7, "children": []}]}]}`), &v)
+	got := Validate(v, reflect.TypeFor[node]())
+	want := []string{
+		"$.children[0].children[0].name: is integer, want string",
+		"$.children[0].children[0].note: missing",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate = %q, want %q", got, want)
+	}
+
+	json.Unmarshal([]byte(`{"name": "a", "note": null, "children": [], "depth": 1.5}`), &v)
+	if got := Validate(v, reflect.TypeFor[node]()); len(got) != 1 || got[0] != "$.depth: is number, want integer" {
+		t.Errorf("fractional int: %q", got)
+	}
+	if got := Validate([]any{}, reflect.TypeFor[node]()); len(got) != 1 || got[0] != "$: is array, want object" {
+		t.Errorf("array for object: %q", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, result := range []map[string]any{
+		{"skipped": true, "reason": "not in sparse checkout", "missing_path": "a", "checkout": map[string]any{}},
+		{"error": "Function 'x' not found in call graph"},
+		{"targets": map[string]any{}, "total_targets": 0.0},
+	} {
+		if err := Check("impact", result); err != nil {
+			t.Errorf("Check(%v) = %v", result, err)
+		}
+	}
+	if err := Check("no_such_command", map[string]any{}); err != nil {
+		t.Errorf("unknown command checked: %v", err)
+	}
+
+	err := Check("impact", map[string]any{"targets": map[string]any{"f": map[string]any{"function": "f"}}})
+	var mismatch *MismatchError
+	if !errors.As(err, &mismatch) || mismatch.Command != "impact" {
+		t.Fatalf("Check = %v, want *MismatchError", err)
+	}
+	if !slices.Contains(mismatch.Problems, "$.total_targets: missing") ||
+		!slices.Contains(mismatch.Problems, `$.targets["f"].callers: missing`) {
+		t.Errorf("problems = %q", mismatch.Problems)
+	}
+}
+
+func TestOutput(t *testing.T) {
+	var s struct {
+		Type  string           `json:"type"`
+		AnyOf []map[string]any `json:"anyOf"`
+	}
+	if err := json.Unmarshal(Output("structure", reflect.TypeFor[node]()), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Type != "object" || len(s.AnyOf) != 4 {
+		t.Errorf("Output = %+v", s)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Output of an unknown command did not panic")
+		}
+	}()
+	Output("no_such_command")
+}
+
+type backendFunc func(ctx context.Context, command, project string, args map[string]any) (map[string]any, error)
+
+func (f backendFunc) Run(ctx context.Context, command, project string, args map[string]any) (map[string]any, error) {
+	return f(ctx, command, project, args)
+}
+
+func TestChecked(t *testing.T) {
+	bad := backendFunc(func(context.Context, string, string, map[string]any) (map[string]any, error) {
+		return map[string]any{"root": "/w"}, nil
+	})
+
+	lenient := &Checked{Backend: bad}
+	if res, err := lenient.Run(context.Background(), "structure", "/w", nil); err != nil || res["root"] != "/w" {
+		t.Errorf("lenient Run = %v, %v", res, err)
+	}
+
+	strict := &Checked{Backend: bad, Strict: true}
+	_, err := strict.Run(context.Background(), "structure", "/w", nil)
+	var mismatch *MismatchError
+	if !errors.As(err, &mismatch) || !strings.Contains(err.Error(), "$.language: missing") {
+		t.Errorf("strict Run error = %v", err)
+	}
+	if _, err := strict.Run(context.Background(), "diagnostics", "/w", nil); err != nil {
+		t.Errorf("unchecked command: %v", err)
+	}
+}
+
+// TestSidecarResults runs commands on the real sidecar against this
+// repository and checks every result strictly, so a Python change that
+// breaks a schema fails here.
+func TestSidecarResults(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := filepath.Join(wd, "..", "..")
+	pyPath := filepath.Join(repo, "python")
+	if _, err := os.Stat(filepath.Join(pyPath, "intermap", "__main__.py")); err != nil {
+		t.Skipf("Python module not found at %s", pyPath)
+	}
+	bridge := analysis.NewPython(pyPath)
+	defer bridge.Close()
+	b := &Checked{Backend: bridge, Strict: true}
+
+	cases := []struct {
+		command, project string
+		args             map[string]any
+	}{
+		{"structure", pyPath, map[string]any{"language": "python", "max_results": 20}},
+		{"impact", pyPath, map[string]any{"target": "dispatch", "language": "python"}},
+		{"impact", pyPath, map[string]any{"target": "no_such_function", "language": "python"}},
+		{"doc_coverage", pyPath, map[string]any{"language": "python", "top": 5}},
+		{"api_surface", repo, map[string]any{"language": "go", "packages": []any{"registry"}}},
+		{"message_inventory", pyPath, map[string]any{"language": "python", "max_results": 20}},
+		{"reference_edges", pyPath, map[string]any{"language": "python", "max_files": 50}},
+		{"detect_patterns", repo, map[string]any{"language": "go"}},
+		{"live_changes", repo, map[string]any{"baseline": "HEAD"}},
+		{"describe_symbol", pyPath, map[string]any{"symbol": "dispatch"}},
+		{"effects_analysis", pyPath, map[string]any{"max_results": 20}},
+		{"error_flow", pyPath, map[string]any{"max_results": 20}},
+		{"taint_paths", pyPath, map[string]any{"max_results": 20}},
+		{"code_search", pyPath, map[string]any{"pattern": "def dispatch", "max_results": 5}},
+	}
+	for _, c := range cases {
+		if _, err := b.Run(context.Background(), c.command, c.project, c.args); err != nil {
+			t.Errorf("%s %v: %v", c.command, c.args, err)
+		}
+	}
+}
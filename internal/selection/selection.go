@@ -0,0 +1,291 @@
+// Package selection persists named sets of files and symbols ("the
+// auth-refactor scope") so multi-step agent workflows can pass a short,
+// stable handle between tool calls instead of re-sending long lists. Each
+// selection is a JSON file in the store's directory, so selections
+// outlive the server and are shared by every agent in the workspace.
+package selection
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors returned by the store, wrapped with the offending name or mode.
+var (
+	ErrNotFound = errors.New("selection not found")
+	ErrInvalid  = errors.New("invalid selection")
+	ErrBusy     = errors.New("selection is being written by another process")
+)
+
+// Writers serialize on a per-name lock file, created with O_EXCL so it
+// works across processes. A lock older than staleLock belongs to a writer
+// that died and is broken.
+const (
+	lockWait  = 10 * time.Second
+	staleLock = time.Minute
+	lockPoll  = 10 * time.Millisecond
+)
+
+// Selection is a named set of files and symbols.
+type Selection struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Project is the project Files are relative to, if any.
+	Project string    `json:"project,omitempty"`
+	Files   []string  `json:"files"`
+	Symbols []string  `json:"symbols"`
+	Updated time.Time `json:"updated"`
+}
+
+// Summary describes a selection without its contents.
+type Summary struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Project     string    `json:"project,omitempty"`
+	Files       int       `json:"files"`
+	Symbols     int       `json:"symbols"`
+	Updated     time.Time `json:"updated"`
+}
+
+// Summary returns sel's summary.
+func (sel *Selection) Summary() Summary {
+	return Summary{Name: sel.Name, Description: sel.Description, Project: sel.Project,
+		Files: len(sel.Files), Symbols: len(sel.Symbols), Updated: sel.Updated}
+}
+
+// Ways Save combines a selection with a stored one.
+const (
+	Replace = "replace"
+	Add     = "add"
+	Remove  = "remove"
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ValidName rejects names that are not 1-64 letters, digits, '.', '_',
+// or '-', starting with a letter or digit.
+func ValidName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("%w name %q: use up to 64 letters, digits, '.', '_', or '-'", ErrInvalid, name)
+	}
+	return nil
+}
+
+// Store keeps selections in a directory. It is safe for concurrent use,
+// including by several processes sharing the directory.
+type Store struct {
+	dir string
+	mu  sync.Mutex // serializes this process's writers before the lock file
+}
+
+// New returns a Store in dir, which is created on first save.
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+// lock takes the named selection's lock file, waiting up to lockWait for
+// another writer, and returns its release func.
+func (s *Store) lock(name string) (func(), error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	lock := filepath.Join(s.dir, "."+name+".lock")
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() {
+				os.Remove(lock)
+				s.mu.Unlock()
+			}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			s.mu.Unlock()
+			return nil, err
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			s.mu.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrBusy, name)
+		}
+		time.Sleep(lockPoll)
+	}
+}
+
+// Load returns the named selection.
+func (s *Store) Load(name string) (*Selection, error) {
+	if err := ValidName(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sel Selection
+	if err := json.Unmarshal(data, &sel); err != nil {
+		return nil, fmt.Errorf("selection %s: %w", name, err)
+	}
+	return &sel, nil
+}
+
+// Save stores sel. With Add or Remove, its files and symbols are added to
+// or removed from the stored selection of the same name, which must exist
+// for Remove; its description and project replace the stored ones when
+// set. Lists keep their first-seen order without duplicates. Save holds
+// the selection's lock from reading the stored one to replacing it, so
+// concurrent saves are applied one after another. It returns the stored
+// selection.
+func (s *Store) Save(sel Selection, mode string) (*Selection, error) {
+	if err := ValidName(sel.Name); err != nil {
+		return nil, err
+	}
+	if mode != "" && mode != Replace && mode != Add && mode != Remove {
+		return nil, fmt.Errorf("%w mode %q (want replace, add, or remove)", ErrInvalid, mode)
+	}
+	unlock, err := s.lock(sel.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	out := sel
+	switch mode {
+	case "", Replace:
+		out.Files, out.Symbols = union(nil, sel.Files), union(nil, sel.Symbols)
+	case Add, Remove:
+		old, err := s.Load(sel.Name)
+		if errors.Is(err, ErrNotFound) && mode == Add {
+			old, err = &Selection{Name: sel.Name}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = *old
+		if sel.Description != "" {
+			out.Description = sel.Description
+		}
+		if sel.Project != "" {
+			out.Project = sel.Project
+		}
+		if mode == Add {
+			out.Files, out.Symbols = union(old.Files, sel.Files), union(old.Symbols, sel.Symbols)
+		} else {
+			out.Files, out.Symbols = minus(old.Files, sel.Files), minus(old.Symbols, sel.Symbols)
+		}
+	}
+	out.Updated = time.Now().UTC()
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(s.dir, "."+sel.Name+"-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), s.path(sel.Name)); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Delete removes the named selection.
+func (s *Store) Delete(name string) error {
+	if err := ValidName(name); err != nil {
+		return err
+	}
+	if _, err := os.Stat(s.path(name)); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	unlock, err := s.lock(name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	err = os.Remove(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return err
+}
+
+// List summarizes the stored selections by name.
+func (s *Store) List() ([]Summary, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Summary{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := []Summary{}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || ValidName(name) != nil {
+			continue
+		}
+		sel, err := s.Load(name)
+		if err != nil {
+			continue
+		}
+		out = append(out, sel.Summary())
+	}
+	slices.SortFunc(out, func(a, b Summary) int { return strings.Compare(a.Name, b.Name) })
+	return out, nil
+}
+
+// union appends the items of b missing from a, keeping order. It never
+// returns nil.
+func union(a, b []string) []string {
+	out := make([]string, 0, len(a)+len(b))
+	seen := make(map[string]bool, len(a)+len(b))
+	for _, list := range [][]string{a, b} {
+		for _, v := range list {
+			if v != "" && !seen[v] {
+				seen[v] = true
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// minus returns a without the items of b.
+func minus(a, b []string) []string {
+	out := make([]string, 0, len(a))
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
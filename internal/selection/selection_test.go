@@ -0,0 +1,134 @@
+package selection
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	s := New(filepath.Join(t.TempDir(), "selections"))
+	if list, err := s.List(); err != nil || len(list) != 0 {
+		t.Fatalf("empty store: %v, %v", list, err)
+	}
+	if _, err := s.Load("auth"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing: %v", err)
+	}
+
+	sel, err := s.Save(Selection{Name: "auth", Description: "auth refactor scope", Project: "/src/app",
+		Files: []string{"auth/login.go", "auth/token.go", "auth/login.go"}, Symbols: []string{"Login"}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sel.Files, []string{"auth/login.go", "auth/token.go"}) || sel.Updated.IsZero() {
+		t.Errorf("saved %+v", sel)
+	}
+
+	sel, err = s.Save(Selection{Name: "auth", Files: []string{"auth/session.go", "auth/token.go"}, Symbols: []string{"Refresh"}}, Add)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sel.Files, []string{"auth/login.go", "auth/token.go", "auth/session.go"}) ||
+		!reflect.DeepEqual(sel.Symbols, []string{"Login", "Refresh"}) || sel.Description != "auth refactor scope" || sel.Project != "/src/app" {
+		t.Errorf("after add: %+v", sel)
+	}
+
+	if _, err := s.Save(Selection{Name: "auth", Files: []string{"auth/login.go"}, Symbols: []string{"Login"}}, Remove); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := s.Load("auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Files, []string{"auth/token.go", "auth/session.go"}) || !reflect.DeepEqual(loaded.Symbols, []string{"Refresh"}) {
+		t.Errorf("after remove: %+v", loaded)
+	}
+
+	if _, err := s.Save(Selection{Name: "ghost"}, Remove); !errors.Is(err, ErrNotFound) {
+		t.Errorf("remove from missing: %v", err)
+	}
+	if _, err := s.Save(Selection{Name: "new"}, Add); err != nil {
+		t.Errorf("add to missing: %v", err)
+	}
+	if _, err := s.Save(Selection{Name: "x"}, "merge"); err == nil {
+		t.Error("unknown mode accepted")
+	}
+
+	list, err := s.List()
+	if err != nil || len(list) != 2 || list[0].Name != "auth" || list[0].Files != 2 || list[1].Name != "new" {
+		t.Errorf("list = %+v, %v", list, err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(filepath.Dir(s.dir), "selections"))
+	if len(entries) != 2 {
+		t.Errorf("stray files: %v", entries)
+	}
+
+	if err := s.Delete("new"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("new"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("delete twice: %v", err)
+	}
+}
+
+func TestStore_ConcurrentAdds(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "selections")
+	// Separate stores share only the directory, as separate servers would.
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			file := fmt.Sprintf("f%d.go", i)
+			if _, err := New(dir).Save(Selection{Name: "scope", Files: []string{file}}, Add); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	sel, err := New(dir).Load("scope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sel.Files) != 20 {
+		t.Errorf("lost updates: %d of 20 files kept: %v", len(sel.Files), sel.Files)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("stray files: %v", entries)
+	}
+}
+
+func TestStore_BreaksStaleLock(t *testing.T) {
+	s := New(t.TempDir())
+	lock := filepath.Join(s.dir, ".scope.lock")
+	if err := os.WriteFile(lock, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLock)
+	os.Chtimes(lock, old, old)
+	if _, err := s.Save(Selection{Name: "scope", Files: []string{"a.go"}}, Add); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Errorf("lock left behind: %v", err)
+	}
+}
+
+func TestValidName(t *testing.T) {
+	for _, name := range []string{"auth-refactor", "v1.2_scope", "A"} {
+		if err := ValidName(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "../etc", "a/b", ".hidden", "x y"} {
+		if err := ValidName(name); err == nil {
+			t.Errorf("%q accepted", name)
+		}
+	}
+}
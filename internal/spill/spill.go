@@ -0,0 +1,277 @@
+// Package spill keeps oversized tool results out of MCP messages. A result
+// above the size threshold is written to a temporary file and replaced by a
+// summary and an intermap://result/{id} URI, which clients page through with
+// the fetch_result tool or read as an MCP resource.
+package spill
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// URIPrefix starts every spilled-result URI.
+const URIPrefix = "intermap://result/"
+
+// DefaultThreshold is the result size, in bytes, above which results spill.
+const DefaultThreshold = 256 << 10
+
+// maxEntries bounds how many spilled results are kept; older ones are
+// deleted first.
+const maxEntries = 64
+
+// Store holds spilled results in a private temp directory. The nil Store and
+// a Store with a non-positive threshold are disabled.
+type Store struct {
+	threshold int
+
+	mu    sync.Mutex
+	dir   string
+	order []string // ids, oldest first
+}
+
+// New returns a Store that spills results larger than threshold bytes.
+func New(threshold int) *Store {
+	return &Store{threshold: threshold}
+}
+
+// FromEnv returns a Store using INTERMAP_MAX_RESULT_BYTES as the threshold
+// (DefaultThreshold if unset; 0 disables spilling).
+func FromEnv() *Store {
+	threshold := DefaultThreshold
+	if v := os.Getenv("INTERMAP_MAX_RESULT_BYTES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			threshold = n
+		} else {
+			fmt.Fprintf(os.Stderr, "intermap: ignoring INTERMAP_MAX_RESULT_BYTES=%q: %v\n", v, err)
+		}
+	}
+	return New(threshold)
+}
+
+// Enabled reports whether results can spill.
+func (s *Store) Enabled() bool {
+	return s != nil && s.threshold > 0
+}
+
+// Threshold returns the spill threshold in bytes.
+func (s *Store) Threshold() int {
+	if s == nil {
+		return 0
+	}
+	return s.threshold
+}
+
+// Exceeds reports whether a result of n bytes should spill.
+func (s *Store) Exceeds(n int) bool {
+	return s.Enabled() && n > s.threshold
+}
+
+// Put stores data and returns its id.
+func (s *Store) Put(data []byte) (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("spill id: %w", err)
+	}
+	id := hex.EncodeToString(b[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dir == "" {
+		dir, err := os.MkdirTemp("", "intermap-results-")
+		if err != nil {
+			return "", fmt.Errorf("spill dir: %w", err)
+		}
+		s.dir = dir
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, id+".json"), data, 0o600); err != nil {
+		return "", fmt.Errorf("spill write: %w", err)
+	}
+	s.order = append(s.order, id)
+	for len(s.order) > maxEntries {
+		os.Remove(filepath.Join(s.dir, s.order[0]+".json"))
+		s.order = s.order[1:]
+	}
+	return id, nil
+}
+
+// Get returns a stored result by id.
+func (s *Store) Get(id string) ([]byte, error) {
+	if _, err := hex.DecodeString(id); err != nil || id == "" {
+		return nil, fmt.Errorf("invalid result id %q", id)
+	}
+	s.mu.Lock()
+	dir := s.dir
+	s.mu.Unlock()
+	if dir == "" {
+		return nil, fmt.Errorf("result %s not found", id)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, id+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("result %s not found (results are kept for this server session only)", id)
+	}
+	return data, nil
+}
+
+// Close deletes all stored results.
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dir == "" {
+		return nil
+	}
+	err := os.RemoveAll(s.dir)
+	s.dir, s.order = "", nil
+	return err
+}
+
+// URI returns the resource URI for id.
+func URI(id string) string {
+	return URIPrefix + id
+}
+
+// ParseURI extracts the id from a result URI; a bare id is accepted too.
+func ParseURI(uri string) string {
+	return strings.TrimPrefix(uri, URIPrefix)
+}
+
+// Summarize describes the shape of a JSON document in place of its content:
+// top-level scalars are kept, arrays become {"count": n} and objects
+// {"keys": [...]}.
+func Summarize(data []byte) any {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return shape(v)
+	}
+	out := make(map[string]any, len(obj))
+	for k, item := range obj {
+		out[k] = shape(item)
+	}
+	return out
+}
+
+func shape(v any) any {
+	switch v := v.(type) {
+	case []any:
+		return map[string]any{"count": len(v)}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if len(keys) > 20 {
+			return map[string]any{"key_count": len(keys)}
+		}
+		return map[string]any{"keys": keys}
+	case string:
+		if len(v) > 200 {
+			return map[string]any{"length": len(v)}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// Page is one slice of a spilled result.
+type Page struct {
+	URI  string `json:"uri"`
+	Path string `json:"path,omitempty"`
+	// Offset and Total count bytes of the raw document, or items of the
+	// array at Path.
+	Offset  int  `json:"offset"`
+	Total   int  `json:"total"`
+	HasMore bool `json:"has_more"`
+	// NextOffset is set when HasMore.
+	NextOffset int    `json:"next_offset,omitempty"`
+	Items      []any  `json:"items,omitempty"`
+	Value      any    `json:"value,omitempty"`
+	Text       string `json:"text,omitempty"`
+}
+
+// Slice returns part of a document. With an empty path it returns up to
+// limit bytes of raw text from offset (cut at a UTF-8 boundary). Otherwise
+// path is a dot-separated key/index path: an array there is paged by item,
+// anything else is returned whole.
+func Slice(data []byte, path string, offset, limit int) (*Page, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	p := &Page{Path: path, Offset: offset}
+	if path == "" {
+		p.Total = len(data)
+		if offset > len(data) {
+			offset = len(data)
+		}
+		end := offset + limit
+		if end >= len(data) {
+			end = len(data)
+		} else {
+			for end > offset && !utf8.RuneStart(data[end]) {
+				end--
+			}
+		}
+		p.Text = string(data[offset:end])
+		if end < len(data) {
+			p.HasMore, p.NextOffset = true, end
+		}
+		return p, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("decode result: %w", err)
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			item, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("path %q: no key %q", path, key)
+			}
+			v = item
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("path %q: bad index %q", path, key)
+			}
+			v = node[i]
+		default:
+			return nil, fmt.Errorf("path %q: %q is not an object or array", path, key)
+		}
+	}
+	items, ok := v.([]any)
+	if !ok {
+		p.Value = v
+		return p, nil
+	}
+	p.Total = len(items)
+	if offset > len(items) {
+		offset = len(items)
+	}
+	end := min(offset+limit, len(items))
+	p.Items = items[offset:end]
+	if end < len(items) {
+		p.HasMore, p.NextOffset = true, end
+	}
+	return p, nil
+}
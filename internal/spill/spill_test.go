@@ -0,0 +1,105 @@
+package spill
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	s := New(10)
+	defer s.Close()
+	if !s.Exceeds(11) || s.Exceeds(10) {
+		t.Error("Exceeds should compare against the threshold")
+	}
+
+	id, err := s.Put([]byte(`{"a":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ParseURI(URI(id)); got != id {
+		t.Errorf("ParseURI(URI(%q)) = %q", id, got)
+	}
+	data, err := s.Get(id)
+	if err != nil || string(data) != `{"a":1}` {
+		t.Errorf("Get = %s, %v", data, err)
+	}
+	if _, err := s.Get("../etc/passwd"); err == nil {
+		t.Error("Get should reject non-hex ids")
+	}
+
+	dir := s.dir
+	s.Close()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Close left %s behind", dir)
+	}
+	if _, err := s.Get(id); err == nil {
+		t.Error("Get after Close should fail")
+	}
+}
+
+func TestStore_Disabled(t *testing.T) {
+	var nilS *Store
+	if nilS.Enabled() || nilS.Exceeds(1<<30) || New(0).Exceeds(1<<30) {
+		t.Error("nil and zero-threshold stores should never spill")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	got := Summarize([]byte(`{"count":3,"items":[1,2,3],"meta":{"b":1,"a":2},"name":"x"}`))
+	out, _ := json.Marshal(got)
+	want := `{"count":3,"items":{"count":3},"meta":{"keys":["a","b"]},"name":"x"}`
+	if string(out) != want {
+		t.Errorf("Summarize = %s, want %s", out, want)
+	}
+}
+
+func TestSlice_Bytes(t *testing.T) {
+	data := []byte(`{"s":"héllo"}`)
+	var text strings.Builder
+	offset := 0
+	for {
+		p, err := Slice(data, "", offset, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text.WriteString(p.Text)
+		if !p.HasMore {
+			break
+		}
+		offset = p.NextOffset
+	}
+	if text.String() != string(data) {
+		t.Errorf("reassembled %q, want %q", text.String(), data)
+	}
+
+	// Byte 8 falls inside "é"; the page must stop before it.
+	p, _ := Slice(data, "", 0, 8)
+	if p.Text != `{"s":"h` || p.NextOffset != 7 {
+		t.Errorf("page = %q next %d", p.Text, p.NextOffset)
+	}
+}
+
+func TestSlice_Path(t *testing.T) {
+	data := []byte(`{"projects":[{"files":["a","b","c"]}],"n":7}`)
+
+	p, err := Slice(data, "projects.0.files", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Total != 3 || len(p.Items) != 1 || p.Items[0] != "b" || !p.HasMore || p.NextOffset != 2 {
+		t.Errorf("page = %+v", p)
+	}
+
+	p, err = Slice(data, "n", 0, 10)
+	if err != nil || p.Value != json.Number("7") {
+		t.Errorf("scalar page = %+v, %v", p, err)
+	}
+
+	for _, bad := range []string{"missing", "projects.5", "n.x"} {
+		if _, err := Slice(data, bad, 0, 10); err == nil {
+			t.Errorf("Slice(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+// Package stats computes per-project health metrics for the workspace
+// dashboard: lines of code by language, test-to-code ratio, manifest
+// dependency counts, and last-commit recency.
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProjectStats holds metrics for one project.
+type ProjectStats struct {
+	Name            string         `json:"name"`
+	Path            string         `json:"path"`
+	Group           string         `json:"group"`
+	Language        string         `json:"language"`
+	Files           int            `json:"files"`
+	LOC             int            `json:"loc"`
+	TestLOC         int            `json:"test_loc"`
+	TestRatio       float64        `json:"test_ratio"`
+	Languages       map[string]int `json:"languages"`
+	Dependencies    int            `json:"dependencies"`
+	LastCommit      string         `json:"last_commit,omitempty"`
+	DaysSinceCommit int            `json:"days_since_commit"`
+}
+
+// extLanguages maps source extensions to the language counted in LOC.
+var extLanguages = map[string]string{
+	".go": "go", ".py": "python", ".ts": "typescript", ".tsx": "typescript",
+	".js": "javascript", ".jsx": "javascript", ".rs": "rust", ".java": "java",
+	".c": "c", ".h": "c", ".cpp": "cpp", ".hpp": "cpp", ".rb": "ruby",
+	".php": "php", ".kt": "kotlin", ".swift": "swift", ".cs": "csharp",
+	".scala": "scala", ".lua": "lua", ".ex": "elixir", ".exs": "elixir",
+	".sh": "shell",
+}
+
+// skipDirs are never descended into.
+var skipDirs = map[string]bool{
+	"vendor": true, "node_modules": true, "__pycache__": true, "venv": true,
+	"target": true, "dist": true, "build": true,
+}
+
+// Collect computes stats for the project rooted at path. now is injected so
+// recency is testable.
+func Collect(name, path, group, language string, now time.Time) ProjectStats {
+	s := ProjectStats{
+		Name:            name,
+		Path:            path,
+		Group:           group,
+		Language:        language,
+		Languages:       make(map[string]int),
+		DaysSinceCommit: -1,
+	}
+
+	filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != path && (strings.HasPrefix(name, ".") || skipDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		lang, ok := extLanguages[strings.ToLower(filepath.Ext(name))]
+		if !ok {
+			return nil
+		}
+		n := countLines(p)
+		s.Files++
+		s.LOC += n
+		s.Languages[lang] += n
+		rel, _ := filepath.Rel(path, p)
+		if IsTestFile(rel) {
+			s.TestLOC += n
+		}
+		return nil
+	})
+
+	if code := s.LOC - s.TestLOC; code > 0 {
+		s.TestRatio = float64(s.TestLOC) / float64(code)
+	}
+	s.Dependencies = countDependencies(path)
+
+	if ts := lastCommitTime(path); !ts.IsZero() {
+		s.LastCommit = ts.UTC().Format(time.RFC3339)
+		s.DaysSinceCommit = int(now.Sub(ts).Hours() / 24)
+	}
+	return s
+}
+
+// FileCounts summarizes a project's files by extension.
+type FileCounts struct {
+	Files      int                       `json:"files"`
+	LOC        int                       `json:"loc"`
+	TestFiles  int                       `json:"test_files"`
+	TestLOC    int                       `json:"test_loc"`
+	Extensions map[string]ExtensionCount `json:"extensions"`
+}
+
+// ExtensionCount is the file count and non-blank lines for one extension.
+type ExtensionCount struct {
+	Files int `json:"files"`
+	LOC   int `json:"loc"`
+}
+
+// maxCountedFileSize skips line counting for larger files, which are
+// almost always generated or data.
+const maxCountedFileSize = 4 << 20
+
+// CountFiles tallies every file under path with an extension, skipping the
+// same directories as Collect. Lines are counted for text files up to
+// 4 MiB; binary files count as files with no lines.
+func CountFiles(path string) FileCounts {
+	c := FileCounts{Extensions: make(map[string]ExtensionCount)}
+	filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != path && (strings.HasPrefix(name, ".") || skipDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(name))
+		if !d.Type().IsRegular() || ext == "" || ext == name {
+			return nil
+		}
+		n := 0
+		if info, err := d.Info(); err == nil && info.Size() <= maxCountedFileSize {
+			n = countTextLines(p)
+		}
+		e := c.Extensions[ext]
+		e.Files++
+		e.LOC += n
+		c.Extensions[ext] = e
+		c.Files++
+		c.LOC += n
+		rel, _ := filepath.Rel(path, p)
+		if IsTestFile(rel) {
+			c.TestFiles++
+			c.TestLOC += n
+		}
+		return nil
+	})
+	return c
+}
+
+// IsTestFile reports whether a project-relative path looks like a test file
+// under the common conventions of the supported languages.
+func IsTestFile(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	base := filepath.Base(rel)
+	switch {
+	case strings.HasSuffix(base, "_test.go"),
+		strings.HasPrefix(base, "test_") && strings.HasSuffix(base, ".py"),
+		strings.HasSuffix(base, "_test.py"),
+		strings.Contains(base, ".test."),
+		strings.Contains(base, ".spec."),
+		strings.HasSuffix(base, "Test.java"),
+		strings.HasSuffix(base, "_spec.rb"):
+		return true
+	}
+	for _, dir := range strings.Split(filepath.Dir(rel), "/") {
+		if dir == "tests" || dir == "test" || dir == "__tests__" || dir == "spec" {
+			return true
+		}
+	}
+	return false
+}
+
+func countLines(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	n := 0
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		if len(bytes.TrimSpace(sc.Bytes())) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// countTextLines is countLines for files that do not look binary (a NUL
+// byte in the first 8 KiB).
+func countTextLines(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	head := make([]byte, 8<<10)
+	n, _ := f.Read(head)
+	f.Close()
+	if bytes.IndexByte(head[:n], 0) >= 0 {
+		return 0
+	}
+	return countLines(path)
+}
+
+var (
+	goRequireLine = regexp.MustCompile(`^\s*require\s+\S+\s+v`)
+	goBlockLine   = regexp.MustCompile(`^\s*[\w./-]+\s+v\S+`)
+	tomlDepHeader = regexp.MustCompile(`^\[(.+\.)?(dependencies|dev-dependencies)\]$`)
+)
+
+// countDependencies counts direct dependencies declared in the project's
+// manifests (go.mod, package.json, Cargo.toml, requirements.txt, pyproject.toml).
+func countDependencies(path string) int {
+	total := 0
+
+	if data, err := os.ReadFile(filepath.Join(path, "go.mod")); err == nil {
+		inBlock := false
+		for _, line := range strings.Split(string(data), "\n") {
+			t := strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(t, "require ("):
+				inBlock = true
+			case inBlock && t == ")":
+				inBlock = false
+			case inBlock && goBlockLine.MatchString(t) && !strings.Contains(t, "// indirect"):
+				total++
+			case goRequireLine.MatchString(t) && !strings.Contains(t, "// indirect"):
+				total++
+			}
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(path, "package.json")); err == nil {
+		var pkg struct {
+			Dependencies    map[string]any `json:"dependencies"`
+			DevDependencies map[string]any `json:"devDependencies"`
+		}
+		if json.Unmarshal(data, &pkg) == nil {
+			total += len(pkg.Dependencies) + len(pkg.DevDependencies)
+		}
+	}
+
+	for _, manifest := range []string{"Cargo.toml", "pyproject.toml"} {
+		data, err := os.ReadFile(filepath.Join(path, manifest))
+		if err != nil {
+			continue
+		}
+		inDeps := false
+		for _, line := range strings.Split(string(data), "\n") {
+			t := strings.TrimSpace(line)
+			if strings.HasPrefix(t, "[") {
+				inDeps = tomlDepHeader.MatchString(t)
+				continue
+			}
+			if inDeps && t != "" && !strings.HasPrefix(t, "#") && strings.Contains(t, "=") && !strings.HasPrefix(t, "python") {
+				total++
+			}
+		}
+		if manifest == "pyproject.toml" {
+			total += countPEP621Dependencies(string(data))
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(path, "requirements.txt")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			t := strings.TrimSpace(line)
+			if t != "" && !strings.HasPrefix(t, "#") && !strings.HasPrefix(t, "-") {
+				total++
+			}
+		}
+	}
+	return total
+}
+
+// countPEP621Dependencies counts entries in a pyproject `dependencies = [...]` array.
+func countPEP621Dependencies(data string) int {
+	idx := strings.Index(data, "\ndependencies = [")
+	if idx < 0 {
+		return 0
+	}
+	rest := data[idx+len("\ndependencies = ["):]
+	end := strings.Index(rest, "]")
+	if end < 0 {
+		return 0
+	}
+	n := 0
+	for _, item := range strings.Split(rest[:end], ",") {
+		if strings.TrimSpace(item) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+func lastCommitTime(path string) time.Time {
+	out, err := exec.Command("git", "-C", path, "log", "-1", "--format=%ct").Output()
+	if err != nil {
+		return time.Time{}
+	}
+	sec, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
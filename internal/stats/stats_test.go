@@ -0,0 +1,95 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "go.mod"), "module x\n\ngo 1.23\n\nrequire (\n\tgithub.com/a/b v1.0.0\n\tgithub.com/c/d v1.0.0 // indirect\n)\n")
+	write(t, filepath.Join(dir, "main.go"), "package main\n\nfunc main() {\n}\n")
+	write(t, filepath.Join(dir, "main_test.go"), "package main\n\nimport \"testing\"\n")
+	write(t, filepath.Join(dir, "scripts", "run.py"), "print(1)\n")
+	write(t, filepath.Join(dir, "node_modules", "dep.js"), "ignored()\n")
+
+	s := Collect("x", dir, "g", "go", time.Now())
+	if s.Files != 3 {
+		t.Errorf("expected 3 files, got %d", s.Files)
+	}
+	if s.LOC != 6 {
+		t.Errorf("expected 6 non-blank lines, got %d", s.LOC)
+	}
+	if s.TestLOC != 2 {
+		t.Errorf("expected 2 test lines, got %d", s.TestLOC)
+	}
+	if s.Languages["go"] != 5 || s.Languages["python"] != 1 {
+		t.Errorf("unexpected language breakdown: %v", s.Languages)
+	}
+	if s.Dependencies != 1 {
+		t.Errorf("expected 1 direct dependency, got %d", s.Dependencies)
+	}
+	if s.DaysSinceCommit != -1 {
+		t.Errorf("expected -1 days for non-git dir, got %d", s.DaysSinceCommit)
+	}
+}
+
+func TestCountFiles(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "main.go"), "package main\n\nfunc main() {\n}\n")
+	write(t, filepath.Join(dir, "main_test.go"), "package main\n")
+	write(t, filepath.Join(dir, "README.MD"), "# x\n\ntext\n")
+	write(t, filepath.Join(dir, "logo.png"), "\x89PNG\x00\x00\nmore\n")
+	write(t, filepath.Join(dir, "Makefile"), "all:\n")
+	write(t, filepath.Join(dir, ".env"), "A=1\n")
+	write(t, filepath.Join(dir, "vendor", "dep.go"), "package dep\n")
+
+	c := CountFiles(dir)
+	if c.Files != 4 || c.LOC != 6 || c.TestFiles != 1 || c.TestLOC != 1 {
+		t.Errorf("totals = %+v", c)
+	}
+	want := map[string]ExtensionCount{".go": {Files: 2, LOC: 4}, ".md": {Files: 1, LOC: 2}, ".png": {Files: 1}}
+	if len(c.Extensions) != len(want) {
+		t.Fatalf("extensions = %v", c.Extensions)
+	}
+	for ext, w := range want {
+		if c.Extensions[ext] != w {
+			t.Errorf("%s = %+v, want %+v", ext, c.Extensions[ext], w)
+		}
+	}
+}
+
+func TestIsTestFile(t *testing.T) {
+	for path, want := range map[string]bool{
+		"pkg/foo_test.go":       true,
+		"tests/helpers.py":      true,
+		"src/app.spec.ts":       true,
+		"python/test_x.py":      true,
+		"pkg/foo.go":            false,
+		"src/contest/winner.py": false,
+	} {
+		if got := IsTestFile(path); got != want {
+			t.Errorf("IsTestFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestCountDependencies_PackageJSON(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "package.json"), `{"dependencies":{"a":"1"},"devDependencies":{"b":"1","c":"1"}}`)
+	if got := countDependencies(dir); got != 3 {
+		t.Errorf("expected 3, got %d", got)
+	}
+}
@@ -0,0 +1,332 @@
+// Package targets inventories the build targets a project declares in a
+// Makefile, Taskfile, or justfile, with their commands and dependencies.
+//
+// Parsing is line-based and approximate: Make variables are not expanded,
+// includes are not followed, and pattern rules are skipped.
+package targets
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Runners.
+const (
+	RunnerMake = "make"
+	RunnerTask = "task"
+	RunnerJust = "just"
+)
+
+// Target is one named target.
+type Target struct {
+	Name        string   `json:"name"`
+	Runner      string   `json:"runner"`
+	File        string   `json:"file"`
+	Description string   `json:"description,omitempty"`
+	Deps        []string `json:"deps,omitempty"`
+	Commands    []string `json:"commands,omitempty"`
+	// Run is the command line that invokes the target from the project root.
+	Run string `json:"run"`
+}
+
+// manifests lists the recognized files per runner, in lookup order. Only the
+// first existing file of each runner is read, as the tools themselves do.
+var manifests = []struct {
+	runner string
+	names  []string
+	parse  func(data string) []Target
+}{
+	{RunnerMake, []string{"GNUmakefile", "makefile", "Makefile"}, parseMakefile},
+	{RunnerTask, []string{"Taskfile.yml", "Taskfile.yaml", "taskfile.yml", "taskfile.yaml"}, parseTaskfile},
+	{RunnerJust, []string{"justfile", "Justfile", ".justfile"}, parseJustfile},
+}
+
+// Scan returns the targets declared at the root of project, grouped by
+// runner and in file order within each.
+func Scan(project string) []Target {
+	var out []Target
+	for _, m := range manifests {
+		for _, name := range m.names {
+			data, err := os.ReadFile(filepath.Join(project, name))
+			if err != nil {
+				continue
+			}
+			for _, t := range m.parse(string(data)) {
+				t.Runner = m.runner
+				t.File = name
+				t.Run = m.runner + " " + t.Name
+				out = append(out, t)
+			}
+			break
+		}
+	}
+	return out
+}
+
+// --- Make ---
+
+// splitMakeRule splits a rule line into its targets and the text after the
+// colon. Variable assignments (including := and ::=) are not rules.
+func splitMakeRule(line string) (targets, rest string, ok bool) {
+	i := strings.IndexByte(line, ':')
+	if i <= 0 || strings.ContainsAny(line[:i], "=#") {
+		return "", "", false
+	}
+	rest = line[i+1:]
+	if strings.HasPrefix(rest, ":") { // double-colon rule
+		rest = rest[1:]
+	}
+	if strings.HasPrefix(rest, "=") {
+		return "", "", false
+	}
+	return line[:i], rest, true
+}
+
+func parseMakefile(data string) []Target {
+	var out []Target
+	index := make(map[string]int)
+	var current []int // targets of the rule whose recipe is being read
+	comment := ""
+	lines := strings.Split(strings.ReplaceAll(data, "\\\n", " "), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "\t") {
+			cmd := strings.TrimSpace(line)
+			if cmd != "" && !strings.HasPrefix(cmd, "#") {
+				for _, i := range current {
+					out[i].Commands = append(out[i].Commands, cmd)
+				}
+			}
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") {
+			comment = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			continue
+		}
+		current = nil
+		names, rest, ok := splitMakeRule(line)
+		if !ok || line[0] == ' ' {
+			comment = ""
+			continue
+		}
+		desc := comment
+		comment = ""
+		// Self-documenting convention: "target: deps ## description".
+		if i := strings.Index(rest, "##"); i >= 0 {
+			desc = strings.TrimSpace(rest[i+2:])
+			rest = rest[:i]
+		} else if i := strings.Index(rest, "#"); i >= 0 {
+			rest = rest[:i]
+		}
+		// Inline recipe: "target: deps ; command".
+		var inline string
+		if i := strings.Index(rest, ";"); i >= 0 {
+			inline = strings.TrimSpace(rest[i+1:])
+			rest = rest[:i]
+		}
+		var deps []string
+		for _, d := range strings.Fields(strings.ReplaceAll(rest, "|", " ")) {
+			if !strings.Contains(d, "$") {
+				deps = append(deps, d)
+			}
+		}
+		for _, name := range strings.Fields(names) {
+			if strings.HasPrefix(name, ".") || strings.Contains(name, "%") || strings.Contains(name, "$") {
+				continue
+			}
+			i, ok := index[name]
+			if !ok {
+				i = len(out)
+				index[name] = i
+				out = append(out, Target{Name: name})
+			}
+			out[i].Deps = appendUnique(out[i].Deps, deps...)
+			if desc != "" {
+				out[i].Description = desc
+			}
+			if inline != "" {
+				out[i].Commands = append(out[i].Commands, inline)
+			}
+			current = append(current, i)
+		}
+	}
+	return out
+}
+
+// --- Task ---
+
+// taskfileTask is the subset of a go-task task definition read here. A task
+// may also be written as a bare command string or list of commands.
+type taskfileTask struct {
+	Desc    string `yaml:"desc"`
+	Summary string `yaml:"summary"`
+	Cmds    []any  `yaml:"cmds"`
+	Cmd     string `yaml:"cmd"`
+	Deps    []any  `yaml:"deps"`
+}
+
+func parseTaskfile(data string) []Target {
+	var doc struct {
+		Tasks yaml.Node `yaml:"tasks"`
+	}
+	if yaml.Unmarshal([]byte(data), &doc) != nil || doc.Tasks.Kind != yaml.MappingNode {
+		return nil
+	}
+	var out []Target
+	// Walk the mapping node directly to keep declaration order.
+	for i := 0; i+1 < len(doc.Tasks.Content); i += 2 {
+		name := doc.Tasks.Content[i].Value
+		val := doc.Tasks.Content[i+1]
+		var task taskfileTask
+		switch val.Kind {
+		case yaml.ScalarNode:
+			task.Cmd = val.Value
+		case yaml.SequenceNode:
+			val.Decode(&task.Cmds)
+		default:
+			if val.Decode(&task) != nil {
+				continue
+			}
+		}
+		t := Target{Name: name, Description: task.Desc}
+		if t.Description == "" {
+			t.Description = firstLine(task.Summary)
+		}
+		if task.Cmd != "" {
+			t.Commands = append(t.Commands, task.Cmd)
+		}
+		for _, c := range task.Cmds {
+			switch c := c.(type) {
+			case string:
+				t.Commands = append(t.Commands, c)
+			case map[string]any:
+				if cmd, ok := c["cmd"].(string); ok {
+					t.Commands = append(t.Commands, cmd)
+				} else if sub, ok := c["task"].(string); ok {
+					// Calling another task is a dependency run in sequence.
+					t.Commands = append(t.Commands, "task "+sub)
+					t.Deps = appendUnique(t.Deps, sub)
+				}
+			}
+		}
+		for _, d := range task.Deps {
+			switch d := d.(type) {
+			case string:
+				t.Deps = appendUnique(t.Deps, d)
+			case map[string]any:
+				if sub, ok := d["task"].(string); ok {
+					t.Deps = appendUnique(t.Deps, sub)
+				}
+			}
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// --- just ---
+
+var (
+	justRecipe = regexp.MustCompile(`^@?([A-Za-z_][\w-]*)((?:\s+[^:]*?)?):(.*)$`)
+	justDepRef = regexp.MustCompile(`\(?\s*([A-Za-z_][\w-]*)`)
+)
+
+func parseJustfile(data string) []Target {
+	var out []Target
+	current := -1
+	var comment []string
+	for _, line := range strings.Split(data, "\n") {
+		if line != "" && (line[0] == ' ' || line[0] == '\t') {
+			cmd := strings.TrimSpace(line)
+			if current >= 0 && cmd != "" && !strings.HasPrefix(cmd, "#") {
+				out[current].Commands = append(out[current].Commands, cmd)
+			}
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			comment = append(comment, strings.TrimSpace(strings.TrimLeft(trimmed, "#")))
+			continue
+		case strings.HasPrefix(trimmed, "["):
+			// Attributes like [private] sit between a doc comment and its
+			// recipe.
+			continue
+		}
+		current = -1
+		m := justRecipe.FindStringSubmatch(trimmed)
+		if m == nil || strings.HasPrefix(m[3], "=") || isJustKeyword(m[1]) {
+			comment = nil
+			continue
+		}
+		t := Target{Name: m[1]}
+		if len(comment) > 0 {
+			t.Description = comment[len(comment)-1]
+		}
+		comment = nil
+		// Dependencies: "recipe: a b (c 'arg')", optionally followed by
+		// "&& d" for subsequent recipes.
+		for _, part := range strings.Split(m[3], "&&") {
+			for _, d := range justDepRef.FindAllStringSubmatch(stripQuoted(part), -1) {
+				t.Deps = appendUnique(t.Deps, d[1])
+			}
+		}
+		current = len(out)
+		out = append(out, t)
+	}
+	return out
+}
+
+func isJustKeyword(word string) bool {
+	switch word {
+	case "set", "alias", "export", "import", "mod":
+		return true
+	}
+	return false
+}
+
+// stripQuoted removes quoted arguments so they are not read as recipe names.
+func stripQuoted(s string) string {
+	var b strings.Builder
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// --- helpers ---
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, have := range list {
+			if have == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func firstLine(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	return s
+}
@@ -0,0 +1,126 @@
+package targets
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func find(t *testing.T, ts []Target, runner, name string) Target {
+	t.Helper()
+	for _, tg := range ts {
+		if tg.Runner == runner && tg.Name == name {
+			return tg
+		}
+	}
+	t.Fatalf("no %s target %q in %+v", runner, name, ts)
+	return Target{}
+}
+
+const makefile = `GO ?= go
+BIN := bin/app
+
+.PHONY: build test
+
+# Build the binary.
+build: gen $(BIN)
+	$(GO) build -o $(BIN) ./cmd/app
+
+test: build ## Run unit tests
+	$(GO) test ./...
+	# not a command
+
+%.o: %.c
+	cc -c $<
+
+lint fmt: ; golangci-lint run
+`
+
+const taskfile = `version: '3'
+tasks:
+  build:
+    desc: Build it
+    deps: [gen]
+    cmds:
+      - go build ./...
+      - task: vet
+  gen: go generate ./...
+  vet:
+    summary: |
+      Vet the code.
+      More detail.
+    cmds: [go vet ./...]
+`
+
+const justfile = `set shell := ["bash", "-c"]
+version := "1.0"
+
+# Run the tests
+[group('ci')]
+test filter="": build (lint "strict") && report
+    go test -run '{{filter}}' ./...
+
+build:
+    go build ./...
+
+alias t := test
+`
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{"Makefile": makefile, "Taskfile.yml": taskfile, "justfile": justfile} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ts := Scan(dir)
+
+	build := find(t, ts, RunnerMake, "build")
+	if build.Description != "Build the binary." || !reflect.DeepEqual(build.Deps, []string{"gen"}) {
+		t.Errorf("make build = %+v", build)
+	}
+	if !reflect.DeepEqual(build.Commands, []string{"$(GO) build -o $(BIN) ./cmd/app"}) {
+		t.Errorf("make build commands = %v", build.Commands)
+	}
+	test := find(t, ts, RunnerMake, "test")
+	if test.Description != "Run unit tests" || len(test.Commands) != 1 || test.Run != "make test" {
+		t.Errorf("make test = %+v", test)
+	}
+	if lint := find(t, ts, RunnerMake, "fmt"); !reflect.DeepEqual(lint.Commands, []string{"golangci-lint run"}) {
+		t.Errorf("make fmt = %+v", lint)
+	}
+	for _, tg := range ts {
+		if tg.Runner == RunnerMake && (tg.Name == "GO" || tg.Name == "BIN" || tg.Name == "%.o" || tg.Name == ".PHONY") {
+			t.Errorf("unexpected make target %q", tg.Name)
+		}
+	}
+
+	tb := find(t, ts, RunnerTask, "build")
+	if !reflect.DeepEqual(tb.Deps, []string{"vet", "gen"}) || !reflect.DeepEqual(tb.Commands, []string{"go build ./...", "task vet"}) {
+		t.Errorf("task build = %+v", tb)
+	}
+	if gen := find(t, ts, RunnerTask, "gen"); !reflect.DeepEqual(gen.Commands, []string{"go generate ./..."}) {
+		t.Errorf("task gen = %+v", gen)
+	}
+	if vet := find(t, ts, RunnerTask, "vet"); vet.Description != "Vet the code." {
+		t.Errorf("task vet = %+v", vet)
+	}
+
+	jt := find(t, ts, RunnerJust, "test")
+	if jt.Description != "Run the tests" || !reflect.DeepEqual(jt.Deps, []string{"build", "lint", "report"}) {
+		t.Errorf("just test = %+v", jt)
+	}
+	if len(jt.Commands) != 1 {
+		t.Errorf("just test commands = %v", jt.Commands)
+	}
+	var justNames []string
+	for _, tg := range ts {
+		if tg.Runner == RunnerJust {
+			justNames = append(justNames, tg.Name)
+		}
+	}
+	if !reflect.DeepEqual(justNames, []string{"test", "build"}) {
+		t.Errorf("just targets = %v", justNames)
+	}
+}
@@ -0,0 +1,264 @@
+// Package toolchain inventories the language versions projects require —
+// go.mod's go and toolchain lines, Python's requires-python, Node's
+// engines, Rust's edition and rust-version, and the pin files version
+// managers read — and checks them against the toolchains installed
+// locally.
+package toolchain
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Languages.
+const (
+	Go     = "go"
+	Python = "python"
+	Node   = "node"
+	Rust   = "rust"
+)
+
+// Check statuses.
+const (
+	StatusOK       = "ok"
+	StatusConflict = "conflict"
+	// StatusMissing means the toolchain is not installed.
+	StatusMissing = "missing"
+	// StatusUnknown means the constraint could not be evaluated, such as
+	// an .nvmrc naming "lts/*" or a "nightly" Rust channel.
+	StatusUnknown = "unknown"
+)
+
+// Requirement is one language-version constraint a project declares.
+type Requirement struct {
+	Language string `json:"language"`
+	// Field is what was read: "go", "toolchain", "requires-python",
+	// "tool.poetry.dependencies.python", ".python-version", "engines.node",
+	// ".nvmrc", ".node-version", "edition", "rust-version", or
+	// "rust-toolchain".
+	Field      string `json:"field"`
+	Constraint string `json:"constraint"`
+	// Source is the file, relative to the project.
+	Source string `json:"source"`
+	// Pin marks version-manager pins (.python-version, .nvmrc,
+	// rust-toolchain, go.mod toolchain), which name one release rather
+	// than a range.
+	Pin bool `json:"pin,omitempty"`
+}
+
+// Check is a Requirement evaluated against the installed toolchain.
+type Check struct {
+	Requirement
+	Installed string `json:"installed,omitempty"`
+	Status    string `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// editionMinimum is the first rustc release supporting each edition.
+var editionMinimum = map[string]string{
+	"2015": "1.0",
+	"2018": "1.31",
+	"2021": "1.56",
+	"2024": "1.85",
+}
+
+var (
+	goDirective        = regexp.MustCompile(`(?m)^go\s+(\S+)`)
+	toolchainDirective = regexp.MustCompile(`(?m)^toolchain\s+go(\S+)`)
+	tomlKey            = regexp.MustCompile(`^([\w.-]+)\s*=\s*"([^"]*)"`)
+)
+
+// Requirements reads the language-version constraints of the project at
+// dir. Unreadable or absent files are skipped.
+func Requirements(dir string) []Requirement {
+	var out []Requirement
+	add := func(lang, field, constraint, source string, pin bool) {
+		if constraint = strings.TrimSpace(constraint); constraint != "" {
+			out = append(out, Requirement{Language: lang, Field: field, Constraint: constraint, Source: source, Pin: pin})
+		}
+	}
+
+	if src, ok := read(dir, "go.mod"); ok {
+		if m := goDirective.FindStringSubmatch(src); m != nil {
+			add(Go, "go", m[1], "go.mod", false)
+		}
+		if m := toolchainDirective.FindStringSubmatch(src); m != nil {
+			add(Go, "toolchain", m[1], "go.mod", true)
+		}
+	}
+
+	if src, ok := read(dir, "pyproject.toml"); ok {
+		add(Python, "requires-python", tomlValue(src, "project", "requires-python"), "pyproject.toml", false)
+		add(Python, "tool.poetry.dependencies.python", tomlValue(src, "tool.poetry.dependencies", "python"), "pyproject.toml", false)
+	}
+	if src, ok := read(dir, ".python-version"); ok {
+		add(Python, ".python-version", firstLine(src), ".python-version", true)
+	}
+
+	if src, ok := read(dir, "package.json"); ok {
+		var manifest struct {
+			Engines map[string]string `json:"engines"`
+		}
+		if json.Unmarshal([]byte(src), &manifest) == nil {
+			add(Node, "engines.node", manifest.Engines["node"], "package.json", false)
+		}
+	}
+	for _, name := range []string{".nvmrc", ".node-version"} {
+		if src, ok := read(dir, name); ok {
+			add(Node, name, firstLine(src), name, true)
+		}
+	}
+
+	if src, ok := read(dir, "Cargo.toml"); ok {
+		add(Rust, "edition", tomlValue(src, "package", "edition"), "Cargo.toml", false)
+		add(Rust, "rust-version", tomlValue(src, "package", "rust-version"), "Cargo.toml", false)
+	}
+	if src, ok := read(dir, "rust-toolchain.toml"); ok {
+		add(Rust, "rust-toolchain", tomlValue(src, "toolchain", "channel"), "rust-toolchain.toml", true)
+	} else if src, ok := read(dir, "rust-toolchain"); ok {
+		add(Rust, "rust-toolchain", firstLine(src), "rust-toolchain", true)
+	}
+	return out
+}
+
+// Evaluate checks each requirement against installed, which maps a
+// language to its local toolchain version ("" or absent when missing).
+func Evaluate(reqs []Requirement, installed map[string]string) []Check {
+	out := make([]Check, 0, len(reqs))
+	for _, r := range reqs {
+		c := Check{Requirement: r, Installed: installed[r.Language]}
+		if c.Installed == "" {
+			c.Status, c.Detail = StatusMissing, r.Language+" is not installed"
+		} else {
+			c.Status, c.Detail = evaluate(r, c.Installed)
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func evaluate(r Requirement, installed string) (status, detail string) {
+	constraint := r.Constraint
+	switch {
+	case r.Language == Rust && r.Field == "edition":
+		minimum, ok := editionMinimum[constraint]
+		if !ok {
+			return StatusUnknown, "unknown edition " + constraint
+		}
+		constraint = ">=" + minimum
+	case r.Language == Rust && r.Field == "rust-version", r.Language == Go && r.Field == "go":
+		constraint = ">=" + constraint
+	case r.Pin:
+		constraint = strings.TrimPrefix(strings.TrimPrefix(constraint, "python-"), "v")
+		if parseVersion(constraint) == nil {
+			return StatusUnknown, "pin " + r.Constraint + " does not name a release"
+		}
+		constraint = "=" + constraint
+	}
+
+	var ok, parsed bool
+	switch r.Language {
+	case Python:
+		ok, parsed = matchPEP440(constraint, installed)
+	case Node:
+		ok, parsed = matchSemver(constraint, installed)
+	default:
+		ok, parsed = matchSimple(constraint, installed)
+	}
+	switch {
+	case !parsed:
+		return StatusUnknown, "cannot evaluate " + r.Constraint
+	case ok:
+		return StatusOK, ""
+	}
+	detail = r.Field + " " + r.Constraint + " excludes installed " + r.Language + " " + installed
+	switch {
+	case r.Language == Go:
+		detail += " (GOTOOLCHAIN=auto may download a newer toolchain)"
+	case r.Language == Rust && r.Pin:
+		detail += " (rustup installs the pinned toolchain on first use)"
+	}
+	return StatusConflict, detail
+}
+
+// Installed returns the local toolchain versions by language, leaving out
+// toolchains that are not on PATH or do not answer.
+func Installed(ctx context.Context) map[string]string {
+	probes := []struct {
+		lang string
+		argv []string
+	}{
+		{Go, []string{"go", "env", "GOVERSION"}},
+		{Python, []string{"python3", "--version"}},
+		{Node, []string{"node", "--version"}},
+		{Rust, []string{"rustc", "--version"}},
+	}
+	out := make(map[string]string, len(probes))
+	for _, p := range probes {
+		if _, err := exec.LookPath(p.argv[0]); err != nil {
+			continue
+		}
+		cctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		data, err := exec.CommandContext(cctx, p.argv[0], p.argv[1:]...).Output()
+		cancel()
+		if err != nil {
+			continue
+		}
+		if v := versionWord.FindString(string(data)); v != "" {
+			out[p.lang] = v
+		}
+	}
+	return out
+}
+
+// versionWord finds the dotted version in "go1.23.4", "Python 3.11.7",
+// "v20.11.0", or "rustc 1.80.0 (051478957 2024-07-21)".
+var versionWord = regexp.MustCompile(`\d+\.\d+(?:\.\d+)?`)
+
+// maxFileSize bounds the files read.
+const maxFileSize = 1 << 20
+
+func read(dir, name string) (string, bool) {
+	p := filepath.Join(dir, name)
+	info, err := os.Stat(p)
+	if err != nil || info.IsDir() || info.Size() > maxFileSize {
+		return "", false
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func firstLine(src string) string {
+	for _, line := range strings.Split(src, "\n") {
+		if t := strings.TrimSpace(line); t != "" && !strings.HasPrefix(t, "#") {
+			return t
+		}
+	}
+	return ""
+}
+
+// tomlValue returns the string assigned to key in the named table, read
+// line by line.
+func tomlValue(src, section, key string) string {
+	in := false
+	for _, line := range strings.Split(src, "\n") {
+		t := strings.TrimSpace(line)
+		if strings.HasPrefix(t, "[") {
+			in = strings.Trim(t, "[] ") == section
+			continue
+		}
+		if m := tomlKey.FindStringSubmatch(t); in && m != nil && m[1] == key {
+			return m[2]
+		}
+	}
+	return ""
+}
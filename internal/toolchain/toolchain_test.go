@@ -0,0 +1,99 @@
+package toolchain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func write(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequirements(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "go.mod", "module example.com/x\n\ngo 1.23\n\ntoolchain go1.23.4\n")
+	write(t, dir, "pyproject.toml", "[project]\nname = \"x\"\nrequires-python = \">=3.10,<3.13\"\n\n[tool.poetry.dependencies]\npython = \"^3.10\"\n")
+	write(t, dir, ".python-version", "# pyenv\n3.11\n")
+	write(t, dir, "package.json", `{"name":"x","engines":{"node":">=18 <21"}}`)
+	write(t, dir, ".nvmrc", "lts/*\n")
+	write(t, dir, "Cargo.toml", "[package]\nname = \"x\"\nedition = \"2021\"\nrust-version = \"1.70\"\n\n[dependencies]\nedition = \"9\"\n")
+	write(t, dir, "rust-toolchain.toml", "[toolchain]\nchannel = \"1.75.0\"\n")
+
+	got := map[string]Requirement{}
+	for _, r := range Requirements(dir) {
+		got[r.Language+" "+r.Field] = r
+	}
+	want := map[string]string{
+		"go go":                                  "1.23",
+		"go toolchain":                           "1.23.4",
+		"python requires-python":                 ">=3.10,<3.13",
+		"python tool.poetry.dependencies.python": "^3.10",
+		"python .python-version":                 "3.11",
+		"node engines.node":                      ">=18 <21",
+		"node .nvmrc":                            "lts/*",
+		"rust edition":                           "2021",
+		"rust rust-version":                      "1.70",
+		"rust rust-toolchain":                    "1.75.0",
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d requirements, want %d: %+v", len(got), len(want), got)
+	}
+	for k, c := range want {
+		if got[k].Constraint != c {
+			t.Errorf("%s = %q, want %q", k, got[k].Constraint, c)
+		}
+	}
+	if !got["go toolchain"].Pin || got["go go"].Pin {
+		t.Errorf("pin flags: %+v", got)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	installed := map[string]string{Go: "1.22.5", Python: "3.11.7", Node: "20.11.0", Rust: "1.80.0"}
+	for _, tc := range []struct {
+		lang, field, constraint string
+		pin                     bool
+		want                    string
+	}{
+		{Go, "go", "1.22", false, StatusOK},
+		{Go, "go", "1.23", false, StatusConflict},
+		{Go, "toolchain", "1.22.5", true, StatusOK},
+		{Python, "requires-python", ">=3.10,<3.13", false, StatusOK},
+		{Python, "requires-python", ">=3.12", false, StatusConflict},
+		{Python, "requires-python", "~=3.11.2", false, StatusOK},
+		{Python, "requires-python", "==3.10.*", false, StatusConflict},
+		{Python, "requires-python", "!=3.11.*", false, StatusConflict},
+		{Python, "tool.poetry.dependencies.python", "^3.9", false, StatusOK},
+		{Python, ".python-version", "3.11", true, StatusOK},
+		{Python, ".python-version", "3.12.1", true, StatusConflict},
+		{Python, ".python-version", "pypy3.10", true, StatusUnknown},
+		{Node, "engines.node", ">=18 <21", false, StatusOK},
+		{Node, "engines.node", ">= 22", false, StatusConflict},
+		{Node, "engines.node", "^16 || ^20.1", false, StatusOK},
+		{Node, "engines.node", "~20.12.0", false, StatusConflict},
+		{Node, "engines.node", "16 - 20", false, StatusOK},
+		{Node, "engines.node", "18.x", false, StatusConflict},
+		{Node, "engines.node", "*", false, StatusOK},
+		{Node, ".nvmrc", "v20", true, StatusOK},
+		{Node, ".nvmrc", "lts/*", true, StatusUnknown},
+		{Rust, "edition", "2024", false, StatusConflict},
+		{Rust, "edition", "2021", false, StatusOK},
+		{Rust, "rust-version", "1.80", false, StatusOK},
+		{Rust, "rust-toolchain", "nightly", true, StatusUnknown},
+	} {
+		r := Requirement{Language: tc.lang, Field: tc.field, Constraint: tc.constraint, Pin: tc.pin}
+		got := Evaluate([]Requirement{r}, installed)[0]
+		if got.Status != tc.want {
+			t.Errorf("%s %s %q: status %s (%s), want %s", tc.lang, tc.field, tc.constraint, got.Status, got.Detail, tc.want)
+		}
+	}
+
+	missing := Evaluate([]Requirement{{Language: Rust, Field: "edition", Constraint: "2021"}}, map[string]string{})
+	if missing[0].Status != StatusMissing {
+		t.Errorf("no rustc: %+v", missing[0])
+	}
+}
@@ -0,0 +1,239 @@
+package toolchain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// comparator is one clause of a version constraint. A prefix comparator
+// ("==3.11.*", "18.x") matches versions whose leading components equal v.
+type comparator struct {
+	op string // "=", "!=", "<", "<=", ">", ">=", "prefix", "!prefix"
+	v  []int
+}
+
+func (c comparator) match(v []int) bool {
+	switch c.op {
+	case "prefix":
+		return hasPrefix(v, c.v)
+	case "!prefix":
+		return !hasPrefix(v, c.v)
+	}
+	n := compare(v, c.v)
+	switch c.op {
+	case "=":
+		// A pin of fewer components ("3.11") admits any release in it.
+		return hasPrefix(v, c.v)
+	case "!=":
+		return n != 0
+	case "<":
+		return n < 0
+	case "<=":
+		return n <= 0
+	case ">":
+		return n > 0
+	case ">=":
+		return n >= 0
+	}
+	return false
+}
+
+var numericPart = regexp.MustCompile(`^\d+`)
+
+// parseVersion reads the numeric components of "1.23", "3.11.7", or
+// "1.80.0-beta"; it returns nil when the first one is not a number.
+func parseVersion(s string) []int {
+	var out []int
+	for _, part := range strings.Split(strings.TrimSpace(s), ".") {
+		m := numericPart.FindString(part)
+		if m == "" {
+			break
+		}
+		n, _ := strconv.Atoi(m)
+		out = append(out, n)
+		if len(m) != len(part) {
+			break
+		}
+	}
+	return out
+}
+
+func compare(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func hasPrefix(v, prefix []int) bool {
+	if len(v) < len(prefix) {
+		v = append(append([]int(nil), v...), make([]int, len(prefix)-len(v))...)
+	}
+	for i, p := range prefix {
+		if v[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// bump returns the version after v at component i: bump([3,10,2], 0) is [4].
+func bump(v []int, i int) []int {
+	out := append([]int(nil), v[:i+1]...)
+	out[i]++
+	return out
+}
+
+var opPrefix = regexp.MustCompile(`^(===|==|!=|~=|<=|>=|<|>|=|\^|~)?\s*(.*)$`)
+
+// parseClause turns one operator-and-version clause into comparators.
+// Caret and tilde follow Poetry and npm: ^1.2 is >=1.2 <2, ~1.2 is
+// >=1.2 <1.3; ~= follows PEP 440. A trailing .*, .x, or bare x makes a
+// prefix match.
+func parseClause(s string) ([]comparator, bool) {
+	m := opPrefix.FindStringSubmatch(strings.TrimSpace(s))
+	op, ver := m[1], strings.TrimPrefix(strings.TrimSpace(m[2]), "v")
+	if ver == "*" || ver == "x" || ver == "X" {
+		return nil, op == "" || op == ">="
+	}
+	wild := false
+	for _, suffix := range []string{".*", ".x", ".X"} {
+		for strings.HasSuffix(ver, suffix) {
+			ver, wild = strings.TrimSuffix(ver, suffix), true
+		}
+	}
+	v := parseVersion(ver)
+	if v == nil {
+		return nil, false
+	}
+	if wild {
+		switch op {
+		case "", "=", "==":
+			return []comparator{{op: "prefix", v: v}}, true
+		case "!=":
+			return []comparator{{op: "!prefix", v: v}}, true
+		}
+		return nil, false
+	}
+	switch op {
+	case "^":
+		i := 0
+		for i < len(v)-1 && v[i] == 0 {
+			i++
+		}
+		return []comparator{{op: ">=", v: v}, {op: "<", v: bump(v, i)}}, true
+	case "~":
+		i := 1
+		if len(v) == 1 {
+			i = 0
+		}
+		return []comparator{{op: ">=", v: v}, {op: "<", v: bump(v, i)}}, true
+	case "~=":
+		if len(v) < 2 {
+			return nil, false
+		}
+		return []comparator{{op: ">=", v: v}, {op: "<", v: bump(v, len(v)-2)}}, true
+	case "", "==", "===":
+		return []comparator{{op: "=", v: v}}, true
+	}
+	return []comparator{{op: op, v: v}}, true
+}
+
+func matchAll(clauses []string, installed string) (ok, parsed bool) {
+	v := parseVersion(installed)
+	if v == nil {
+		return false, false
+	}
+	ok = true
+	for _, clause := range clauses {
+		if strings.TrimSpace(clause) == "" {
+			continue
+		}
+		cs, valid := parseClause(clause)
+		if !valid {
+			return false, false
+		}
+		for _, c := range cs {
+			if !c.match(v) {
+				ok = false
+			}
+		}
+	}
+	return ok, true
+}
+
+// matchPEP440 evaluates a comma-separated PEP 440 specifier set, also
+// accepting Poetry's caret and tilde.
+func matchPEP440(constraint, installed string) (ok, parsed bool) {
+	return matchAll(strings.Split(constraint, ","), installed)
+}
+
+var hyphenRange = regexp.MustCompile(`^\s*(\S+)\s+-\s+(\S+)\s*$`)
+
+// matchSemver evaluates an npm range: ||-separated alternatives of
+// space-separated comparators, with hyphen ranges ("16 - 20").
+func matchSemver(constraint, installed string) (ok, parsed bool) {
+	parsed = true
+	for _, alt := range strings.Split(constraint, "||") {
+		var clauses []string
+		if m := hyphenRange.FindStringSubmatch(alt); m != nil {
+			clauses = []string{">=" + m[1], upperBound(m[2])}
+		} else {
+			// Join operators split from their version (">= 18").
+			fields := strings.Fields(alt)
+			for i := 0; i < len(fields); i++ {
+				if strings.Trim(fields[i], "<>=^~") == "" && i+1 < len(fields) {
+					fields[i+1] = fields[i] + fields[i+1]
+					continue
+				}
+				clauses = append(clauses, fields[i])
+			}
+		}
+		altOK, altParsed := matchAll(clauses, installed)
+		if !altParsed {
+			parsed = false
+			continue
+		}
+		if altOK {
+			return true, true
+		}
+	}
+	return false, parsed
+}
+
+// upperBound is the comparator for the upper end of a hyphen range: a
+// partial version admits everything inside it ("16 - 20" takes 20.x).
+func upperBound(s string) string {
+	v := parseVersion(strings.TrimPrefix(s, "v"))
+	if len(v) > 0 && len(v) < 3 {
+		return "<" + joinVersion(bump(v, len(v)-1))
+	}
+	return "<=" + s
+}
+
+func joinVersion(v []int) string {
+	parts := make([]string, len(v))
+	for i, n := range v {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ".")
+}
+
+// matchSimple evaluates Go and Rust constraints, which are single
+// comparisons built by evaluate.
+func matchSimple(constraint, installed string) (ok, parsed bool) {
+	return matchAll([]string{constraint}, installed)
+}
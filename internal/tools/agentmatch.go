@@ -0,0 +1,227 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermap/client"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// Confidence levels for an agent's project match.
+const (
+	ConfidenceHigh      = "high"      // reservation paths or an absolute project path
+	ConfidenceMedium    = "medium"    // a unique exact project name
+	ConfidenceAmbiguous = "ambiguous" // several projects fit; see Candidates
+	ConfidenceNone      = "none"
+)
+
+// AgentMatch is the project an agent was attributed to and how.
+type AgentMatch struct {
+	Project    *registry.Project
+	MatchedBy  string // "reservations", "path", or "name"
+	Confidence string
+	// Candidates lists group/name for each project that fit an ambiguous
+	// match.
+	Candidates []string
+}
+
+// matchAgentProject attributes an agent to a project. The agent's active
+// reservations are the primary evidence: absolute patterns are resolved with
+// registry.Resolve, relative ones against the projects containing their
+// directory. The agent's self-reported project field only breaks ties or,
+// failing any reservation evidence, matches by absolute path or exact name.
+func matchAgentProject(agent client.Agent, reservations []client.Reservation, projects []registry.Project) AgentMatch {
+	// A reservation that fits one project is strong evidence; one that
+	// fits several (a relative pattern present in many repos) is weak.
+	strong := map[string]registry.Project{}
+	weak := map[string]registry.Project{}
+	for _, r := range reservations {
+		ps := reservationProjects(r, projects)
+		for _, p := range ps {
+			if len(ps) == 1 {
+				strong[p.Path] = p
+			} else {
+				weak[p.Path] = p
+			}
+		}
+	}
+
+	switch len(strong) {
+	case 0:
+	case 1:
+		for _, p := range strong {
+			return AgentMatch{Project: &p, MatchedBy: "reservations", Confidence: ConfidenceHigh}
+		}
+	default:
+		fits := projectValues(strong)
+		if named := exactProjects(agent.Project, fits); len(named) == 1 {
+			return AgentMatch{Project: &named[0], MatchedBy: "reservations", Confidence: ConfidenceHigh}
+		}
+		// Conflicting strong evidence outranks the self-reported name.
+		return ambiguousMatch("reservations", fits)
+	}
+	if named := exactProjects(agent.Project, projectValues(weak)); len(named) == 1 {
+		return AgentMatch{Project: &named[0], MatchedBy: "reservations", Confidence: ConfidenceHigh}
+	}
+
+	if filepath.IsAbs(agent.Project) {
+		if p, err := registry.Resolve(agent.Project); err == nil {
+			if scanned, ok := projectAt(p.Path, projects); ok {
+				p = &scanned
+			}
+			return AgentMatch{Project: p, MatchedBy: "path", Confidence: ConfidenceHigh}
+		}
+	}
+	switch named := exactProjects(agent.Project, projects); len(named) {
+	case 0:
+		return AgentMatch{Confidence: ConfidenceNone}
+	case 1:
+		return AgentMatch{Project: &named[0], MatchedBy: "name", Confidence: ConfidenceMedium}
+	default:
+		return ambiguousMatch("name", named)
+	}
+}
+
+// reservationProjects returns the projects a reservation pattern can refer
+// to.
+func reservationProjects(r client.Reservation, projects []registry.Project) []registry.Project {
+	dir := patternDir(r.Pattern)
+	if filepath.IsAbs(dir) {
+		p, err := registry.Resolve(dir)
+		if err != nil {
+			return nil
+		}
+		if scanned, ok := projectAt(p.Path, projects); ok {
+			return []registry.Project{scanned}
+		}
+		return []registry.Project{*p}
+	}
+
+	pool := projects
+	if r.Project != "" {
+		if named := exactProjects(r.Project, projects); len(named) > 0 {
+			pool = named
+		}
+	}
+	if dir == "." {
+		// No path to check; only an explicit project narrows it down.
+		if len(pool) < len(projects) {
+			return pool
+		}
+		return nil
+	}
+	var out []registry.Project
+	for _, p := range pool {
+		if _, err := os.Stat(filepath.Join(p.Path, dir)); err == nil {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// patternDir returns the directory part of a reservation pattern's literal
+// prefix, e.g. "internal/tools" for "internal/tools/*.go".
+func patternDir(pattern string) string {
+	literal := pattern
+	if i := strings.IndexAny(pattern, "*?[{"); i >= 0 {
+		literal = pattern[:i]
+		if !strings.HasSuffix(literal, "/") {
+			literal = filepath.Dir(literal)
+		}
+	} else {
+		literal = filepath.Dir(literal)
+	}
+	return filepath.Clean(literal)
+}
+
+// exactProjects returns the projects whose name or group/name equals name.
+func exactProjects(name string, projects []registry.Project) []registry.Project {
+	if name == "" {
+		return nil
+	}
+	var out []registry.Project
+	for _, m := range registry.Lookup(projects, name) {
+		if m.Kind == registry.MatchExact {
+			out = append(out, m.Project)
+		}
+	}
+	return out
+}
+
+func projectValues(m map[string]registry.Project) []registry.Project {
+	out := make([]registry.Project, 0, len(m))
+	for _, p := range m {
+		out = append(out, p)
+	}
+	return out
+}
+
+func projectAt(path string, projects []registry.Project) (registry.Project, bool) {
+	for _, p := range projects {
+		if p.Path == path {
+			return p, true
+		}
+	}
+	return registry.Project{}, false
+}
+
+func ambiguousMatch(by string, fits []registry.Project) AgentMatch {
+	names := make([]string, 0, len(fits))
+	for _, p := range fits {
+		names = append(names, p.Group+"/"+p.Name)
+	}
+	sort.Strings(names)
+	return AgentMatch{MatchedBy: by, Confidence: ConfidenceAmbiguous, Candidates: names}
+}
+
+// closedTaskStatuses are task states that no longer describe current work.
+var closedTaskStatuses = map[string]bool{
+	"done": true, "completed": true, "closed": true,
+	"cancelled": true, "canceled": true, "failed": true,
+}
+
+// currentTasks picks each agent's most recently updated open task.
+func currentTasks(tasks []client.Task) map[string]client.Task {
+	out := make(map[string]client.Task)
+	for _, t := range tasks {
+		if t.AgentID == "" || closedTaskStatuses[strings.ToLower(t.Status)] {
+			continue
+		}
+		if cur, ok := out[t.AgentID]; !ok || laterTime(t.UpdatedAt, cur.UpdatedAt) {
+			out[t.AgentID] = t
+		}
+	}
+	return out
+}
+
+// latestMessages returns each agent's latest message time, sent or
+// received.
+func latestMessages(messages []client.Message) map[string]string {
+	out := make(map[string]string)
+	for _, m := range messages {
+		for _, id := range []string{m.From, m.To} {
+			if id != "" && laterTime(m.CreatedAt, out[id]) {
+				out[id] = m.CreatedAt
+			}
+		}
+	}
+	return out
+}
+
+// laterTime reports whether RFC 3339 timestamp a is after b. Unparseable
+// values compare as strings, and anything beats an empty b.
+func laterTime(a, b string) bool {
+	if b == "" {
+		return a != ""
+	}
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return a > b
+	}
+	return ta.After(tb)
+}
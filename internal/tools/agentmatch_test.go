@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermap/client"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+func TestMatchAgentProject(t *testing.T) {
+	root := t.TempDir()
+	var projects []registry.Project
+	for _, gp := range [][2]string{{"core", "api"}, {"apps", "api"}, {"core", "web"}} {
+		path := filepath.Join(root, gp[0], gp[1])
+		for _, dir := range []string{".git", "internal"} {
+			if err := os.MkdirAll(filepath.Join(path, dir), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		projects = append(projects, registry.Project{Name: gp[1], Group: gp[0], Path: path})
+	}
+	if err := os.MkdirAll(filepath.Join(root, "core", "web", "ui"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name           string
+		project        string
+		patterns       []string
+		wantPath       string
+		wantConf       string
+		wantMatchBy    string
+		wantCandidates int
+	}{
+		{
+			// "api" is a fragment shared by two repos; the absolute
+			// reservation settles it.
+			name: "absolute reservation", project: "api",
+			patterns: []string{filepath.Join(root, "apps", "api", "internal", "*.go")},
+			wantPath: filepath.Join(root, "apps", "api"), wantConf: ConfidenceHigh, wantMatchBy: "reservations",
+		},
+		{
+			name: "unique relative reservation", project: "something-else",
+			patterns: []string{"ui/**"},
+			wantPath: filepath.Join(root, "core", "web"), wantConf: ConfidenceHigh, wantMatchBy: "reservations",
+		},
+		{
+			name: "shared relative reservation narrowed by name", project: "core/api",
+			patterns: []string{"internal/*.go"},
+			wantPath: filepath.Join(root, "core", "api"), wantConf: ConfidenceHigh, wantMatchBy: "reservations",
+		},
+		{
+			name: "ambiguous name", project: "api",
+			wantConf: ConfidenceAmbiguous, wantMatchBy: "name", wantCandidates: 2,
+		},
+		{
+			name: "unique name", project: "web",
+			wantPath: filepath.Join(root, "core", "web"), wantConf: ConfidenceMedium, wantMatchBy: "name",
+		},
+		{
+			name: "substring no longer matches", project: "we",
+			wantConf: ConfidenceNone,
+		},
+	} {
+		var held []client.Reservation
+		for _, p := range tc.patterns {
+			held = append(held, client.Reservation{Pattern: p, IsActive: true})
+		}
+		m := matchAgentProject(client.Agent{Project: tc.project}, held, projects)
+		gotPath := ""
+		if m.Project != nil {
+			gotPath = m.Project.Path
+		}
+		if gotPath != tc.wantPath || m.Confidence != tc.wantConf || m.MatchedBy != tc.wantMatchBy || len(m.Candidates) != tc.wantCandidates {
+			t.Errorf("%s: got path %q, %s by %q, candidates %v", tc.name, gotPath, m.Confidence, m.MatchedBy, m.Candidates)
+		}
+	}
+}
+
+func TestPatternDir(t *testing.T) {
+	for in, want := range map[string]string{
+		"internal/tools/*.go":  "internal/tools",
+		"internal/tools/":      "internal/tools",
+		"internal/to*":         "internal",
+		"cmd/main.go":          "cmd",
+		"**":                   ".",
+		"/ws/core/api/**/*.go": "/ws/core/api",
+	} {
+		if got := patternDir(in); got != want {
+			t.Errorf("patternDir(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAgentActivity(t *testing.T) {
+	tasks := currentTasks([]client.Task{
+		{AgentID: "a1", Title: "old", Status: "in_progress", UpdatedAt: "2026-01-01T00:00:00Z"},
+		{AgentID: "a1", Title: "new", Status: "in_progress", UpdatedAt: "2026-01-02T00:00:00+02:00"},
+		{AgentID: "a1", Title: "finished", Status: "done", UpdatedAt: "2026-01-03T00:00:00Z"},
+		{AgentID: "a2", Title: "closed only", Status: "Completed"},
+	})
+	if tasks["a1"].Title != "new" {
+		t.Errorf("a1 task = %q, want new", tasks["a1"].Title)
+	}
+	if _, ok := tasks["a2"]; ok {
+		t.Error("closed tasks should not be current")
+	}
+
+	last := latestMessages([]client.Message{
+		{From: "a1", To: "a2", CreatedAt: "2026-01-01T10:00:00Z"},
+		{From: "a2", To: "a1", CreatedAt: "2026-01-01T09:00:00Z"},
+	})
+	if last["a1"] != "2026-01-01T10:00:00Z" || last["a2"] != "2026-01-01T10:00:00Z" {
+		t.Errorf("latest messages = %v", last)
+	}
+}
+
+func TestParseWindowTime(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Time{
+		"24h":                  now.Add(-24 * time.Hour),
+		"90m":                  now.Add(-90 * time.Minute),
+		"2026-02-01T00:00:00Z": time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		got, err := parseWindowTime(in, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("parseWindowTime(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"-1h", "yesterday"} {
+		if _, err := parseWindowTime(bad, now); err == nil {
+			t.Errorf("parseWindowTime(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/internal/forge"
+)
+
+// annotateMarker tags intermap's PR comment so reruns edit it in place.
+const annotateMarker = "<!-- intermap:annotate_pr -->"
+
+// maxAnnotatedFunctions caps the per-function caller lookups in a PR summary.
+const maxAnnotatedFunctions = 10
+
+// AnnotateOptions configures AnnotatePR.
+type AnnotateOptions struct {
+	Project  string
+	Number   int
+	Remote   string // git remote name (default "origin")
+	Language string
+	DryRun   bool // render the comment without posting it
+}
+
+// AnnotateResult is the response for the annotate_pr tool.
+type AnnotateResult struct {
+	PR               *forge.PullRequest  `json:"pr"`
+	Base             string              `json:"base"`
+	ChangedFiles     []string            `json:"changed_files"`
+	ChangedFunctions []string            `json:"changed_functions"`
+	Callers          map[string][]string `json:"callers"`
+	AffectedTests    []string            `json:"affected_tests"`
+	TestCommand      string              `json:"test_command,omitempty"`
+	Comment          string              `json:"comment"`
+	Posted           bool                `json:"posted"`
+	CommentURL       string              `json:"comment_url,omitempty"`
+}
+
+func annotatePR(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("annotate_pr",
+			mcp.WithDescription("Run change impact for a pull/merge request branch against its base and render a summary comment (impacted callers, suggested tests); with dry_run=false, post it via the GitHub or GitLab API. Token from GITHUB_TOKEN or GITLAB_TOKEN. Posting requires the project's HEAD to be the PR head commit."),
+			mcp.WithString("project",
+				mcp.Description("Project path (a checkout of the PR branch)"),
+				mcp.Required(),
+			),
+			mcp.WithNumber("number",
+				mcp.Description("Pull request (GitHub) or merge request IID (GitLab)"),
+				mcp.Required(),
+			),
+			mcp.WithString("remote",
+				mcp.Description("Git remote pointing at the forge (default origin)"),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (defaults to the detected project language)"),
+			),
+			mcp.WithBoolean("dry_run",
+				mcp.Description("Render the comment without posting it (default true)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project := stringOr(args["project"], "")
+			number := intOr(args["number"], 0)
+			if project == "" || number <= 0 {
+				return mcputil.ValidationError("project and number are required")
+			}
+
+			res, err := AnnotatePR(ctx, bridge, AnnotateOptions{
+				Project:  project,
+				Number:   number,
+				Remote:   stringOr(args["remote"], "origin"),
+				Language: languageOr(args["language"], project),
+				DryRun:   boolOr(args["dry_run"], true),
+			})
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(res)
+		},
+	}
+}
+
+// AnnotatePR computes the impact of a PR branch relative to its base and
+// posts (or, with DryRun, only renders) a summary comment.
+func AnnotatePR(ctx context.Context, bridge analysis.Backend, opts AnnotateOptions) (*AnnotateResult, error) {
+	if opts.Remote == "" {
+		opts.Remote = "origin"
+	}
+	if opts.Language == "" {
+		opts.Language = languageOr(nil, opts.Project)
+	}
+	remoteURL, err := exec.CommandContext(ctx, "git", "-C", opts.Project, "remote", "get-url", opts.Remote).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git remote %s: %w", opts.Remote, err)
+	}
+	remote, err := forge.ParseRemote(string(remoteURL))
+	if err != nil {
+		return nil, err
+	}
+	fc, err := forge.NewClient(remote)
+	if err != nil {
+		return nil, err
+	}
+	pr, err := fc.PullRequest(ctx, opts.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	base := mergeBase(ctx, opts.Project, opts.Remote+"/"+pr.BaseRef, pr.BaseRef)
+	impact, err := ChangeImpact(ctx, bridge, opts.Project, opts.Language, base)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &AnnotateResult{
+		PR:               pr,
+		Base:             base,
+		ChangedFiles:     stringSlice(impact["changed_files"]),
+		ChangedFunctions: stringSlice(impact["changed_functions"]),
+		Callers:          make(map[string][]string),
+		AffectedTests:    stringSlice(impact["affected_tests"]),
+		TestCommand:      testCommandString(impact["test_command"]),
+	}
+	for i, fn := range res.ChangedFunctions {
+		if i == maxAnnotatedFunctions {
+			break
+		}
+		callers, err := directCallers(ctx, bridge, opts.Project, opts.Language, fn)
+		if err == nil && len(callers) > 0 {
+			res.Callers[fn] = callers
+		}
+	}
+	res.Comment = renderPRComment(res)
+
+	if opts.DryRun {
+		return res, nil
+	}
+	head, err := exec.CommandContext(ctx, "git", "-C", opts.Project, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-parse HEAD: %w", err)
+	}
+	if h := strings.TrimSpace(string(head)); h != pr.HeadSHA {
+		return nil, fmt.Errorf("checkout is at %s but PR #%d's head is %s; check out the PR head before posting",
+			shortRef(h), opts.Number, shortRef(pr.HeadSHA))
+	}
+	u, err := fc.UpsertComment(ctx, opts.Number, annotateMarker, res.Comment)
+	if err != nil {
+		return nil, err
+	}
+	res.Posted = true
+	res.CommentURL = u
+	return res, nil
+}
+
+// mergeBase returns the merge base of HEAD with the first ref that resolves,
+// or the last ref unchanged if none do.
+func mergeBase(ctx context.Context, project string, refs ...string) string {
+	for _, ref := range refs {
+		out, err := exec.CommandContext(ctx, "git", "-C", project, "merge-base", ref, "HEAD").Output()
+		if err == nil {
+			return strings.TrimSpace(string(out))
+		}
+	}
+	return refs[len(refs)-1]
+}
+
+// directCallers lists file:function for the immediate callers of fn.
+func directCallers(ctx context.Context, bridge analysis.Backend, project, language, fn string) ([]string, error) {
+	result, err := bridge.Run(ctx, "impact", project, map[string]any{
+		"target":    fn,
+		"language":  language,
+		"max_depth": 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(result["targets"])
+	if err != nil {
+		return nil, err
+	}
+	var targets map[string]struct {
+		Callers []struct {
+			File     string `json:"file"`
+			Function string `json:"function"`
+		} `json:"callers"`
+	}
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var out []string
+	for _, t := range targets {
+		for _, c := range t.Callers {
+			key := c.File + ":" + c.Function
+			if !seen[key] {
+				seen[key] = true
+				out = append(out, key)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// testCommandString renders change_impact's test_command, which is either a
+// string or an argv list.
+func testCommandString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return strings.Join(stringSlice(v), " ")
+}
+
+// renderPRComment formats the PR summary as Markdown.
+func renderPRComment(res *AnnotateResult) string {
+	var b strings.Builder
+	b.WriteString(annotateMarker + "\n")
+	b.WriteString("### intermap impact summary\n\n")
+	fmt.Fprintf(&b, "%d changed files, %d changed functions, %d affected tests (base `%s`).\n",
+		len(res.ChangedFiles), len(res.ChangedFunctions), len(res.AffectedTests), shortRef(res.Base))
+
+	if len(res.Callers) > 0 {
+		b.WriteString("\n**Impacted callers**\n\n")
+		fns := make([]string, 0, len(res.Callers))
+		for fn := range res.Callers {
+			fns = append(fns, fn)
+		}
+		sort.Strings(fns)
+		for _, fn := range fns {
+			fmt.Fprintf(&b, "- `%s` ← %s\n", fn, codeList(res.Callers[fn]))
+		}
+		if len(res.ChangedFunctions) > maxAnnotatedFunctions {
+			fmt.Fprintf(&b, "- …and %d more changed functions not expanded\n", len(res.ChangedFunctions)-maxAnnotatedFunctions)
+		}
+	}
+
+	if len(res.AffectedTests) > 0 {
+		b.WriteString("\n**Suggested tests**\n\n")
+		for _, t := range res.AffectedTests {
+			fmt.Fprintf(&b, "- `%s`\n", t)
+		}
+		if res.TestCommand != "" {
+			fmt.Fprintf(&b, "\n```\n%s\n```\n", res.TestCommand)
+		}
+	}
+	return b.String()
+}
+
+func codeList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, s := range items {
+		quoted[i] = "`" + s + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func shortRef(ref string) string {
+	if len(ref) == 40 {
+		return ref[:12]
+	}
+	return ref
+}
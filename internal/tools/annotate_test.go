@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermap/internal/python/fakesidecar"
+)
+
+func TestRenderPRComment(t *testing.T) {
+	res := &AnnotateResult{
+		Base:             "0123456789abcdef0123456789abcdef01234567",
+		ChangedFiles:     []string{"a.py"},
+		ChangedFunctions: []string{"parse"},
+		Callers:          map[string][]string{"parse": {"cli.py:main", "api.py:handle"}},
+		AffectedTests:    []string{"tests/test_a.py"},
+		TestCommand:      testCommandString([]any{"pytest", "tests/test_a.py"}),
+	}
+	got := renderPRComment(res)
+	for _, want := range []string{
+		annotateMarker,
+		"1 changed files, 1 changed functions, 1 affected tests (base `0123456789ab`)",
+		"- `parse` ← `cli.py:main`, `api.py:handle`",
+		"- `tests/test_a.py`",
+		"pytest tests/test_a.py",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("comment missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestAnnotatePR_PostsOnlyForPRHead(t *testing.T) {
+	project := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"remote", "add", "origin", "https://github.com/o/r.git"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", project}, args...)...).CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+	}
+	out, _ := exec.Command("git", "-C", project, "rev-parse", "HEAD").Output()
+	head := strings.TrimSpace(string(out))
+
+	prHead := strings.Repeat("d", 40)
+	var posted int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/o/r/pulls/7":
+			json.NewEncoder(w).Encode(map[string]any{"number": 7, "head": map[string]any{"sha": prHead}, "base": map[string]any{"ref": "main"}})
+		case r.Method == "GET":
+			w.Write([]byte("[]"))
+		default:
+			posted++
+			w.Write([]byte("{}"))
+		}
+	}))
+	defer ts.Close()
+	t.Setenv("GITHUB_TOKEN", "tok")
+	t.Setenv("GITHUB_API_URL", ts.URL)
+
+	fake := fakesidecar.New(t, fakesidecar.Script{
+		"change_impact": {{Result: map[string]any{"changed_files": []any{}, "changed_functions": []any{}, "affected_tests": []any{}}}},
+	})
+	res := fakesidecar.CallTool(t, annotatePR(fake.Bridge), map[string]any{"project": project, "number": 7})
+	var got AnnotateResult
+	fakesidecar.DecodeResult(t, res, &got)
+	if got.Posted || posted != 0 {
+		t.Errorf("default call posted: %+v", got)
+	}
+
+	opts := AnnotateOptions{Project: project, Number: 7, Language: "python"}
+	if _, err := AnnotatePR(context.Background(), fake.Bridge, opts); err == nil || !strings.Contains(err.Error(), "check out the PR head") || posted != 0 {
+		t.Errorf("posting from another checkout: err = %v, posted = %d", err, posted)
+	}
+	prHead = head
+	if got, err := AnnotatePR(context.Background(), fake.Bridge, opts); err != nil || !got.Posted || posted != 1 {
+		t.Errorf("posting from the PR head: %+v, %v, posted = %d", got, err, posted)
+	}
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+)
+
+func apiSurface(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("api_surface",
+			mcp.WithDescription("List a project's public API per package — exported Go identifiers (with struct and interface members), Python __all__ or public names, TypeScript exports, Rust pub items — with normalized signatures and symbol IDs, so two snapshots diff cleanly and consumer docs can be generated from it."),
+			mcp.WithString("project",
+				mcp.Description("Project root path"),
+				mcp.Required(),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (defaults to the detected project language)"),
+			),
+			mcp.WithArray("packages",
+				mcp.Description("Only these packages, as in symbol IDs: Go directory, Python dotted module, or file path without extension"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithBoolean("include_internal",
+				mcp.Description("Go only: also list internal/ and main packages (default false)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project, _ := args["project"].(string)
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+
+			pyArgs := map[string]any{
+				"language":         languageOr(args["language"], project),
+				"include_internal": boolOr(args["include_internal"], false),
+			}
+			if packages := stringSlice(args["packages"]); len(packages) > 0 {
+				pyArgs["packages"] = packages
+			}
+
+			result, err := runCached(ctx, bridge, "api_surface", "api_surface", project, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
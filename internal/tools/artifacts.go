@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/artifacts"
+)
+
+// ArtifactMapResult is the response for the artifact_map tool.
+type ArtifactMapResult struct {
+	Project   string               `json:"project"`
+	Artifacts []artifacts.Artifact `json:"artifacts"`
+	Count     int                  `json:"count"`
+	// Rebuild lists the artifacts that include ChangedFiles.
+	ChangedFiles []string            `json:"changed_files,omitempty"`
+	Rebuild      []artifacts.Rebuild `json:"rebuild"`
+}
+
+func artifactMap() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("artifact_map",
+			mcp.WithDescription("Map each buildable artifact (Go main packages, Python console_scripts, npm bins, Dockerfiles) to the source files it is built from. Given changed files, also lists the artifacts that need rebuilding."),
+			mcp.WithString("project",
+				mcp.Description("Project path to scan"),
+				mcp.Required(),
+			),
+			mcp.WithString("kind",
+				mcp.Description("Only report one kind: go_binary, python_script, npm_bin, or docker_image"),
+			),
+			mcp.WithArray("files",
+				mcp.Description("Changed files (project-relative); adds the artifacts that include them as rebuild"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithBoolean("include_files",
+				mcp.Description("Include each artifact's source file list (default true)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project := stringOr(args["project"], "")
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+			kind := stringOr(args["kind"], "")
+			switch kind {
+			case "", artifacts.KindGoBinary, artifacts.KindPythonScript, artifacts.KindNpmBin, artifacts.KindDockerImage:
+			default:
+				return mcputil.ValidationError("kind must be go_binary, python_script, npm_bin, or docker_image")
+			}
+
+			arts, err := artifacts.Scan(project)
+			if err != nil {
+				return mcputil.WrapError(fmt.Errorf("scan artifacts: %w", err))
+			}
+			if kind != "" {
+				filtered := arts[:0]
+				for _, a := range arts {
+					if a.Kind == kind {
+						filtered = append(filtered, a)
+					}
+				}
+				arts = filtered
+			}
+
+			result := ArtifactMapResult{Project: project, Count: len(arts), Rebuild: []artifacts.Rebuild{}}
+			if changed := stringSlice(args["files"]); len(changed) > 0 {
+				result.ChangedFiles = changed
+				result.Rebuild = artifacts.Affected(arts, changed)
+			}
+			if !boolOr(args["include_files"], true) {
+				for i := range arts {
+					arts[i].Files = nil
+				}
+			}
+			result.Artifacts = append([]artifacts.Artifact{}, arts...)
+			return jsonResult(result)
+		},
+	}
+}
+
+// addRebuildArtifacts annotates a change_impact result with the artifacts
+// that include its changed files.
+func addRebuildArtifacts(project string, result map[string]any) error {
+	arts, err := artifacts.Scan(project)
+	if err != nil {
+		return fmt.Errorf("scan artifacts: %w", err)
+	}
+	result["rebuild_artifacts"] = artifacts.Affected(arts, stringSlice(result["changed_files"]))
+	return nil
+}
@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/internal/graph"
+)
+
+// BoundaryCluster is one suggested module in the boundary_suggest result.
+type BoundaryCluster struct {
+	Name     string   `json:"name"`
+	Files    []string `json:"files"`
+	Packages []string `json:"packages"`
+	graph.ClusterMetrics
+	// ExtractionCandidate marks cohesive, loosely coupled clusters that
+	// currently share a package with other clusters.
+	ExtractionCandidate bool `json:"extraction_candidate"`
+}
+
+// BoundarySuggestResult is the response for the boundary_suggest tool.
+type BoundarySuggestResult struct {
+	Project   string            `json:"project"`
+	Scope     string            `json:"scope,omitempty"`
+	FileCount int               `json:"file_count"`
+	EdgeCount int               `json:"edge_count"`
+	Clusters  []BoundaryCluster `json:"clusters"`
+}
+
+func boundarySuggest(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("boundary_suggest",
+			mcp.WithDescription("Suggest module/package boundaries by clustering the file-level dependency graph. Reports cohesion and coupling per cluster and flags extraction candidates inside overgrown packages."),
+			mcp.WithString("project",
+				mcp.Description("Project path to analyze"),
+				mcp.Required(),
+			),
+			mcp.WithString("path",
+				mcp.Description("Only cluster files under this project-relative directory (e.g. an overgrown package)"),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (defaults to the detected project language)"),
+			),
+			mcp.WithNumber("min_size",
+				mcp.Description("Minimum files per reported cluster (default 2)"),
+			),
+			mcp.WithNumber("max_files",
+				mcp.Description("Maximum number of files to scan (default 500)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project := stringOr(args["project"], "")
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+			scope := strings.Trim(stringOr(args["path"], ""), "/")
+
+			rd, err := fetchRefData(ctx, bridge, project, languageOr(args["language"], project), intOr(args["max_files"], 500))
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(suggestBoundaries(rd, project, scope, intOr(args["min_size"], 2)))
+		},
+	}
+}
+
+// suggestBoundaries clusters the file graph of rd, optionally restricted to
+// files under scope.
+func suggestBoundaries(rd *refData, project, scope string, minSize int) BoundarySuggestResult {
+	inScope := func(f string) bool {
+		return scope == "" || f == scope || strings.HasPrefix(f, scope+"/")
+	}
+
+	g := graph.New()
+	for _, d := range rd.Definitions {
+		if inScope(d.File) {
+			g.AddNode(d.File)
+		}
+	}
+	for _, e := range rd.Edges {
+		if inScope(e.SrcFile) && inScope(e.DstFile) {
+			g.AddEdge(e.SrcFile, e.DstFile)
+		}
+	}
+
+	res := BoundarySuggestResult{
+		Project:   project,
+		Scope:     scope,
+		FileCount: g.Len(),
+		EdgeCount: g.EdgeCount(),
+		Clusters:  []BoundaryCluster{},
+	}
+
+	clustersPerPkg := make(map[string]int)
+	for _, members := range g.Communities() {
+		if len(members) < minSize {
+			continue
+		}
+		pkgs := packagesOf(members)
+		for _, p := range pkgs {
+			clustersPerPkg[p]++
+		}
+		res.Clusters = append(res.Clusters, BoundaryCluster{
+			Name:           clusterName(members),
+			Files:          members,
+			Packages:       pkgs,
+			ClusterMetrics: g.Metrics(members),
+		})
+	}
+
+	for i := range res.Clusters {
+		c := &res.Clusters[i]
+		shared := false
+		for _, p := range c.Packages {
+			if clustersPerPkg[p] > 1 {
+				shared = true
+			}
+		}
+		c.ExtractionCandidate = shared && c.InternalEdges > 0 && c.Cohesion >= 0.5
+	}
+	return res
+}
+
+// packagesOf returns the sorted distinct directories of files.
+func packagesOf(files []string) []string {
+	seen := make(map[string]bool)
+	for _, f := range files {
+		seen[path.Dir(f)] = true
+	}
+	out := make([]string, 0, len(seen))
+	for p := range seen {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// clusterName derives a label from the most common directory and the
+// longest shared file-name stem of the cluster.
+func clusterName(files []string) string {
+	counts := make(map[string]int)
+	for _, f := range files {
+		counts[path.Dir(f)]++
+	}
+	dir, best := "", 0
+	for d, n := range counts {
+		if n > best || (n == best && d < dir) {
+			dir, best = d, n
+		}
+	}
+
+	stem := strings.TrimSuffix(path.Base(files[0]), path.Ext(files[0]))
+	for _, f := range files[1:] {
+		base := strings.TrimSuffix(path.Base(f), path.Ext(f))
+		for !strings.HasPrefix(base, stem) {
+			stem = stem[:len(stem)-1]
+		}
+	}
+	stem = strings.Trim(stem, "_-.")
+	if len(stem) >= 3 {
+		return path.Join(dir, stem)
+	}
+	return dir
+}
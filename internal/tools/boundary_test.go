@@ -0,0 +1,41 @@
+package tools
+
+import "testing"
+
+func TestSuggestBoundaries_SplitsPackage(t *testing.T) {
+	rd := &refData{
+		Edges: []refEdge{
+			{SrcFile: "pkg/http_server.go", SrcSymbol: "A", DstFile: "pkg/http_routes.go", DstSymbol: "B"},
+			{SrcFile: "pkg/http_routes.go", SrcSymbol: "B", DstFile: "pkg/http_mw.go", DstSymbol: "C"},
+			{SrcFile: "pkg/http_mw.go", SrcSymbol: "C", DstFile: "pkg/http_server.go", DstSymbol: "A"},
+			{SrcFile: "pkg/db_conn.go", SrcSymbol: "D", DstFile: "pkg/db_query.go", DstSymbol: "E"},
+			{SrcFile: "pkg/db_query.go", SrcSymbol: "E", DstFile: "pkg/db_conn.go", DstSymbol: "D"},
+			{SrcFile: "other/x.go", SrcSymbol: "X", DstFile: "pkg/db_conn.go", DstSymbol: "D"},
+		},
+	}
+
+	res := suggestBoundaries(rd, "/p", "pkg", 2)
+	if res.FileCount != 5 {
+		t.Errorf("expected 5 scoped files, got %d", res.FileCount)
+	}
+	if len(res.Clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %+v", res.Clusters)
+	}
+	for _, c := range res.Clusters {
+		if !c.ExtractionCandidate {
+			t.Errorf("expected cluster %s to be an extraction candidate", c.Name)
+		}
+		if c.Cohesion != 1 {
+			t.Errorf("cluster %s: expected cohesion 1 within scope, got %f", c.Name, c.Cohesion)
+		}
+	}
+	if res.Clusters[0].Name != "pkg/http" {
+		t.Errorf("expected first cluster named pkg/http, got %s", res.Clusters[0].Name)
+	}
+}
+
+func TestClusterName_FallsBackToDir(t *testing.T) {
+	if got := clusterName([]string{"a/foo.go", "a/bar.go"}); got != "a" {
+		t.Errorf("expected a, got %s", got)
+	}
+}
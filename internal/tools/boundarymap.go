@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// boundaryKinds are the boundary kinds boundary_map can report.
+var boundaryKinds = []string{"cgo", "python_extension", "subprocess", "http", "grpc"}
+
+func boundaryMap(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("boundary_map",
+			mcp.WithDescription("Find cross-language call boundaries that import analysis misses: cgo, imports of Python native extensions (pybind11, PyO3, Cython, C API), subprocess runs of binaries a project builds, and HTTP or gRPC clients reaching a project's server. Each call site names the project on the other end when it resolves; cross_project_deps includes the edges that cross projects."),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
+			),
+			mcp.WithArray("kinds",
+				mcp.Description("Only these boundary kinds: cgo, python_extension, subprocess, http, grpc (default all)"),
+				mcp.WithStringItems(),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root := stringOr(args["root"], "")
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+			kinds := stringSlice(args["kinds"])
+			for _, k := range kinds {
+				if !slices.Contains(boundaryKinds, k) {
+					return mcputil.ValidationError(fmt.Sprintf("unknown boundary kind %q", k))
+				}
+			}
+
+			pyArgs := map[string]any{"max_depth": registry.MaxDepth()}
+			if len(kinds) > 0 {
+				pyArgs["kinds"] = kinds
+			}
+			result, err := bridge.Run(ctx, "boundary_map", root, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
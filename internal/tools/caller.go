@@ -0,0 +1,28 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Caller runs a tool by name, for in-process front ends such as the
+// explorer and the editor endpoint.
+type Caller func(ctx context.Context, tool string, args map[string]any) (*mcp.CallToolResult, error)
+
+// ServerCaller calls s's registered tools directly, through their
+// middleware, without an MCP transport.
+func ServerCaller(s *server.MCPServer) Caller {
+	return func(ctx context.Context, tool string, args map[string]any) (*mcp.CallToolResult, error) {
+		t := s.GetTool(tool)
+		if t == nil {
+			return nil, fmt.Errorf("unknown tool %s", tool)
+		}
+		req := mcp.CallToolRequest{}
+		req.Params.Name = tool
+		req.Params.Arguments = args
+		return t.Handler(ctx, req)
+	}
+}
@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/vcs"
+	"github.com/mistakeknot/intermap/internal/workflows"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// ProjectWorkflows is the ci_map entry for one repository.
+type ProjectWorkflows struct {
+	Project   string               `json:"project"`
+	Path      string               `json:"path"`
+	Workflows []workflows.Workflow `json:"workflows"`
+	// ChangedFiles and Runs are set when files or git_base were given.
+	ChangedFiles []string        `json:"changed_files,omitempty"`
+	Runs         []workflows.Run `json:"runs,omitempty"`
+	Errors       []string        `json:"errors,omitempty"`
+}
+
+func ciMap() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("ci_map",
+			mcp.WithDescription("Map GitHub Actions workflows per project: triggers, path filters, and jobs, with stale path filters that match no tracked file. Given changed files or a git base, predicts which workflows run."),
+			mcp.WithString("project",
+				mcp.Description("Repository path; omit to scan every project under root"),
+			),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan when project is omitted (defaults to CWD)"),
+			),
+			mcp.WithArray("files",
+				mcp.Description("Changed files (repository-relative) to predict workflow runs for"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithString("git_base",
+				mcp.Description("Git ref to diff against to get the changed files (ignored when files is given)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			files := stringSlice(args["files"])
+			base := stringOr(args["git_base"], "")
+			if base != "" {
+				if err := vcs.CheckRef(base); err != nil {
+					return mcputil.ValidationError("git_base: %v", err)
+				}
+			}
+
+			var repos []registry.Project
+			if project := stringOr(args["project"], ""); project != "" {
+				repos = []registry.Project{{Name: filepath.Base(project), Path: project}}
+			} else {
+				root := stringOr(args["root"], "")
+				if root == "" {
+					var err error
+					root, err = os.Getwd()
+					if err != nil {
+						return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+					}
+				}
+				var err error
+				repos, err = registry.Scan(root)
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("scan: %w", err))
+				}
+			}
+
+			result := []ProjectWorkflows{}
+			for _, p := range repos {
+				pw, ok := mapWorkflows(ctx, p, files, base)
+				if ok {
+					result = append(result, pw)
+				}
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+// mapWorkflows builds the ci_map entry for one repository; ok is false when
+// it has no workflows.
+func mapWorkflows(ctx context.Context, p registry.Project, files []string, base string) (ProjectWorkflows, bool) {
+	ws, errs := workflows.Load(p.Path)
+	pw := ProjectWorkflows{Project: p.Name, Path: p.Path, Workflows: ws}
+	for _, err := range errs {
+		pw.Errors = append(pw.Errors, err.Error())
+	}
+	if len(ws) == 0 && len(errs) == 0 {
+		return pw, false
+	}
+	if pw.Workflows == nil {
+		pw.Workflows = []workflows.Workflow{}
+	}
+
+	tracked, err := workflows.RepoFiles(p.Path)
+	if err != nil {
+		pw.Errors = append(pw.Errors, err.Error())
+	} else {
+		for i := range pw.Workflows {
+			pw.Workflows[i].MarkStale(tracked)
+		}
+	}
+
+	changed := files
+	if len(changed) == 0 && base != "" {
+		repo, err := vcs.Find(p.Path)
+		if err == nil {
+			changed, err = repo.ChangedSince(ctx, repo.Root(), base)
+		}
+		if err != nil {
+			pw.Errors = append(pw.Errors, fmt.Sprintf("diff %s: %v", base, err))
+			return pw, true
+		}
+		if changed == nil {
+			changed = []string{}
+		}
+	}
+	if changed != nil {
+		pw.ChangedFiles = changed
+		pw.Runs = workflows.Runs(pw.Workflows, changed)
+	}
+	return pw, true
+}
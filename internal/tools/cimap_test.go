@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCIMap_GitBase(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=ada", "GIT_AUTHOR_EMAIL=ada@example.com",
+			"GIT_COMMITTER_NAME=ada", "GIT_COMMITTER_EMAIL=ada@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(rel, body string) {
+		t.Helper()
+		path := filepath.Join(repo, rel)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write(".github/workflows/ci.yml", "name: CI\non:\n  push:\n    paths: [\"**.go\"]\njobs:\n  test:\n    runs-on: ubuntu-latest\n")
+	write("main.go", "package main\n")
+	write("README.md", "hi\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "one")
+	write("main.go", "package main\n\nfunc main() {}\n")
+
+	run := func(base string) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]any{"project": repo, "git_base": base}
+		res, err := ciMap().Handler(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	var got []ProjectWorkflows
+	res := run("HEAD")
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got); err != nil || len(got) != 1 {
+		t.Fatalf("%v: %s", err, res.Content[0].(mcp.TextContent).Text)
+	}
+	if !reflect.DeepEqual(got[0].ChangedFiles, []string{"main.go"}) || len(got[0].Runs) != 1 {
+		t.Errorf("changed %v, runs %+v", got[0].ChangedFiles, got[0].Runs)
+	}
+
+	leak := filepath.Join(t.TempDir(), "leak")
+	if res := run("--output=" + leak); !res.IsError {
+		t.Error("git_base accepted an option")
+	}
+	if _, err := os.Stat(leak); !os.IsNotExist(err) {
+		t.Errorf("git wrote %s: %v", leak, err)
+	}
+}
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+func codemodPlan(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("codemod_plan",
+			mcp.WithDescription("Plan a structural rewrite across the workspace without applying it: every matching site with its rendered replacement, grouped by project and CODEOWNERS owner, and an ordered migration plan where projects follow the projects they depend on."),
+			mcp.WithString("root",
+				mcp.Description("Monorepo root directory to scan"),
+				mcp.Required(),
+			),
+			mcp.WithString("pattern",
+				mcp.Description("Structural match template (code_search syntax: :[x] balanced text, :[[x]] identifier), or a sentence like 'replace calls to client.ListAgents with client.Agents.List'"),
+				mcp.Required(),
+			),
+			mcp.WithString("rewrite",
+				mcp.Description("Rewrite template using the pattern's holes, e.g. client.Agents.List(:[args])"),
+			),
+			mcp.WithString("language",
+				mcp.Description("Only files of this language: python, go, typescript, javascript, rust, java, c, ruby, shell"),
+			),
+			mcp.WithArray("projects",
+				mcp.Description("Only plan for these projects (names)"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithNumber("max_results",
+				mcp.Description("Maximum sites to collect (default 2000)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root, _ := args["root"].(string)
+			if root == "" {
+				return mcputil.ValidationError("root is required")
+			}
+			pattern, _ := args["pattern"].(string)
+			if pattern == "" {
+				return mcputil.ValidationError("pattern is required")
+			}
+
+			pyArgs := map[string]any{
+				"pattern":     pattern,
+				"max_depth":   registry.MaxDepth(),
+				"max_results": intOr(args["max_results"], 2000),
+			}
+			if rewrite, ok := args["rewrite"].(string); ok {
+				pyArgs["rewrite"] = rewrite
+			}
+			if lang := stringOr(args["language"], ""); lang != "" {
+				pyArgs["language"] = lang
+			}
+			if projects := stringSlice(args["projects"]); len(projects) > 0 {
+				pyArgs["projects"] = projects
+			}
+
+			// Pass root as the "project" positional arg to bridge.Run
+			result, err := bridge.Run(ctx, "codemod_plan", root, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+func codeSearch(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("code_search",
+			mcp.WithDescription("Search source code with a regex, literal text, or a comby-style structural pattern (:[x] matches balanced text, :[[x]] an identifier, ... anything). Each match carries its project and enclosing symbol with its symbol ID, so results link into the code map."),
+			mcp.WithString("pattern",
+				mcp.Description("Pattern to search for"),
+				mcp.Required(),
+			),
+			mcp.WithString("mode",
+				mcp.Description("regex (default), literal, or structural"),
+			),
+			mcp.WithString("project",
+				mcp.Description("Project path to search (default: the whole workspace under root)"),
+			),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to search when no project is given (defaults to CWD)"),
+			),
+			mcp.WithString("language",
+				mcp.Description("Only files of this language: python, go, typescript, javascript, rust, java, c, ruby, shell"),
+			),
+			mcp.WithString("glob",
+				mcp.Description("Only files whose path or name matches this glob, e.g. 'internal/**' or '*_test.go'"),
+			),
+			mcp.WithBoolean("ignore_case",
+				mcp.Description("Match case-insensitively (default false)"),
+			),
+			mcp.WithNumber("max_results",
+				mcp.Description("Maximum matches to return (default 500)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			pattern := stringOr(args["pattern"], "")
+			if pattern == "" {
+				return mcputil.ValidationError("pattern is required")
+			}
+			mode := stringOr(args["mode"], "regex")
+			switch mode {
+			case "regex", "literal", "structural":
+			default:
+				return mcputil.ValidationError("unknown mode %q (want regex, literal, or structural)", mode)
+			}
+
+			path := stringOr(args["project"], stringOr(args["root"], ""))
+			if path == "" {
+				var err error
+				path, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+
+			pyArgs := map[string]any{
+				"pattern":     pattern,
+				"mode":        mode,
+				"ignore_case": boolOr(args["ignore_case"], false),
+				"max_depth":   registry.MaxDepth(),
+				"max_results": intOr(args["max_results"], 500),
+			}
+			if lang := stringOr(args["language"], ""); lang != "" {
+				pyArgs["language"] = lang
+			}
+			if glob := stringOr(args["glob"], ""); glob != "" {
+				pyArgs["glob"] = glob
+			}
+
+			result, err := bridge.Run(ctx, "code_search", path, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
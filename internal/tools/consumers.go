@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+func consumers(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("consumers",
+			mcp.WithDescription("List every file in other workspace projects that imports a project, or one package or module of it, grouped by imported package — the reverse of cross_project_deps at package granularity. Also names projects that declare the dependency in a manifest but never import it."),
+			mcp.WithString("root",
+				mcp.Description("Monorepo root directory to scan"),
+				mcp.Required(),
+			),
+			mcp.WithString("project",
+				mcp.Description("Target project name or path"),
+				mcp.Required(),
+			),
+			mcp.WithString("package",
+				mcp.Description("Only imports of this package: Go import path or project-relative directory, Python dotted module, JS subpath or specifier, or Rust module path"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root, _ := args["root"].(string)
+			if root == "" {
+				return mcputil.ValidationError("root is required")
+			}
+			project, _ := args["project"].(string)
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+
+			pyArgs := map[string]any{
+				"project":   project,
+				"max_depth": registry.MaxDepth(),
+			}
+			if pkg := stringOr(args["package"], ""); pkg != "" {
+				pyArgs["package"] = pkg
+			}
+
+			// Pass root as the "project" positional arg to bridge.Run
+			result, err := bridge.Run(ctx, "consumers", root, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
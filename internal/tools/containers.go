@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/containers"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// ContainerMapResult is the response for the container_map tool.
+type ContainerMapResult struct {
+	Root        string                  `json:"root"`
+	Services    []containers.Service    `json:"services"`
+	Dockerfiles []containers.Dockerfile `json:"dockerfiles"`
+	// Unlinked names services that could not be tied to a project.
+	Unlinked []string `json:"unlinked"`
+}
+
+func containerMap() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("container_map",
+			mcp.WithDescription("Deployment topology: parse Dockerfiles, docker-compose files, and Kubernetes workload manifests, linking each service to the project whose code it packages, with its image, ports, env var names, and dependencies."),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root := stringOr(args["root"], "")
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+
+			m, err := containers.Scan(root)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			projects, err := registry.Scan(root)
+			if err != nil {
+				return mcputil.WrapError(fmt.Errorf("scan: %w", err))
+			}
+			m.Link(root, projects)
+
+			result := ContainerMapResult{Root: root, Services: m.Services, Dockerfiles: m.Dockerfiles, Unlinked: []string{}}
+			for _, s := range m.Services {
+				if s.Project == "" {
+					result.Unlinked = append(result.Unlinked, s.File+":"+s.Name)
+				}
+			}
+			return jsonResult(result)
+		},
+	}
+}
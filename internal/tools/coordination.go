@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mistakeknot/intermap/internal/coordination"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// notConfigured explains an unavailable coordination provider.
+func notConfigured(c coordination.Provider) string {
+	if c.Name() == "intermute" {
+		return "intermute not configured (INTERMUTE_URL not set)"
+	}
+	return fmt.Sprintf("%s coordination provider not configured", c.Name())
+}
+
+// listTasks returns all tasks, or none if c does not track tasks.
+func listTasks(ctx context.Context, c coordination.Provider) ([]coordination.Task, error) {
+	if tl, ok := c.(coordination.TaskLister); ok {
+		return tl.ListTasks(ctx, "")
+	}
+	return nil, nil
+}
+
+// listMessages returns all messages, or none if c does not track messages.
+func listMessages(ctx context.Context, c coordination.Provider) ([]coordination.Message, error) {
+	if ml, ok := c.(coordination.MessageLister); ok {
+		return ml.ListMessages(ctx, "")
+	}
+	return nil, nil
+}
+
+// releaseReservation releases id, or returns coordination.ErrNotSupported
+// if c cannot release reservations.
+func releaseReservation(ctx context.Context, c coordination.Provider, id string) error {
+	if r, ok := c.(coordination.Releaser); ok {
+		return r.ReleaseReservation(ctx, id)
+	}
+	return coordination.ErrNotSupported
+}
+
+// maxScopedFetches bounds concurrent per-project reservation requests.
+const maxScopedFetches = 8
+
+// coordinationSnapshot is what agent_map reads from a provider, with the
+// error from each part.
+type coordinationSnapshot struct {
+	agents       []coordination.Agent
+	reservations []coordination.Reservation
+	tasks        []coordination.Task
+	messages     []coordination.Message
+
+	agentsErr, reservationsErr, tasksErr, messagesErr error
+}
+
+// fetchCoordination reads agents, reservations, tasks, and messages from c
+// in one overlay request when the provider offers one, and otherwise with
+// concurrent requests. When scoped is set, reservations are fetched per
+// project in projects instead of all at once.
+func fetchCoordination(ctx context.Context, c coordination.Provider, projects []registry.Project, scoped bool) coordinationSnapshot {
+	if src, ok := c.(coordination.OverlaySource); ok {
+		overlay, err := src.GetOverlay(ctx)
+		if err == nil {
+			return coordinationSnapshot{
+				agents:       overlay.Agents,
+				reservations: overlay.Reservations,
+				tasks:        overlay.Tasks,
+				messages:     overlay.Messages,
+			}
+		}
+		if !errors.Is(err, coordination.ErrNotSupported) {
+			return coordinationSnapshot{agentsErr: err}
+		}
+	}
+
+	var snap coordinationSnapshot
+	var wg sync.WaitGroup
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		snap.agents, snap.agentsErr = c.ListAgents(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		if scoped {
+			snap.reservations, snap.reservationsErr = scopedReservations(ctx, c, projects)
+		} else {
+			snap.reservations, snap.reservationsErr = c.ListReservations(ctx, "")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		snap.tasks, snap.tasksErr = listTasks(ctx, c)
+	}()
+	go func() {
+		defer wg.Done()
+		snap.messages, snap.messagesErr = listMessages(ctx, c)
+	}()
+	wg.Wait()
+	return snap
+}
+
+// scopedReservations fetches the reservations of each distinct project
+// name, deduplicated by ID. Reservations without a project are not seen.
+func scopedReservations(ctx context.Context, c coordination.Provider, projects []registry.Project) ([]coordination.Reservation, error) {
+	names := make([]string, 0, len(projects))
+	seen := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		if !seen[p.Name] {
+			seen[p.Name] = true
+			names = append(names, p.Name)
+		}
+	}
+
+	results := make([][]coordination.Reservation, len(names))
+	errs := make([]error, len(names))
+	sem := make(chan struct{}, maxScopedFetches)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = c.ListReservations(ctx, name)
+		}()
+	}
+	wg.Wait()
+
+	var out []coordination.Reservation
+	ids := make(map[string]bool)
+	for i, rs := range results {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("project %s: %w", names[i], errs[i])
+		}
+		for _, r := range rs {
+			if !ids[r.ID] {
+				ids[r.ID] = true
+				out = append(out, r)
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mistakeknot/intermap/client"
+	"github.com/mistakeknot/intermap/client/clienttest"
+	"github.com/mistakeknot/intermap/internal/coordination"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+func TestFetchCoordination(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	overlay := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path+"?"+r.URL.RawQuery]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/api/overlay":
+			if !overlay {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(client.Overlay{
+				Agents:       []client.Agent{{AgentID: "a1"}},
+				Reservations: []client.Reservation{{ID: "r1", AgentID: "a1", IsActive: true}},
+			})
+		case "/api/agents":
+			json.NewEncoder(w).Encode([]client.Agent{{AgentID: "a1"}, {AgentID: "a2"}})
+		case "/api/reservations":
+			var rs []client.Reservation
+			switch r.URL.Query().Get("project") {
+			case "":
+				rs = []client.Reservation{{ID: "r1"}, {ID: "r2"}, {ID: "r3"}}
+			case "intermap":
+				rs = []client.Reservation{{ID: "r1", Project: "intermap"}}
+			case "clavain":
+				rs = []client.Reservation{{ID: "r2", Project: "clavain"}}
+			}
+			json.NewEncoder(w).Encode(rs)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	projects := []registry.Project{{Name: "intermap"}, {Name: "clavain"}, {Name: "intermap", Group: "forks"}}
+	ctx := context.Background()
+
+	c := coordination.NewIntermute(client.NewClient(client.WithBaseURL(ts.URL)))
+	snap := fetchCoordination(ctx, c, projects, false)
+	if snap.agentsErr != nil || len(snap.agents) != 1 || len(snap.reservations) != 1 {
+		t.Fatalf("overlay snapshot = %+v", snap)
+	}
+	if hits["/api/agents?"] != 0 {
+		t.Errorf("overlay should replace separate requests: %v", hits)
+	}
+
+	overlay = false
+	c = coordination.NewIntermute(client.NewClient(client.WithBaseURL(ts.URL)))
+	for range 2 {
+		snap = fetchCoordination(ctx, c, projects, false)
+	}
+	if snap.agentsErr != nil || len(snap.agents) != 2 || len(snap.reservations) != 3 {
+		t.Fatalf("fallback snapshot = %+v", snap)
+	}
+	if hits["/api/overlay?"] != 2 {
+		t.Errorf("a missing overlay endpoint should be probed once per client: %v", hits)
+	}
+
+	snap = fetchCoordination(ctx, c, projects, true)
+	if snap.reservationsErr != nil || len(snap.reservations) != 2 {
+		t.Fatalf("scoped reservations = %+v, %v", snap.reservations, snap.reservationsErr)
+	}
+	if hits["/api/reservations?project=intermap"] != 1 {
+		t.Errorf("duplicate project names should be fetched once: %v", hits)
+	}
+}
+
+func TestBuildAgentMap_Intermute(t *testing.T) {
+	root := t.TempDir()
+	for _, p := range []string{"intermap", "interlock"} {
+		if err := os.MkdirAll(filepath.Join(root, p, ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := clienttest.NewServer(t,
+		clienttest.WithoutOverlay(),
+		clienttest.WithAgents(
+			client.Agent{AgentID: "a1", Name: "builder", Project: "intermap", Status: "active"},
+			client.Agent{AgentID: "a2", Name: "reviewer", Status: "idle"},
+		),
+		clienttest.WithReservations(client.Reservation{AgentID: "a2", Pattern: filepath.Join(root, "interlock", "*.go"), IsActive: true}),
+		clienttest.WithTasks(client.Task{ID: "t1", AgentID: "a1", Title: "wire budgets", Status: "in_progress"}),
+	)
+	c := coordination.NewIntermute(srv.Client(client.WithRetryPolicy(client.RetryPolicy{})))
+	ctx := context.Background()
+
+	result, err := buildAgentMap(ctx, c, root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, a := range result.Agents {
+		got[a.Name] = filepath.Base(a.ProjectPath) + "/" + a.CurrentTask
+	}
+	want := map[string]string{"builder": "intermap/wire budgets", "reviewer": "interlock/"}
+	if result.AgentsError != "" || !reflect.DeepEqual(got, want) {
+		t.Errorf("agents = %v (error %q), want %v", got, result.AgentsError, want)
+	}
+
+	// A failing reservations endpoint still lists the agents.
+	srv.Fail(clienttest.RouteListReservations, clienttest.Fault{Status: http.StatusServiceUnavailable})
+	result, err = buildAgentMap(ctx, c, root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Agents) != 2 || !strings.Contains(result.AgentsError, "reservations unavailable") {
+		t.Errorf("agents = %d, error = %q", len(result.Agents), result.AgentsError)
+	}
+}
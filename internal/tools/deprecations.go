@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+func deprecations(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("deprecations",
+			mcp.WithDescription("Find deprecated symbols (Go \"Deprecated:\" doc paragraphs, Python @deprecated or DeprecationWarning, TS/JS @deprecated JSDoc) and the uses of each still left across the workspace, with a burn-down list per project."),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory (defaults to CWD)"),
+			),
+			mcp.WithString("project",
+				mcp.Description("Only symbols defined in this project (name or path); uses are still found workspace-wide"),
+			),
+			mcp.WithNumber("max_sites",
+				mcp.Description("Uses listed per symbol; all are counted (default 20)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root := stringOr(args["root"], "")
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+
+			pyArgs := map[string]any{
+				"max_sites": intOr(args["max_sites"], 20),
+				"max_depth": registry.MaxDepth(),
+			}
+			if project := stringOr(args["project"], ""); project != "" {
+				pyArgs["project"] = project
+			}
+
+			// Pass root as the "project" positional arg to bridge.Run
+			result, err := bridge.Run(ctx, "deprecations", root, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
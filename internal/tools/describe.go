@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+)
+
+func describeSymbol(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("describe_symbol",
+			mcp.WithDescription("Summarize a function or method without reading its file: parameters, return types, raised errors, project callees and callers, external calls, and statically detected side effects (filesystem, network, subprocess, env, database). Deterministic and cached in the project index."),
+			mcp.WithString("project",
+				mcp.Description("Project root path"),
+				mcp.Required(),
+			),
+			mcp.WithString("symbol",
+				mcp.Description("Function name, Scope.name for methods, or a symbol ID"),
+				mcp.Required(),
+			),
+			mcp.WithString("file",
+				mcp.Description("Project-relative file, to pick one of several same-named symbols"),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (defaults to the detected project language)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project, _ := args["project"].(string)
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+			symbol := stringOr(args["symbol"], "")
+			if symbol == "" {
+				return mcputil.ValidationError("symbol is required")
+			}
+
+			pyArgs := map[string]any{
+				"symbol":   symbol,
+				"language": languageOr(args["language"], project),
+			}
+			if file := stringOr(args["file"], ""); file != "" {
+				pyArgs["file"] = file
+			}
+			result, err := bridge.Run(ctx, "describe_symbol", project, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
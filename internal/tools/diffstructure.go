@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+)
+
+func structureOfDiff(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("structure_of_diff",
+			mcp.WithDescription("Outline only the files a diff touches: parses each changed file at the diff's new side and returns its symbols with line spans, marking which ones the hunks intersect. Cheaper than code_structure for PR review. Python spans are exact; other languages run each symbol to the next one and are flagged approximate."),
+			mcp.WithString("project",
+				mcp.Description("Project root directory (must be in a git repo)"),
+				mcp.Required(),
+			),
+			mcp.WithString("base",
+				mcp.Description("Git ref the diff starts from (default HEAD)"),
+			),
+			mcp.WithString("head",
+				mcp.Description("Git ref the diff ends at (default: the working tree)"),
+			),
+			mcp.WithArray("files",
+				mcp.Description("Only report these project-relative files"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithNumber("max_files",
+				mcp.Description("Maximum changed files to outline (default 200)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project, _ := args["project"].(string)
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+			maxFiles := intOr(args["max_files"], 200)
+			if maxFiles < 1 {
+				return mcputil.ValidationError("max_files must be at least 1")
+			}
+
+			base, head := stringOr(args["base"], "HEAD"), stringOr(args["head"], "")
+			if strings.HasPrefix(base, "-") || strings.HasPrefix(head, "-") {
+				return mcputil.ValidationError("base and head must be git refs, not options")
+			}
+
+			pyArgs := map[string]any{
+				"base":      base,
+				"max_files": maxFiles,
+			}
+			if head != "" {
+				pyArgs["head"] = head
+			}
+			if files := stringSlice(args["files"]); len(files) > 0 {
+				pyArgs["files"] = files
+			}
+
+			result, err := bridge.Run(ctx, "structure_of_diff", project, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
@@ -0,0 +1,295 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermap/analysis"
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+	"github.com/mistakeknot/intermap/internal/webhook"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// Reindex schedules background re-indexing of hot projects, comparing each
+// run's graph with the previous run's to detect drift. No jobs disables it.
+type Reindex struct {
+	Jobs []ReindexJob
+	// FanIn is the caller count at which a symbol counts as high fan-in
+	// (default 10).
+	FanIn int
+	// Dir holds the previous snapshots (default <user cache>/intermap/drift).
+	Dir string
+}
+
+// ReindexJob re-indexes one project every Every.
+type ReindexJob struct {
+	Project string
+	// Language defaults to the detected project language.
+	Language string
+	Every    time.Duration
+}
+
+var reindex Reindex
+
+// SetReindex configures scheduled re-indexing. Call before StartReindex.
+func SetReindex(r Reindex) {
+	if r.FanIn <= 0 {
+		r.FanIn = 10
+	}
+	reindex = r
+}
+
+// ParseSchedule parses a job interval: a Go duration ("30m") or @hourly,
+// @daily, or @weekly.
+func ParseSchedule(s string) (time.Duration, error) {
+	switch s {
+	case "@hourly":
+		return time.Hour, nil
+	case "@daily":
+		return 24 * time.Hour, nil
+	case "@weekly":
+		return 7 * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("bad schedule %q: want a duration or @hourly, @daily, @weekly", s)
+	}
+	if d < time.Minute {
+		return 0, fmt.Errorf("schedule %q is under a minute", s)
+	}
+	return d, nil
+}
+
+// DriftSnapshot is the part of a project's graph drift is measured on.
+type DriftSnapshot struct {
+	Project  string    `json:"project"`
+	Language string    `json:"language"`
+	Taken    time.Time `json:"taken"`
+	Symbols  int       `json:"symbols"`
+	Edges    int       `json:"edges"`
+	// Cycles are the file-level dependency cycles.
+	Cycles [][]string `json:"cycles"`
+	// HighFanIn maps symbols (file:name) with at least the fan-in
+	// threshold's callers to their caller count.
+	HighFanIn map[string]int `json:"high_fan_in"`
+}
+
+// Drift is what changed for the worse between two snapshots.
+type Drift struct {
+	Project  string `json:"project"`
+	Language string `json:"language"`
+	// Since is when the previous snapshot was taken.
+	Since        time.Time     `json:"since"`
+	NewCycles    [][]string    `json:"new_cycles"`
+	NewHighFanIn []FanInSymbol `json:"new_high_fan_in"`
+}
+
+// FanInSymbol is a symbol that crossed the fan-in threshold.
+type FanInSymbol struct {
+	Symbol  string `json:"symbol"`
+	Callers int    `json:"callers"`
+	// Previous is its caller count in the previous snapshot.
+	Previous int `json:"previous"`
+}
+
+// Empty reports whether nothing drifted.
+func (d Drift) Empty() bool {
+	return len(d.NewCycles) == 0 && len(d.NewHighFanIn) == 0
+}
+
+// Message is a one-line description of the drift.
+func (d Drift) Message() string {
+	var parts []string
+	for _, c := range d.NewCycles {
+		parts = append(parts, "new cycle "+strings.Join(c, " -> "))
+	}
+	for _, s := range d.NewHighFanIn {
+		parts = append(parts, fmt.Sprintf("%s now has %d callers (was %d)", s.Symbol, s.Callers, s.Previous))
+	}
+	return fmt.Sprintf("%s drifted since %s: %s", filepath.Base(d.Project), d.Since.Format(time.RFC3339), strings.Join(parts, "; "))
+}
+
+// TakeDriftSnapshot refreshes project's index and snapshots its graph.
+func TakeDriftSnapshot(ctx context.Context, bridge analysis.Backend, project, language string, fanIn int) (*DriftSnapshot, error) {
+	args := map[string]any{"language": language, "baseline": "HEAD"}
+	if hash, err := registry.MtimeHash(project); err == nil {
+		args["mtime_hash"] = hash
+	}
+	if _, err := bridge.Run(ctx, "index_update", project, args); err != nil {
+		return nil, fmt.Errorf("index_update: %w", err)
+	}
+	// Bypass the result cache: the point is a fresh graph.
+	rd, err := fetchRefData(context.WithValue(ctx, noResultCacheKey{}, true), bridge, project, language, 5000)
+	if err != nil {
+		return nil, err
+	}
+	symbols := rd.symbolGraph()
+	s := &DriftSnapshot{
+		Project:   project,
+		Language:  language,
+		Taken:     time.Now().UTC(),
+		Symbols:   len(rd.Definitions),
+		Edges:     symbols.EdgeCount(),
+		Cycles:    rd.fileGraph(false).Cycles(),
+		HighFanIn: map[string]int{},
+	}
+	for _, id := range symbols.Nodes() {
+		if n := symbols.InDegree(id); n >= fanIn {
+			s.HighFanIn[id] = n
+		}
+	}
+	return s, nil
+}
+
+// CompareSnapshots returns the cycles and high fan-in symbols in cur that
+// prev did not have.
+func CompareSnapshots(prev, cur *DriftSnapshot) Drift {
+	d := Drift{Project: cur.Project, Language: cur.Language, Since: prev.Taken,
+		NewCycles: [][]string{}, NewHighFanIn: []FanInSymbol{}}
+	old := make(map[string]bool, len(prev.Cycles))
+	for _, c := range prev.Cycles {
+		old[strings.Join(c, "\x00")] = true
+	}
+	for _, c := range cur.Cycles {
+		if !old[strings.Join(c, "\x00")] {
+			d.NewCycles = append(d.NewCycles, c)
+		}
+	}
+	for id, n := range cur.HighFanIn {
+		if _, was := prev.HighFanIn[id]; !was {
+			d.NewHighFanIn = append(d.NewHighFanIn, FanInSymbol{Symbol: id, Callers: n})
+		}
+	}
+	slices.SortFunc(d.NewHighFanIn, func(a, b FanInSymbol) int {
+		if a.Callers != b.Callers {
+			return b.Callers - a.Callers
+		}
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+	return d
+}
+
+// StartReindex runs each configured job now and then on its schedule until
+// the returned stop function is called, passing drift to notify and to
+// the drift webhook event. It does nothing without jobs.
+func StartReindex(bridge analysis.Backend, notify func(Drift)) (stop func()) {
+	r := reindex
+	if len(r.Jobs) == 0 {
+		return func() {}
+	}
+	dir := r.Dir
+	if dir == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap: reindex: %v\n", err)
+			return func() {}
+		}
+		dir = filepath.Join(cache, "intermap", "drift")
+	}
+	ctx, cancel := context.WithCancel(pybridge.WithPriority(context.Background(), pybridge.Background))
+	done := make(chan struct{}, len(r.Jobs))
+	for _, job := range r.Jobs {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			ticker := time.NewTicker(job.Every)
+			defer ticker.Stop()
+			for {
+				drift, err := reindexJob(ctx, bridge, job, r.FanIn, dir)
+				switch {
+				case err != nil && ctx.Err() == nil:
+					fmt.Fprintf(os.Stderr, "intermap: reindex %s: %v\n", job.Project, err)
+				case drift != nil:
+					notify(*drift)
+					webhooks.Emit(webhook.EventDrift, drift.Project, map[string]any{
+						"since":           drift.Since,
+						"new_cycles":      drift.NewCycles,
+						"new_high_fan_in": drift.NewHighFanIn,
+					})
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+	return func() {
+		cancel()
+		for range r.Jobs {
+			<-done
+		}
+	}
+}
+
+// reindexJob snapshots job's project, stores the snapshot in dir, and
+// returns the drift from the stored previous one, or nil when there was
+// none or nothing drifted.
+func reindexJob(ctx context.Context, bridge analysis.Backend, job ReindexJob, fanIn int, dir string) (*Drift, error) {
+	language := languageOr(job.Language, job.Project)
+	cur, err := TakeDriftSnapshot(ctx, bridge, job.Project, language, fanIn)
+	if err != nil {
+		return nil, err
+	}
+	path := snapshotPath(dir, job.Project, language)
+	prev, err := loadSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveSnapshot(path, cur); err != nil {
+		return nil, err
+	}
+	if prev == nil {
+		return nil, nil
+	}
+	d := CompareSnapshots(prev, cur)
+	if d.Empty() {
+		return nil, nil
+	}
+	return &d, nil
+}
+
+func snapshotPath(dir, project, language string) string {
+	sum := sha256.Sum256([]byte(project + "\x00" + language))
+	return filepath.Join(dir, filepath.Base(project)+"-"+hex.EncodeToString(sum[:6])+".json")
+}
+
+// loadSnapshot reads a snapshot, or returns nil when there is none.
+func loadSnapshot(path string) (*DriftSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s DriftSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &s, nil
+}
+
+func saveSnapshot(path string, s *DriftSnapshot) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
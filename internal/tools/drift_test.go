@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// edgeBackend answers index_update and reference_edges with edges.
+type edgeBackend struct {
+	edges [][2]string // "file:symbol" pairs
+}
+
+func (b *edgeBackend) Run(ctx context.Context, command, project string, args map[string]any) (map[string]any, error) {
+	switch command {
+	case "index_update":
+		return map[string]any{"mode": "incremental"}, nil
+	case "reference_edges":
+		var edges []any
+		for _, e := range b.edges {
+			srcFile, srcSymbol := splitSymbol(e[0])
+			dstFile, dstSymbol := splitSymbol(e[1])
+			edges = append(edges, map[string]any{"src_file": srcFile, "src_symbol": srcSymbol, "dst_file": dstFile, "dst_symbol": dstSymbol})
+		}
+		return map[string]any{"language": "go", "definitions": []any{}, "edges": edges}, nil
+	}
+	return nil, fmt.Errorf("unexpected command %s", command)
+}
+
+func splitSymbol(id string) (string, string) {
+	file, symbol, _ := strings.Cut(id, ":")
+	return file, symbol
+}
+
+func TestReindexDrift(t *testing.T) {
+	dir := t.TempDir()
+	project := t.TempDir()
+	job := ReindexJob{Project: project, Language: "go", Every: time.Hour}
+	b := &edgeBackend{edges: [][2]string{
+		{"a.go:A", "b.go:B"},
+		{"c.go:C", "b.go:B"},
+	}}
+
+	// The first run only records a baseline.
+	if d, err := reindexJob(context.Background(), b, job, 2, dir); err != nil || d != nil {
+		t.Fatalf("baseline: %+v, %v", d, err)
+	}
+	// Unchanged: no drift.
+	if d, err := reindexJob(context.Background(), b, job, 2, dir); err != nil || d != nil {
+		t.Fatalf("unchanged: %+v, %v", d, err)
+	}
+
+	b.edges = append(b.edges,
+		[2]string{"b.go:B", "a.go:A"}, // a.go <-> b.go cycle
+		[2]string{"a.go:A", "d.go:D"},
+		[2]string{"c.go:C", "d.go:D"},
+		[2]string{"b.go:B", "d.go:D"},
+	)
+	d, err := reindexJob(context.Background(), b, job, 2, dir)
+	if err != nil || d == nil {
+		t.Fatalf("drift: %+v, %v", d, err)
+	}
+	if len(d.NewCycles) != 1 || fmt.Sprint(d.NewCycles[0]) != "[a.go b.go]" {
+		t.Errorf("new cycles = %v", d.NewCycles)
+	}
+	if len(d.NewHighFanIn) != 1 || d.NewHighFanIn[0] != (FanInSymbol{Symbol: "d.go:D", Callers: 3}) {
+		t.Errorf("new high fan-in = %+v", d.NewHighFanIn)
+	}
+	if d.Project != project || d.Since.IsZero() || d.Message() == "" {
+		t.Errorf("drift = %+v", d)
+	}
+
+	// The drift is now the baseline.
+	if d, err := reindexJob(context.Background(), b, job, 2, dir); err != nil || d != nil {
+		t.Fatalf("after drift: %+v, %v", d, err)
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"@hourly": time.Hour,
+		"@daily":  24 * time.Hour,
+		"@weekly": 7 * 24 * time.Hour,
+		"90m":     90 * time.Minute,
+	} {
+		if got, err := ParseSchedule(in); err != nil || got != want {
+			t.Errorf("ParseSchedule(%s) = %v, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"", "@monthly", "5s", "0 * * * *"} {
+		if _, err := ParseSchedule(in); err == nil {
+			t.Errorf("ParseSchedule(%q): no error", in)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+)
+
+func effectsAnalysis(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("effects_analysis",
+			mcp.WithDescription("Classify functions by capability (filesystem, network, subprocess, env, database): directly when they call a known sink API, transitively when they reach such a function through the call graph, with the shortest chain. Use it to size a change's blast radius and pick security review targets."),
+			mcp.WithString("project",
+				mcp.Description("Project root path"),
+				mcp.Required(),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (defaults to the detected project language)"),
+			),
+			mcp.WithArray("capabilities",
+				mcp.Description("Only these capabilities: filesystem, network, subprocess, env, database (default all)"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithArray("files",
+				mcp.Description("Only report functions defined in these project-relative files, e.g. the files a change touches"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithBoolean("include_pure",
+				mcp.Description("Also list functions with no capability (default false)"),
+			),
+			mcp.WithNumber("max_results",
+				mcp.Description("Maximum functions to return (default 1000)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project, _ := args["project"].(string)
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+
+			pyArgs := map[string]any{
+				"language":     languageOr(args["language"], project),
+				"include_pure": boolOr(args["include_pure"], false),
+				"max_results":  intOr(args["max_results"], 1000),
+			}
+			if caps := stringSlice(args["capabilities"]); len(caps) > 0 {
+				for _, c := range caps {
+					switch c {
+					case "filesystem", "network", "subprocess", "env", "database":
+					default:
+						return mcputil.ValidationError("unknown capability %q (want filesystem, network, subprocess, env, or database)", c)
+					}
+				}
+				pyArgs["capabilities"] = caps
+			}
+			if files := stringSlice(args["files"]); len(files) > 0 {
+				pyArgs["files"] = files
+			}
+
+			result, err := runCached(ctx, bridge, "effects_analysis", "effects_analysis", project, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+)
+
+func errorFlow(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("error_flow",
+			mcp.WithDescription("Map where errors and exceptions are created, wrapped, swallowed, or turned into panics in each function, and rank swallowed-error sites by whether the call graph reaches them from an entry point, with the shortest path."),
+			mcp.WithString("project",
+				mcp.Description("Project root path"),
+				mcp.Required(),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (defaults to the detected project language)"),
+			),
+			mcp.WithArray("kinds",
+				mcp.Description("Only these site kinds: created, wrapped, swallowed, panic (default all)"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithArray("files",
+				mcp.Description("Only report sites in these project-relative files"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithArray("entry_points",
+				mcp.Description("Entry function names or Scope.names (default: main functions and functions not called outside tests)"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithNumber("max_results",
+				mcp.Description("Maximum sites, and swallowed sites, to return (default 500)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project, _ := args["project"].(string)
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+
+			pyArgs := map[string]any{
+				"language":    languageOr(args["language"], project),
+				"max_results": intOr(args["max_results"], 500),
+			}
+			if kinds := stringSlice(args["kinds"]); len(kinds) > 0 {
+				for _, k := range kinds {
+					switch k {
+					case "created", "wrapped", "swallowed", "panic":
+					default:
+						return mcputil.ValidationError("unknown kind %q (want created, wrapped, swallowed, or panic)", k)
+					}
+				}
+				pyArgs["kinds"] = kinds
+			}
+			if files := stringSlice(args["files"]); len(files) > 0 {
+				pyArgs["files"] = files
+			}
+			if entries := stringSlice(args["entry_points"]); len(entries) > 0 {
+				pyArgs["entry_points"] = entries
+			}
+
+			result, err := runCached(ctx, bridge, "error_flow", "error_flow", project, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
@@ -0,0 +1,265 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/client"
+	"github.com/mistakeknot/intermap/internal/coordination"
+	"github.com/mistakeknot/intermap/internal/export"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// ExportOptions controls what BuildWorkspaceMap includes.
+type ExportOptions struct {
+	Root           string
+	IncludeSymbols bool
+	Top            int // key symbols per project when IncludeSymbols is set
+	MaxFiles       int
+}
+
+// crossProjectData is the decoded cross_project_deps result.
+type crossProjectData struct {
+	Projects []struct {
+		Project   string `json:"project"`
+		DependsOn []struct {
+			Project string `json:"project"`
+			Type    string `json:"type"`
+			Via     string `json:"via"`
+		} `json:"depends_on"`
+	} `json:"projects"`
+}
+
+func exportMap(bridge analysis.Backend, c coordination.Provider) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("export_map",
+			mcp.WithDescription("Export the workspace map (projects, cross-project dependencies, key symbols, agent overlay) as a property graph in JSON Graph Format or GraphML for Neo4j, Gephi, or dashboards."),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
+			),
+			mcp.WithString("format",
+				mcp.Description("Output format: json (JGF, default) or graphml"),
+			),
+			mcp.WithBoolean("include_symbols",
+				mcp.Description("Include the top PageRank symbols of each project (slower)"),
+			),
+			mcp.WithNumber("top",
+				mcp.Description("Key symbols per project when include_symbols is set (default 10)"),
+			),
+			mcp.WithString("output",
+				mcp.Description("Write the export to this file instead of returning it; needs INTERMAP_ALLOW_WRITES=1 on the server"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root := stringOr(args["root"], "")
+			format := stringOr(args["format"], "json")
+			output := stringOr(args["output"], "")
+			if output != "" && os.Getenv(allowWritesEnv) != "1" {
+				return mcputil.ValidationError("writes are disabled; set %s=1 on the server to write output, or omit it", allowWritesEnv)
+			}
+
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+
+			g, err := BuildWorkspaceMap(ctx, bridge, c, ExportOptions{
+				Root:           root,
+				IncludeSymbols: boolOr(args["include_symbols"], false),
+				Top:            intOr(args["top"], 10),
+				MaxFiles:       500,
+			})
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			data, err := g.Encode(format)
+			if err != nil {
+				return mcputil.ValidationError("%v", err)
+			}
+
+			if output != "" {
+				if err := os.WriteFile(output, data, 0o644); err != nil {
+					return mcputil.WrapError(fmt.Errorf("write export: %w", err))
+				}
+				return jsonResult(map[string]any{
+					"output": output,
+					"format": format,
+					"nodes":  len(g.Nodes),
+					"edges":  len(g.Edges),
+				})
+			}
+			return mcp.NewToolResultText(string(data)), nil
+		},
+	}
+}
+
+// BuildWorkspaceMap assembles the workspace property graph: project nodes,
+// depends_on edges between projects, optional key symbol nodes with defines
+// edges, and agent nodes with works_on edges. Symbol and agent data are
+// best-effort: projects that fail to rank and an unreachable intermute are
+// skipped rather than aborting the export.
+func BuildWorkspaceMap(ctx context.Context, bridge analysis.Backend, c coordination.Provider, opts ExportOptions) (*export.Graph, error) {
+	projects, err := registry.Scan(opts.Root)
+	if err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	g := &export.Graph{Label: opts.Root}
+	projectByName := make(map[string]registry.Project, len(projects))
+	for _, p := range projects {
+		projectByName[p.Name] = p
+		g.Nodes = append(g.Nodes, export.Node{
+			ID:    projectNodeID(p.Name),
+			Label: p.Name,
+			Type:  export.NodeProject,
+			Metadata: map[string]any{
+				"path":     p.Path,
+				"language": p.Language,
+				"group":    p.Group,
+				"branch":   p.GitBranch,
+			},
+		})
+	}
+
+	if bridge != nil {
+		deps, err := fetchCrossProjectDeps(ctx, bridge, opts.Root)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range deps.Projects {
+			if _, ok := projectByName[p.Project]; !ok {
+				continue
+			}
+			for _, d := range p.DependsOn {
+				if _, ok := projectByName[d.Project]; !ok {
+					continue
+				}
+				g.Edges = append(g.Edges, export.Edge{
+					Source:   projectNodeID(p.Project),
+					Target:   projectNodeID(d.Project),
+					Relation: export.RelDependsOn,
+					Metadata: map[string]any{"type": d.Type, "via": d.Via},
+				})
+			}
+		}
+	}
+
+	if bridge != nil && opts.IncludeSymbols {
+		for _, p := range projects {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if p.Language == "unknown" {
+				continue
+			}
+			res, err := rankProject(ctx, bridge, p.Path, p.Language, opts.Top, opts.MaxFiles)
+			if err != nil {
+				continue
+			}
+			for _, s := range res.Symbols {
+				id := "symbol:" + p.Name + ":" + s.ID
+				g.Nodes = append(g.Nodes, export.Node{
+					ID:    id,
+					Label: s.Symbol,
+					Type:  export.NodeSymbol,
+					Metadata: map[string]any{
+						"symbol_id": s.ID,
+						"file":      s.File,
+						"kind":      s.Kind,
+						"score":     s.Score,
+					},
+				})
+				g.Edges = append(g.Edges, export.Edge{
+					Source:   projectNodeID(p.Name),
+					Target:   id,
+					Relation: export.RelDefines,
+				})
+			}
+		}
+	}
+
+	if c != nil && c.Available() {
+		agents, err := c.ListAgents(ctx)
+		if err == nil {
+			reservations, _ := c.ListReservations(ctx, "")
+			reservationsByAgent := make(map[string][]client.Reservation)
+			for _, r := range reservations {
+				if r.IsActive {
+					reservationsByAgent[r.AgentID] = append(reservationsByAgent[r.AgentID], r)
+				}
+			}
+			for _, a := range agents {
+				id := "agent:" + a.AgentID
+				held := reservationsByAgent[a.AgentID]
+				patterns := make([]string, 0, len(held))
+				for _, r := range held {
+					patterns = append(patterns, r.Pattern)
+				}
+				match := matchAgentProject(a, held, projects)
+				g.Nodes = append(g.Nodes, export.Node{
+					ID:    id,
+					Label: a.Name,
+					Type:  export.NodeAgent,
+					Metadata: map[string]any{
+						"status":       a.Status,
+						"last_seen":    a.LastSeen,
+						"reservations": patterns,
+						"confidence":   match.Confidence,
+					},
+				})
+				if match.Project == nil {
+					continue
+				}
+				if _, ok := projectByName[match.Project.Name]; ok {
+					g.Edges = append(g.Edges, export.Edge{
+						Source:   id,
+						Target:   projectNodeID(match.Project.Name),
+						Relation: export.RelWorksOn,
+					})
+				}
+			}
+		}
+	}
+	return g, nil
+}
+
+func projectNodeID(name string) string {
+	return "project:" + name
+}
+
+// fetchCrossProjectDeps runs cross_project_deps for root and decodes the result.
+func fetchCrossProjectDeps(ctx context.Context, bridge analysis.Backend, root string) (*crossProjectData, error) {
+	result, err := bridge.Run(ctx, "cross_project_deps", root, map[string]any{"max_depth": registry.MaxDepth()})
+	if err != nil {
+		return nil, err
+	}
+	cd, err := decodeCrossProjectDeps(result)
+	if err != nil {
+		return nil, err
+	}
+	pushCrossProjectDeps(cd)
+	return cd, nil
+}
+
+// decodeCrossProjectDeps converts a raw cross_project_deps result.
+func decodeCrossProjectDeps(result any) (*crossProjectData, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("marshal cross_project_deps: %w", err)
+	}
+	var cd crossProjectData
+	if err := json.Unmarshal(data, &cd); err != nil {
+		return nil, fmt.Errorf("decode cross_project_deps: %w", err)
+	}
+	return &cd, nil
+}
@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+
+	"github.com/mistakeknot/intermap/internal/gocalls"
+)
+
+// Descriptions of the goos, goarch, and build_tags parameters shared by the
+// tools that analyze Go call graphs.
+const (
+	goosDescription      = "Go target OS for build constraints and _GOOS file suffixes (go only; default the server's GOOS)"
+	goarchDescription    = "Go target architecture for build constraints and _GOARCH file suffixes (go only; default the server's GOARCH)"
+	buildTagsDescription = "Extra Go build tags to satisfy, e.g. integration (go only). The result's build_context lists the files excluded"
+)
+
+var buildTagPattern = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+
+// goBuildContext reads the goos, goarch, and build_tags arguments,
+// defaulting to the platform the server runs on.
+func goBuildContext(args map[string]any) (gocalls.BuildContext, error) {
+	bc := gocalls.BuildContext{
+		GOOS:   stringOr(args["goos"], runtime.GOOS),
+		GOARCH: stringOr(args["goarch"], runtime.GOARCH),
+		Tags:   stringSlice(args["build_tags"]),
+	}
+	for _, v := range append([]string{bc.GOOS, bc.GOARCH}, bc.Tags...) {
+		if !buildTagPattern.MatchString(v) {
+			return bc, fmt.Errorf("invalid build tag %q", v)
+		}
+	}
+	return bc, nil
+}
+
+// addGoBuild passes bc to the sidecar as go_build, which restricts Go files
+// to those built under it and reports the rest.
+func addGoBuild(pyArgs map[string]any, bc gocalls.BuildContext) {
+	tags := bc.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	pyArgs["go_build"] = map[string]any{"goos": bc.GOOS, "goarch": bc.GOARCH, "tags": tags}
+}
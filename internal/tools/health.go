@@ -0,0 +1,228 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/client"
+	"github.com/mistakeknot/intermap/internal/coordination"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// Coordination issue kinds.
+const (
+	IssueStaleAgent     = "stale_agent"     // agent's last_seen is older than stale_after
+	IssueInactiveHolder = "inactive_holder" // reservation held by a stale, inactive, or unknown agent
+	IssueMissingPath    = "missing_path"    // reservation pattern matches no path in the registry
+)
+
+// inactiveAgentStatuses are agent states that should not hold reservations.
+var inactiveAgentStatuses = map[string]bool{
+	"offline": true, "inactive": true, "stopped": true, "dead": true, "exited": true,
+}
+
+// HealthIssue is one coordination problem with a suggested fix.
+type HealthIssue struct {
+	Kind          string `json:"kind"`
+	AgentID       string `json:"agent_id"`
+	AgentName     string `json:"agent_name,omitempty"`
+	ReservationID string `json:"reservation_id,omitempty"`
+	Pattern       string `json:"pattern,omitempty"`
+	Detail        string `json:"detail"`
+	Action        string `json:"action"`
+	Released      bool   `json:"released,omitempty"`
+	ReleaseError  string `json:"release_error,omitempty"`
+}
+
+// CoordinationHealthResult is the response for the coordination_health tool.
+type CoordinationHealthResult struct {
+	AgentsAvailable bool           `json:"agents_available"`
+	AgentsError     string         `json:"agents_error,omitempty"`
+	Agents          int            `json:"agents"`
+	Reservations    int            `json:"reservations"`
+	StaleAfter      string         `json:"stale_after"`
+	Issues          []HealthIssue  `json:"issues"`
+	Counts          map[string]int `json:"counts"`
+	Released        int            `json:"released"`
+}
+
+func coordinationHealth(c coordination.Provider) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("coordination_health",
+			mcp.WithDescription("Find coordination debris in the coordination backend (intermute or files): agents not seen recently, reservations held by stale or unknown agents, and reservations on paths that no longer exist in the workspace. Suggests cleanup and can release the orphaned reservations."),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
+			),
+			mcp.WithString("stale_after",
+				mcp.Description("Age of last_seen after which an agent is stale, as a Go duration (default \"30m\")"),
+			),
+			mcp.WithBoolean("release",
+				mcp.Description("Release reservations flagged inactive_holder or missing_path (default false)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root := stringOr(args["root"], "")
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+			staleAfter, err := time.ParseDuration(stringOr(args["stale_after"], "30m"))
+			if err != nil || staleAfter <= 0 {
+				return mcputil.ValidationError("stale_after must be a positive duration such as 30m or 2h")
+			}
+			release := boolOr(args["release"], false)
+
+			result := CoordinationHealthResult{
+				AgentsAvailable: c.Available(),
+				StaleAfter:      staleAfter.String(),
+				Issues:          []HealthIssue{},
+				Counts:          map[string]int{},
+			}
+			if !c.Available() {
+				result.AgentsError = notConfigured(c)
+				return jsonResult(result)
+			}
+			agents, err := c.ListAgents(ctx)
+			if err != nil {
+				result.AgentsError = fmt.Sprintf("%s unreachable: %v", c.Name(), err)
+				return jsonResult(result)
+			}
+			reservations, err := c.ListReservations(ctx, "")
+			if err != nil {
+				result.AgentsError = fmt.Sprintf("reservations unavailable: %v", err)
+			}
+			projects, err := scanProjects(root, false)
+			if err != nil {
+				return mcputil.WrapError(fmt.Errorf("scan: %w", err))
+			}
+
+			result.Agents = len(agents)
+			result.Issues = coordinationIssues(agents, reservations, projects, staleAfter, time.Now())
+			for i := range result.Issues {
+				issue := &result.Issues[i]
+				result.Counts[issue.Kind]++
+				if !release || issue.ReservationID == "" {
+					continue
+				}
+				if err := releaseReservation(ctx, c, issue.ReservationID); err != nil {
+					issue.ReleaseError = err.Error()
+					if errors.Is(err, coordination.ErrNotSupported) {
+						// Every later release would fail the same way.
+						release = false
+					}
+					continue
+				}
+				issue.Released = true
+				result.Released++
+			}
+			for _, r := range reservations {
+				if r.IsActive {
+					result.Reservations++
+				}
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+// coordinationIssues flags stale agents and orphaned reservations. Each
+// reservation is reported at most once, as inactive_holder before
+// missing_path.
+func coordinationIssues(agents []client.Agent, reservations []client.Reservation, projects []registry.Project, staleAfter time.Duration, now time.Time) []HealthIssue {
+	issues := []HealthIssue{}
+	byID := make(map[string]client.Agent, len(agents))
+	inactive := make(map[string]string) // agent ID -> reason
+	for _, a := range agents {
+		byID[a.AgentID] = a
+		if inactiveAgentStatuses[strings.ToLower(a.Status)] {
+			inactive[a.AgentID] = "status " + a.Status
+		}
+		seen, err := time.Parse(time.RFC3339, a.LastSeen)
+		if err != nil {
+			continue
+		}
+		if age := now.Sub(seen); age > staleAfter {
+			age = age.Round(time.Minute)
+			issues = append(issues, HealthIssue{
+				Kind:      IssueStaleAgent,
+				AgentID:   a.AgentID,
+				AgentName: a.Name,
+				Detail:    fmt.Sprintf("last seen %s ago", age),
+				Action:    "confirm the agent is gone, then deregister it and release its reservations",
+			})
+			if _, ok := inactive[a.AgentID]; !ok {
+				inactive[a.AgentID] = fmt.Sprintf("last seen %s ago", age)
+			}
+		}
+	}
+
+	for _, r := range reservations {
+		if !r.IsActive {
+			continue
+		}
+		issue := HealthIssue{
+			AgentID:       r.AgentID,
+			AgentName:     byID[r.AgentID].Name,
+			ReservationID: r.ID,
+			Pattern:       r.Pattern,
+		}
+		if _, known := byID[r.AgentID]; !known {
+			issue.Kind = IssueInactiveHolder
+			issue.Detail = "held by an agent the coordination backend no longer lists"
+		} else if reason, ok := inactive[r.AgentID]; ok {
+			issue.Kind = IssueInactiveHolder
+			issue.Detail = "held by an inactive agent (" + reason + ")"
+		} else if reason := missingReservationPath(r, projects); reason != "" {
+			issue.Kind = IssueMissingPath
+			issue.Detail = reason
+		} else {
+			continue
+		}
+		issue.Action = fmt.Sprintf("release reservation %s", r.ID)
+		issues = append(issues, issue)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Kind != issues[j].Kind {
+			return issues[i].Kind > issues[j].Kind // stale_agent, missing_path, inactive_holder
+		}
+		return issues[i].AgentID < issues[j].AgentID
+	})
+	return issues
+}
+
+// missingReservationPath explains why a reservation's pattern refers to
+// nothing in the workspace, or returns "" if it still refers to something
+// (or cannot be checked).
+func missingReservationPath(r client.Reservation, projects []registry.Project) string {
+	dir := patternDir(r.Pattern)
+	if filepath.IsAbs(dir) {
+		if _, err := os.Stat(dir); err != nil {
+			return fmt.Sprintf("%s does not exist", dir)
+		}
+		return ""
+	}
+	if r.Project != "" && len(exactProjects(r.Project, projects)) == 0 {
+		return fmt.Sprintf("project %q is not in the registry", r.Project)
+	}
+	if dir == "." {
+		return ""
+	}
+	if len(reservationProjects(r, projects)) == 0 {
+		return fmt.Sprintf("no project contains %s/", dir)
+	}
+	return ""
+}
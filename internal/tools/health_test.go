@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mistakeknot/intermap/client"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+func TestCoordinationIssues(t *testing.T) {
+	root := t.TempDir()
+	api := filepath.Join(root, "core", "api")
+	if err := os.MkdirAll(filepath.Join(api, "internal"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	projects := []registry.Project{{Name: "api", Group: "core", Path: api}}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	agents := []client.Agent{
+		{AgentID: "live", LastSeen: "2026-03-01T11:55:00Z", Status: "active"},
+		{AgentID: "stale", LastSeen: "2026-03-01T09:00:00Z", Status: "active"},
+		{AgentID: "off", LastSeen: "2026-03-01T11:59:00Z", Status: "offline"},
+	}
+	reservations := []client.Reservation{
+		{ID: "ok", AgentID: "live", Pattern: "internal/*.go", Project: "api", IsActive: true},
+		{ID: "ok-abs", AgentID: "live", Pattern: filepath.Join(api, "internal", "*.go"), IsActive: true},
+		{ID: "gone-dir", AgentID: "live", Pattern: "removed/**", Project: "api", IsActive: true},
+		{ID: "gone-abs", AgentID: "live", Pattern: filepath.Join(root, "old", "x.go"), IsActive: true},
+		{ID: "gone-project", AgentID: "live", Pattern: "*.go", Project: "retired", IsActive: true},
+		{ID: "stale-held", AgentID: "stale", Pattern: "internal/*.go", IsActive: true},
+		{ID: "off-held", AgentID: "off", Pattern: "internal/*.go", IsActive: true},
+		{ID: "ghost-held", AgentID: "ghost", Pattern: "internal/*.go", IsActive: true},
+		{ID: "released", AgentID: "ghost", Pattern: "internal/*.go", IsActive: false},
+	}
+
+	kinds := map[string]string{}
+	var staleAgents []string
+	for _, issue := range coordinationIssues(agents, reservations, projects, 30*time.Minute, now) {
+		if issue.Kind == IssueStaleAgent {
+			staleAgents = append(staleAgents, issue.AgentID)
+			continue
+		}
+		kinds[issue.ReservationID] = issue.Kind
+	}
+	if len(staleAgents) != 1 || staleAgents[0] != "stale" {
+		t.Errorf("stale agents = %v, want [stale]", staleAgents)
+	}
+	want := map[string]string{
+		"gone-dir":     IssueMissingPath,
+		"gone-abs":     IssueMissingPath,
+		"gone-project": IssueMissingPath,
+		"stale-held":   IssueInactiveHolder,
+		"off-held":     IssueInactiveHolder,
+		"ghost-held":   IssueInactiveHolder,
+	}
+	if len(kinds) != len(want) {
+		t.Errorf("reservation issues = %v, want %v", kinds, want)
+	}
+	for id, kind := range want {
+		if kinds[id] != kind {
+			t.Errorf("%s: kind %q, want %q", id, kinds[id], kind)
+		}
+	}
+}
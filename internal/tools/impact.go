@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/gocalls"
+)
+
+// PreciseImpactResult is the impact_analysis response for precision
+// "precise". Targets has the same shape as the sidecar's result, with call
+// site lines and a dynamic flag on callers.
+type PreciseImpactResult struct {
+	Targets      map[string]*gocalls.Node `json:"targets"`
+	TotalTargets int                      `json:"total_targets"`
+	Precision    string                   `json:"precision"`
+	Algorithm    string                   `json:"algorithm"`
+	EdgeCount    int                      `json:"edge_count"`
+}
+
+// preciseGoImpact answers impact_analysis from an x/tools call graph of the
+// Go packages under project, built under bc.
+func preciseGoImpact(ctx context.Context, project, target string, maxDepth int, algo string, bc gocalls.BuildContext) (*mcp.CallToolResult, error) {
+	if algo != gocalls.CHA && algo != gocalls.RTA {
+		return mcputil.ValidationError("algorithm must be %s or %s, got %q", gocalls.CHA, gocalls.RTA, algo)
+	}
+	edges, err := gocalls.Build(ctx, project, algo, bc)
+	if err != nil {
+		return mcputil.WrapError(fmt.Errorf("precise call graph: %w", err))
+	}
+
+	targets := gocalls.Impact(edges, gocalls.ParseTarget(target), maxDepth)
+	if len(targets) == 0 {
+		return jsonResult(map[string]any{"error": fmt.Sprintf("Function '%s' not found in call graph", target)})
+	}
+	return jsonResult(PreciseImpactResult{
+		Targets:      targets,
+		TotalTargets: len(targets),
+		Precision:    "precise",
+		Algorithm:    algo,
+		EdgeCount:    len(edges),
+	})
+}
+
+// decorateImpact adds caller depths and path counts to res, then applies
+// impact_analysis's presentation options: edges, snippets, and grouping.
+// The result is decoded afresh, so a cached result is never modified.
+func decorateImpact(res *mcp.CallToolResult, err error, project, language string, args map[string]any) (*mcp.CallToolResult, error) {
+	if err != nil || res == nil || res.IsError || len(res.Content) == 0 {
+		return res, err
+	}
+	text, ok := res.Content[0].(mcp.TextContent)
+	if !ok {
+		return res, err
+	}
+	var result map[string]any
+	if json.Unmarshal([]byte(text.Text), &result) != nil {
+		return res, err
+	}
+	if _, ok := result["targets"]; !ok {
+		return res, err
+	}
+	edges := annotateImpact(result)
+	if boolOr(args["include_edges"], false) {
+		result["edges"] = edges
+	}
+	if boolOr(args["include_snippets"], false) {
+		lines := min(max(intOr(args["snippet_lines"], defaultSnippetLines), 0), maxSnippetLines)
+		addSnippets(project, result, lines, intOr(args["max_snippet_bytes"], defaultSnippetBytes))
+	}
+	if groupBy := stringOr(args["group_by"], ""); groupBy != "" {
+		result["group_by"] = groupBy
+		result["groups"] = groupCallers(project, language, result, groupBy, intOr(args["group_limit"], defaultGroupLimit))
+	}
+	return jsonResult(result)
+}
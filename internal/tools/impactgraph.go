@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+// ImpactEdge is a call in an impact_analysis caller graph. Caller and
+// Callee are file:function, the form target accepts.
+type ImpactEdge struct {
+	Caller  string `json:"caller"`
+	Callee  string `json:"callee"`
+	Line    int    `json:"line,omitempty"`
+	Dynamic bool   `json:"dynamic,omitempty"`
+}
+
+// annotateImpact sets depth and paths on every caller in result's trees
+// and returns the calls the trees record, sorted. Trees list a function
+// once and repeat it as a truncated leaf, so depths are recomputed over
+// the recovered graph: depth is the shortest call distance to the target,
+// and paths the number of distinct shortest call paths.
+func annotateImpact(result map[string]any) []ImpactEdge {
+	targets, _ := result["targets"].(map[string]any)
+	seen := map[ImpactEdge]bool{}
+	var all []ImpactEdge
+	for _, name := range slices.Sorted(maps.Keys(targets)) {
+		root, ok := targets[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		// callers maps a function to the functions calling it.
+		callers := map[string][]string{}
+		var nodes []map[string]any
+		var collect func(node map[string]any)
+		collect = func(node map[string]any) {
+			list, _ := node["callers"].([]any)
+			for _, c := range list {
+				caller, ok := c.(map[string]any)
+				if !ok {
+					continue
+				}
+				e := ImpactEdge{Caller: nodeKey(caller), Callee: nodeKey(node), Line: intOr(caller["line"], 0)}
+				e.Dynamic, _ = caller["dynamic"].(bool)
+				if !slices.Contains(callers[e.Callee], e.Caller) {
+					callers[e.Callee] = append(callers[e.Callee], e.Caller)
+				}
+				if !seen[e] {
+					seen[e] = true
+					all = append(all, e)
+				}
+				nodes = append(nodes, caller)
+				collect(caller)
+			}
+		}
+		collect(root)
+
+		target := nodeKey(root)
+		depth := map[string]int{target: 0}
+		paths := map[string]int{target: 1}
+		for frontier := []string{target}; len(frontier) > 0; {
+			var next []string
+			for _, callee := range frontier {
+				for _, caller := range callers[callee] {
+					d, ok := depth[caller]
+					if !ok {
+						d = depth[callee] + 1
+						depth[caller] = d
+						next = append(next, caller)
+					}
+					if d == depth[callee]+1 {
+						paths[caller] += paths[callee]
+					}
+				}
+			}
+			frontier = next
+		}
+		for _, n := range nodes {
+			k := nodeKey(n)
+			n["depth"] = depth[k]
+			n["paths"] = paths[k]
+		}
+	}
+	slices.SortFunc(all, func(a, b ImpactEdge) int {
+		return cmp.Or(cmp.Compare(a.Callee, b.Callee), cmp.Compare(a.Caller, b.Caller), cmp.Compare(a.Line, b.Line))
+	})
+	return all
+}
+
+func nodeKey(node map[string]any) string {
+	fn, _ := node["function"].(string)
+	file, _ := node["file"].(string)
+	return file + ":" + fn
+}
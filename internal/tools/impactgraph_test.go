@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAnnotateImpact(t *testing.T) {
+	// main reaches parse through load and save; run reaches it first via
+	// the depth-first walk but is also a direct caller.
+	var result map[string]any
+	json.Unmarshal([]byte(`{"targets": {"parse": {"function": "parse", "file": "p.go", "callers": [
+		{"function": "load", "file": "io.go", "line": 4, "callers": [
+			{"function": "main", "file": "main.go", "callers": [
+				{"function": "run", "file": "main.go", "callers": []}]}]},
+		{"function": "save", "file": "io.go", "line": 9, "dynamic": true, "callers": [
+			{"function": "main", "file": "main.go", "callers": [], "truncated": true}]},
+		{"function": "run", "file": "main.go", "callers": [], "truncated": true}]}}}`), &result)
+
+	edges := annotateImpact(result)
+	if len(edges) != 6 {
+		t.Fatalf("edges = %+v, want 6", edges)
+	}
+	if e := edges[4]; e != (ImpactEdge{Caller: "io.go:save", Callee: "p.go:parse", Line: 9, Dynamic: true}) {
+		t.Errorf("edges[4] = %+v", e)
+	}
+
+	want := map[string][2]int{"io.go:load": {1, 1}, "io.go:save": {1, 1}, "main.go:main": {2, 2}, "main.go:run": {1, 1}}
+	var walk func(node map[string]any)
+	walk = func(node map[string]any) {
+		for _, c := range node["callers"].([]any) {
+			caller := c.(map[string]any)
+			w := want[nodeKey(caller)]
+			if caller["depth"] != w[0] || caller["paths"] != w[1] {
+				t.Errorf("%s: depth %v paths %v, want %d and %d", nodeKey(caller), caller["depth"], caller["paths"], w[0], w[1])
+			}
+			walk(caller)
+		}
+	}
+	walk(result["targets"].(map[string]any)["parse"].(map[string]any))
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// defaultGroupLimit is how many callers each impact_analysis group lists
+// before collapsing the rest into a count.
+const defaultGroupLimit = 10
+
+// impactGroupings are the accepted impact_analysis group_by values.
+var impactGroupings = []string{"file", "package", "project"}
+
+// ImpactGroup is the callers of an impact_analysis result that share a
+// file, package, or project.
+type ImpactGroup struct {
+	Key     string          `json:"key"`
+	Count   int             `json:"count"`
+	Callers []GroupedCaller `json:"callers"`
+	// Collapsed counts callers past the group limit, which are omitted.
+	Collapsed int `json:"collapsed,omitempty"`
+}
+
+// GroupedCaller is one distinct caller in an ImpactGroup. Depth is its
+// nearest distance from a target, 1 for direct callers.
+type GroupedCaller struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Depth    int    `json:"depth"`
+}
+
+// groupCallers collects the distinct callers in result's caller trees and
+// groups them by by. Groups are ordered largest first; callers within a
+// group nearest first. limit <= 0 lists every caller.
+func groupCallers(project, language string, result map[string]any, by string, limit int) []ImpactGroup {
+	type key struct{ function, file string }
+	seen := map[key]*GroupedCaller{}
+	var order []key
+	var visit func(node map[string]any, depth int)
+	visit = func(node map[string]any, depth int) {
+		callers, _ := node["callers"].([]any)
+		for _, c := range callers {
+			caller, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			fn, _ := caller["function"].(string)
+			file, _ := caller["file"].(string)
+			k := key{fn, file}
+			depth := intOr(caller["depth"], depth)
+			if gc, ok := seen[k]; ok {
+				gc.Depth = min(gc.Depth, depth)
+			} else {
+				seen[k] = &GroupedCaller{Function: fn, File: file, Line: intOr(caller["line"], 0), Depth: depth}
+				order = append(order, k)
+			}
+			visit(caller, depth+1)
+		}
+	}
+	targets, _ := result["targets"].(map[string]any)
+	for _, name := range slices.Sorted(maps.Keys(targets)) {
+		if node, ok := targets[name].(map[string]any); ok {
+			visit(node, 1)
+		}
+	}
+
+	byKey := map[string]*ImpactGroup{}
+	projects := map[string]string{}
+	for _, k := range order {
+		gc := seen[k]
+		gk := groupKey(project, language, gc.File, by, projects)
+		g, ok := byKey[gk]
+		if !ok {
+			g = &ImpactGroup{Key: gk}
+			byKey[gk] = g
+		}
+		g.Count++
+		g.Callers = append(g.Callers, *gc)
+	}
+
+	groups := make([]ImpactGroup, 0, len(byKey))
+	for _, g := range byKey {
+		slices.SortFunc(g.Callers, func(a, b GroupedCaller) int {
+			if a.Depth != b.Depth {
+				return a.Depth - b.Depth
+			}
+			if c := strings.Compare(a.File, b.File); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Function, b.Function)
+		})
+		if limit > 0 && len(g.Callers) > limit {
+			g.Collapsed = len(g.Callers) - limit
+			g.Callers = g.Callers[:limit]
+		}
+		groups = append(groups, *g)
+	}
+	slices.SortFunc(groups, func(a, b ImpactGroup) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return groups
+}
+
+// groupKey names the group file belongs to. Packages follow symbol IDs:
+// the directory for Go, the dotted module for Python, and the file
+// without its extension otherwise. Projects are resolved once per
+// directory through cache.
+func groupKey(project, language, file, by string, cache map[string]string) string {
+	switch by {
+	case "file":
+		return file
+	case "package":
+		return packagePath(file, language)
+	}
+	if !filepath.IsAbs(file) {
+		return filepath.Base(project)
+	}
+	dir := filepath.Dir(file)
+	if name, ok := cache[dir]; ok {
+		return name
+	}
+	name := dir
+	if p, err := registry.Resolve(dir); err == nil {
+		name = p.Name
+	}
+	cache[dir] = name
+	return name
+}
+
+// packagePath mirrors the sidecar's symbol_ids.package_path.
+func packagePath(file, language string) string {
+	p := filepath.ToSlash(file)
+	switch language {
+	case "go":
+		if dir := path.Dir(p); dir != "." {
+			return dir
+		}
+		return ""
+	case "python":
+		parts := strings.Split(strings.TrimSuffix(p, path.Ext(p)), "/")
+		if parts[len(parts)-1] == "__init__" {
+			parts = parts[:len(parts)-1]
+		}
+		return strings.Join(parts, ".")
+	}
+	return strings.TrimSuffix(p, path.Ext(p))
+}
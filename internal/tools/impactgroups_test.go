@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGroupCallers(t *testing.T) {
+	var result map[string]any
+	json.Unmarshal([]byte(`{"targets": {"parse": {"function": "parse", "file": "pkg/parse.py", "callers": [
+		{"function": "load", "file": "pkg/io.py", "callers": [
+			{"function": "main", "file": "cli.py", "callers": []},
+			{"function": "parse", "file": "pkg/parse.py", "callers": [], "truncated": true}]},
+		{"function": "save", "file": "pkg/io.py", "callers": [
+			{"function": "load", "file": "pkg/io.py", "callers": [], "truncated": true}]},
+		{"function": "init", "file": "pkg/__init__.py", "callers": []}]}}}`), &result)
+
+	groups := groupCallers("/src/app", "python", result, "package", 0)
+	want := map[string]int{"pkg.io": 2, "pkg.parse": 1, "pkg": 1, "cli": 1}
+	if len(groups) != len(want) {
+		t.Fatalf("groups = %+v", groups)
+	}
+	if groups[0].Key != "pkg.io" || groups[0].Count != 2 {
+		t.Errorf("largest group = %+v, want pkg.io with 2", groups[0])
+	}
+	for _, g := range groups {
+		if want[g.Key] != g.Count {
+			t.Errorf("group %s has %d callers, want %d", g.Key, g.Count, want[g.Key])
+		}
+		for _, c := range g.Callers {
+			if c.Function == "load" && c.Depth != 1 {
+				t.Errorf("load depth = %d, want its nearest, 1", c.Depth)
+			}
+			if c.Function == "main" && c.Depth != 2 {
+				t.Errorf("main depth = %d, want 2", c.Depth)
+			}
+		}
+	}
+
+	groups = groupCallers("/src/app", "python", result, "file", 1)
+	if groups[0].Key != "pkg/io.py" || len(groups[0].Callers) != 1 || groups[0].Collapsed != 1 {
+		t.Errorf("limited file group = %+v, want one caller and one collapsed", groups[0])
+	}
+
+	groups = groupCallers("/src/app", "python", result, "project", 0)
+	if len(groups) != 1 || groups[0].Key != "app" || groups[0].Count != 5 {
+		t.Errorf("project groups = %+v, want app with 5", groups)
+	}
+}
+
+func TestPackagePath(t *testing.T) {
+	for _, tt := range []struct{ file, language, want string }{
+		{"internal/tools/tools.go", "go", "internal/tools"},
+		{"main.go", "go", ""},
+		{"pkg/sub/mod.py", "python", "pkg.sub.mod"},
+		{"pkg/__init__.py", "python", "pkg"},
+		{"src/app.ts", "typescript", "src/app"},
+	} {
+		if got := packagePath(tt.file, tt.language); got != tt.want {
+			t.Errorf("packagePath(%q, %q) = %q, want %q", tt.file, tt.language, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/containers"
+	"github.com/mistakeknot/intermap/internal/infra"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// InfraMapResult is the response for the infra_map tool.
+type InfraMapResult struct {
+	Root    string         `json:"root"`
+	Modules []infra.Module `json:"modules"`
+	Charts  []infra.Chart  `json:"charts"`
+	// DeployedBy maps each deployed project to the module and chart
+	// directories that deploy it.
+	DeployedBy map[string][]string `json:"deployed_by"`
+}
+
+func infraMap() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("infra_map",
+			mcp.WithDescription("Infrastructure-as-code map: parse Terraform modules and Helm charts, reporting providers, module and chart dependencies, and which application projects each deploys (via container images, local paths, and installed charts)."),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root := stringOr(args["root"], "")
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+
+			m, err := infra.Scan(root)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			projects, err := registry.Scan(root)
+			if err != nil {
+				return mcputil.WrapError(fmt.Errorf("scan: %w", err))
+			}
+			// Images built from a project's code (compose build: sections)
+			// link deployments whose image names differ from the project.
+			built := map[string]string{}
+			if c, err := containers.Scan(root); err == nil {
+				c.Link(root, projects)
+				built = c.BuiltImages()
+			}
+			m.Link(root, projects, built)
+
+			result := InfraMapResult{Root: root, Modules: m.Modules, Charts: m.Charts, DeployedBy: map[string][]string{}}
+			for _, mod := range m.Modules {
+				for _, d := range mod.Deploys {
+					result.DeployedBy[d.Project] = append(result.DeployedBy[d.Project], mod.Dir)
+				}
+			}
+			for _, c := range m.Charts {
+				for _, d := range c.Deploys {
+					result.DeployedBy[d.Project] = append(result.DeployedBy[d.Project], c.Dir)
+				}
+			}
+			for _, dirs := range result.DeployedBy {
+				sort.Strings(dirs)
+			}
+			return jsonResult(result)
+		},
+	}
+}
@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/internal/graph"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// KeySymbol is a ranked symbol in the key_symbols result.
+type KeySymbol struct {
+	// ID is the stable symbol ID (package#qualified.name@sighash), or
+	// file:symbol when the definition could not be located.
+	ID      string  `json:"id"`
+	File    string  `json:"file"`
+	Symbol  string  `json:"symbol"`
+	Kind    string  `json:"kind,omitempty"`
+	Line    int     `json:"line,omitempty"`
+	Score   float64 `json:"score"`
+	Callers int     `json:"callers"`
+	Callees int     `json:"callees"`
+}
+
+// KeyFile is a ranked file in the key_symbols result.
+type KeyFile struct {
+	File       string  `json:"file"`
+	Score      float64 `json:"score"`
+	Dependents int     `json:"dependents"`
+}
+
+// KeySymbolsResult is the per-project response for the key_symbols tool.
+type KeySymbolsResult struct {
+	Project   string      `json:"project"`
+	Language  string      `json:"language"`
+	Symbols   []KeySymbol `json:"symbols"`
+	Files     []KeyFile   `json:"files"`
+	NodeCount int         `json:"node_count"`
+	EdgeCount int         `json:"edge_count"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// KeySymbolsWorkspaceResult is the response when key_symbols ranks every
+// project under a workspace root.
+type KeySymbolsWorkspaceResult struct {
+	Root     string             `json:"root"`
+	Projects []KeySymbolsResult `json:"projects"`
+}
+
+func keySymbols(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("key_symbols",
+			mcp.WithDescription("Rank the most architecturally important functions, types, and files by PageRank. Symbols are ranked over the call graph; files over call edges plus import edges, so a file that is imported but never called still ranks. Pass project for one project, or root to rank every project in the workspace."),
+			mcp.WithString("project",
+				mcp.Description("Project path to rank"),
+			),
+			mcp.WithString("root",
+				mcp.Description("Workspace root: rank every project found by project_registry"),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (defaults to the detected project language)"),
+			),
+			mcp.WithNumber("top",
+				mcp.Description("Number of symbols and files to return per project (default 20)"),
+			),
+			mcp.WithNumber("max_files",
+				mcp.Description("Maximum number of files to scan per project (default 500)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project := stringOr(args["project"], "")
+			root := stringOr(args["root"], "")
+			if project == "" && root == "" {
+				return mcputil.ValidationError("project or root is required")
+			}
+			top := intOr(args["top"], 20)
+			maxFiles := intOr(args["max_files"], 500)
+
+			if project != "" {
+				res, err := rankProject(ctx, bridge, project, languageOr(args["language"], project), top, maxFiles)
+				if err != nil {
+					return mcputil.WrapError(err)
+				}
+				return jsonResult(res)
+			}
+
+			projects, err := registry.Scan(root)
+			if err != nil {
+				return mcputil.WrapError(fmt.Errorf("scan: %w", err))
+			}
+			out := KeySymbolsWorkspaceResult{Root: root, Projects: []KeySymbolsResult{}}
+			for _, p := range projects {
+				if ctx.Err() != nil {
+					return mcputil.WrapError(ctx.Err())
+				}
+				lang := stringOr(args["language"], p.Language)
+				if lang == "unknown" {
+					continue
+				}
+				res, err := rankProject(ctx, bridge, p.Path, lang, top, maxFiles)
+				if err != nil {
+					res = &KeySymbolsResult{Project: p.Path, Language: lang, Error: err.Error()}
+				}
+				out.Projects = append(out.Projects, *res)
+			}
+			return jsonResult(out)
+		},
+	}
+}
+
+// rankProject computes symbol and file PageRank for a single project.
+func rankProject(ctx context.Context, bridge analysis.Backend, project, language string, top, maxFiles int) (*KeySymbolsResult, error) {
+	if _, err := os.Stat(project); err != nil {
+		return nil, fmt.Errorf("project: %w", err)
+	}
+	rd, err := fetchRefData(ctx, bridge, project, language, maxFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &KeySymbolsResult{
+		Project:  project,
+		Language: rd.Language,
+		Symbols:  []KeySymbol{},
+		Files:    []KeyFile{},
+	}
+
+	sg := rd.symbolGraph()
+	res.NodeCount = sg.Len()
+	res.EdgeCount = sg.EdgeCount()
+	defs := rd.definitionIndex()
+	stable := rd.stableIDs()
+	for _, r := range graph.Top(sg.PageRank(0.85, 100), top) {
+		file, symbol, _ := strings.Cut(r.ID, ":")
+		ks := KeySymbol{
+			ID:      r.ID,
+			File:    file,
+			Symbol:  symbol,
+			Score:   r.Score,
+			Callers: sg.InDegree(r.ID),
+			Callees: sg.OutDegree(r.ID),
+		}
+		if d, ok := defs[r.ID]; ok {
+			ks.Kind = d.Kind
+			ks.Line = d.Line
+		}
+		if id, ok := stable[r.ID]; ok {
+			ks.ID = id
+		}
+		res.Symbols = append(res.Symbols, ks)
+	}
+
+	fg := rd.fileGraph(true)
+	for _, r := range graph.Top(fg.PageRank(0.85, 100), top) {
+		res.Files = append(res.Files, KeyFile{
+			File:       r.ID,
+			Score:      r.Score,
+			Dependents: fg.InDegree(r.ID),
+		})
+	}
+	return res, nil
+}
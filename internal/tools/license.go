@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/deps"
+	"github.com/mistakeknot/intermap/internal/license"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+var licensePolicy license.Policy
+
+// SetLicensePolicy sets the policy license_check applies by default.
+func SetLicensePolicy(p license.Policy) {
+	licensePolicy = p
+}
+
+// LicenseFinding is a dependency that fails the license policy, or whose
+// license is unknown.
+type LicenseFinding struct {
+	Project   string `json:"project"`
+	Package   string `json:"package"`
+	Version   string `json:"version,omitempty"`
+	Ecosystem string `json:"ecosystem"`
+	License   string `json:"license"`
+	// Reason is "denied", "not_allowed", or "unknown".
+	Reason string `json:"reason"`
+	Dev    bool   `json:"dev,omitempty"`
+	// Chain is the path of packages (name@version) from the project's
+	// direct dependency to this one.
+	Chain []string `json:"chain"`
+}
+
+// LicenseCheckResult is the response for the license_check tool.
+type LicenseCheckResult struct {
+	Root       string           `json:"root"`
+	Policy     license.Policy   `json:"policy"`
+	Checked    int              `json:"checked"`
+	Violations []LicenseFinding `json:"violations"`
+	// Unknown lists packages with no detectable license when the policy
+	// only warns about them.
+	Unknown []LicenseFinding `json:"unknown"`
+	// Licenses counts checked packages by license expression.
+	Licenses map[string]int `json:"licenses"`
+}
+
+func licenseCheck() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("license_check",
+			mcp.WithDescription("Check dependency licenses against the allow/deny policy from config (licenses section) and report violations with the dependency chain that introduces each. Licenses come from lockfiles and installed packages (module cache, node_modules, virtualenv, Cargo registry)."),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
+			),
+			mcp.WithString("project",
+				mcp.Description("Project name or path to check (default: every project in the workspace)"),
+			),
+			mcp.WithString("allow",
+				mcp.Description("Comma-separated SPDX identifiers to allow, replacing the configured allow list"),
+			),
+			mcp.WithString("deny",
+				mcp.Description("Comma-separated SPDX identifiers to deny, replacing the configured deny list"),
+			),
+			mcp.WithBoolean("include_dev",
+				mcp.Description("Also check development-only dependencies (default false)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root := stringOr(args["root"], "")
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+			policy := licensePolicy
+			if s := stringOr(args["allow"], ""); s != "" {
+				policy.Allow = splitList(s)
+			}
+			if s := stringOr(args["deny"], ""); s != "" {
+				policy.Deny = splitList(s)
+			}
+			switch policy.Unknown {
+			case "":
+				policy.Unknown = "warn"
+			case "warn", "deny", "allow":
+			default:
+				return mcputil.ValidationError("licenses.unknown must be warn, deny, or allow")
+			}
+
+			result, err := CheckLicenses(root, stringOr(args["project"], ""), policy, boolOr(args["include_dev"], false))
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+// CheckLicenses inventories the dependencies of the projects under root
+// (or the one named) and applies policy to each package's license.
+func CheckLicenses(root, project string, policy license.Policy, includeDev bool) (*LicenseCheckResult, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("abs root: %w", err)
+	}
+	projects, err := registry.Scan(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+	result := &LicenseCheckResult{Root: absRoot, Policy: policy, Violations: []LicenseFinding{}, Unknown: []LicenseFinding{}, Licenses: map[string]int{}}
+	found := false
+	for _, p := range projects {
+		if project != "" && p.Name != project && p.Path != project {
+			continue
+		}
+		found = true
+		components := deps.Scan(p.Path)
+		deps.ResolveLicenses(p.Path, components)
+		chains := deps.Chains(components)
+		label := make(map[string]string, len(components))
+		for _, c := range components {
+			label[c.PURL()] = c.Name + "@" + c.Version
+		}
+
+		for _, c := range components {
+			if (c.Dev && !includeDev) || policy.Exempt(c.Name) {
+				continue
+			}
+			result.Checked++
+			finding := LicenseFinding{Project: p.Name, Package: c.Name, Version: c.Version, Ecosystem: c.Ecosystem, License: c.License, Dev: c.Dev}
+			for _, purl := range chains[c.PURL()] {
+				finding.Chain = append(finding.Chain, strings.TrimSuffix(label[purl], "@"))
+			}
+
+			if c.License == "" {
+				result.Licenses["unknown"]++
+				finding.Reason = "unknown"
+				switch policy.Unknown {
+				case "deny":
+					result.Violations = append(result.Violations, finding)
+				case "warn":
+					result.Unknown = append(result.Unknown, finding)
+				}
+				continue
+			}
+			result.Licenses[c.License]++
+			if v := policy.Evaluate(c.License); v != license.Allowed {
+				finding.Reason = v.String()
+				result.Violations = append(result.Violations, finding)
+			}
+		}
+	}
+	if project != "" && !found {
+		return nil, fmt.Errorf("project %q not found under %s", project, absRoot)
+	}
+	return result, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
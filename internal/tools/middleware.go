@@ -0,0 +1,417 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+)
+
+// Middleware wraps a tool, usually by replacing its handler with one that
+// calls the original. It sees the tool as wrapped by the stages inside it.
+type Middleware func(server.ServerTool) server.ServerTool
+
+// stage is a named step of the handler pipeline.
+type stage struct {
+	name string
+	mw   Middleware
+	// on is whether the stage runs by default.
+	on bool
+	// off, if set, is applied in the stage's place when it is disabled.
+	off Middleware
+}
+
+// stages are the pipeline every tool handler runs through, outermost
+// first. Spill sits outside redaction so spilled results are stored
+// redacted, and structured outside both so structured content matches the
+// text returned.
+var stages = []stage{
+	{name: "audit", mw: withAudit},
+	{name: "rate_limit", mw: withRateLimit, on: true},
+	{name: "usage", mw: withUsage, on: true},
+	{name: "validation", mw: withValidation, on: true},
+	{name: "project_resolution", mw: withProjectResolution, on: true},
+	{name: "scope", mw: withScope, on: true},
+	{name: "provenance", mw: withProvenance},
+	{name: "structured", mw: withStructured, on: true},
+	{name: "spill", mw: withSpill, on: true},
+	{name: "redaction", mw: withRedaction, on: true},
+	{name: "priority", mw: withPriority, on: true},
+	// runCached reuses results unless the cache stage is disabled.
+	{name: "cache", mw: func(t server.ServerTool) server.ServerTool { return t }, on: true, off: withoutResultCache},
+}
+
+// Pipeline configures the handler middleware.
+type Pipeline struct {
+	// Enable turns stages on or off by name, overriding their defaults:
+	// audit and provenance are off, the rest on.
+	Enable map[string]bool
+	// RateLimit caps each session's tool calls per minute; 0 is unlimited.
+	RateLimit int
+}
+
+var pipeline Pipeline
+
+// SetPipeline replaces the middleware configuration. It must be called
+// before RegisterAll. Unknown stage names are an error and are ignored.
+func SetPipeline(p Pipeline) error {
+	var unknown []string
+	for name := range p.Enable {
+		if !knownStage(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	pipeline = p
+	limiter.setLimit(p.RateLimit)
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown middleware %s (want one of %s)", strings.Join(unknown, ", "), strings.Join(StageNames(), ", "))
+	}
+	return nil
+}
+
+// StageNames lists the pipeline stages, outermost first.
+func StageNames() []string {
+	names := make([]string, len(stages))
+	for i, s := range stages {
+		names[i] = s.name
+	}
+	return names
+}
+
+func knownStage(name string) bool {
+	for _, s := range stages {
+		if s.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPipeline wraps t in the enabled stages.
+func applyPipeline(t server.ServerTool) server.ServerTool {
+	for i := len(stages) - 1; i >= 0; i-- {
+		s := stages[i]
+		on, set := pipeline.Enable[s.name]
+		if !set {
+			on = s.on
+		}
+		switch {
+		case on:
+			t = s.mw(t)
+		case s.off != nil:
+			t = s.off(t)
+		}
+	}
+	return t
+}
+
+// toolDefaults are per-tool argument presets, keyed by tool then argument.
+var toolDefaults map[string]map[string]any
+
+// SetToolDefaults installs per-tool argument presets, which the validation
+// stage merges under explicit arguments. Presets for unknown tools or
+// arguments, or of the wrong type, are dropped and reported in the error.
+// Call before RegisterAll.
+func SetToolDefaults(defaults map[string]map[string]any) error {
+	schemas := make(map[string]mcp.ToolInputSchema, len(Specs))
+	for _, s := range Specs {
+		schemas[s.Name] = s.New(Deps{}).Tool.InputSchema
+	}
+	var problems []string
+	valid := make(map[string]map[string]any, len(defaults))
+	for tool, args := range defaults {
+		schema, ok := schemas[tool]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("tool_defaults for unknown tool %q", tool))
+			continue
+		}
+		for name, v := range args {
+			if msg := checkArg(schema, name, v); msg != "" {
+				problems = append(problems, fmt.Sprintf("tool_defaults for %s: %s", tool, msg))
+				continue
+			}
+			if valid[tool] == nil {
+				valid[tool] = map[string]any{}
+			}
+			valid[tool][name] = v
+		}
+	}
+	toolDefaults = valid
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkArg describes what is wrong with passing v as argument name, or
+// returns "".
+func checkArg(schema mcp.ToolInputSchema, name string, v any) string {
+	prop, ok := schema.Properties[name].(map[string]any)
+	if !ok {
+		return fmt.Sprintf("unknown argument %s", name)
+	}
+	want, _ := prop["type"].(string)
+	if got := jsonType(v); want != "" && got != want && !(want == "number" && got == "integer") {
+		return fmt.Sprintf("%s must be a %s, not a %s", name, want, got)
+	}
+	return ""
+}
+
+// withValidation merges the tool's preset arguments under the explicit
+// ones, then rejects calls that omit a required argument or pass one of
+// the wrong JSON type, before the handler runs. A null counts as omitted.
+func withValidation(t server.ServerTool) server.ServerTool {
+	schema := t.Tool.InputSchema
+	presets := toolDefaults[t.Tool.Name]
+	next := t.Handler
+	t.Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+		if len(presets) > 0 {
+			args = maps.Clone(args)
+			if args == nil {
+				args = map[string]any{}
+			}
+			for name, v := range presets {
+				if args[name] == nil {
+					args[name] = v
+				}
+			}
+			req.Params.Arguments = args
+		}
+		for _, name := range schema.Required {
+			if v, ok := args[name]; !ok || v == nil || v == "" {
+				return mcputil.ValidationError("%s is required", name)
+			}
+		}
+		for name, v := range args {
+			if _, ok := schema.Properties[name]; !ok || v == nil {
+				continue
+			}
+			if msg := checkArg(schema, name, v); msg != "" {
+				return mcputil.ValidationError("%s", msg)
+			}
+		}
+		return next(ctx, req)
+	}
+	return t
+}
+
+// jsonType names the JSON type of a decoded argument.
+func jsonType(v any) string {
+	switch n := v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, float32:
+		return "number"
+	case int, int64, int32:
+		return "integer"
+	case json.Number:
+		if _, err := n.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any, []string:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// withAudit logs every call: the tool, session, arguments, outcome, and
+// duration. String arguments are redacted and shortened.
+func withAudit(t server.ServerTool) server.ServerTool {
+	next := t.Handler
+	name := t.Tool.Name
+	t.Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		res, err := next(ctx, req)
+		outcome := "ok"
+		switch {
+		case err != nil:
+			outcome = "error: " + err.Error()
+		case res != nil && res.IsError:
+			outcome = "tool error"
+		}
+		slog.InfoContext(ctx, "tool call",
+			"tool", name,
+			"session", sessionID(ctx),
+			"args", auditArgs(req.GetArguments()),
+			"outcome", outcome,
+			"duration", time.Since(start).Round(time.Millisecond))
+		return res, err
+	}
+	return t
+}
+
+func auditArgs(args map[string]any) map[string]any {
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		if s, ok := v.(string); ok {
+			s = redactor.String(s)
+			if len(s) > 200 {
+				s = s[:200] + "..."
+			}
+			v = s
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// rateLimiter allows each session a number of calls per minute, refilled
+// continuously. A bucket that has refilled is the same as a new one, so
+// buckets idle for a minute are dropped rather than kept for every session
+// ever seen.
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	buckets map[string]*bucket
+	swept   time.Time // last sweep for full buckets
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+var limiter = &rateLimiter{buckets: map[string]*bucket{}, now: time.Now}
+
+func (l *rateLimiter) setLimit(perMinute int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = max(perMinute, 0)
+	l.buckets = map[string]*bucket{}
+}
+
+// allow takes a call from session's bucket. When it is empty, allow
+// returns the limit and how long until the next call is allowed.
+func (l *rateLimiter) allow(session string) (limit int, wait time.Duration, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit == 0 {
+		return 0, 0, true
+	}
+	now := l.now()
+	if now.Sub(l.swept) >= time.Minute {
+		l.sweep(now)
+	}
+	b, ok := l.buckets[session]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit), last: now}
+		l.buckets[session] = b
+	}
+	rate := float64(l.limit) / float64(time.Minute)
+	b.tokens = min(float64(l.limit), b.tokens+float64(now.Sub(b.last))*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return l.limit, time.Duration((1 - b.tokens) / rate), false
+	}
+	b.tokens--
+	return l.limit, 0, true
+}
+
+// sweep drops the buckets that have refilled by now. A full refill takes at
+// most a minute, so sweeping once a minute bounds the map by the sessions
+// active in the last two.
+func (l *rateLimiter) sweep(now time.Time) {
+	rate := float64(l.limit) / float64(time.Minute)
+	for session, b := range l.buckets {
+		if b.tokens+float64(now.Sub(b.last))*rate >= float64(l.limit) {
+			delete(l.buckets, session)
+		}
+	}
+	l.swept = now
+}
+
+// withRateLimit refuses calls from a session over Pipeline.RateLimit.
+func withRateLimit(t server.ServerTool) server.ServerTool {
+	next := t.Handler
+	t.Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		session := sessionID(ctx)
+		if limit, wait, ok := limiter.allow(session); !ok {
+			return mcputil.TransientError("session %s is over the limit of %d tool calls per minute; retry in %s",
+				session, limit, wait.Truncate(time.Second)+time.Second)
+		}
+		return next(ctx, req)
+	}
+	return t
+}
+
+// Provenance is attached to results as _meta.intermap when the provenance
+// stage is on.
+type Provenance struct {
+	Tool        string    `json:"tool"`
+	Project     string    `json:"project,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+	DurationMS  int64     `json:"duration_ms"`
+}
+
+// withProvenance records which tool produced a result, for which project,
+// when, and how long it took, in the result's _meta.
+func withProvenance(t server.ServerTool) server.ServerTool {
+	next := t.Handler
+	name := t.Tool.Name
+	t.Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		res, err := next(ctx, req)
+		if err != nil || res == nil {
+			return res, err
+		}
+		p := Provenance{
+			Tool:        name,
+			Project:     redactor.String(stringOr(req.GetArguments()["project"], "")),
+			GeneratedAt: start.UTC(),
+			DurationMS:  time.Since(start).Milliseconds(),
+		}
+		if res.Meta == nil {
+			res.Meta = &mcp.Meta{}
+		}
+		if res.Meta.AdditionalFields == nil {
+			res.Meta.AdditionalFields = map[string]any{}
+		}
+		res.Meta.AdditionalFields["intermap"] = p
+		return res, nil
+	}
+	return t
+}
+
+// withStructured returns the JSON text of a tool's successful results as
+// structured content too, for tools that declare an output schema.
+func withStructured(t server.ServerTool) server.ServerTool {
+	if t.Tool.RawOutputSchema == nil {
+		return t
+	}
+	next := t.Handler
+	t.Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		res, err := next(ctx, req)
+		if err != nil || res == nil || res.IsError || len(res.Content) == 0 {
+			return res, err
+		}
+		text, ok := res.Content[0].(mcp.TextContent)
+		if !ok {
+			return res, nil
+		}
+		var structured map[string]any
+		if json.Unmarshal([]byte(text.Text), &structured) == nil {
+			res.StructuredContent = structured
+		}
+		return res, nil
+	}
+	return t
+}
@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// echoTool returns a tool with a required string "project" and a number
+// "limit" whose handler reports whether it ran.
+func echoTool(ran *bool) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("echo",
+			mcp.WithString("project", mcp.Required()),
+			mcp.WithNumber("limit"),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			*ran = true
+			_, bypass := ctx.Value(noResultCacheKey{}).(bool)
+			return jsonResult(map[string]any{"cache_bypassed": bypass})
+		},
+	}
+}
+
+func call(t *testing.T, tool server.ServerTool, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	var req mcp.CallToolRequest
+	req.Params.Arguments = args
+	res, err := tool.Handler(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func resultText(res *mcp.CallToolResult) string {
+	return res.Content[0].(mcp.TextContent).Text
+}
+
+func TestWithValidation(t *testing.T) {
+	var ran bool
+	tool := withValidation(echoTool(&ran))
+	for _, tc := range []struct {
+		args map[string]any
+		err  string
+	}{
+		{map[string]any{}, "project is required"},
+		{map[string]any{"project": nil}, "project is required"},
+		{map[string]any{"project": 3.0}, "project must be a string, not a number"},
+		{map[string]any{"project": "p", "limit": "ten"}, "limit must be a number, not a string"},
+		{map[string]any{"project": "p", "limit": 10.0, "extra": true}, ""},
+	} {
+		ran = false
+		res := call(t, tool, tc.args)
+		if tc.err == "" {
+			if res.IsError || !ran {
+				t.Errorf("%v: rejected: %s", tc.args, resultText(res))
+			}
+			continue
+		}
+		if !res.IsError || ran || !strings.Contains(resultText(res), tc.err) {
+			t.Errorf("%v: got %q (ran %v), want error %q", tc.args, resultText(res), ran, tc.err)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := &rateLimiter{now: func() time.Time { return now }}
+	l.setLimit(2)
+	for i := range 2 {
+		if _, _, ok := l.allow("a"); !ok {
+			t.Fatalf("call %d refused", i)
+		}
+	}
+	if _, wait, ok := l.allow("a"); ok || wait != 30*time.Second {
+		t.Errorf("third call: ok %v, wait %s; want refused for 30s", ok, wait)
+	}
+	if _, _, ok := l.allow("b"); !ok {
+		t.Error("other session refused")
+	}
+	now = now.Add(30 * time.Second)
+	if _, _, ok := l.allow("a"); !ok {
+		t.Error("refused after refill")
+	}
+
+	// Idle sessions' buckets refill and are dropped by the next sweep;
+	// a session still short of tokens keeps its bucket.
+	for i := range 100 {
+		l.allow(fmt.Sprintf("s%d", i))
+	}
+	now = now.Add(45 * time.Second)
+	l.allow("c")
+	if _, ok := l.buckets["a"]; !ok || len(l.buckets) != 2 {
+		t.Errorf("%d buckets after sweep, want a and c", len(l.buckets))
+	}
+}
+
+func TestApplyPipeline(t *testing.T) {
+	t.Cleanup(func() { SetPipeline(Pipeline{}) })
+
+	var ran bool
+	res := call(t, applyPipeline(echoTool(&ran)), map[string]any{"project": t.TempDir()})
+	if resultText(res) != `{"cache_bypassed":false}` || res.Meta != nil {
+		t.Errorf("default pipeline: %s, meta %v", resultText(res), res.Meta)
+	}
+
+	if err := SetPipeline(Pipeline{Enable: map[string]bool{"cache": false, "provenance": true, "validation": false}}); err != nil {
+		t.Fatal(err)
+	}
+	res = call(t, applyPipeline(echoTool(&ran)), map[string]any{"limit": "ten"})
+	if resultText(res) != `{"cache_bypassed":true}` {
+		t.Errorf("cache disabled: %s", resultText(res))
+	}
+	if p, ok := res.Meta.AdditionalFields["intermap"].(Provenance); !ok || p.Tool != "echo" || p.GeneratedAt.IsZero() {
+		t.Errorf("provenance = %#v", res.Meta.AdditionalFields["intermap"])
+	}
+
+	SetPipeline(Pipeline{RateLimit: 1})
+	tool := applyPipeline(echoTool(&ran))
+	call(t, tool, map[string]any{"project": "p"})
+	if res := call(t, tool, map[string]any{"project": "p"}); !res.IsError || !strings.Contains(resultText(res), "1 tool calls per minute") {
+		t.Errorf("second call over the rate limit: %s", resultText(res))
+	}
+
+	if err := SetPipeline(Pipeline{Enable: map[string]bool{"caching": true}}); err == nil || !strings.Contains(err.Error(), "caching") {
+		t.Errorf("unknown stage: err = %v", err)
+	}
+}
+
+func TestWithStructured(t *testing.T) {
+	var ran bool
+	if tool := withStructured(echoTool(&ran)); call(t, tool, nil).StructuredContent != nil {
+		t.Error("structured content for a tool without an output schema")
+	}
+
+	tool := echoTool(&ran)
+	tool.Tool.RawOutputSchema = json.RawMessage(`{"type": "object"}`)
+	res := call(t, withStructured(tool), nil)
+	if got, _ := res.StructuredContent.(map[string]any); got["cache_bypassed"] != false {
+		t.Errorf("structured content = %#v", res.StructuredContent)
+	}
+	if resultText(res) != `{"cache_bypassed":false}` {
+		t.Errorf("text = %s", resultText(res))
+	}
+}
+
+func TestToolDefaults(t *testing.T) {
+	t.Cleanup(func() { SetToolDefaults(nil) })
+	err := SetToolDefaults(map[string]map[string]any{
+		"code_structure": {"language": "go", "max_results": 5.0, "bogus": true},
+		"no_such_tool":   {"x": 1.0},
+		"key_symbols":    {"top": "many"},
+	})
+	for _, want := range []string{"unknown tool \"no_such_tool\"", "unknown argument bogus", "top must be a number, not a string"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v does not mention %q", err, want)
+		}
+	}
+	if len(toolDefaults) != 1 || len(toolDefaults["code_structure"]) != 2 {
+		t.Errorf("kept presets = %v", toolDefaults)
+	}
+
+	var got map[string]any
+	tool := withValidation(server.ServerTool{
+		Tool: mcp.NewTool("code_structure", mcp.WithString("language"), mcp.WithNumber("max_results")),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			got = req.GetArguments()
+			return jsonResult(nil)
+		},
+	})
+	call(t, tool, map[string]any{"language": "python"})
+	if got["language"] != "python" || got["max_results"] != 5.0 {
+		t.Errorf("merged arguments = %v", got)
+	}
+}
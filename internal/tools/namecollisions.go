@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// collisionKinds are the kind classes name_collisions compares.
+var collisionKinds = []string{"type", "function", "value"}
+
+func nameCollisions(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("name_collisions",
+			mcp.WithDescription("Find exported symbols with the same name in more than one workspace project — three different Client types, two New functions — ranked by how close the projects are in the import graph: imports (one project imports the other) before shared_importer (a third project imports both). Use before naming a new exported symbol, or to explain confusing shadowing when an identifier resolves to the wrong package."),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
+			),
+			mcp.WithArray("kinds",
+				mcp.Description("Kind classes to compare: type (classes, structs, interfaces, enums, traits), function, value (consts and vars). Default type."),
+				mcp.WithStringItems(),
+			),
+			mcp.WithString("scope",
+				mcp.Description("neighborhood (default) reports collisions between projects linked by imports; all also reports unrelated projects"),
+			),
+			mcp.WithArray("ignore",
+				mcp.Description("Names never reported"),
+				mcp.WithStringItems(),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root := stringOr(args["root"], "")
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+			root, err := filepath.Abs(root)
+			if err != nil {
+				return mcputil.WrapError(fmt.Errorf("abs root: %w", err))
+			}
+			kinds := stringSlice(args["kinds"])
+			for _, k := range kinds {
+				if !slices.Contains(collisionKinds, k) {
+					return mcputil.ValidationError(fmt.Sprintf("unknown kind %q", k))
+				}
+			}
+			scope := stringOr(args["scope"], "neighborhood")
+			if scope != "neighborhood" && scope != "all" {
+				return mcputil.ValidationError(fmt.Sprintf("unknown scope %q", scope))
+			}
+			languages, err := projectLanguages(root)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+
+			pyArgs := map[string]any{
+				"scope":     scope,
+				"languages": languages,
+				"max_depth": registry.MaxDepth(),
+			}
+			if len(kinds) > 0 {
+				pyArgs["kinds"] = kinds
+			}
+			if ignore := stringSlice(args["ignore"]); len(ignore) > 0 {
+				pyArgs["ignore"] = ignore
+			}
+			result, err := bridge.Run(ctx, "name_collisions", root, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+// projectLanguages maps each project path under root to the language the
+// registry detects for it, so the sidecar reads every project's API the
+// same way the rest of the tools see it.
+func projectLanguages(root string) (map[string]string, error) {
+	projects, err := registry.Scan(root)
+	if err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+	out := make(map[string]string, len(projects))
+	for _, p := range projects {
+		if p.Language != "unknown" {
+			out[p.Path] = p.Language
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,33 @@
+package tools
+
+import "github.com/mistakeknot/intermap/internal/webhook"
+
+// webhooks receives analysis-completion events. The zero value is disabled.
+var webhooks *webhook.Notifier
+
+// SetWebhooks installs the notifier for analysis-completion events. Call
+// before RegisterAll.
+func SetWebhooks(n *webhook.Notifier) {
+	webhooks = n
+}
+
+// Summary keys forwarded for each event; full results can be large, so hooks
+// get the headline numbers and lists only.
+var (
+	changeImpactSummaryKeys = []string{"changed_files", "affected_tests", "affected_count", "skipped_count", "total_tests", "test_command"}
+	indexUpdateSummaryKeys  = []string{"mode", "reparsed", "edges_added", "edges_removed", "drift", "files", "edge_count"}
+)
+
+// emitEvent posts a summary of result to subscribed webhooks.
+func emitEvent(event, project string, result map[string]any, keys []string) {
+	if !webhooks.Enabled() {
+		return
+	}
+	summary := make(map[string]any, len(keys))
+	for _, k := range keys {
+		if v, ok := result[k]; ok {
+			summary[k] = v
+		}
+	}
+	webhooks.Emit(event, project, summary)
+}
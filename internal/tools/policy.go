@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/internal/ci"
+	"github.com/mistakeknot/intermap/internal/config"
+	"github.com/mistakeknot/intermap/internal/policy"
+)
+
+// PolicyResult is the response for the evaluate_policy tool.
+type PolicyResult struct {
+	Project       string             `json:"project"`
+	Base          string             `json:"base"`
+	Rules         int                `json:"rules"`
+	ChangedFiles  []string           `json:"changed_files"`
+	AffectedTests []string           `json:"affected_tests"`
+	Violations    []policy.Violation `json:"violations"`
+	Blocked       bool               `json:"blocked"`
+}
+
+// ProjectPolicy parses rules, one per line, or when rules is empty the
+// policy in project's .intermap.yaml.
+func ProjectPolicy(project, rules string) ([]policy.Rule, error) {
+	if rules != "" {
+		return policy.ParseText(rules)
+	}
+	ws, err := config.LoadWorkspace(project)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := policy.Parse(ws.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("%s policy: %w", config.WorkspaceFile, err)
+	}
+	return parsed, nil
+}
+
+func evaluatePolicy(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("evaluate_policy",
+			mcp.WithDescription(`Evaluate change-gating rules against the git diff and its impacted tests, e.g. block "client changes need tests": changed("internal/client/**") and not tests("internal/client/**"). Rules come from the rules argument or the project's .intermap.yaml policy list.`),
+			mcp.WithString("project",
+				mcp.Description("Project path to analyze"),
+				mcp.Required(),
+			),
+			mcp.WithString("git_base",
+				mcp.Description("Git ref to diff against (default HEAD~1)"),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (defaults to the detected project language)"),
+			),
+			mcp.WithString("rules",
+				mcp.Description(`Rules, one per line: block|warn "name": condition. Conditions combine and, or, not, comparisons, and changed(glob...), tests(glob...), affected(glob...), missing(glob...), count(list). Overrides the project's policy`),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project := stringOr(args["project"], "")
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+			rules, err := ProjectPolicy(project, stringOr(args["rules"], ""))
+			if err != nil {
+				return mcputil.ValidationError("%v", err)
+			}
+			if len(rules) == 0 {
+				return mcputil.ValidationError("no policy rules: pass rules or add a policy list to %s", config.WorkspaceFile)
+			}
+
+			base := stringOr(args["git_base"], "HEAD~1")
+			impact, err := ChangeImpact(ctx, bridge, project, stringOr(args["language"], ""), base)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			report, err := ci.EvaluateImpact(project, base, ci.FailOnNone, impact)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			if err := report.ApplyPolicy(rules); err != nil {
+				return mcputil.ValidationError("%v", err)
+			}
+			return jsonResult(PolicyResult{
+				Project:       project,
+				Base:          base,
+				Rules:         len(rules),
+				ChangedFiles:  report.ChangedFiles,
+				AffectedTests: report.AffectedTests,
+				Violations:    report.Policy,
+				Blocked:       policy.Blocked(report.Policy),
+			})
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+)
+
+// DefaultToolPriorities are the sidecar scheduling classes of tools that
+// are not Normal. Lookups an agent is waiting on jump ahead of rebuilds and
+// workspace-wide scans.
+var DefaultToolPriorities = map[string]pybridge.Priority{
+	"describe_symbol":    pybridge.Interactive,
+	"code_structure":     pybridge.Interactive,
+	"reference_edges":    pybridge.Interactive,
+	"key_symbols":        pybridge.Interactive,
+	"index_update":       pybridge.Background,
+	"export_map":         pybridge.Background,
+	"cross_project_deps": pybridge.Background,
+	"boundary_map":       pybridge.Background,
+	"service_graph":      pybridge.Background,
+	"codemod_plan":       pybridge.Background,
+}
+
+// Priorities configures sidecar scheduling.
+type Priorities struct {
+	// Tools overrides DefaultToolPriorities by tool name.
+	Tools map[string]pybridge.Priority
+	// Weights overrides pybridge.DefaultWeights by class.
+	Weights map[pybridge.Priority]int
+}
+
+var priorities = Priorities{Tools: DefaultToolPriorities}
+
+// SetPriorities replaces the sidecar scheduling configuration. It must be
+// called before RegisterAll.
+func SetPriorities(p Priorities) {
+	merged := make(map[string]pybridge.Priority, len(DefaultToolPriorities)+len(p.Tools))
+	for tool, class := range DefaultToolPriorities {
+		merged[tool] = class
+	}
+	for tool, class := range p.Tools {
+		merged[tool] = class
+	}
+	priorities = Priorities{Tools: merged, Weights: p.Weights}
+}
+
+// withPriority wraps a tool so the sidecar commands it runs are scheduled
+// in its class.
+func withPriority(t server.ServerTool) server.ServerTool {
+	class, ok := priorities.Tools[t.Tool.Name]
+	if !ok || class == pybridge.Normal {
+		return t
+	}
+	next := t.Handler
+	t.Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return next(pybridge.WithPriority(ctx, class), req)
+	}
+	return t
+}
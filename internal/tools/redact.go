@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/intermap/internal/redact"
+)
+
+// redactor rewrites absolute paths in tool results. The zero value leaves
+// results untouched.
+var redactor *redact.Redactor
+
+// SetRedactor installs the path redactor applied by the redaction stage.
+// Call before RegisterAll.
+func SetRedactor(r *redact.Redactor) {
+	redactor = r
+}
+
+// withRedaction rewrites absolute paths in every text item of a result,
+// error messages included: JSON documents value by value, other text as
+// a whole.
+func withRedaction(t server.ServerTool) server.ServerTool {
+	next := t.Handler
+	t.Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		res, err := next(ctx, req)
+		if res == nil || !redactor.Enabled() {
+			return res, err
+		}
+		for i, c := range res.Content {
+			text, ok := c.(mcp.TextContent)
+			if !ok {
+				continue
+			}
+			if json.Valid([]byte(text.Text)) {
+				text.Text = string(redactor.JSON([]byte(text.Text)))
+			} else {
+				text.Text = redactor.String(text.Text)
+			}
+			res.Content[i] = text
+		}
+		return res, err
+	}
+	return t
+}
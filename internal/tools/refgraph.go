@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/internal/graph"
+)
+
+// refDefinition is one definition tag from the reference_edges command.
+type refDefinition struct {
+	File  string `json:"file"`
+	Name  string `json:"name"`
+	Line  int    `json:"line"`
+	Kind  string `json:"kind"`
+	Scope string `json:"scope"`
+	ID    string `json:"id,omitempty"`
+}
+
+// refEdge is one caller → callee edge from the reference_edges command.
+type refEdge struct {
+	SrcFile   string `json:"src_file"`
+	SrcSymbol string `json:"src_symbol"`
+	DstFile   string `json:"dst_file"`
+	DstSymbol string `json:"dst_symbol"`
+	SrcID     string `json:"src_id,omitempty"`
+	DstID     string `json:"dst_id,omitempty"`
+}
+
+// refFileEdge is one importer → imported file edge from the
+// reference_edges command.
+type refFileEdge struct {
+	SrcFile string `json:"src_file"`
+	DstFile string `json:"dst_file"`
+}
+
+// refData is the decoded reference_edges result.
+type refData struct {
+	Language    string          `json:"language"`
+	Definitions []refDefinition `json:"definitions"`
+	Edges       []refEdge       `json:"edges"`
+	FileEdges   []refFileEdge   `json:"file_edges"`
+}
+
+// fetchRefData runs reference_edges for project and decodes the result.
+func fetchRefData(ctx context.Context, bridge analysis.Backend, project, language string, maxFiles int) (*refData, error) {
+	result, err := runCached(ctx, bridge, "reference_edges", "reference_edges", project, map[string]any{
+		"language":  language,
+		"max_files": maxFiles,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rd, err := decodeRefData(result)
+	if err != nil {
+		return nil, err
+	}
+	pushRefData(project, rd)
+	return rd, nil
+}
+
+// decodeRefData converts a raw reference_edges result into refData.
+func decodeRefData(result any) (*refData, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("marshal reference_edges: %w", err)
+	}
+	var rd refData
+	if err := json.Unmarshal(data, &rd); err != nil {
+		return nil, fmt.Errorf("decode reference_edges: %w", err)
+	}
+	return &rd, nil
+}
+
+// symbolID is the node ID for a symbol in symbol-level graphs. It is local
+// to one analysis; stableIDs maps it to the cross-analysis symbol ID.
+func symbolID(file, symbol string) string {
+	return file + ":" + symbol
+}
+
+// symbolGraph builds a caller → callee graph over symbols.
+func (rd *refData) symbolGraph() *graph.Graph {
+	g := graph.New()
+	for _, e := range rd.Edges {
+		g.AddEdge(symbolID(e.SrcFile, e.SrcSymbol), symbolID(e.DstFile, e.DstSymbol))
+	}
+	return g
+}
+
+// fileGraph builds a file-level dependency graph: an edge a → b means some
+// symbol in a calls a symbol in b or, with imports, that a imports b.
+// Import edges keep files used only for their constants, types, or
+// re-exports in key_symbols' ranking; drift snapshots leave them out so
+// their cycles stay comparable with older snapshots.
+func (rd *refData) fileGraph(imports bool) *graph.Graph {
+	g := graph.New()
+	for _, d := range rd.Definitions {
+		g.AddNode(d.File)
+	}
+	for _, e := range rd.Edges {
+		g.AddEdge(e.SrcFile, e.DstFile)
+	}
+	if imports {
+		for _, e := range rd.FileEdges {
+			g.AddEdge(e.SrcFile, e.DstFile)
+		}
+	}
+	return g
+}
+
+// definitionIndex maps symbol IDs to their definitions.
+func (rd *refData) definitionIndex() map[string]refDefinition {
+	idx := make(map[string]refDefinition, len(rd.Definitions))
+	for _, d := range rd.Definitions {
+		name := d.Name
+		if d.Scope != "" {
+			name = d.Scope + "." + d.Name
+		}
+		idx[symbolID(d.File, name)] = d
+		if _, ok := idx[symbolID(d.File, d.Name)]; !ok {
+			idx[symbolID(d.File, d.Name)] = d
+		}
+	}
+	return idx
+}
+
+// stableIDs maps file:symbol node IDs to the stable symbol IDs
+// (package#qualified.name@sighash) assigned by the Python side.
+func (rd *refData) stableIDs() map[string]string {
+	ids := make(map[string]string)
+	for _, d := range rd.Definitions {
+		if d.ID == "" {
+			continue
+		}
+		name := d.Name
+		if d.Scope != "" {
+			name = d.Scope + "." + d.Name
+		}
+		ids[symbolID(d.File, name)] = d.ID
+	}
+	for _, e := range rd.Edges {
+		if e.SrcID != "" {
+			ids[symbolID(e.SrcFile, e.SrcSymbol)] = e.SrcID
+		}
+		if e.DstID != "" {
+			ids[symbolID(e.DstFile, e.DstSymbol)] = e.DstID
+		}
+	}
+	return ids
+}
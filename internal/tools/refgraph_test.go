@@ -0,0 +1,81 @@
+package tools
+
+import "testing"
+
+func testRefData() *refData {
+	return &refData{
+		Language: "go",
+		Definitions: []refDefinition{
+			{File: "a.go", Name: "Main", Line: 3, Kind: "func"},
+			{File: "b.go", Name: "Run", Line: 10, Kind: "method", Scope: "Server"},
+			{File: "c.go", Name: "Helper", Line: 5, Kind: "func"},
+		},
+		Edges: []refEdge{
+			{SrcFile: "a.go", SrcSymbol: "Main", DstFile: "b.go", DstSymbol: "Server.Run"},
+			{SrcFile: "b.go", SrcSymbol: "Server.Run", DstFile: "c.go", DstSymbol: "Helper"},
+			{SrcFile: "a.go", SrcSymbol: "Main", DstFile: "c.go", DstSymbol: "Helper"},
+		},
+	}
+}
+
+func TestRefData_SymbolGraph(t *testing.T) {
+	g := testRefData().symbolGraph()
+	if g.Len() != 3 || g.EdgeCount() != 3 {
+		t.Fatalf("expected 3 nodes/3 edges, got %d/%d", g.Len(), g.EdgeCount())
+	}
+	if got := g.InDegree("c.go:Helper"); got != 2 {
+		t.Errorf("expected Helper to have 2 callers, got %d", got)
+	}
+}
+
+func TestRefData_FileGraph(t *testing.T) {
+	g := testRefData().fileGraph(true)
+	if g.Len() != 3 {
+		t.Errorf("expected 3 files, got %d", g.Len())
+	}
+	if got := g.InDegree("c.go"); got != 2 {
+		t.Errorf("expected c.go to have 2 dependents, got %d", got)
+	}
+
+	// d.go is only imported, never called.
+	rd := testRefData()
+	rd.Definitions = append(rd.Definitions, refDefinition{File: "d.go", Name: "Limit", Line: 1, Kind: "const"})
+	rd.FileEdges = []refFileEdge{{SrcFile: "a.go", DstFile: "d.go"}, {SrcFile: "b.go", DstFile: "d.go"}, {SrcFile: "a.go", DstFile: "b.go"}}
+	if g = rd.fileGraph(false); g.InDegree("d.go") != 0 {
+		t.Error("import edges added without imports")
+	}
+	g = rd.fileGraph(true)
+	if got := g.InDegree("d.go"); got != 2 {
+		t.Errorf("expected d.go to have 2 importers, got %d", got)
+	}
+	if g.EdgeCount() != 5 {
+		t.Errorf("expected 5 file edges (a→b counted once), got %d", g.EdgeCount())
+	}
+}
+
+func TestRefData_DefinitionIndex(t *testing.T) {
+	idx := testRefData().definitionIndex()
+	if d, ok := idx["b.go:Server.Run"]; !ok || d.Line != 10 {
+		t.Errorf("expected scoped lookup for Server.Run, got %+v (ok=%v)", d, ok)
+	}
+	if d, ok := idx["b.go:Run"]; !ok || d.Kind != "method" {
+		t.Errorf("expected bare-name lookup for Run, got %+v (ok=%v)", d, ok)
+	}
+}
+
+func TestRefData_StableIDs(t *testing.T) {
+	rd := testRefData()
+	rd.Definitions[0].ID = ".#Main@1234abcd"
+	rd.Edges[0].DstID = ".#Server.Run@deadbeef"
+
+	ids := rd.stableIDs()
+	if ids["a.go:Main"] != ".#Main@1234abcd" {
+		t.Errorf("expected definition ID for Main, got %q", ids["a.go:Main"])
+	}
+	if ids["b.go:Server.Run"] != ".#Server.Run@deadbeef" {
+		t.Errorf("expected edge ID for Server.Run, got %q", ids["b.go:Server.Run"])
+	}
+	if _, ok := ids["c.go:Helper"]; ok {
+		t.Error("expected no ID for Helper")
+	}
+}
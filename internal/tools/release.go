@@ -0,0 +1,196 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/internal/deps"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// ReleasePin is a consumer's pin of the released project.
+type ReleasePin struct {
+	Ecosystem string `json:"ecosystem"`
+	Version   string `json:"version,omitempty"`
+	Source    string `json:"source"`
+	// LastRelease is set when the pin is the release being compared
+	// against (since), so the consumer gets the changes on its next bump.
+	LastRelease bool `json:"last_release,omitempty"`
+}
+
+// BreakingUse is a consumer line naming a removed or changed symbol.
+type BreakingUse struct {
+	Symbol  string `json:"symbol"`
+	Package string `json:"package"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+// ReleaseConsumer is one workspace project importing the released project.
+type ReleaseConsumer struct {
+	Project      string        `json:"project"`
+	Path         string        `json:"path"`
+	Files        int           `json:"files"`
+	Packages     []string      `json:"packages"`
+	BreakingUses []BreakingUse `json:"breaking_uses"`
+	Pins         []ReleasePin  `json:"pins"`
+	// LocalReplace is the go.mod replace pointing the consumer at the
+	// workspace copy; such consumers build against unreleased changes now.
+	LocalReplace string `json:"local_replace,omitempty"`
+}
+
+// ReleaseImpactResult is the response for the release_impact tool.
+type ReleaseImpactResult struct {
+	Project     string `json:"project"`
+	Path        string `json:"path"`
+	Language    string `json:"language"`
+	Since       string `json:"since,omitempty"`
+	PublishedAs []struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"published_as"`
+	// API is the api_surface diff since the last release: removed,
+	// changed, extended, and added symbols, and whether any break. It is
+	// null when the project has no tag.
+	API          map[string]any    `json:"api"`
+	Breaking     bool              `json:"breaking"`
+	Consumers    []ReleaseConsumer `json:"consumers"`
+	DeclaredOnly []string          `json:"declared_only"`
+	// Coordinate lists the consumers with breaking uses, most affected
+	// first: the projects to talk to before tagging.
+	Coordinate []string `json:"coordinate"`
+}
+
+func releaseImpact(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("release_impact",
+			mcp.WithDescription("Before tagging a release of a workspace project: diff its public API since the last tag (removed, changed, extended, added symbols), list the workspace projects that import it with the version each pins and any local replace, and point out consumer lines naming a removed or changed symbol. coordinate lists the consumers to talk to first."),
+			mcp.WithString("project",
+				mcp.Description("Project about to be released: name or path"),
+				mcp.Required(),
+			),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
+			),
+			mcp.WithString("since",
+				mcp.Description("Ref of the previous release (default: the latest tag reachable from HEAD)"),
+			),
+			mcp.WithString("language",
+				mcp.Description("The project's language (defaults to the detected language)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project := stringOr(args["project"], "")
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+			root := stringOr(args["root"], "")
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+			root, err := filepath.Abs(root)
+			if err != nil {
+				return mcputil.WrapError(fmt.Errorf("abs root: %w", err))
+			}
+			path, err := findProject(root, project)
+			if err != nil {
+				return mcputil.NotFoundError("%v", err)
+			}
+
+			pyArgs := map[string]any{
+				"project":   path,
+				"language":  languageOr(args["language"], path),
+				"max_depth": registry.MaxDepth(),
+			}
+			if since := stringOr(args["since"], ""); since != "" {
+				pyArgs["since"] = since
+			}
+			raw, err := bridge.Run(ctx, "release_impact", root, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			result, err := decodeReleaseImpact(raw)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			addReleasePins(result)
+			return jsonResult(result)
+		},
+	}
+}
+
+// findProject resolves a project name or path under root to its directory.
+func findProject(root, project string) (string, error) {
+	projects, err := registry.Scan(root)
+	if err != nil {
+		return "", fmt.Errorf("scan: %w", err)
+	}
+	abs, _ := filepath.Abs(project)
+	for _, p := range projects {
+		if p.Name == project || p.Path == abs {
+			return p.Path, nil
+		}
+	}
+	return "", fmt.Errorf("project %q not found under %s", project, root)
+}
+
+func decodeReleaseImpact(raw map[string]any) (*ReleaseImpactResult, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("marshal release_impact: %w", err)
+	}
+	var r ReleaseImpactResult
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("decode release_impact: %w", err)
+	}
+	return &r, nil
+}
+
+// addReleasePins fills in each consumer's pins of the released project
+// and its local replace, and derives Breaking and Coordinate.
+func addReleasePins(r *ReleaseImpactResult) {
+	published := map[string]string{}
+	for _, p := range r.PublishedAs {
+		published[p.Ecosystem] = p.Name
+	}
+	if breaking, ok := r.API["breaking"].(bool); ok {
+		r.Breaking = breaking
+	}
+	r.Coordinate = []string{}
+	for i := range r.Consumers {
+		c := &r.Consumers[i]
+		c.Pins = []ReleasePin{}
+		for _, comp := range deps.Scan(c.Path) {
+			if name, ok := published[comp.Ecosystem]; ok && comp.Direct && comp.Name == name {
+				c.Pins = append(c.Pins, ReleasePin{
+					Ecosystem:   comp.Ecosystem,
+					Version:     comp.Version,
+					Source:      comp.Source,
+					LastRelease: r.Since != "" && comp.Version == r.Since,
+				})
+			}
+		}
+		if module, ok := published[deps.Go]; ok {
+			for _, rep := range deps.GoReplaces(c.Path) {
+				if rep.Old == module && rep.Local && filepath.Clean(filepath.Join(c.Path, rep.New)) == r.Path {
+					c.LocalReplace = rep.Target()
+				}
+			}
+		}
+		if len(c.BreakingUses) > 0 {
+			r.Coordinate = append(r.Coordinate, c.Project)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddReleasePins(t *testing.T) {
+	root := t.TempDir()
+	lib := filepath.Join(root, "lib")
+	app := filepath.Join(root, "app")
+	cli := filepath.Join(root, "cli")
+	for path, content := range map[string]string{
+		filepath.Join(app, "go.mod"): "module example.com/app\n\nrequire example.com/lib v1.0.0\n",
+		filepath.Join(cli, "go.mod"): "module example.com/cli\n\nrequire example.com/lib v0.9.0\n\nreplace example.com/lib => ../lib\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := decodeReleaseImpact(map[string]any{
+		"project":      "lib",
+		"path":         lib,
+		"since":        "v1.0.0",
+		"published_as": []any{map[string]any{"ecosystem": "golang", "name": "example.com/lib"}},
+		"api":          map[string]any{"breaking": true},
+		"consumers": []any{
+			map[string]any{"project": "app", "path": app, "breaking_uses": []any{
+				map[string]any{"symbol": "Close", "package": "client", "file": "main.go", "line": 7},
+			}},
+			map[string]any{"project": "cli", "path": cli, "breaking_uses": []any{}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addReleasePins(r)
+
+	if !r.Breaking || len(r.Coordinate) != 1 || r.Coordinate[0] != "app" {
+		t.Errorf("breaking %v, coordinate %v", r.Breaking, r.Coordinate)
+	}
+	appC, cliC := r.Consumers[0], r.Consumers[1]
+	if len(appC.Pins) != 1 || appC.Pins[0].Version != "v1.0.0" || !appC.Pins[0].LastRelease || appC.LocalReplace != "" {
+		t.Errorf("app: %+v", appC)
+	}
+	if len(cliC.Pins) != 1 || cliC.Pins[0].LastRelease || cliC.LocalReplace != "../lib" {
+		t.Errorf("cli: %+v", cliC)
+	}
+}
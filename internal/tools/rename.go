@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+)
+
+// allowWritesEnv must be "1" for apply_rename to modify files and for
+// export_map and sbom to write their output.
+const allowWritesEnv = "INTERMAP_ALLOW_WRITES"
+
+func applyRename(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("apply_rename",
+			mcp.WithDescription("Rename an identifier or rewrite an import path across a project. Returns the edits, a unified diff, and whether the rename is mechanically safe (new name unused and not a keyword; the old name never used as a member, exported or public, builtin, or outside the renamed files; Go or Python only). Dry run by default; writing requires dry_run=false and INTERMAP_ALLOW_WRITES=1 on the server, and only safe renames are written."),
+			mcp.WithString("project",
+				mcp.Description("Project path to edit"),
+				mcp.Required(),
+			),
+			mcp.WithString("old",
+				mcp.Description("Identifier or import path to rename"),
+				mcp.Required(),
+			),
+			mcp.WithString("new",
+				mcp.Description("Replacement identifier or import path"),
+				mcp.Required(),
+			),
+			mcp.WithString("kind",
+				mcp.Description("identifier (default): whole-word occurrences outside comments and strings. import: Go import paths, Python modules, or JS/TS specifiers, including paths below old"),
+			),
+			mcp.WithString("language",
+				mcp.Description("Language whose files are edited (defaults to the detected project language)"),
+			),
+			mcp.WithArray("files",
+				mcp.Description("Only edit these project-relative files"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithBoolean("dry_run",
+				mcp.Description("Only report the edits and diff (default true)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project, _ := args["project"].(string)
+			old, _ := args["old"].(string)
+			replacement, _ := args["new"].(string)
+			if project == "" || old == "" || replacement == "" {
+				return mcputil.ValidationError("project, old, and new are required")
+			}
+			kind := stringOr(args["kind"], "identifier")
+			switch kind {
+			case "identifier", "import":
+			default:
+				return mcputil.ValidationError("kind must be identifier or import, got %q", kind)
+			}
+			dryRun := boolOr(args["dry_run"], true)
+			if !dryRun && os.Getenv(allowWritesEnv) != "1" {
+				return mcputil.ValidationError("writes are disabled; set %s=1 on the server to apply edits", allowWritesEnv)
+			}
+
+			pyArgs := map[string]any{
+				"kind":     kind,
+				"old":      old,
+				"new":      replacement,
+				"language": languageOr(args["language"], project),
+				"apply":    !dryRun,
+			}
+			if files := stringSlice(args["files"]); len(files) > 0 {
+				pyArgs["files"] = files
+			}
+
+			result, err := bridge.Run(ctx, "apply_rename", project, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/internal/coordination"
+	"github.com/mistakeknot/intermap/internal/export"
+	"github.com/mistakeknot/intermap/internal/report"
+)
+
+// ReportOptions controls what BuildReport includes.
+type ReportOptions struct {
+	ExportOptions
+	Title string
+	// Since is the git window for hotspots, in any form git log --since
+	// takes (default "30 days ago").
+	Since string
+	// Hotspots is how many files to list (default 20).
+	Hotspots int
+}
+
+// BuildReport assembles the static HTML report's data from the workspace
+// map, plus git churn within opts.Since for hotspots. Projects that are
+// not git working copies have no churn.
+func BuildReport(ctx context.Context, bridge analysis.Backend, c coordination.Provider, opts ReportOptions) (*report.Report, error) {
+	if opts.Since == "" {
+		opts.Since = "30 days ago"
+	}
+	if opts.Hotspots <= 0 {
+		opts.Hotspots = 20
+	}
+	g, err := BuildWorkspaceMap(ctx, bridge, c, opts.ExportOptions)
+	if err != nil {
+		return nil, err
+	}
+	r := &report.Report{
+		Title:        opts.Title,
+		Root:         opts.Root,
+		Generated:    time.Now(),
+		Since:        opts.Since,
+		Coordination: c != nil && c.Available(),
+	}
+	if r.Title == "" {
+		r.Title = "Workspace map: " + filepath.Base(opts.Root)
+	}
+
+	nodes := make(map[string]export.Node, len(g.Nodes))
+	byID := map[string]*report.Project{}
+	var ids, agentIDs []string
+	for _, n := range g.Nodes {
+		// Nodes are keyed by name, so same-named projects in different
+		// groups share one; keep the first.
+		if _, dup := nodes[n.ID]; dup {
+			continue
+		}
+		nodes[n.ID] = n
+		if n.Type == export.NodeProject {
+			r.Projects = append(r.Projects, report.Project{
+				Name:     n.Label,
+				Group:    metaString(n.Metadata, "group"),
+				Language: metaString(n.Metadata, "language"),
+				Path:     metaString(n.Metadata, "path"),
+				Branch:   metaString(n.Metadata, "branch"),
+			})
+			ids = append(ids, n.ID)
+		}
+		if n.Type == export.NodeAgent {
+			agentIDs = append(agentIDs, n.ID)
+		}
+	}
+	for i := range r.Projects {
+		byID[ids[i]] = &r.Projects[i]
+	}
+
+	dependsOn := map[string]map[string]bool{}
+	dependents := map[string]map[string]bool{}
+	agentProject := map[string]string{}
+	for _, e := range g.Edges {
+		switch e.Relation {
+		case export.RelDependsOn:
+			from, to := byID[e.Source], byID[e.Target]
+			if dependsOn[from.Name][to.Name] {
+				continue
+			}
+			r.Deps = append(r.Deps, report.Dep{From: from.Name, To: to.Name,
+				Type: metaString(e.Metadata, "type"), Via: metaString(e.Metadata, "via")})
+			addTo(dependsOn, from.Name, to.Name)
+			addTo(dependents, to.Name, from.Name)
+		case export.RelDefines:
+			if p := byID[e.Source]; p != nil {
+				p.Symbols = append(p.Symbols, nodes[e.Target].Label)
+			}
+		case export.RelWorksOn:
+			if p := byID[e.Target]; p != nil {
+				p.Agents = append(p.Agents, nodes[e.Source].Label)
+				agentProject[e.Source] = p.Name
+			}
+		}
+	}
+	for _, id := range agentIDs {
+		n := nodes[id]
+		reservations, _ := n.Metadata["reservations"].([]string)
+		r.Agents = append(r.Agents, report.Agent{
+			Name:         n.Label,
+			Status:       metaString(n.Metadata, "status"),
+			LastSeen:     metaString(n.Metadata, "last_seen"),
+			Project:      agentProject[n.ID],
+			Reservations: reservations,
+		})
+	}
+	for i := range r.Projects {
+		p := &r.Projects[i]
+		p.DependsOn, p.Dependents = len(dependsOn[p.Name]), len(dependents[p.Name])
+	}
+
+	r.Hotspots = churnHotspots(ctx, r.Projects, opts.Since)
+	r.Hotspots = r.Hotspots[:min(len(r.Hotspots), opts.Hotspots)]
+	return r, nil
+}
+
+// churnHotspots counts commits since since per file across projects'
+// git histories, most changed first, and sets each project's Commits.
+// Each file counts toward the innermost project holding it, so nested
+// projects are not counted twice.
+func churnHotspots(ctx context.Context, projects []report.Project, since string) []report.Hotspot {
+	var hotspots []report.Hotspot
+	for i := range projects {
+		p := &projects[i]
+		if p.Path == "" {
+			continue
+		}
+		out, err := gitOutput(ctx, p.Path, "log", "--since="+since, "--format=%x1e", "--name-only", "--relative", "--", ".")
+		if err != nil {
+			continue
+		}
+		counts := map[string]int{}
+		for _, commit := range strings.Split(string(out), "\x1e") {
+			owned := false
+			for _, file := range strings.Split(commit, "\n") {
+				if file == "" || innermost(projects, filepath.Join(p.Path, file)) != p {
+					continue
+				}
+				counts[file]++
+				owned = true
+			}
+			if owned {
+				p.Commits++
+			}
+		}
+		for file, n := range counts {
+			hotspots = append(hotspots, report.Hotspot{Project: p.Name, File: file, Commits: n})
+		}
+	}
+	slices.SortFunc(hotspots, func(a, b report.Hotspot) int {
+		if a.Commits != b.Commits {
+			return b.Commits - a.Commits
+		}
+		if a.Project != b.Project {
+			return strings.Compare(a.Project, b.Project)
+		}
+		return strings.Compare(a.File, b.File)
+	})
+	return hotspots
+}
+
+// innermost returns the project with the longest path holding file.
+func innermost(projects []report.Project, file string) *report.Project {
+	var best *report.Project
+	for i := range projects {
+		p := &projects[i]
+		if p.Path != "" && strings.HasPrefix(file, p.Path+string(filepath.Separator)) &&
+			(best == nil || len(p.Path) > len(best.Path)) {
+			best = p
+		}
+	}
+	return best
+}
+
+func addTo(m map[string]map[string]bool, key, value string) {
+	if m[key] == nil {
+		m[key] = map[string]bool{}
+	}
+	m[key][value] = true
+}
+
+func metaString(m map[string]any, key string) string {
+	if v, ok := m[key]; ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
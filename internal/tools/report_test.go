@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/mistakeknot/intermap/internal/report"
+)
+
+func TestChurnHotspots(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	commit := func(files ...string) {
+		t.Helper()
+		for _, f := range files {
+			path := filepath.Join(repo, f)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			body, _ := os.ReadFile(path)
+			if err := os.WriteFile(path, append(body, 'x'), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", "change"}} {
+			cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+			cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=ada", "GIT_AUTHOR_EMAIL=ada@example.com",
+				"GIT_COMMITTER_NAME=ada", "GIT_COMMITTER_EMAIL=ada@example.com")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, out)
+			}
+		}
+	}
+	if out, err := exec.Command("git", "-C", repo, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	commit("main.go", "lib/lib.go")
+	commit("main.go")
+	commit("main.go", "lib/lib.go")
+	commit("lib/lib.go")
+	commit("README.md")
+
+	projects := []report.Project{{Name: "app", Path: repo}, {Name: "lib", Path: filepath.Join(repo, "lib")}}
+	hot := churnHotspots(context.Background(), projects, "1 year ago")
+	want := []report.Hotspot{
+		{Project: "app", File: "main.go", Commits: 3},
+		{Project: "lib", File: "lib.go", Commits: 3},
+		{Project: "app", File: "README.md", Commits: 1},
+	}
+	if len(hot) != len(want) {
+		t.Fatalf("hotspots = %+v, want %+v", hot, want)
+	}
+	for i := range want {
+		if hot[i] != want[i] {
+			t.Errorf("hotspot %d = %+v, want %+v", i, hot[i], want[i])
+		}
+	}
+	// lib's files belong to lib, so app's commits touching only them don't count.
+	if projects[0].Commits != 4 || projects[1].Commits != 3 {
+		t.Errorf("commits: app %d, lib %d; want 4, 3", projects[0].Commits, projects[1].Commits)
+	}
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// withProjectResolution wraps a tool that takes a "project" argument so a
+// value that is not an existing path is looked up by name in the workspace
+// registry. An unambiguous match replaces the argument and the result gains
+// a note saying so; otherwise the call fails with the candidates. Calls that
+// write files or post (see projectWrites) accept only an exact match, and a
+// name, prefix, or fuzzy one fails with the suggestion instead.
+func withProjectResolution(t server.ServerTool) server.ServerTool {
+	if _, ok := t.Tool.InputSchema.Properties["project"]; !ok {
+		return t
+	}
+	next := t.Handler
+	t.Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+		project := stringOr(args["project"], "")
+		if project == "" {
+			return next(ctx, req)
+		}
+		if _, err := os.Stat(project); err == nil {
+			return next(ctx, req)
+		}
+		m, err := lookupProject(ScopeFrom(ctx), project)
+		if err != nil {
+			return mcputil.NotFoundError("%v", err)
+		}
+		resolved := m.Project
+		if m.Kind != registry.MatchExact && projectWrites(t.Tool.Name, args) {
+			return mcputil.NotFoundError("project %q not found; did you mean %s/%s (%s)? %s only writes to an exact project name or path",
+				project, resolved.Group, resolved.Name, redactor.String(resolved.Path), t.Tool.Name)
+		}
+
+		args = maps.Clone(args)
+		args["project"] = resolved.Path
+		req.Params.Arguments = args
+		res, err := next(ctx, req)
+		if err == nil && res != nil && !res.IsError {
+			note := fmt.Sprintf("note: project %q not found; using %s/%s (%s)",
+				project, resolved.Group, resolved.Name, redactor.String(resolved.Path))
+			res.Content = append(res.Content, mcp.NewTextContent(note))
+		}
+		return res, err
+	}
+	return t
+}
+
+// writeTools are the tools that change files or post to a remote when
+// called with dry_run=false.
+var writeTools = map[string]bool{
+	"apply_rename": true,
+	"annotate_pr":  true,
+}
+
+// projectWrites reports whether a call to tool with args writes or posts.
+// Both write tools default to a dry run.
+func projectWrites(tool string, args map[string]any) bool {
+	return writeTools[tool] && !boolOr(args["dry_run"], true)
+}
+
+// lookupProject matches name against the projects under the workspace root
+// (INTERMAP_WORKSPACE_ROOT, or the working directory) that scope allows.
+func lookupProject(scope *Scope, name string) (registry.Match, error) {
+	root, err := workspaceRoot()
+	if err != nil {
+		return registry.Match{}, err
+	}
+	projects, err := scanProjects(root, false)
+	if err != nil {
+		return registry.Match{}, fmt.Errorf("project %q not found and workspace scan failed: %w", name, err)
+	}
+	return registry.FindMatch(scope.filter(projects), name)
+}
+
+// workspaceRoot returns INTERMAP_WORKSPACE_ROOT, or the working directory,
+// as an absolute path.
+func workspaceRoot() (string, error) {
+	root := os.Getenv("INTERMAP_WORKSPACE_ROOT")
+	if root == "" {
+		var err error
+		if root, err = os.Getwd(); err != nil {
+			return "", fmt.Errorf("getwd: %w", err)
+		}
+	}
+	return filepath.Abs(root)
+}
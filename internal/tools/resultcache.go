@@ -0,0 +1,260 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/internal/cache"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// DefaultResultTTLs are how long each tool's sidecar results are reused
+// while the project's source mtimes are unchanged. Tools that read git
+// history, the clock, or other workspaces are not listed: MtimeHash does
+// not cover what they depend on.
+var DefaultResultTTLs = map[string]time.Duration{
+	"code_structure":    10 * time.Minute,
+	"impact_analysis":   10 * time.Minute,
+	"reference_edges":   10 * time.Minute,
+	"api_surface":       30 * time.Minute,
+	"doc_coverage":      30 * time.Minute,
+	"message_inventory": 30 * time.Minute,
+	"effects_analysis":  10 * time.Minute,
+	"taint_paths":       10 * time.Minute,
+	"error_flow":        10 * time.Minute,
+}
+
+// defaultResultEntries bounds the results cached per tool.
+const defaultResultEntries = 32
+
+var resultCaches = newResultCaches(DefaultResultTTLs, defaultResultEntries)
+
+type resultCacheSet struct {
+	mu     sync.Mutex
+	ttls   map[string]time.Duration
+	max    int
+	caches map[string]*cache.Cache[map[string]any]
+	shared *cache.Shared[map[string]any]
+	// validator is ValidateMtime, ValidateContent, or ValidateAuto.
+	validator string
+}
+
+func newResultCaches(ttls map[string]time.Duration, maxEntries int) *resultCacheSet {
+	if maxEntries <= 0 {
+		maxEntries = defaultResultEntries
+	}
+	return &resultCacheSet{ttls: ttls, max: maxEntries, caches: map[string]*cache.Cache[map[string]any]{}, validator: ValidateMtime}
+}
+
+// Result cache validators: what decides that a cached result still
+// describes the project.
+const (
+	// ValidateMtime compares source file mtimes. It is the cheapest, but
+	// checkouts that rewrite files with the same bytes invalidate results,
+	// and tools that preserve mtimes can leave stale ones.
+	ValidateMtime = "mtime"
+	// ValidateContent compares XXH64 hashes of source file contents on
+	// every lookup: the strictest, at the cost of reading every file.
+	ValidateContent = "content"
+	// ValidateAuto accepts matching mtimes and falls back to contents when
+	// they moved, so checkout churn keeps results without hashing every
+	// lookup.
+	ValidateAuto = "auto"
+)
+
+// ResultCache configures the analysis result caches.
+type ResultCache struct {
+	// TTLs overrides DefaultResultTTLs per tool; zero disables caching for
+	// that tool.
+	TTLs map[string]time.Duration
+	// MaxEntries bounds the results kept in memory per tool (0 uses the
+	// default).
+	MaxEntries int
+	// SharedDir, when set, is a directory shared with other intermap
+	// processes: results missing from memory are looked up there, and new
+	// results are written there.
+	SharedDir string
+	// Validator is ValidateMtime (the default), ValidateContent, or
+	// ValidateAuto.
+	Validator string
+}
+
+// SetResultCache replaces the analysis result caches.
+func SetResultCache(rc ResultCache) {
+	merged := make(map[string]time.Duration, len(DefaultResultTTLs)+len(rc.TTLs))
+	for tool, ttl := range DefaultResultTTLs {
+		merged[tool] = ttl
+	}
+	for tool, ttl := range rc.TTLs {
+		merged[tool] = ttl
+	}
+	set := newResultCaches(merged, rc.MaxEntries)
+	switch rc.Validator {
+	case "", ValidateMtime:
+	case ValidateContent, ValidateAuto:
+		set.validator = rc.Validator
+	default:
+		fmt.Fprintf(os.Stderr, "intermap: unknown result cache validator %q, using %s\n", rc.Validator, ValidateMtime)
+	}
+	if rc.SharedDir != "" {
+		shared, err := cache.NewShared[map[string]any](rc.SharedDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "intermap: shared result cache disabled: %v\n", err)
+		} else {
+			set.shared = shared
+			go shared.Prune(slices.Max(slices.Collect(maps.Values(merged))))
+		}
+	}
+	resultCaches = set
+}
+
+// forTool returns tool's cache, or nil when its results are not cached.
+func (s *resultCacheSet) forTool(tool string) *cache.Cache[map[string]any] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ttl := s.ttls[tool]
+	if ttl <= 0 {
+		return nil
+	}
+	c, ok := s.caches[tool]
+	if !ok {
+		c = cache.New[map[string]any](ttl, s.max)
+		s.caches[tool] = c
+	}
+	return c
+}
+
+type noResultCacheKey struct{}
+
+// withoutResultCache stands in for the cache stage when it is disabled:
+// the tool's sidecar commands always run.
+func withoutResultCache(t server.ServerTool) server.ServerTool {
+	next := t.Handler
+	t.Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return next(context.WithValue(ctx, noResultCacheKey{}, true), req)
+	}
+	return t
+}
+
+// sharedLockWait bounds how long a call waits for another process that is
+// computing the same result.
+const sharedLockWait = 30 * time.Second
+
+// runCached runs command through the bridge on behalf of tool, reusing the
+// result of an identical earlier call (same command, project, and args)
+// while the validator finds the project's sources unchanged and tool's TTL
+// has not expired. With a shared directory, the result may come from another
+// process, and concurrent identical calls across processes compute it
+// once. Errors are never cached.
+func runCached(ctx context.Context, bridge analysis.Backend, tool, command, project string, args map[string]any) (map[string]any, error) {
+	set := resultCaches
+	c := set.forTool(tool)
+	if c == nil || ctx.Value(noResultCacheKey{}) != nil {
+		return bridge.Run(ctx, command, project, args)
+	}
+	key, ok := resultKey(command, project, args)
+	if !ok {
+		return bridge.Run(ctx, command, project, args)
+	}
+	mtimeHash, err := registry.MtimeHash(project)
+	if err != nil {
+		return bridge.Run(ctx, command, project, args)
+	}
+	// The content hash reads every source file, so it is computed at most
+	// once per call and only when the validator needs it.
+	var contentHash string
+	var contentDone bool
+	content := func() string {
+		if !contentDone {
+			contentHash, _ = registry.ContentHash(project)
+			contentDone = true
+		}
+		return contentHash
+	}
+	valid := func(h *cache.Hashes) bool {
+		switch set.validator {
+		case ValidateContent:
+			return h.Content != "" && h.Content == content()
+		case ValidateAuto:
+			if h.Mtime == mtimeHash {
+				return true
+			}
+			if h.Content != "" && h.Content == content() {
+				h.Mtime = mtimeHash
+				return true
+			}
+			return false
+		}
+		return h.Mtime == mtimeHash
+	}
+	hashes := func() cache.Hashes {
+		if set.validator == ValidateMtime {
+			return cache.Hashes{Mtime: mtimeHash}
+		}
+		return cache.Hashes{Mtime: mtimeHash, Content: content()}
+	}
+	if cached, ok := c.GetValid(key, valid); ok {
+		return cached, nil
+	}
+
+	// Shared entries are addressed by the validator's hash, so a lookup
+	// needs no further checks.
+	sharedKey := ""
+	if set.shared != nil {
+		if set.validator == ValidateMtime {
+			sharedKey = key + "\x00" + mtimeHash
+		} else if h := content(); h != "" {
+			sharedKey = key + "\x00" + h
+		}
+	}
+	if sharedKey != "" {
+		ttl := set.ttls[tool]
+		if cached, ok := set.shared.Get(sharedKey, ttl); ok {
+			c.PutHashes(key, hashes(), cached)
+			return cached, nil
+		}
+		release, _ := set.shared.Lock(ctx, sharedKey, sharedLockWait)
+		defer release()
+		// Another process may have finished while we waited for the lock.
+		if cached, ok := set.shared.Get(sharedKey, ttl); ok {
+			c.PutHashes(key, hashes(), cached)
+			return cached, nil
+		}
+	}
+
+	result, err := bridge.Run(ctx, command, project, args)
+	if err != nil {
+		return nil, err
+	}
+	c.PutHashes(key, hashes(), result)
+	if sharedKey != "" {
+		if err := set.shared.Put(sharedKey, result); err != nil {
+			fmt.Fprintf(os.Stderr, "intermap: shared result cache: %v\n", err)
+		}
+	}
+	return result, nil
+}
+
+// resultKey identifies a bridge call. Map keys marshal sorted, so equal
+// arguments give equal keys.
+func resultKey(command, project string, args map[string]any) (string, bool) {
+	abs, err := filepath.Abs(project)
+	if err != nil {
+		return "", false
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	return command + "\x00" + abs + "\x00" + string(data), true
+}
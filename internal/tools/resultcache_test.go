@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+func TestRunCached(t *testing.T) {
+	bridge := pybridge.NewBridge(testPythonPath(t))
+	defer bridge.Close()
+	t.Cleanup(func() { SetResultCache(ResultCache{}) })
+	SetResultCache(ResultCache{})
+
+	project := t.TempDir()
+	src := filepath.Join(project, "a.py")
+	if err := os.WriteFile(src, []byte("def f():\n    pass\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args := map[string]any{"language": "python", "max_results": 10}
+	ctx := context.Background()
+
+	first, err := runCached(ctx, bridge, "code_structure", "structure", project, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := resultKey("structure", project, args)
+	hash, _ := registry.MtimeHash(project)
+	if cached, ok := resultCaches.forTool("code_structure").Get(key, hash); !ok || cached["root"] != first["root"] {
+		t.Fatalf("result not cached: %v", cached)
+	}
+
+	// A source change moves the mtime hash, so the entry no longer applies.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(src, later, later); err != nil {
+		t.Fatal(err)
+	}
+	newHash, _ := registry.MtimeHash(project)
+	if _, ok := resultCaches.forTool("code_structure").Get(key, newHash); ok {
+		t.Error("cache hit after a source change")
+	}
+
+	SetResultCache(ResultCache{TTLs: map[string]time.Duration{"code_structure": 0}})
+	if resultCaches.forTool("code_structure") != nil {
+		t.Error("zero TTL did not disable caching")
+	}
+	if resultCaches.forTool("api_surface") == nil {
+		t.Error("override dropped the other default TTLs")
+	}
+	if resultCaches.forTool("change_impact") != nil {
+		t.Error("change_impact results are cached")
+	}
+}
+
+func TestRunCached_Shared(t *testing.T) {
+	bridge := pybridge.NewBridge(testPythonPath(t))
+	defer bridge.Close()
+	t.Cleanup(func() { SetResultCache(ResultCache{}) })
+	shared := t.TempDir()
+	SetResultCache(ResultCache{SharedDir: shared})
+
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "a.py"), []byte("def f():\n    pass\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args := map[string]any{"language": "python", "max_results": 10}
+	first, err := runCached(context.Background(), bridge, "code_structure", "structure", project, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh cache set is another server instance. A nil bridge would
+	// panic on Run, so the result must come from the shared directory.
+	SetResultCache(ResultCache{SharedDir: shared})
+	second, err := runCached(context.Background(), nil, "code_structure", "structure", project, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second["root"] != first["root"] {
+		t.Errorf("shared result = %v, want %v", second["root"], first["root"])
+	}
+}
+
+func TestRunCached_Validators(t *testing.T) {
+	bridge := pybridge.NewBridge(testPythonPath(t))
+	defer bridge.Close()
+	t.Cleanup(func() { SetResultCache(ResultCache{}) })
+	args := map[string]any{"language": "python", "max_results": 10}
+	ctx := context.Background()
+
+	setup := func(validator string) (string, string) {
+		SetResultCache(ResultCache{Validator: validator})
+		project := t.TempDir()
+		src := filepath.Join(project, "a.py")
+		if err := os.WriteFile(src, []byte("def f():\n    pass\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runCached(ctx, bridge, "code_structure", "structure", project, args); err != nil {
+			t.Fatal(err)
+		}
+		return project, src
+	}
+
+	// auto: a touch with unchanged bytes still hits, via the content hash.
+	project, src := setup(ValidateAuto)
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(src, later, later)
+	if _, err := runCached(ctx, nil, "code_structure", "structure", project, args); err != nil {
+		t.Fatal(err)
+	}
+
+	// content: an edit that keeps the mtime is a miss.
+	project, src = setup(ValidateContent)
+	info, _ := os.Stat(src)
+	os.WriteFile(src, []byte("def f():\n    pass\n\n\ndef g():\n    pass\n"), 0o644)
+	os.Chtimes(src, info.ModTime(), info.ModTime())
+	result, err := runCached(ctx, bridge, "code_structure", "structure", project, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := json.Marshal(result); !strings.Contains(string(data), `"g"`) {
+		t.Errorf("stale result after an mtime-preserving edit: %s", data)
+	}
+}
+
+func TestResultKey_ArgOrder(t *testing.T) {
+	a, _ := resultKey("impact", ".", map[string]any{"target": "f", "max_depth": 3})
+	b, _ := resultKey("impact", ".", map[string]any{"max_depth": 3, "target": "f"})
+	c, _ := resultKey("impact", ".", map[string]any{"max_depth": 4, "target": "f"})
+	if a != b || a == c {
+		t.Errorf("keys: %q %q %q", a, b, c)
+	}
+}
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/deps"
+	"github.com/mistakeknot/intermap/internal/sbom"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+func sbomTool() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("sbom",
+			mcp.WithDescription("Software bill of materials: inventory dependencies from manifests and lockfiles (go.mod, package-lock.json, Cargo.lock, uv.lock, poetry.lock, pyproject.toml, requirements.txt) and render a CycloneDX or SPDX JSON document for one project or the whole workspace."),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
+			),
+			mcp.WithString("project",
+				mcp.Description("Project name or path to describe (default: every project in the workspace)"),
+			),
+			mcp.WithString("format",
+				mcp.Description("Output format: cyclonedx (CycloneDX 1.5, default) or spdx (SPDX 2.3)"),
+			),
+			mcp.WithString("output",
+				mcp.Description("Write the document to this file instead of returning it; needs INTERMAP_ALLOW_WRITES=1 on the server"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root := stringOr(args["root"], "")
+			format := stringOr(args["format"], "cyclonedx")
+			output := stringOr(args["output"], "")
+			if output != "" && os.Getenv(allowWritesEnv) != "1" {
+				return mcputil.ValidationError("writes are disabled; set %s=1 on the server to write output, or omit it", allowWritesEnv)
+			}
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+
+			bom, err := BuildSBOM(root, stringOr(args["project"], ""))
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			data, err := bom.Encode(format)
+			if err != nil {
+				return mcputil.ValidationError("%v", err)
+			}
+
+			if output != "" {
+				if err := os.WriteFile(output, data, 0o644); err != nil {
+					return mcputil.WrapError(fmt.Errorf("write sbom: %w", err))
+				}
+				components := 0
+				for _, p := range bom.Projects {
+					components += len(p.Components)
+				}
+				return jsonResult(map[string]any{
+					"output":     output,
+					"format":     format,
+					"projects":   len(bom.Projects),
+					"components": components,
+				})
+			}
+			return mcp.NewToolResultText(string(data)), nil
+		},
+	}
+}
+
+// BuildSBOM inventories the dependencies of the projects under root, or
+// of the one project named (by name or path) when project is set.
+func BuildSBOM(root, project string) (*sbom.BOM, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("abs root: %w", err)
+	}
+	projects, err := registry.Scan(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+	bom := &sbom.BOM{Name: filepath.Base(absRoot)}
+	for _, p := range projects {
+		if project != "" && p.Name != project && p.Path != project {
+			continue
+		}
+		components := deps.Scan(p.Path)
+		deps.ResolveLicenses(p.Path, components)
+		bom.Projects = append(bom.Projects, sbom.Project{Name: p.Name, Components: components})
+	}
+	if project != "" && len(bom.Projects) == 0 {
+		return nil, fmt.Errorf("project %q not found under %s", project, absRoot)
+	}
+	return bom, nil
+}
@@ -0,0 +1,191 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// Scope restricts a session to some of the workspace's projects. Calls
+// without one, such as over stdio, are unrestricted.
+type Scope struct {
+	// Name identifies the scope in errors, e.g. the API key's name.
+	Name string
+	// Projects are project names, group/name paths, globs over either, or
+	// absolute project paths.
+	Projects []string
+}
+
+type scopeKey struct{}
+
+// WithScope returns ctx restricted to s's projects.
+func WithScope(ctx context.Context, s *Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, s)
+}
+
+// ScopeFrom returns ctx's scope, or nil when it is unrestricted.
+func ScopeFrom(ctx context.Context) *Scope {
+	s, _ := ctx.Value(scopeKey{}).(*Scope)
+	return s
+}
+
+// Allows reports whether p is one of the scope's projects. A nil Scope
+// allows every project.
+func (s *Scope) Allows(p registry.Project) bool {
+	if s == nil {
+		return true
+	}
+	qualified := p.Name
+	if p.Group != "" {
+		qualified = p.Group + "/" + p.Name
+	}
+	for _, pat := range s.Projects {
+		if filepath.IsAbs(pat) {
+			if filepath.Clean(pat) == filepath.Clean(p.Path) {
+				return true
+			}
+			continue
+		}
+		for _, name := range []string{p.Name, qualified} {
+			if ok, _ := path.Match(pat, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filter returns the projects s allows.
+func (s *Scope) filter(projects []registry.Project) []registry.Project {
+	if s == nil {
+		return projects
+	}
+	out := make([]registry.Project, 0, len(projects))
+	for _, p := range projects {
+		if s.Allows(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// allowsPath reports whether dir lies in a workspace project s allows.
+func (s *Scope) allowsPath(dir string) bool {
+	if s == nil {
+		return true
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	root, err := workspaceRoot()
+	if err != nil {
+		return false
+	}
+	projects, err := scanProjects(root, false)
+	if err != nil {
+		return false
+	}
+	var owner *registry.Project
+	for i, p := range projects {
+		if (abs == p.Path || strings.HasPrefix(abs, p.Path+string(filepath.Separator))) &&
+			(owner == nil || len(p.Path) > len(owner.Path)) {
+			owner = &projects[i]
+		}
+	}
+	return owner != nil && s.Allows(*owner)
+}
+
+// scopeListsProjects are tools that take a workspace root but filter what
+// they return by scope themselves.
+var scopeListsProjects = map[string]bool{"project_registry": true}
+
+// scopePathArgs are the arguments that name files or directories. A
+// relative path, file, or files entry is resolved against the project, or
+// the root when there is none.
+var scopePathArgs = []string{"project", "root", "path", "file", "files"}
+
+// scopeRootOptional are tools that ignore root when given a project, so an
+// omitted root is not checked then.
+var scopeRootOptional = map[string]bool{"key_symbols": true, "ci_map": true, "semantic_search": true}
+
+// scopeNotPaths are tool arguments named like paths that are not.
+var scopeNotPaths = map[string]map[string]bool{"fetch_result": {"path": true}}
+
+// withScope rejects calls with a path argument outside the session's
+// scope: the project, the root (default the working directory), and any
+// path, file, or files. Workspace-wide tools therefore need a root inside
+// an allowed project, except those that filter their own results. Scoped
+// calls may not write an output file at all.
+func withScope(t server.ServerTool) server.ServerTool {
+	var params []string
+	for _, name := range scopePathArgs {
+		if _, ok := t.Tool.InputSchema.Properties[name]; ok && !scopeNotPaths[t.Tool.Name][name] {
+			params = append(params, name)
+		}
+	}
+	if len(params) == 0 || scopeListsProjects[t.Tool.Name] {
+		return t
+	}
+	_, hasRoot := t.Tool.InputSchema.Properties["root"]
+	_, hasOutput := t.Tool.InputSchema.Properties["output"]
+	next := t.Handler
+	t.Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		s := ScopeFrom(ctx)
+		if s == nil {
+			return next(ctx, req)
+		}
+		args := req.GetArguments()
+		if hasOutput && stringOr(args["output"], "") != "" {
+			return mcputil.ValidationError("%s may not write files on the server; omit output", s.Name)
+		}
+		base := stringOr(args["project"], "")
+		if root := stringOr(args["root"], ""); hasRoot && (root != "" || base == "" || !scopeRootOptional[t.Tool.Name]) {
+			if root == "" {
+				root, _ = os.Getwd()
+			}
+			if !s.allowsPath(root) {
+				return mcputil.ValidationError("%s may only analyze inside its projects; root %s is outside them", s.Name, redactor.String(root))
+			}
+			if base == "" {
+				base = root
+			}
+		}
+		for _, name := range params {
+			var paths []string
+			switch name {
+			case "root":
+				continue
+			case "files":
+				paths = stringSlice(args[name])
+			default:
+				if v := stringOr(args[name], ""); v != "" {
+					paths = []string{v}
+				}
+			}
+			for _, p := range paths {
+				if name != "project" && !filepath.IsAbs(p) && base != "" {
+					p = filepath.Join(base, p)
+				}
+				if !s.allowsPath(p) {
+					return mcputil.ValidationError("%s %s is outside the projects allowed for %s", name, redactor.String(p), s.Name)
+				}
+			}
+		}
+		return next(ctx, req)
+	}
+	return t
+}
+
+// allowsSelection reports whether a selection of project is one s allows.
+// A scoped session cannot use selections without a project.
+func (s *Scope) allowsSelection(project string) bool {
+	return s == nil || project != "" && s.allowsPath(project)
+}
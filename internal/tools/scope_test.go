@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+func TestWithScope(t *testing.T) {
+	root := t.TempDir()
+	for _, p := range []string{"core/intermap", "core/intermute", "web"} {
+		if err := os.MkdirAll(filepath.Join(root, p, ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("INTERMAP_WORKSPACE_ROOT", root)
+	scoped := WithScope(context.Background(), &Scope{Name: "team", Projects: []string{"core/intermap", "web"}})
+
+	ok := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("{}"), nil
+	}
+	probe := withScope(server.ServerTool{
+		Tool:    mcp.NewTool("probe", mcp.WithString("project"), mcp.WithString("root"), mcp.WithString("path"), mcp.WithArray("files")),
+		Handler: ok,
+	})
+	projectOnly := withScope(server.ServerTool{Tool: mcp.NewTool("probe", mcp.WithString("project")), Handler: ok})
+	rootOptional := withScope(server.ServerTool{Tool: mcp.NewTool("key_symbols", mcp.WithString("project"), mcp.WithString("root")), Handler: ok})
+	writes := withScope(server.ServerTool{Tool: mcp.NewTool("export_map", mcp.WithString("root"), mcp.WithString("output")), Handler: ok})
+	web := filepath.Join(root, "web")
+	for _, tt := range []struct {
+		tool    server.ServerTool
+		ctx     context.Context
+		args    map[string]any
+		allowed bool
+	}{
+		{projectOnly, scoped, map[string]any{"project": filepath.Join(root, "core", "intermap")}, true},
+		{projectOnly, scoped, map[string]any{"project": filepath.Join(root, "core", "intermap", "cmd")}, true},
+		{projectOnly, scoped, map[string]any{"project": filepath.Join(root, "core", "intermute")}, false},
+		{probe, scoped, map[string]any{"root": web}, true},
+		{probe, scoped, map[string]any{"root": root}, false},
+		{probe, scoped, map[string]any{"project": web, "root": root}, false},
+		{probe, scoped, map[string]any{"project": web}, false}, // root defaults to the working directory
+		{probe, scoped, map[string]any{"project": web, "root": web, "path": "src", "files": []any{"a.go", "b/c.go"}}, true},
+		{probe, scoped, map[string]any{"project": web, "root": web, "path": "../core/intermute"}, false},
+		{probe, scoped, map[string]any{"root": web, "files": []any{"a.go", filepath.Join(root, "core", "intermute", "x.go")}}, false},
+		{rootOptional, scoped, map[string]any{"project": web}, true},
+		{rootOptional, scoped, map[string]any{"project": web, "root": root}, false},
+		{writes, scoped, map[string]any{"root": web}, true},
+		{writes, scoped, map[string]any{"root": web, "output": filepath.Join(web, "map.json")}, false},
+		{writes, context.Background(), map[string]any{"root": root, "output": filepath.Join(web, "map.json")}, true},
+		{probe, context.Background(), map[string]any{"root": root}, true},
+	} {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = tt.args
+		res, err := tt.tool.Handler(tt.ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.IsError == tt.allowed {
+			t.Errorf("%v: allowed = %v, want %v", tt.args, !res.IsError, tt.allowed)
+		}
+	}
+
+	if _, err := lookupProject(ScopeFrom(scoped), "intermute"); err == nil {
+		t.Error("name resolution found a project outside the scope")
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"root": root, "refresh": true}
+	res, err := projectRegistry().Handler(scoped, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var projects []registry.Project
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &projects); err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, p := range projects {
+		names[p.Name] = true
+	}
+	if len(projects) != 2 || !names["intermap"] || !names["web"] {
+		t.Errorf("scoped project_registry = %+v", projects)
+	}
+}
+
+func TestScopedSelectionsAndUsage(t *testing.T) {
+	root := t.TempDir()
+	for _, p := range []string{"web", "api"} {
+		if err := os.MkdirAll(filepath.Join(root, p, ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("INTERMAP_WORKSPACE_ROOT", root)
+	scoped := WithScope(context.Background(), &Scope{Name: "team", Projects: []string{"web"}})
+	call := func(ctx context.Context, tool server.ServerTool, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		res, err := withScope(tool).Handler(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	if res := call(context.Background(), saveSelection(), map[string]any{"name": "api-scope", "project": filepath.Join(root, "api"), "files": []any{"a.go"}}); res.IsError {
+		t.Fatalf("unscoped save: %+v", res)
+	}
+	if res := call(scoped, saveSelection(), map[string]any{"name": "web-scope", "project": filepath.Join(root, "web"), "files": []any{"a.go"}}); res.IsError {
+		t.Fatalf("scoped save: %+v", res)
+	}
+	for _, args := range []map[string]any{
+		{"name": "api-scope", "project": filepath.Join(root, "web"), "files": []any{"a.go"}},
+		{"name": "loose", "files": []any{"a.go"}},
+	} {
+		if res := call(scoped, saveSelection(), args); !res.IsError {
+			t.Errorf("scoped save %v allowed", args)
+		}
+	}
+	if res := call(scoped, loadSelection(), map[string]any{"name": "api-scope"}); !res.IsError {
+		t.Error("scoped load of another project's selection allowed")
+	}
+	res := call(scoped, loadSelection(), map[string]any{})
+	if text := res.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "web-scope") || strings.Contains(text, "api-scope") {
+		t.Errorf("scoped list = %s", text)
+	}
+
+	if res := call(scoped, usageStats(), map[string]any{"all_sessions": true}); !res.IsError {
+		t.Error("scoped usage_stats listed all sessions")
+	}
+}
+
+func TestScopeAllows(t *testing.T) {
+	s := &Scope{Projects: []string{"inter*", "platform/api", "/srv/ws/tools"}}
+	for _, tt := range []struct {
+		p    registry.Project
+		want bool
+	}{
+		{registry.Project{Name: "intermap", Group: "core"}, true},
+		{registry.Project{Name: "api", Group: "platform"}, true},
+		{registry.Project{Name: "api", Group: "legacy"}, false},
+		{registry.Project{Name: "tools", Path: "/srv/ws/tools"}, true},
+		{registry.Project{Name: "tools", Path: "/srv/other/tools"}, false},
+	} {
+		if got := s.Allows(tt.p); got != tt.want {
+			t.Errorf("Allows(%+v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if !(*Scope)(nil).Allows(registry.Project{Name: "any"}) {
+		t.Error("nil scope should allow every project")
+	}
+}
@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/selection"
+)
+
+// selectionStore returns the workspace's selections, kept under
+// .intermap/selections in the workspace root.
+func selectionStore() (*selection.Store, error) {
+	root, err := workspaceRoot()
+	if err != nil {
+		return nil, err
+	}
+	return selection.New(filepath.Join(root, ".intermap", "selections")), nil
+}
+
+// selectionError maps store errors to tool errors.
+func selectionError(err error) (*mcp.CallToolResult, error) {
+	if errors.Is(err, selection.ErrNotFound) {
+		return mcputil.NotFoundError("%v", err)
+	}
+	if errors.Is(err, selection.ErrInvalid) {
+		return mcputil.ValidationError("%v", err)
+	}
+	if errors.Is(err, selection.ErrBusy) {
+		return mcputil.TransientError("%v", err)
+	}
+	return mcputil.WrapError(err)
+}
+
+func saveSelection() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("save_selection",
+			mcp.WithDescription("Save a named set of files and symbols (e.g. the auth-refactor scope) in the workspace, so later steps and other agents can pass the name instead of the lists; read it back with load_selection."),
+			mcp.WithString("name",
+				mcp.Description("Selection name: up to 64 letters, digits, '.', '_', or '-'"),
+				mcp.Required(),
+			),
+			mcp.WithArray("files",
+				mcp.Description("Files in the selection"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithArray("symbols",
+				mcp.Description("Symbols in the selection"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithString("project",
+				mcp.Description("Project path the files are relative to"),
+			),
+			mcp.WithString("description",
+				mcp.Description("What the selection is for"),
+			),
+			mcp.WithString("mode",
+				mcp.Description("replace (default) the stored selection, add to it, remove from it, or delete it"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			name := stringOr(args["name"], "")
+			if name == "" {
+				return mcputil.ValidationError("name is required")
+			}
+			store, err := selectionStore()
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			if s := ScopeFrom(ctx); s != nil {
+				if stringOr(args["project"], "") == "" {
+					return mcputil.ValidationError("project is required for %s", s.Name)
+				}
+				if prev, err := store.Load(name); err == nil && !s.allowsSelection(prev.Project) {
+					return mcputil.ValidationError("selection %s belongs to a project outside those allowed for %s", name, s.Name)
+				}
+			}
+			mode := stringOr(args["mode"], selection.Replace)
+			if mode == "delete" {
+				if err := store.Delete(name); err != nil {
+					return selectionError(err)
+				}
+				return jsonResult(map[string]any{"name": name, "deleted": true})
+			}
+			sel, err := store.Save(selection.Selection{
+				Name:        name,
+				Description: stringOr(args["description"], ""),
+				Project:     stringOr(args["project"], ""),
+				Files:       stringSlice(args["files"]),
+				Symbols:     stringSlice(args["symbols"]),
+			}, mode)
+			if err != nil {
+				return selectionError(err)
+			}
+			return jsonResult(sel.Summary())
+		},
+	}
+}
+
+func loadSelection() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("load_selection",
+			mcp.WithDescription("Load a named set of files and symbols saved with save_selection; without a name, list the saved selections."),
+			mcp.WithString("name",
+				mcp.Description("Selection name; omit to list selections"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			store, err := selectionStore()
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			name := stringOr(req.GetArguments()["name"], "")
+			if name == "" {
+				list, err := store.List()
+				if err != nil {
+					return mcputil.WrapError(err)
+				}
+				if s := ScopeFrom(ctx); s != nil {
+					allowed := list[:0]
+					for _, sum := range list {
+						if s.allowsSelection(sum.Project) {
+							allowed = append(allowed, sum)
+						}
+					}
+					list = allowed
+				}
+				return jsonResult(map[string]any{"selections": list})
+			}
+			sel, err := store.Load(name)
+			if err != nil {
+				return selectionError(err)
+			}
+			if !ScopeFrom(ctx).allowsSelection(sel.Project) {
+				return mcputil.NotFoundError("%v: %s", selection.ErrNotFound, name)
+			}
+			return jsonResult(sel)
+		},
+	}
+}
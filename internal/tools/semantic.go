@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// SemanticSearch configures the embedding index behind semantic_search.
+// An empty DB disables the tool.
+type SemanticSearch struct {
+	DB        string
+	Provider  string
+	Endpoint  string
+	Model     string
+	APIKeyEnv string
+}
+
+var semanticSearch SemanticSearch
+
+// SetSemanticSearch enables semantic_search, filling in the default
+// provider, endpoint, and model.
+func SetSemanticSearch(s SemanticSearch) {
+	if s.Provider == "" {
+		s.Provider = "openai"
+	}
+	if s.Provider == "openai" {
+		if s.Endpoint == "" {
+			s.Endpoint = "http://localhost:11434/v1/embeddings"
+		}
+		if s.Model == "" {
+			s.Model = "nomic-embed-text"
+		}
+	}
+	if s.Provider == "local" && s.Model == "" {
+		s.Model = "all-MiniLM-L6-v2"
+	}
+	semanticSearch = s
+}
+
+func semanticSearchTool(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("semantic_search",
+			mcp.WithDescription("Answer natural-language queries (\"where do we validate reservation patterns\") with ranked code locations, by embedding symbols and files and comparing them to the query. Each call first embeds chunks that changed since the last call. Requires semantic_search in the config."),
+			mcp.WithString("query",
+				mcp.Description("What to look for, in plain language"),
+				mcp.Required(),
+			),
+			mcp.WithString("project",
+				mcp.Description("Project path to search (default: the whole workspace under root)"),
+			),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to search when no project is given (defaults to CWD)"),
+			),
+			mcp.WithNumber("top_k",
+				mcp.Description("Number of results (default 10)"),
+			),
+			mcp.WithNumber("max_embed",
+				mcp.Description("Embed at most this many new chunks before answering; the rest are reported as pending and embedded by later calls (default 2000)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if semanticSearch.DB == "" {
+				return mcputil.ValidationError("semantic search is not enabled; set semantic_search.db in the intermap config")
+			}
+			args := req.GetArguments()
+			query := stringOr(args["query"], "")
+			if query == "" {
+				return mcputil.ValidationError("query is required")
+			}
+			path := stringOr(args["project"], stringOr(args["root"], ""))
+			if path == "" {
+				var err error
+				path, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+
+			s := semanticSearch
+			result, err := bridge.Run(ctx, "semantic_search", path, map[string]any{
+				"db":          s.DB,
+				"query":       query,
+				"provider":    s.Provider,
+				"endpoint":    s.Endpoint,
+				"model":       s.Model,
+				"api_key_env": s.APIKeyEnv,
+				"top_k":       intOr(args["top_k"], 10),
+				"max_embed":   intOr(args["max_embed"], 2000),
+				"max_depth":   registry.MaxDepth(),
+			})
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+func serviceGraph(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("service_graph",
+			mcp.WithDescription("Infer which deployable projects call which at runtime — the service graph, distinct from the import graph. Edges come from HTTP/gRPC clients whose URL, port, or generated stub points at a sibling project, and from endpoint environment variables (INTERMUTE_URL, RANKER_GRPC_ADDR) read in code or set in .env files. Each project is marked deployable when it listens on a port, ships a Dockerfile, or builds a binary."),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
+			),
+			mcp.WithString("project",
+				mcp.Description("Only return this project's service and the edges touching it"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root := stringOr(args["root"], "")
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+
+			result, err := bridge.Run(ctx, "service_graph", root, map[string]any{"max_depth": registry.MaxDepth()})
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			if project := stringOr(args["project"], ""); project != "" {
+				if result, err = focusServiceGraph(result, project); err != nil {
+					return mcputil.NotFoundError("%v", err)
+				}
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+// focusServiceGraph narrows a service_graph result to one project's
+// service and the edges into or out of it.
+func focusServiceGraph(result map[string]any, project string) (map[string]any, error) {
+	var services []any
+	if all, ok := result["services"].([]any); ok {
+		for _, s := range all {
+			if m, ok := s.(map[string]any); ok && m["project"] == project {
+				services = append(services, m)
+			}
+		}
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("project %q not found under %v", project, result["root"])
+	}
+	edges := []any{}
+	if all, ok := result["edges"].([]any); ok {
+		for _, e := range all {
+			if m, ok := e.(map[string]any); ok && (m["from"] == project || m["to"] == project) {
+				edges = append(edges, m)
+			}
+		}
+	}
+	return map[string]any{
+		"root":        result["root"],
+		"services":    services,
+		"edges":       edges,
+		"total_edges": len(edges),
+	}, nil
+}
@@ -0,0 +1,34 @@
+package tools
+
+import "testing"
+
+func TestFocusServiceGraph(t *testing.T) {
+	result := map[string]any{
+		"root": "/ws",
+		"services": []any{
+			map[string]any{"project": "intermap"},
+			map[string]any{"project": "intermute"},
+			map[string]any{"project": "ranker"},
+		},
+		"edges": []any{
+			map[string]any{"from": "intermap", "to": "intermute"},
+			map[string]any{"from": "intermap", "to": "ranker"},
+			map[string]any{"from": "ranker", "to": "intermute"},
+		},
+	}
+
+	got, err := focusServiceGraph(result, "ranker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if services := got["services"].([]any); len(services) != 1 {
+		t.Errorf("services = %v", services)
+	}
+	if got["total_edges"] != 2 {
+		t.Errorf("edges = %v", got["edges"])
+	}
+
+	if _, err := focusServiceGraph(result, "nope"); err == nil {
+		t.Error("unknown project: no error")
+	}
+}
@@ -0,0 +1,313 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/internal/graph"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// BrokenReference is a call site that stops resolving after the simulated change.
+type BrokenReference struct {
+	File   string `json:"file"`
+	Symbol string `json:"symbol"`
+	Target string `json:"target"`
+}
+
+// Dependency is a directed package or project dependency.
+type Dependency struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// LayerViolation is a new dependency from a lower layer to a higher one.
+type LayerViolation struct {
+	Dependency
+	FromLayer string `json:"from_layer"`
+	ToLayer   string `json:"to_layer"`
+}
+
+// SimulateMoveResult is the response for the simulate_move tool.
+type SimulateMoveResult struct {
+	Project                    string            `json:"project"`
+	Operation                  string            `json:"operation"`
+	Source                     string            `json:"source"`
+	Destination                string            `json:"destination,omitempty"`
+	MovedSymbols               []string          `json:"moved_symbols"`
+	BrokenReferences           []BrokenReference `json:"broken_references"`
+	NewPackageDependencies     []Dependency      `json:"new_package_dependencies"`
+	RemovedPackageDependencies []Dependency      `json:"removed_package_dependencies"`
+	NewProjectDependencies     []Dependency      `json:"new_project_dependencies,omitempty"`
+	NewCycles                  [][]string        `json:"new_cycles"`
+	LayerViolations            []LayerViolation  `json:"layer_violations"`
+	Safe                       bool              `json:"safe"`
+}
+
+// moveSpec describes a simulated relocation or deletion.
+type moveSpec struct {
+	SourceFile   string
+	SourceSymbol string // empty = whole file
+	Dest         string // project-relative file, "@project/rel" for another project, empty = delete
+	DestProject  string // name of the destination project when it differs from the source
+	ProjectName  string
+	Layers       []string
+}
+
+func simulateMove(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("simulate_move",
+			mcp.WithDescription("Simulate relocating or deleting a file or symbol before editing: reports call sites that would break, new package/project dependencies, new import cycles, and layering violations."),
+			mcp.WithString("project",
+				mcp.Description("Project path to analyze"),
+				mcp.Required(),
+			),
+			mcp.WithString("source",
+				mcp.Description("Project-relative file to move, or file:Symbol to move a single symbol"),
+				mcp.Required(),
+			),
+			mcp.WithString("destination",
+				mcp.Description("Destination file (project-relative, or absolute for another project). Omit to simulate deletion."),
+			),
+			mcp.WithArray("layers",
+				mcp.Description("Ordered directory prefixes from top to bottom layer; a lower layer may not depend on a higher one"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (defaults to the detected project language)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project := stringOr(args["project"], "")
+			source := stringOr(args["source"], "")
+			if project == "" || source == "" {
+				return mcputil.ValidationError("project and source are required")
+			}
+			absProject, err := filepath.Abs(project)
+			if err != nil {
+				return mcputil.WrapError(fmt.Errorf("abs project: %w", err))
+			}
+
+			spec := moveSpec{
+				ProjectName: filepath.Base(absProject),
+				Layers:      stringSlice(args["layers"]),
+			}
+			spec.SourceFile, spec.SourceSymbol, _ = strings.Cut(filepath.ToSlash(source), ":")
+
+			if dest := stringOr(args["destination"], ""); dest != "" {
+				if !filepath.IsAbs(dest) {
+					dest = filepath.Join(absProject, dest)
+				}
+				spec.Dest, spec.DestProject, err = resolveDestination(absProject, dest)
+				if err != nil {
+					return mcputil.ValidationError("destination: %v", err)
+				}
+			}
+
+			rd, err := fetchRefData(ctx, bridge, project, languageOr(args["language"], project), 5000)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(runMoveSimulation(rd, project, spec))
+		},
+	}
+}
+
+// resolveDestination converts an absolute destination into a project-relative
+// path, or "@name/rel" plus the project name when it lies in another project.
+func resolveDestination(absProject, dest string) (string, string, error) {
+	if rel, err := filepath.Rel(absProject, dest); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel), "", nil
+	}
+	p, err := registry.Resolve(filepath.Dir(dest))
+	if err != nil {
+		return "", "", err
+	}
+	rel, err := filepath.Rel(p.Path, dest)
+	if err != nil {
+		return "", "", err
+	}
+	return "@" + p.Name + "/" + filepath.ToSlash(rel), p.Name, nil
+}
+
+// runMoveSimulation rewrites the reference graph according to spec and diffs
+// the package graph before and after.
+func runMoveSimulation(rd *refData, project string, spec moveSpec) SimulateMoveResult {
+	res := SimulateMoveResult{
+		Project:                    project,
+		Operation:                  "move",
+		Source:                     spec.SourceFile,
+		Destination:                spec.Dest,
+		MovedSymbols:               []string{},
+		BrokenReferences:           []BrokenReference{},
+		NewPackageDependencies:     []Dependency{},
+		RemovedPackageDependencies: []Dependency{},
+		NewCycles:                  [][]string{},
+		LayerViolations:            []LayerViolation{},
+	}
+	if spec.SourceSymbol != "" {
+		res.Source += ":" + spec.SourceSymbol
+	}
+	if spec.Dest == "" {
+		res.Operation = "delete"
+	}
+
+	moved := func(file, symbol string) bool {
+		if file != spec.SourceFile {
+			return false
+		}
+		return spec.SourceSymbol == "" || symbol == spec.SourceSymbol || strings.HasPrefix(symbol, spec.SourceSymbol+".")
+	}
+	// unit is what a reference binds to: the package directory for Go,
+	// the module file for everything else.
+	unit := func(file string) string {
+		if rd.Language == "go" {
+			return path.Dir(file)
+		}
+		return file
+	}
+
+	movedSet := make(map[string]bool)
+	for _, d := range rd.Definitions {
+		name := d.Name
+		if d.Scope != "" {
+			name = d.Scope + "." + d.Name
+		}
+		if moved(d.File, name) {
+			movedSet[name] = true
+		}
+	}
+
+	before, after := graph.New(), graph.New()
+	callersRemain, movedCallsBack := false, false
+	for _, e := range rd.Edges {
+		srcMoved, dstMoved := moved(e.SrcFile, e.SrcSymbol), moved(e.DstFile, e.DstSymbol)
+		if srcMoved {
+			movedSet[e.SrcSymbol] = true
+		}
+		if dstMoved {
+			movedSet[e.DstSymbol] = true
+		}
+
+		addPkgEdge(before, e.SrcFile, e.DstFile)
+		if res.Operation == "delete" {
+			if dstMoved && !srcMoved {
+				res.BrokenReferences = append(res.BrokenReferences, BrokenReference{File: e.SrcFile, Symbol: e.SrcSymbol, Target: symbolID(e.DstFile, e.DstSymbol)})
+			}
+			if !srcMoved && !dstMoved {
+				addPkgEdge(after, e.SrcFile, e.DstFile)
+			}
+			continue
+		}
+
+		src, dst := e.SrcFile, e.DstFile
+		if srcMoved {
+			src = spec.Dest
+		}
+		if dstMoved {
+			dst = spec.Dest
+			if !srcMoved {
+				callersRemain = true
+				if unit(e.DstFile) != unit(spec.Dest) || spec.DestProject != "" {
+					res.BrokenReferences = append(res.BrokenReferences, BrokenReference{File: e.SrcFile, Symbol: e.SrcSymbol, Target: symbolID(e.DstFile, e.DstSymbol)})
+				}
+			}
+		} else if srcMoved {
+			movedCallsBack = true
+		}
+		addPkgEdge(after, src, dst)
+	}
+
+	for s := range movedSet {
+		res.MovedSymbols = append(res.MovedSymbols, s)
+	}
+	sort.Strings(res.MovedSymbols)
+
+	res.NewPackageDependencies = edgeDiff(after, before)
+	res.RemovedPackageDependencies = edgeDiff(before, after)
+
+	if spec.DestProject != "" {
+		if callersRemain {
+			res.NewProjectDependencies = append(res.NewProjectDependencies, Dependency{From: spec.ProjectName, To: spec.DestProject})
+		}
+		if movedCallsBack {
+			res.NewProjectDependencies = append(res.NewProjectDependencies, Dependency{From: spec.DestProject, To: spec.ProjectName})
+		}
+	}
+
+	existing := make(map[string]bool)
+	for _, c := range before.Cycles() {
+		existing[strings.Join(c, "\x00")] = true
+	}
+	for _, c := range after.Cycles() {
+		if !existing[strings.Join(c, "\x00")] {
+			res.NewCycles = append(res.NewCycles, c)
+		}
+	}
+
+	if len(spec.Layers) > 0 {
+		for _, d := range res.NewPackageDependencies {
+			fi, fl := layerOf(d.From, spec.Layers)
+			ti, tl := layerOf(d.To, spec.Layers)
+			if fi >= 0 && ti >= 0 && fi > ti {
+				res.LayerViolations = append(res.LayerViolations, LayerViolation{Dependency: d, FromLayer: fl, ToLayer: tl})
+			}
+		}
+	}
+
+	res.Safe = len(res.BrokenReferences) == 0 && len(res.NewCycles) == 0 && len(res.LayerViolations) == 0
+	return res
+}
+
+// addPkgEdge adds a directory-level dependency edge for a file-level edge.
+func addPkgEdge(g *graph.Graph, srcFile, dstFile string) {
+	src, dst := path.Dir(srcFile), path.Dir(dstFile)
+	g.AddNode(src)
+	g.AddNode(dst)
+	if src != dst {
+		g.AddEdge(src, dst)
+	}
+}
+
+// edgeDiff returns edges present in a but not in b, sorted.
+func edgeDiff(a, b *graph.Graph) []Dependency {
+	out := []Dependency{}
+	for _, from := range a.Nodes() {
+		have := make(map[string]bool)
+		for _, to := range b.Successors(from) {
+			have[to] = true
+		}
+		for _, to := range a.Successors(from) {
+			if !have[to] {
+				out = append(out, Dependency{From: from, To: to})
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].From != out[j].From {
+			return out[i].From < out[j].From
+		}
+		return out[i].To < out[j].To
+	})
+	return out
+}
+
+// layerOf returns the index and prefix of the first layer containing pkg, or -1.
+func layerOf(pkg string, layers []string) (int, string) {
+	for i, l := range layers {
+		l = strings.Trim(l, "/")
+		if pkg == l || strings.HasPrefix(pkg, l+"/") {
+			return i, l
+		}
+	}
+	return -1, ""
+}
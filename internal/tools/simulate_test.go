@@ -0,0 +1,76 @@
+package tools
+
+import "testing"
+
+func simulateRefData() *refData {
+	return &refData{
+		Language: "go",
+		Definitions: []refDefinition{
+			{File: "api/handler.go", Name: "Handle", Kind: "func"},
+			{File: "store/db.go", Name: "Query", Kind: "func"},
+			{File: "store/util.go", Name: "Format", Kind: "func"},
+		},
+		Edges: []refEdge{
+			{SrcFile: "api/handler.go", SrcSymbol: "Handle", DstFile: "store/db.go", DstSymbol: "Query"},
+			{SrcFile: "store/db.go", SrcSymbol: "Query", DstFile: "store/util.go", DstSymbol: "Format"},
+		},
+	}
+}
+
+func TestRunMoveSimulation_Delete(t *testing.T) {
+	res := runMoveSimulation(simulateRefData(), "/p", moveSpec{SourceFile: "store/db.go", SourceSymbol: "Query"})
+	if res.Operation != "delete" {
+		t.Errorf("expected delete, got %s", res.Operation)
+	}
+	if len(res.BrokenReferences) != 1 || res.BrokenReferences[0].File != "api/handler.go" {
+		t.Errorf("expected handler.go to break, got %+v", res.BrokenReferences)
+	}
+	if res.Safe {
+		t.Error("expected unsafe deletion")
+	}
+}
+
+func TestRunMoveSimulation_CycleAndLayers(t *testing.T) {
+	// Moving Format into api/ makes store depend on api while api depends on store.
+	res := runMoveSimulation(simulateRefData(), "/p", moveSpec{
+		SourceFile:   "store/util.go",
+		SourceSymbol: "Format",
+		Dest:         "api/format.go",
+		Layers:       []string{"api", "store"},
+	})
+	if len(res.NewPackageDependencies) != 1 || res.NewPackageDependencies[0] != (Dependency{From: "store", To: "api"}) {
+		t.Errorf("expected new store → api dependency, got %+v", res.NewPackageDependencies)
+	}
+	if len(res.NewCycles) != 1 {
+		t.Errorf("expected one new cycle, got %v", res.NewCycles)
+	}
+	if len(res.LayerViolations) != 1 || res.LayerViolations[0].FromLayer != "store" {
+		t.Errorf("expected store → api layer violation, got %+v", res.LayerViolations)
+	}
+	if len(res.BrokenReferences) != 1 {
+		t.Errorf("expected Query's call to Format to need updating, got %+v", res.BrokenReferences)
+	}
+}
+
+func TestRunMoveSimulation_SamePackageIsSafe(t *testing.T) {
+	res := runMoveSimulation(simulateRefData(), "/p", moveSpec{
+		SourceFile:   "store/util.go",
+		SourceSymbol: "Format",
+		Dest:         "store/format.go",
+	})
+	if !res.Safe {
+		t.Errorf("expected same-package move to be safe, got %+v", res)
+	}
+}
+
+func TestRunMoveSimulation_CrossProject(t *testing.T) {
+	res := runMoveSimulation(simulateRefData(), "/p", moveSpec{
+		SourceFile:  "store/db.go",
+		Dest:        "@shared/db.go",
+		DestProject: "shared",
+		ProjectName: "app",
+	})
+	if len(res.NewProjectDependencies) != 2 {
+		t.Errorf("expected app ↔ shared dependencies, got %+v", res.NewProjectDependencies)
+	}
+}
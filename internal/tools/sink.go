@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"path/filepath"
+
+	"github.com/mistakeknot/intermap/internal/graphsink"
+)
+
+// graphSink receives analysis results as tools run. The zero value is a
+// disabled sink.
+var graphSink *graphsink.Sink
+
+// SetGraphSink installs the sink that reference and dependency analyses are
+// mirrored to. Call before RegisterAll.
+func SetGraphSink(s *graphsink.Sink) {
+	graphSink = s
+}
+
+// pushRefData mirrors a project's definitions and call edges to the sink,
+// keyed by stable symbol ID where the Python side provided one.
+func pushRefData(project string, rd *refData) {
+	if !graphSink.Enabled() {
+		return
+	}
+	stable := rd.stableIDs()
+	idOf := func(local string) string {
+		if id, ok := stable[local]; ok {
+			return id
+		}
+		return local
+	}
+
+	symbols := make([]graphsink.Symbol, 0, len(rd.Definitions))
+	for _, d := range rd.Definitions {
+		name := d.Name
+		if d.Scope != "" {
+			name = d.Scope + "." + d.Name
+		}
+		id := d.ID
+		if id == "" {
+			id = symbolID(d.File, name)
+		}
+		symbols = append(symbols, graphsink.Symbol{ID: id, File: d.File, Name: name, Kind: d.Kind, Line: d.Line})
+	}
+	calls := make([]graphsink.Call, 0, len(rd.Edges))
+	for _, e := range rd.Edges {
+		calls = append(calls, graphsink.Call{
+			Src: idOf(symbolID(e.SrcFile, e.SrcSymbol)),
+			Dst: idOf(symbolID(e.DstFile, e.DstSymbol)),
+		})
+	}
+	graphSink.PushSymbols(projectName(project), symbols, calls)
+}
+
+// pushCrossProjectDeps mirrors cross-project dependency edges to the sink.
+func pushCrossProjectDeps(cd *crossProjectData) {
+	if !graphSink.Enabled() {
+		return
+	}
+	var deps []graphsink.Dependency
+	for _, p := range cd.Projects {
+		for _, d := range p.DependsOn {
+			deps = append(deps, graphsink.Dependency{From: p.Project, To: d.Project, Type: d.Type, Via: d.Via})
+		}
+	}
+	graphSink.PushDependencies(deps)
+}
+
+// projectName matches registry naming: the project directory's base name.
+func projectName(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return filepath.Base(path)
+}
@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mistakeknot/intermap/internal/graphsink"
+)
+
+func TestPushRefData(t *testing.T) {
+	var got []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Statements []struct {
+				Parameters map[string]any `json:"parameters"`
+			} `json:"statements"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, s := range body.Statements {
+			got = append(got, s.Parameters)
+		}
+		w.Write([]byte(`{"results":[],"errors":[]}`))
+	}))
+	defer ts.Close()
+
+	sink := graphsink.New(graphsink.WithEndpoint(ts.URL))
+	SetGraphSink(sink)
+	defer SetGraphSink(nil)
+
+	pushRefData("/work/intermap", testRefData())
+	sink.Close()
+
+	if len(got) != 3 {
+		t.Fatalf("expected 3 statements, got %d", len(got))
+	}
+	if got[0]["project"] != "intermap" {
+		t.Errorf("expected project intermap, got %v", got[0]["project"])
+	}
+	if rows, _ := got[0]["rows"].([]any); len(rows) != 3 {
+		t.Errorf("expected 3 symbols, got %v", got[0]["rows"])
+	}
+	if rows, _ := got[1]["rows"].([]any); len(rows) != 3 {
+		t.Errorf("expected 3 calls, got %v", got[1]["rows"])
+	}
+}
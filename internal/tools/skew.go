@@ -0,0 +1,354 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/deps"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// Pin is one project's choice of a dependency version.
+type Pin struct {
+	Project string `json:"project"`
+	// Source is the manifest, lockfile, vendor/modules.txt, or .gitmodules
+	// the pin was read from.
+	Source string `json:"source"`
+	Direct bool   `json:"direct,omitempty"`
+}
+
+// SkewVersion groups the pins of one version.
+type SkewVersion struct {
+	Version string `json:"version"`
+	Pins    []Pin  `json:"pins"`
+}
+
+// SkewedDependency is a dependency pinned at more than one version.
+type SkewedDependency struct {
+	Ecosystem string        `json:"ecosystem"`
+	Name      string        `json:"name"`
+	Versions  []SkewVersion `json:"versions"`
+}
+
+// PinConflict is a Go pin that a replace directive or go.work overrides
+// or contradicts, so the version a project builds with depends on whether
+// it is built inside the workspace.
+type PinConflict struct {
+	Project string `json:"project"`
+	Module  string `json:"module"`
+	Pinned  string `json:"pinned,omitempty"`
+	// Kind is "workspace_module" (go.work uses a local copy of the module),
+	// "workspace_replace" (go.work replaces it), "replace_mismatch" (the
+	// project's own replace differs from go.work's), "replace_not_in_workspace"
+	// (a local replace points outside go.work's use list), or "vendor_stale"
+	// (vendor/modules.txt disagrees with go.mod).
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// VersionSkewResult is the response for the version_skew tool.
+type VersionSkewResult struct {
+	Root      string             `json:"root"`
+	Projects  int                `json:"projects"`
+	Skew      []SkewedDependency `json:"skew"`
+	Conflicts []PinConflict      `json:"conflicts"`
+	// GoWork lists the go.work files found at the root or a project.
+	GoWork []string `json:"go_work"`
+}
+
+func versionSkew() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("version_skew",
+			mcp.WithDescription("Find dependencies that workspace projects pin at different versions (from manifests, lockfiles, vendor/modules.txt, and git submodule commits), and Go pins that go.mod replace directives or go.work override or contradict."),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
+			),
+			mcp.WithArray("ecosystems",
+				mcp.Description("Only these ecosystems: golang, npm, pypi, cargo, git (default all)"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithBoolean("include_indirect",
+				mcp.Description("Also compare transitive dependencies from lockfiles (default false: direct dependencies only)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root := stringOr(args["root"], "")
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+			ecosystems := stringSlice(args["ecosystems"])
+			for _, e := range ecosystems {
+				switch e {
+				case deps.Go, deps.NPM, deps.PyPI, deps.Cargo, "git":
+				default:
+					return mcputil.ValidationError("unknown ecosystem %q (want golang, npm, pypi, cargo, or git)", e)
+				}
+			}
+
+			result, err := FindVersionSkew(ctx, root, ecosystems, boolOr(args["include_indirect"], false))
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+// FindVersionSkew compares the dependency pins of the projects under root.
+// An empty ecosystems list means all of them.
+func FindVersionSkew(ctx context.Context, root string, ecosystems []string, includeIndirect bool) (*VersionSkewResult, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("abs root: %w", err)
+	}
+	projects, err := registry.Scan(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+	wanted := func(eco string) bool {
+		return len(ecosystems) == 0 || slices.Contains(ecosystems, eco)
+	}
+
+	works := map[string]deps.WorkFile{}
+	if w, ok := deps.GoWork(absRoot); ok {
+		works[absRoot] = w
+	}
+	for _, p := range projects {
+		if w, ok := deps.GoWork(p.Path); ok {
+			works[p.Path] = w
+		}
+	}
+
+	type key struct{ eco, name string }
+	pins := map[key]map[string][]Pin{}
+	add := func(eco, name, version string, pin Pin) {
+		k := key{eco, name}
+		if pins[k] == nil {
+			pins[k] = map[string][]Pin{}
+		}
+		pins[k][version] = append(pins[k][version], pin)
+	}
+
+	result := &VersionSkewResult{Root: absRoot, Projects: len(projects), Skew: []SkewedDependency{}, Conflicts: []PinConflict{}, GoWork: []string{}}
+	for _, p := range projects {
+		var required []deps.Component
+		for _, c := range deps.Scan(p.Path) {
+			if c.Version == "" || !wanted(c.Ecosystem) || (!c.Direct && !includeIndirect) {
+				continue
+			}
+			add(c.Ecosystem, c.Name, c.Version, Pin{Project: p.Name, Source: c.Source, Direct: c.Direct})
+			if c.Ecosystem == deps.Go {
+				required = append(required, c)
+			}
+		}
+		if wanted("git") {
+			for _, s := range submoduleCommits(ctx, p.Path) {
+				add("git", s.url, s.commit, Pin{Project: p.Name, Source: ".gitmodules " + s.path, Direct: true})
+			}
+		}
+		if wanted(deps.Go) {
+			result.Conflicts = append(result.Conflicts, goPinConflicts(p, required, nearestWork(works, p.Path, absRoot))...)
+		}
+	}
+
+	for k, byVersion := range pins {
+		if len(byVersion) < 2 {
+			continue
+		}
+		d := SkewedDependency{Ecosystem: k.eco, Name: k.name}
+		for v, ps := range byVersion {
+			sort.Slice(ps, func(i, j int) bool { return ps[i].Project < ps[j].Project })
+			d.Versions = append(d.Versions, SkewVersion{Version: v, Pins: ps})
+		}
+		sort.Slice(d.Versions, func(i, j int) bool { return d.Versions[i].Version < d.Versions[j].Version })
+		result.Skew = append(result.Skew, d)
+	}
+	sort.Slice(result.Skew, func(i, j int) bool {
+		a, b := result.Skew[i], result.Skew[j]
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		return a.Name < b.Name
+	})
+	for dir := range works {
+		result.GoWork = append(result.GoWork, filepath.Join(dir, "go.work"))
+	}
+	sort.Strings(result.GoWork)
+	return result, nil
+}
+
+// nearestWork returns the go.work governing dir: the one in the deepest
+// directory at or above it, within root.
+func nearestWork(works map[string]deps.WorkFile, dir, root string) *deps.WorkFile {
+	for d := dir; ; d = filepath.Dir(d) {
+		if w, ok := works[d]; ok {
+			return &w
+		}
+		if d == root || d == filepath.Dir(d) {
+			return nil
+		}
+	}
+}
+
+// goPinConflicts checks a project's Go requirements against its own
+// replace directives, its vendor directory, and the governing go.work.
+func goPinConflicts(p registry.Project, required []deps.Component, work *deps.WorkFile) []PinConflict {
+	var out []PinConflict
+	own := deps.GoReplaces(p.Path)
+
+	vendored := map[string]string{}
+	for _, c := range deps.VendoredGo(p.Path) {
+		vendored[c.Name] = c.Version
+	}
+	if len(vendored) > 0 {
+		for _, c := range required {
+			if v, ok := vendored[c.Name]; ok && v != c.Version {
+				out = append(out, PinConflict{Project: p.Name, Module: c.Name, Pinned: c.Version, Kind: "vendor_stale",
+					Detail: fmt.Sprintf("vendor/modules.txt has %s; run go mod vendor", v)})
+			}
+		}
+	}
+
+	if work == nil {
+		return out
+	}
+	inWorkspace := slices.Contains(work.Use, p.Path)
+	workModules := map[string]string{}
+	for _, dir := range work.Use {
+		if mod := deps.GoModulePath(dir); mod != "" {
+			workModules[mod] = dir
+		}
+	}
+	for _, c := range required {
+		if !inWorkspace {
+			break
+		}
+		if dir, ok := workModules[c.Name]; ok {
+			out = append(out, PinConflict{Project: p.Name, Module: c.Name, Pinned: c.Version, Kind: "workspace_module",
+				Detail: "go.work uses the local module in " + relTo(work.Dir, dir) + "; the pinned version only applies outside the workspace"})
+			continue
+		}
+		for _, r := range work.Replace {
+			if r.Applies(c.Name, c.Version) {
+				out = append(out, PinConflict{Project: p.Name, Module: c.Name, Pinned: c.Version, Kind: "workspace_replace",
+					Detail: "go.work replaces it with " + r.Target()})
+				break
+			}
+		}
+	}
+	for _, r := range own {
+		if !inWorkspace {
+			break
+		}
+		for _, wr := range work.Replace {
+			if wr.Old == r.Old && wr.OldVersion == r.OldVersion && !sameTarget(wr, work.Dir, r, p.Path) {
+				out = append(out, PinConflict{Project: p.Name, Module: r.Old, Kind: "replace_mismatch",
+					Detail: "go.mod replaces it with " + r.Target() + " but go.work with " + wr.Target() + ", which wins in the workspace"})
+			}
+		}
+		if r.Local {
+			target := filepath.Clean(filepath.Join(p.Path, r.New))
+			if !slices.Contains(work.Use, target) {
+				out = append(out, PinConflict{Project: p.Name, Module: r.Old, Kind: "replace_not_in_workspace",
+					Detail: "go.mod replaces it with " + r.New + ", which go.work does not use"})
+			}
+		}
+	}
+	return out
+}
+
+// sameTarget reports whether two replace directives, each relative to its
+// own file's directory, point at the same replacement.
+func sameTarget(a deps.Replace, aDir string, b deps.Replace, bDir string) bool {
+	if a.Local && b.Local {
+		return filepath.Clean(filepath.Join(aDir, a.New)) == filepath.Clean(filepath.Join(bDir, b.New))
+	}
+	return a.Local == b.Local && a.New == b.New && a.NewVersion == b.NewVersion
+}
+
+func relTo(base, path string) string {
+	if rel, err := filepath.Rel(base, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+type submodule struct {
+	path, url, commit string
+}
+
+// submoduleCommits returns the project's git submodules with the commits
+// its HEAD records for them. URLs are normalized to host/path so clones
+// over https and ssh compare equal.
+func submoduleCommits(ctx context.Context, dir string) []submodule {
+	data, err := os.ReadFile(filepath.Join(dir, ".gitmodules"))
+	if err != nil {
+		return nil
+	}
+	var subs []submodule
+	var cur *submodule
+	for _, line := range strings.Split(string(data), "\n") {
+		t := strings.TrimSpace(line)
+		if strings.HasPrefix(t, "[submodule") {
+			subs = append(subs, submodule{})
+			cur = &subs[len(subs)-1]
+			continue
+		}
+		k, v, ok := strings.Cut(t, "=")
+		if !ok || cur == nil {
+			continue
+		}
+		switch strings.TrimSpace(k) {
+		case "path":
+			cur.path = strings.TrimSpace(v)
+		case "url":
+			cur.url = normalizeGitURL(strings.TrimSpace(v))
+		}
+	}
+
+	var out []submodule
+	for _, s := range subs {
+		if s.path == "" || s.url == "" {
+			continue
+		}
+		res, err := exec.CommandContext(ctx, "git", "-C", dir, "ls-tree", "HEAD", "--", s.path).Output()
+		if err != nil {
+			continue
+		}
+		// "160000 commit <sha>\t<path>"
+		fields := strings.Fields(string(res))
+		if len(fields) >= 3 && fields[1] == "commit" {
+			s.commit = fields[2]
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func normalizeGitURL(u string) string {
+	u = strings.TrimSuffix(strings.TrimSuffix(u, "/"), ".git")
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+		if at := strings.Index(u, "@"); at >= 0 && at < strings.Index(u+"/", "/") {
+			u = u[at+1:]
+		}
+	} else if at := strings.Index(u, "@"); at >= 0 {
+		// scp-like: git@host:owner/repo
+		u = strings.Replace(u[at+1:], ":", "/", 1)
+	}
+	return u
+}
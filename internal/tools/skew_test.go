@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindVersionSkew(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, body string) {
+		t.Helper()
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, p := range []string{"api", "lib", "worker", "web"} {
+		if err := os.MkdirAll(filepath.Join(root, p, ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.work", "go 1.23\n\nuse (\n\t./api\n\t./lib\n\t./worker\n)\n\nreplace github.com/x/y => github.com/x/y v1.5.0\n")
+	write("lib/go.mod", "module example.com/lib\n\ngo 1.23\n\nrequire github.com/x/y v1.2.0\n")
+	write("api/go.mod", "module example.com/api\n\ngo 1.23\n\nrequire (\n\texample.com/lib v0.3.0\n\tgithub.com/x/y v1.4.0\n\tgithub.com/p/q v0.2.0\n)\n\nreplace example.com/shared => ../shared\n")
+	write("api/vendor/modules.txt", "# github.com/p/q v0.1.0\n## explicit\n")
+	write("worker/go.mod", "module example.com/worker\n\ngo 1.23\n\nrequire github.com/x/y v1.2.0\n\nreplace github.com/x/y => ../y\n")
+	write("web/package.json", `{"name": "web", "dependencies": {"left-pad": "1.3.0"}}`)
+
+	result, err := FindVersionSkew(context.Background(), root, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Projects != 4 || !reflect.DeepEqual(result.GoWork, []string{filepath.Join(root, "go.work")}) {
+		t.Errorf("projects = %d, go_work = %v", result.Projects, result.GoWork)
+	}
+	if len(result.Skew) != 1 {
+		t.Fatalf("skew = %+v", result.Skew)
+	}
+	d := result.Skew[0]
+	if d.Ecosystem != "golang" || d.Name != "github.com/x/y" || len(d.Versions) != 2 {
+		t.Fatalf("skewed = %+v", d)
+	}
+	if v := d.Versions[0]; v.Version != "v1.2.0" || len(v.Pins) != 2 || v.Pins[0].Project != "lib" || v.Pins[1].Project != "worker" {
+		t.Errorf("v1.2.0 pins = %+v", v)
+	}
+
+	got := map[string][]string{}
+	for _, c := range result.Conflicts {
+		got[c.Project] = append(got[c.Project], c.Kind+" "+c.Module)
+	}
+	want := map[string][]string{
+		"api": {
+			"vendor_stale github.com/p/q",
+			"workspace_module example.com/lib",
+			"workspace_replace github.com/x/y",
+			"replace_not_in_workspace example.com/shared",
+		},
+		"lib":    {"workspace_replace github.com/x/y"},
+		"worker": {"workspace_replace github.com/x/y", "replace_mismatch github.com/x/y", "replace_not_in_workspace github.com/x/y"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("conflicts = %v, want %v", got, want)
+	}
+
+	npmOnly, err := FindVersionSkew(context.Background(), root, []string{"npm"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(npmOnly.Skew) != 0 || len(npmOnly.Conflicts) != 0 {
+		t.Errorf("npm only = %+v", npmOnly)
+	}
+}
+
+func TestNormalizeGitURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://github.com/a/b.git":         "github.com/a/b",
+		"git@github.com:a/b.git":             "github.com/a/b",
+		"ssh://git@github.com/a/b":           "github.com/a/b",
+		"https://user@gitlab.example.com/c/": "gitlab.example.com/c",
+	} {
+		if got := normalizeGitURL(in); got != want {
+			t.Errorf("normalizeGitURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"bytes"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Snippet bounds for impact_analysis include_snippets.
+const (
+	defaultSnippetLines = 3
+	maxSnippetLines     = 20
+	defaultSnippetBytes = 32 << 10
+	maxSnippetFileBytes = 4 << 20
+)
+
+// Snippet is the source around a caller's call site.
+type Snippet struct {
+	// Line is the call site, or the caller's definition when the call
+	// could not be located.
+	Line  int    `json:"line"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+// snippetter attaches snippets to caller trees within a byte budget,
+// reading each file once.
+type snippetter struct {
+	project   string
+	context   int
+	budget    int
+	truncated bool
+	files     map[string][]string
+}
+
+// addSnippets attaches a Snippet to every caller in result's targets,
+// context lines either side of the call, until budget bytes of snippet
+// text are used. Callers past the budget get none and the result gains
+// snippets_truncated.
+func addSnippets(project string, result map[string]any, context, budget int) {
+	s := &snippetter{project: project, context: context, budget: budget, files: map[string][]string{}}
+	targets, _ := result["targets"].(map[string]any)
+	for _, key := range slices.Sorted(maps.Keys(targets)) {
+		if node, ok := targets[key].(map[string]any); ok {
+			s.walk(node)
+		}
+	}
+	if s.truncated {
+		result["snippets_truncated"] = true
+	}
+}
+
+// walk attaches snippets to node's callers, breadth first so nearer
+// callers win when the budget runs out.
+func (s *snippetter) walk(root map[string]any) {
+	queue := []map[string]any{root}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		callee, _ := node["function"].(string)
+		callers, _ := node["callers"].([]any)
+		for _, c := range callers {
+			caller, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			if snip, ok := s.snippet(caller, callee); ok {
+				if len(snip.Text) > s.budget {
+					s.truncated = true
+				} else {
+					s.budget -= len(snip.Text)
+					caller["snippet"] = snip
+				}
+			}
+			queue = append(queue, caller)
+		}
+	}
+}
+
+func (s *snippetter) snippet(caller map[string]any, callee string) (Snippet, bool) {
+	file, _ := caller["file"].(string)
+	lines := s.read(file)
+	if lines == nil {
+		return Snippet{}, false
+	}
+	line := intOr(caller["line"], 0)
+	if line <= 0 {
+		fn, _ := caller["function"].(string)
+		line = callSite(lines, fn, callee)
+	}
+	if line <= 0 || line > len(lines) {
+		return Snippet{}, false
+	}
+	start := max(1, line-s.context)
+	end := min(len(lines), line+s.context)
+	return Snippet{Line: line, Start: start, End: end, Text: strings.Join(lines[start-1:end], "\n")}, true
+}
+
+func (s *snippetter) read(file string) []string {
+	if file == "" {
+		return nil
+	}
+	if lines, ok := s.files[file]; ok {
+		return lines
+	}
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.project, file)
+	}
+	var lines []string
+	if info, err := os.Stat(path); err == nil && info.Size() <= maxSnippetFileBytes {
+		if data, err := os.ReadFile(path); err == nil && !bytes.Contains(data[:min(len(data), 8000)], []byte{0}) {
+			lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		}
+	}
+	s.files[file] = lines
+	return lines
+}
+
+// callSite finds the 1-based line where fn calls callee: the first call
+// after fn's definition, else any call in the file, else the definition.
+// It returns 0 when none is found.
+func callSite(lines []string, fn, callee string) int {
+	def := 0
+	if name := lastSegment(fn); name != "" {
+		defRe := regexp.MustCompile(`^\s*(?:async\s+def|def|func|function)\s+(?:\([^)]*\)\s*)?` + regexp.QuoteMeta(name) + `\b`)
+		for i, l := range lines {
+			if defRe.MatchString(l) {
+				def = i + 1
+				break
+			}
+		}
+	}
+	if name := lastSegment(callee); name != "" {
+		callRe := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\s*\(`)
+		for i := def; i < len(lines); i++ {
+			if callRe.MatchString(lines[i]) {
+				return i + 1
+			}
+		}
+		if def > 0 {
+			for i := range def {
+				if callRe.MatchString(lines[i]) {
+					return i + 1
+				}
+			}
+		}
+	}
+	return def
+}
+
+// lastSegment strips receiver and package qualifiers: "T.M" becomes "M".
+func lastSegment(name string) string {
+	if i := strings.LastIndexAny(name, ".:"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
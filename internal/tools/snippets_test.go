@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const snippetSource = `package app
+
+import "fmt"
+
+func helper() {
+	parse("b")
+}
+
+func run() {
+	fmt.Println("start")
+	x := parse("a")
+	fmt.Println(x)
+}
+
+func parse(s string) string {
+	return s
+}
+`
+
+func TestCallSite(t *testing.T) {
+	lines := strings.Split(snippetSource, "\n")
+	for _, tt := range []struct {
+		fn, callee string
+		want       int
+	}{
+		{"run", "parse", 11},
+		{"helper", "app.parse", 6},
+		{"run", "missing", 9},
+		{"nowhere", "parse", 6},
+		{"nowhere", "missing", 0},
+	} {
+		if got := callSite(lines, tt.fn, tt.callee); got != tt.want {
+			t.Errorf("callSite(%q, %q) = %d, want %d", tt.fn, tt.callee, got, tt.want)
+		}
+	}
+}
+
+func TestAddSnippets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.go"), []byte(snippetSource), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	impact := func() map[string]any {
+		var m map[string]any
+		json.Unmarshal([]byte(`{"targets": {"parse": {"function": "parse", "file": "app.go", "callers": [
+			{"function": "run", "file": "app.go", "callers": [
+				{"function": "main", "file": "main.go", "callers": []}]},
+			{"function": "helper", "file": "app.go", "line": 6, "callers": []}]}}}`), &m)
+		return m
+	}
+	callers := func(m map[string]any) []any {
+		return m["targets"].(map[string]any)["parse"].(map[string]any)["callers"].([]any)
+	}
+
+	m := impact()
+	addSnippets(dir, m, 1, defaultSnippetBytes)
+	run := callers(m)[0].(map[string]any)
+	if snip, ok := run["snippet"].(Snippet); !ok || snip.Line != 11 || snip.Start != 10 || snip.End != 12 ||
+		!strings.Contains(snip.Text, `x := parse("a")`) {
+		t.Errorf("run snippet = %+v", run["snippet"])
+	}
+	if snip, ok := callers(m)[1].(map[string]any)["snippet"].(Snippet); !ok || snip.Line != 6 {
+		t.Errorf("helper snippet = %+v", snip)
+	}
+	if _, ok := run["callers"].([]any)[0].(map[string]any)["snippet"]; ok {
+		t.Error("caller in a missing file got a snippet")
+	}
+	if m["snippets_truncated"] != nil {
+		t.Error("snippets_truncated set within budget")
+	}
+
+	m = impact()
+	addSnippets(dir, m, 1, 30)
+	if _, ok := callers(m)[0].(map[string]any)["snippet"]; ok {
+		t.Error("snippet over budget attached")
+	}
+	if m["snippets_truncated"] != true {
+		t.Error("snippets_truncated not set over budget")
+	}
+}
@@ -0,0 +1,490 @@
+package tools
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/internal/coordination"
+	"github.com/mistakeknot/intermap/internal/mcpfilter"
+	"github.com/mistakeknot/intermap/internal/schema"
+)
+
+// Backend is something a tool runs on, as listed in the tool table.
+type Backend string
+
+const (
+	BackendGo        Backend = "Go"
+	BackendPython    Backend = "Python"    // the analysis sidecar
+	BackendIntermute Backend = "intermute" // the coordination provider
+	BackendGit       Backend = "git"
+)
+
+// Deps are the shared backends tool constructors draw on.
+type Deps struct {
+	Analysis     analysis.Backend
+	Coordination coordination.Provider
+}
+
+// Spec declares one MCP tool. Registration, profile filtering, and the
+// tool table in CLAUDE.md are all derived from Specs.
+type Spec struct {
+	Name    string
+	Cluster mcpfilter.Cluster
+	// Backends are what the tool runs on, main implementation first.
+	Backends []Backend
+	// Summary is the tool's one-line description in the tool table.
+	Summary string
+	// New builds the tool's schema and handler.
+	New func(Deps) server.ServerTool
+}
+
+// Needs reports whether the tool runs on b.
+func (s Spec) Needs(b Backend) bool {
+	for _, have := range s.Backends {
+		if have == b {
+			return true
+		}
+	}
+	return false
+}
+
+func noDeps(f func() server.ServerTool) func(Deps) server.ServerTool {
+	return func(Deps) server.ServerTool { return f() }
+}
+
+func needsAnalysis(f func(analysis.Backend) server.ServerTool) func(Deps) server.ServerTool {
+	return func(d Deps) server.ServerTool { return f(d.Analysis) }
+}
+
+func needsCoordination(f func(coordination.Provider) server.ServerTool) func(Deps) server.ServerTool {
+	return func(d Deps) server.ServerTool { return f(d.Coordination) }
+}
+
+// returns declares that the tool returns the result of the sidecar command,
+// publishing the command's schema as the tool's output schema. Tools that
+// reshape the result do not declare one.
+func returns(command string, f func(Deps) server.ServerTool) func(Deps) server.ServerTool {
+	out := schema.Output(command, reflect.TypeFor[SpilledResult]())
+	return func(d Deps) server.ServerTool {
+		t := f(d)
+		t.Tool.RawOutputSchema = out
+		return t
+	}
+}
+
+// Specs lists every tool in registration order. Adding a tool means adding
+// an entry here and regenerating the docs table (see TestToolTable).
+var Specs = []Spec{
+	{
+		Name:     "project_registry",
+		Cluster:  mcpfilter.ClusterStructure,
+		Backends: []Backend{BackendGo},
+		Summary:  "Scan workspace projects; filter by group, language, or name glob and select fields",
+		New:      noDeps(projectRegistry),
+	},
+	{
+		Name:     "resolve_project",
+		Cluster:  mcpfilter.ClusterStructure,
+		Backends: []Backend{BackendGo},
+		Summary:  "Find project for a file path",
+		New:      noDeps(resolveProject),
+	},
+	{
+		Name:     "agent_map",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendGo, BackendIntermute},
+		Summary:  "Active agents overlay",
+		New:      needsCoordination(agentMap),
+	},
+	{
+		Name:     "code_structure",
+		Cluster:  mcpfilter.ClusterStructure,
+		Backends: []Backend{BackendPython},
+		Summary:  "Functions/classes/imports",
+		New:      returns("structure", needsAnalysis(codeStructure)),
+	},
+	{
+		Name:     "impact_analysis",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython, BackendGo},
+		Summary:  "Reverse call graph (`precision`: fast, precise for Go, typed for Python)",
+		New:      returns("impact", needsAnalysis(impactAnalysis)),
+	},
+	{
+		Name:     "change_impact",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Affected tests for changes, with runner commands and flaky/slow metadata",
+		New:      returns("change_impact", needsAnalysis(changeImpact)),
+	},
+	{
+		Name:     "cross_project_deps",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendPython},
+		Summary:  "Monorepo dependency graph (module, path, plugin, script, and cross-language boundary edges)",
+		New:      returns("cross_project_deps", needsAnalysis(crossProjectDeps)),
+	},
+	{
+		Name:     "detect_patterns",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Architecture pattern detection",
+		New:      returns("detect_patterns", needsAnalysis(detectPatterns)),
+	},
+	{
+		Name:     "live_changes",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendPython},
+		Summary:  "Git-diff with structural annotation",
+		New:      returns("live_changes", needsAnalysis(liveChanges)),
+	},
+	{
+		Name:     "reference_edges",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendPython},
+		Summary:  "Definition tags and cross-file call edges",
+		New:      returns("reference_edges", needsAnalysis(referenceEdges)),
+	},
+	{
+		Name:     "key_symbols",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendGo, BackendPython},
+		Summary:  "PageRank ranking of symbols (call graph) and files (call and import edges)",
+		New:      needsAnalysis(keySymbols),
+	},
+	{
+		Name:     "boundary_suggest",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendGo, BackendPython},
+		Summary:  "Community-detected module boundary suggestions",
+		New:      needsAnalysis(boundarySuggest),
+	},
+	{
+		Name:     "simulate_move",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendGo, BackendPython},
+		Summary:  "What-if file/symbol move or deletion",
+		New:      needsAnalysis(simulateMove),
+	},
+	{
+		Name:     "index_update",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Incremental call graph/index refresh from git diff",
+		New:      returns("index_update", needsAnalysis(indexUpdate)),
+	},
+	{
+		Name:     "workspace_stats",
+		Cluster:  mcpfilter.ClusterStructure,
+		Backends: []Backend{BackendGo},
+		Summary:  "Per-project LOC, tests, deps, recency dashboard",
+		New:      noDeps(workspaceStats),
+	},
+	{
+		Name:     "export_map",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendGo, BackendPython},
+		Summary:  "Workspace graph export (JGF/GraphML)",
+		New:      func(d Deps) server.ServerTool { return exportMap(d.Analysis, d.Coordination) },
+	},
+	{
+		Name:     "annotate_pr",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendGo, BackendPython},
+		Summary:  "Post PR/MR impact summary comment (GitHub/GitLab)",
+		New:      needsAnalysis(annotatePR),
+	},
+	{
+		Name:     "bench_impact",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Benchmarks affected by changed code",
+		New:      returns("bench_impact", needsAnalysis(benchImpact)),
+	},
+	{
+		Name:     "artifact_map",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendGo},
+		Summary:  "Map buildable artifacts (Go binaries, console scripts, npm bins, Docker images) to their source files; list what needs rebuilding",
+		New:      noDeps(artifactMap),
+	},
+	{
+		Name:     "container_map",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendGo},
+		Summary:  "Dockerfile/compose/Kubernetes services linked to projects, with ports and env var names",
+		New:      noDeps(containerMap),
+	},
+	{
+		Name:     "build_targets",
+		Cluster:  mcpfilter.ClusterStructure,
+		Backends: []Backend{BackendGo},
+		Summary:  "Make/Task/just targets per project with commands and deps",
+		New:      noDeps(buildTargets),
+	},
+	{
+		Name:     "ci_map",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendGo},
+		Summary:  "GitHub Actions triggers/path filters per project; stale filters; workflows a diff will run",
+		New:      noDeps(ciMap),
+	},
+	{
+		Name:     "doc_coverage",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Public symbols missing doc comments, ranked by call count",
+		New:      returns("doc_coverage", needsAnalysis(docCoverage)),
+	},
+	{
+		Name:     "message_inventory",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Log/error/CLI help/i18n strings with locations and duplicates",
+		New:      returns("message_inventory", needsAnalysis(messageInventory)),
+	},
+	{
+		Name:     "fetch_result",
+		Cluster:  mcpfilter.ClusterStructure,
+		Backends: []Backend{BackendGo},
+		Summary:  "Page through a result too large to return inline (spilled to `intermap://result/{id}`)",
+		New:      noDeps(fetchResult),
+	},
+	{
+		Name:     "who_touches",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendGo, BackendIntermute, BackendGit},
+		Summary:  "Agents reserving, recent committers, and pending changes for a file or glob",
+		New:      needsCoordination(whoTouches),
+	},
+	{
+		Name:     "coordination_health",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendGo, BackendIntermute},
+		Summary:  "Stale agents and orphaned reservations, with optional release",
+		New:      needsCoordination(coordinationHealth),
+	},
+	{
+		Name:     "agent_timeline",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendPython},
+		Summary:  "Agent assignment and reservation history over a window (opt-in snapshots)",
+		New:      returns("agent_timeline", needsAnalysis(agentTimeline)),
+	},
+	{
+		Name:     "script_map",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendPython},
+		Summary:  "Shell script and Makefile invocation edges to scripts and project binaries",
+		New:      returns("script_map", needsAnalysis(scriptMap)),
+	},
+	{
+		Name:     "infra_map",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendGo},
+		Summary:  "Terraform modules and Helm charts: providers, module deps, deployed projects",
+		New:      noDeps(infraMap),
+	},
+	{
+		Name:     "sbom",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendGo},
+		Summary:  "CycloneDX/SPDX SBOM from manifests and lockfiles",
+		New:      noDeps(sbomTool),
+	},
+	{
+		Name:     "license_check",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendGo},
+		Summary:  "Dependency licenses vs allow/deny policy, with introducing chains",
+		New:      noDeps(licenseCheck),
+	},
+	{
+		Name:     "code_search",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendPython},
+		Summary:  "Regex, literal, or comby-style structural search with project and enclosing symbol per match",
+		New:      returns("code_search", needsAnalysis(codeSearch)),
+	},
+	{
+		Name:     "semantic_search",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendPython},
+		Summary:  "Natural-language code search over embedded symbols (opt-in, needs an embeddings endpoint)",
+		New:      returns("semantic_search", needsAnalysis(semanticSearchTool)),
+	},
+	{
+		Name:     "describe_symbol",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Structural function summary: params, returns, callees, callers, side effects (cached)",
+		New:      returns("describe_symbol", needsAnalysis(describeSymbol)),
+	},
+	{
+		Name:     "effects_analysis",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Functions by capability (fs, network, subprocess, env, db), direct or via call chains",
+		New:      returns("effects_analysis", needsAnalysis(effectsAnalysis)),
+	},
+	{
+		Name:     "taint_paths",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Call paths from untrusted input (HTTP, CLI, env) to exec/SQL/file-write sinks",
+		New:      returns("taint_paths", needsAnalysis(taintPaths)),
+	},
+	{
+		Name:     "error_flow",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Error creation, wrapping, swallowing, and panic sites, ranked by reachability",
+		New:      returns("error_flow", needsAnalysis(errorFlow)),
+	},
+	{
+		Name:     "api_surface",
+		Cluster:  mcpfilter.ClusterStructure,
+		Backends: []Backend{BackendPython},
+		Summary:  "Public API per package with normalized signatures and symbol IDs",
+		New:      returns("api_surface", needsAnalysis(apiSurface)),
+	},
+	{
+		Name:     "consumers",
+		Cluster:  mcpfilter.ClusterStructure,
+		Backends: []Backend{BackendPython},
+		Summary:  "Files in other projects importing a project or package (reverse cross_project_deps)",
+		New:      returns("consumers", needsAnalysis(consumers)),
+	},
+	{
+		Name:     "version_skew",
+		Cluster:  mcpfilter.ClusterStructure,
+		Backends: []Backend{BackendGo},
+		Summary:  "Dependencies pinned at different versions across projects; pins overridden by replace/go.work",
+		New:      noDeps(versionSkew),
+	},
+	{
+		Name:     "codemod_plan",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendPython},
+		Summary:  "Plan a structural rewrite across projects: sites, owners, and dependency-ordered steps, without editing",
+		New:      returns("codemod_plan", needsAnalysis(codemodPlan)),
+	},
+	{
+		Name:     "apply_rename",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Identifier or import path rename with dry-run diff; writes only when safe and INTERMAP_ALLOW_WRITES=1",
+		New:      returns("apply_rename", needsAnalysis(applyRename)),
+	},
+	{
+		Name:     "usage_stats",
+		Cluster:  mcpfilter.ClusterStructure,
+		Backends: []Backend{BackendGo},
+		Summary:  "Per-session analysis cost (CPU, files parsed, bytes returned) and budgets",
+		New:      noDeps(usageStats),
+	},
+	{
+		Name:     "symbol_history",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendGo, BackendGit},
+		Summary:  "When a symbol was introduced, its call sites across refs, and recent commits touching it",
+		New:      noDeps(symbolHistory),
+	},
+	{
+		Name:     "deprecations",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Deprecated symbols with remaining uses across the workspace and a per-project burn-down",
+		New:      returns("deprecations", needsAnalysis(deprecations)),
+	},
+	{
+		Name:     "evaluate_policy",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Change-gating rules (e.g. client changes need tests) checked against the diff and impacted tests",
+		New:      needsAnalysis(evaluatePolicy),
+	},
+	{
+		Name:     "save_selection",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendGo},
+		Summary:  "Save a named set of files and symbols as a handle for later calls",
+		New:      noDeps(saveSelection),
+	},
+	{
+		Name:     "load_selection",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendGo},
+		Summary:  "Load a saved selection, or list them",
+		New:      noDeps(loadSelection),
+	},
+	{
+		Name:     "structure_of_diff",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Symbol outlines of only the files in a diff, with the symbols its hunks touch",
+		New:      returns("structure_of_diff", needsAnalysis(structureOfDiff)),
+	},
+	{
+		Name:     "boundary_map",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendPython},
+		Summary:  "Cross-language call boundaries: cgo, native extensions, subprocess, HTTP and gRPC",
+		New:      returns("boundary_map", needsAnalysis(boundaryMap)),
+	},
+	{
+		Name:     "service_graph",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendPython},
+		Summary:  "Runtime service-to-service graph from HTTP/gRPC clients and endpoint env vars",
+		New:      needsAnalysis(serviceGraph),
+	},
+	{
+		Name:     "toolchain_inventory",
+		Cluster:  mcpfilter.ClusterStructure,
+		Backends: []Backend{BackendGo},
+		Summary:  "Required Go/Python/Node/Rust versions per project, checked against installed toolchains",
+		New:      noDeps(toolchainInventory),
+	},
+	{
+		Name:     "release_impact",
+		Cluster:  mcpfilter.ClusterAnalysis,
+		Backends: []Backend{BackendPython},
+		Summary:  "Before a release: API diff since the last tag, consumers with their pins, and the lines it breaks",
+		New:      needsAnalysis(releaseImpact),
+	},
+	{
+		Name:     "name_collisions",
+		Cluster:  mcpfilter.ClusterNavigation,
+		Backends: []Backend{BackendPython},
+		Summary:  "Exported names defined by more than one project, ranked by import-graph proximity",
+		New:      returns("name_collisions", needsAnalysis(nameCollisions)),
+	},
+}
+
+// Clusters maps each tool name to its cluster, for mcpfilter.Filter.
+func Clusters() map[string]mcpfilter.Cluster {
+	m := make(map[string]mcpfilter.Cluster, len(Specs))
+	for _, s := range Specs {
+		m[s.Name] = s.Cluster
+	}
+	return m
+}
+
+// ToolTable renders Specs as the Markdown tool table in CLAUDE.md.
+func ToolTable() string {
+	var b strings.Builder
+	b.WriteString("| Tool | Source | Description |\n")
+	b.WriteString("|------|--------|-------------|\n")
+	for _, s := range Specs {
+		backends := make([]string, len(s.Backends))
+		for i, be := range s.Backends {
+			backends[i] = string(be)
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s |\n", s.Name, strings.Join(backends, "+"), s.Summary)
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mistakeknot/intermap/internal/mcpfilter"
+)
+
+var updateDocs = flag.Bool("update", false, "rewrite the tool table in CLAUDE.md")
+
+func TestSpecs(t *testing.T) {
+	seen := map[string]bool{}
+	for _, s := range Specs {
+		if seen[s.Name] {
+			t.Errorf("duplicate spec %q", s.Name)
+		}
+		seen[s.Name] = true
+		if s.Cluster == "" || len(s.Backends) == 0 || s.Summary == "" {
+			t.Errorf("%s: incomplete spec %+v", s.Name, s)
+		}
+		if tool := s.New(Deps{}); tool.Tool.Name != s.Name {
+			t.Errorf("spec %q builds tool %q", s.Name, tool.Tool.Name)
+		}
+	}
+	if len(Specs) != 55 {
+		t.Errorf("want 55 tools, got %d", len(Specs))
+	}
+}
+
+func TestSpecProfiles(t *testing.T) {
+	getName := func(s Spec) string { return s.Name }
+	core := mcpfilter.Filter(Specs, getName, mcpfilter.ProfileCore, Clusters(), mcpfilter.ProfileClusters)
+	if len(core) != 34 {
+		t.Errorf("core profile: want 34 tools, got %d", len(core))
+	}
+	minimal := mcpfilter.Filter(Specs, getName, mcpfilter.ProfileMinimal, Clusters(), mcpfilter.ProfileClusters)
+	if len(minimal) != 11 {
+		t.Errorf("minimal profile: want 11 tools, got %d", len(minimal))
+	}
+}
+
+// TestToolTable checks that CLAUDE.md lists Specs. Run with -update to
+// regenerate the table.
+func TestToolTable(t *testing.T) {
+	const path = "../../CLAUDE.md"
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := string(data)
+	start := strings.Index(doc, "| Tool | Source | Description |")
+	if start < 0 {
+		t.Fatal("CLAUDE.md has no tool table")
+	}
+	end := start + strings.Index(doc[start:], "\n\n") + 1
+	want := ToolTable()
+	if doc[start:end] == want {
+		return
+	}
+	if !*updateDocs {
+		t.Fatal("CLAUDE.md tool table is out of date; run go test ./internal/tools -run TestToolTable -update")
+	}
+	if err := os.WriteFile(path, []byte(doc[:start]+want+doc[end:]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/spill"
+)
+
+// spillStore holds results too large to return inline. The zero value
+// disables spilling.
+var spillStore *spill.Store
+
+// SetSpillStore installs the store the spill stage moves oversized results to.
+// Call before RegisterAll.
+func SetSpillStore(s *spill.Store) {
+	spillStore = s
+}
+
+// SpilledResult replaces a tool result larger than the spill threshold.
+type SpilledResult struct {
+	Spilled bool   `json:"spilled"`
+	URI     string `json:"uri"`
+	Bytes   int    `json:"bytes"`
+	Summary any    `json:"summary"`
+	Hint    string `json:"hint"`
+}
+
+// withSpill replaces a JSON result larger than the spill threshold with a
+// SpilledResult pointing at the stored copy. Errors are never spilled.
+func withSpill(t server.ServerTool) server.ServerTool {
+	next := t.Handler
+	t.Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		res, err := next(ctx, req)
+		if err != nil || res == nil || res.IsError || len(res.Content) == 0 {
+			return res, err
+		}
+		text, ok := res.Content[0].(mcp.TextContent)
+		if !ok || !spillStore.Exceeds(len(text.Text)) || !json.Valid([]byte(text.Text)) {
+			return res, err
+		}
+		spilled, err := spillResult([]byte(text.Text))
+		if err != nil {
+			return mcputil.WrapError(fmt.Errorf("spill: %w", err))
+		}
+		res.Content[0] = spilled.Content[0]
+		return res, nil
+	}
+	return t
+}
+
+// spillResult stores an encoded result and returns the summary response.
+func spillResult(data []byte) (*mcp.CallToolResult, error) {
+	id, err := spillStore.Put(data)
+	if err != nil {
+		return nil, err
+	}
+	out, err := json.Marshal(SpilledResult{
+		Spilled: true,
+		URI:     spill.URI(id),
+		Bytes:   len(data),
+		Summary: spill.Summarize(data),
+		Hint:    "Result exceeded the size limit; page through it with fetch_result (use path to page an array by item).",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+func fetchResult() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("fetch_result",
+			mcp.WithDescription("Page through a result that was too large to return inline (spilled: true). Without path, returns raw JSON text by byte offset; with path, pages the array at that dot-separated path by item."),
+			mcp.WithString("uri",
+				mcp.Description("Result URI (intermap://result/{id}) from the spilled response"),
+				mcp.Required(),
+			),
+			mcp.WithString("path",
+				mcp.Description("Dot-separated key/index path into the result, e.g. \"messages\" or \"projects.0.files\""),
+			),
+			mcp.WithNumber("offset",
+				mcp.Description("Byte offset, or item offset when path is set (default 0)"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Bytes to return (default half the spill threshold), or items when path is set (default 100)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			uri := stringOr(args["uri"], "")
+			if uri == "" {
+				return mcputil.ValidationError("uri is required")
+			}
+			if !spillStore.Enabled() {
+				return mcputil.ValidationError("result spilling is disabled (INTERMAP_MAX_RESULT_BYTES=0)")
+			}
+			data, err := spillStore.Get(spill.ParseURI(uri))
+			if err != nil {
+				return mcputil.NotFoundError("%v", err)
+			}
+			path := stringOr(args["path"], "")
+			def := 100
+			if path == "" {
+				def = max(spillStore.Threshold()/2, 1)
+			}
+			limit := intOr(args["limit"], def)
+			if limit <= 0 {
+				return mcputil.ValidationError("limit must be positive")
+			}
+			page, err := spill.Slice(data, path, intOr(args["offset"], 0), limit)
+			if err != nil {
+				return mcputil.ValidationError("%v", err)
+			}
+			page.URI = spill.URI(spill.ParseURI(uri))
+			// Pages are already redacted and bounded, so skip jsonResult to
+			// avoid spilling a page again.
+			out, err := json.Marshal(page)
+			if err != nil {
+				return mcputil.WrapError(fmt.Errorf("marshal: %w", err))
+			}
+			return mcp.NewToolResultText(string(out)), nil
+		},
+	}
+}
+
+// resultResource exposes spilled results as intermap://result/{id}.
+func resultResource() (mcp.ResourceTemplate, server.ResourceTemplateHandlerFunc) {
+	template := mcp.NewResourceTemplate(spill.URIPrefix+"{id}", "Spilled tool result",
+		mcp.WithTemplateDescription("A tool result too large to return inline, as JSON"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+	handler := func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		data, err := spillStore.Get(spill.ParseURI(req.Params.URI))
+		if err != nil {
+			return nil, err
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      req.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		}}, nil
+	}
+	return template, handler
+}
@@ -0,0 +1,167 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/cache"
+	"github.com/mistakeknot/intermap/internal/stats"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+var workspaceStatsCache = cache.New[WorkspaceStatsResult](5*time.Minute, 10)
+
+// WorkspaceTotals aggregates stats across all projects.
+type WorkspaceTotals struct {
+	Files        int            `json:"files"`
+	LOC          int            `json:"loc"`
+	TestLOC      int            `json:"test_loc"`
+	TestRatio    float64        `json:"test_ratio"`
+	Dependencies int            `json:"dependencies"`
+	Languages    map[string]int `json:"languages"`
+}
+
+// add accumulates one project's stats.
+func (t *WorkspaceTotals) add(s stats.ProjectStats) {
+	t.Files += s.Files
+	t.LOC += s.LOC
+	t.TestLOC += s.TestLOC
+	t.Dependencies += s.Dependencies
+	for lang, n := range s.Languages {
+		t.Languages[lang] += n
+	}
+}
+
+func (t *WorkspaceTotals) finish() {
+	if code := t.LOC - t.TestLOC; code > 0 {
+		t.TestRatio = float64(t.TestLOC) / float64(code)
+	}
+}
+
+// GroupStats aggregates the projects in a group and its subgroups, so
+// "platform" includes "platform/services".
+type GroupStats struct {
+	Group    string `json:"group"`
+	Projects int    `json:"projects"`
+	WorkspaceTotals
+}
+
+// WorkspaceStatsResult is the response for the workspace_stats tool.
+type WorkspaceStatsResult struct {
+	Root         string               `json:"root"`
+	ProjectCount int                  `json:"project_count"`
+	Totals       WorkspaceTotals      `json:"totals"`
+	Groups       []GroupStats         `json:"groups"`
+	Stale        []string             `json:"stale"`
+	Untested     []string             `json:"untested"`
+	Projects     []stats.ProjectStats `json:"projects"`
+}
+
+func workspaceStats() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("workspace_stats",
+			mcp.WithDescription("Health dashboard for the workspace: per-project LOC, language breakdown, test-to-code ratio, dependency counts, and last-commit recency, plus totals and stale/untested project lists."),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
+			),
+			mcp.WithNumber("stale_days",
+				mcp.Description("Projects with no commit for this many days are listed as stale (default 90)"),
+			),
+			mcp.WithBoolean("refresh",
+				mcp.Description("Force cache refresh"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root := stringOr(args["root"], "")
+			refresh, _ := args["refresh"].(bool)
+			staleDays := intOr(args["stale_days"], 90)
+
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+
+			cacheKey := fmt.Sprintf("%s|%d", root, staleDays)
+			if !refresh {
+				if cached, ok := workspaceStatsCache.Get(cacheKey, ""); ok {
+					return jsonResult(cached)
+				}
+			}
+
+			projects, err := registry.Scan(root)
+			if err != nil {
+				return mcputil.WrapError(fmt.Errorf("scan: %w", err))
+			}
+
+			result := buildWorkspaceStats(root, projects, staleDays, time.Now())
+			workspaceStatsCache.Put(cacheKey, "", result)
+			return jsonResult(result)
+		},
+	}
+}
+
+func buildWorkspaceStats(root string, projects []registry.Project, staleDays int, now time.Time) WorkspaceStatsResult {
+	result := WorkspaceStatsResult{
+		Root:         root,
+		ProjectCount: len(projects),
+		Totals:       WorkspaceTotals{Languages: make(map[string]int)},
+		Stale:        []string{},
+		Untested:     []string{},
+		Projects:     make([]stats.ProjectStats, 0, len(projects)),
+	}
+	groups := make(map[string]*GroupStats)
+	for _, p := range projects {
+		s := stats.Collect(p.Name, p.Path, p.Group, p.Language, now)
+		result.Projects = append(result.Projects, s)
+
+		result.Totals.add(s)
+		for _, g := range groupAncestors(p.Group) {
+			gs, ok := groups[g]
+			if !ok {
+				gs = &GroupStats{Group: g, WorkspaceTotals: WorkspaceTotals{Languages: make(map[string]int)}}
+				groups[g] = gs
+			}
+			gs.Projects++
+			gs.add(s)
+		}
+		if s.DaysSinceCommit >= staleDays {
+			result.Stale = append(result.Stale, p.Name)
+		}
+		if s.LOC > 0 && s.TestLOC == 0 {
+			result.Untested = append(result.Untested, p.Name)
+		}
+	}
+	result.Totals.finish()
+	result.Groups = make([]GroupStats, 0, len(groups))
+	for _, g := range groups {
+		g.finish()
+		result.Groups = append(result.Groups, *g)
+	}
+	sort.Slice(result.Groups, func(i, j int) bool { return result.Groups[i].Group < result.Groups[j].Group })
+	return result
+}
+
+// groupAncestors returns group and each of its parent groups, outermost
+// first: "a/b/c" gives a, a/b, a/b/c. The root group "" has none.
+func groupAncestors(group string) []string {
+	if group == "" {
+		return nil
+	}
+	parts := strings.Split(group, "/")
+	out := make([]string, len(parts))
+	for i := range parts {
+		out[i] = strings.Join(parts[:i+1], "/")
+	}
+	return out
+}
@@ -0,0 +1,246 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/vcs"
+)
+
+// SymbolCommit is a commit in a symbol's history.
+type SymbolCommit struct {
+	Commit  string `json:"commit"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Subject string `json:"subject"`
+}
+
+// SymbolUsage is how a symbol was used as of one ref.
+type SymbolUsage struct {
+	Ref     string `json:"ref"`
+	Commit  string `json:"commit,omitempty"`
+	Date    string `json:"date,omitempty"`
+	Defined bool   `json:"defined"`
+	// CallSites counts lines calling the symbol by name, outside its
+	// definition; Files counts the files holding them.
+	CallSites int    `json:"call_sites"`
+	Files     int    `json:"files"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SymbolHistoryResult is the response for the symbol_history tool.
+type SymbolHistoryResult struct {
+	Project string `json:"project"`
+	Symbol  string `json:"symbol"`
+	// Name is the identifier searched for: Symbol without qualifiers.
+	Name string `json:"name"`
+	// Introduced is the oldest commit adding a definition of Name.
+	Introduced *SymbolCommit `json:"introduced,omitempty"`
+	// Usage is ordered oldest ref first.
+	Usage []SymbolUsage `json:"usage"`
+	// Trend compares call sites at the first and last refs: growing,
+	// shrinking, or steady.
+	Trend         string         `json:"trend,omitempty"`
+	RecentCommits []SymbolCommit `json:"recent_commits"`
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func symbolHistory() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("symbol_history",
+			mcp.WithDescription("Show a symbol's history from git: the commit that introduced it, its call-site count at each of a series of refs, and recent commits touching it. Context for deciding whether a function is safe to deprecate."),
+			mcp.WithString("project",
+				mcp.Description("Project path (a git working copy)"),
+				mcp.Required(),
+			),
+			mcp.WithString("symbol",
+				mcp.Description("Function, method, or type name; qualifiers (T.M, pkg.F) are dropped"),
+				mcp.Required(),
+			),
+			mcp.WithArray("refs",
+				mcp.Description("Refs to count call sites at, oldest first (default: the latest max_refs tags, then HEAD)"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithNumber("max_refs",
+				mcp.Description("Tags to use when refs is omitted (default 5)"),
+			),
+			mcp.WithNumber("max_commits",
+				mcp.Description("Recent commits to list (default 10)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project := stringOr(args["project"], "")
+			symbol := stringOr(args["symbol"], "")
+			if project == "" || symbol == "" {
+				return mcputil.ValidationError("project and symbol are required")
+			}
+			name := lastSegment(symbol)
+			if !identRe.MatchString(name) {
+				return mcputil.ValidationError("symbol %q does not end in an identifier", symbol)
+			}
+			absProject, err := filepath.Abs(project)
+			if err != nil {
+				return mcputil.WrapError(fmt.Errorf("abs project: %w", err))
+			}
+			if _, err := gitOutput(ctx, absProject, "rev-parse", "--git-dir"); err != nil {
+				return mcputil.ValidationError("symbol_history needs a git working copy: %v", err)
+			}
+
+			refs := stringSlice(args["refs"])
+			for _, ref := range refs {
+				if err := vcs.CheckRef(ref); err != nil {
+					return mcputil.ValidationError("refs: %v", err)
+				}
+			}
+			if len(refs) == 0 {
+				if refs, err = recentTags(ctx, absProject, intOr(args["max_refs"], 5)); err != nil {
+					return mcputil.WrapError(err)
+				}
+				refs = append(refs, "HEAD")
+			}
+
+			result := SymbolHistoryResult{Project: absProject, Symbol: symbol, Name: name}
+			q := regexp.QuoteMeta(name)
+			defGit := `(def|func|function|class|type)[[:space:]]+(\([^)]*\)[[:space:]]*)?` + q + `([^A-Za-z0-9_]|$)`
+			intro, err := symbolCommits(ctx, absProject, defGit, "--reverse")
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			if len(intro) > 0 {
+				result.Introduced = &intro[0]
+			}
+			defRe := regexp.MustCompile(`^\s*(?:async\s+def|def|func|function|class|type)\s+(?:\([^)]*\)\s*)?` + q + `\b`)
+			for _, ref := range refs {
+				result.Usage = append(result.Usage, symbolUsage(ctx, absProject, ref, q, defGit, defRe))
+			}
+			result.Trend = usageTrend(result.Usage)
+			recent, err := symbolCommits(ctx, absProject, `(^|[^A-Za-z0-9_])`+q+`([^A-Za-z0-9_]|$)`,
+				"-n", fmt.Sprint(max(intOr(args["max_commits"], 10), 1)))
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			result.RecentCommits = recent
+			return jsonResult(result)
+		},
+	}
+}
+
+// gitOutput runs git in dir with UTC dates.
+func gitOutput(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "TZ=UTC")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil && stderr.Len() > 0 {
+		err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, err
+}
+
+// recentTags returns the n most recently created tags, oldest first.
+func recentTags(ctx context.Context, project string, n int) ([]string, error) {
+	out, err := gitOutput(ctx, project, "tag", "--sort=-creatordate")
+	if err != nil {
+		return nil, fmt.Errorf("git tag: %w", err)
+	}
+	tags := strings.Fields(string(out))
+	tags = tags[:min(len(tags), max(n, 0))]
+	slices.Reverse(tags)
+	return tags, nil
+}
+
+// symbolCommits lists commits under project that change the number of
+// lines matching the extended regexp pattern, newest first unless extra
+// says otherwise.
+func symbolCommits(ctx context.Context, project, pattern string, extra ...string) ([]SymbolCommit, error) {
+	args := append([]string{"log", "--date=iso-strict-local", "--format=\x1e%H\x1f%an\x1f%cd\x1f%s", "-E", "-G", pattern}, extra...)
+	out, err := gitOutput(ctx, project, append(args, "--", ".")...)
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	var commits []SymbolCommit
+	for _, entry := range strings.Split(string(out), "\x1e") {
+		fields := strings.Split(strings.TrimSpace(entry), "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		commits = append(commits, SymbolCommit{Commit: fields[0], Author: fields[1], Date: fields[2], Subject: fields[3]})
+	}
+	return commits, nil
+}
+
+// symbolUsage counts calls of the quoted name q as of ref. Lines matching
+// defRe are definitions, not calls.
+func symbolUsage(ctx context.Context, project, ref, q, defGit string, defRe *regexp.Regexp) SymbolUsage {
+	u := SymbolUsage{Ref: ref}
+	out, err := gitOutput(ctx, project, "log", "-1", "--date=iso-strict-local", "--format=%H\x1f%cd", "--end-of-options", ref, "--")
+	if err != nil {
+		u.Error = fmt.Sprintf("unknown ref: %v", err)
+		return u
+	}
+	u.Commit, u.Date, _ = strings.Cut(strings.TrimSpace(string(out)), "\x1f")
+
+	if _, err := gitOutput(ctx, project, "grep", "-q", "-I", "-E", defGit, u.Commit, "--", "."); err == nil {
+		u.Defined = true
+	} else if !grepNoMatch(err) {
+		u.Error = fmt.Sprintf("git grep: %v", err)
+		return u
+	}
+
+	out, err = gitOutput(ctx, project, "grep", "-z", "-n", "-I", "-E", `(^|[^A-Za-z0-9_])`+q+`[[:space:]]*\(`, u.Commit, "--", ".")
+	if err != nil && !grepNoMatch(err) {
+		u.Error = fmt.Sprintf("git grep: %v", err)
+		return u
+	}
+	files := map[string]bool{}
+	// Lines are commit:path NUL line NUL text.
+	for _, line := range strings.Split(string(out), "\n") {
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 || defRe.MatchString(parts[2]) {
+			continue
+		}
+		u.CallSites++
+		files[parts[0]] = true
+	}
+	u.Files = len(files)
+	return u
+}
+
+// grepNoMatch reports whether err is git grep's exit status for no match.
+func grepNoMatch(err error) bool {
+	var exit *exec.ExitError
+	return errors.As(err, &exit) && exit.ExitCode() == 1
+}
+
+func usageTrend(usage []SymbolUsage) string {
+	var counts []int
+	for _, u := range usage {
+		if u.Error == "" {
+			counts = append(counts, u.CallSites)
+		}
+	}
+	if len(counts) < 2 {
+		return ""
+	}
+	switch first, last := counts[0], counts[len(counts)-1]; {
+	case last > first:
+		return "growing"
+	case last < first:
+		return "shrinking"
+	}
+	return "steady"
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSymbolHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=ada", "GIT_AUTHOR_EMAIL=ada@example.com",
+			"GIT_COMMITTER_NAME=ada", "GIT_COMMITTER_EMAIL=ada@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	commit := func(msg, file, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, file), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", ".")
+		git("commit", "-q", "-m", msg)
+	}
+	git("init", "-q")
+	commit("initial", "main.go", "package main\n\nfunc main() {}\n")
+	commit("add parse", "parse.go", "package main\n\nfunc parse(s string) string { return s }\n")
+	commit("use parse", "main.go", "package main\n\nfunc main() {\n\tparse(\"a\")\n}\n")
+	git("tag", "v1")
+	commit("use parse more", "cli.go", "package main\n\nfunc run() {\n\tparse(\"b\")\n\tx.parse(\"c\")\n\treparse()\n}\n")
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"project": repo, "symbol": "main.parse"}
+	res, err := symbolHistory().Handler(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got SymbolHistoryResult
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got); err != nil {
+		t.Fatalf("%v: %s", err, res.Content[0].(mcp.TextContent).Text)
+	}
+	if got.Name != "parse" || got.Introduced == nil || got.Introduced.Subject != "add parse" {
+		t.Errorf("introduced = %+v", got.Introduced)
+	}
+	if len(got.Usage) != 2 {
+		t.Fatalf("usage = %+v, want v1 and HEAD", got.Usage)
+	}
+	if u := got.Usage[0]; u.Ref != "v1" || !u.Defined || u.CallSites != 1 || u.Files != 1 {
+		t.Errorf("v1 usage = %+v", u)
+	}
+	if u := got.Usage[1]; u.Ref != "HEAD" || u.CallSites != 3 || u.Files != 2 {
+		t.Errorf("HEAD usage = %+v", u)
+	}
+	if got.Trend != "growing" {
+		t.Errorf("trend = %q", got.Trend)
+	}
+	if len(got.RecentCommits) != 3 || got.RecentCommits[0].Subject != "use parse more" {
+		t.Errorf("recent commits = %+v", got.RecentCommits)
+	}
+
+	req.Params.Arguments = map[string]any{"project": repo, "symbol": "parse", "refs": []any{"nope"}}
+	res, _ = symbolHistory().Handler(context.Background(), req)
+	got = SymbolHistoryResult{}
+	json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got)
+	if len(got.Usage) != 1 || got.Usage[0].Error == "" || got.Trend != "" {
+		t.Errorf("unknown ref usage = %+v", got.Usage)
+	}
+
+	leak := filepath.Join(t.TempDir(), "leak")
+	req.Params.Arguments = map[string]any{"project": repo, "symbol": "parse", "refs": []any{"HEAD", "--output=" + leak}}
+	if res, _ = symbolHistory().Handler(context.Background(), req); !res.IsError {
+		t.Error("refs accepted an option")
+	}
+	if _, err := os.Stat(leak); !os.IsNotExist(err) {
+		t.Errorf("git wrote %s: %v", leak, err)
+	}
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+)
+
+func taintPaths(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("taint_paths",
+			mcp.WithDescription("Find call paths from functions that read untrusted input (HTTP params, CLI args, env) to functions that call sensitive sinks (process/code exec, SQL, file writes). Lightweight and function-level, not a full SAST: flows flag review-worthy code, not confirmed vulnerabilities."),
+			mcp.WithString("project",
+				mcp.Description("Project root path"),
+				mcp.Required(),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (defaults to the detected project language)"),
+			),
+			mcp.WithArray("sources",
+				mcp.Description("Only these source kinds: http, cli, env (default all)"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithArray("sinks",
+				mcp.Description("Only these sink kinds: exec, sql, file_write (default all)"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithArray("files",
+				mcp.Description("Only flows passing through these project-relative files"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithNumber("max_depth",
+				mcp.Description("Maximum calls between source and sink (default 6)"),
+			),
+			mcp.WithNumber("max_results",
+				mcp.Description("Maximum flows to return (default 500)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project, _ := args["project"].(string)
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+
+			pyArgs := map[string]any{
+				"language":    languageOr(args["language"], project),
+				"max_depth":   intOr(args["max_depth"], 6),
+				"max_results": intOr(args["max_results"], 500),
+			}
+			if sources := stringSlice(args["sources"]); len(sources) > 0 {
+				for _, s := range sources {
+					switch s {
+					case "http", "cli", "env":
+					default:
+						return mcputil.ValidationError("unknown source kind %q (want http, cli, or env)", s)
+					}
+				}
+				pyArgs["sources"] = sources
+			}
+			if sinks := stringSlice(args["sinks"]); len(sinks) > 0 {
+				for _, s := range sinks {
+					switch s {
+					case "exec", "sql", "file_write":
+					default:
+						return mcputil.ValidationError("unknown sink kind %q (want exec, sql, or file_write)", s)
+					}
+				}
+				pyArgs["sinks"] = sinks
+			}
+			if files := stringSlice(args["files"]); len(files) > 0 {
+				pyArgs["files"] = files
+			}
+
+			result, err := runCached(ctx, bridge, "taint_paths", "taint_paths", project, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+// addTaintPaths adds the source-to-sink flows that pass through the
+// changed files of a change_impact result.
+func addTaintPaths(ctx context.Context, bridge analysis.Backend, project, language string, result map[string]any) error {
+	changed := stringSlice(result["changed_files"])
+	if len(changed) == 0 {
+		result["taint_paths"] = []any{}
+		return nil
+	}
+	flows, err := runCached(ctx, bridge, "taint_paths", "taint_paths", project, map[string]any{
+		"language": language,
+		"files":    changed,
+	})
+	if err != nil {
+		return err
+	}
+	result["taint_paths"] = flows["flows"]
+	return nil
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/targets"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// ProjectTargets lists the build targets of one project.
+type ProjectTargets struct {
+	Project string           `json:"project"`
+	Path    string           `json:"path"`
+	Targets []targets.Target `json:"targets"`
+}
+
+func buildTargets() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("build_targets",
+			mcp.WithDescription("List Make, Task, and just targets per project with their descriptions, commands, and dependencies — how to build and test a project without guessing."),
+			mcp.WithString("project",
+				mcp.Description("Project path; omit to list every project under root"),
+			),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan when project is omitted (defaults to CWD)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			if project := stringOr(args["project"], ""); project != "" {
+				return jsonResult([]ProjectTargets{{
+					Project: filepath.Base(project),
+					Path:    project,
+					Targets: nonNilTargets(targets.Scan(project)),
+				}})
+			}
+
+			root := stringOr(args["root"], "")
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+			projects, err := registry.Scan(root)
+			if err != nil {
+				return mcputil.WrapError(fmt.Errorf("scan: %w", err))
+			}
+			result := []ProjectTargets{}
+			for _, p := range projects {
+				if ts := targets.Scan(p.Path); len(ts) > 0 {
+					result = append(result, ProjectTargets{Project: p.Name, Path: p.Path, Targets: ts})
+				}
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+func nonNilTargets(ts []targets.Target) []targets.Target {
+	if ts == nil {
+		return []targets.Target{}
+	}
+	return ts
+}
@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/internal/coordination"
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+)
+
+// AgentHistory configures periodic agent_map snapshots. An empty DB
+// disables recording and the agent_timeline tool.
+type AgentHistory struct {
+	DB         string
+	Interval   time.Duration
+	RetainDays int
+}
+
+var agentHistory AgentHistory
+
+// SetAgentHistory enables agent_map snapshot recording. Call before
+// RegisterAll and StartAgentHistory.
+func SetAgentHistory(h AgentHistory) {
+	if h.Interval <= 0 {
+		h.Interval = 5 * time.Minute
+	}
+	agentHistory = h
+}
+
+// StartAgentHistory records an agent_map snapshot now and every configured
+// interval until the returned stop function is called. It does nothing
+// unless history is enabled and intermute is configured.
+func StartAgentHistory(bridge analysis.Backend, c coordination.Provider) (stop func()) {
+	h := agentHistory
+	root, err := workspaceRoot()
+	if h.DB == "" || !c.Available() || err != nil {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(pybridge.WithPriority(context.Background(), pybridge.Background))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(h.Interval)
+		defer ticker.Stop()
+		for {
+			if err := recordAgentSnapshot(ctx, bridge, c, root, h); err != nil && ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "intermap: agent history: %v\n", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func recordAgentSnapshot(ctx context.Context, bridge analysis.Backend, c coordination.Provider, root string, h AgentHistory) error {
+	result, err := buildAgentMap(ctx, c, root, false)
+	if err != nil {
+		return err
+	}
+	if result.AgentsError != "" && len(result.Agents) == 0 {
+		// An unreachable intermute is a gap, not an empty workspace.
+		return fmt.Errorf("skipped snapshot: %s", result.AgentsError)
+	}
+	_, err = bridge.Run(ctx, "agent_history_record", root, map[string]any{
+		"db":          h.DB,
+		"agents":      result.Agents,
+		"retain_days": h.RetainDays,
+	})
+	return err
+}
+
+func agentTimeline(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("agent_timeline",
+			mcp.WithDescription("Show how agent-to-project assignments, tasks, and reservations evolved over a time window, from recorded agent_map snapshots. Requires agent_history in the config."),
+			mcp.WithString("since",
+				mcp.Description("Window start: a duration before now (\"24h\", \"90m\") or an RFC 3339 time (default 24h)"),
+			),
+			mcp.WithString("until",
+				mcp.Description("Window end, in the same forms as since (default now)"),
+			),
+			mcp.WithString("agent",
+				mcp.Description("Only this agent (ID or name)"),
+			),
+			mcp.WithString("project",
+				mcp.Description("Only assignments to this project (name or path)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if agentHistory.DB == "" {
+				return mcputil.ValidationError("agent history is not enabled; set agent_history.db in the intermap config")
+			}
+			args := req.GetArguments()
+			now := time.Now()
+			since, err := parseWindowTime(stringOr(args["since"], "24h"), now)
+			if err != nil {
+				return mcputil.ValidationError("since: %v", err)
+			}
+			until := now
+			if s := stringOr(args["until"], ""); s != "" {
+				if until, err = parseWindowTime(s, now); err != nil {
+					return mcputil.ValidationError("until: %v", err)
+				}
+			}
+			if !since.Before(until) {
+				return mcputil.ValidationError("since must be before until")
+			}
+			root, err := workspaceRoot()
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+
+			pyArgs := map[string]any{
+				"db":    agentHistory.DB,
+				"since": since.UTC().Format(time.RFC3339),
+				"until": until.UTC().Format(time.RFC3339),
+			}
+			if a := stringOr(args["agent"], ""); a != "" {
+				pyArgs["agent"] = a
+			}
+			if p := stringOr(args["project"], ""); p != "" {
+				pyArgs["project"] = p
+			}
+			result, err := bridge.Run(ctx, "agent_timeline", root, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+// parseWindowTime accepts a duration before now or an RFC 3339 time.
+func parseWindowTime(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("duration %q is negative", s)
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a duration nor an RFC 3339 time", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/toolchain"
+	"github.com/mistakeknot/intermap/registry"
+)
+
+// ProjectToolchains is one project's language-version requirements.
+type ProjectToolchains struct {
+	Project      string            `json:"project"`
+	Requirements []toolchain.Check `json:"requirements"`
+	Conflicts    int               `json:"conflicts"`
+}
+
+// ToolchainInventoryResult is the response for the toolchain_inventory tool.
+type ToolchainInventoryResult struct {
+	Root string `json:"root"`
+	// Installed maps each language to the local toolchain version; absent
+	// languages have no toolchain on PATH.
+	Installed map[string]string   `json:"installed"`
+	Projects  []ProjectToolchains `json:"projects"`
+	Conflicts int                 `json:"conflicts"`
+}
+
+func toolchainInventory() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("toolchain_inventory",
+			mcp.WithDescription("Report each workspace project's required language versions — go.mod go/toolchain, Python requires-python and .python-version, Node engines and .nvmrc, Rust edition, rust-version, and rust-toolchain — and flag the ones the locally installed go, python3, node, and rustc do not satisfy."),
+			mcp.WithString("root",
+				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
+			),
+			mcp.WithBoolean("conflicts_only",
+				mcp.Description("Only list requirements that conflict with the installed toolchains or whose toolchain is missing (default false)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			root := stringOr(args["root"], "")
+			if root == "" {
+				var err error
+				root, err = os.Getwd()
+				if err != nil {
+					return mcputil.WrapError(fmt.Errorf("getwd: %w", err))
+				}
+			}
+
+			result, err := ToolchainInventory(root, toolchain.Installed(ctx), boolOr(args["conflicts_only"], false))
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+// ToolchainInventory evaluates the language-version requirements of the
+// projects under root against installed. Projects without requirements
+// are left out.
+func ToolchainInventory(root string, installed map[string]string, conflictsOnly bool) (*ToolchainInventoryResult, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("abs root: %w", err)
+	}
+	projects, err := registry.Scan(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	result := &ToolchainInventoryResult{Root: absRoot, Installed: installed, Projects: []ProjectToolchains{}}
+	for _, p := range projects {
+		pt := ProjectToolchains{Project: p.Name, Requirements: []toolchain.Check{}}
+		for _, c := range toolchain.Evaluate(toolchain.Requirements(p.Path), installed) {
+			bad := c.Status == toolchain.StatusConflict || c.Status == toolchain.StatusMissing
+			if bad {
+				pt.Conflicts++
+			}
+			if bad || !conflictsOnly {
+				pt.Requirements = append(pt.Requirements, c)
+			}
+		}
+		if len(pt.Requirements) > 0 {
+			result.Projects = append(result.Projects, pt)
+			result.Conflicts += pt.Conflicts
+		}
+	}
+	return result, nil
+}
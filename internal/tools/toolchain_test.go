@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestToolchainInventory(t *testing.T) {
+	root := t.TempDir()
+	for rel, content := range map[string]string{
+		"api/.git/HEAD":          "ref: refs/heads/main\n",
+		"api/go.mod":             "module example.com/api\n\ngo 1.24\n",
+		"web/.git/HEAD":          "ref: refs/heads/main\n",
+		"web/package.json":       `{"engines":{"node":">=18"}}`,
+		"docs/.git/HEAD":         "ref: refs/heads/main\n",
+		"worker/.git/HEAD":       "ref: refs/heads/main\n",
+		"worker/pyproject.toml":  "[project]\nrequires-python = \">=3.10\"\n",
+		"worker/.python-version": "3.12\n",
+	} {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	installed := map[string]string{"go": "1.23.4", "python": "3.11.7"}
+
+	result, err := ToolchainInventory(root, installed, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byProject := map[string]ProjectToolchains{}
+	for _, p := range result.Projects {
+		byProject[p.Project] = p
+	}
+	if _, ok := byProject["docs"]; ok || len(byProject) != 3 {
+		t.Errorf("projects = %+v", result.Projects)
+	}
+	if byProject["api"].Conflicts != 1 || byProject["web"].Conflicts != 1 {
+		t.Errorf("api/web conflicts: %+v", result.Projects)
+	}
+	if w := byProject["worker"]; w.Conflicts != 1 || len(w.Requirements) != 2 {
+		t.Errorf("worker: %+v", w)
+	}
+	if result.Conflicts != 3 {
+		t.Errorf("conflicts = %d, want 3", result.Conflicts)
+	}
+
+	result, _ = ToolchainInventory(root, installed, true)
+	for _, p := range result.Projects {
+		if len(p.Requirements) != p.Conflicts {
+			t.Errorf("conflicts_only kept %+v", p)
+		}
+	}
+}
@@ -5,68 +5,146 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
+	"path"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/analysis"
+	"github.com/mistakeknot/intermap/client"
 	"github.com/mistakeknot/intermap/internal/cache"
-	"github.com/mistakeknot/intermap/internal/client"
+	"github.com/mistakeknot/intermap/internal/coordination"
+	"github.com/mistakeknot/intermap/internal/gocalls"
 	"github.com/mistakeknot/intermap/internal/mcpfilter"
 	pybridge "github.com/mistakeknot/intermap/internal/python"
-	"github.com/mistakeknot/intermap/internal/registry"
+	"github.com/mistakeknot/intermap/internal/schema"
+	"github.com/mistakeknot/intermap/internal/vcs"
+	"github.com/mistakeknot/intermap/internal/webhook"
+	"github.com/mistakeknot/intermap/registry"
 )
 
 var projectCache = cache.New[[]registry.Project](5*time.Minute, 10)
+var projectStatsCache = cache.New[[]registry.Project](5*time.Minute, 10)
 var detectPatternsCache = cache.New[map[string]any](5*time.Minute, 10)
 var crossProjectDepsCache = cache.New[map[string]any](5*time.Minute, 10)
 
+// strictSchemas fails calls whose sidecar result does not match the
+// command's schema, instead of logging the mismatch.
+var strictSchemas bool
+
+// SetStrictSchemas sets whether schema mismatches fail the call. Call
+// before RegisterAll.
+func SetStrictSchemas(strict bool) {
+	strictSchemas = strict
+}
+
 // RegisterAll registers MCP tools with the server, filtered by the active profile,
 // and returns the Python bridge for lifecycle management. Caller should defer bridge.Close().
 // Set INTERMAP_TOOL_PROFILE or MCP_TOOL_PROFILE to "core" or "minimal" to reduce
-// the tool surface. Default is "full" (all 9 tools).
-func RegisterAll(s *server.MCPServer, c *client.Client) *pybridge.Bridge {
+// the tool surface. Default is "full" (all tools).
+func RegisterAll(s *server.MCPServer, c coordination.Provider) *pybridge.Bridge {
 	bridge := pybridge.NewBridge(pybridge.DefaultPythonPath())
+	bridge.SetUsageHook(recordBridgeUsage)
+	bridge.SetPriorityWeights(priorities.Weights)
 	profile := mcpfilter.ReadProfile("INTERMAP_TOOL_PROFILE")
 
-	allTools := []server.ServerTool{
-		projectRegistry(),
-		resolveProject(),
-		agentMap(c),
-		codeStructure(bridge),
-		impactAnalysis(bridge),
-		changeImpact(bridge),
-		crossProjectDeps(bridge),
-		detectPatterns(bridge),
-		liveChanges(bridge),
-		referenceEdges(bridge),
-	}
-
-	filtered := mcpfilter.Filter(allTools, func(t server.ServerTool) string {
-		return t.Tool.Name
-	}, profile, mcpfilter.ToolClusters, mcpfilter.ProfileClusters)
+	specs := mcpfilter.Filter(Specs, func(s Spec) string {
+		return s.Name
+	}, profile, Clusters(), mcpfilter.ProfileClusters)
 
+	deps := Deps{Analysis: &schema.Checked{Backend: bridge, Strict: strictSchemas}, Coordination: c}
+	filtered := make([]server.ServerTool, len(specs))
+	for i, spec := range specs {
+		filtered[i] = applyPipeline(spec.New(deps))
+	}
 	s.AddTools(filtered...)
+	if spillStore.Enabled() {
+		s.AddResourceTemplate(resultResource())
+	}
 	return bridge
 }
 
+// scanProjects returns registry.Scan(root), cached until the root's group
+// directories change or the TTL expires.
+func scanProjects(root string, refresh bool) ([]registry.Project, error) {
+	// A failed fingerprint leaves the hash empty, falling back to the TTL
+	// alone.
+	fingerprint, _ := registry.ScanFingerprint(root)
+	if !refresh {
+		if cached, ok := projectCache.Get(root, fingerprint); ok {
+			return cached, nil
+		}
+	}
+	projects, err := registry.Scan(root)
+	if err != nil {
+		return nil, err
+	}
+	projectCache.Put(root, fingerprint, projects)
+	return projects, nil
+}
+
+// projectStats returns projects with Stats filled in, cached per root for
+// the stats TTL since counting reads every file.
+func projectStats(root string, projects []registry.Project, refresh bool) []registry.Project {
+	fingerprint, _ := registry.ScanFingerprint(root)
+	if !refresh {
+		if cached, ok := projectStatsCache.Get(root, fingerprint); ok {
+			return cached
+		}
+	}
+	withStats := registry.WithStats(projects)
+	projectStatsCache.Put(root, fingerprint, withStats)
+	return withStats
+}
+
 func projectRegistry() server.ServerTool {
 	return server.ServerTool{
 		Tool: mcp.NewTool("project_registry",
-			mcp.WithDescription("Scan workspace and list all projects with their language, group, and git branch."),
+			mcp.WithDescription("Scan workspace and list all projects with their language, group, and git branch. Filter by group, language, or name glob and select fields to keep large workspaces cheap."),
 			mcp.WithString("root",
 				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
 			),
 			mcp.WithBoolean("refresh",
 				mcp.Description("Force cache refresh"),
 			),
+			mcp.WithBoolean("include_stats",
+				mcp.Description("Add per-project stats: file counts and LOC per extension, and test file counts (slower; default false)"),
+			),
+			mcp.WithString("group",
+				mcp.Description("Only projects in this group or a group nested below it (e.g. platform matches platform/services)"),
+			),
+			mcp.WithString("language",
+				mcp.Description("Only projects of this language (case-insensitive)"),
+			),
+			mcp.WithString("name_pattern",
+				mcp.Description("Only projects whose name matches this glob (e.g. inter*)"),
+			),
+			mcp.WithArray("fields",
+				mcp.Description("Return only these project fields (name, path, language, group, vcs, git_branch, checkout, stats)"),
+				mcp.WithStringItems(),
+			),
 		),
 		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			args := req.GetArguments()
 			root := stringOr(args["root"], "")
 			refresh, _ := args["refresh"].(bool)
+			filter := projectFilter{
+				group:    strings.Trim(stringOr(args["group"], ""), "/"),
+				language: stringOr(args["language"], ""),
+				pattern:  stringOr(args["name_pattern"], ""),
+			}
+			if _, err := path.Match(filter.pattern, ""); err != nil {
+				return mcputil.ValidationError("name_pattern %q: %v", filter.pattern, err)
+			}
+			fields := stringSlice(args["fields"])
+			for _, f := range fields {
+				if !slices.Contains(projectFields, f) {
+					return mcputil.ValidationError("unknown field %q (want %s)", f, strings.Join(projectFields, ", "))
+				}
+			}
 
 			if root == "" {
 				var err error
@@ -76,24 +154,81 @@ func projectRegistry() server.ServerTool {
 				}
 			}
 
-			cacheKey := root
-			if !refresh {
-				if cached, ok := projectCache.Get(cacheKey, ""); ok {
-					return jsonResult(cached)
-				}
-			}
-
-			projects, err := registry.Scan(root)
+			projects, err := scanProjects(root, refresh)
 			if err != nil {
 				return mcputil.WrapError(fmt.Errorf("scan: %w", err))
 			}
-
-			projectCache.Put(cacheKey, "", projects)
+			scope := ScopeFrom(ctx)
+			projects = scope.filter(projects)
+			if filter.active() || scope != nil {
+				projects = filter.apply(projects)
+				if boolOr(args["include_stats"], false) {
+					// A filtered subset is small; count it directly rather
+					// than filling the per-root cache with a partial list.
+					projects = registry.WithStats(projects)
+				}
+			} else if boolOr(args["include_stats"], false) {
+				projects = projectStats(root, projects, refresh)
+			}
+			if len(fields) > 0 {
+				return jsonResult(selectFields(projects, fields))
+			}
 			return jsonResult(projects)
 		},
 	}
 }
 
+// projectFields are the JSON fields of registry.Project that fields may
+// select.
+var projectFields = []string{"name", "path", "language", "group", "vcs", "git_branch", "checkout", "stats"}
+
+// projectFilter narrows a project list for project_registry.
+type projectFilter struct {
+	group, language, pattern string
+}
+
+func (f projectFilter) active() bool {
+	return f.group != "" || f.language != "" || f.pattern != ""
+}
+
+func (f projectFilter) apply(projects []registry.Project) []registry.Project {
+	out := make([]registry.Project, 0, len(projects))
+	for _, p := range projects {
+		if f.group != "" && p.Group != f.group && !strings.HasPrefix(p.Group, f.group+"/") {
+			continue
+		}
+		if f.language != "" && !strings.EqualFold(p.Language, f.language) {
+			continue
+		}
+		if f.pattern != "" {
+			if ok, _ := path.Match(f.pattern, p.Name); !ok {
+				continue
+			}
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// selectFields reduces each project to the named JSON fields. Fields a
+// project omits when empty (vcs, checkout, stats) stay omitted.
+func selectFields(projects []registry.Project, fields []string) []map[string]any {
+	out := make([]map[string]any, len(projects))
+	for i, p := range projects {
+		data, _ := json.Marshal(p)
+		var all map[string]any
+		json.Unmarshal(data, &all)
+		picked := make(map[string]any, len(fields))
+		for _, f := range fields {
+			if v, ok := all[f]; ok {
+				picked[f] = v
+			}
+		}
+		out[i] = picked
+	}
+	return out
+}
+
 func resolveProject() server.ServerTool {
 	return server.ServerTool{
 		Tool: mcp.NewTool("resolve_project",
@@ -121,14 +256,25 @@ func resolveProject() server.ServerTool {
 
 // AgentOverlay holds the combined agent + project + reservation data.
 type AgentOverlay struct {
-	AgentID      string   `json:"agent_id"`
-	Name         string   `json:"name"`
-	Status       string   `json:"status"`
-	Project      string   `json:"project"`
-	ProjectPath  string   `json:"project_path,omitempty"`
+	AgentID     string `json:"agent_id"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Project     string `json:"project"`
+	ProjectPath string `json:"project_path,omitempty"`
+	// Confidence is high, medium, ambiguous, or none (see matchAgentProject);
+	// MatchedBy names the evidence and Candidates lists the projects that
+	// fit an ambiguous match.
+	Confidence   string   `json:"confidence"`
+	MatchedBy    string   `json:"matched_by,omitempty"`
+	Candidates   []string `json:"candidates,omitempty"`
 	SessionID    string   `json:"session_id,omitempty"`
 	LastSeen     string   `json:"last_seen,omitempty"`
 	Reservations []string `json:"reservations,omitempty"`
+	// CurrentTask and TaskStatus describe the agent's most recently updated
+	// open task; LastMessageAt is its latest message sent or received.
+	CurrentTask   string `json:"current_task,omitempty"`
+	TaskStatus    string `json:"task_status,omitempty"`
+	LastMessageAt string `json:"last_message_at,omitempty"`
 }
 
 // AgentMapResult is the top-level response for the agent_map tool.
@@ -137,15 +283,20 @@ type AgentMapResult struct {
 	AgentsAvailable bool           `json:"agents_available"`
 	AgentsError     string         `json:"agents_error,omitempty"`
 	ProjectCount    int            `json:"project_count"`
+	// AmbiguousCount is the number of agents matched to several projects.
+	AmbiguousCount int `json:"ambiguous_count"`
 }
 
-func agentMap(c *client.Client) server.ServerTool {
+func agentMap(c coordination.Provider) server.ServerTool {
 	return server.ServerTool{
 		Tool: mcp.NewTool("agent_map",
 			mcp.WithDescription("Show which agents are working on which projects and files. Combines project registry, agent list, and file reservations into a unified overlay."),
 			mcp.WithString("root",
 				mcp.Description("Workspace root directory to scan (defaults to CWD)"),
 			),
+			mcp.WithBoolean("scope_reservations",
+				mcp.Description("Fetch reservations per registry project instead of all at once; skips reservations with no project (default false)"),
+			),
 		),
 		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			args := req.GetArguments()
@@ -159,85 +310,102 @@ func agentMap(c *client.Client) server.ServerTool {
 				}
 			}
 
-			// Scan projects from filesystem
-			projects, err := registry.Scan(root)
+			result, err := buildAgentMap(ctx, c, root, boolOr(args["scope_reservations"], false))
 			if err != nil {
-				return mcputil.WrapError(fmt.Errorf("scan: %w", err))
-			}
-
-			// Build project path lookup
-			projectByName := make(map[string]registry.Project)
-			for _, p := range projects {
-				projectByName[p.Name] = p
-			}
-
-			result := AgentMapResult{
-				Agents:          []AgentOverlay{},
-				AgentsAvailable: c.Available(),
-				ProjectCount:    len(projects),
-			}
-
-			if !c.Available() {
-				result.AgentsError = "intermute not configured (INTERMUTE_URL not set)"
-				return jsonResult(result)
+				return mcputil.WrapError(err)
 			}
+			return jsonResult(result)
+		},
+	}
+}
 
-			// Fetch agents from intermute
-			agents, err := c.ListAgents(ctx)
-			if err != nil {
-				result.AgentsError = fmt.Sprintf("intermute unreachable: %v", err)
-				return jsonResult(result)
-			}
+// buildAgentMap assembles the agent overlay for the projects under root.
+// Provider failures are reported in AgentsError rather than as an error.
+// scoped limits the reservation fetch to the registry's projects.
+func buildAgentMap(ctx context.Context, c coordination.Provider, root string, scoped bool) (AgentMapResult, error) {
+	// Scan projects from filesystem
+	projects, err := registry.Scan(root)
+	if err != nil {
+		return AgentMapResult{}, fmt.Errorf("scan: %w", err)
+	}
 
-			// Fetch all reservations
-			reservations, err := c.ListReservations(ctx, "")
-			if err != nil {
-				result.AgentsError = fmt.Sprintf("reservations unavailable: %v", err)
-				// Still return agents without reservation data
-			}
+	result := AgentMapResult{
+		Agents:          []AgentOverlay{},
+		AgentsAvailable: c.Available(),
+		ProjectCount:    len(projects),
+	}
 
-			// Index reservations by agent ID
-			reservationsByAgent := make(map[string][]string)
-			for _, r := range reservations {
-				if r.IsActive {
-					reservationsByAgent[r.AgentID] = append(reservationsByAgent[r.AgentID], r.Pattern)
-				}
-			}
+	if !c.Available() {
+		result.AgentsError = notConfigured(c)
+		return result, nil
+	}
 
-			// Build overlay entries
-			for _, agent := range agents {
-				overlay := AgentOverlay{
-					AgentID:      agent.AgentID,
-					Name:         agent.Name,
-					Status:       agent.Status,
-					Project:      agent.Project,
-					SessionID:    agent.SessionID,
-					LastSeen:     agent.LastSeen,
-					Reservations: reservationsByAgent[agent.AgentID],
-				}
+	snap := fetchCoordination(ctx, c, projects, scoped)
+	if snap.agentsErr != nil {
+		result.AgentsError = fmt.Sprintf("%s unreachable: %v", c.Name(), snap.agentsErr)
+		return result, nil
+	}
+	agents, reservations, tasks, messages := snap.agents, snap.reservations, snap.tasks, snap.messages
+	// Missing reservations, tasks, or messages still return the agents;
+	// only the first failure is reported.
+	switch {
+	case snap.reservationsErr != nil:
+		result.AgentsError = fmt.Sprintf("reservations unavailable: %v", snap.reservationsErr)
+	case snap.tasksErr != nil:
+		result.AgentsError = fmt.Sprintf("tasks unavailable: %v", snap.tasksErr)
+	case snap.messagesErr != nil:
+		result.AgentsError = fmt.Sprintf("messages unavailable: %v", snap.messagesErr)
+	}
+	taskByAgent := currentTasks(tasks)
+	lastMessage := latestMessages(messages)
 
-				// Match agent to project by name or path containment
-				if p, ok := projectByName[agent.Project]; ok {
-					overlay.ProjectPath = p.Path
-				} else {
-					// Try matching by path substring
-					for _, p := range projects {
-						if strings.Contains(p.Path, agent.Project) || strings.Contains(agent.Project, p.Name) {
-							overlay.ProjectPath = p.Path
-							break
-						}
-					}
-				}
+	// Index reservations by agent ID
+	reservationsByAgent := make(map[string][]client.Reservation)
+	for _, r := range reservations {
+		if r.IsActive {
+			reservationsByAgent[r.AgentID] = append(reservationsByAgent[r.AgentID], r)
+		}
+	}
 
-				result.Agents = append(result.Agents, overlay)
-			}
+	// Build overlay entries
+	for _, agent := range agents {
+		held := reservationsByAgent[agent.AgentID]
+		patterns := make([]string, 0, len(held))
+		for _, r := range held {
+			patterns = append(patterns, r.Pattern)
+		}
+		match := matchAgentProject(agent, held, projects)
+		overlay := AgentOverlay{
+			AgentID:      agent.AgentID,
+			Name:         agent.Name,
+			Status:       agent.Status,
+			Project:      agent.Project,
+			Confidence:   match.Confidence,
+			MatchedBy:    match.MatchedBy,
+			Candidates:   match.Candidates,
+			SessionID:    agent.SessionID,
+			LastSeen:     agent.LastSeen,
+			Reservations: patterns,
+		}
+		if match.Project != nil {
+			overlay.ProjectPath = match.Project.Path
+		}
+		if match.Confidence == ConfidenceAmbiguous {
+			result.AmbiguousCount++
+		}
+		if task, ok := taskByAgent[agent.AgentID]; ok {
+			overlay.CurrentTask = task.Title
+			overlay.TaskStatus = task.Status
+		}
+		overlay.LastMessageAt = lastMessage[agent.AgentID]
 
-			return jsonResult(result)
-		},
+		result.Agents = append(result.Agents, overlay)
 	}
+
+	return result, nil
 }
 
-func codeStructure(bridge *pybridge.Bridge) server.ServerTool {
+func codeStructure(bridge analysis.Backend) server.ServerTool {
 	return server.ServerTool{
 		Tool: mcp.NewTool("code_structure",
 			mcp.WithDescription("Analyze code structure of a project — list all functions, classes, and imports."),
@@ -246,11 +414,24 @@ func codeStructure(bridge *pybridge.Bridge) server.ServerTool {
 				mcp.Required(),
 			),
 			mcp.WithString("language",
-				mcp.Description("Programming language (python, typescript, go, rust)"),
+				mcp.Description("Programming language (python, typescript, go, rust). Defaults to the detected project language."),
 			),
 			mcp.WithNumber("max_results",
 				mcp.Description("Maximum number of files to analyze (default 100)"),
 			),
+			mcp.WithBoolean("sample",
+				mcp.Description("Analyze entry points, recently changed and widely imported files first and report coverage (default: automatic above 5000 files)"),
+			),
+			mcp.WithString("goos",
+				mcp.Description(goosDescription),
+			),
+			mcp.WithString("goarch",
+				mcp.Description(goarchDescription),
+			),
+			mcp.WithArray("build_tags",
+				mcp.Description(buildTagsDescription),
+				mcp.WithStringItems(),
+			),
 		),
 		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			args := req.GetArguments()
@@ -260,11 +441,21 @@ func codeStructure(bridge *pybridge.Bridge) server.ServerTool {
 			}
 
 			pyArgs := map[string]any{
-				"language":    stringOr(args["language"], "python"),
+				"language":    languageOr(args["language"], project),
 				"max_results": intOr(args["max_results"], 100),
 			}
+			if sample, ok := args["sample"].(bool); ok {
+				pyArgs["sample"] = sample
+			}
+			if pyArgs["language"] == "go" {
+				bc, err := goBuildContext(args)
+				if err != nil {
+					return mcputil.ValidationError("%v", err)
+				}
+				addGoBuild(pyArgs, bc)
+			}
 
-			result, err := bridge.Run(ctx, "structure", project, pyArgs)
+			result, err := runCached(ctx, bridge, "code_structure", "structure", project, pyArgs)
 			if err != nil {
 				return mcputil.WrapError(err)
 			}
@@ -273,7 +464,7 @@ func codeStructure(bridge *pybridge.Bridge) server.ServerTool {
 	}
 }
 
-func impactAnalysis(bridge *pybridge.Bridge) server.ServerTool {
+func impactAnalysis(bridge analysis.Backend) server.ServerTool {
 	return server.ServerTool{
 		Tool: mcp.NewTool("impact_analysis",
 			mcp.WithDescription("Find all callers of a function (reverse call graph) — useful for understanding what code is affected by changes."),
@@ -282,15 +473,49 @@ func impactAnalysis(bridge *pybridge.Bridge) server.ServerTool {
 				mcp.Required(),
 			),
 			mcp.WithString("target",
-				mcp.Description("Function name to find callers of"),
+				mcp.Description("Function name to find callers of, file:name, or a stable symbol ID (package#name)"),
 				mcp.Required(),
 			),
 			mcp.WithString("language",
-				mcp.Description("Programming language"),
+				mcp.Description("Programming language (defaults to the detected project language)"),
 			),
 			mcp.WithNumber("max_depth",
 				mcp.Description("Maximum call graph traversal depth (default 3)"),
 			),
+			mcp.WithString("precision",
+				mcp.Description("fast (default): heuristic call graph from the sidecar. precise (Go only): sound x/tools call graph (CHA or RTA over SSA), including interface dispatch and method values. typed (Python only): callers found through jedi's type inference, including attribute calls on inferred types"),
+			),
+			mcp.WithString("algorithm",
+				mcp.Description("Call graph algorithm for precise Go analysis: cha (default, every package) or rta (only code reachable from main packages and tests)"),
+			),
+			mcp.WithString("goos",
+				mcp.Description(goosDescription),
+			),
+			mcp.WithString("goarch",
+				mcp.Description(goarchDescription),
+			),
+			mcp.WithArray("build_tags",
+				mcp.Description(buildTagsDescription),
+				mcp.WithStringItems(),
+			),
+			mcp.WithBoolean("include_edges",
+				mcp.Description("Also return the reverse call graph as edges [{caller, callee, line, dynamic}], naming functions file:function (default false)"),
+			),
+			mcp.WithBoolean("include_snippets",
+				mcp.Description("Attach the source around each caller's call site as snippet {line, start, end, text}, saving separate file reads (default false)"),
+			),
+			mcp.WithNumber("snippet_lines",
+				mcp.Description("Lines of context either side of each call site (default 3, max 20)"),
+			),
+			mcp.WithNumber("max_snippet_bytes",
+				mcp.Description("Total snippet text budget; nearer callers are served first and snippets_truncated is set when it runs out (default 32768)"),
+			),
+			mcp.WithString("group_by",
+				mcp.Description("Also list the distinct callers in groups with counts: file, package, or project"),
+			),
+			mcp.WithNumber("group_limit",
+				mcp.Description("Callers listed per group; the rest are counted as collapsed (default 10, 0 for all)"),
+			),
 		),
 		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			args := req.GetArguments()
@@ -300,22 +525,52 @@ func impactAnalysis(bridge *pybridge.Bridge) server.ServerTool {
 				return mcputil.ValidationError("project and target are required")
 			}
 
+			language := languageOr(args["language"], project)
+			maxDepth := intOr(args["max_depth"], 3)
+			if by := stringOr(args["group_by"], ""); by != "" && !slices.Contains(impactGroupings, by) {
+				return mcputil.ValidationError("group_by must be one of %s, got %q", strings.Join(impactGroupings, ", "), by)
+			}
+			bc, err := goBuildContext(args)
+			if err != nil {
+				return mcputil.ValidationError("%v", err)
+			}
+			switch precision := stringOr(args["precision"], "fast"); precision {
+			case "fast":
+			case "precise":
+				if language != "go" {
+					return mcputil.ValidationError("precision %q is only available for go, not %s", precision, language)
+				}
+				res, err := preciseGoImpact(ctx, project, target, maxDepth, stringOr(args["algorithm"], gocalls.CHA), bc)
+				return decorateImpact(res, err, project, language, args)
+			case "typed":
+				if language != "python" {
+					return mcputil.ValidationError("precision %q is only available for python, not %s", precision, language)
+				}
+			default:
+				return mcputil.ValidationError("precision must be fast, precise, or typed, got %q", precision)
+			}
+
 			pyArgs := map[string]any{
 				"target":    target,
-				"language":  stringOr(args["language"], "python"),
-				"max_depth": intOr(args["max_depth"], 3),
+				"language":  language,
+				"max_depth": maxDepth,
+				"precision": stringOr(args["precision"], "fast"),
+			}
+			if language == "go" {
+				addGoBuild(pyArgs, bc)
 			}
 
-			result, err := bridge.Run(ctx, "impact", project, pyArgs)
+			result, err := runCached(ctx, bridge, "impact_analysis", "impact", project, pyArgs)
 			if err != nil {
 				return mcputil.WrapError(err)
 			}
-			return jsonResult(result)
+			res, err := jsonResult(result)
+			return decorateImpact(res, err, project, language, args)
 		},
 	}
 }
 
-func changeImpact(bridge *pybridge.Bridge) server.ServerTool {
+func changeImpact(bridge analysis.Backend) server.ServerTool {
 	return server.ServerTool{
 		Tool: mcp.NewTool("change_impact",
 			mcp.WithDescription("Find which tests to run based on changed files — uses call graph analysis and import tracking."),
@@ -324,7 +579,7 @@ func changeImpact(bridge *pybridge.Bridge) server.ServerTool {
 				mcp.Required(),
 			),
 			mcp.WithString("language",
-				mcp.Description("Programming language"),
+				mcp.Description("Programming language (defaults to the detected project language)"),
 			),
 			mcp.WithBoolean("use_git",
 				mcp.Description("Use git diff to detect changed files"),
@@ -332,6 +587,28 @@ func changeImpact(bridge *pybridge.Bridge) server.ServerTool {
 			mcp.WithString("git_base",
 				mcp.Description("Git ref to diff against (default HEAD~1)"),
 			),
+			mcp.WithString("output",
+				mcp.Description("Also format the selected tests for a runner: pytest (node IDs), gotest (per-package -run regexes), or jest (--runTestsByPath)"),
+			),
+			mcp.WithString("test_history",
+				mcp.Description("JUnit XML file or directory, or JSON ledger, of past test runs; annotates selected tests with average duration and flakiness and returns a suggested run order"),
+			),
+			mcp.WithBoolean("artifacts",
+				mcp.Description("Also list the binaries, scripts, and images that include the changed files (see artifact_map)"),
+			),
+			mcp.WithBoolean("taint",
+				mcp.Description("Also list input-to-sink flows that pass through the changed files (see taint_paths)"),
+			),
+			mcp.WithString("goos",
+				mcp.Description(goosDescription),
+			),
+			mcp.WithString("goarch",
+				mcp.Description(goarchDescription),
+			),
+			mcp.WithArray("build_tags",
+				mcp.Description(buildTagsDescription),
+				mcp.WithStringItems(),
+			),
 		),
 		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			args := req.GetArguments()
@@ -341,12 +618,106 @@ func changeImpact(bridge *pybridge.Bridge) server.ServerTool {
 			}
 
 			pyArgs := map[string]any{
-				"language": stringOr(args["language"], "python"),
+				"language": languageOr(args["language"], project),
 				"use_git":  boolOr(args["use_git"], true),
 				"git_base": stringOr(args["git_base"], "HEAD~1"),
 			}
+			if history := stringOr(args["test_history"], ""); history != "" {
+				pyArgs["test_history"] = history
+			}
+			switch output := stringOr(args["output"], ""); output {
+			case "":
+			case "pytest", "gotest", "jest":
+				pyArgs["output"] = output
+			default:
+				return mcputil.ValidationError("output must be pytest, gotest, or jest")
+			}
+			if pyArgs["language"] == "go" {
+				bc, err := goBuildContext(args)
+				if err != nil {
+					return mcputil.ValidationError("%v", err)
+				}
+				addGoBuild(pyArgs, bc)
+			}
+
+			result, err := runChangeImpact(ctx, bridge, project, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			if boolOr(args["artifacts"], false) {
+				if err := addRebuildArtifacts(project, result); err != nil {
+					return mcputil.WrapError(err)
+				}
+			}
+			if boolOr(args["taint"], false) {
+				if err := addTaintPaths(ctx, bridge, project, pyArgs["language"].(string), result); err != nil {
+					return mcputil.WrapError(err)
+				}
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+// ChangeImpact runs change_impact for the git diff of project against base.
+// An empty language means the detected project language.
+func ChangeImpact(ctx context.Context, bridge analysis.Backend, project, language, base string) (map[string]any, error) {
+	return runChangeImpact(ctx, bridge, project, map[string]any{
+		"language": languageOr(language, project),
+		"use_git":  true,
+		"git_base": base,
+	})
+}
+
+func runChangeImpact(ctx context.Context, bridge analysis.Backend, project string, pyArgs map[string]any) (map[string]any, error) {
+	result, err := bridge.Run(ctx, "change_impact", project, pyArgs)
+	if err != nil {
+		return nil, err
+	}
+	emitEvent(webhook.EventChangeImpact, project, result, changeImpactSummaryKeys)
+	return result, nil
+}
+
+func benchImpact(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("bench_impact",
+			mcp.WithDescription("Find benchmarks (Go Benchmark*, pytest-benchmark tests) that exercise changed code, with ready-to-run benchmark commands."),
+			mcp.WithString("project",
+				mcp.Description("Project path to analyze"),
+				mcp.Required(),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (go or python; defaults to the detected project language)"),
+			),
+			mcp.WithString("git_base",
+				mcp.Description("Git ref to diff against (default HEAD~1)"),
+			),
+			mcp.WithArray("files",
+				mcp.Description("Explicit changed files (project-relative); overrides git diff"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithNumber("max_depth",
+				mcp.Description("Maximum caller depth between changed code and a benchmark (default 5)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project, _ := args["project"].(string)
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+
+			pyArgs := map[string]any{
+				"language":  languageOr(args["language"], project),
+				"use_git":   true,
+				"git_base":  stringOr(args["git_base"], "HEAD~1"),
+				"max_depth": intOr(args["max_depth"], 5),
+			}
+			if files := stringSlice(args["files"]); files != nil {
+				pyArgs["files"] = files
+			}
 
-			result, err := bridge.Run(ctx, "change_impact", project, pyArgs)
+			result, err := bridge.Run(ctx, "bench_impact", project, pyArgs)
 			if err != nil {
 				return mcputil.WrapError(err)
 			}
@@ -355,10 +726,10 @@ func changeImpact(bridge *pybridge.Bridge) server.ServerTool {
 	}
 }
 
-func crossProjectDeps(bridge *pybridge.Bridge) server.ServerTool {
+func crossProjectDeps(bridge analysis.Backend) server.ServerTool {
 	return server.ServerTool{
 		Tool: mcp.NewTool("cross_project_deps",
-			mcp.WithDescription("Map cross-project dependencies in a monorepo — Go module deps, Python path deps, plugin references."),
+			mcp.WithDescription("Map cross-project dependencies in a monorepo — Go module deps, Python path deps, plugin references, scripts or Makefiles invoking another project's scripts or binaries, and cross-language boundaries (cgo, native extensions, subprocess, HTTP, gRPC) reaching another project."),
 			mcp.WithString("root",
 				mcp.Description("Monorepo root directory to scan"),
 				mcp.Required(),
@@ -376,7 +747,7 @@ func crossProjectDeps(bridge *pybridge.Bridge) server.ServerTool {
 			refresh, _ := args["refresh"].(bool)
 
 			cacheKey := root
-			mtimeHash := gitHeadSHA(root)
+			mtimeHash := headRevision(root)
 			if !refresh && mtimeHash != "" {
 				if cached, ok := crossProjectDepsCache.Get(cacheKey, mtimeHash); ok {
 					return jsonResult(cached)
@@ -384,19 +755,24 @@ func crossProjectDeps(bridge *pybridge.Bridge) server.ServerTool {
 			}
 
 			// Pass root as the "project" positional arg to bridge.Run
-			result, err := bridge.Run(ctx, "cross_project_deps", root, map[string]any{})
+			result, err := bridge.Run(ctx, "cross_project_deps", root, map[string]any{"max_depth": registry.MaxDepth()})
 			if err != nil {
 				return mcputil.WrapError(err)
 			}
 			if mtimeHash != "" {
 				crossProjectDepsCache.Put(cacheKey, mtimeHash, result)
 			}
+			if graphSink.Enabled() {
+				if cd, err := decodeCrossProjectDeps(result); err == nil {
+					pushCrossProjectDeps(cd)
+				}
+			}
 			return jsonResult(result)
 		},
 	}
 }
 
-func detectPatterns(bridge *pybridge.Bridge) server.ServerTool {
+func detectPatterns(bridge analysis.Backend) server.ServerTool {
 	return server.ServerTool{
 		Tool: mcp.NewTool("detect_patterns",
 			mcp.WithDescription("Detect architectural patterns: HTTP handlers, MCP tools, middleware, interfaces, CLI commands, plugin structures."),
@@ -423,7 +799,7 @@ func detectPatterns(bridge *pybridge.Bridge) server.ServerTool {
 			}
 
 			cacheKey := project
-			mtimeHash := gitHeadSHA(project)
+			mtimeHash := headRevision(project)
 			if !refresh && mtimeHash != "" {
 				if cached, ok := detectPatternsCache.Get(cacheKey, mtimeHash); ok {
 					return jsonResult(cached)
@@ -442,7 +818,91 @@ func detectPatterns(bridge *pybridge.Bridge) server.ServerTool {
 	}
 }
 
-func liveChanges(bridge *pybridge.Bridge) server.ServerTool {
+func docCoverage(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("doc_coverage",
+			mcp.WithDescription("Report which public functions, methods, classes, and types lack doc comments or docstrings, with per-file coverage and the undocumented symbols ranked by how often they are called."),
+			mcp.WithString("project",
+				mcp.Description("Project path to analyze"),
+				mcp.Required(),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (defaults to the detected project language)"),
+			),
+			mcp.WithNumber("top",
+				mcp.Description("Maximum undocumented symbols to return (default 50)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project, _ := args["project"].(string)
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+
+			result, err := runCached(ctx, bridge, "doc_coverage", "doc_coverage", project, map[string]any{
+				"language": languageOr(args["language"], project),
+				"top":      intOr(args["top"], 50),
+			})
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+func messageInventory(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("message_inventory",
+			mcp.WithDescription("Extract user-facing strings — log messages, error messages, CLI help, i18n keys — with file and line, plus texts duplicated across locations, for consistency reviews and translation work."),
+			mcp.WithString("project",
+				mcp.Description("Project path to analyze"),
+				mcp.Required(),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (defaults to the detected project language)"),
+			),
+			mcp.WithArray("kinds",
+				mcp.Description("Only these kinds: log, error, cli_help, i18n (default all)"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithNumber("max_results",
+				mcp.Description("Maximum messages to return (default 2000)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project, _ := args["project"].(string)
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+
+			pyArgs := map[string]any{
+				"language":    languageOr(args["language"], project),
+				"max_results": intOr(args["max_results"], 2000),
+			}
+			if kinds := stringSlice(args["kinds"]); len(kinds) > 0 {
+				for _, k := range kinds {
+					switch k {
+					case "log", "error", "cli_help", "i18n":
+					default:
+						return mcputil.ValidationError("unknown kind %q (want log, error, cli_help, or i18n)", k)
+					}
+				}
+				pyArgs["kinds"] = kinds
+			}
+
+			result, err := runCached(ctx, bridge, "message_inventory", "message_inventory", project, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+func liveChanges(bridge analysis.Backend) server.ServerTool {
 	return server.ServerTool{
 		Tool: mcp.NewTool("live_changes",
 			mcp.WithDescription("Detect changes since a git baseline and annotate with affected symbols (functions, classes)."),
@@ -476,7 +936,7 @@ func liveChanges(bridge *pybridge.Bridge) server.ServerTool {
 	}
 }
 
-func referenceEdges(bridge *pybridge.Bridge) server.ServerTool {
+func referenceEdges(bridge analysis.Backend) server.ServerTool {
 	return server.ServerTool{
 		Tool: mcp.NewTool("reference_edges",
 			mcp.WithDescription("Extract definition tags and cross-file reference edges for graph construction. Returns definitions (with line numbers) and caller/callee edges suitable for PageRank or call graph analysis."),
@@ -490,6 +950,16 @@ func referenceEdges(bridge *pybridge.Bridge) server.ServerTool {
 			mcp.WithNumber("max_files",
 				mcp.Description("Maximum number of files to scan (default 500)"),
 			),
+			mcp.WithString("goos",
+				mcp.Description(goosDescription),
+			),
+			mcp.WithString("goarch",
+				mcp.Description(goarchDescription),
+			),
+			mcp.WithArray("build_tags",
+				mcp.Description(buildTagsDescription),
+				mcp.WithStringItems(),
+			),
 		),
 		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			args := req.GetArguments()
@@ -502,11 +972,72 @@ func referenceEdges(bridge *pybridge.Bridge) server.ServerTool {
 				"language":  stringOr(args["language"], "auto"),
 				"max_files": intOr(args["max_files"], 500),
 			}
+			if lang := pyArgs["language"]; lang == "go" || lang == "auto" && registry.DetectLanguage(project) == "go" {
+				bc, err := goBuildContext(args)
+				if err != nil {
+					return mcputil.ValidationError("%v", err)
+				}
+				addGoBuild(pyArgs, bc)
+			}
 
-			result, err := bridge.Run(ctx, "reference_edges", project, pyArgs)
+			result, err := runCached(ctx, bridge, "reference_edges", "reference_edges", project, pyArgs)
 			if err != nil {
 				return mcputil.WrapError(err)
 			}
+			if graphSink.Enabled() {
+				if rd, err := decodeRefData(result); err == nil {
+					pushRefData(project, rd)
+				}
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+func indexUpdate(bridge analysis.Backend) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("index_update",
+			mcp.WithDescription("Incrementally refresh the sidecar's call graph and definition index: re-parses only files changed since a git baseline (plus their callers) and patches the stored graph. Falls back to a full rebuild when files changed outside the diff."),
+			mcp.WithString("project",
+				mcp.Description("Project root directory"),
+				mcp.Required(),
+			),
+			mcp.WithString("language",
+				mcp.Description("Programming language (defaults to the detected project language)"),
+			),
+			mcp.WithString("baseline",
+				mcp.Description("Git ref used to find changed files (default HEAD)"),
+			),
+			mcp.WithArray("files",
+				mcp.Description("Explicit project-relative changed files; skips the git diff"),
+				mcp.WithStringItems(),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project, _ := args["project"].(string)
+			if project == "" {
+				return mcputil.ValidationError("project is required")
+			}
+
+			pyArgs := map[string]any{
+				"language": languageOr(args["language"], project),
+				"baseline": stringOr(args["baseline"], "HEAD"),
+			}
+			if files := stringSlice(args["files"]); files != nil {
+				pyArgs["files"] = files
+			}
+			// MtimeHash lets the sidecar skip work when nothing moved and
+			// anchors its drift check.
+			if hash, err := registry.MtimeHash(project); err == nil {
+				pyArgs["mtime_hash"] = hash
+			}
+
+			result, err := bridge.Run(ctx, "index_update", project, pyArgs)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			emitEvent(webhook.EventIndexUpdate, project, result, indexUpdateSummaryKeys)
 			return jsonResult(result)
 		},
 	}
@@ -529,6 +1060,18 @@ func stringOr(v any, def string) string {
 	return def
 }
 
+// languageOr returns the explicit language argument, or the language detected
+// for project. Undetectable projects fall back to "python".
+func languageOr(v any, project string) string {
+	if s, ok := v.(string); ok && s != "" {
+		return s
+	}
+	if lang := registry.DetectLanguage(project); lang != "unknown" {
+		return lang
+	}
+	return "python"
+}
+
 func intOr(v any, def int) int {
 	switch n := v.(type) {
 	case float64:
@@ -543,6 +1086,21 @@ func intOr(v any, def int) int {
 	return def
 }
 
+// stringSlice converts a JSON array argument to a []string, skipping non-strings.
+func stringSlice(v any) []string {
+	items, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
 func boolOr(v any, def bool) bool {
 	if b, ok := v.(bool); ok {
 		return b
@@ -550,12 +1108,16 @@ func boolOr(v any, def bool) bool {
 	return def
 }
 
-// gitHeadSHA returns the HEAD commit SHA for a git repo, or empty string on error.
-func gitHeadSHA(dir string) string {
-	cmd := exec.Command("git", "-C", dir, "rev-parse", "HEAD")
-	out, err := cmd.Output()
+// headRevision returns the current revision of the git, jj, or hg repo
+// containing dir, or empty string on error.
+func headRevision(dir string) string {
+	repo, err := vcs.Find(dir)
+	if err != nil {
+		return ""
+	}
+	rev, err := repo.Revision(context.Background())
 	if err != nil {
 		return ""
 	}
-	return strings.TrimSpace(string(out))
+	return rev
 }
@@ -2,11 +2,27 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/gocalls"
+	"github.com/mistakeknot/intermap/internal/license"
 	pybridge "github.com/mistakeknot/intermap/internal/python"
+	"github.com/mistakeknot/intermap/internal/python/fakesidecar"
+	"github.com/mistakeknot/intermap/internal/redact"
+	"github.com/mistakeknot/intermap/internal/spill"
+	"github.com/mistakeknot/intermap/registry"
 )
 
 func TestStringOr(t *testing.T) {
@@ -30,8 +46,8 @@ func TestStringOr_NonStringTypes(t *testing.T) {
 	}
 }
 
-func TestGitHeadSHA_ReturnsNonEmpty(t *testing.T) {
-	sha := gitHeadSHA(".")
+func TestHeadRevision_ReturnsNonEmpty(t *testing.T) {
+	sha := headRevision(".")
 	if sha == "" {
 		t.Skip("not in a git repo")
 	}
@@ -40,8 +56,8 @@ func TestGitHeadSHA_ReturnsNonEmpty(t *testing.T) {
 	}
 }
 
-func TestGitHeadSHA_InvalidDir(t *testing.T) {
-	sha := gitHeadSHA("/nonexistent/path")
+func TestHeadRevision_InvalidDir(t *testing.T) {
+	sha := headRevision("/nonexistent/path")
 	if sha != "" {
 		t.Errorf("expected empty for invalid dir, got: %s", sha)
 	}
@@ -96,7 +112,7 @@ func BenchmarkDetectPatterns_Warm(b *testing.B) {
 	if err != nil {
 		b.Fatal(err)
 	}
-	sha := gitHeadSHA(".")
+	sha := headRevision(".")
 	detectPatternsCache.Put(".", sha, result)
 
 	b.ResetTimer()
@@ -134,7 +150,7 @@ func BenchmarkCrossProjectDeps_Warm(b *testing.B) {
 	if err != nil {
 		b.Fatal(err)
 	}
-	sha := gitHeadSHA(root)
+	sha := headRevision(root)
 	crossProjectDepsCache.Put(root, sha, result)
 
 	b.ResetTimer()
@@ -144,3 +160,434 @@ func BenchmarkCrossProjectDeps_Warm(b *testing.B) {
 		}
 	}
 }
+
+func TestLanguageOr(t *testing.T) {
+	dir := t.TempDir()
+	if got := languageOr("rust", dir); got != "rust" {
+		t.Errorf("explicit language: expected rust, got %s", got)
+	}
+	if got := languageOr(nil, dir); got != "python" {
+		t.Errorf("undetectable project: expected python, got %s", got)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := languageOr("", dir); got != "go" {
+		t.Errorf("detected language: expected go, got %s", got)
+	}
+}
+
+// resultTool returns a tool whose handler answers with jsonResult(v).
+func resultTool(v any) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("result"),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return jsonResult(v)
+		},
+	}
+}
+
+func TestWithRedaction(t *testing.T) {
+	defer SetRedactor(nil)
+	SetRedactor(redact.New(redact.ModeRelative, "/srv/ws"))
+
+	res, err := withRedaction(resultTool(map[string]any{"path": "/srv/ws/core/api"})).Handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := res.Content[0].(mcp.TextContent).Text
+	if text != `{"path":"core/api"}` {
+		t.Errorf("redacted result = %s", text)
+	}
+
+	res, _ = withRedaction(server.ServerTool{
+		Tool: mcp.NewTool("fails"),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcputil.NotFoundError("no project at /srv/ws/core/gone")
+		},
+	}).Handler(context.Background(), mcp.CallToolRequest{})
+	if text := res.Content[0].(mcp.TextContent).Text; strings.Contains(text, "/srv/ws") {
+		t.Errorf("error not redacted: %s", text)
+	}
+}
+
+func TestWithSpill(t *testing.T) {
+	store := spill.New(64)
+	defer store.Close()
+	defer SetSpillStore(nil)
+	SetSpillStore(store)
+
+	items := make([]int, 100)
+	res, err := withSpill(resultTool(map[string]any{"items": items, "count": 100})).Handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spilled SpilledResult
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &spilled); err != nil {
+		t.Fatal(err)
+	}
+	if !spilled.Spilled || !strings.HasPrefix(spilled.URI, spill.URIPrefix) {
+		t.Fatalf("want spilled result, got %+v", spilled)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"uri": spilled.URI, "path": "items", "limit": float64(40)}
+	res, err = fetchResult().Handler(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var page spill.Page
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 100 || len(page.Items) != 40 || page.NextOffset != 40 {
+		t.Errorf("page = total %d, %d items, next %d", page.Total, len(page.Items), page.NextOffset)
+	}
+
+	res, _ = withSpill(resultTool(map[string]any{"count": 1})).Handler(context.Background(), mcp.CallToolRequest{})
+	if text := res.Content[0].(mcp.TextContent).Text; text != `{"count":1}` {
+		t.Errorf("small result changed: %s", text)
+	}
+}
+
+func TestWithProjectResolution(t *testing.T) {
+	root := t.TempDir()
+	for _, p := range []string{"core/intermap", "core/intermute"} {
+		if err := os.MkdirAll(filepath.Join(root, p, ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("INTERMAP_WORKSPACE_ROOT", root)
+
+	var got string
+	tool := withProjectResolution(server.ServerTool{
+		Tool: mcp.NewTool("probe", mcp.WithString("project")),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			got = stringOr(req.GetArguments()["project"], "")
+			return mcp.NewToolResultText("{}"), nil
+		},
+	})
+	call := func(project string) *mcp.CallToolResult {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]any{"project": project}
+		res, err := tool.Handler(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	res := call("intermapp")
+	if got != filepath.Join(root, "core", "intermap") || len(res.Content) != 2 {
+		t.Errorf("fuzzy: project = %q, content = %v", got, res.Content)
+	}
+
+	got = ""
+	res = call("interm")
+	if !res.IsError || got != "" {
+		t.Fatalf("ambiguous name should fail without calling the tool")
+	}
+	if text := res.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "core/intermap") || !strings.Contains(text, "core/intermute") {
+		t.Errorf("ambiguous error lacks candidates: %s", text)
+	}
+
+	existing := filepath.Join(root, "core", "intermute")
+	res = call(existing)
+	if got != existing || len(res.Content) != 1 {
+		t.Errorf("existing path rewritten: %q", got)
+	}
+
+	write := withProjectResolution(server.ServerTool{
+		Tool: mcp.NewTool("apply_rename", mcp.WithString("project"), mcp.WithBoolean("dry_run")),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			got = stringOr(req.GetArguments()["project"], "")
+			return mcp.NewToolResultText("{}"), nil
+		},
+	})
+	for _, tc := range []struct {
+		project string
+		dryRun  any
+		ok      bool
+	}{
+		{"intermapp", nil, true},
+		{"intermapp", true, true},
+		{"intermapp", false, false},
+		{"intermap", false, true},
+		{"core/intermute", false, true},
+		{"/old/core/intermute", false, true},
+		{"/old/checkout/intermap", true, true},
+		{"/old/checkout/intermap", false, false},
+	} {
+		got = ""
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]any{"project": tc.project}
+		if tc.dryRun != nil {
+			req.Params.Arguments.(map[string]any)["dry_run"] = tc.dryRun
+		}
+		res, err := write.Handler(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.IsError == tc.ok || (got != "") != tc.ok {
+			t.Errorf("apply_rename(%q, dry_run=%v): error = %v, project = %q", tc.project, tc.dryRun, res.IsError, got)
+		}
+		if !tc.ok {
+			if text := res.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "did you mean core/intermap") {
+				t.Errorf("refusal lacks the suggestion: %s", text)
+			}
+		}
+	}
+}
+
+func TestBuildWorkspaceStats_Groups(t *testing.T) {
+	root := t.TempDir()
+	var projects []registry.Project
+	for _, p := range []struct{ group, name, file string }{
+		{"platform/services", "auth", "main.go"},
+		{"platform/services", "billing", "main.go"},
+		{"platform", "web", "app.ts"},
+		{"", "tool", "run.py"},
+	} {
+		dir := filepath.Join(root, p.group, p.name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, p.file), []byte("x := 1\ny := 2\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		projects = append(projects, registry.Project{Name: p.name, Group: p.group, Path: dir})
+	}
+
+	result := buildWorkspaceStats(root, projects, 90, time.Now())
+	got := map[string][2]int{}
+	for _, g := range result.Groups {
+		got[g.Group] = [2]int{g.Projects, g.LOC}
+	}
+	want := map[string][2]int{"platform": {3, 6}, "platform/services": {2, 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groups = %v, want %v", got, want)
+	}
+	if result.Totals.LOC != 8 {
+		t.Errorf("totals LOC = %d, want 8", result.Totals.LOC)
+	}
+}
+
+func TestImpactAnalysis_Precision(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	call := func(args map[string]any) string {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		res, err := impactAnalysis(nil).Handler(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.IsError {
+			t.Fatalf("%v: expected an error result", args)
+		}
+		return res.Content[0].(mcp.TextContent).Text
+	}
+	project := t.TempDir()
+
+	if text := call(map[string]any{"project": project, "target": "f", "language": "python", "precision": "precise"}); !strings.Contains(text, "only available for go") {
+		t.Errorf("precise python: %s", text)
+	}
+	if text := call(map[string]any{"project": project, "target": "f", "precision": "exact"}); !strings.Contains(text, "fast, precise, or typed") {
+		t.Errorf("unknown precision: %s", text)
+	}
+	if text := call(map[string]any{"project": project, "target": "f", "language": "go", "precision": "typed"}); !strings.Contains(text, "only available for python") {
+		t.Errorf("typed go: %s", text)
+	}
+	if text := call(map[string]any{"project": project, "target": "f", "language": "go", "precision": "precise"}); !strings.Contains(text, "precise call graph: load packages") {
+		t.Errorf("without the go command: %s", text)
+	}
+	if text := call(map[string]any{"project": project, "target": "f", "language": "go", "build_tags": []any{"e2e; rm"}}); !strings.Contains(text, "invalid build tag") {
+		t.Errorf("bad build tag: %s", text)
+	}
+}
+
+func TestApplyRename_WritesGated(t *testing.T) {
+	t.Setenv(allowWritesEnv, "")
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"project": t.TempDir(), "old": "a", "new": "b", "dry_run": false}
+	res, err := applyRename(nil).Handler(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := res.Content[0].(mcp.TextContent).Text; !res.IsError || !strings.Contains(text, allowWritesEnv) {
+		t.Errorf("write without %s: %s", allowWritesEnv, text)
+	}
+}
+
+func TestOutput_WritesGated(t *testing.T) {
+	t.Setenv(allowWritesEnv, "")
+	out := filepath.Join(t.TempDir(), "map.json")
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"root": t.TempDir(), "output": out}
+	for _, tool := range []server.ServerTool{exportMap(nil, nil), sbomTool()} {
+		res, err := tool.Handler(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if text := res.Content[0].(mcp.TextContent).Text; !res.IsError || !strings.Contains(text, allowWritesEnv) {
+			t.Errorf("%s output without %s: %s", tool.Tool.Name, allowWritesEnv, text)
+		}
+		if _, err := os.Stat(out); !os.IsNotExist(err) {
+			t.Errorf("%s wrote %s: %v", tool.Tool.Name, out, err)
+		}
+	}
+}
+
+func TestGoBuildContext(t *testing.T) {
+	bc, err := goBuildContext(map[string]any{"goarch": "arm64", "build_tags": []any{"integration", "go1.22"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := gocalls.BuildContext{GOOS: runtime.GOOS, GOARCH: "arm64", Tags: []string{"integration", "go1.22"}}
+	if !reflect.DeepEqual(bc, want) {
+		t.Errorf("goBuildContext = %+v, want %+v", bc, want)
+	}
+	pyArgs := map[string]any{}
+	addGoBuild(pyArgs, gocalls.BuildContext{GOOS: "linux", GOARCH: "amd64"})
+	if got := pyArgs["go_build"]; !reflect.DeepEqual(got, map[string]any{"goos": "linux", "goarch": "amd64", "tags": []string{}}) {
+		t.Errorf("go_build = %v", got)
+	}
+	if _, err := goBuildContext(map[string]any{"goos": "linux amd64"}); err == nil {
+		t.Error("goBuildContext accepted a GOOS with a space")
+	}
+}
+
+func TestCodeStructure_FakeSidecar(t *testing.T) {
+	t.Cleanup(func() { SetResultCache(ResultCache{}) })
+	SetResultCache(ResultCache{TTLs: map[string]time.Duration{"code_structure": 0}})
+	fake := fakesidecar.New(t, fakesidecar.Script{
+		"structure": {{Result: map[string]any{"files": []any{map[string]any{"path": "main.go"}}}}},
+	})
+	project := t.TempDir()
+	res := fakesidecar.CallTool(t, codeStructure(fake.Bridge), map[string]any{
+		"project": project, "language": "go", "goos": "windows", "build_tags": []any{"integration"},
+	})
+	var got map[string]any
+	fakesidecar.DecodeResult(t, res, &got)
+	if files, _ := got["files"].([]any); len(files) != 1 {
+		t.Errorf("result = %v", got)
+	}
+
+	reqs := fake.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("requests = %+v", reqs)
+	}
+	want := map[string]any{"goos": "windows", "goarch": runtime.GOARCH, "tags": []any{"integration"}}
+	if reqs[0].Project != project || reqs[0].Args["max_results"] != float64(100) || !reflect.DeepEqual(reqs[0].Args["go_build"], want) {
+		t.Errorf("request = %+v", reqs[0])
+	}
+}
+
+func TestCheckLicenses(t *testing.T) {
+	root := t.TempDir()
+	app := filepath.Join(root, "app")
+	files := map[string]string{
+		".git/HEAD":    "ref: refs/heads/main\n",
+		"package.json": `{"name": "app", "dependencies": {"web": "1.0.0", "left-pad": "1.3.0"}, "devDependencies": {"gpl-tool": "1.0.0"}}`,
+		"package-lock.json": `{"lockfileVersion": 3, "packages": {
+			"": {"dependencies": {"web": "1.0.0", "left-pad": "1.3.0"}, "devDependencies": {"gpl-tool": "1.0.0"}},
+			"node_modules/web": {"version": "1.0.0", "license": "MIT", "dependencies": {"copyleft": "^2"}},
+			"node_modules/copyleft": {"version": "2.0.0", "license": "GPL-3.0-only"},
+			"node_modules/left-pad": {"version": "1.3.0", "license": "WTFPL"},
+			"node_modules/gpl-tool": {"version": "1.0.0", "license": "GPL-3.0", "dev": true},
+			"node_modules/mystery": {"version": "0.1.0"}
+		}}`,
+	}
+	for rel, content := range files {
+		p := filepath.Join(app, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	policy := license.Policy{Deny: []string{"GPL-3.0"}, Exceptions: []string{"left-pad"}, Unknown: "warn"}
+	result, err := CheckLicenses(root, "app", policy, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Checked != 3 {
+		t.Errorf("checked = %d, want 3 (dev and excepted packages skipped)", result.Checked)
+	}
+	if len(result.Violations) != 1 {
+		t.Fatalf("violations = %+v", result.Violations)
+	}
+	v := result.Violations[0]
+	if v.Package != "copyleft" || v.Reason != "denied" || !reflect.DeepEqual(v.Chain, []string{"web@1.0.0", "copyleft@2.0.0"}) {
+		t.Errorf("violation = %+v", v)
+	}
+	if len(result.Unknown) != 1 || result.Unknown[0].Package != "mystery" {
+		t.Errorf("unknown = %+v", result.Unknown)
+	}
+
+	policy.Unknown = "deny"
+	if result, err = CheckLicenses(root, "", policy, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Violations) != 3 {
+		t.Errorf("violations with dev and unknown denied = %+v", result.Violations)
+	}
+	if _, err := CheckLicenses(root, "nope", policy, false); err == nil {
+		t.Error("CheckLicenses accepted an unknown project")
+	}
+}
+
+func TestProjectRegistry_Filters(t *testing.T) {
+	root := t.TempDir()
+	for p, marker := range map[string]string{
+		"platform/services/auth": "go.mod",
+		"platform/billing":       "go.mod",
+		"platform/web":           "package.json",
+		"tools/intermap":         "go.mod",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, p, ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, p, marker), []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list := func(args map[string]any) []map[string]any {
+		t.Helper()
+		args["root"] = root
+		args["refresh"] = true
+		res := call(t, projectRegistry(), args)
+		if res.IsError {
+			t.Fatalf("%v: %s", args, resultText(res))
+		}
+		var out []map[string]any
+		if err := json.Unmarshal([]byte(resultText(res)), &out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	names := func(ps []map[string]any) []string {
+		var out []string
+		for _, p := range ps {
+			out = append(out, p["name"].(string))
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	if got := names(list(map[string]any{"group": "platform", "language": "GO"})); !slices.Equal(got, []string{"auth", "billing"}) {
+		t.Errorf("group platform, language go = %v", got)
+	}
+	if got := names(list(map[string]any{"name_pattern": "*i*"})); !slices.Equal(got, []string{"billing", "intermap"}) {
+		t.Errorf("name_pattern *i* = %v", got)
+	}
+	got := list(map[string]any{"group": "tools", "fields": []any{"name", "language"}})
+	if len(got) != 1 || len(got[0]) != 2 || got[0]["language"] != "go" {
+		t.Errorf("fields = %v", got)
+	}
+	if res := call(t, projectRegistry(), map[string]any{"root": root, "fields": []any{"owner"}}); !res.IsError {
+		t.Error("unknown field accepted")
+	}
+}
@@ -0,0 +1,234 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+)
+
+// defaultSession names calls that arrive without an MCP client session.
+const defaultSession = "default"
+
+// UsageTotals is the analysis cost attributed to a session or tool.
+type UsageTotals struct {
+	Calls         int     `json:"calls"`
+	CPUSeconds    float64 `json:"cpu_seconds"`
+	FilesParsed   int     `json:"files_parsed"`
+	BytesReturned int     `json:"bytes_returned"`
+}
+
+func (u *UsageTotals) add(o UsageTotals) {
+	u.Calls += o.Calls
+	u.CPUSeconds += o.CPUSeconds
+	u.FilesParsed += o.FilesParsed
+	u.BytesReturned += o.BytesReturned
+}
+
+// Budgets caps each session's usage; zero fields are unlimited. A call is
+// refused once any total has reached its cap, so the call that crosses a
+// cap still completes.
+type Budgets struct {
+	Calls         int     `json:"calls,omitempty"`
+	CPUSeconds    float64 `json:"cpu_seconds,omitempty"`
+	FilesParsed   int     `json:"files_parsed,omitempty"`
+	BytesReturned int     `json:"bytes_returned,omitempty"`
+}
+
+// exceeded describes the first cap u has reached, or returns "".
+func (b Budgets) exceeded(u UsageTotals) string {
+	switch {
+	case b.Calls > 0 && u.Calls >= b.Calls:
+		return fmt.Sprintf("calls %d of %d", u.Calls, b.Calls)
+	case b.CPUSeconds > 0 && u.CPUSeconds >= b.CPUSeconds:
+		return fmt.Sprintf("cpu_seconds %.1f of %g", u.CPUSeconds, b.CPUSeconds)
+	case b.FilesParsed > 0 && u.FilesParsed >= b.FilesParsed:
+		return fmt.Sprintf("files_parsed %d of %d", u.FilesParsed, b.FilesParsed)
+	case b.BytesReturned > 0 && u.BytesReturned >= b.BytesReturned:
+		return fmt.Sprintf("bytes_returned %d of %d", u.BytesReturned, b.BytesReturned)
+	}
+	return ""
+}
+
+// SessionUsage is one session's totals with a per-tool breakdown.
+type SessionUsage struct {
+	Session   string                  `json:"session"`
+	FirstCall time.Time               `json:"first_call"`
+	LastCall  time.Time               `json:"last_call"`
+	Totals    UsageTotals             `json:"totals"`
+	Tools     map[string]*UsageTotals `json:"tools"`
+}
+
+// usageLedger accumulates cost per MCP session.
+type usageLedger struct {
+	mu       sync.Mutex
+	budgets  Budgets
+	sessions map[string]*SessionUsage
+}
+
+var usage = &usageLedger{sessions: map[string]*SessionUsage{}}
+
+// SetBudgets replaces the per-session budgets. Usage recorded so far is
+// kept.
+func SetBudgets(b Budgets) {
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	usage.budgets = b
+}
+
+// record adds cost to session's totals and tool's breakdown.
+func (l *usageLedger) record(session, tool string, cost UsageTotals) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.sessions[session]
+	if !ok {
+		s = &SessionUsage{Session: session, FirstCall: time.Now(), Tools: map[string]*UsageTotals{}}
+		l.sessions[session] = s
+	}
+	s.LastCall = time.Now()
+	s.Totals.add(cost)
+	t, ok := s.Tools[tool]
+	if !ok {
+		t = &UsageTotals{}
+		s.Tools[tool] = t
+	}
+	t.add(cost)
+}
+
+// check returns why session may not make another call, or "".
+func (l *usageLedger) check(session string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.sessions[session]
+	if !ok {
+		return ""
+	}
+	return l.budgets.exceeded(s.Totals)
+}
+
+// snapshot copies session's usage, or returns an empty one.
+func (l *usageLedger) snapshot(session string) SessionUsage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.sessions[session]
+	if !ok {
+		return SessionUsage{Session: session, Tools: map[string]*UsageTotals{}}
+	}
+	out := *s
+	out.Tools = make(map[string]*UsageTotals, len(s.Tools))
+	for name, t := range s.Tools {
+		c := *t
+		out.Tools[name] = &c
+	}
+	return out
+}
+
+func (l *usageLedger) sessionIDs() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Sorted(maps.Keys(l.sessions))
+}
+
+// usageCall identifies the session and tool a bridge command runs for.
+type usageCall struct {
+	session, tool string
+}
+
+type usageCallKey struct{}
+
+// sessionID returns the MCP client session behind ctx.
+func sessionID(ctx context.Context) string {
+	if s := server.ClientSessionFromContext(ctx); s != nil && s.SessionID() != "" {
+		return s.SessionID()
+	}
+	return defaultSession
+}
+
+// recordBridgeUsage is the bridge's usage hook: it charges sidecar CPU time
+// and parsed files to the session and tool that issued the command.
+func recordBridgeUsage(ctx context.Context, command string, u pybridge.Usage) {
+	call, ok := ctx.Value(usageCallKey{}).(usageCall)
+	if !ok {
+		// Background work, e.g. agent history snapshots.
+		call = usageCall{session: defaultSession, tool: command}
+	}
+	usage.record(call.session, call.tool, UsageTotals{CPUSeconds: u.CPUSeconds, FilesParsed: u.FilesParsed})
+}
+
+// withUsage wraps a tool so its calls and returned bytes are charged to
+// the calling session, and calls are refused once the session is over
+// budget. usage_stats is never refused, so an agent can see why.
+func withUsage(t server.ServerTool) server.ServerTool {
+	next := t.Handler
+	name := t.Tool.Name
+	t.Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		session := sessionID(ctx)
+		if name != "usage_stats" {
+			if over := usage.check(session); over != "" {
+				return mcputil.TransientError("session %s is over its analysis budget (%s); see usage_stats", session, over)
+			}
+		}
+		ctx = context.WithValue(ctx, usageCallKey{}, usageCall{session: session, tool: name})
+		res, err := next(ctx, req)
+		cost := UsageTotals{Calls: 1}
+		if res != nil {
+			for _, c := range res.Content {
+				if text, ok := c.(mcp.TextContent); ok {
+					cost.BytesReturned += len(text.Text)
+				}
+			}
+		}
+		usage.record(session, name, cost)
+		return res, err
+	}
+	return t
+}
+
+// UsageStatsResult is the response for the usage_stats tool.
+type UsageStatsResult struct {
+	SessionUsage
+	Budgets Budgets `json:"budgets"`
+	// OverBudget names the cap this session has reached, if any.
+	OverBudget string         `json:"over_budget,omitempty"`
+	Sessions   []SessionUsage `json:"sessions,omitempty"`
+}
+
+func usageStats() server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("usage_stats",
+			mcp.WithDescription("Analysis cost charged to this MCP session: calls, sidecar CPU seconds, files parsed, and bytes returned, in total and per tool, with the configured per-session budgets. Never refused, even over budget."),
+			mcp.WithBoolean("all_sessions",
+				mcp.Description("Also list every session's usage (default false)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			session := sessionID(ctx)
+			usage.mu.Lock()
+			budgets := usage.budgets
+			usage.mu.Unlock()
+
+			result := UsageStatsResult{
+				SessionUsage: usage.snapshot(session),
+				Budgets:      budgets,
+			}
+			result.OverBudget = budgets.exceeded(result.Totals)
+			if boolOr(args["all_sessions"], false) {
+				if s := ScopeFrom(ctx); s != nil {
+					return mcputil.ValidationError("all_sessions is not available to %s", s.Name)
+				}
+				for _, id := range usage.sessionIDs() {
+					result.Sessions = append(result.Sessions, usage.snapshot(id))
+				}
+			}
+			return jsonResult(result)
+		},
+	}
+}
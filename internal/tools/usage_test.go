@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	pybridge "github.com/mistakeknot/intermap/internal/python"
+)
+
+func TestWithUsage_Budgets(t *testing.T) {
+	t.Cleanup(func() {
+		usage = &usageLedger{sessions: map[string]*SessionUsage{}}
+	})
+	usage = &usageLedger{sessions: map[string]*SessionUsage{}}
+	SetBudgets(Budgets{Calls: 2, CPUSeconds: 5})
+
+	tool := withUsage(server.ServerTool{
+		Tool: mcp.NewTool("echo"),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			recordBridgeUsage(ctx, "structure", pybridge.Usage{CPUSeconds: 1.5, FilesParsed: 3})
+			return mcp.NewToolResultText("hello"), nil
+		},
+	})
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if res, _ := tool.Handler(ctx, mcp.CallToolRequest{}); res.IsError {
+			t.Fatalf("call %d refused: %v", i, res.Content)
+		}
+	}
+	res, _ := tool.Handler(ctx, mcp.CallToolRequest{})
+	if !res.IsError || !strings.Contains(res.Content[0].(mcp.TextContent).Text, "calls 2 of 2") {
+		t.Fatalf("third call not refused: %v", res.Content)
+	}
+
+	stats, _ := withUsage(usageStats()).Handler(ctx, mcp.CallToolRequest{})
+	if stats.IsError {
+		t.Fatalf("usage_stats refused over budget: %v", stats.Content)
+	}
+	var got UsageStatsResult
+	if err := json.Unmarshal([]byte(stats.Content[0].(mcp.TextContent).Text), &got); err != nil {
+		t.Fatal(err)
+	}
+	echo := got.Tools["echo"]
+	if got.Session != defaultSession || echo == nil || *echo != (UsageTotals{Calls: 2, CPUSeconds: 3, FilesParsed: 6, BytesReturned: 10}) {
+		t.Errorf("usage = %+v, echo = %+v", got.SessionUsage, echo)
+	}
+	if got.OverBudget != "calls 2 of 2" {
+		t.Errorf("over_budget = %q", got.OverBudget)
+	}
+}
+
+func TestBridgeUsageHook(t *testing.T) {
+	bridge := pybridge.NewBridge(testPythonPath(t))
+	defer bridge.Close()
+	var got pybridge.Usage
+	bridge.SetUsageHook(func(ctx context.Context, command string, u pybridge.Usage) { got = u })
+
+	project := t.TempDir()
+	for _, name := range []string{"a.py", "b.py"} {
+		if err := os.WriteFile(filepath.Join(project, name), []byte("def f():\n    pass\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := bridge.Run(context.Background(), "structure", project, map[string]any{"language": "python"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.FilesParsed != 2 || got.CPUSeconds <= 0 {
+		t.Errorf("usage = %+v", got)
+	}
+}
@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mistakeknot/intermap/internal/coordination"
+	"github.com/mistakeknot/intermap/internal/webhook"
+)
+
+// WatchAlert is a file with uncommitted edits that another agent has
+// reserved.
+type WatchAlert struct {
+	Project string `json:"project"`
+	File    string `json:"file"`
+	// Status is the file's git status ("M", "A", "??"...).
+	Status       string            `json:"status"`
+	Reservations []FileReservation `json:"reservations"`
+}
+
+// Message is a one-line description of the alert.
+func (a WatchAlert) Message() string {
+	var holders []string
+	for _, r := range a.Reservations {
+		name := r.AgentName
+		if name == "" {
+			name = r.AgentID
+		}
+		holders = append(holders, fmt.Sprintf("%s (%s)", name, r.Pattern))
+	}
+	return fmt.Sprintf("%s is reserved by %s", a.File, strings.Join(holders, ", "))
+}
+
+// Watcher reports local edits to files other agents have reserved. Each
+// conflict is reported once, until the file is clean again or its
+// reservations change.
+type Watcher struct {
+	// Projects are the git working copies to watch.
+	Projects []string
+	// Self is the watching agent's ID or name; its own reservations never
+	// alert.
+	Self     string
+	Provider coordination.Provider
+
+	seen map[string]bool
+}
+
+// Check returns conflicts that are new since the previous Check.
+func (w *Watcher) Check(ctx context.Context) ([]WatchAlert, error) {
+	current := map[string]bool{}
+	var alerts []WatchAlert
+	for _, project := range w.Projects {
+		pending, err := projectPending(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+		conflicts, err := ReservationConflicts(ctx, w.Provider, project, w.Self, pending)
+		if err != nil {
+			return nil, err
+		}
+		for _, alert := range conflicts {
+			key := watchKey(alert)
+			current[key] = true
+			if !w.seen[key] {
+				alerts = append(alerts, alert)
+			}
+		}
+	}
+	w.seen = current
+	return alerts, nil
+}
+
+// ReservationConflicts returns the files, keyed by path relative to
+// project with their git status, that agents other than self have
+// reserved, sorted by file.
+func ReservationConflicts(ctx context.Context, c coordination.Provider, project, self string, files map[string]string) ([]WatchAlert, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	held, err := projectReservations(ctx, c, project)
+	if err != nil {
+		return nil, fmt.Errorf("reservations: %w", err)
+	}
+	names := make([]string, 0, len(files))
+	for f := range files {
+		names = append(names, f)
+	}
+	slices.Sort(names)
+	var alerts []WatchAlert
+	for _, f := range names {
+		alert := WatchAlert{Project: project, File: f, Status: files[f]}
+		for _, h := range held {
+			if !isSelf(self, h.FileReservation) && h.re.MatchString(f) {
+				alert.Reservations = append(alert.Reservations, h.FileReservation)
+			}
+		}
+		if len(alert.Reservations) > 0 {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, nil
+}
+
+func isSelf(self string, r FileReservation) bool {
+	return self != "" && (r.AgentID == self || r.AgentName == self)
+}
+
+// Run checks every interval until ctx is done, passing new conflicts to
+// alert and posting them to the reservation_conflict webhook event. A
+// failed check is passed to onErr and retried at the next tick.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, alert func(WatchAlert), onErr func(error)) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		alerts, err := w.Check(ctx)
+		if err != nil && ctx.Err() == nil {
+			onErr(err)
+		}
+		for _, a := range alerts {
+			alert(a)
+			webhooks.Emit(webhook.EventReservationConflict, a.Project, map[string]any{
+				"file":         a.File,
+				"status":       a.Status,
+				"reservations": a.Reservations,
+			})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// watchKey identifies a conflict: the file and who holds it under which
+// patterns.
+func watchKey(a WatchAlert) string {
+	parts := []string{a.Project, a.File}
+	for _, r := range a.Reservations {
+		parts = append(parts, r.AgentID+"="+r.Pattern)
+	}
+	slices.Sort(parts[2:])
+	return strings.Join(parts, "\x00")
+}
+
+// projectPending returns the uncommitted status of files in project, keyed
+// by path relative to project (git reports them relative to the
+// repository root).
+func projectPending(ctx context.Context, project string) (map[string]string, error) {
+	prefix, err := gitOutput(ctx, project, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a git working copy: %w", project, err)
+	}
+	pending, err := pendingChanges(ctx, project, ".")
+	if err != nil {
+		return nil, err
+	}
+	p := strings.TrimSpace(string(prefix))
+	if p == "" {
+		return pending, nil
+	}
+	out := make(map[string]string, len(pending))
+	for f, status := range pending {
+		if rel, ok := strings.CutPrefix(f, p); ok {
+			out[rel] = status
+		}
+	}
+	return out, nil
+}
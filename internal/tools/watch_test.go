@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/mistakeknot/intermap/internal/coordination"
+)
+
+func TestWatcher(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	project := filepath.Join(repo, "svc")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=ada", "GIT_AUTHOR_EMAIL=ada@example.com",
+			"GIT_COMMITTER_NAME=ada", "GIT_COMMITTER_EMAIL=ada@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(path, body string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write(filepath.Join(project, "lib", "x.go"), "package lib\n")
+	write(filepath.Join(project, "main.go"), "package main\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	coord := t.TempDir()
+	write(filepath.Join(coord, "agents", "a1.json"), `{"name": "builder"}`)
+	write(filepath.Join(coord, "reservations", "r1.json"), `{"agent_id": "a1", "pattern": "lib/**"}`)
+	write(filepath.Join(coord, "reservations", "r2.json"), `{"agent_id": "me", "pattern": "main.go"}`)
+	w := &Watcher{Projects: []string{project}, Self: "me", Provider: coordination.NewFile(coord)}
+	check := func(want int) []WatchAlert {
+		t.Helper()
+		alerts, err := w.Check(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(alerts) != want {
+			t.Fatalf("alerts = %+v, want %d", alerts, want)
+		}
+		return alerts
+	}
+
+	check(0)
+	write(filepath.Join(project, "lib", "x.go"), "package lib // edited\n")
+	write(filepath.Join(project, "main.go"), "package main // edited\n")
+	a := check(1)[0]
+	if a.File != "lib/x.go" || a.Status != "M" || len(a.Reservations) != 1 || a.Reservations[0].AgentName != "builder" {
+		t.Errorf("alert = %+v", a)
+	}
+	if got, want := a.Message(), "lib/x.go is reserved by builder (lib/**)"; got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+	// Reported once while the conflict lasts, and again after it clears.
+	check(0)
+	git("checkout", "--", "svc/lib/x.go")
+	check(0)
+	write(filepath.Join(project, "lib", "x.go"), "package lib // again\n")
+	check(1)
+}
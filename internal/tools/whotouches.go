@@ -0,0 +1,430 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mistakeknot/interbase/go/mcputil"
+	"github.com/mistakeknot/intermap/internal/coordination"
+	"github.com/mistakeknot/intermap/internal/workflows"
+)
+
+// FileReservation is an active reservation covering a file.
+type FileReservation struct {
+	AgentID   string `json:"agent_id"`
+	AgentName string `json:"agent_name,omitempty"`
+	Pattern   string `json:"pattern"`
+	Reason    string `json:"reason,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// FileModifier is an author of recent commits to a file.
+type FileModifier struct {
+	Author      string `json:"author"`
+	Commits     int    `json:"commits"`
+	LastCommit  string `json:"last_commit"`
+	LastSubject string `json:"last_subject"`
+}
+
+// FileTouch is everything currently touching one file.
+type FileTouch struct {
+	File         string            `json:"file"`
+	Reservations []FileReservation `json:"reservations,omitempty"`
+	Modifiers    []FileModifier    `json:"modifiers,omitempty"`
+	// Pending is the file's uncommitted git status ("M", "A", "D", "??"...).
+	Pending string `json:"pending,omitempty"`
+	// Conflicts explains why concurrent work on the file may collide.
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+// WhoTouchesResult is the response for the who_touches tool.
+type WhoTouchesResult struct {
+	Project string      `json:"project"`
+	Path    string      `json:"path"`
+	Since   string      `json:"since"`
+	Matched int         `json:"matched"`
+	Files   []FileTouch `json:"files"`
+	// Truncated is set when more than max_files files were touched.
+	Truncated       bool   `json:"truncated,omitempty"`
+	AgentsAvailable bool   `json:"agents_available"`
+	AgentsError     string `json:"agents_error,omitempty"`
+}
+
+func whoTouches(c coordination.Provider) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool("who_touches",
+			mcp.WithDescription("Show who is touching a file or glob: agents holding reservations on it, authors of recent commits, and uncommitted changes, with conflicts flagged. A file-granular complement to agent_map."),
+			mcp.WithString("project",
+				mcp.Description("Project path"),
+				mcp.Required(),
+			),
+			mcp.WithString("path",
+				mcp.Description("Project-relative file, directory, or glob (e.g. \"internal/tools/*.go\")"),
+				mcp.Required(),
+			),
+			mcp.WithString("since",
+				mcp.Description("How far back to look for commits, as a git date (default \"30 days ago\")"),
+			),
+			mcp.WithNumber("max_files",
+				mcp.Description("Maximum number of touched files to report (default 50)"),
+			),
+		),
+		Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			project := stringOr(args["project"], "")
+			path := stringOr(args["path"], "")
+			if project == "" || path == "" {
+				return mcputil.ValidationError("project and path are required")
+			}
+			absProject, err := filepath.Abs(project)
+			if err != nil {
+				return mcputil.WrapError(fmt.Errorf("abs project: %w", err))
+			}
+			if filepath.IsAbs(path) {
+				rel, err := filepath.Rel(absProject, path)
+				if err != nil || strings.HasPrefix(rel, "..") {
+					return mcputil.ValidationError("path %q is outside project %q", path, project)
+				}
+				path = rel
+			}
+			path = filepath.ToSlash(filepath.Clean(path))
+
+			result := WhoTouchesResult{
+				Project:         absProject,
+				Path:            path,
+				Since:           stringOr(args["since"], "30 days ago"),
+				AgentsAvailable: c.Available(),
+			}
+			matches := queryMatcher(path)
+			files, err := matchProjectFiles(absProject, path, matches)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			result.Matched = len(files)
+
+			touches := make(map[string]*FileTouch, len(files))
+			touch := func(f string) *FileTouch {
+				if t, ok := touches[f]; ok {
+					return t
+				}
+				t := &FileTouch{File: f}
+				touches[f] = t
+				return t
+			}
+			if !isGlob(path) && len(files) == 1 && files[0] == path {
+				// A single file is reported even when nothing touches it.
+				touch(path)
+			}
+
+			if c.Available() {
+				held, err := projectReservations(ctx, c, absProject)
+				if err != nil {
+					result.AgentsError = fmt.Sprintf("%s unreachable: %v", c.Name(), err)
+				}
+				for _, f := range files {
+					for _, r := range held {
+						if r.re.MatchString(f) {
+							touch(f).Reservations = append(touch(f).Reservations, r.FileReservation)
+						}
+					}
+				}
+			}
+
+			inSet := make(map[string]bool, len(files))
+			for _, f := range files {
+				inSet[f] = true
+			}
+			modifiers, err := recentModifiers(ctx, absProject, pathspec(path), result.Since)
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			for f, mods := range modifiers {
+				if inSet[f] {
+					touch(f).Modifiers = mods
+				}
+			}
+			pending, err := pendingChanges(ctx, absProject, pathspec(path))
+			if err != nil {
+				return mcputil.WrapError(err)
+			}
+			for f, status := range pending {
+				// Untracked files are not in ls-files but can still match.
+				if inSet[f] || matches(f) {
+					touch(f).Pending = status
+				}
+			}
+
+			for _, t := range touches {
+				t.Conflicts = touchConflicts(t)
+				result.Files = append(result.Files, *t)
+			}
+			// Conflicts first, then most recently active.
+			sort.Slice(result.Files, func(i, j int) bool {
+				a, b := result.Files[i], result.Files[j]
+				if (len(a.Conflicts) > 0) != (len(b.Conflicts) > 0) {
+					return len(a.Conflicts) > 0
+				}
+				if la, lb := lastActivity(a), lastActivity(b); la != lb {
+					return la > lb
+				}
+				return a.File < b.File
+			})
+			if limit := intOr(args["max_files"], 50); limit > 0 && len(result.Files) > limit {
+				result.Files = result.Files[:limit]
+				result.Truncated = true
+			}
+			if result.Files == nil {
+				result.Files = []FileTouch{}
+			}
+			return jsonResult(result)
+		},
+	}
+}
+
+// matchProjectFiles returns the tracked files that path (a file, directory,
+// or glob) refers to. A plain path that is not tracked is still returned so
+// new files can be queried.
+func matchProjectFiles(project, path string, matches func(string) bool) ([]string, error) {
+	all, err := workflows.RepoFiles(project)
+	if err != nil {
+		return nil, fmt.Errorf("list files: %w", err)
+	}
+	var out []string
+	for _, f := range all {
+		if matches(f) {
+			out = append(out, f)
+		}
+	}
+	if len(out) == 0 && !isGlob(path) {
+		out = append(out, path)
+	}
+	return out, nil
+}
+
+// queryMatcher returns a predicate for the files path refers to: path
+// itself, files under it as a directory, or files matched by it as a glob.
+func queryMatcher(path string) func(file string) bool {
+	if isGlob(path) {
+		return globRegexp(path).MatchString
+	}
+	return func(file string) bool {
+		return file == path || path == "." || strings.HasPrefix(file, path+"/")
+	}
+}
+
+func isGlob(p string) bool {
+	return strings.ContainsAny(p, "*?[{")
+}
+
+// pathspec turns a who_touches path into a git pathspec.
+func pathspec(path string) string {
+	if isGlob(path) {
+		return ":(glob)" + path
+	}
+	return path
+}
+
+// heldReservation is a reservation with its pattern compiled relative to the
+// project root.
+type heldReservation struct {
+	FileReservation
+	re *regexp.Regexp
+}
+
+// projectReservations returns the active reservations that can cover files
+// in project: absolute patterns under it, and relative patterns whose
+// reservation names no other project.
+func projectReservations(ctx context.Context, c coordination.Provider, project string) ([]heldReservation, error) {
+	reservations, err := c.ListReservations(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string)
+	if agents, err := c.ListAgents(ctx); err == nil {
+		for _, a := range agents {
+			names[a.AgentID] = a.Name
+		}
+	}
+	base := filepath.Base(project)
+	group := filepath.Base(filepath.Dir(project))
+	var out []heldReservation
+	for _, r := range reservations {
+		if !r.IsActive {
+			continue
+		}
+		pattern := r.Pattern
+		if filepath.IsAbs(pattern) {
+			rel, err := filepath.Rel(project, pattern)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			pattern = filepath.ToSlash(rel)
+		} else if r.Project != "" && r.Project != base && r.Project != group+"/"+base {
+			continue
+		}
+		out = append(out, heldReservation{
+			FileReservation: FileReservation{
+				AgentID:   r.AgentID,
+				AgentName: names[r.AgentID],
+				Pattern:   r.Pattern,
+				Reason:    r.Reason,
+				CreatedAt: r.CreatedAt,
+			},
+			re: globRegexp(pattern),
+		})
+	}
+	return out, nil
+}
+
+// globRegexp compiles a reservation-style glob: `*` and `?` stay within a
+// path segment, `**` crosses segments, and `{a,b}` alternates. A pattern
+// naming a directory also covers everything under it.
+func globRegexp(p string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	depth := 0
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '{':
+			b.WriteString("(?:")
+			depth++
+		case c == '}' && depth > 0:
+			b.WriteString(")")
+			depth--
+		case c == ',' && depth > 0:
+			b.WriteString("|")
+		case c == '[':
+			if j := strings.IndexByte(p[i:], ']'); j > 0 {
+				b.WriteString(p[i : i+j+1])
+				i += j
+			} else {
+				b.WriteString(`\[`)
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("(?:/.*)?$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return regexp.MustCompile("^" + regexp.QuoteMeta(p) + "$")
+	}
+	return re
+}
+
+// recentModifiers returns, per file, the authors of commits since since that
+// touched spec, most recent first.
+func recentModifiers(ctx context.Context, project, spec, since string) (map[string][]FileModifier, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", project, "log",
+		"--since="+since, "--no-merges", "--date=iso-strict-local",
+		"--format=\x1e%an\x1f%cd\x1f%s", "--name-only", "--", spec)
+	// UTC timestamps sort lexically across committers' time zones.
+	cmd.Env = append(os.Environ(), "TZ=UTC")
+	out, err := cmd.Output()
+	if err != nil {
+		if _, statErr := os.Stat(filepath.Join(project, ".git")); statErr != nil {
+			return nil, nil // not a git repo: no history to report
+		}
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	byFile := make(map[string][]FileModifier)
+	for _, entry := range strings.Split(string(out), "\x1e") {
+		header, names, _ := strings.Cut(entry, "\n")
+		fields := strings.Split(header, "\x1f")
+		if len(fields) != 3 {
+			continue
+		}
+		for _, f := range strings.Split(names, "\n") {
+			if f = strings.TrimSpace(f); f == "" {
+				continue
+			}
+			mods := byFile[f]
+			i := 0
+			for i < len(mods) && mods[i].Author != fields[0] {
+				i++
+			}
+			if i == len(mods) {
+				// git log is newest first, so the first commit seen per
+				// author is their latest.
+				byFile[f] = append(mods, FileModifier{Author: fields[0], Commits: 1, LastCommit: fields[1], LastSubject: fields[2]})
+			} else {
+				mods[i].Commits++
+			}
+		}
+	}
+	return byFile, nil
+}
+
+// pendingChanges returns the uncommitted status of files matching spec.
+func pendingChanges(ctx context.Context, project, spec string) (map[string]string, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", project, "status", "--porcelain", "-z", "--untracked-files=all", "--", spec).Output()
+	if err != nil {
+		return nil, nil // not a git repo or git unavailable
+	}
+	pending := make(map[string]string)
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		line := entries[i]
+		if len(line) < 4 {
+			continue
+		}
+		pending[line[3:]] = strings.TrimSpace(line[:2])
+		if line[0] == 'R' || line[0] == 'C' {
+			i++ // skip the original path of a rename or copy
+		}
+	}
+	return pending, nil
+}
+
+// touchConflicts lists the reasons concurrent work on a file may collide.
+func touchConflicts(t *FileTouch) []string {
+	var out []string
+	agents := make(map[string]bool)
+	for _, r := range t.Reservations {
+		agents[r.AgentID] = true
+	}
+	if len(agents) > 1 {
+		ids := make([]string, 0, len(agents))
+		for id := range agents {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		out = append(out, fmt.Sprintf("reserved by %d agents: %s", len(ids), strings.Join(ids, ", ")))
+	}
+	if t.Pending != "" && len(agents) > 0 {
+		out = append(out, fmt.Sprintf("uncommitted changes (%s) in a reserved file; confirm they belong to the reserving agent", t.Pending))
+	}
+	return out
+}
+
+// lastActivity is the latest commit time on a file, or "~" (sorting after
+// any timestamp) for files with uncommitted changes.
+func lastActivity(t FileTouch) string {
+	if t.Pending != "" {
+		return "~"
+	}
+	latest := ""
+	for _, m := range t.Modifiers {
+		latest = max(latest, m.LastCommit)
+	}
+	return latest
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mistakeknot/intermap/client"
+	"github.com/mistakeknot/intermap/internal/coordination"
+)
+
+func TestWhoTouches(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=ada", "GIT_AUTHOR_EMAIL=ada@example.com",
+			"GIT_COMMITTER_NAME=ada", "GIT_COMMITTER_EMAIL=ada@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, body string) {
+		t.Helper()
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write("internal/a.go", "package internal\n")
+	write("internal/b.go", "package internal\n")
+	write("cmd/main.go", "package main\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	write("internal/a.go", "package internal // edited\n")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/agents":
+			json.NewEncoder(w).Encode([]client.Agent{{AgentID: "a1", Name: "builder"}, {AgentID: "a2", Name: "fixer"}})
+		case "/api/reservations":
+			json.NewEncoder(w).Encode([]client.Reservation{
+				{AgentID: "a1", Pattern: "internal/**", IsActive: true},
+				{AgentID: "a2", Pattern: filepath.Join(repo, "internal", "a.go"), IsActive: true},
+				{AgentID: "a2", Pattern: "cmd/*.go", Project: "elsewhere", IsActive: true},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"project": repo, "path": "internal/*.go"}
+	res, err := whoTouches(coordination.NewIntermute(client.NewClient(client.WithBaseURL(ts.URL)))).Handler(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got WhoTouchesResult
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Matched != 2 || len(got.Files) != 2 {
+		t.Fatalf("want 2 matched files, got %+v", got)
+	}
+	a := got.Files[0]
+	if a.File != "internal/a.go" || len(a.Reservations) != 2 || a.Pending != "M" || len(a.Conflicts) != 2 {
+		t.Errorf("internal/a.go: %+v", a)
+	}
+	if len(a.Modifiers) != 1 || a.Modifiers[0].Author != "ada" || a.Modifiers[0].Commits != 1 {
+		t.Errorf("internal/a.go modifiers: %+v", a.Modifiers)
+	}
+	if b := got.Files[1]; b.File != "internal/b.go" || len(b.Reservations) != 1 || len(b.Conflicts) != 0 {
+		t.Errorf("internal/b.go: %+v", b)
+	}
+
+	// cmd/*.go is reserved for another project, so nothing touches cmd/.
+	req.Params.Arguments = map[string]any{"project": repo, "path": "cmd", "since": "1 year ago"}
+	res, _ = whoTouches(coordination.NewIntermute(client.NewClient(client.WithBaseURL(ts.URL)))).Handler(context.Background(), req)
+	got = WhoTouchesResult{}
+	json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got)
+	if len(got.Files) != 1 || len(got.Files[0].Reservations) != 0 {
+		t.Errorf("cmd: %+v", got.Files)
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"internal/*.go", "internal/a.go", true},
+		{"internal/*.go", "internal/x/a.go", false},
+		{"internal/**", "internal/x/a.go", true},
+		{"**/*.go", "a.go", true},
+		{"src/{api,web}/*.ts", "src/web/i.ts", true},
+		{"src/{api,web}/*.ts", "src/cli/i.ts", false},
+		{"internal", "internal/a.go", true},
+		{"a?.go", "ab.go", true},
+	} {
+		if got := globRegexp(tc.pattern).MatchString(tc.file); got != tc.want {
+			t.Errorf("globRegexp(%q) on %q = %v, want %v", tc.pattern, tc.file, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package vcs
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Checkout describes a working copy that does not hold the whole
+// repository. Analyses should expect paths named by history or by other
+// files to be missing locally.
+type Checkout struct {
+	// Sparse is set when only part of the tree is checked out (git
+	// sparse-checkout, the hg sparse extension).
+	Sparse bool `json:"sparse,omitempty"`
+	// PartialClone is set when objects are fetched on demand (git partial
+	// clone, hg narrow clone), so reading old revisions may need the
+	// network.
+	PartialClone bool `json:"partial_clone,omitempty"`
+}
+
+// Complete reports whether the working copy holds the whole repository.
+func (c Checkout) Complete() bool { return !c.Sparse && !c.PartialClone }
+
+// CheckoutOf reads the sparse and partial-clone state of the repository
+// rooted at root from its metadata files. A colocated jj repo reports the
+// state of its git repository.
+func CheckoutOf(root string) Checkout {
+	switch Marker(root) {
+	case Git, Jujutsu:
+		if _, err := os.Stat(filepath.Join(root, ".git")); err != nil {
+			return Checkout{}
+		}
+		return gitCheckout(gitDir(root))
+	case Mercurial:
+		return Checkout{
+			Sparse:       nonEmpty(filepath.Join(root, ".hg", "sparse")),
+			PartialClone: nonEmpty(filepath.Join(root, ".hg", "store", "narrowspec")),
+		}
+	}
+	return Checkout{}
+}
+
+// gitCheckout reads core.sparseCheckout, extensions.partialClone, and
+// remote.*.promisor from the repository config. Linked worktrees keep the
+// config in the common directory and may add a config.worktree.
+func gitCheckout(dir string) Checkout {
+	common := dir
+	if data, err := os.ReadFile(filepath.Join(dir, "commondir")); err == nil {
+		common = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(dir, common)
+		}
+	}
+	cfg := readGitConfig(filepath.Join(common, "config"))
+	for k, v := range readGitConfig(filepath.Join(dir, "config.worktree")) {
+		cfg[k] = v
+	}
+
+	var c Checkout
+	c.Sparse = isTrue(cfg["core.sparsecheckout"]) && nonEmpty(filepath.Join(dir, "info", "sparse-checkout"))
+	if cfg["extensions.partialclone"] != "" {
+		c.PartialClone = true
+	}
+	for k, v := range cfg {
+		if strings.HasPrefix(k, "remote.") && strings.HasSuffix(k, ".promisor") && isTrue(v) {
+			c.PartialClone = true
+		}
+	}
+	return c
+}
+
+// readGitConfig parses the subset of git's config syntax needed here into
+// lowercased "section.subsection.key" names. Includes are not followed.
+func readGitConfig(path string) map[string]string {
+	cfg := map[string]string{}
+	f, err := os.Open(path)
+	if err != nil {
+		return cfg
+	}
+	defer f.Close()
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				continue
+			}
+			// [remote "origin"] -> remote.origin
+			name, sub, _ := strings.Cut(line[1:end], " ")
+			section = strings.ToLower(name)
+			if sub = strings.Trim(strings.TrimSpace(sub), `"`); sub != "" {
+				section += "." + strings.ToLower(sub)
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			value = "true" // a bare key is a true boolean
+		}
+		cfg[section+"."+strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return cfg
+}
+
+func isTrue(v string) bool {
+	switch strings.ToLower(v) {
+	case "true", "yes", "on", "1":
+		return true
+	}
+	return false
+}
+
+func nonEmpty(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Size() > 0
+}
@@ -0,0 +1,76 @@
+package vcs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckoutOf(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("plain/.git/config", "[core]\n\tbare = false\n")
+	write("sparse/.git/config", "[core]\n\tsparseCheckout = true\n")
+	write("sparse/.git/info/sparse-checkout", "/src/\n")
+	write("sparse-off/.git/config", "[core]\n\tsparseCheckout = false\n")
+	write("sparse-off/.git/info/sparse-checkout", "/src/\n")
+	write("partial/.git/config", "[remote \"origin\"]\n\turl = https://example.com/r.git\n\tpromisor = true\n\tpartialclonefilter = blob:none\n")
+	write("partial-ext/.git/config", "[extensions]\n\tpartialClone = origin\n")
+	// A linked worktree: .git file -> gitdir, commondir -> main config.
+	write("main/.git/config", "[extensions]\n\tpartialClone = origin\n")
+	write("main/.git/worktrees/wt/commondir", "../..\n")
+	write("main/.git/worktrees/wt/config.worktree", "[core]\n\tsparseCheckout = true\n")
+	write("main/.git/worktrees/wt/info/sparse-checkout", "/docs/\n")
+	write("wt/.git", "gitdir: "+filepath.Join(root, "main/.git/worktrees/wt")+"\n")
+	write("hg/.hg/sparse", "[include]\nsrc\n")
+	write("narrow/.hg/store/narrowspec", "[include]\npath:src\n")
+
+	for dir, want := range map[string]Checkout{
+		"plain":       {},
+		"sparse":      {Sparse: true},
+		"sparse-off":  {},
+		"partial":     {PartialClone: true},
+		"partial-ext": {PartialClone: true},
+		"wt":          {Sparse: true, PartialClone: true},
+		"hg":          {Sparse: true},
+		"narrow":      {PartialClone: true},
+	} {
+		if got := CheckoutOf(filepath.Join(root, dir)); got != want {
+			t.Errorf("CheckoutOf(%s) = %+v, want %+v", dir, got, want)
+		}
+	}
+	if !(Checkout{}).Complete() || (Checkout{Sparse: true}).Complete() {
+		t.Error("Complete is wrong")
+	}
+}
+
+func TestCheckoutOf_GitSparseCheckout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"sparse-checkout", "set", "--no-cone", "/src/"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if got := CheckoutOf(root); !got.Sparse || got.PartialClone {
+		t.Errorf("CheckoutOf = %+v, want sparse only", got)
+	}
+}
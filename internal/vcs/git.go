@@ -0,0 +1,81 @@
+package vcs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type gitRepo struct{ root string }
+
+func (r *gitRepo) Kind() string { return Git }
+func (r *gitRepo) Root() string { return r.root }
+
+// Branch reads .git/HEAD directly; a .git file (worktree or submodule)
+// points at the real git directory.
+func (r *gitRepo) Branch(ctx context.Context) string {
+	return readGitHead(gitDir(r.root))
+}
+
+func (r *gitRepo) Revision(ctx context.Context) (string, error) {
+	out, err := run(ctx, r.root, "git", "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (r *gitRepo) Dirty(ctx context.Context) (bool, error) {
+	out, err := run(ctx, r.root, "git", "status", "--porcelain", "--untracked-files=normal")
+	if err != nil {
+		return false, err
+	}
+	return len(lines(out)) > 0, nil
+}
+
+func (r *gitRepo) ChangedSince(ctx context.Context, dir, ref string) ([]string, error) {
+	if err := CheckRef(ref); err != nil {
+		return nil, err
+	}
+	out, err := run(ctx, dir, "git", "diff", "--name-only", "--relative", "--end-of-options", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	return lines(out), nil
+}
+
+// gitDir resolves the git directory of the working copy at root.
+func gitDir(root string) string {
+	dotGit := filepath.Join(root, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil || info.IsDir() {
+		return dotGit
+	}
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return dotGit
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return dotGit
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	return target
+}
+
+// readGitHead returns the branch HEAD points at, or the short hash of a
+// detached HEAD.
+func readGitHead(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	if branch, ok := strings.CutPrefix(head, "ref: refs/heads/"); ok {
+		return branch
+	}
+	return shortID(head)
+}
@@ -0,0 +1,68 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type hgRepo struct{ root string }
+
+func (r *hgRepo) Kind() string { return Mercurial }
+func (r *hgRepo) Root() string { return r.root }
+
+// Branch returns the active bookmark, else the named branch. Both are read
+// from .hg, so no hg process is started.
+func (r *hgRepo) Branch(ctx context.Context) string {
+	for _, name := range []string{"bookmarks.current", "branch"} {
+		data, err := os.ReadFile(filepath.Join(r.root, ".hg", name))
+		if err == nil {
+			if branch := strings.TrimSpace(string(data)); branch != "" {
+				return branch
+			}
+		}
+	}
+	return "default"
+}
+
+func (r *hgRepo) Revision(ctx context.Context) (string, error) {
+	out, err := run(ctx, r.root, "hg", "log", "-r", ".", "-T", "{node}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (r *hgRepo) Dirty(ctx context.Context) (bool, error) {
+	out, err := run(ctx, r.root, "hg", "status", "-mardu")
+	if err != nil {
+		return false, err
+	}
+	return len(lines(out)) > 0, nil
+}
+
+// ChangedSince passes "." as a pattern so that hg, like git --relative,
+// limits the status to dir and prints paths relative to it.
+func (r *hgRepo) ChangedSince(ctx context.Context, dir, ref string) ([]string, error) {
+	if err := CheckRef(ref); err != nil {
+		return nil, err
+	}
+	out, err := run(ctx, dir, "hg", "status", "-mard", "-n", "--rev", hgRevision(ref), ".")
+	if err != nil {
+		return nil, err
+	}
+	return lines(out), nil
+}
+
+// hgRevision translates git's HEAD forms to hg revsets.
+func hgRevision(ref string) string {
+	if n, ok := headAncestor(ref); ok {
+		if n == 0 {
+			return "."
+		}
+		return fmt.Sprintf(".~%d", n)
+	}
+	return ref
+}
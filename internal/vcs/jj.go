@@ -0,0 +1,69 @@
+package vcs
+
+import (
+	"context"
+	"strings"
+)
+
+// jjRepo is a Jujutsu working copy. jj has no uncommitted state: edits
+// amend the working-copy commit @, so git's HEAD corresponds to @-.
+type jjRepo struct{ root string }
+
+func (r *jjRepo) Kind() string { return Jujutsu }
+func (r *jjRepo) Root() string { return r.root }
+
+// Branch returns the bookmark nearest to @ among its ancestors, falling
+// back to @'s short change ID. It does not snapshot the working copy.
+func (r *jjRepo) Branch(ctx context.Context) string {
+	out, err := run(ctx, r.root, "jj", "log", "--no-graph", "--ignore-working-copy",
+		"-r", "latest(heads(::@ & bookmarks()))",
+		"-T", `local_bookmarks.map(|b| b.name()).join(",") ++ "\n"`)
+	if err == nil {
+		if names := strings.TrimSpace(string(out)); names != "" {
+			name, _, _ := strings.Cut(names, ",")
+			return name
+		}
+	}
+	out, err = run(ctx, r.root, "jj", "log", "--no-graph", "--ignore-working-copy",
+		"-r", "@", "-T", "change_id.short(8)")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (r *jjRepo) Revision(ctx context.Context) (string, error) {
+	out, err := run(ctx, r.root, "jj", "log", "--no-graph", "-r", "@", "-T", "commit_id")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Dirty reports whether the working-copy commit has changes.
+func (r *jjRepo) Dirty(ctx context.Context) (bool, error) {
+	out, err := run(ctx, r.root, "jj", "diff", "--name-only", "-r", "@")
+	if err != nil {
+		return false, err
+	}
+	return len(lines(out)) > 0, nil
+}
+
+func (r *jjRepo) ChangedSince(ctx context.Context, dir, ref string) ([]string, error) {
+	if err := CheckRef(ref); err != nil {
+		return nil, err
+	}
+	out, err := run(ctx, dir, "jj", "diff", "--name-only", "--from", jjRevision(ref), "--to", "@", ".")
+	if err != nil {
+		return nil, err
+	}
+	return lines(out), nil
+}
+
+// jjRevision translates git's HEAD forms to jj revsets.
+func jjRevision(ref string) string {
+	if n, ok := headAncestor(ref); ok {
+		return "@" + strings.Repeat("-", n+1)
+	}
+	return ref
+}
@@ -0,0 +1,175 @@
+// Package vcs detects the version control system of a project directory and
+// answers the few questions intermap asks of it: the current branch, whether
+// the working copy is dirty, and which files changed since a ref.
+//
+// Git, Jujutsu (jj, including repos colocated with git), and Mercurial (hg)
+// are supported. Branches are read from repository files where the format
+// allows it, so scanning a workspace does not spawn a process per git or hg
+// project; everything else runs the VCS's command-line tool.
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VCS kinds.
+const (
+	Git       = "git"
+	Jujutsu   = "jj"
+	Mercurial = "hg"
+)
+
+// commandTimeout bounds each VCS command.
+const commandTimeout = 10 * time.Second
+
+// Repo is a working copy of one repository.
+type Repo interface {
+	// Kind is Git, Jujutsu, or Mercurial.
+	Kind() string
+	// Root is the absolute working copy root.
+	Root() string
+	// Branch returns the current branch (the nearest bookmark for jj, the
+	// active bookmark or named branch for hg), a short revision ID when
+	// there is none, or "" if it cannot be read.
+	Branch(ctx context.Context) string
+	// Revision returns the ID of the current commit. For jj this is the
+	// working-copy commit, which changes with every edit.
+	Revision(ctx context.Context) (string, error)
+	// Dirty reports whether the working copy has uncommitted changes,
+	// including untracked files.
+	Dirty(ctx context.Context) (bool, error)
+	// ChangedSince returns the files under dir that differ between ref and
+	// the working copy, relative to dir. Refs use the repo's own syntax,
+	// except that git's HEAD, HEAD~N, and HEAD^ work everywhere.
+	ChangedSince(ctx context.Context, dir, ref string) ([]string, error)
+}
+
+// markers lists the metadata directory of each kind in detection order.
+// Jujutsu comes first so that a colocated jj repo, which also has .git, is
+// treated as jj.
+var markers = []struct{ dir, kind string }{
+	{".jj", Jujutsu},
+	{".git", Git},
+	{".hg", Mercurial},
+}
+
+// Marker returns the kind of repository rooted at dir, or "" if dir is not
+// a working copy root.
+func Marker(dir string) string {
+	for _, m := range markers {
+		if _, err := os.Stat(filepath.Join(dir, m.dir)); err == nil {
+			return m.kind
+		}
+	}
+	return ""
+}
+
+// Open returns the repository rooted at dir, or nil if dir is not a working
+// copy root.
+func Open(dir string) Repo {
+	switch Marker(dir) {
+	case Jujutsu:
+		return &jjRepo{root: dir}
+	case Git:
+		return &gitRepo{root: dir}
+	case Mercurial:
+		return &hgRepo{root: dir}
+	}
+	return nil
+}
+
+// Find walks up from path to the nearest working copy root and opens it.
+func Find(path string) (Repo, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("abs path: %w", err)
+	}
+	for dir := abs; ; {
+		if r := Open(dir); r != nil {
+			return r, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("path %q is not within a git, jj, or hg repository", path)
+		}
+		dir = parent
+	}
+}
+
+// CheckRef rejects a ref that a VCS command would parse as an option, such
+// as git's --output=<file>. Every command that takes a caller's ref checks
+// it here first; git commands also pass it after --end-of-options.
+func CheckRef(ref string) error {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid ref %q", ref)
+	}
+	return nil
+}
+
+// run executes a VCS command in dir and returns its stdout.
+func run(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	// Keep hg output stable regardless of user config, and fail instead of
+	// fetching missing objects when a partial clone is offline.
+	cmd.Env = append(os.Environ(), "HGPLAIN=1", "GIT_NO_LAZY_FETCH=1")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s %s: %w: %s", name, args[0], err, msg)
+		}
+		return nil, fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return out, nil
+}
+
+// lines splits command output into its non-empty lines.
+func lines(out []byte) []string {
+	var result []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// headAncestor parses git's HEAD, HEAD^, HEAD~, and HEAD~N into the number
+// of generations above HEAD.
+func headAncestor(ref string) (int, bool) {
+	switch ref {
+	case "HEAD":
+		return 0, true
+	case "HEAD^", "HEAD~":
+		return 1, true
+	}
+	rest, ok := strings.CutPrefix(ref, "HEAD~")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// shortID truncates a revision ID the way git's detached HEAD is shown.
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
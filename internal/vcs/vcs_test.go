@@ -0,0 +1,167 @@
+package vcs
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMarker(t *testing.T) {
+	root := t.TempDir()
+	for dir, markers := range map[string][]string{
+		"plain":     nil,
+		"git":       {".git"},
+		"hg":        {".hg"},
+		"colocated": {".git", ".jj"},
+	} {
+		for _, m := range markers {
+			if err := os.MkdirAll(filepath.Join(root, dir, m), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for dir, want := range map[string]string{"plain": "", "git": Git, "hg": Mercurial, "colocated": Jujutsu} {
+		if got := Marker(filepath.Join(root, dir)); got != want {
+			t.Errorf("Marker(%s) = %q, want %q", dir, got, want)
+		}
+	}
+
+	r, err := Find(filepath.Join(root, "hg", "sub", "dir"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Kind() != Mercurial || r.Root() != filepath.Join(root, "hg") {
+		t.Errorf("Find = %s at %s", r.Kind(), r.Root())
+	}
+	if r, err := Find(filepath.Join(root, "plain")); err == nil {
+		t.Errorf("Find outside a repository = %s at %s", r.Kind(), r.Root())
+	}
+}
+
+func TestRevisionTranslation(t *testing.T) {
+	for _, tc := range []struct{ ref, jj, hg string }{
+		{"HEAD", "@-", "."},
+		{"HEAD~1", "@--", ".~1"},
+		{"HEAD^", "@--", ".~1"},
+		{"HEAD~3", "@----", ".~3"},
+		{"main", "main", "main"},
+		{"HEAD~x", "HEAD~x", "HEAD~x"},
+	} {
+		if got := jjRevision(tc.ref); got != tc.jj {
+			t.Errorf("jjRevision(%q) = %q, want %q", tc.ref, got, tc.jj)
+		}
+		if got := hgRevision(tc.ref); got != tc.hg {
+			t.Errorf("hgRevision(%q) = %q, want %q", tc.ref, got, tc.hg)
+		}
+	}
+}
+
+func TestHgBranch(t *testing.T) {
+	root := t.TempDir()
+	hg := filepath.Join(root, ".hg")
+	if err := os.Mkdir(hg, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	r := Open(root)
+	ctx := context.Background()
+	if got := r.Branch(ctx); got != "default" {
+		t.Errorf("Branch = %q, want default", got)
+	}
+	os.WriteFile(filepath.Join(hg, "branch"), []byte("stable\n"), 0o644)
+	if got := r.Branch(ctx); got != "stable" {
+		t.Errorf("Branch = %q, want stable", got)
+	}
+	os.WriteFile(filepath.Join(hg, "bookmarks.current"), []byte("feature"), 0o644)
+	if got := r.Branch(ctx); got != "feature" {
+		t.Errorf("Branch = %q, want feature", got)
+	}
+}
+
+func TestGitRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(root, rel)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q", "-b", "trunk")
+	write("a.go", "package a\n")
+	write("sub/b.go", "package sub\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "one")
+
+	r := Open(root)
+	ctx := context.Background()
+	if r.Kind() != Git {
+		t.Fatalf("Kind = %q", r.Kind())
+	}
+	if got := r.Branch(ctx); got != "trunk" {
+		t.Errorf("Branch = %q, want trunk", got)
+	}
+	rev, err := r.Revision(ctx)
+	if err != nil || len(rev) != 40 {
+		t.Errorf("Revision = %q, %v", rev, err)
+	}
+	if dirty, err := r.Dirty(ctx); err != nil || dirty {
+		t.Errorf("Dirty = %v, %v on a clean tree", dirty, err)
+	}
+
+	write("sub/b.go", "package sub\n\nvar X = 1\n")
+	git("commit", "-q", "-am", "two")
+	write("a.go", "package a\n\nvar Y = 2\n")
+	if dirty, err := r.Dirty(ctx); err != nil || !dirty {
+		t.Errorf("Dirty = %v, %v after an edit", dirty, err)
+	}
+
+	changed, err := r.ChangedSince(ctx, root, "HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a.go", "sub/b.go"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("ChangedSince(HEAD~1) = %v, want %v", changed, want)
+	}
+	changed, err = r.ChangedSince(ctx, filepath.Join(root, "sub"), "HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b.go"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("ChangedSince(HEAD~1) in sub = %v, want %v", changed, want)
+	}
+	out := filepath.Join(t.TempDir(), "leak")
+	if _, err := r.ChangedSince(ctx, root, "--output="+out); err == nil {
+		t.Error("ChangedSince accepted an option as its ref")
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("git wrote %s: %v", out, err)
+	}
+
+	git("checkout", "-q", "--detach", "HEAD")
+	rev, _ = r.Revision(ctx)
+	if got := r.Branch(ctx); got != rev[:8] {
+		t.Errorf("detached Branch = %q, want %q", got, rev[:8])
+	}
+}
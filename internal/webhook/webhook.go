@@ -0,0 +1,186 @@
+// Package webhook posts analysis-completion events to configured URLs so
+// non-MCP systems (CI, chat relays) can consume intermap results without
+// polling.
+//
+// Deliveries are asynchronous and best-effort: a single background worker
+// POSTs each event once, and failures are logged to stderr. When a hook has
+// a secret, the body is signed with HMAC-SHA256 in X-Intermap-Signature.
+//
+// A nil *Notifier, or one with no hooks, is a valid no-op notifier.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Event names emitted by the tools.
+const (
+	EventChangeImpact = "change_impact"
+	EventIndexUpdate  = "index_update"
+	// EventReservationConflict is emitted by watch mode for local edits to
+	// a file another agent has reserved.
+	EventReservationConflict = "reservation_conflict"
+	// EventDrift is emitted by scheduled re-indexing when a project gains
+	// dependency cycles or high fan-in symbols.
+	EventDrift = "drift"
+)
+
+// Hook is one webhook subscription.
+type Hook struct {
+	URL string
+	// Events filters which events are delivered; empty means all.
+	Events  []string
+	Headers map[string]string
+	Secret  string
+}
+
+// Event is the JSON payload POSTed to hooks.
+type Event struct {
+	Event   string         `json:"event"`
+	Project string         `json:"project"`
+	Time    string         `json:"time"`
+	Summary map[string]any `json:"summary"`
+}
+
+type delivery struct {
+	hook Hook
+	body []byte
+}
+
+// Notifier delivers events to hooks.
+type Notifier struct {
+	hooks []Hook
+	http  *http.Client
+	queue chan delivery
+	wg    sync.WaitGroup
+	once  sync.Once
+
+	mu      sync.Mutex
+	lastErr error
+}
+
+// New creates a notifier for hooks and starts its worker.
+func New(hooks []Hook) *Notifier {
+	n := &Notifier{
+		hooks: hooks,
+		http:  &http.Client{Timeout: 10 * time.Second},
+		queue: make(chan delivery, 64),
+	}
+	if n.Enabled() {
+		n.wg.Add(1)
+		go n.run()
+	}
+	return n
+}
+
+// Enabled reports whether any hooks are configured.
+func (n *Notifier) Enabled() bool {
+	return n != nil && len(n.hooks) > 0
+}
+
+// Emit queues event for every hook subscribed to it.
+func (n *Notifier) Emit(event, project string, summary map[string]any) {
+	if !n.Enabled() {
+		return
+	}
+	body, err := json.Marshal(Event{
+		Event:   event,
+		Project: project,
+		Time:    time.Now().UTC().Format(time.RFC3339),
+		Summary: summary,
+	})
+	if err != nil {
+		n.setErr(fmt.Errorf("marshal %s event: %w", event, err))
+		return
+	}
+	for _, h := range n.hooks {
+		if len(h.Events) > 0 && !slices.Contains(h.Events, event) {
+			continue
+		}
+		select {
+		case n.queue <- delivery{hook: h, body: body}:
+		default:
+			n.setErr(fmt.Errorf("webhook queue full, dropped %s event for %s", event, h.URL))
+		}
+	}
+}
+
+// LastError returns the most recent delivery failure, if any.
+func (n *Notifier) LastError() error {
+	if n == nil {
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.lastErr
+}
+
+// Close flushes queued deliveries and stops the worker.
+func (n *Notifier) Close() {
+	if !n.Enabled() {
+		return
+	}
+	n.once.Do(func() {
+		close(n.queue)
+		n.wg.Wait()
+	})
+}
+
+func (n *Notifier) run() {
+	defer n.wg.Done()
+	for d := range n.queue {
+		if err := n.post(context.Background(), d); err != nil {
+			n.setErr(err)
+		}
+	}
+}
+
+func (n *Notifier) setErr(err error) {
+	n.mu.Lock()
+	n.lastErr = err
+	n.mu.Unlock()
+	fmt.Fprintf(os.Stderr, "intermap-mcp: webhook: %v\n", err)
+}
+
+func (n *Notifier) post(ctx context.Context, d delivery) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", d.hook.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range d.hook.Headers {
+		req.Header.Set(k, v)
+	}
+	if d.hook.Secret != "" {
+		req.Header.Set("X-Intermap-Signature", "sha256="+Sign(d.hook.Secret, d.body))
+	}
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("post %s: %w", d.hook.URL, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("post %s: HTTP %d", d.hook.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body under secret, as sent in
+// X-Intermap-Signature (after the "sha256=" prefix).
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
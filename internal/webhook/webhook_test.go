@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEmit(t *testing.T) {
+	var mu sync.Mutex
+	var got []Event
+	var sigs, tokens []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var ev Event
+		json.Unmarshal(body, &ev)
+		mu.Lock()
+		got = append(got, ev)
+		sigs = append(sigs, r.Header.Get("X-Intermap-Signature"))
+		tokens = append(tokens, r.Header.Get("X-Token"))
+		if want := "sha256=" + Sign("s3cret", body); sigs[len(sigs)-1] != want {
+			t.Errorf("signature mismatch: got %q want %q", sigs[len(sigs)-1], want)
+		}
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	n := New([]Hook{
+		{URL: ts.URL, Events: []string{EventChangeImpact}, Headers: map[string]string{"X-Token": "t"}, Secret: "s3cret"},
+	})
+	n.Emit(EventChangeImpact, "intermap", map[string]any{"affected_count": 2})
+	n.Emit(EventIndexUpdate, "intermap", map[string]any{"mode": "full"})
+	n.Close()
+
+	if len(got) != 1 {
+		t.Fatalf("expected 1 delivery (index_update filtered), got %d", len(got))
+	}
+	if got[0].Event != EventChangeImpact || got[0].Project != "intermap" {
+		t.Errorf("unexpected event: %+v", got[0])
+	}
+	if tokens[0] != "t" {
+		t.Errorf("expected custom header, got %q", tokens[0])
+	}
+	if err := n.LastError(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestEmit_HTTPError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	n := New([]Hook{{URL: ts.URL}})
+	n.Emit(EventIndexUpdate, "p", nil)
+	n.Close()
+
+	if err := n.LastError(); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("expected HTTP 502 error, got %v", err)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	var nilNotifier *Notifier
+	nilNotifier.Emit(EventChangeImpact, "p", nil)
+	nilNotifier.Close()
+
+	if New(nil).Enabled() {
+		t.Error("notifier without hooks should be disabled")
+	}
+}
@@ -0,0 +1,318 @@
+// Package workflows parses GitHub Actions workflow files and evaluates
+// their path filters, to predict which workflows a set of changed files
+// triggers and to find filters that no longer match anything in the repo.
+package workflows
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkflowDir is where GitHub looks for workflow files, relative to the
+// repository root.
+const WorkflowDir = ".github/workflows"
+
+// pathEvents are the events that honor paths/paths-ignore filters.
+var pathEvents = map[string]bool{"push": true, "pull_request": true, "pull_request_target": true}
+
+// Trigger is one event of a workflow's `on:` section.
+type Trigger struct {
+	Event       string   `json:"event"`
+	Branches    []string `json:"branches,omitempty"`
+	Paths       []string `json:"paths,omitempty"`
+	PathsIgnore []string `json:"paths_ignore,omitempty"`
+}
+
+// Job is one workflow job.
+type Job struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name,omitempty"`
+	Needs []string `json:"needs,omitempty"`
+	// If is the job's condition, reported verbatim; it is not evaluated.
+	If string `json:"if,omitempty"`
+}
+
+// Workflow is one parsed workflow file.
+type Workflow struct {
+	File     string    `json:"file"`
+	Name     string    `json:"name,omitempty"`
+	Triggers []Trigger `json:"triggers"`
+	Jobs     []Job     `json:"jobs"`
+	// StalePaths are non-negated path filters that match no file in the
+	// repository.
+	StalePaths []string `json:"stale_paths,omitempty"`
+}
+
+// Run is a predicted workflow run for a set of changed files.
+type Run struct {
+	File  string   `json:"file"`
+	Name  string   `json:"name,omitempty"`
+	Event string   `json:"event"`
+	Jobs  []string `json:"jobs"`
+	// MatchedFiles are the changed files that passed the event's path
+	// filter; empty when the event has no path filter.
+	MatchedFiles []string `json:"matched_files,omitempty"`
+}
+
+// Load parses every workflow under repo's .github/workflows. Files that fail
+// to parse are returned as errors alongside the workflows that did.
+func Load(repo string) ([]Workflow, []error) {
+	entries, err := os.ReadDir(filepath.Join(repo, filepath.FromSlash(WorkflowDir)))
+	if err != nil {
+		return nil, nil
+	}
+	var out []Workflow
+	var errs []error
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+		rel := WorkflowDir + "/" + e.Name()
+		data, err := os.ReadFile(filepath.Join(repo, filepath.FromSlash(rel)))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		w, err := Parse(rel, data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out = append(out, w)
+	}
+	return out, errs
+}
+
+// Parse parses one workflow file.
+func Parse(file string, data []byte) (Workflow, error) {
+	var doc struct {
+		Name string    `yaml:"name"`
+		On   yaml.Node `yaml:"on"`
+		Jobs yaml.Node `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return Workflow{}, fmt.Errorf("%s: %w", file, err)
+	}
+	w := Workflow{File: file, Name: doc.Name, Triggers: []Trigger{}, Jobs: []Job{}}
+
+	switch doc.On.Kind {
+	case yaml.ScalarNode:
+		w.Triggers = append(w.Triggers, Trigger{Event: doc.On.Value})
+	case yaml.SequenceNode:
+		for _, n := range doc.On.Content {
+			w.Triggers = append(w.Triggers, Trigger{Event: n.Value})
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(doc.On.Content); i += 2 {
+			t := Trigger{Event: doc.On.Content[i].Value}
+			var filters struct {
+				Branches    []string `yaml:"branches"`
+				Paths       []string `yaml:"paths"`
+				PathsIgnore []string `yaml:"paths-ignore"`
+			}
+			if doc.On.Content[i+1].Kind == yaml.MappingNode {
+				doc.On.Content[i+1].Decode(&filters)
+			}
+			t.Branches, t.Paths, t.PathsIgnore = filters.Branches, filters.Paths, filters.PathsIgnore
+			w.Triggers = append(w.Triggers, t)
+		}
+	}
+
+	if doc.Jobs.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(doc.Jobs.Content); i += 2 {
+			j := Job{ID: doc.Jobs.Content[i].Value}
+			var spec struct {
+				Name  string    `yaml:"name"`
+				Needs yaml.Node `yaml:"needs"`
+				If    string    `yaml:"if"`
+			}
+			doc.Jobs.Content[i+1].Decode(&spec)
+			j.Name, j.If = spec.Name, spec.If
+			switch spec.Needs.Kind {
+			case yaml.ScalarNode:
+				j.Needs = []string{spec.Needs.Value}
+			case yaml.SequenceNode:
+				spec.Needs.Decode(&j.Needs)
+			}
+			w.Jobs = append(w.Jobs, j)
+		}
+	}
+	return w, nil
+}
+
+// RepoFiles lists the files tracked in repo, falling back to a directory
+// walk (skipping hidden directories) outside git.
+func RepoFiles(repo string) ([]string, error) {
+	if out, err := exec.Command("git", "-C", repo, "ls-files", "-z").Output(); err == nil {
+		var files []string
+		for _, f := range strings.Split(string(out), "\x00") {
+			if f != "" {
+				files = append(files, f)
+			}
+		}
+		return files, nil
+	}
+	var files []string
+	err := filepath.WalkDir(repo, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if p != repo && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(repo, p)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+// MarkStale sets StalePaths from the repository's file list.
+func (w *Workflow) MarkStale(files []string) {
+	seen := make(map[string]bool)
+	w.StalePaths = nil
+	for _, t := range w.Triggers {
+		for _, p := range append(append([]string{}, t.Paths...), t.PathsIgnore...) {
+			if strings.HasPrefix(p, "!") || seen[p] {
+				continue
+			}
+			seen[p] = true
+			re := compileGlob(p)
+			matched := false
+			for _, f := range files {
+				if re.MatchString(f) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				w.StalePaths = append(w.StalePaths, p)
+			}
+		}
+	}
+}
+
+// Runs predicts which of the workflows' path-filtered events (push and pull
+// request) run for the changed files. Events without path filters always
+// run; branch filters and job conditions are not evaluated.
+func Runs(ws []Workflow, changed []string) []Run {
+	out := []Run{}
+	for _, w := range ws {
+		for _, t := range w.Triggers {
+			if !pathEvents[t.Event] {
+				continue
+			}
+			matched, ok := t.Match(changed)
+			if !ok {
+				continue
+			}
+			r := Run{File: w.File, Name: w.Name, Event: t.Event, Jobs: []string{}, MatchedFiles: matched}
+			for _, j := range w.Jobs {
+				r.Jobs = append(r.Jobs, j.ID)
+			}
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Match reports whether the event fires for the changed files, and which
+// files passed its path filter. With paths, a file counts when the last
+// pattern matching it is not negated; with paths-ignore, the event fires
+// unless every file is ignored.
+func (t Trigger) Match(changed []string) ([]string, bool) {
+	switch {
+	case len(t.Paths) > 0:
+		matched := filterFiles(t.Paths, changed)
+		return matched, len(matched) > 0
+	case len(t.PathsIgnore) > 0:
+		ignored := make(map[string]bool)
+		for _, f := range filterFiles(t.PathsIgnore, changed) {
+			ignored[f] = true
+		}
+		var kept []string
+		for _, f := range changed {
+			if !ignored[f] {
+				kept = append(kept, f)
+			}
+		}
+		return kept, len(kept) > 0
+	default:
+		return nil, true
+	}
+}
+
+func filterFiles(patterns, files []string) []string {
+	type rule struct {
+		re     *regexp.Regexp
+		negate bool
+	}
+	rules := make([]rule, len(patterns))
+	for i, p := range patterns {
+		neg := strings.HasPrefix(p, "!")
+		rules[i] = rule{compileGlob(strings.TrimPrefix(p, "!")), neg}
+	}
+	var out []string
+	for _, f := range files {
+		in := false
+		for _, r := range rules {
+			if r.re.MatchString(f) {
+				in = !r.negate
+			}
+		}
+		if in {
+			out = append(out, f)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// compileGlob translates a GitHub Actions filter pattern to a regexp: `*`
+// matches within a path segment, `**` across segments, and `?` and `+`
+// quantify the preceding character.
+func compileGlob(p string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?' || c == '+':
+			b.WriteByte(c)
+		case c == '[':
+			if j := strings.IndexByte(p[i:], ']'); j > 0 {
+				b.WriteString(p[i : i+j+1])
+				i += j
+			} else {
+				b.WriteString(`\[`)
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return regexp.MustCompile("^" + regexp.QuoteMeta(p) + "$")
+	}
+	return re
+}
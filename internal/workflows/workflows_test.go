@@ -0,0 +1,95 @@
+package workflows
+
+import (
+	"reflect"
+	"testing"
+)
+
+const ciWorkflow = `name: CI
+on:
+  push:
+    branches: [main]
+    paths:
+      - "**.go"
+      - "go.mod"
+      - "!docs/**"
+      - "legacy/**"
+  pull_request:
+    paths-ignore: ["docs/**", "*.md"]
+  workflow_dispatch:
+jobs:
+  test:
+    runs-on: ubuntu-latest
+  release:
+    needs: test
+    if: github.ref == 'refs/heads/main'
+`
+
+func TestParse(t *testing.T) {
+	w, err := Parse(".github/workflows/ci.yml", []byte(ciWorkflow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Name != "CI" || len(w.Triggers) != 3 || len(w.Jobs) != 2 {
+		t.Fatalf("unexpected workflow %+v", w)
+	}
+	if w.Triggers[2].Event != "workflow_dispatch" {
+		t.Errorf("trigger order: %+v", w.Triggers)
+	}
+	if rel := w.Jobs[1]; !reflect.DeepEqual(rel.Needs, []string{"test"}) || rel.If == "" {
+		t.Errorf("release job = %+v", rel)
+	}
+
+	if _, err := Parse("bad.yml", []byte("on: [push\n")); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestRuns(t *testing.T) {
+	w, _ := Parse(".github/workflows/ci.yml", []byte(ciWorkflow))
+	ws := []Workflow{w}
+
+	runs := Runs(ws, []string{"docs/guide.md", "README.md"})
+	if len(runs) != 0 {
+		t.Errorf("docs-only change: expected no runs, got %+v", runs)
+	}
+
+	runs = Runs(ws, []string{"internal/x/x.go", "docs/api.go"})
+	if len(runs) != 2 {
+		t.Fatalf("expected push and pull_request runs, got %+v", runs)
+	}
+	if !reflect.DeepEqual(runs[0].MatchedFiles, []string{"internal/x/x.go"}) {
+		t.Errorf("push matched %v; docs/api.go should be negated", runs[0].MatchedFiles)
+	}
+	if !reflect.DeepEqual(runs[1].Jobs, []string{"test", "release"}) {
+		t.Errorf("jobs = %v", runs[1].Jobs)
+	}
+}
+
+func TestMarkStale(t *testing.T) {
+	w, _ := Parse(".github/workflows/ci.yml", []byte(ciWorkflow))
+	w.MarkStale([]string{"main.go", "go.mod", "README.md"})
+	if want := []string{"legacy/**", "docs/**"}; !reflect.DeepEqual(w.StalePaths, want) {
+		t.Errorf("stale = %v, want %v", w.StalePaths, want)
+	}
+}
+
+func TestCompileGlob(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"*.md", "README.md", true},
+		{"*.md", "docs/a.md", false},
+		{"**.md", "docs/a.md", true},
+		{"**/README.md", "README.md", true},
+		{"docs/**", "docs/a/b.txt", true},
+		{"src/*.go", "src/a.go", true},
+		{"src/*.go", "src/sub/a.go", false},
+		{"v[12].txt", "v2.txt", true},
+	} {
+		if got := compileGlob(tc.pattern).MatchString(tc.file); got != tc.want {
+			t.Errorf("%q vs %q: got %v, want %v", tc.pattern, tc.file, got, tc.want)
+		}
+	}
+}
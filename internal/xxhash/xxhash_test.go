@@ -0,0 +1,22 @@
+package xxhash
+
+import "testing"
+
+func TestSum64(t *testing.T) {
+	// Reference values from the xxHash project (XXH64, seed 0).
+	for _, tc := range []struct {
+		in   string
+		want uint64
+	}{
+		{"", 0xef46db3751d8e999},
+		{"a", 0xd24ec4f1a98c6e5b},
+		{"abc", 0x44bc2cf5ad770999},
+		{"message digest", 0x066ed728fceeb3be},
+		{"abcdefghijklmnopqrstuvwxyz", 0xcfe1f278fa89835c},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", 0xe04a477f19ee145d},
+	} {
+		if got := Sum64([]byte(tc.in)); got != tc.want {
+			t.Errorf("Sum64(%q) = %#x, want %#x", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+// Package python embeds the intermap Python package, so the binary can
+// restore a plugin's python/ directory (intermap-mcp install-check -repair).
+// It is not part of intermap's public Go API.
+package python
+
+import "embed"
+
+// Files holds the sources of the intermap package, rooted at this
+// directory: intermap/__main__.py, intermap/vendor/__init__.py, and so on.
+//
+//go:embed intermap/*.py intermap/vendor/*.py
+var Files embed.FS
@@ -5,11 +5,17 @@ Modes:
   --sidecar                   Persistent stdin/stdout JSON-RPC loop
 """
 
+from __future__ import annotations
+
 import argparse
 import json
+import os
 import sys
+import tempfile
+import threading
 import traceback
 
+from . import sidecar_log, usage
 from .errors import IntermapError
 
 
@@ -50,15 +56,94 @@ def _run_single(args):
         _error_exit(type(e).__name__, str(e))
 
 
+_stdout_lock = threading.Lock()
+
+# Framings the sidecar speaks, advertised in the ready frame. The bridge
+# picks one by sending {"framing": name}; until then frames are ndjson, one
+# JSON object per line. A length frame is the payload's byte count in
+# decimal, a newline, then the payload, so results need no size limit.
+FRAMINGS = ("length", "ndjson")
+_framing = "ndjson"
+
+# Set from {"framing": "length", "handoff": {"dir", "min_bytes"}}: responses
+# whose JSON is larger than min_bytes are written to a file in dir and only
+# {"id", "handoff": {"path", "bytes"}} goes over the pipe. The bridge reads
+# and deletes the file, and removes any this process leaves behind.
+HANDOFF_PREFIX = "intermap-result-"
+_handoff: dict | None = None
+
+
+def _write_frame(frame: dict) -> None:
+    """Write one protocol frame; log frames may come from worker threads."""
+    _write_encoded(json.dumps(frame).encode())
+
+
+def _write_response(resp: dict) -> None:
+    """Write a response frame, handing it off through a file if it is large."""
+    data = json.dumps(resp).encode()
+    if _handoff is not None and len(data) > _handoff["min_bytes"]:
+        try:
+            fd, path = tempfile.mkstemp(
+                prefix=f"{HANDOFF_PREFIX}{os.getpid()}-", suffix=".json", dir=_handoff["dir"])
+            with os.fdopen(fd, "wb") as f:
+                f.write(data)
+        except OSError:
+            pass  # fall back to the pipe
+        else:
+            _write_frame({"id": resp.get("id"), "handoff": {"path": path, "bytes": len(data)}})
+            return
+    _write_encoded(data)
+
+
+def _write_encoded(data: bytes) -> None:
+    with _stdout_lock:
+        if _framing == "length":
+            data = b"%d\n" % len(data) + data
+        else:
+            data += b"\n"
+        view = memoryview(data)
+        out = sys.stdout.buffer
+        while view:
+            view = view[out.write(view):]
+        out.flush()
+
+
+def _read_frame(stdin) -> bytes | None:
+    """Read one request frame, or None at EOF."""
+    if _framing == "length":
+        header = stdin.readline()
+        if not header:
+            return None
+        size = int(header)
+        data = stdin.read(size)
+        return data if len(data) == size else None
+    return stdin.readline() or None
+
+
+def _select_framing(name, handoff=None) -> None:
+    """Switch to the framing the bridge chose and acknowledge it in that framing."""
+    global _framing, _handoff
+    if name not in FRAMINGS:
+        _write_frame({"id": None, "error": {"type": "InvalidFraming", "message": f"unknown framing {name!r}"}})
+        return
+    with _stdout_lock:
+        _framing = name
+    # Handoff references are length frames; see HANDOFF_PREFIX.
+    _handoff = handoff if name == "length" else None
+    _write_frame({"framing": name})
+
+
 def _run_sidecar():
     """Persistent sidecar: read JSON requests from stdin, write responses to stdout."""
     from .analyze import dispatch
 
+    sidecar_log.install(_write_frame)
+
     # Signal readiness
-    sys.stdout.write('{"status":"ready"}\n')
-    sys.stdout.flush()
+    _write_frame({"status": "ready", "framing": list(FRAMINGS), "handoff": True})
 
-    for line in sys.stdin:
+    stdin = sys.stdin.buffer
+    while (line := _read_frame(stdin)) is not None:
         line = line.strip()
         if not line:
             continue
@@ -67,8 +152,11 @@ def _run_sidecar():
             req = json.loads(line)
         except json.JSONDecodeError as e:
             resp = {"id": None, "error": {"type": "InvalidJSON", "message": str(e)}}
-            sys.stdout.write(json.dumps(resp) + "\n")
-            sys.stdout.flush()
+            _write_frame(resp)
+            continue
+
+        if "framing" in req and "command" not in req:
+            _select_framing(req["framing"], req.get("handoff"))
             continue
 
         req_id = req.get("id")
@@ -76,6 +164,8 @@ def _run_sidecar():
         project = req.get("project", "")
         extra_args = req.get("args", {})
 
+        sidecar_log.set_request(req_id, command)
+        usage.start()
         try:
             result = dispatch(command, project, extra_args)
             resp = {"id": req_id, "result": result}
@@ -109,6 +199,8 @@ def _run_sidecar():
                 },
             }
         except Exception as e:
+            # The bridge reports the tail of stderr with unrecoverable errors.
+            traceback.print_exc(file=sys.stderr)
             resp = {
                 "id": req_id,
                 "error": {
@@ -118,8 +210,8 @@ def _run_sidecar():
                 },
             }
 
-        sys.stdout.write(json.dumps(resp) + "\n")
-        sys.stdout.flush()
+        resp["usage"] = usage.snapshot()
+        _write_response(resp)
 
 
 def _error_exit(error_type: str, message: str):
@@ -0,0 +1,194 @@
+"""Agent activity history: periodic agent_map snapshots in local SQLite.
+
+The Go server records a snapshot of the agent overlay every interval (opt-in,
+see ``agent_history`` in the config). ``agent_timeline`` replays the
+snapshots in a window into per-agent segments, where a segment is a run of
+consecutive snapshots with the same project, reservations, and task, and
+into change events between them.
+
+Timestamps are RFC 3339 UTC strings (``2026-01-02T03:04:05Z``), so they
+compare correctly as text.
+"""
+
+import json
+import sqlite3
+from datetime import datetime, timedelta, timezone
+from pathlib import Path
+
+_SCHEMA = """
+CREATE TABLE IF NOT EXISTS snapshots (
+    id INTEGER PRIMARY KEY,
+    taken_at TEXT NOT NULL,
+    root TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS snapshots_root_time ON snapshots(root, taken_at);
+CREATE TABLE IF NOT EXISTS assignments (
+    snapshot_id INTEGER NOT NULL REFERENCES snapshots(id) ON DELETE CASCADE,
+    agent_id TEXT NOT NULL,
+    name TEXT,
+    status TEXT,
+    project TEXT,
+    project_path TEXT,
+    confidence TEXT,
+    current_task TEXT,
+    reservations TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS assignments_snapshot ON assignments(snapshot_id);
+"""
+
+
+def _connect(db_path: str) -> sqlite3.Connection:
+    Path(db_path).parent.mkdir(parents=True, exist_ok=True)
+    conn = sqlite3.connect(db_path)
+    conn.execute("PRAGMA foreign_keys = ON")
+    conn.executescript(_SCHEMA)
+    return conn
+
+
+def _now() -> str:
+    return datetime.now(timezone.utc).strftime("%Y-%m-%dT%H:%M:%SZ")
+
+
+def record_snapshot(db_path: str, root: str, agents: list[dict], taken_at: str | None = None, retain_days: int = 30) -> dict:
+    """Store one agent_map snapshot and prune snapshots older than retain_days.
+
+    agents are agent_map overlay entries. Returns {"snapshot_id", "agents",
+    "pruned"}.
+    """
+    taken_at = taken_at or _now()
+    with _connect(db_path) as conn:
+        cur = conn.execute("INSERT INTO snapshots (taken_at, root) VALUES (?, ?)", (taken_at, root))
+        snapshot_id = cur.lastrowid
+        conn.executemany(
+            "INSERT INTO assignments (snapshot_id, agent_id, name, status, project, project_path,"
+            " confidence, current_task, reservations) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+            [
+                (
+                    snapshot_id,
+                    a.get("agent_id", ""),
+                    a.get("name"),
+                    a.get("status"),
+                    a.get("project"),
+                    a.get("project_path"),
+                    a.get("confidence"),
+                    a.get("current_task"),
+                    json.dumps(sorted(a.get("reservations") or [])),
+                )
+                for a in agents
+                if a.get("agent_id")
+            ],
+        )
+        pruned = 0
+        if retain_days > 0:
+            cutoff = (datetime.now(timezone.utc) - timedelta(days=retain_days)).strftime("%Y-%m-%dT%H:%M:%SZ")
+            pruned = conn.execute("DELETE FROM snapshots WHERE taken_at < ?", (cutoff,)).rowcount
+    conn.close()
+    return {"snapshot_id": snapshot_id, "agents": len(agents), "pruned": pruned}
+
+
+def agent_timeline(
+    db_path: str,
+    root: str,
+    since: str,
+    until: str | None = None,
+    agent: str | None = None,
+    project: str | None = None,
+) -> dict:
+    """Replay snapshots of root between since and until.
+
+    agent filters by agent ID or name; project by project name or path.
+    Returns {"since", "until", "snapshots", "agents", "events"}. Each agent
+    is {"agent_id", "name", "segments"}, and each segment is {"from", "to",
+    "snapshots", "project", "project_path", "status", "current_task",
+    "reservations"}. Events are {"time", "agent_id", "kind", ...} with kind
+    joined, left, moved, task, reserved, or released.
+    """
+    until = until or _now()
+    conn = _connect(db_path)
+    try:
+        times = [
+            row[0]
+            for row in conn.execute(
+                "SELECT taken_at FROM snapshots WHERE root = ? AND taken_at >= ? AND taken_at <= ? ORDER BY taken_at, id",
+                (root, since, until),
+            )
+        ]
+        rows = conn.execute(
+            "SELECT s.taken_at, a.agent_id, a.name, a.status, a.project, a.project_path, a.current_task, a.reservations"
+            " FROM assignments a JOIN snapshots s ON s.id = a.snapshot_id"
+            " WHERE s.root = ? AND s.taken_at >= ? AND s.taken_at <= ? ORDER BY s.taken_at, s.id",
+            (root, since, until),
+        ).fetchall()
+    finally:
+        conn.close()
+
+    index = {t: i for i, t in enumerate(times)}
+    by_agent: dict[str, list[dict]] = {}
+    for taken_at, agent_id, name, status, proj, proj_path, task, reservations in rows:
+        if agent and agent not in (agent_id, name):
+            continue
+        if project and project not in (proj, proj_path):
+            continue
+        by_agent.setdefault(agent_id, []).append({
+            "time": taken_at,
+            "index": index[taken_at],
+            "name": name,
+            "status": status,
+            "project": proj,
+            "project_path": proj_path,
+            "current_task": task,
+            "reservations": json.loads(reservations),
+        })
+
+    agents = []
+    events = []
+    for agent_id, observations in sorted(by_agent.items()):
+        segments = []
+        prev = None
+        for obs in observations:
+            gap = prev is not None and obs["index"] != prev["index"] + 1
+            if prev is None or gap:
+                if gap:
+                    events.append({"time": times[prev["index"] + 1], "agent_id": agent_id, "kind": "left"})
+                events.append({"time": obs["time"], "agent_id": agent_id, "kind": "joined", "project": obs["project"]})
+            else:
+                events.extend(_changes(agent_id, prev, obs))
+            if prev is None or gap or _key(prev) != _key(obs):
+                segments.append({
+                    "from": obs["time"],
+                    "to": obs["time"],
+                    "snapshots": 0,
+                    "project": obs["project"],
+                    "project_path": obs["project_path"],
+                    "status": obs["status"],
+                    "current_task": obs["current_task"],
+                    "reservations": obs["reservations"],
+                })
+            segments[-1]["to"] = obs["time"]
+            segments[-1]["snapshots"] += 1
+            prev = obs
+        if prev["index"] + 1 < len(times):
+            events.append({"time": times[prev["index"] + 1], "agent_id": agent_id, "kind": "left"})
+        agents.append({"agent_id": agent_id, "name": observations[-1]["name"], "segments": segments})
+
+    events.sort(key=lambda e: (e["time"], e["agent_id"]))
+    return {"since": since, "until": until, "snapshots": len(times), "agents": agents, "events": events}
+
+
+def _key(obs: dict):
+    return (obs["project_path"] or obs["project"], obs["current_task"], tuple(obs["reservations"]))
+
+
+def _changes(agent_id: str, prev: dict, obs: dict) -> list[dict]:
+    out = []
+    at = {"time": obs["time"], "agent_id": agent_id}
+    if (prev["project_path"] or prev["project"]) != (obs["project_path"] or obs["project"]):
+        out.append({**at, "kind": "moved", "from": prev["project"], "to": obs["project"]})
+    if prev["current_task"] != obs["current_task"]:
+        out.append({**at, "kind": "task", "from": prev["current_task"], "to": obs["current_task"]})
+    before, after = set(prev["reservations"]), set(obs["reservations"])
+    if after - before:
+        out.append({**at, "kind": "reserved", "patterns": sorted(after - before)})
+    if before - after:
+        out.append({**at, "kind": "released", "patterns": sorted(before - after)})
+    return out
@@ -366,6 +366,7 @@ def analyze_impact(
     max_depth: int = 3,
     target_file: str | None = None,
     language: str = "python",
+    precision: str = "fast",
 ) -> dict:
     """Convenience wrapper that builds call graph from path.
 
@@ -375,14 +376,53 @@ def analyze_impact(
         max_depth: How deep to traverse callers
         target_file: Optional file filter
         language: Source language
+        precision: "fast" for the heuristic call graph, or "typed" (Python
+            only) to find callers through jedi's type inference
 
     Returns:
         Impact analysis results
     """
-    from .cross_file_calls import build_project_call_graph
+    from .symbol_ids import SymbolIdResolver, package_path, parse_symbol_id
+
+    if precision not in ("fast", "typed"):
+        return {"error": f"Unknown precision '{precision}' (want fast or typed)"}
+    if precision == "typed" and language != "python":
+        return {"error": f"precision 'typed' is only available for python, not {language}"}
+
+    target_package = None
+    if "#" in target_func:
+        target_package, target_func, _ = parse_symbol_id(target_func)
+
+    if precision == "typed":
+        from .typed_calls import analyze_typed_impact
+        result = analyze_typed_impact(path, target_func, max_depth, target_file)
+    else:
+        from .cross_file_calls import build_project_call_graph
+        call_graph = build_project_call_graph(path, language=language)
+        result = impact_analysis(call_graph, target_func, max_depth, target_file)
+    if "targets" not in result:
+        return result
+
+    resolver = SymbolIdResolver(path, language)
+    if target_package is not None:
+        result["targets"] = {
+            key: tree for key, tree in result["targets"].items()
+            if package_path(tree["file"], language) == target_package
+        }
+        result["total_targets"] = len(result["targets"])
+        if not result["targets"]:
+            return {"error": f"Function '{target_func}' not found in package '{target_package}'"}
 
-    call_graph = build_project_call_graph(path, language=language)
-    return impact_analysis(call_graph, target_func, max_depth, target_file)
+    for tree in result["targets"].values():
+        _annotate_tree_ids(tree, resolver)
+    return result
+
+
+def _annotate_tree_ids(tree: dict, resolver) -> None:
+    """Attach stable symbol IDs to every node of a caller tree."""
+    tree["id"] = resolver.resolve(tree["file"], tree.get("qualified", tree["function"]))
+    for caller in tree["callers"]:
+        _annotate_tree_ids(caller, resolver)
 
 
 def analyze_dead_code(
@@ -6,10 +6,20 @@ Called from __main__.py.
 
 from __future__ import annotations
 
+from . import build_context, vcs
+
 
 def dispatch(command: str, project: str, args: dict) -> dict:
     """Dispatch a command to the appropriate analysis function.
 
+    When project is in a sparse checkout or partial clone, the result gains
+    a "checkout" entry describing it, and a path that turns out to be
+    missing yields a skipped result instead of an error.
+
+    A "go_build" argument ({goos, goarch, tags}) restricts Go files to those
+    built in that context, and the result gains a "build_context" entry
+    listing the files excluded.
+
     Args:
         command: Analysis command name
         project: Project root path
@@ -18,12 +28,42 @@ def dispatch(command: str, project: str, args: dict) -> dict:
     Returns:
         Dict result from the analysis function
     """
+    build = build_context.from_args(args.get("go_build"))
+    if build is not None:
+        with build_context.using(build):
+            result = _checked_dispatch(command, project, args)
+        if isinstance(result, dict) and not result.get("skipped"):
+            result["build_context"] = build.describe(project)
+        return result
+    return _checked_dispatch(command, project, args)
+
+
+def _checked_dispatch(command: str, project: str, args: dict) -> dict:
+    checkout = vcs.checkout_state(project) if project else None
+    if checkout is None:
+        return _dispatch(command, project, args)
+    try:
+        result = _dispatch(command, project, args)
+    except FileNotFoundError as e:
+        return {
+            "skipped": True,
+            "reason": "path not present in this checkout",
+            "missing_path": e.filename or str(e),
+            "checkout": checkout,
+        }
+    if isinstance(result, dict):
+        result.setdefault("checkout", checkout)
+    return result
+
+
+def _dispatch(command: str, project: str, args: dict) -> dict:
     if command == "structure":
         from .code_structure import get_code_structure
         return get_code_structure(
             project,
             language=args.get("language", "python"),
             max_results=args.get("max_results", 1000),
+            sample=args.get("sample"),
         )
 
     elif command == "impact":
@@ -34,6 +74,7 @@ def dispatch(command: str, project: str, args: dict) -> dict:
             max_depth=args.get("max_depth", 3),
             target_file=args.get("target_file"),
             language=args.get("language", "python"),
+            precision=args.get("precision", "fast"),
         )
 
     elif command == "dead_code":
@@ -60,6 +101,65 @@ def dispatch(command: str, project: str, args: dict) -> dict:
             git_base=args.get("git_base", "HEAD~1"),
             language=args.get("language", "python"),
             max_depth=args.get("max_depth", 5),
+            output=args.get("output"),
+            test_history=args.get("test_history"),
+        )
+
+    elif command == "bench_impact":
+        from .bench_impact import analyze_bench_impact
+        return analyze_bench_impact(
+            project,
+            files=args.get("files"),
+            use_git=args.get("use_git", True),
+            git_base=args.get("git_base", "HEAD~1"),
+            language=args.get("language", "python"),
+            max_depth=args.get("max_depth", 5),
+        )
+
+    elif command == "doc_coverage":
+        from .doc_coverage import analyze_doc_coverage
+        return analyze_doc_coverage(
+            project,
+            language=args.get("language", "python"),
+            top=args.get("top", 50),
+        )
+
+    elif command == "api_surface":
+        from .api_surface import api_surface
+        return api_surface(
+            project,
+            language=args.get("language", "python"),
+            packages=args.get("packages"),
+            include_internal=args.get("include_internal", False),
+        )
+
+    elif command == "message_inventory":
+        from .messages import inventory_messages
+        return inventory_messages(
+            project,
+            language=args.get("language", "python"),
+            kinds=args.get("kinds"),
+            max_results=args.get("max_results", 2000),
+        )
+
+    elif command == "agent_history_record":
+        from .agent_history import record_snapshot
+        return record_snapshot(
+            args["db"],
+            project,
+            args.get("agents") or [],
+            retain_days=args.get("retain_days", 30),
+        )
+
+    elif command == "agent_timeline":
+        from .agent_history import agent_timeline
+        return agent_timeline(
+            args["db"],
+            project,
+            since=args["since"],
+            until=args.get("until"),
+            agent=args.get("agent"),
+            project=args.get("project"),
         )
 
     elif command == "diagnostics":
@@ -88,7 +188,160 @@ def dispatch(command: str, project: str, args: dict) -> dict:
 
     elif command == "cross_project_deps":
         from .cross_project import scan_cross_project_deps
-        return scan_cross_project_deps(project)
+        return scan_cross_project_deps(project, max_depth=args.get("max_depth", 4))
+
+    elif command == "consumers":
+        from .consumers import find_consumers
+        return find_consumers(
+            project,
+            args["project"],
+            package=args.get("package"),
+            max_depth=args.get("max_depth", 4),
+        )
+
+    elif command == "deprecations":
+        from .deprecations import find_deprecations
+        return find_deprecations(
+            project,
+            project=args.get("project"),
+            max_sites=args.get("max_sites", 20),
+            max_depth=args.get("max_depth", 4),
+        )
+
+    elif command == "codemod_plan":
+        from .codemod_plan import codemod_plan
+        return codemod_plan(
+            project,
+            args["pattern"],
+            rewrite=args.get("rewrite"),
+            language=args.get("language"),
+            projects=args.get("projects"),
+            max_depth=args.get("max_depth", 4),
+            max_results=args.get("max_results", 2000),
+        )
+
+    elif command == "apply_rename":
+        from .rename import apply_rename
+        return apply_rename(
+            project,
+            args.get("kind", "identifier"),
+            args["old"],
+            args["new"],
+            language=args.get("language", "python"),
+            files=args.get("files"),
+            apply=args.get("apply", False),
+        )
+
+    elif command == "script_map":
+        from .script_map import scan_scripts
+        return scan_scripts(project, max_depth=args.get("max_depth", 4))
+
+    elif command == "boundary_map":
+        from .boundaries import scan_boundaries
+        return scan_boundaries(project, max_depth=args.get("max_depth", 4), kinds=args.get("kinds"))
+
+    elif command == "service_graph":
+        from .service_graph import build_service_graph
+        return build_service_graph(project, max_depth=args.get("max_depth", 4))
+
+    elif command == "release_impact":
+        from .release_impact import release_impact
+        return release_impact(
+            project,
+            args["project"],
+            language=args.get("language", "python"),
+            since=args.get("since"),
+            max_depth=args.get("max_depth", 4),
+        )
+
+    elif command == "name_collisions":
+        from .name_collisions import find_name_collisions
+        return find_name_collisions(
+            project,
+            kinds=args.get("kinds"),
+            scope=args.get("scope", "neighborhood"),
+            languages=args.get("languages"),
+            ignore=args.get("ignore"),
+            max_depth=args.get("max_depth", 4),
+        )
+
+    elif command == "code_search":
+        from .code_search import search_code
+        return search_code(
+            project,
+            args["pattern"],
+            mode=args.get("mode", "regex"),
+            language=args.get("language"),
+            glob=args.get("glob"),
+            ignore_case=args.get("ignore_case", False),
+            max_depth=args.get("max_depth", 4),
+            max_results=args.get("max_results", 500),
+        )
+
+    elif command == "semantic_search":
+        from .semantic import semantic_search
+        return semantic_search(
+            args["db"],
+            project,
+            args.get("query", ""),
+            provider=args.get("provider", "openai"),
+            endpoint=args.get("endpoint", ""),
+            model=args.get("model", ""),
+            api_key_env=args.get("api_key_env", ""),
+            top_k=args.get("top_k", 10),
+            max_embed=args.get("max_embed", 2000),
+            max_depth=args.get("max_depth", 4),
+        )
+
+    elif command == "describe_symbol":
+        from .symbol_summary import describe_symbol
+        language = args.get("language", "auto")
+        if language == "auto":
+            language = _detect_project_language(project)
+        return describe_symbol(project, args["symbol"], language, file=args.get("file"))
+
+    elif command == "effects_analysis":
+        from .effects_analysis import analyze_effects
+        language = args.get("language", "auto")
+        if language == "auto":
+            language = _detect_project_language(project)
+        return analyze_effects(
+            project,
+            language,
+            capabilities=args.get("capabilities"),
+            files=args.get("files"),
+            include_pure=args.get("include_pure", False),
+            max_results=args.get("max_results", 1000),
+        )
+
+    elif command == "taint_paths":
+        from .taint import find_taint_paths
+        language = args.get("language", "auto")
+        if language == "auto":
+            language = _detect_project_language(project)
+        return find_taint_paths(
+            project,
+            language,
+            sources=args.get("sources"),
+            sinks=args.get("sinks"),
+            files=args.get("files"),
+            max_depth=args.get("max_depth", 6),
+            max_results=args.get("max_results", 500),
+        )
+
+    elif command == "error_flow":
+        from .error_flow import error_flow
+        language = args.get("language", "auto")
+        if language == "auto":
+            language = _detect_project_language(project)
+        return error_flow(
+            project,
+            language,
+            kinds=args.get("kinds"),
+            files=args.get("files"),
+            entry_points=args.get("entry_points"),
+            max_results=args.get("max_results", 500),
+        )
 
     elif command == "detect_patterns":
         from .patterns import detect_patterns
@@ -105,9 +358,32 @@ def dispatch(command: str, project: str, args: dict) -> dict:
             language=args.get("language", "auto"),
         )
 
+    elif command == "structure_of_diff":
+        from .diff_structure import structure_of_diff
+        return structure_of_diff(
+            project,
+            base=args.get("base", "HEAD"),
+            head=args.get("head"),
+            files=args.get("files"),
+            max_files=args.get("max_files", 200),
+        )
+
     elif command == "reference_edges":
         return _reference_edges(project, args)
 
+    elif command == "index_update":
+        from .graph_store import index_update
+        language = args.get("language", "auto")
+        if language == "auto":
+            language = _detect_project_language(project)
+        return index_update(
+            project,
+            language,
+            files=args.get("files"),
+            baseline=args.get("baseline", "HEAD"),
+            mtime_hash=args.get("mtime_hash", ""),
+        )
+
     else:
         return {"error": "UnknownCommand", "message": f"Unknown command: {command}"}
 
@@ -133,7 +409,12 @@ def _detect_project_language(project: str) -> str:
 
 def _reference_edges(project: str, args: dict) -> dict:
     """Build definition list and cross-file reference edges for graph construction."""
-    from .cross_file_calls import build_definition_list, build_project_call_graph
+    from pathlib import Path
+
+    from .cross_file_calls import build_definition_list, build_project_call_graph, scan_project
+    from .graph_store import get_store
+    from .sampling import import_edges
+    from .symbol_ids import SymbolIdResolver, annotate_definitions
 
     language = args.get("language", "auto")
     if language == "auto":
@@ -141,16 +422,25 @@ def _reference_edges(project: str, args: dict) -> dict:
 
     max_files = args.get("max_files", 500)
 
-    definitions = build_definition_list(
-        project,
-        language=language,
-        max_files=max_files,
-    )
+    # Reuse the incrementally maintained store when index_update keeps it fresh.
+    store = get_store(project, language, create=False)
+    if store is not None and store.is_current():
+        definitions = store.definition_list(max_files)
+        graph_edges = store.edges()
+    else:
+        definitions = build_definition_list(
+            project,
+            language=language,
+            max_files=max_files,
+        )
+        graph_edges = build_project_call_graph(
+            project,
+            language=language,
+        ).edges
 
-    graph = build_project_call_graph(
-        project,
-        language=language,
-    )
+    annotate_definitions(project, definitions, language)
+    resolver = SymbolIdResolver(project, language)
+    resolver.seed(definitions)
 
     edges = [
         {
@@ -158,15 +448,32 @@ def _reference_edges(project: str, args: dict) -> dict:
             "src_symbol": e[1],
             "dst_file": e[2],
             "dst_symbol": e[3],
+            "src_id": resolver.resolve(e[0], e[1]),
+            "dst_id": resolver.resolve(e[2], e[3]),
         }
-        for e in graph.edges
+        for e in graph_edges
     ]
 
     files_scanned = len(set(d["file"] for d in definitions))
 
+    # Import lines link files whose calls the call graph can't resolve. They
+    # come from every scanned source file, so modules holding only constants
+    # or re-exports, which have no definitions, are linked too.
+    root = Path(project).resolve()
+    sources = [Path(f) for f in scan_project(root, language)]
+    if max_files and len(sources) > max_files:
+        sources = sources[:max_files]
+    imports = import_edges(root, sources)
+    file_edges = [
+        {"src_file": str(src.relative_to(root)), "dst_file": str(dst.relative_to(root))}
+        for src in sorted(imports)
+        for dst in sorted(imports[src])
+    ]
+
     return {
         "definitions": definitions,
         "edges": edges,
+        "file_edges": file_edges,
         "files_scanned": files_scanned,
         "language": language,
         "edge_count": len(edges),
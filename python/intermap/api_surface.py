@@ -0,0 +1,338 @@
+"""Public API surface of a project, with normalized signatures.
+
+What counts as public, per language:
+
+- python: the names in a module's literal ``__all__`` when it has one,
+  otherwise top-level functions, classes, and variables not starting with
+  ``_``; plus public methods of public classes. Modules under a ``_``-prefixed
+  path component (other than ``__init__.py``) are private.
+- go: exported funcs, methods on exported types, types, consts, and vars,
+  with the exported fields and methods of struct and interface types as
+  ``members``. ``package main`` and ``internal/`` packages are skipped
+  unless ``include_internal`` is set.
+- typescript/javascript: ``export``ed declarations and ``export { ... }``
+  lists.
+- rust: ``pub`` items (``pub(crate)`` and friends are not public).
+
+Signatures are declaration headers with whitespace collapsed and bodies cut
+off, and each symbol carries the symbol ID built from its signature and
+members, so two surfaces diff cleanly: a changed ID under the same name is
+a changed signature.
+"""
+
+from __future__ import annotations
+
+import ast
+import re
+from pathlib import Path
+
+from .change_impact import _scan_project_files, is_test_file
+from .symbol_ids import make_symbol_id, package_path
+from .symbol_summary import _blank, _close
+
+_WS = re.compile(r"\s+")
+_GO_FUNC = re.compile(r"^func\s+(?:\(\s*\w*\s*\*?\s*(\w+)(?:\[[^\]]*\])?\s*\)\s*)?([A-Z]\w*)\s*[\[(]", re.M)
+_GO_TYPE = re.compile(r"^type\s+([A-Z]\w*)\b", re.M)
+_GO_VALUE = re.compile(r"^(const|var)\s+(?:([A-Z]\w*)\b|\()", re.M)
+_GO_GROUP_ITEM = re.compile(r"^\s*([A-Z]\w*)\b")
+_JS_DECL = re.compile(
+    r"^export\s+(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?"
+    r"(async\s+function\*?|function\*?|class|interface|type|enum|const|let|var|namespace)\s+(\w+)",
+    re.M,
+)
+_JS_LIST = re.compile(r"^export\s*(?:type\s*)?\{([^}]*)\}", re.M)
+_RS_DECL = re.compile(
+    r"^[ \t]*pub\s+(?:(?:async|const|unsafe|extern(?:\s+\"\w+\")?)\s+)*"
+    r"(fn|struct|enum|trait|type|const|static|mod|union)\s+(\w+)",
+    re.M,
+)
+_RS_IMPL = re.compile(r"^[ \t]*impl\b(?:\s*<[^>{]*>)?\s+([\w:]+)(?:<[^>{]*>)?\s*(?:where\b[^{]*)?\{", re.M)
+_RS_KINDS = {"fn": "function", "static": "var", "const": "const", "mod": "module"}
+_JS_KINDS = {"function": "function", "class": "class", "interface": "interface", "type": "type",
+             "enum": "enum", "namespace": "namespace"}
+
+
+def api_surface(
+    project_path: str,
+    language: str = "python",
+    packages: list[str] | None = None,
+    include_internal: bool = False,
+) -> dict:
+    """List the public API of a project.
+
+    Args:
+        project_path: Project root
+        language: Project language
+        packages: Only these packages (symbol-ID package components, e.g.
+            ``internal/tools`` or ``intermap.analyze``)
+        include_internal: Go only: also list ``internal/`` and main packages
+
+    Returns:
+        Dict with language, packages ({package, symbols}, sorted) where each
+        symbol is {name, kind, file, line, signature, id} plus members for
+        Go structs and interfaces, and count.
+    """
+    project = Path(project_path).resolve()
+    by_package: dict[str, list[dict]] = {}
+    for path in _scan_project_files(str(project), language=language):
+        rel = Path(path).relative_to(project).as_posix()
+        if is_test_file(rel) or not _public_file(rel, language, include_internal):
+            continue
+        package = package_path(rel, language)
+        if packages and package not in packages:
+            continue
+        try:
+            source = Path(path).read_text(encoding="utf-8", errors="replace")
+        except OSError:
+            continue
+        if language == "go" and not include_internal and re.search(r"^package\s+main\b", source, re.M):
+            continue
+        for sym in _public_symbols(source, language):
+            sym["file"] = rel
+            sym["signature"] = _WS.sub(" ", sym["signature"]).strip()
+            sym["id"] = make_symbol_id(package, sym["name"], " ".join([sym["signature"], *sym.get("members", ())]))
+            by_package.setdefault(package, []).append(sym)
+
+    out = []
+    for package in sorted(by_package):
+        symbols = sorted(by_package[package], key=lambda s: (s["name"], s["file"], s["line"]))
+        out.append({"package": package, "symbols": [_ordered(s) for s in symbols]})
+    return {
+        "language": language,
+        "packages": out,
+        "count": sum(len(p["symbols"]) for p in out),
+    }
+
+
+def _ordered(sym: dict) -> dict:
+    keys = ("name", "kind", "file", "line", "signature", "id", "members")
+    return {k: sym[k] for k in keys if k in sym}
+
+
+def _public_file(rel: str, language: str, include_internal: bool) -> bool:
+    parts = rel.split("/")
+    if language == "python":
+        return not any(p.startswith("_") and p != "__init__.py" for p in parts)
+    if language == "go" and not include_internal:
+        return "internal" not in parts[:-1]
+    return True
+
+
+def _public_symbols(source: str, language: str) -> list[dict]:
+    if language == "python":
+        return _python_symbols(source)
+    code = _blank(source, language)
+    if language == "go":
+        return _go_symbols(source, code)
+    if language in ("typescript", "javascript"):
+        return _js_symbols(source, code)
+    if language == "rust":
+        return _rust_symbols(source, code)
+    return []
+
+
+def _line_of(code: str, offset: int) -> int:
+    return code.count("\n", 0, offset) + 1
+
+
+def _header(source: str, code: str, start: int, stops: str = "{;") -> tuple[str, int]:
+    """Declaration text from start up to the first depth-0 stop character
+    (or the end of a depth-0 line), and the index where it stopped."""
+    depth = 0
+    i = start
+    while i < len(code):
+        ch = code[i]
+        if ch in "([":
+            depth += 1
+        elif ch in ")]":
+            depth -= 1
+        elif depth == 0 and (ch in stops or ch == "\n" and code[start:i].strip() and not _continues(code, i)):
+            break
+        i += 1
+    return source[start:i], i
+
+
+def _continues(code: str, newline: int) -> bool:
+    """Whether the declaration line ending at newline carries on below."""
+    before = code[:newline].rstrip()
+    after = code[newline:].lstrip()
+    return before.endswith((",", "=", "=>", "->", ":", "|", "&")) or after.startswith(("=>", "->", ":", "|", "&", "where"))
+
+
+# --- Python ---------------------------------------------------------------
+
+
+def _python_symbols(source: str) -> list[dict]:
+    try:
+        tree = ast.parse(source)
+    except SyntaxError:
+        return []
+    exported = _dunder_all(tree)
+
+    def public(name: str) -> bool:
+        return name in exported if exported is not None else not name.startswith("_")
+
+    out = []
+    for node in tree.body:
+        if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)) and public(node.name):
+            out.append({"name": node.name, "kind": "function", "line": node.lineno, "signature": _py_def(node)})
+        elif isinstance(node, ast.ClassDef) and public(node.name):
+            bases = ", ".join(ast.unparse(b) for b in node.bases + node.keywords)
+            out.append({
+                "name": node.name, "kind": "class", "line": node.lineno,
+                "signature": f"class {node.name}({bases})" if bases else f"class {node.name}",
+            })
+            for item in node.body:
+                if isinstance(item, (ast.FunctionDef, ast.AsyncFunctionDef)) and not item.name.startswith("_"):
+                    out.append({
+                        "name": f"{node.name}.{item.name}", "kind": "method",
+                        "line": item.lineno, "signature": _py_def(item),
+                    })
+        elif isinstance(node, (ast.Assign, ast.AnnAssign)):
+            targets = node.targets if isinstance(node, ast.Assign) else [node.target]
+            for t in targets:
+                if isinstance(t, ast.Name) and t.id != "__all__" and public(t.id):
+                    sig = t.id
+                    if isinstance(node, ast.AnnAssign):
+                        sig += f": {ast.unparse(node.annotation)}"
+                    out.append({"name": t.id, "kind": "variable", "line": node.lineno, "signature": sig})
+    return out
+
+
+def _dunder_all(tree: ast.Module) -> set[str] | None:
+    """Names in a literal module-level __all__, or None when there is none."""
+    for node in tree.body:
+        if isinstance(node, ast.Assign) and any(isinstance(t, ast.Name) and t.id == "__all__" for t in node.targets):
+            try:
+                return set(ast.literal_eval(node.value))
+            except (ValueError, TypeError):
+                return None
+    return None
+
+
+def _py_def(node) -> str:
+    prefix = "async def" if isinstance(node, ast.AsyncFunctionDef) else "def"
+    sig = f"{prefix} {node.name}({ast.unparse(node.args)})"
+    if node.returns is not None:
+        sig += f" -> {ast.unparse(node.returns)}"
+    return sig
+
+
+# --- Go -------------------------------------------------------------------
+
+
+def _go_symbols(source: str, code: str) -> list[dict]:
+    out = []
+    for m in _GO_FUNC.finditer(code):
+        receiver, name = m.groups()
+        if receiver and not receiver[0].isupper():
+            continue
+        sig, _ = _header(source, code, m.start(), "{")
+        out.append({
+            "name": f"{receiver}.{name}" if receiver else name,
+            "kind": "method" if receiver else "function",
+            "line": _line_of(code, m.start()),
+            "signature": sig,
+        })
+    for m in _GO_TYPE.finditer(code):
+        sig, stop = _header(source, code, m.start(), "{")
+        sym = {"name": m.group(1), "kind": "type", "line": _line_of(code, m.start()), "signature": sig}
+        kind = re.search(r"\b(struct|interface)\s*$", code[m.start():stop])
+        if kind and stop < len(code) and code[stop] == "{":
+            sym["kind"] = kind.group(1)
+            sym["members"] = _go_members(source, code, stop)
+        out.append(sym)
+    for m in _GO_VALUE.finditer(code):
+        keyword, name = m.groups()
+        if name:
+            out.append({"name": name, "kind": keyword, "line": _line_of(code, m.start()),
+                        "signature": _code_line(source, code, m.start())})
+            continue
+        body_end = _close(code, m.end() - 1)
+        offset = m.end()
+        for text in code[m.end():body_end].split("\n"):
+            item = _GO_GROUP_ITEM.match(text)
+            if item:
+                out.append({"name": item.group(1), "kind": keyword, "line": _line_of(code, offset),
+                            "signature": f"{keyword} {_code_line(source, code, offset).strip()}"})
+            offset += len(text) + 1
+    return out
+
+
+def _go_members(source: str, code: str, brace: int) -> list[str]:
+    """Exported fields or methods declared directly inside a struct or
+    interface body, and embedded types."""
+    end = _close(code, brace)
+    members = []
+    offset = brace + 1
+    depth = 0
+    for text in code[brace + 1:end].split("\n"):
+        if depth == 0:
+            stripped = text.strip()
+            if stripped and (stripped[0].isupper() or stripped.startswith("*")):
+                members.append(_WS.sub(" ", _code_line(source, code, offset)).strip().rstrip(" {"))
+        depth += text.count("{") - text.count("}")
+        offset += len(text) + 1
+    return members
+
+
+def _code_line(source: str, code: str, start: int) -> str:
+    """Source from start to the end of its line, without a trailing comment."""
+    end = code.find("\n", start)
+    end = len(code) if end < 0 else end
+    # Comments are blanked in code, so its stripped length ends the statement.
+    return source[start:start + len(code[start:end].rstrip())]
+
+
+# --- TypeScript / JavaScript ------------------------------------------------
+
+
+def _js_symbols(source: str, code: str) -> list[dict]:
+    out = []
+    for m in _JS_DECL.finditer(code):
+        keyword, name = m.groups()
+        keyword = keyword.split()[-1].rstrip("*") if keyword.startswith("async") else keyword.rstrip("*")
+        if keyword in ("const", "let", "var"):
+            rest = code[m.end():m.end() + 200]
+            arrow = re.match(r"\s*(?::[^=]*)?=\s*(?:async\s*)?(?:\([^)]*\)|\w+)\s*(?::[^=]*)?=>", rest)
+            kind = "function" if arrow else "variable"
+            if arrow:
+                sig = source[m.start():m.end() + arrow.end()]
+            else:
+                sig, _ = _header(source, code, m.start(), "=;")
+        else:
+            kind = _JS_KINDS[keyword]
+            sig, _ = _header(source, code, m.start(), ";" if keyword == "type" else "{;")
+        out.append({"name": name, "kind": kind, "line": _line_of(code, m.start()), "signature": sig})
+    for m in _JS_LIST.finditer(code):
+        for item in m.group(1).split(","):
+            local, _, alias = item.strip().partition(" as ")
+            name = (alias or local).strip()
+            if name:
+                out.append({"name": name, "kind": "export", "line": _line_of(code, m.start()),
+                            "signature": f"export {{ {item.strip()} }}"})
+    return out
+
+
+# --- Rust -----------------------------------------------------------------
+
+
+def _rust_symbols(source: str, code: str) -> list[dict]:
+    # Inherent impl blocks; trait impls ("impl T for U") never match, and
+    # their methods are not declared pub anyway.
+    impls = [(m.end() - 1, _close(code, m.end() - 1), m.group(1).rsplit("::", 1)[-1]) for m in _RS_IMPL.finditer(code)]
+    out = []
+    for m in _RS_DECL.finditer(code):
+        keyword, name = m.groups()
+        owner = next((t for start, end, t in impls if start < m.start() < end), "")
+        if owner:
+            name = f"{owner}.{name}"
+        start = m.start() + len(code[m.start():m.end()]) - len(code[m.start():m.end()].lstrip())
+        sig, _ = _header(source, code, start, "{;=" if keyword in ("const", "static", "type") else "{;")
+        out.append({
+            "name": name,
+            "kind": "method" if owner else _RS_KINDS.get(keyword, keyword),
+            "line": _line_of(code, m.start()),
+            "signature": sig,
+        })
+    return out
@@ -0,0 +1,188 @@
+"""Benchmark impact: which benchmarks exercise changed code.
+
+Benchmarks are Go ``Benchmark*`` functions in ``_test.go`` files and
+pytest-benchmark tests (test functions taking the ``benchmark`` fixture).
+
+A benchmark is affected when its file changed, or when its body references a
+changed function or any transitive caller of one (up to ``max_depth`` in the
+project call graph). Matching benchmark bodies by name keeps same-package Go
+calls visible even where the call graph only records cross-file edges.
+"""
+
+import ast
+import logging
+import re
+import shlex
+from collections import deque
+from pathlib import Path
+
+from .analysis import FunctionRef, build_reverse_graph
+from .change_impact import _scan_project_files, get_changed_functions, get_git_changed_files, is_test_file
+
+logger = logging.getLogger(__name__)
+
+_GO_BENCH = re.compile(r"^func\s+(Benchmark\w*)\s*\(\s*\w+\s+\*testing\.B\s*\)", re.MULTILINE)
+_CALLED_NAME = re.compile(r"\b([A-Za-z_]\w*)\s*\(")
+
+
+def analyze_bench_impact(
+    project_path: str,
+    files: list[str] | None = None,
+    use_git: bool = True,
+    git_base: str = "HEAD~1",
+    language: str = "python",
+    max_depth: int = 5,
+) -> dict:
+    """Find benchmarks affected by changed files.
+
+    Returns {"changed_files", "changed_functions", "benchmarks",
+    "benchmark_count", "commands", "command_lines"}; each benchmark is
+    {"file", "function", "via"} where via lists the affected names it calls.
+    """
+    project = Path(project_path).resolve()
+    if files:
+        changed_files = files
+    elif use_git:
+        changed_files = get_git_changed_files(str(project), git_base)
+    else:
+        changed_files = []
+
+    changed_refs = set()
+    for f in changed_files:
+        abs_path = project / f
+        if abs_path.exists():
+            for fn in get_changed_functions(str(abs_path), language=language):
+                changed_refs.add(FunctionRef(file=f, name=fn["name"]))
+
+    affected_names = {r.name for r in changed_refs} | _transitive_callers(project, language, changed_refs, max_depth)
+
+    benchmarks = []
+    changed_set = {Path(f).as_posix() for f in changed_files}
+    for rel, name, called in _find_benchmarks(project, language):
+        via = sorted((called & affected_names) - {name})
+        if rel in changed_set or via:
+            benchmarks.append({"file": rel, "function": name, "via": via})
+    benchmarks.sort(key=lambda b: (b["file"], b["function"]))
+
+    commands = _bench_commands(benchmarks, language)
+    return {
+        "changed_files": changed_files,
+        "changed_functions": sorted({r.name for r in changed_refs}),
+        "benchmarks": benchmarks,
+        "benchmark_count": len(benchmarks),
+        "commands": commands,
+        "command_lines": [shlex.join(c) for c in commands],
+    }
+
+
+def _transitive_callers(project: Path, language: str, seeds: set, max_depth: int) -> set[str]:
+    """Names of functions that reach any seed within max_depth calls."""
+    if not seeds:
+        return set()
+    from .cross_file_calls import build_project_call_graph
+
+    try:
+        graph = build_project_call_graph(str(project), language=language)
+    except Exception as e:
+        logger.debug("bench_impact: call graph failed: %s", e)
+        return set()
+    reverse = build_reverse_graph(graph.edges)
+
+    # Match seeds by name as well: the extractor and call graph may disagree
+    # on file path spelling.
+    seed_names = {s.name for s in seeds}
+    frontier = deque((ref, 0) for ref in reverse if ref.name in seed_names)
+    seen = {ref for ref, _ in frontier}
+    names = set()
+    while frontier:
+        ref, depth = frontier.popleft()
+        if depth >= max_depth:
+            continue
+        for caller in reverse.get(ref, []):
+            if caller not in seen:
+                seen.add(caller)
+                names.add(caller.name)
+                frontier.append((caller, depth + 1))
+    return names
+
+
+def _find_benchmarks(project: Path, language: str):
+    """Yield (rel_path, benchmark_name, names_called_in_body)."""
+    for path in _scan_project_files(str(project), language=language):
+        p = Path(path)
+        rel = p.relative_to(project).as_posix()
+        if language == "go":
+            if rel.endswith("_test.go"):
+                yield from _go_benchmarks(p, rel)
+        elif language == "python":
+            if is_test_file(rel):
+                yield from _pytest_benchmarks(p, rel)
+
+
+def _go_benchmarks(path: Path, rel: str):
+    try:
+        src = path.read_text(encoding="utf-8", errors="replace")
+    except OSError:
+        return
+    for m in _GO_BENCH.finditer(src):
+        body = _brace_body(src, m.end())
+        yield rel, m.group(1), set(_CALLED_NAME.findall(body))
+
+
+def _brace_body(src: str, start: int) -> str:
+    """Return the text of the brace-delimited block opening at/after start."""
+    open_at = src.find("{", start)
+    if open_at < 0:
+        return ""
+    depth = 0
+    for i in range(open_at, len(src)):
+        if src[i] == "{":
+            depth += 1
+        elif src[i] == "}":
+            depth -= 1
+            if depth == 0:
+                return src[open_at:i + 1]
+    return src[open_at:]
+
+
+def _pytest_benchmarks(path: Path, rel: str):
+    """Yield pytest-benchmark tests; methods are named "TestCls::test_x"."""
+    try:
+        tree = ast.parse(path.read_text(encoding="utf-8", errors="replace"))
+    except (OSError, SyntaxError):
+        return
+    candidates = []
+    for node in tree.body:
+        if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
+            candidates.append((node.name, node))
+        elif isinstance(node, ast.ClassDef) and node.name.startswith("Test"):
+            for item in node.body:
+                if isinstance(item, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                    candidates.append((f"{node.name}::{item.name}", item))
+    for name, fn in candidates:
+        if not fn.name.startswith("test") or "benchmark" not in {a.arg for a in fn.args.args}:
+            continue
+        called = set()
+        for sub in ast.walk(fn):
+            if isinstance(sub, ast.Name):
+                called.add(sub.id)
+            elif isinstance(sub, ast.Attribute):
+                called.add(sub.attr)
+        yield rel, name, called
+
+
+def _bench_commands(benchmarks: list[dict], language: str) -> list[list[str]]:
+    if not benchmarks:
+        return []
+    if language == "go":
+        by_pkg: dict[str, list[str]] = {}
+        for b in benchmarks:
+            by_pkg.setdefault(Path(b["file"]).parent.as_posix(), []).append(b["function"])
+        commands = []
+        for pkg in sorted(by_pkg):
+            local = "." if pkg == "." else f"./{pkg}"
+            names = sorted(set(by_pkg[pkg]))
+            commands.append(["go", "test", local, "-run", "^$", "-bench", "^(" + "|".join(names) + ")$"])
+        return commands
+    node_ids = [f"{b['file']}::{b['function']}" for b in benchmarks]
+    return [["pytest", "--benchmark-only", *node_ids]]
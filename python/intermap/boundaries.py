@@ -0,0 +1,337 @@
+"""Cross-language call boundaries between and within projects.
+
+Import analysis stops where one language hands off to another. scan_boundaries()
+reads the source files of every project under a root and reports, per call
+site:
+
+- "cgo": a Go file with import "C"; its #cgo -I/-L flags and #include
+  paths are resolved, and one landing in another project names it;
+- "python_extension": a Python import of a module some project builds as
+  a native extension (setuptools Extension(), CPython PyInit_, pybind11,
+  nanobind, PyO3 #[pymodule], or a Cython .pyx file);
+- "subprocess": subprocess/os.system, exec.Command, child_process, or
+  Command::new running a binary some project builds (see script_map);
+- "http": an http(s) URL literal whose host names a project, or whose
+  localhost port a project listens on;
+- "grpc": a generated client for a service declared in some project's
+  .proto files (NewFooClient, FooStub, new FooClient, FooClient::connect),
+  or a gRPC dial of an address resolved like an http URL.
+
+Matching is regex-based: commands and URLs built at runtime are not
+followed, and generated protobuf code is skipped.
+"""
+
+import os
+import re
+
+from .cross_project import _SKIP_DIRS, _discover_projects
+from .script_map import _project_binaries
+
+KINDS = ("cgo", "python_extension", "subprocess", "http", "grpc")
+
+# Largest file read.
+_MAX_FILE_BYTES = 1 << 20
+
+_GO_EXTS = {".go"}
+_PY_EXTS = {".py"}
+_JS_EXTS = {".js", ".mjs", ".cjs", ".ts", ".tsx", ".jsx"}
+_RUST_EXTS = {".rs"}
+_C_EXTS = {".c", ".cc", ".cpp", ".cxx", ".h", ".hpp"}
+_SOURCE_EXTS = _GO_EXTS | _PY_EXTS | _JS_EXTS | _RUST_EXTS | _C_EXTS | {".pyx", ".proto"}
+_GENERATED_SUFFIXES = (".pb.go", "_pb2.py", "_pb2_grpc.py", "_pb.js", "_pb.ts", "_grpc_pb.js")
+
+_LOCAL_HOSTS = {"localhost", "127.0.0.1", "0.0.0.0", "[::1]"}
+
+_LISTEN_RES = [
+    re.compile(r'\b(?:ListenAndServe(?:TLS)?|net\.Listen)\(\s*(?:"tcp"\s*,\s*)?"[^"]*:(\d+)"'),
+    re.compile(r'\bAddr:\s*"[^"]*:(\d+)"'),
+    re.compile(r'\.(?:run|serve)\([^)\n]*\bport\s*=\s*(\d+)'),
+    re.compile(r'\.listen\(\s*(\d+)'),
+    re.compile(r'\bbind\(\s*"[^"]*:(\d+)"'),
+]
+_EXPOSE_RE = re.compile(r"^\s*EXPOSE\s+(\d+)", re.M)
+_SERVICE_RE = re.compile(r"^\s*service\s+(\w+)\s*\{", re.M)
+
+_EXTENSION_RES = [
+    ("setuptools", re.compile(r'\bExtension\(\s*(?:name\s*=\s*)?["\']([\w.]+)["\']')),
+    ("cpython", re.compile(r"\bPyInit_(\w+)\s*\(")),
+    ("pybind11", re.compile(r"\bPYBIND11_MODULE\(\s*(\w+)")),
+    ("nanobind", re.compile(r"\bNB_MODULE\(\s*(\w+)")),
+    ("pyo3", re.compile(r'#\[pymodule\]\s*(?:#\[pyo3\(name\s*=\s*"(\w+)"\)\]\s*)?(?:pub\s+)?fn\s+(\w+)')),
+]
+
+_SUBPROCESS_RES = {
+    "py": re.compile(
+        r"\b(?:subprocess\.(?:run|call|check_call|check_output|Popen)|os\.(?:system|popen)"
+        r"|asyncio\.create_subprocess_(?:exec|shell))\(\s*\[?\s*[rbf]?[\"']([^\"']+)[\"']"
+    ),
+    "go": re.compile(r'\bexec\.Command(?:Context)?\(\s*(?:[\w.]+\s*,\s*)?"([^"]+)"'),
+    "js": re.compile(r"\b(?:spawn|spawnSync|execFile|execFileSync|exec|execSync|execa)\(\s*['\"`]([^'\"`]+)['\"`]"),
+    "rs": re.compile(r'\bCommand::new\(\s*"([^"]+)"'),
+}
+
+_STUB_RES = {
+    "py": re.compile(r"(?<!class )\b(\w+)Stub\("),
+    "go": re.compile(r"(?<!func )\bNew(\w+)Client\("),
+    "js": re.compile(r"\bnew\s+(?:\w+\.)?(\w+)Client\("),
+    "rs": re.compile(r"\b(\w+)Client::(?:connect|new)\("),
+}
+
+_DIAL_RES = {
+    "py": re.compile(r"\bgrpc\.(?:aio\.)?(?:insecure|secure)_channel\(\s*[\"']([^\"']+)[\"']"),
+    "go": re.compile(r'\bgrpc\.(?:Dial|DialContext|NewClient)\(\s*(?:\w+\s*,\s*)?"([^"]+)"'),
+}
+
+_URL_RE = re.compile(r"[\"'`](https?)://([A-Za-z0-9_.-]+|\[::1\])(?::(\d+))?(/[^\"'`\s]*)?[\"'`]")
+_CGO_IMPORT_RE = re.compile(r'^import\s+"C"', re.M)
+_CGO_PATH_RE = re.compile(r'^[ \t]*(?://\s*)?#(?:cgo\b[^:\n]*:.*?-[IL]\s*(\S+)|include\s+"([^"]+)")', re.M)
+_CGO_LIB_RE = re.compile(r"^[ \t]*(?://\s*)?#cgo\b[^:\n]*:.*$", re.M)
+_PY_IMPORT_RE = re.compile(
+    r"^[ \t]*(?:from\s+([\w.]+)\s+import\s+(?:\(([\w\s,]+)\)|([\w \t,]+))|import\s+([\w.]+))", re.M
+)
+
+
+def scan_boundaries(
+    root: str,
+    max_depth: int = 4,
+    projects: list[dict] | None = None,
+    kinds: list[str] | None = None,
+) -> dict:
+    """Scan the projects under root for cross-language call boundaries.
+
+    Args:
+        root: Workspace or project root
+        max_depth: Directory levels below root searched for projects
+        projects: Already discovered projects ({name, path}), instead of
+            searching again
+        kinds: Only report these boundary kinds (default: all of KINDS)
+
+    Returns:
+        Dict with boundaries ({file, project, line, kind, target,
+        to_project}, to_project empty when unresolved), the extensions,
+        services, and ports they were resolved against, and per-kind counts.
+    """
+    root = os.path.abspath(root)
+    projects = list(projects) if projects is not None else _discover_projects(root, max_depth)
+    if not projects:
+        projects = [{"name": os.path.basename(root), "path": root, "group": ""}]
+    wanted = set(kinds or KINDS)
+    unknown = wanted - set(KINDS)
+    if unknown:
+        raise ValueError(f"unknown boundary kinds: {', '.join(sorted(unknown))}")
+
+    def owner(path: str) -> str:
+        best, best_len = "", -1
+        for p in projects:
+            if (path == p["path"] or path.startswith(p["path"] + os.sep)) and len(p["path"]) > best_len:
+                best, best_len = p["name"], len(p["path"])
+        return best
+
+    names = {p["name"] for p in projects}
+    binaries = _project_binaries(projects)
+    files = list(_iter_sources(root, [p["path"] for p in projects]))
+
+    # First pass: what each project provides to the others.
+    extensions: list[dict] = []
+    services: dict[str, str] = {}
+    ports: dict[str, str] = {}
+    for path, text in _read_all(files):
+        project = owner(path)
+        rel = os.path.relpath(path, root).replace(os.sep, "/")
+        for module, binding in _extension_modules(path, text):
+            extensions.append({"module": module, "binding": binding, "file": rel, "project": project})
+        if path.endswith(".proto"):
+            for m in _SERVICE_RE.finditer(text):
+                services.setdefault(m.group(1), project)
+        for regex in _LISTEN_RES + ([_EXPOSE_RE] if os.path.basename(path).startswith("Dockerfile") else []):
+            for m in regex.finditer(text):
+                ports.setdefault(m.group(1), project)
+    extension_owner: dict[str, str] = {}
+    for ext in extensions:
+        extension_owner.setdefault(ext["module"], ext["project"])
+
+    # Second pass: call sites.
+    boundaries: list[dict] = []
+    for path, text in _read_all(files):
+        if path.endswith(_GENERATED_SUFFIXES):
+            continue
+        lang = _language(path)
+        if lang is None:
+            continue
+        project = owner(path)
+        rel = os.path.relpath(path, root).replace(os.sep, "/")
+        found: list[tuple[int, str, str, str]] = []
+
+        if lang == "go" and "cgo" in wanted and _CGO_IMPORT_RE.search(text):
+            found.append(_cgo_boundary(path, text, root, owner))
+
+        if lang == "py" and "python_extension" in wanted and extension_owner:
+            for m in _PY_IMPORT_RE.finditer(text):
+                for module in _imported_modules(m):
+                    if module in extension_owner:
+                        found.append((_line(text, m.start()), "python_extension", module, extension_owner[module]))
+
+        if "subprocess" in wanted and lang in _SUBPROCESS_RES:
+            for m in _SUBPROCESS_RES[lang].finditer(text):
+                words = m.group(1).split()
+                binary = os.path.basename(words[0]) if words else ""
+                if binary in binaries:
+                    found.append((_line(text, m.start()), "subprocess", binary, binaries[binary]))
+
+        if "http" in wanted:
+            for m in _URL_RE.finditer(text):
+                target_project = _resolve_host(m.group(2), m.group(3), names, ports)
+                if target_project:
+                    url = m.group(0)[1:-1]
+                    found.append((_line(text, m.start()), "http", url, target_project))
+
+        if "grpc" in wanted:
+            if lang in _STUB_RES and services:
+                for m in _STUB_RES[lang].finditer(text):
+                    if m.group(1) in services:
+                        found.append((_line(text, m.start()), "grpc", m.group(1), services[m.group(1)]))
+            if lang in _DIAL_RES:
+                for m in _DIAL_RES[lang].finditer(text):
+                    host, _, port = m.group(1).rpartition(":")
+                    target_project = _resolve_host(host or m.group(1), port or None, names, ports)
+                    if target_project:
+                        found.append((_line(text, m.start()), "grpc", m.group(1), target_project))
+
+        seen = set()
+        for line, kind, target, to_project in found:
+            if (line, kind, target) in seen:
+                continue
+            seen.add((line, kind, target))
+            boundaries.append({
+                "file": rel,
+                "project": project,
+                "line": line,
+                "kind": kind,
+                "target": target,
+                "to_project": to_project,
+            })
+
+    boundaries.sort(key=lambda b: (b["file"], b["line"], b["kind"], b["target"]))
+    by_kind = {k: 0 for k in KINDS if k in wanted}
+    for b in boundaries:
+        by_kind[b["kind"]] += 1
+    return {
+        "root": root,
+        "boundaries": boundaries,
+        "extensions": extensions,
+        "services": dict(sorted(services.items())),
+        "ports": dict(sorted(ports.items(), key=lambda kv: int(kv[0]))),
+        "by_kind": by_kind,
+        "total_boundaries": len(boundaries),
+    }
+
+
+def _iter_sources(root: str, project_paths: list[str]):
+    """Yield source, proto, setup.py, and Dockerfile paths in the projects."""
+    def wanted(path: str) -> bool:
+        return any(
+            path == p or path.startswith(p + os.sep) or p.startswith(path + os.sep)
+            for p in project_paths
+        )
+
+    for dirpath, dirnames, filenames in os.walk(root):
+        dirnames[:] = sorted(
+            d for d in dirnames
+            if d not in _SKIP_DIRS and not d.startswith(".") and wanted(os.path.join(dirpath, d))
+        )
+        if not any(dirpath == p or dirpath.startswith(p + os.sep) for p in project_paths):
+            continue
+        for name in sorted(filenames):
+            if os.path.splitext(name)[1] in _SOURCE_EXTS or name.startswith("Dockerfile"):
+                yield os.path.join(dirpath, name)
+
+
+def _read_all(paths: list[str]):
+    """Yield (path, text) for each readable file under the size limit."""
+    for path in paths:
+        try:
+            if os.path.getsize(path) > _MAX_FILE_BYTES:
+                continue
+            with open(path, encoding="utf-8", errors="replace") as f:
+                yield path, f.read()
+        except OSError:
+            continue
+
+
+def _language(path: str) -> str | None:
+    ext = os.path.splitext(path)[1]
+    if ext in _GO_EXTS:
+        return "go"
+    if ext in _PY_EXTS:
+        return "py"
+    if ext in _JS_EXTS:
+        return "js"
+    if ext in _RUST_EXTS:
+        return "rs"
+    return None
+
+
+def _line(text: str, offset: int) -> int:
+    return text.count("\n", 0, offset) + 1
+
+
+def _resolve_host(host: str, port: str | None, names: set[str], ports: dict[str, str]) -> str:
+    """The project a host names: a local host by the port a project
+    listens on, any other by its first label as a project name."""
+    if host in _LOCAL_HOSTS:
+        return ports.get(port or "", "")
+    label = host.split(".", 1)[0]
+    for candidate in (label, label.replace("_", "-"), label.replace("-", "_")):
+        if candidate in names:
+            return candidate
+    return ""
+
+
+def _extension_modules(path: str, text: str) -> list[tuple[str, str]]:
+    """(module, binding) for native extension modules a file defines."""
+    name = os.path.basename(path)
+    ext = os.path.splitext(name)[1]
+    if ext == ".pyx":
+        return [(os.path.splitext(name)[0], "cython")]
+    modules = []
+    for binding, regex in _EXTENSION_RES:
+        if binding == "setuptools" and name != "setup.py":
+            continue
+        if binding in ("cpython", "pybind11", "nanobind") and ext not in _C_EXTS:
+            continue
+        if binding == "pyo3" and ext not in _RUST_EXTS:
+            continue
+        for m in regex.finditer(text):
+            module = next(g for g in m.groups() if g)
+            modules.append((module.rsplit(".", 1)[-1], binding))
+    return modules
+
+
+def _imported_modules(m: re.Match) -> list[str]:
+    """Module names an import statement may load: the last component of
+    the imported module, plus the names a from-import pulls in."""
+    if m.group(4):
+        return [m.group(4).rsplit(".", 1)[-1]]
+    names = [m.group(1).rsplit(".", 1)[-1]]
+    for part in (m.group(2) or m.group(3)).split(","):
+        word = part.split()[0] if part.split() else ""
+        if word.isidentifier():
+            names.append(word)
+    return names
+
+
+def _cgo_boundary(path: str, text: str, root: str, owner) -> tuple[int, str, str, str]:
+    """The cgo boundary of a Go file: a #cgo/#include path in another
+    project if there is one, otherwise the linked libraries or "C"."""
+    base = os.path.dirname(path)
+    here = owner(path)
+    for m in _CGO_PATH_RE.finditer(text):
+        raw = (m.group(1) or m.group(2)).replace("${SRCDIR}", base)
+        target = os.path.normpath(os.path.join(base, raw))
+        if os.path.exists(target):
+            project = owner(target)
+            if project and project != here:
+                return _line(text, m.start()), "cgo", os.path.relpath(target, root).replace(os.sep, "/"), project
+    libs = sorted({lib for m in _CGO_LIB_RE.finditer(text) for lib in re.findall(r"-l(\w+)", m.group(0))})
+    line = _line(text, _CGO_IMPORT_RE.search(text).start())
+    return line, "cgo", " ".join(f"-l{lib}" for lib in libs) or "C", ""
@@ -0,0 +1,215 @@
+"""Go build context: GOOS, GOARCH, and build tags for Go analysis.
+
+Without a build context every ``.go`` file is scanned, so a function defined
+once per platform (``open_linux.go``, ``open_windows.go``) shows up as
+several definitions and the call graph joins code that never builds
+together. When a context is active, ``iter_workspace_files`` skips Go files
+the go command would exclude for it, using the same rules:
+
+- file name suffixes ``_GOOS``, ``_GOARCH``, and ``_GOOS_GOARCH`` (before
+  an optional ``_test``), ignoring the part before the first underscore
+- ``//go:build`` expressions, or legacy ``// +build`` lines when there is
+  no ``//go:build`` line, in the header before the package clause
+
+Tags satisfied are GOOS and GOARCH, ``unix`` for Unix systems, the OS a
+GOOS implies (android implies linux, ios implies darwin, illumos implies
+solaris), every ``go1.N`` release tag, ``gc``, and the extra tags given.
+"""
+
+from __future__ import annotations
+
+import os
+import re
+from contextlib import contextmanager
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Iterator
+
+KNOWN_OS = {
+    "aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js",
+    "linux", "nacl", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos",
+}
+KNOWN_ARCH = {
+    "386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be", "loong64", "mips",
+    "mipsle", "mips64", "mips64le", "mips64p32", "mips64p32le", "ppc", "ppc64", "ppc64le",
+    "riscv", "riscv64", "s390", "s390x", "sparc", "sparc64", "wasm",
+}
+UNIX_OS = {
+    "aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios",
+    "linux", "netbsd", "openbsd", "solaris",
+}
+_IMPLIED_OS = {"android": "linux", "ios": "darwin", "illumos": "solaris"}
+
+_RELEASE_TAG = re.compile(r"go1\.\d+$")
+_TOKEN = re.compile(r"\s*(\|\||&&|!|\(|\)|[\w.]+)")
+
+_ACTIVE: "BuildContext | None" = None
+
+
+@dataclass(frozen=True)
+class BuildContext:
+    goos: str
+    goarch: str
+    tags: tuple[str, ...] = ()
+
+    def satisfied(self, tag: str) -> bool:
+        """Whether a single build tag holds in this context."""
+        if tag in (self.goos, self.goarch) or tag in self.tags:
+            return True
+        if tag == "unix":
+            return self.goos in UNIX_OS
+        if tag == "gc":
+            return True
+        return _IMPLIED_OS.get(self.goos) == tag or bool(_RELEASE_TAG.match(tag))
+
+    def matches_name(self, filename: str) -> bool:
+        """Apply the go command's _GOOS/_GOARCH file name rules."""
+        name = filename[:-3] if filename.endswith(".go") else filename
+        if name.endswith("_test"):
+            name = name[: -len("_test")]
+        i = name.find("_")
+        if i < 0:
+            return True
+        parts = name[i:].split("_")
+        if len(parts) >= 2 and parts[-2] in KNOWN_OS and parts[-1] in KNOWN_ARCH:
+            return self.satisfied(parts[-2]) and parts[-1] == self.goarch
+        if parts[-1] in KNOWN_OS:
+            return self.satisfied(parts[-1])
+        if parts[-1] in KNOWN_ARCH:
+            return parts[-1] == self.goarch
+        return True
+
+    def matches(self, path: str | Path) -> bool:
+        """Whether the go command would build path in this context."""
+        path = Path(path)
+        if not self.matches_name(path.name):
+            return False
+        try:
+            text = path.read_text(errors="replace")
+        except OSError:
+            return True
+        return self.matches_header(text)
+
+    def matches_header(self, text: str) -> bool:
+        """Evaluate the build constraints in a Go file's header."""
+        go_build = None
+        plus_build = []
+        in_block = False
+        for line in text.splitlines():
+            line = line.strip()
+            if in_block:
+                in_block = "*/" not in line
+                continue
+            if line.startswith("/*"):
+                in_block = "*/" not in line
+                continue
+            if line.startswith("//go:build"):
+                go_build = line[len("//go:build"):]
+            elif line.startswith("// +build"):
+                plus_build.append(line[len("// +build"):])
+            elif line and not line.startswith("//"):
+                break
+        if go_build is not None:
+            return _Expr(go_build, self).value()
+        return all(self._plus_build(line) for line in plus_build)
+
+    def _plus_build(self, line: str) -> bool:
+        # Space-separated options are ORed, comma-separated terms ANDed.
+        return any(
+            all(self.satisfied(t.lstrip("!")) != t.startswith("!") for t in option.split(","))
+            for option in line.split()
+        )
+
+    def describe(self, project: str | Path) -> dict:
+        """Summarize the context with the project's excluded Go files."""
+        return {
+            "goos": self.goos,
+            "goarch": self.goarch,
+            "tags": list(self.tags),
+            "excluded": excluded_files(project, self),
+        }
+
+
+class _Expr:
+    """Recursive-descent evaluator for //go:build expressions."""
+
+    def __init__(self, text: str, ctx: BuildContext):
+        self.tokens = _TOKEN.findall(text)
+        self.pos = 0
+        self.ctx = ctx
+
+    def value(self) -> bool:
+        try:
+            result = self._or()
+        except IndexError:
+            return True
+        # Malformed constraints are ignored, as go vet reports them instead.
+        return result if self.pos == len(self.tokens) else True
+
+    def _next(self) -> str:
+        tok = self.tokens[self.pos]
+        self.pos += 1
+        return tok
+
+    def _peek(self) -> str:
+        return self.tokens[self.pos] if self.pos < len(self.tokens) else ""
+
+    def _or(self) -> bool:
+        result = self._and()
+        while self._peek() == "||":
+            self._next()
+            result = self._and() or result
+        return result
+
+    def _and(self) -> bool:
+        result = self._not()
+        while self._peek() == "&&":
+            self._next()
+            result = self._not() and result
+        return result
+
+    def _not(self) -> bool:
+        tok = self._next()
+        if tok == "!":
+            return not self._not()
+        if tok == "(":
+            result = self._or()
+            if self._next() != ")":
+                raise IndexError
+            return result
+        return self.ctx.satisfied(tok)
+
+
+def from_args(value) -> BuildContext | None:
+    """Build a context from the bridge's go_build argument."""
+    if not isinstance(value, dict) or not value.get("goos") or not value.get("goarch"):
+        return None
+    return BuildContext(value["goos"], value["goarch"], tuple(sorted(set(value.get("tags") or ()))))
+
+
+def active() -> BuildContext | None:
+    """The context applied to Go file enumeration, if any."""
+    return _ACTIVE
+
+
+@contextmanager
+def using(ctx: BuildContext | None) -> Iterator[None]:
+    """Apply ctx to Go file enumeration for the duration of the block."""
+    global _ACTIVE
+    prev, _ACTIVE = _ACTIVE, ctx
+    try:
+        yield
+    finally:
+        _ACTIVE = prev
+
+
+def excluded_files(project: str | Path, ctx: BuildContext) -> list[str]:
+    """Project-relative Go files that ctx excludes."""
+    from .workspace import iter_workspace_files
+
+    root = Path(project).resolve()
+    with using(None):
+        files = list(iter_workspace_files(root, extensions={".go"}))
+    return sorted(
+        os.path.relpath(f, root).replace(os.sep, "/") for f in files if not ctx.matches(f)
+    )
@@ -2,16 +2,18 @@
 Change Impact Analysis.
 
 Determines which tests to run based on changed files.
-Uses explicit file list or git diff.
+Uses explicit file list or the project's VCS diff (git, jj, or hg).
 """
 
 import logging
 import re
-import subprocess
 from pathlib import Path
 
+from . import vcs
 from .analysis import analyze_impact
 from .extractors import DefaultExtractor
+from .history import load_history, order_tests
+from .runners import format_tests
 from .workspace import iter_workspace_files
 
 logger = logging.getLogger(__name__)
@@ -57,6 +59,7 @@ def is_test_file(file_path: str) -> bool:
         r".*\.test\.[jt]sx?$", # foo.test.js, foo.test.tsx
         r".*\.spec\.[jt]sx?$", # foo.spec.js, foo.spec.tsx
         r"^test_.*\.[jt]s$",   # test_foo.js
+        r".*_test\.go$",       # foo_test.go
     ]
 
     for pattern in patterns:
@@ -289,29 +292,16 @@ def find_affected_tests(
 
 def get_git_changed_files(project_path: str, base: str = "HEAD~1") -> list[str]:
     """
-    Get list of changed files from git diff.
+    Get list of changed files from the project's VCS (git, jj, or hg).
 
     Args:
         project_path: Project root
-        base: Git ref to diff against (default: HEAD~1)
+        base: Ref to diff against (default: HEAD~1, translated for jj and hg)
 
     Returns:
-        List of changed file paths (relative to project)
+        List of changed file paths (relative to project, limited to it)
     """
-    try:
-        result = subprocess.run(
-            ["git", "diff", "--name-only", base],
-            capture_output=True,
-            text=True,
-            cwd=project_path,
-            timeout=10,
-        )
-        if result.returncode == 0:
-            files = [f.strip() for f in result.stdout.strip().split("\n") if f.strip()]
-            return files
-    except Exception as e:
-        logger.debug(f"Failed to get git changed files: {e}")
-    return []
+    return vcs.changed_files(project_path, base)
 
 
 def analyze_change_impact(
@@ -321,6 +311,8 @@ def analyze_change_impact(
     git_base: str = "HEAD~1",
     language: str = "python",
     max_depth: int = 5,
+    output: str | None = None,
+    test_history: str | None = None,
     **_kwargs,
 ) -> dict:
     """
@@ -333,6 +325,10 @@ def analyze_change_impact(
         git_base: Git ref to diff against (default: HEAD~1)
         language: Programming language
         max_depth: Max depth for call graph traversal
+        output: Runner to format selected tests for ("pytest", "gotest",
+            "jest"); adds a "test_selection" entry to the result
+        test_history: JUnit XML file/directory or JSON ledger of past runs;
+            adds "test_metadata" (duration, flakiness) and "suggested_order"
 
     Returns:
         Dict with affected tests and metadata
@@ -351,14 +347,22 @@ def analyze_change_impact(
         source = "explicit"
     elif use_git:
         changed_files = get_git_changed_files(str(project), git_base)
-        source = f"git:{git_base}"
+        source = f"{vcs.kind(str(project)) or vcs.GIT}:{git_base}"
     else:
-        # Default: try git
+        # Default: try the project's VCS
         changed_files = get_git_changed_files(str(project))
-        source = "git:HEAD~1" if changed_files else "none"
+        source = f"{vcs.kind(str(project)) or vcs.GIT}:HEAD~1" if changed_files else "none"
+
+    # A sparse checkout lacks files outside its patterns; analyzing them
+    # would only fail, so set them aside.
+    skipped_missing: list[str] = []
+    checkout = vcs.checkout_state(str(project))
+    if checkout and checkout["sparse"]:
+        skipped_missing = [f for f in changed_files if not (project / f).exists()]
+        changed_files = [f for f in changed_files if (project / f).exists()]
 
     if not changed_files:
-        return {
+        result = {
             "changed_files": [],
             "changed_functions": [],
             "affected_tests": [],
@@ -369,6 +373,12 @@ def analyze_change_impact(
             "source": source,
             "message": "No changed files detected",
         }
+        if skipped_missing:
+            result["skipped_missing"] = skipped_missing
+            result["message"] = "All changed files are outside the sparse checkout"
+        if output:
+            result["test_selection"] = format_tests(str(project), [], output)
+        return result
 
     # Filter to source files only (not tests, configs, etc.)
     source_extensions = {
@@ -394,5 +404,14 @@ def analyze_change_impact(
         max_depth=max_depth,
     )
     result["source"] = source
+    if skipped_missing:
+        result["skipped_missing"] = skipped_missing
+    if test_history:
+        metadata = load_history(test_history, str(project))
+        affected = result["affected_tests"]
+        result["test_metadata"] = {t: metadata[t] for t in affected if t in metadata}
+        result["suggested_order"] = order_tests(affected, metadata)
+    if output:
+        result["test_selection"] = format_tests(str(project), result["affected_tests"], output)
 
     return result
@@ -0,0 +1,348 @@
+"""Code search with regex, literal, and structural patterns.
+
+Every match carries the symbol that encloses it (name, kind, and stable
+symbol ID) and the project that owns the file, so results link straight
+into the rest of the code map.
+
+Structural patterns follow comby's template syntax:
+
+- ``:[name]`` matches any text with balanced (), [], and {} and does not
+  cross an unbalanced closing bracket; it may span lines. ``...`` and
+  ``:[_]`` are anonymous holes.
+- ``:[[name]]`` matches one identifier.
+- Whitespace in the pattern matches any run of whitespace, including none
+  next to punctuation.
+- A name used twice must bind the same text both times.
+
+Patterns must start and end with literal text or an identifier hole, so
+every match has a definite extent.
+"""
+
+from __future__ import annotations
+
+import bisect
+import fnmatch
+import os
+import re
+from pathlib import Path
+
+from .cross_project import _SKIP_DIRS, _discover_projects
+from .extractors import DefaultExtractor
+from .project_index import _compute_symbol_ranges
+from .symbol_ids import make_symbol_id, package_path, read_signature
+from .workspace import iter_workspace_files
+
+MODES = ("regex", "literal", "structural")
+
+_LANG_EXTS = {
+    "python": {".py"},
+    "go": {".go"},
+    "typescript": {".ts", ".tsx"},
+    "javascript": {".js", ".jsx", ".mjs", ".cjs"},
+    "rust": {".rs"},
+    "java": {".java"},
+    "c": {".c", ".h", ".cc", ".cpp", ".hpp"},
+    "ruby": {".rb"},
+    "shell": {".sh", ".bash"},
+}
+_EXT_LANG = {ext: lang for lang, exts in _LANG_EXTS.items() for ext in exts}
+
+_MAX_FILE_BYTES = 1 << 20
+_MAX_HOLE_CHARS = 10000
+_MAX_TEXT_CHARS = 500
+
+_HOLE = re.compile(r":\[\[(\w+)\]\]|:\[(\w*)\]|\.\.\.")
+_PIECE = re.compile(r"\s+|\w+|[^\w\s]")
+_OPEN = {"(": ")", "[": "]", "{": "}"}
+_CLOSE = set(_OPEN.values())
+
+
+def search_code(
+    path: str,
+    pattern: str,
+    mode: str = "regex",
+    language: str | None = None,
+    glob: str | None = None,
+    ignore_case: bool = False,
+    max_depth: int = 4,
+    max_results: int = 500,
+) -> dict:
+    """Search source files under path (a project or a workspace root).
+
+    Args:
+        path: Project or workspace root
+        pattern: Regex, literal text, or structural template
+        mode: "regex", "literal", or "structural"
+        language: Only files of this language (default all source files)
+        glob: Only files whose root-relative path matches this glob
+        ignore_case: Match case-insensitively
+        max_depth: Directory levels below path searched for projects
+        max_results: Stop after this many matches
+
+    Returns:
+        Dict with matches ({file, project, line, column, end_line, text,
+        symbol, holes}), count, files_searched, and truncated.
+    """
+    if mode not in MODES:
+        raise ValueError(f"unknown mode {mode!r} (want regex, literal, or structural)")
+    if language and language not in _LANG_EXTS:
+        raise ValueError(f"unknown language {language!r}")
+    if not pattern:
+        raise ValueError("pattern is required")
+
+    root = str(Path(path).resolve())
+    flags = re.MULTILINE | (re.IGNORECASE if ignore_case else 0)
+    if mode == "structural":
+        matcher = _Structural(pattern, flags)
+    else:
+        try:
+            regex = re.compile(re.escape(pattern) if mode == "literal" else pattern, flags)
+        except re.error as e:
+            raise ValueError(f"invalid regex: {e}") from e
+        matcher = None
+
+    projects = _discover_projects(root, max_depth) or [{"name": os.path.basename(root), "path": root}]
+    extensions = _LANG_EXTS[language] if language else set(_EXT_LANG)
+    extractor = DefaultExtractor()
+
+    matches = []
+    files_searched = 0
+    truncated = False
+    for file_path, rel, text in iter_sources(root, extensions):
+        if glob and not (fnmatch.fnmatch(rel, glob) or fnmatch.fnmatch(file_path.name, glob)):
+            continue
+        files_searched += 1
+
+        if matcher is not None:
+            found = matcher.finditer(text, quotes="\"`" if file_path.suffix == ".rs" else "\"'`")
+        else:
+            found = ((m.start(), m.end(), {}) for m in regex.finditer(text) if m.end() > m.start())
+
+        symbols = None
+        line_starts = None
+        for start, end, holes in found:
+            if len(matches) >= max_results:
+                truncated = True
+                break
+            if line_starts is None:
+                line_starts = [0] + [i + 1 for i, ch in enumerate(text) if ch == "\n"]
+                project = owner(projects, str(file_path))
+                symbols = file_symbols(extractor, file_path, project, len(line_starts))
+            line = bisect.bisect_right(line_starts, start)
+            end_line = bisect.bisect_right(line_starts, max(start, end - 1))
+            matches.append({
+                "file": rel,
+                "project": project["name"],
+                "line": line,
+                "column": start - line_starts[line - 1] + 1,
+                "end_line": end_line,
+                "text": text[start:end][:_MAX_TEXT_CHARS],
+                "symbol": _enclosing(symbols, line),
+                "holes": holes,
+            })
+        if truncated:
+            break
+
+    return {
+        "root": root,
+        "mode": mode,
+        "pattern": pattern,
+        "matches": matches,
+        "count": len(matches),
+        "files_searched": files_searched,
+        "truncated": truncated,
+    }
+
+
+def iter_sources(root: str, extensions: set[str] | None = None):
+    """Yield (path, root-relative posix path, text) for searchable files.
+
+    Vendored directories, files over 1 MiB, and binary files are skipped.
+    """
+    for file_path in iter_workspace_files(root, extensions=extensions or set(_EXT_LANG)):
+        rel = file_path.relative_to(root)
+        if any(part in _SKIP_DIRS for part in rel.parts[:-1]):
+            continue
+        text = _read_source(file_path)
+        if text is not None:
+            yield file_path, rel.as_posix(), text
+
+
+def _read_source(path: Path) -> str | None:
+    try:
+        if path.stat().st_size > _MAX_FILE_BYTES:
+            return None
+        data = path.read_bytes()
+    except OSError:
+        return None
+    if b"\0" in data[:8192]:
+        return None
+    return data.decode("utf-8", errors="replace")
+
+
+def owner(projects: list[dict], path: str) -> dict:
+    """Return the innermost project containing path."""
+    best = {"name": "", "path": ""}
+    for p in projects:
+        if (path == p["path"] or path.startswith(p["path"] + os.sep)) and len(p["path"]) > len(best["path"]):
+            best = p
+    return best
+
+
+def file_symbols(extractor: DefaultExtractor, file_path: Path, project: dict, total_lines: int) -> list[dict]:
+    """Return the file's symbols with line ranges and project-relative IDs."""
+    try:
+        info = extractor.extract(str(file_path))
+    except Exception:
+        return []
+    base = project["path"] or str(file_path.parent)
+    rel = os.path.relpath(file_path, base).replace(os.sep, "/")
+    language = _EXT_LANG.get(file_path.suffix, "")
+    classes = {c.name for c in info.classes}
+
+    symbols = []
+    for key, (start, end) in _compute_symbol_ranges(info, rel, total_lines).items():
+        name = key[len(rel) + 1:]
+        kind = "method" if "." in name else "class" if name in classes else "function"
+        symbols.append({
+            "name": name,
+            "kind": kind,
+            "line": start,
+            "end_line": end,
+            "id": make_symbol_id(package_path(rel, language), name, read_signature(file_path, start)),
+        })
+    return symbols
+
+
+def _enclosing(symbols: list[dict], line: int) -> dict | None:
+    """Return the innermost symbol whose range contains line."""
+    best = None
+    for s in symbols:
+        if s["line"] <= line <= s["end_line"] and (best is None or s["line"] > best["line"]):
+            best = s
+    if best is None:
+        return None
+    return {"name": best["name"], "kind": best["kind"], "line": best["line"], "id": best["id"]}
+
+
+class _Structural:
+    """A compiled structural template."""
+
+    def __init__(self, pattern: str, flags: int):
+        self.tokens = []  # ("lit", regex) | ("ident", name) | ("hole", name)
+        pos = 0
+        for m in _HOLE.finditer(pattern):
+            self._literal(pattern[pos:m.start()], flags)
+            if m.group(1):
+                self.tokens.append(("ident", m.group(1)))
+            else:
+                self.tokens.append(("hole", "" if m.group(2) in (None, "_") else m.group(2)))
+            pos = m.end()
+        self._literal(pattern[pos:], flags)
+        if not self.tokens or self.tokens[0][0] == "hole" or self.tokens[-1][0] == "hole":
+            raise ValueError("structural pattern must start and end with literal text or an identifier hole")
+        self.first = self.tokens[0][1] if self.tokens[0][0] == "lit" else _IDENT
+
+    def _literal(self, text: str, flags: int) -> None:
+        pieces = _PIECE.findall(text.strip())
+        if not pieces:
+            return
+        parts = []
+        for i, piece in enumerate(pieces):
+            if piece.isspace():
+                between_words = pieces[i - 1][-1].isalnum() and pieces[i + 1][0].isalnum()
+                parts.append(r"\s+" if between_words else r"\s*")
+            else:
+                parts.append(re.escape(piece))
+        self.tokens.append(("lit", re.compile(r"\s*" + "".join(parts), flags)))
+
+    def finditer(self, text: str, quotes: str):
+        """Yield (start, end, holes) for non-overlapping matches in text."""
+        self.text = text
+        self.quotes = quotes
+        pos = 0
+        while True:
+            m = self.first.search(text, pos)
+            if m is None:
+                return
+            start = m.start() + len(m.group()) - len(m.group().lstrip())
+            found = self._match(0, start, {})
+            if found is None:
+                pos = m.start() + 1
+                continue
+            end, holes = found
+            yield start, end, holes
+            pos = max(end, start + 1)
+
+    def _match(self, idx: int, pos: int, holes: dict):
+        if idx == len(self.tokens):
+            return pos, holes
+        kind, value = self.tokens[idx]
+        if kind in ("lit", "ident"):
+            m = (value if kind == "lit" else _IDENT).match(self.text, pos)
+            if m is None:
+                return None
+            if kind == "ident":
+                holes = self._bind(holes, value, m.group(1))
+                if holes is None:
+                    return None
+            return self._match(idx + 1, m.end(), holes)
+
+        for end in self._hole_ends(pos):
+            bound = self._bind(holes, value, self.text[pos:end])
+            if bound is not None:
+                found = self._match(idx + 1, end, bound)
+                if found is not None:
+                    return found
+        return None
+
+    def _bind(self, holes: dict, name: str, value: str) -> dict | None:
+        if not name:
+            return holes
+        value = " ".join(value.split())
+        if name in holes:
+            return holes if holes[name] == value else None
+        return {**holes, name: value}
+
+    def _hole_ends(self, pos: int):
+        """Yield the ends of balanced spans starting at pos, shortest first."""
+        text = self.text
+        limit = min(len(text), pos + _MAX_HOLE_CHARS)
+        stack = []
+        i = pos
+        while True:
+            if not stack:
+                yield i
+            if i >= limit:
+                return
+            ch = text[i]
+            if ch in _OPEN:
+                stack.append(_OPEN[ch])
+            elif ch in _CLOSE:
+                if not stack or stack.pop() != ch:
+                    return
+            elif ch in self.quotes:
+                close = self._string_end(i)
+                if close is not None:
+                    i = close
+            i += 1
+
+    def _string_end(self, i: int) -> int | None:
+        """Return the index of the quote closing the string opened at i."""
+        text = self.text
+        quote = text[i]
+        j = i + 1
+        while j < len(text):
+            ch = text[j]
+            if ch == "\\":
+                j += 2
+                continue
+            if ch == quote:
+                return j
+            if ch == "\n" and quote != "`":
+                return None
+            j += 1
+        return None
+
+
+_IDENT = re.compile(r"\s*\b(\w+)\b")
@@ -4,9 +4,11 @@ Replaces the api.get_code_structure() import that analysis.py needs,
 without pulling in the full API facade from tldr-swinton.
 """
 
+from itertools import chain, islice
 from pathlib import Path
 
 from .extractors import DefaultExtractor
+from .sampling import coverage, select_files
 from .workspace import iter_workspace_files
 
 
@@ -25,10 +27,16 @@ _EXT_MAP = {
 _extractor = DefaultExtractor()
 
 
+# Projects with more candidate files than this are sampled unless the
+# caller says otherwise.
+AUTO_SAMPLE_FILES = 5000
+
+
 def get_code_structure(
     root: str,
     language: str = "python",
     max_results: int = 1000,
+    sample: bool | None = None,
 ) -> dict:
     """Get code structure (functions, classes, imports) for all files in a project.
 
@@ -36,33 +44,64 @@ def get_code_structure(
         root: Root directory to analyze
         language: Language to analyze
         max_results: Maximum number of files to analyze
+        sample: Pick files by priority (entry points, recent changes, fan-in,
+            directory coverage) instead of walk order, and report coverage.
+            None samples projects with more than AUTO_SAMPLE_FILES files.
 
     Returns:
-        Dict with {root, language, files: [{path, functions, classes, imports}]}
+        Dict with {root, language, files: [{path, functions, classes, imports}]};
+        sampled results add sampled, coverage, and a reason (and fan_in) per file
     """
     root_path = Path(root)
     extensions = _EXT_MAP.get(language, {".py"})
 
     result = {"root": str(root_path), "language": language, "files": []}
 
-    count = 0
-    for file_path in iter_workspace_files(root_path, extensions=extensions):
-        if count >= max_results:
-            break
-
-        try:
-            info = _extractor.extract(str(file_path))
-            info_dict = info.to_dict()
-
-            file_entry = {
-                "path": str(file_path.relative_to(root_path)),
-                "functions": [f["name"] for f in info_dict.get("functions", [])],
-                "classes": [c["name"] for c in info_dict.get("classes", [])],
-                "imports": info_dict.get("imports", []),
-            }
-            result["files"].append(file_entry)
-            count += 1
-        except Exception:
-            pass
+    # iter_workspace_files yields resolved paths.
+    root_path = root_path.resolve()
+    files = iter_workspace_files(root_path, extensions=extensions)
+    if sample is None:
+        head = list(islice(files, AUTO_SAMPLE_FILES + 1))
+        sample = len(head) > AUTO_SAMPLE_FILES
+        files = chain(head, files)
+
+    if not sample:
+        count = 0
+        for file_path in files:
+            if count >= max_results:
+                break
+            entry = _file_entry(root_path, file_path)
+            if entry is not None:
+                result["files"].append(entry)
+                count += 1
+        return result
 
+    candidates = list(files)
+    chosen, fan_in = select_files(root_path, candidates, max_results)
+    extracted = []
+    for file_path, reason in chosen:
+        entry = _file_entry(root_path, file_path)
+        if entry is None:
+            continue
+        entry["reason"] = reason
+        if fan_in.get(file_path):
+            entry["fan_in"] = fan_in[file_path]
+        result["files"].append(entry)
+        extracted.append((file_path, reason))
+    result["sampled"] = True
+    result["coverage"] = coverage(root_path, candidates, extracted)
     return result
+
+
+def _file_entry(root_path: Path, file_path: Path) -> dict | None:
+    """Extract one file's structure, or None if it cannot be parsed."""
+    try:
+        info_dict = _extractor.extract(str(file_path)).to_dict()
+    except Exception:
+        return None
+    return {
+        "path": str(file_path.relative_to(root_path)),
+        "functions": [f["name"] for f in info_dict.get("functions", [])],
+        "classes": [c["name"] for c in info_dict.get("classes", [])],
+        "imports": info_dict.get("imports", []),
+    }
@@ -0,0 +1,240 @@
+"""Codemod planning: where a rewrite lands and in what order to ship it.
+
+A codemod is a structural match template and an optional rewrite template
+(see code_search for the syntax). ``codemod_plan`` finds every match across
+the workspace, renders the rewrite for each site, and groups the sites by
+project and by CODEOWNERS owner. Nothing is edited.
+
+Projects are ordered into steps by cross-project dependencies: a project
+comes after every affected project it depends on, directly or through
+unaffected projects, so libraries migrate before their consumers. Projects
+in a dependency cycle share one step, marked ``cycle``.
+
+The pattern may also be written as a sentence, ``replace [calls to] OLD
+with NEW``. "calls to" expands OLD and NEW to ``OLD(:[args])`` and
+``NEW(:[args])``.
+"""
+
+from __future__ import annotations
+
+import fnmatch
+import os
+import re
+from pathlib import Path
+
+from .code_search import _HOLE, search_code
+from .cross_project import _discover_projects, scan_cross_project_deps
+
+_SENTENCE = re.compile(r"^\s*replace\s+(calls\s+to\s+)?(.+?)\s+with\s+(.+?)\s*$", re.I | re.S)
+_CODEOWNERS = ("CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS")
+
+
+def parse_pattern(pattern: str, rewrite: str | None = None) -> tuple[str, str | None]:
+    """Split a "replace OLD with NEW" sentence into match and rewrite
+    templates; other patterns are returned as given."""
+    m = _SENTENCE.match(pattern)
+    if rewrite is not None or m is None:
+        return pattern, rewrite
+    old, new = m.group(2), m.group(3)
+    if m.group(1):
+        return f"{old}(:[args])", f"{new}(:[args])"
+    return old, new
+
+
+def codemod_plan(
+    root: str,
+    pattern: str,
+    rewrite: str | None = None,
+    language: str | None = None,
+    projects: list[str] | None = None,
+    max_depth: int = 4,
+    max_results: int = 2000,
+) -> dict:
+    """Plan a structural rewrite across the workspace without applying it.
+
+    Args:
+        root: Workspace root
+        pattern: Structural match template, or "replace [calls to] OLD
+            with NEW"
+        rewrite: Rewrite template using the pattern's holes
+        language: Only files of this language
+        projects: Only these projects (names)
+        max_depth: Directory levels below root searched for projects
+        max_results: Stop after this many sites
+
+    Returns:
+        Dict with match, rewrite, projects ({project, path, owners, files,
+        sites, depends_on}), owners ({owner, projects, sites}), steps
+        ({step, projects, cycle}), total_sites, total_files, and truncated.
+    """
+    match, rewrite = parse_pattern(pattern, rewrite)
+    if rewrite is not None:
+        unknown = sorted({n for n in _hole_names(rewrite) if n not in _hole_names(match)})
+        if unknown:
+            raise ValueError(f"rewrite uses holes not in the pattern: {', '.join(unknown)}")
+
+    root = str(Path(root).resolve())
+    found = search_code(
+        root, match, mode="structural", language=language, max_depth=max_depth, max_results=max_results,
+    )
+    known = {p["name"]: p for p in _discover_projects(root, max_depth)}
+    workspace_rules = _codeowners(root)
+
+    by_project: dict[str, dict] = {}
+    for m in found["matches"]:
+        name = m["project"] or os.path.basename(root)
+        if projects and name not in projects:
+            continue
+        entry = by_project.get(name)
+        if entry is None:
+            path = known[name]["path"] if name in known else root
+            entry = by_project[name] = {
+                "project": name,
+                "path": os.path.relpath(path, root).replace(os.sep, "/"),
+                "rules": _codeowners(path) if path != root else [],
+                "owners": set(),
+                "sites": [],
+            }
+        site = {
+            "file": m["file"],
+            "line": m["line"],
+            "column": m["column"],
+            "text": m["text"],
+            "symbol": m["symbol"]["id"] if m["symbol"] else None,
+        }
+        if rewrite is not None:
+            site["replacement"] = _render(rewrite, m["holes"])
+        site["owners"] = _owners_for(m["file"], entry, workspace_rules)
+        entry["owners"].update(site["owners"])
+        entry["sites"].append(site)
+
+    deps = _dependencies(root, max_depth, set(by_project))
+    steps = _order(set(by_project), deps)
+
+    owners: dict[str, dict] = {}
+    for entry in by_project.values():
+        for site in entry["sites"]:
+            for o in site["owners"] or ["(unowned)"]:
+                agg = owners.setdefault(o, {"owner": o, "projects": set(), "sites": 0})
+                agg["projects"].add(entry["project"])
+                agg["sites"] += 1
+
+    step_of = {p: s["step"] for s in steps for p in s["projects"]}
+    return {
+        "root": root,
+        "match": match,
+        "rewrite": rewrite,
+        "projects": [
+            {
+                "project": e["project"],
+                "path": e["path"],
+                "step": step_of[e["project"]],
+                "owners": sorted(e["owners"]),
+                "files": len({s["file"] for s in e["sites"]}),
+                "sites": e["sites"],
+                "depends_on": sorted(deps[e["project"]]),
+            }
+            for e in sorted(by_project.values(), key=lambda e: (step_of[e["project"]], e["project"]))
+        ],
+        "owners": [
+            {"owner": o["owner"], "projects": sorted(o["projects"]), "sites": o["sites"]}
+            for o in sorted(owners.values(), key=lambda o: (-o["sites"], o["owner"]))
+        ],
+        "steps": steps,
+        "total_sites": sum(len(e["sites"]) for e in by_project.values()),
+        "total_files": len({(e["project"], s["file"]) for e in by_project.values() for s in e["sites"]}),
+        "truncated": found["truncated"],
+    }
+
+
+def _hole_names(template: str) -> set[str]:
+    return {m.group(1) or m.group(2) for m in _HOLE.finditer(template) if (m.group(1) or m.group(2)) not in (None, "", "_")}
+
+
+def _render(template: str, holes: dict) -> str:
+    return _HOLE.sub(lambda m: holes.get(m.group(1) or m.group(2) or "", m.group(0)), template)
+
+
+def _dependencies(root: str, max_depth: int, affected: set[str]) -> dict[str, set[str]]:
+    """Affected projects each affected project depends on, following edges
+    through unaffected projects."""
+    graph: dict[str, set[str]] = {}
+    for p in scan_cross_project_deps(root, max_depth)["projects"]:
+        graph.setdefault(p["project"], set()).update(d["project"] for d in p["depends_on"])
+    out = {}
+    for name in affected:
+        seen, stack = set(), list(graph.get(name, ()))
+        while stack:
+            dep = stack.pop()
+            if dep in seen or dep == name:
+                continue
+            seen.add(dep)
+            stack.extend(graph.get(dep, ()))
+        out[name] = seen & affected
+    return out
+
+
+def _order(affected: set[str], deps: dict[str, set[str]]) -> list[dict]:
+    """Group projects into steps, dependencies first."""
+    steps = []
+    done: set[str] = set()
+    remaining = set(affected)
+    while remaining:
+        ready = sorted(p for p in remaining if deps[p] <= done)
+        cycle = not ready
+        if cycle:
+            # Every remaining project waits on another; ship the ones whose
+            # outstanding dependencies all depend back on them together.
+            ready = sorted(p for p in remaining if all(p in deps[d] for d in deps[p] - done))
+            ready = ready or sorted(remaining)
+        steps.append({"step": len(steps) + 1, "projects": ready, "cycle": cycle})
+        done.update(ready)
+        remaining.difference_update(ready)
+    return steps
+
+
+def _codeowners(base: str) -> list[tuple[str, list[str]]]:
+    """(pattern, owners) rules from the first CODEOWNERS file under base."""
+    for name in _CODEOWNERS:
+        path = Path(base) / name
+        if not path.is_file():
+            continue
+        rules = []
+        for line in path.read_text(errors="replace").splitlines():
+            line = line.split("#", 1)[0].strip()
+            if line:
+                pattern, *owners = line.split()
+                rules.append((pattern, owners))
+        return rules
+    return []
+
+
+def _owners_for(file: str, entry: dict, workspace_rules: list) -> list[str]:
+    """Owners from the last matching rule, preferring the project's own
+    CODEOWNERS over the workspace's."""
+    project_rel = file[len(entry["path"]) + 1:] if entry["path"] != "." and file.startswith(entry["path"] + "/") else file
+    for rules, rel in ((entry["rules"], project_rel), (workspace_rules, file)):
+        owners = None
+        for pattern, names in rules:
+            if _owner_match(pattern, rel):
+                owners = names
+        if owners is not None:
+            return owners
+    return []
+
+
+def _owner_match(pattern: str, rel: str) -> bool:
+    """Match a CODEOWNERS pattern against a slash path (gitignore rules:
+    anchored when it contains a non-trailing slash, a directory covers
+    everything beneath it)."""
+    anchored = "/" in pattern.rstrip("/")
+    pat = pattern.strip("/")
+    if pat in ("", "*", "**"):
+        return True
+    candidates = [rel] if anchored else [rel] + [rel.split("/", i)[i] for i in range(1, rel.count("/") + 1)]
+    for cand in candidates:
+        parts = cand.split("/")
+        for n in range(1, len(parts) + 1):
+            if fnmatch.fnmatchcase("/".join(parts[:n]), pat):
+                return True
+    return False
@@ -0,0 +1,201 @@
+"""Reverse dependency lookup: who in the workspace imports a project.
+
+``cross_project_deps`` answers "what does this project depend on" from
+manifests. ``consumers`` turns it around at package granularity by reading
+import statements across every other project:
+
+- go: import paths under the target's ``go.mod`` module path
+- python: ``import``/``from`` of the target's top-level packages (root or
+  ``src/`` layout) or its ``[project] name``
+- typescript/javascript: ``import``, ``export ... from``, ``require()``, and
+  dynamic ``import()`` of the target's ``package.json`` name or a subpath
+- rust: ``use`` and ``extern crate`` of the target's crate name
+
+Projects whose manifests declare the target (``cross_project_deps`` edges)
+but whose sources never import it are listed as ``declared_only``, which is
+usually a stale dependency or a binary/script use.
+"""
+
+from __future__ import annotations
+
+import ast
+import json
+import os
+import re
+from pathlib import Path
+
+from .code_search import iter_sources, owner
+from .cross_project import _discover_projects, scan_cross_project_deps
+
+_GO_IMPORT_BLOCK = re.compile(r"^import\s*\((.*?)^\)", re.M | re.S)
+_GO_IMPORT_LINE = re.compile(r'^import\s+(?:[\w.]+\s+)?"([^"]+)"', re.M)
+_GO_SPEC = re.compile(r'^\s*(?:[\w.]+\s+)?"([^"]+)"', re.M)
+_JS_IMPORT = re.compile(
+    r"""(?:\bfrom\s*|\bimport\s*\(?\s*|\brequire\s*\(\s*)["']([^"']+)["']""",
+    re.M,
+)
+_RS_USE = re.compile(r"^\s*(?:pub\s+)?(?:use\s+::?|extern\s+crate\s+)(\w+)((?:::\w+)*)", re.M)
+_EXTS = {".go", ".py", ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".rs"}
+
+
+def find_consumers(root: str, project: str, package: str | None = None, max_depth: int = 4) -> dict:
+    """List files in other workspace projects that import project.
+
+    Args:
+        root: Workspace root
+        project: Target project name or path
+        package: Only imports of this package or module (Go import path or
+            project-relative directory, Python dotted module, JS subpath
+            or full specifier, Rust module path); default the whole project
+        max_depth: Directory levels below root searched for projects
+
+    Returns:
+        Dict with project, names (import prefixes the target is known by),
+        packages ({package, consumers: [{project, file, line, import}]}),
+        projects ({project, files, packages}), total_files, and
+        declared_only (projects with a manifest edge but no import).
+    """
+    projects = _discover_projects(root, max_depth)
+    target = _find_project(projects, project)
+    if target is None:
+        raise LookupError(f"project {project!r} not found under {root}")
+    names = _import_names(target["path"])
+    wanted = _package_filter(package, names, target["path"]) if package else None
+
+    by_package: dict[str, list[dict]] = {}
+    per_project: dict[str, dict] = {}
+    for path, _, text in iter_sources(root, _EXTS):
+        home = owner(projects, str(path))
+        if not home["name"] or home["path"] == target["path"]:
+            continue
+        for line, spec, pkg, alt in _imports(path.suffix, text, names):
+            if wanted:
+                hits = [c for c in (pkg, alt) if c and any(c == w or c.startswith(w + sep) for w, sep in wanted)]
+                if not hits:
+                    continue
+                pkg = hits[0]
+            rel = os.path.relpath(path, home["path"]).replace(os.sep, "/")
+            by_package.setdefault(pkg, []).append({"project": home["name"], "file": rel, "line": line, "import": spec})
+            entry = per_project.setdefault(home["name"], {"files": set(), "packages": set()})
+            entry["files"].add(rel)
+            entry["packages"].add(pkg)
+
+    declared = {
+        p["project"] for p in scan_cross_project_deps(root, max_depth)["projects"]
+        for d in p["depends_on"]
+        if d["project"] == target["name"] and d["type"] != "script"
+    }
+    return {
+        "project": target["name"],
+        "names": sorted(n for n, _ in names),
+        "packages": [
+            {"package": pkg, "consumers": sorted(uses, key=lambda u: (u["project"], u["file"], u["line"]))}
+            for pkg, uses in sorted(by_package.items())
+        ],
+        "projects": [
+            {"project": name, "files": len(e["files"]), "packages": sorted(e["packages"])}
+            for name, e in sorted(per_project.items())
+        ],
+        "total_files": sum(len(e["files"]) for e in per_project.values()),
+        "declared_only": sorted(declared - set(per_project)),
+    }
+
+
+def _find_project(projects: list[dict], project: str) -> dict | None:
+    path = os.path.abspath(project) if os.sep in project else ""
+    for p in projects:
+        if p["name"] == project or os.path.abspath(p["path"]) == path:
+            return p
+    return None
+
+
+def _import_names(project_path: str) -> list[tuple[str, str]]:
+    """Import prefixes for a project as (name, separator) pairs, where the
+    separator joins the name to its subpackages ("/" for Go and JS, "." for
+    Python, "::" for Rust)."""
+    root = Path(project_path)
+    names = []
+    gomod = root / "go.mod"
+    if gomod.is_file():
+        m = re.search(r"^module\s+(\S+)", gomod.read_text(errors="replace"), re.M)
+        if m:
+            names.append((m.group(1), "/"))
+    pkg_json = root / "package.json"
+    if pkg_json.is_file():
+        try:
+            name = json.loads(pkg_json.read_text(errors="replace")).get("name")
+        except (json.JSONDecodeError, AttributeError):
+            name = None
+        if isinstance(name, str) and name:
+            names.append((name, "/"))
+    cargo = root / "Cargo.toml"
+    if cargo.is_file():
+        m = re.search(r'^\[package\][^\[]*?^name\s*=\s*"([^"]+)"', cargo.read_text(errors="replace"), re.M | re.S)
+        if m:
+            names.append((m.group(1).replace("-", "_"), "::"))
+    py = set()
+    for base in (root, root / "src"):
+        if base.is_dir():
+            py.update(d.name for d in base.iterdir() if (d / "__init__.py").is_file() and d.name.isidentifier())
+    pyproject = root / "pyproject.toml"
+    if pyproject.is_file():
+        m = re.search(r'^\[project\][^\[]*?^name\s*=\s*"([^"]+)"', pyproject.read_text(errors="replace"), re.M | re.S)
+        if m:
+            py.add(re.sub(r"[-.]+", "_", m.group(1)).lower())
+    names.extend((n, ".") for n in sorted(py))
+    return names
+
+
+def _package_filter(package: str, names: list[tuple[str, str]], project_path: str) -> list[tuple[str, str]]:
+    """Resolve package to the import prefixes it may appear as."""
+    out = [(package, sep) for _, sep in names]
+    for name, sep in names:
+        if sep == "/" and (Path(project_path) / package).is_dir():
+            # A project-relative Go directory or JS subpath.
+            out.append((f"{name}/{package.strip('/')}", sep))
+    return out
+
+
+def _imports(suffix: str, text: str, names: list[tuple[str, str]]) -> list[tuple[int, str, str, str]]:
+    """(line, import spec, imported package, alternative) for imports of
+    names in text. The alternative is set for Python "from pkg import name",
+    where pkg.name may be a submodule rather than an attribute."""
+    found = []
+    if suffix == ".go":
+        specs = []
+        for block in _GO_IMPORT_BLOCK.finditer(text):
+            specs.extend((block.start(1) + m.start(1), m.group(1)) for m in _GO_SPEC.finditer(block.group(1)))
+        specs.extend((m.start(1), m.group(1)) for m in _GO_IMPORT_LINE.finditer(text))
+        for offset, spec in specs:
+            if _under(spec, names, "/"):
+                found.append((text.count("\n", 0, offset) + 1, spec, spec, ""))
+    elif suffix == ".py":
+        try:
+            tree = ast.parse(text)
+        except SyntaxError:
+            return []
+        for node in ast.walk(tree):
+            if isinstance(node, ast.Import):
+                mods = [(a.name, a.name, "") for a in node.names]
+            elif isinstance(node, ast.ImportFrom) and node.module and not node.level:
+                mods = [(f"from {node.module} import {a.name}", node.module, f"{node.module}.{a.name}") for a in node.names]
+            else:
+                continue
+            for spec, mod, alt in mods:
+                if _under(mod, names, "."):
+                    found.append((node.lineno, spec, mod, alt))
+    elif suffix == ".rs":
+        for m in _RS_USE.finditer(text):
+            crate, rest = m.group(1), m.group(2)
+            if _under(crate, names, "::"):
+                found.append((text.count("\n", 0, m.start()) + 1, crate + rest, crate + rest, ""))
+    else:
+        for m in _JS_IMPORT.finditer(text):
+            spec = m.group(1)
+            if _under(spec, names, "/"):
+                found.append((text.count("\n", 0, m.start(1)) + 1, spec, spec, ""))
+    return sorted(set(found))
+
+
+def _under(spec: str, names: list[tuple[str, str]], sep: str) -> bool:
+    return any(s == sep and (spec == n or spec.startswith(n + sep)) for n, s in names)
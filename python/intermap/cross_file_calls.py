@@ -1732,32 +1732,35 @@ def build_function_index(
 
     for src_file in scan_project(root, language, workspace_config):
         src_path = Path(src_file)
-        rel_path = src_path.relative_to(root)
-
-        # Derive module name from file path
-        # e.g., pkg/core.py -> pkg.core, utils.ts -> utils
-        module_parts = list(rel_path.parts[:-1]) + [rel_path.stem]
-        module_name = '/'.join(module_parts) if language == "typescript" else '.'.join(module_parts)
-
-        # Also track the simple module name (last component)
-        simple_module = rel_path.stem
-
-        if language == "python":
-            _index_python_file(src_path, rel_path, module_name, simple_module, index)
-        elif language == "typescript":
-            _index_typescript_file(src_path, rel_path, module_name, simple_module, index)
-        elif language == "go":
-            _index_go_file(src_path, rel_path, module_name, simple_module, index)
-        elif language == "rust":
-            _index_rust_file(src_path, rel_path, module_name, simple_module, index)
-        elif language == "java":
-            _index_java_file(src_path, rel_path, module_name, simple_module, index)
-        elif language == "c":
-            _index_c_file(src_path, rel_path, module_name, simple_module, index)
+        index_file(src_path, src_path.relative_to(root), language, index)
 
     return index
 
 
+def index_file(src_path: Path, rel_path: Path, language: str, index: dict) -> None:
+    """Add one file's definitions to a function index in place."""
+    # Derive module name from file path
+    # e.g., pkg/core.py -> pkg.core, utils.ts -> utils
+    module_parts = list(rel_path.parts[:-1]) + [rel_path.stem]
+    module_name = '/'.join(module_parts) if language == "typescript" else '.'.join(module_parts)
+
+    # Also track the simple module name (last component)
+    simple_module = rel_path.stem
+
+    if language == "python":
+        _index_python_file(src_path, rel_path, module_name, simple_module, index)
+    elif language == "typescript":
+        _index_typescript_file(src_path, rel_path, module_name, simple_module, index)
+    elif language == "go":
+        _index_go_file(src_path, rel_path, module_name, simple_module, index)
+    elif language == "rust":
+        _index_rust_file(src_path, rel_path, module_name, simple_module, index)
+    elif language == "java":
+        _index_java_file(src_path, rel_path, module_name, simple_module, index)
+    elif language == "c":
+        _index_c_file(src_path, rel_path, module_name, simple_module, index)
+
+
 def build_definition_list(
     root: str | Path,
     language: str = "python",
@@ -1789,23 +1792,28 @@ def build_definition_list(
     for src_file in files:
         src_path = Path(src_file)
         rel_path = src_path.relative_to(root)
-
-        if language == "python":
-            defs.extend(_defs_python_file(src_path, rel_path))
-        elif language == "typescript":
-            defs.extend(_defs_typescript_file(src_path, rel_path))
-        elif language == "go":
-            defs.extend(_defs_go_file(src_path, rel_path))
-        elif language == "rust":
-            defs.extend(_defs_rust_file(src_path, rel_path))
-        elif language == "java":
-            defs.extend(_defs_java_file(src_path, rel_path))
-        elif language == "c":
-            defs.extend(_defs_c_file(src_path, rel_path))
+        defs.extend(definitions_for_file(src_path, rel_path, language))
 
     return defs
 
 
+def definitions_for_file(src_path: Path, rel_path: Path, language: str) -> list[dict]:
+    """Extract definitions from a single file using the language's extractor."""
+    if language == "python":
+        return _defs_python_file(src_path, rel_path)
+    elif language == "typescript":
+        return _defs_typescript_file(src_path, rel_path)
+    elif language == "go":
+        return _defs_go_file(src_path, rel_path)
+    elif language == "rust":
+        return _defs_rust_file(src_path, rel_path)
+    elif language == "java":
+        return _defs_java_file(src_path, rel_path)
+    elif language == "c":
+        return _defs_c_file(src_path, rel_path)
+    return []
+
+
 def _defs_python_file(src_path: Path, rel_path: Path) -> list[dict]:
     """Extract definitions with line numbers from a Python file."""
     try:
@@ -1815,23 +1823,31 @@ def _defs_python_file(src_path: Path, rel_path: Path) -> list[dict]:
         return []
 
     defs = []
-    for node in ast.walk(tree):
-        if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
-            defs.append({
-                "file": str(rel_path),
-                "name": node.name,
-                "line": node.lineno,
-                "kind": "func",
-                "scope": "",
-            })
-        elif isinstance(node, ast.ClassDef):
-            defs.append({
-                "file": str(rel_path),
-                "name": node.name,
-                "line": node.lineno,
-                "kind": "class",
-                "scope": "",
-            })
+
+    def visit(node, scope: str):
+        for child in ast.iter_child_nodes(node):
+            if isinstance(child, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                defs.append({
+                    "file": str(rel_path),
+                    "name": child.name,
+                    "line": child.lineno,
+                    "kind": "method" if scope else "func",
+                    "scope": scope,
+                })
+                visit(child, "")
+            elif isinstance(child, ast.ClassDef):
+                defs.append({
+                    "file": str(rel_path),
+                    "name": child.name,
+                    "line": child.lineno,
+                    "kind": "class",
+                    "scope": "",
+                })
+                visit(child, child.name)
+            else:
+                visit(child, scope)
+
+    visit(tree, "")
     return defs
 
 
@@ -3260,7 +3276,9 @@ def _extract_c_file_calls(file_path: Path, root: Path) -> dict[str, list[tuple[s
 def build_project_call_graph(
     root: str | Path,
     language: str = "python",
-    use_workspace_config: bool = True
+    use_workspace_config: bool = True,
+    only_files: Optional[set[str]] = None,
+    func_index: Optional[dict] = None,
 ) -> ProjectCallGraph:
     """
     Build a complete project-wide call graph.
@@ -3277,6 +3295,9 @@ def build_project_call_graph(
         use_workspace_config: If True, loads .claude/workspace.json to scope
                              indexing to activePackages and excludePatterns.
                              Defaults to True for monorepo support.
+        only_files: If set, only resolve calls made from these project-relative
+                    files (used for incremental updates).
+        func_index: Pre-built function index to reuse instead of rescanning.
 
     Returns:
         ProjectCallGraph with edges as (src_file, src_func, dst_file, dst_func)
@@ -3289,20 +3310,21 @@ def build_project_call_graph(
     if use_workspace_config:
         workspace_config = load_workspace_config(root)
 
-    func_index = build_function_index(root, language, workspace_config)
+    if func_index is None:
+        func_index = build_function_index(root, language, workspace_config)
 
     if language == "python":
-        _build_python_call_graph(root, graph, func_index, workspace_config)
+        _build_python_call_graph(root, graph, func_index, workspace_config, only_files)
     elif language == "typescript":
-        _build_typescript_call_graph(root, graph, func_index, workspace_config)
+        _build_typescript_call_graph(root, graph, func_index, workspace_config, only_files)
     elif language == "go":
-        _build_go_call_graph(root, graph, func_index, workspace_config)
+        _build_go_call_graph(root, graph, func_index, workspace_config, only_files)
     elif language == "rust":
-        _build_rust_call_graph(root, graph, func_index, workspace_config)
+        _build_rust_call_graph(root, graph, func_index, workspace_config, only_files)
     elif language == "java":
-        _build_java_call_graph(root, graph, func_index, workspace_config)
+        _build_java_call_graph(root, graph, func_index, workspace_config, only_files)
     elif language == "c":
-        _build_c_call_graph(root, graph, func_index, workspace_config)
+        _build_c_call_graph(root, graph, func_index, workspace_config, only_files)
 
     return graph
 
@@ -3311,12 +3333,15 @@ def _build_python_call_graph(
     root: Path,
     graph: ProjectCallGraph,
     func_index: dict,
-    workspace_config: Optional[WorkspaceConfig] = None
+    workspace_config: Optional[WorkspaceConfig] = None,
+    only_files: Optional[set[str]] = None,
 ):
     """Build call graph for Python files."""
     for py_file in scan_project(root, "python", workspace_config):
         py_path = Path(py_file)
         rel_path = str(py_path.relative_to(root))
+        if only_files is not None and rel_path not in only_files:
+            continue
 
         # Get imports for this file
         imports = parse_imports(py_path)
@@ -3385,12 +3410,15 @@ def _build_typescript_call_graph(
     root: Path,
     graph: ProjectCallGraph,
     func_index: dict,
-    workspace_config: Optional[WorkspaceConfig] = None
+    workspace_config: Optional[WorkspaceConfig] = None,
+    only_files: Optional[set[str]] = None,
 ):
     """Build call graph for TypeScript files."""
     for ts_file in scan_project(root, "typescript", workspace_config):
         ts_path = Path(ts_file)
         rel_path = str(ts_path.relative_to(root))
+        if only_files is not None and rel_path not in only_files:
+            continue
 
         # Get imports for this file
         imports = parse_ts_imports(ts_path)
@@ -3493,12 +3521,15 @@ def _build_go_call_graph(
     root: Path,
     graph: ProjectCallGraph,
     func_index: dict,
-    workspace_config: Optional[WorkspaceConfig] = None
+    workspace_config: Optional[WorkspaceConfig] = None,
+    only_files: Optional[set[str]] = None,
 ):
     """Build call graph for Go files."""
     for go_file in scan_project(root, "go", workspace_config):
         go_path = Path(go_file)
         rel_path = str(go_path.relative_to(root))
+        if only_files is not None and rel_path not in only_files:
+            continue
 
         # Get imports for this file
         imports = parse_go_imports(go_path)
@@ -3582,12 +3613,15 @@ def _build_rust_call_graph(
     root: Path,
     graph: ProjectCallGraph,
     func_index: dict,
-    workspace_config: Optional[WorkspaceConfig] = None
+    workspace_config: Optional[WorkspaceConfig] = None,
+    only_files: Optional[set[str]] = None,
 ):
     """Build call graph for Rust files."""
     for rs_file in scan_project(root, "rust", workspace_config):
         rs_path = Path(rs_file)
         rel_path = str(rs_path.relative_to(root))
+        if only_files is not None and rel_path not in only_files:
+            continue
 
         # Get imports for this file
         imports = parse_rust_imports(rs_path)
@@ -3705,12 +3739,15 @@ def _build_java_call_graph(
     root: Path,
     graph: ProjectCallGraph,
     func_index: dict,
-    workspace_config: Optional[WorkspaceConfig] = None
+    workspace_config: Optional[WorkspaceConfig] = None,
+    only_files: Optional[set[str]] = None,
 ):
     """Build call graph for Java files."""
     for java_file in scan_project(root, "java", workspace_config):
         java_path = Path(java_file)
         rel_path = str(java_path.relative_to(root))
+        if only_files is not None and rel_path not in only_files:
+            continue
 
         # Get imports for this file
         imports = parse_java_imports(java_path)
@@ -3772,12 +3809,15 @@ def _build_c_call_graph(
     root: Path,
     graph: ProjectCallGraph,
     func_index: dict,
-    workspace_config: Optional[WorkspaceConfig] = None
+    workspace_config: Optional[WorkspaceConfig] = None,
+    only_files: Optional[set[str]] = None,
 ):
     """Build call graph for C files."""
     for c_file in scan_project(root, "c", workspace_config):
         c_path = Path(c_file)
         rel_path = str(c_path.relative_to(root))
+        if only_files is not None and rel_path not in only_files:
+            continue
 
         # Get includes for this file
         includes = parse_c_imports(c_path)
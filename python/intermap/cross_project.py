@@ -4,27 +4,42 @@ import os
 import re
 import json
 
+from . import vcs
 
-def scan_cross_project_deps(root: str) -> dict:
+
+# Directories never searched for projects, matching the Go registry.
+_SKIP_DIRS = {"node_modules", "vendor", "target", "dist", "build", "__pycache__", "venv"}
+
+
+def scan_cross_project_deps(root: str, max_depth: int = 4) -> dict:
     """Scan a monorepo root and detect cross-project dependencies.
 
     Detects:
     - Go module dependencies (go.mod replace directives)
     - Python path dependencies (pyproject.toml path deps)
     - Plugin dependencies (explicit env-var patterns in plugin.json)
+    - Script dependencies (shell scripts and Makefiles running another
+      project's scripts or binaries, see script_map)
+    - Cross-language boundaries (cgo, Python native extensions, subprocess,
+      HTTP and gRPC clients reaching another project, see boundaries)
 
     Args:
         root: Monorepo root directory
+        max_depth: Directory levels below root searched for projects
 
     Returns:
-        Dict with projects, their dependencies, and edge counts.
+        Dict with projects, their dependencies, edge counts, and per-group
+        aggregates.
     """
-    projects = _discover_projects(root)
+    projects = _discover_projects(root, max_depth)
     # Use setdefault to handle duplicate project names (amendment #10)
     project_lookup: dict[str, str] = {}
     for p in projects:
         project_lookup.setdefault(p["name"], p["path"])
 
+    script_deps = _scan_script_deps(root, projects, project_lookup)
+    boundary_deps = _scan_boundary_deps(root, projects, project_lookup)
+
     results = []
     total_edges = 0
     for proj in projects:
@@ -32,6 +47,8 @@ def scan_cross_project_deps(root: str) -> dict:
         deps.extend(_scan_go_deps(proj["path"], project_lookup))
         deps.extend(_scan_python_deps(proj["path"], project_lookup))
         deps.extend(_scan_plugin_deps(proj["path"], project_lookup))
+        deps.extend(script_deps.get(proj["name"], []))
+        deps.extend(boundary_deps.get(proj["name"], []))
         # Deduplicate
         seen = set()
         unique_deps = []
@@ -44,46 +61,92 @@ def scan_cross_project_deps(root: str) -> dict:
         results.append({
             "project": proj["name"],
             "path": proj["path"],
+            "group": proj["group"],
             "depends_on": unique_deps,
         })
 
     return {
         "root": root,
         "projects": results,
+        "groups": _aggregate_groups(results),
         "total_projects": len(results),
         "total_edges": total_edges,
     }
 
 
-def _discover_projects(root: str) -> list[dict]:
-    """Find projects by walking top-level dirs for .git markers.
+def _group_ancestors(group: str) -> list[str]:
+    """Return group and each enclosing group: a/b/c -> a/b/c, a/b, a."""
+    if not group:
+        return []
+    parts = group.split("/")
+    return ["/".join(parts[:i]) for i in range(len(parts), 0, -1)]
+
+
+def _aggregate_groups(results: list[dict]) -> list[dict]:
+    """Roll project edges up to every group in the hierarchy.
 
-    Matches the Go registry.Scan() approach: walk all first-level
-    subdirectories, then check for .git in second-level dirs.
+    An edge counts as internal to a group when both ends are inside it
+    (at any depth); otherwise it is listed under the group's depends_on by
+    the target project's group ("" for top-level projects).
+    """
+    project_group: dict[str, str] = {}
+    for r in results:
+        project_group.setdefault(r["project"], r["group"])
+
+    groups: dict[str, dict] = {}
+    for r in results:
+        for g in _group_ancestors(r["group"]):
+            agg = groups.setdefault(g, {"projects": 0, "internal_edges": 0, "depends_on": {}})
+            agg["projects"] += 1
+            for dep in r["depends_on"]:
+                target = project_group.get(dep["project"], "")
+                if g in _group_ancestors(target):
+                    agg["internal_edges"] += 1
+                else:
+                    agg["depends_on"][target] = agg["depends_on"].get(target, 0) + 1
+
+    return [
+        {
+            "group": g,
+            "projects": agg["projects"],
+            "internal_edges": agg["internal_edges"],
+            "depends_on": [
+                {"group": t, "edges": n} for t, n in sorted(agg["depends_on"].items())
+            ],
+        }
+        for g, agg in sorted(groups.items())
+    ]
+
+
+def _discover_projects(root: str, max_depth: int = 4) -> list[dict]:
+    """Find projects by walking up to max_depth levels for VCS markers.
+
+    Matches the Go registry.Scan() approach: a directory with .git, .jj,
+    or .hg is a project and is not searched further, except at the top level, which
+    is always searched. The group is the slash path of the project's
+    parent relative to root ("" for top-level projects).
     (amendment #9: use .git check, not hardcoded group names)
     """
     projects = []
-    try:
-        entries = sorted(os.listdir(root))
-    except OSError:
-        return projects
-
-    for group_name in entries:
-        group_path = os.path.join(root, group_name)
-        if not os.path.isdir(group_path) or group_name.startswith("."):
-            continue
-        try:
-            sub_entries = sorted(os.listdir(group_path))
-        except OSError:
-            continue
-        for name in sub_entries:
-            proj_path = os.path.join(group_path, name)
-            if not os.path.isdir(proj_path) or name.startswith("."):
-                continue
-            # Only include dirs with .git marker (amendment #9)
-            if not os.path.isdir(os.path.join(proj_path, ".git")):
+    level = [""]
+    for depth in range(1, max_depth + 1):
+        next_level = []
+        for rel in level:
+            try:
+                entries = sorted(os.listdir(os.path.join(root, rel)))
+            except OSError:
                 continue
-            projects.append({"name": name, "path": proj_path, "group": group_name})
+            for name in entries:
+                child = f"{rel}/{name}" if rel else name
+                path = os.path.join(root, child)
+                if name.startswith(".") or name in _SKIP_DIRS or not os.path.isdir(path):
+                    continue
+                is_project = vcs.marker(path) is not None
+                if is_project:
+                    projects.append({"name": name, "path": path, "group": rel})
+                if (not is_project or depth == 1) and depth < max_depth:
+                    next_level.append(child)
+        level = next_level
     return projects
 
 
@@ -154,3 +217,39 @@ def _scan_plugin_deps(project_path: str, project_lookup: dict) -> list[dict]:
                 if "INTERMUTE" in key.upper() and "intermute" in project_lookup:
                     deps.append({"project": "intermute", "type": "plugin_ref", "via": f"env.{key}"})
     return deps
+
+
+def _scan_script_deps(root: str, projects: list[dict], project_lookup: dict) -> dict[str, list[dict]]:
+    """Detect scripts and Makefiles invoking another project's scripts or
+    binaries, keyed by the invoking project."""
+    from .script_map import scan_scripts
+
+    deps: dict[str, list[dict]] = {}
+    for edge in scan_scripts(root, projects=projects)["edges"]:
+        src, dst = edge["from_project"], edge["to_project"]
+        if src == dst or src not in project_lookup or dst not in project_lookup:
+            continue
+        deps.setdefault(src, []).append({
+            "project": dst,
+            "type": "script",
+            "via": f"{edge['from']}:{edge['line']} {edge['kind']} {edge['to']}",
+        })
+    return deps
+
+
+def _scan_boundary_deps(root: str, projects: list[dict], project_lookup: dict) -> dict[str, list[dict]]:
+    """Detect cross-language call boundaries reaching another project,
+    keyed by the calling project. The dependency type is the boundary kind."""
+    from .boundaries import scan_boundaries
+
+    deps: dict[str, list[dict]] = {}
+    for b in scan_boundaries(root, projects=projects)["boundaries"]:
+        src, dst = b["project"], b["to_project"]
+        if src == dst or src not in project_lookup or dst not in project_lookup:
+            continue
+        deps.setdefault(src, []).append({
+            "project": dst,
+            "type": b["kind"],
+            "via": f"{b['file']}:{b['line']} {b['target']}",
+        })
+    return deps
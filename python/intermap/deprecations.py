@@ -0,0 +1,243 @@
+"""Deprecation tracker: deprecated symbols and the call sites still using them.
+
+A symbol is deprecated when:
+
+- go: its doc comment has a ``Deprecated:`` paragraph (functions, methods,
+  types, vars, consts, and exported struct fields or grouped names)
+- python: it is decorated with ``@deprecated`` (warnings, typing_extensions,
+  or the ``deprecated`` package), or a function's own body calls
+  ``warnings.warn`` with ``DeprecationWarning`` or
+  ``PendingDeprecationWarning``
+- typescript/javascript: its JSDoc comment has an ``@deprecated`` tag
+
+Remaining uses are found by name across every workspace project, in files
+of the defining language family, skipping comments and the declaration
+itself. Matching is textual, so an unrelated symbol of the same name counts
+as a use.
+"""
+
+from __future__ import annotations
+
+import ast
+import os
+import re
+
+from .code_search import iter_sources, owner
+from .consumers import _find_project
+from .cross_project import _discover_projects
+
+_FAMILIES = {
+    ".go": "go",
+    ".py": "python",
+    ".ts": "js", ".tsx": "js", ".js": "js", ".jsx": "js", ".mjs": "js", ".cjs": "js",
+}
+
+_GO_DEPRECATED = re.compile(r"^\s*//\s*Deprecated:\s*(.*)$")
+_GO_COMMENT = re.compile(r"^\s*//")
+_GO_DECL = re.compile(
+    r"^\s*(?:func\s+(?:\([^)]*\)\s*)?(?P<func>\w+)|type\s+(?P<type>\w+)|(?:var|const)\s+(?P<value>\w+)|(?P<member>[A-Z]\w*)\s+\S)"
+)
+_JSDOC = re.compile(r"/\*\*(?:(?!\*/).)*?@deprecated\b[ \t]*([^\n*]*)(?:(?!\*/).)*\*/", re.S)
+_JS_MODIFIERS = {
+    "export", "default", "async", "public", "private", "protected", "static", "readonly",
+    "abstract", "declare", "override", "get", "set", "function", "class", "interface",
+    "type", "enum", "const", "let", "var", "namespace",
+}
+_JS_KINDS = {"function": "function", "class": "class", "interface": "type", "type": "type", "enum": "type",
+             "const": "value", "let": "value", "var": "value"}
+_JS_WORD = re.compile(r"\s*([A-Za-z_$][\w$]*)")
+_PY_WARNINGS = {"DeprecationWarning", "PendingDeprecationWarning"}
+
+
+def find_deprecations(root: str, project: str | None = None, max_sites: int = 20, max_depth: int = 4) -> dict:
+    """List deprecated symbols and their remaining uses across the workspace.
+
+    Args:
+        root: Workspace root
+        project: Only symbols defined in this project (name or path); uses
+            are still searched for everywhere
+        max_sites: Uses listed per symbol; all are counted
+        max_depth: Directory levels below root searched for projects
+
+    Returns:
+        Dict with symbols ({name, kind, project, file, line, message,
+        remaining, projects: {project: uses}, sites: [{project, file,
+        line}]}), ordered by remaining uses, and projects, the burn-down
+        per project ({project, remaining, symbols: [{name, project, file,
+        uses}]}) ordered by remaining uses.
+    """
+    root = os.path.abspath(root)
+    projects = _discover_projects(root, max_depth) or [{"name": os.path.basename(root), "path": root}]
+    only = None
+    if project:
+        only = _find_project(projects, project)
+        if only is None:
+            raise LookupError(f"project {project!r} not found under {root}")
+
+    sources = []
+    symbols = []
+    for path, _, text in iter_sources(root, set(_FAMILIES)):
+        home = owner(projects, str(path))
+        if not home["name"]:
+            continue
+        rel = os.path.relpath(path, home["path"]).replace(os.sep, "/")
+        family = _FAMILIES[path.suffix]
+        sources.append((home["name"], rel, family, text))
+        if only is not None and home["path"] != only["path"]:
+            continue
+        for sym in _deprecated(family, text):
+            sym.update(project=home["name"], file=rel, family=family)
+            symbols.append(sym)
+
+    by_name: dict[tuple[str, str], list[dict]] = {}
+    for sym in symbols:
+        sym.update(remaining=0, projects={}, sites=[])
+        by_name.setdefault((sym["family"], sym["name"]), []).append(sym)
+    for family in {f for f, _ in by_name}:
+        names = sorted({n for f, n in by_name if f == family}, key=len, reverse=True)
+        use = re.compile(r"(?<![\w$])(" + "|".join(map(re.escape, names)) + r")(?![\w$])")
+        for proj, rel, fam, text in sources:
+            if fam != family:
+                continue
+            for lineno, line in enumerate(text.splitlines(), 1):
+                code = _strip_comment(family, line)
+                for m in use.finditer(code):
+                    for sym in by_name[(family, m.group(1))]:
+                        if proj == sym["project"] and rel == sym["file"] and lineno == sym["line"]:
+                            continue
+                        sym["remaining"] += 1
+                        sym["projects"][proj] = sym["projects"].get(proj, 0) + 1
+                        if len(sym["sites"]) < max_sites:
+                            sym["sites"].append({"project": proj, "file": rel, "line": lineno})
+
+    burn: dict[str, dict] = {}
+    for sym in symbols:
+        del sym["family"]
+        for proj, uses in sym["projects"].items():
+            entry = burn.setdefault(proj, {"project": proj, "remaining": 0, "symbols": []})
+            entry["remaining"] += uses
+            entry["symbols"].append({"name": sym["name"], "project": sym["project"], "file": sym["file"], "uses": uses})
+    for entry in burn.values():
+        entry["symbols"].sort(key=lambda s: (-s["uses"], s["name"], s["project"], s["file"]))
+
+    symbols.sort(key=lambda s: (-s["remaining"], s["project"], s["file"], s["line"]))
+    return {
+        "root": root,
+        "symbols": symbols,
+        "projects": sorted(burn.values(), key=lambda e: (-e["remaining"], e["project"])),
+        "total_symbols": len(symbols),
+        "total_remaining": sum(s["remaining"] for s in symbols),
+    }
+
+
+def _deprecated(family: str, text: str) -> list[dict]:
+    if family == "go":
+        return _go_deprecated(text)
+    if family == "python":
+        return _python_deprecated(text)
+    return _js_deprecated(text)
+
+
+def _go_deprecated(text: str) -> list[dict]:
+    """Declarations whose doc comment block has a Deprecated: paragraph."""
+    found = []
+    message = None
+    for lineno, line in enumerate(text.splitlines(), 1):
+        if _GO_COMMENT.match(line):
+            m = _GO_DEPRECATED.match(line)
+            if m:
+                message = m.group(1).strip()
+            continue
+        if message is not None:
+            d = _GO_DECL.match(line)
+            if d:
+                kind = next(k for k in ("func", "type", "value", "member") if d.group(k))
+                found.append({"name": d.group(kind), "kind": "function" if kind == "func" else kind,
+                              "line": lineno, "message": message})
+        message = None
+    return found
+
+
+def _python_deprecated(text: str) -> list[dict]:
+    try:
+        tree = ast.parse(text)
+    except SyntaxError:
+        return []
+    found = []
+    for node in ast.walk(tree):
+        if not isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef)):
+            continue
+        message = None
+        for dec in node.decorator_list:
+            call = dec if isinstance(dec, ast.Call) else None
+            if _dotted_tail(call.func if call else dec) == "deprecated":
+                message = _first_str(call) if call else ""
+                break
+        if message is None and not isinstance(node, ast.ClassDef):
+            message = _warns_deprecation(node)
+        if message is not None:
+            kind = "class" if isinstance(node, ast.ClassDef) else "function"
+            found.append({"name": node.name, "kind": kind, "line": node.lineno, "message": message})
+    found.sort(key=lambda s: s["line"])
+    return found
+
+
+def _warns_deprecation(func: ast.AST) -> str | None:
+    """The message of a warnings.warn(..., DeprecationWarning) in func's own body."""
+    stack = list(ast.iter_child_nodes(func))
+    while stack:
+        node = stack.pop()
+        if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef, ast.Lambda)):
+            continue
+        if isinstance(node, ast.Call) and _dotted_tail(node.func) == "warn":
+            category = node.args[1] if len(node.args) > 1 else next(
+                (k.value for k in node.keywords if k.arg == "category"), None)
+            if category is not None and _dotted_tail(category) in _PY_WARNINGS:
+                return _first_str(node)
+        stack.extend(ast.iter_child_nodes(node))
+    return None
+
+
+def _dotted_tail(node: ast.AST | None) -> str:
+    if isinstance(node, ast.Attribute):
+        return node.attr
+    if isinstance(node, ast.Name):
+        return node.id
+    return ""
+
+
+def _first_str(call: ast.Call) -> str:
+    if call.args and isinstance(call.args[0], ast.Constant) and isinstance(call.args[0].value, str):
+        return call.args[0].value.strip()
+    return ""
+
+
+def _js_deprecated(text: str) -> list[dict]:
+    """Declarations following a JSDoc comment with an @deprecated tag."""
+    found = []
+    for m in _JSDOC.finditer(text):
+        kind = "method"
+        pos = m.end()
+        while (word := _JS_WORD.match(text, pos)) is not None:
+            pos = word.end()
+            if word.group(1) in _JS_MODIFIERS:
+                kind = _JS_KINDS.get(word.group(1), kind)
+                continue
+            found.append({
+                "name": word.group(1),
+                "kind": kind,
+                "line": text.count("\n", 0, word.start(1)) + 1,
+                "message": m.group(1).strip(),
+            })
+            break
+    return found
+
+
+def _strip_comment(family: str, line: str) -> str:
+    """line without a line comment; a line inside a block comment is dropped."""
+    stripped = line.lstrip()
+    if family == "python":
+        return "" if stripped.startswith("#") else line.split(" #", 1)[0]
+    if stripped.startswith(("//", "/*", "*")):
+        return ""
+    return line.split(" //", 1)[0]
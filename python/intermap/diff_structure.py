@@ -0,0 +1,156 @@
+"""Symbol outlines for just the files a diff touches.
+
+Reviewing a PR rarely needs the whole project's structure: this parses
+only the changed files, at the diff's new side, and marks which symbols
+the hunks intersect. Python symbols have exact line spans from the AST;
+other languages use the regex extractor's start lines, and a symbol is
+taken to run until the next one starts.
+"""
+
+import subprocess
+from pathlib import Path
+
+from . import usage
+from .extractors import BasicRegexExtractor
+from .live_changes import (
+    _extract_python_symbol_ranges_from_source,
+    _hunks_to_new_line_ranges,
+    _parse_git_patch,
+    _range_overlaps_any,
+)
+
+_regex = BasicRegexExtractor()
+
+
+def structure_of_diff(
+    project: str,
+    base: str = "HEAD",
+    head: str | None = None,
+    files: list[str] | None = None,
+    max_files: int = 200,
+) -> dict:
+    """Outline the files changed between base and head.
+
+    Args:
+        project: Project root inside a git repository
+        base: Ref the diff starts from
+        head: Ref the diff ends at; None diffs against the working tree
+        files: Only report these project-relative files
+        max_files: Stop after this many changed files
+
+    Returns:
+        Dict with project, base, head, files ([{path, status, hunks,
+        symbols: [{name, type, line, end, changed}], changed_symbols,
+        approximate}]), total_files, total_changed_symbols, and truncated.
+    """
+    revs = [base] if head is None else [base, head]
+    for rev in revs:
+        if not rev or rev.startswith("-"):
+            raise ValueError(f"invalid git ref {rev!r}")
+    result = subprocess.run(
+        ["git", "diff", "--unified=0", "--no-color", "--no-ext-diff", "--find-renames", "--end-of-options", *revs, "--", "."],
+        capture_output=True,
+        cwd=project,
+        timeout=30,
+    )
+    if result.returncode != 0:
+        message = (result.stderr or b"").decode("utf-8", errors="replace").strip()
+        raise ValueError(f"git diff {' '.join(revs)} failed: {message}")
+    prefix = _show_prefix(project)
+    changes = _parse_git_patch(result.stdout.decode("utf-8", errors="replace"))
+
+    wanted = set(files or [])
+    out = []
+    truncated = False
+    total_changed = 0
+    for change in changes:
+        rel = change["file"][len(prefix):] if change["file"].startswith(prefix) else change["file"]
+        if wanted and rel not in wanted:
+            continue
+        if len(out) >= max_files:
+            truncated = True
+            break
+        entry = {
+            "path": rel,
+            "status": change["status"],
+            "hunks": change["hunks"],
+            "symbols": [],
+            "changed_symbols": [],
+            "approximate": False,
+        }
+        if change["status"] != "deleted":
+            source = _new_source(project, change["file"], rel, head)
+            if source is not None:
+                symbols, approximate = _outline(source, Path(rel).suffix.lower(), rel)
+                _mark_changed(symbols, change["hunks"])
+                entry["symbols"] = symbols
+                entry["changed_symbols"] = [s["name"] for s in symbols if s["changed"]]
+                entry["approximate"] = approximate
+        total_changed += len(entry["changed_symbols"])
+        out.append(entry)
+
+    return {
+        "project": project,
+        "base": base,
+        "head": head or "working tree",
+        "files": out,
+        "total_files": len(out),
+        "total_changed_symbols": total_changed,
+        "truncated": truncated,
+    }
+
+
+def _show_prefix(project: str) -> str:
+    """The project's path within its repository, as diff paths carry it."""
+    result = subprocess.run(
+        ["git", "rev-parse", "--show-prefix"], capture_output=True, text=True, cwd=project, timeout=5,
+    )
+    return result.stdout.strip() if result.returncode == 0 else ""
+
+
+def _new_source(project: str, repo_path: str, rel: str, head: str | None) -> str | None:
+    """The file's text on the diff's new side, or None if unreadable."""
+    if head is None:
+        try:
+            return (Path(project) / rel).read_text(errors="replace")
+        except OSError:
+            return None
+    result = subprocess.run(
+        ["git", "show", f"{head}:{repo_path}"], capture_output=True, cwd=project, timeout=10,
+    )
+    if result.returncode != 0:
+        return None
+    return result.stdout.decode("utf-8", errors="replace")
+
+
+def _outline(source: str, ext: str, name: str) -> tuple[list[dict], bool]:
+    """Symbols with line spans, and whether the spans are approximate."""
+    usage.count_parse()
+    if ext == ".py":
+        symbols = _extract_python_symbol_ranges_from_source(source, name)
+        return [
+            {"name": s["name"], "type": s["type"], "line": s["line"], "start": s["start"], "end": s["end"]}
+            for s in symbols
+        ], False
+
+    extraction = _regex.extract_source(source, ext)
+    starts = sorted((f.line_number, f.name) for f in extraction.functions)
+    total = source.count("\n") + 1
+    symbols = []
+    for i, (line, fname) in enumerate(starts):
+        end = starts[i + 1][0] - 1 if i + 1 < len(starts) else total
+        symbols.append({"name": fname, "type": "function", "line": line, "start": line, "end": max(line, end)})
+    return symbols, True
+
+
+def _mark_changed(symbols: list[dict], hunks: list[dict]) -> None:
+    """Set changed on symbols a hunk's new side overlaps. A pure deletion
+    (new_count 0) sits after new_start, so it marks the symbol spanning
+    new_start and the line that follows."""
+    ranges = _hunks_to_new_line_ranges(hunks)
+    deletions = [int(h["new_start"]) for h in hunks if int(h["new_count"]) == 0]
+    for s in symbols:
+        s["changed"] = _range_overlaps_any(ranges, s["start"], s["end"]) or any(
+            s["start"] <= after < s["end"] for after in deletions
+        )
+        del s["start"]
@@ -0,0 +1,166 @@
+"""Documentation coverage: exported symbols without doc comments.
+
+Public symbols per language:
+
+- python: module-level functions and classes, and methods of public classes,
+  whose names do not start with ``_``; documented means a docstring.
+- go: exported top-level funcs, methods on exported types, and types;
+  documented means a ``//`` comment directly above (``//go:`` directives are
+  skipped).
+- typescript/javascript: ``export``ed functions, classes, and arrow-function
+  consts; documented means a ``/** */`` or ``//`` comment directly above.
+- rust: ``pub`` fns, structs, enums, and traits; documented means ``///``
+  (attributes are skipped).
+
+Undocumented symbols are ranked by how many functions call them in the project
+call graph, then by textual call sites, so the most-used gaps come first.
+"""
+
+import ast
+import logging
+import re
+from collections import Counter
+from pathlib import Path
+
+from .change_impact import _scan_project_files, is_test_file
+
+logger = logging.getLogger(__name__)
+
+_GO_DECL = re.compile(r"^(?:func\s+(?:\(\s*\w*\s*\*?\s*(\w+)[^)]*\)\s*)?([A-Z]\w*)\s*[\[(]|type\s+([A-Z]\w*)\b)")
+_JS_DECL = re.compile(
+    r"^export\s+(?:default\s+)?(?:(?:async\s+)?function\*?\s+(\w+)|(?:abstract\s+)?class\s+(\w+)"
+    r"|(?:const|let)\s+(\w+)\s*=\s*(?:async\s*)?(?:\([^)]*\)|\w+)\s*=>)"
+)
+_RS_DECL = re.compile(r"^\s*pub(?:\([^)]*\))?\s+(?:(?:async|const|unsafe)\s+)*(fn|struct|enum|trait)\s+(\w+)")
+_CALL = re.compile(r"\b([A-Za-z_]\w*)\s*\(")
+
+
+def analyze_doc_coverage(project_path: str, language: str = "python", top: int = 50) -> dict:
+    """Report doc coverage of public symbols.
+
+    Returns {"language", "total", "documented", "coverage", "files",
+    "undocumented", "undocumented_count"}; files maps each file to {"total", "documented"} and
+    undocumented lists the top-ranked gaps as {"file", "name", "kind", "line",
+    "callers", "call_sites"}.
+    """
+    project = Path(project_path).resolve()
+    symbols = []
+    sources = {}
+    for path in _scan_project_files(str(project), language=language):
+        rel = Path(path).relative_to(project).as_posix()
+        if is_test_file(rel):
+            continue
+        try:
+            src = Path(path).read_text(encoding="utf-8", errors="replace")
+        except OSError:
+            continue
+        sources[rel] = src
+        for name, kind, line, documented in _public_symbols(src, language):
+            symbols.append({"file": rel, "name": name, "kind": kind, "line": line, "documented": documented})
+
+    files: dict[str, dict] = {}
+    for s in symbols:
+        f = files.setdefault(s["file"], {"total": 0, "documented": 0})
+        f["total"] += 1
+        f["documented"] += s["documented"]
+
+    total = len(symbols)
+    documented = sum(s["documented"] for s in symbols)
+    undocumented = [s for s in symbols if not s["documented"]]
+    if undocumented:
+        callers = _caller_counts(project, language)
+        call_sites = Counter()
+        for src in sources.values():
+            call_sites.update(_CALL.findall(src))
+        for s in undocumented:
+            del s["documented"]
+            short = s["name"].rsplit(".", 1)[-1]
+            s["callers"] = callers.get((s["file"], s["name"])) or callers.get((s["file"], short)) or callers.get(short, 0)
+            # Each function declaration also matches the call pattern.
+            s["call_sites"] = max(call_sites.get(short, 0) - (s["kind"] in ("function", "method")), 0)
+        undocumented.sort(key=lambda s: (-s["callers"], -s["call_sites"], s["file"], s["line"]))
+
+    return {
+        "language": language,
+        "total": total,
+        "documented": documented,
+        "coverage": round(documented / total, 3) if total else 1.0,
+        "files": dict(sorted(files.items())),
+        "undocumented": undocumented[:top],
+        "undocumented_count": len(undocumented),
+    }
+
+
+def _public_symbols(src: str, language: str):
+    """Yield (name, kind, line, documented) for public symbols in src."""
+    if language == "python":
+        yield from _python_symbols(src)
+        return
+    lines = src.splitlines()
+    for i, line in enumerate(lines):
+        if language == "go":
+            m = _GO_DECL.match(line)
+            if not m:
+                continue
+            receiver, func, typ = m.groups()
+            if receiver and not receiver[0].isupper():
+                continue
+            kind = "type" if typ else ("method" if receiver else "function")
+            name = typ or (f"{receiver}.{func}" if receiver else func)
+            yield name, kind, i + 1, _commented_above(lines, i, "//", skip=("//go:",))
+        elif language in ("typescript", "javascript"):
+            m = _JS_DECL.match(line)
+            if m:
+                func, cls, const = m.groups()
+                kind = "class" if cls else "function"
+                yield func or cls or const, kind, i + 1, _commented_above(lines, i, ("*/", "//"))
+        elif language == "rust":
+            m = _RS_DECL.match(line)
+            if m:
+                kind = "function" if m.group(1) == "fn" else m.group(1)
+                yield m.group(2), kind, i + 1, _commented_above(lines, i, "///", skip=("#[",))
+
+
+def _python_symbols(src: str):
+    try:
+        tree = ast.parse(src)
+    except SyntaxError:
+        return
+    for node in tree.body:
+        if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)) and not node.name.startswith("_"):
+            yield node.name, "function", node.lineno, bool(ast.get_docstring(node))
+        elif isinstance(node, ast.ClassDef) and not node.name.startswith("_"):
+            yield node.name, "class", node.lineno, bool(ast.get_docstring(node))
+            for item in node.body:
+                if isinstance(item, (ast.FunctionDef, ast.AsyncFunctionDef)) and not item.name.startswith("_"):
+                    yield f"{node.name}.{item.name}", "method", item.lineno, bool(ast.get_docstring(item))
+
+
+def _commented_above(lines: list[str], i: int, markers, skip=()) -> bool:
+    """Whether the nearest line above i, ignoring skip-prefixed lines, is a
+    comment. For "*/" the marker is matched at the end of the line."""
+    if isinstance(markers, str):
+        markers = (markers,)
+    j = i - 1
+    while j >= 0 and skip and lines[j].strip().startswith(skip):
+        j -= 1
+    if j < 0:
+        return False
+    prev = lines[j].strip()
+    return any(prev.endswith(m) if m == "*/" else prev.startswith(m) for m in markers)
+
+
+def _caller_counts(project: Path, language: str) -> dict:
+    """Distinct callers per (file, name) and per bare name."""
+    from .cross_file_calls import build_project_call_graph
+
+    try:
+        graph = build_project_call_graph(str(project), language=language)
+    except Exception as e:
+        logger.debug("doc_coverage: call graph failed: %s", e)
+        return {}
+    callers: dict = {}
+    for src_file, src_fn, dst_file, dst_fn in graph.edges:
+        callers.setdefault((dst_file, dst_fn), set()).add((src_file, src_fn))
+        callers.setdefault(dst_fn, set()).add((src_file, src_fn))
+    return {k: len(v) for k, v in callers.items()}
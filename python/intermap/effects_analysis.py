@@ -0,0 +1,113 @@
+"""Capability classification of functions by call-graph reachability.
+
+A function has a capability directly when its body calls a known sink API
+for it (``side_effects``), and transitively when it calls, through any
+chain of project functions, a function that has it directly. Each
+transitive capability is reported with the shortest such chain, so a
+reviewer can see why ``handle_request`` counts as touching the network.
+
+Direct effects come from ``describe_symbol`` summaries and share their
+cache on the ``GraphStore``; edges are the store's call graph.
+"""
+
+from __future__ import annotations
+
+from collections import deque
+
+from .graph_store import get_store
+from .side_effects import CAPABILITIES
+from .symbol_summary import project_summaries
+
+
+def analyze_effects(
+    project: str,
+    language: str,
+    capabilities: list[str] | None = None,
+    files: list[str] | None = None,
+    include_pure: bool = False,
+    max_results: int = 1000,
+) -> dict:
+    """Classify the project's functions by capability.
+
+    Args:
+        project: Project root
+        language: Project language
+        capabilities: Only report these capabilities (default all)
+        files: Only report functions defined in these project-relative files
+        include_pure: Also list functions with no capability
+        max_results: Maximum functions to return
+
+    Returns:
+        Dict with functions ({symbol, file, line, id, capabilities, direct,
+        transitive}), by_capability (direct and transitive counts per
+        capability over all functions), and truncated.
+    """
+    wanted = [c for c in CAPABILITIES if not capabilities or c in capabilities]
+    store = get_store(project, language)
+    if not store.is_current():
+        store.sync()
+
+    nodes = project_summaries(store, language)
+
+    callers: dict[tuple[str, str], set[tuple[str, str]]] = {}
+    for src_file, src_func, dst_file, dst_func in store.edges():
+        src, dst = (src_file, src_func), (dst_file, dst_func)
+        if src in nodes and dst in nodes and src != dst:
+            callers.setdefault(dst, set()).add(src)
+
+    # next_hop[cap][f] is the callee on f's shortest path to a direct sink.
+    next_hop: dict[str, dict[tuple[str, str], tuple[str, str] | None]] = {}
+    for cap in wanted:
+        hops = {key: None for key, s in nodes.items() if cap in s["effects"]}
+        queue = deque(sorted(hops))
+        while queue:
+            key = queue.popleft()
+            for caller in sorted(callers.get(key, ())):
+                if caller not in hops:
+                    hops[caller] = key
+                    queue.append(caller)
+        next_hop[cap] = hops
+
+    by_capability = {cap: {"direct": 0, "transitive": 0} for cap in wanted}
+    functions = []
+    for key in sorted(nodes):
+        summary = nodes[key]
+        direct = {}
+        transitive = {}
+        for cap in wanted:
+            hops = next_hop[cap]
+            if key not in hops:
+                continue
+            if hops[key] is None:
+                direct[cap] = summary["effects"][cap]
+                by_capability[cap]["direct"] += 1
+                continue
+            chain = []
+            cur = hops[key]
+            while cur is not None:
+                chain.append(f"{cur[0]}:{cur[1]}")
+                sink = cur
+                cur = hops[cur]
+            transitive[cap] = {"via": chain, "apis": nodes[sink]["effects"][cap]}
+            by_capability[cap]["transitive"] += 1
+
+        if files and key[0] not in files:
+            continue
+        if not direct and not transitive and not include_pure:
+            continue
+        functions.append({
+            "symbol": key[1],
+            "file": key[0],
+            "line": summary["line"],
+            "id": summary["id"],
+            "capabilities": [c for c in wanted if c in direct or c in transitive],
+            "direct": direct,
+            "transitive": transitive,
+        })
+
+    return {
+        "functions": functions[:max_results],
+        "by_capability": by_capability,
+        "analyzed": len(nodes),
+        "truncated": len(functions) > max_results,
+    }
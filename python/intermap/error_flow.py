@@ -0,0 +1,300 @@
+"""Where errors are created, wrapped, swallowed, or turned into panics.
+
+Each function body is scanned for error sites:
+
+- ``created``: a new error (``raise ValueError(...)``, ``errors.New``,
+  ``fmt.Errorf`` without ``%w``, ``throw new Error``, ``Err(...)``, ``bail!``)
+- ``wrapped``: an error re-raised with context (``raise ... from e``,
+  ``fmt.Errorf("...: %w", err)``, ``errors.Wrap``, ``{ cause }``,
+  ``.context()``, ``.map_err()``)
+- ``swallowed``: a handler that drops the error: an ``except`` or ``catch``
+  that neither re-raises, uses, nor logs it, a Go ``if err != nil`` block
+  that never mentions ``err``, a Rust ``let _ =`` or ``.ok();``
+- ``panic``: an error turned into a crash (``panic``, ``log.Fatal``,
+  ``unwrap()``, ``expect()``, ``sys.exit`` or ``process.exit`` in a handler)
+
+Swallowed sites are then placed on the call graph: a forward BFS from the
+entry points (``main`` functions and roots, i.e. functions that no code
+outside test files calls, or the caller's own list) gives each
+reachable site the shortest path from an entry. Reachable sites come first,
+since those are the failures a user can trigger and never hear about.
+"""
+
+from __future__ import annotations
+
+import ast
+import re
+from collections import deque
+
+from .change_impact import is_test_file
+from .graph_store import get_store
+from .symbol_summary import _blank, _close, project_summaries
+
+KINDS = ("created", "wrapped", "swallowed", "panic")
+
+_LOGGED = re.compile(r"\b(?:log|logger|logging|slog|console\.(?:error|warn)|eprintln!|tracing|warn!|error!)\b")
+_ERR_CHECK = re.compile(r"\b(\w*[eE]rr\w*)\s*!=\s*nil\s*\{")
+_GO_SITES = [
+    (re.compile(r"\b(?:errors\.(?:Wrap|Wrapf|WithMessage|WithMessagef|WithStack|Join))\s*\("), "wrapped"),
+    (re.compile(r"\b(?:errors\.New|fmt\.Errorf)\s*\("), "created"),
+    (re.compile(r"\b(?:panic|log\.(?:Fatal|Fatalf|Fatalln|Panic|Panicf|Panicln))\s*\("), "panic"),
+]
+_JS_SITES = [
+    (re.compile(r"\bthrow\s+new\s+\w+\s*\(|\bPromise\.reject\s*\("), "created"),
+    (re.compile(r"\bprocess\.exit\s*\("), "panic"),
+]
+_RUST_SITES = [
+    (re.compile(r"\.(?:context|with_context|map_err|wrap_err|wrap_err_with)\s*\("), "wrapped"),
+    (re.compile(r"\b(?:bail!|anyhow!|eyre!)\s*\(|(?<![\w.])Err\s*\((?!\s*\w+\s*\))(?!\s*(?:anyhow|eyre)!)"), "created"),
+    (re.compile(r"\.(?:unwrap|expect)\s*\(|\bpanic!\s*\("), "panic"),
+    (re.compile(r"\blet\s+_\s*=|\.ok\s*\(\s*\)\s*;"), "swallowed"),
+]
+_PY_EXITS = {"sys.exit", "os._exit", "os.abort", "exit", "quit"}
+
+
+def error_flow(
+    project: str,
+    language: str,
+    kinds: list[str] | None = None,
+    files: list[str] | None = None,
+    entry_points: list[str] | None = None,
+    max_results: int = 500,
+) -> dict:
+    """Map the project's error sites and rank swallowed ones by reachability.
+
+    Args:
+        project: Project root
+        language: Project language
+        kinds: Only these site kinds (created, wrapped, swallowed, panic)
+        files: Only report sites in these project-relative files
+        entry_points: Entry function names or Scope.names (default: main
+            functions and functions with no callers outside test files)
+        max_results: Maximum sites and swallowed sites to return, each
+
+    Returns:
+        Dict with sites ({kind, file, line, function, detail}) for created,
+        wrapped, and panic sites; swallowed (the same plus reachable and,
+        when reachable, path from an entry); by_kind counts over the whole
+        project; entry_points (how many); and truncated.
+    """
+    wanted = [k for k in KINDS if not kinds or k in kinds]
+    store = get_store(project, language)
+    if not store.is_current():
+        store.sync()
+
+    nodes = project_summaries(store, language)
+    by_file: dict[str, list[tuple[str, dict]]] = {}
+    for (rel, qual), summary in nodes.items():
+        by_file.setdefault(rel, []).append((qual, summary))
+
+    sites = []
+    for rel in sorted(by_file):
+        try:
+            source = (store.root / rel).read_text(encoding="utf-8", errors="replace")
+        except OSError:
+            continue
+        for line, kind, detail in sorted(_scan(source, language)):
+            func = _enclosing(by_file[rel], line)
+            if func:
+                sites.append({"kind": kind, "file": rel, "line": line, "function": func, "detail": detail})
+
+    callees: dict[tuple[str, str], set[tuple[str, str]]] = {}
+    called: set[tuple[str, str]] = set()
+    for src_file, src_func, dst_file, dst_func in store.edges():
+        src, dst = (src_file, src_func), (dst_file, dst_func)
+        if src in nodes and dst in nodes and src != dst:
+            callees.setdefault(src, set()).add(dst)
+            if not is_test_file(src_file):
+                called.add(dst)
+
+    if entry_points:
+        entries = {k for k in nodes if k[1] in entry_points or k[1].rsplit(".", 1)[-1] in entry_points}
+    else:
+        entries = {
+            k for k in nodes
+            if not is_test_file(k[0]) and (k[1] == "main" or k not in called)
+        }
+    parent: dict[tuple[str, str], tuple[str, str] | None] = {k: None for k in entries}
+    queue = deque(sorted(entries))
+    while queue:
+        key = queue.popleft()
+        for nxt in sorted(callees.get(key, ())):
+            if nxt not in parent:
+                parent[nxt] = key
+                queue.append(nxt)
+
+    by_kind = {k: 0 for k in wanted}
+    listed, swallowed = [], []
+    for site in sites:
+        if site["kind"] not in by_kind:
+            continue
+        by_kind[site["kind"]] += 1
+        if files and site["file"] not in files:
+            continue
+        if site["kind"] != "swallowed":
+            listed.append(site)
+            continue
+        key = (site["file"], site["function"])
+        site["reachable"] = key in parent
+        if key in parent:
+            path = []
+            cur = key
+            while cur is not None:
+                path.append(f"{cur[0]}:{cur[1]}")
+                cur = parent[cur]
+            site["path"] = path[::-1]
+        swallowed.append(site)
+    swallowed.sort(key=lambda s: (not s["reachable"], len(s.get("path", ())), s["file"], s["line"]))
+
+    return {
+        "sites": listed[:max_results],
+        "swallowed": swallowed[:max_results],
+        "by_kind": by_kind,
+        "entry_points": len(entries),
+        "truncated": len(listed) > max_results or len(swallowed) > max_results,
+    }
+
+
+def _enclosing(funcs: list[tuple[str, dict]], line: int) -> str:
+    """The innermost function of funcs whose body spans line."""
+    best = None
+    for qual, summary in funcs:
+        if summary["line"] <= line <= summary.get("end_line", summary["line"]):
+            if best is None or summary["line"] >= best[1]:
+                best = (qual, summary["line"])
+    return best[0] if best else ""
+
+
+def _scan(source: str, language: str) -> list[tuple[int, str, str]]:
+    """Error sites in source as (line, kind, detail)."""
+    if language == "python":
+        return _scan_python(source)
+    code = _blank(source, language)
+    if language == "go":
+        return _scan_go(source, code)
+    if language in ("typescript", "javascript"):
+        return _scan_js(source, code)
+    if language == "rust":
+        return _scan_regex(source, code, _RUST_SITES)
+    return []
+
+
+# --- Python ---------------------------------------------------------------
+
+
+def _scan_python(source: str) -> list[tuple[int, str, str]]:
+    try:
+        tree = ast.parse(source)
+    except SyntaxError:
+        return []
+    out = []
+    handled: dict[int, ast.ExceptHandler] = {}
+    for node in ast.walk(tree):
+        if isinstance(node, ast.ExceptHandler):
+            # ast.walk is breadth-first, so nested handlers overwrite outer ones.
+            for inner in ast.walk(node):
+                handled[id(inner)] = node
+            out.extend(_python_handler(node))
+    for node in ast.walk(tree):
+        if not isinstance(node, ast.Raise) or node.exc is None:
+            continue
+        exc = node.exc.func if isinstance(node.exc, ast.Call) else node.exc
+        detail = f"raise {ast.unparse(exc)}"
+        handler = handled.get(id(node))
+        if node.cause is not None:
+            out.append((node.lineno, "wrapped", f"{detail} from {ast.unparse(node.cause)}"))
+        elif handler is not None and handler.name and _uses(node.exc, handler.name):
+            out.append((node.lineno, "wrapped", detail))
+        else:
+            out.append((node.lineno, "created", detail))
+    return out
+
+
+def _python_handler(node: ast.ExceptHandler) -> list[tuple[int, str, str]]:
+    caught = f"except {ast.unparse(node.type)}" if node.type else "except"
+    calls = [_dotted(n.func) for n in ast.walk(node) if isinstance(n, ast.Call)]
+    exits = [c for c in calls if c in _PY_EXITS]
+    if exits:
+        return [(node.lineno, "panic", f"{caught}: {exits[0]}()")]
+    if any(isinstance(n, ast.Raise) for n in ast.walk(node)):
+        return []
+    if node.name and any(_uses(stmt, node.name) for stmt in node.body):
+        return []
+    if any(_LOGGED.match(c) or c.endswith((".exception", ".error", ".warning")) for c in calls):
+        return []
+    first = node.body[0]
+    return [(node.lineno, "swallowed", f"{caught}: {ast.unparse(first).splitlines()[0]}")]
+
+
+def _uses(node: ast.AST, name: str) -> bool:
+    return any(isinstance(n, ast.Name) and n.id == name for n in ast.walk(node))
+
+
+def _dotted(node) -> str:
+    if isinstance(node, ast.Name):
+        return node.id
+    if isinstance(node, ast.Attribute):
+        inner = _dotted(node.value)
+        return f"{inner}.{node.attr}" if inner else ""
+    return ""
+
+
+# --- Brace languages --------------------------------------------------------
+
+
+def _line_of(code: str, offset: int) -> int:
+    return code.count("\n", 0, offset) + 1
+
+
+def _snippet(source: str, start: int) -> str:
+    end = source.find("\n", start)
+    return source[start:end if end >= 0 else len(source)].strip()
+
+
+def _scan_regex(source: str, code: str, table) -> list[tuple[int, str, str]]:
+    out = []
+    for pattern, kind in table:
+        for m in pattern.finditer(code):
+            out.append((_line_of(code, m.start()), kind, _snippet(source, m.start())))
+    return out
+
+
+def _scan_go(source: str, code: str) -> list[tuple[int, str, str]]:
+    out = []
+    for pattern, kind in _GO_SITES:
+        for m in pattern.finditer(code):
+            found = kind
+            if m.group(0).startswith("fmt.Errorf"):
+                args = source[m.end():_close(code, m.end() - 1)]
+                found = "wrapped" if "%w" in args else "created"
+            out.append((_line_of(code, m.start()), found, _snippet(source, m.start())))
+    for m in _ERR_CHECK.finditer(code):
+        body = code[m.end():_close(code, m.end() - 1)]
+        if re.search(rf"\b{re.escape(m.group(1))}\b", body) or _LOGGED.search(body):
+            continue
+        if re.search(r"\b(?:panic|os\.Exit)\s*\(", body):
+            continue
+        detail = f"if {m.group(1)} != nil {{ {' '.join(body.split())} }}"
+        out.append((_line_of(code, m.start()), "swallowed", detail[:120]))
+    return out
+
+
+def _scan_js(source: str, code: str) -> list[tuple[int, str, str]]:
+    out = []
+    for line, kind, detail in _scan_regex(source, code, _JS_SITES):
+        if kind == "created" and re.search(r"\bcause\s*:", detail):
+            kind = "wrapped"
+        out.append((line, kind, detail))
+    for m in re.finditer(r"(?<!\.)\bcatch\s*(?:\(\s*(\w+)?[^)]*\))?\s*\{", code):
+        body = code[m.end():_close(code, m.end() - 1)]
+        name = m.group(1)
+        if re.search(r"\bthrow\b", body) or _LOGGED.search(body):
+            continue
+        if name and re.search(rf"\b{re.escape(name)}\b", body):
+            continue
+        if re.search(r"\bprocess\.exit\s*\(", body):
+            continue
+        out.append((_line_of(code, m.start()), "swallowed", _snippet(source, m.start())))
+    for m in re.finditer(r"\.catch\s*\(\s*(?:\(\s*\)|_)\s*=>", code):
+        out.append((_line_of(code, m.start()), "swallowed", _snippet(source, m.start())))
+    return out
@@ -8,6 +8,7 @@ import ast
 import re
 from pathlib import Path
 
+from . import usage
 from .protocols import ClassInfo, FileExtractionResult, FunctionInfo
 
 
@@ -91,9 +92,10 @@ class BasicRegexExtractor:
 
     def extract(self, path: str) -> FileExtractionResult:
         p = Path(path)
-        ext = p.suffix.lower()
-        source = p.read_text(errors="replace")
+        return self.extract_source(p.read_text(errors="replace"), p.suffix.lower())
 
+    def extract_source(self, source: str, ext: str) -> FileExtractionResult:
+        """Extract from source text of a file with extension ext (".go")."""
         functions = []
         func_pattern = self.PATTERNS.get(ext)
         if func_pattern:
@@ -117,6 +119,7 @@ class DefaultExtractor:
         self._regex = BasicRegexExtractor()
 
     def extract(self, path: str) -> FileExtractionResult:
+        usage.count_parse()
         ext = Path(path).suffix.lower()
         if ext == ".py":
             return self._python.extract(path)
@@ -0,0 +1,201 @@
+"""Incrementally maintained call graph and definition index.
+
+The sidecar keeps one GraphStore per (project, language). The first use
+builds everything; later updates re-parse only the files reported changed
+(by ``live_changes`` or the caller) plus the files that call into them, and
+patch the stored function index, definitions, and edges in place.
+
+Consistency: the store remembers the mtime of every scanned file. After a
+patch, any file whose mtime moved but was not in the changed set is drift —
+the diff missed something — and the store falls back to a full rebuild.
+Callers may also pass the Go-side MtimeHash; an unchanged hash short-circuits
+the update entirely.
+"""
+
+from __future__ import annotations
+
+import os
+from pathlib import Path
+
+from .build_context import BuildContext, active
+from .cross_file_calls import (
+    build_function_index,
+    build_project_call_graph,
+    definitions_for_file,
+    index_file,
+    scan_project,
+)
+from .workspace import load_workspace_config
+
+_STORES: dict[tuple[str, str, BuildContext | None], "GraphStore"] = {}
+
+
+def get_store(project: str, language: str, create: bool = True) -> "GraphStore | None":
+    """Return the store for (project, language) under the active Go build
+    context, building it on first use."""
+    key = (str(Path(project).resolve()), language, active())
+    store = _STORES.get(key)
+    if store is None and create:
+        store = GraphStore(key[0], language)
+        store.rebuild()
+        _STORES[key] = store
+    return store
+
+
+def clear_stores() -> None:
+    """Drop all stores (tests and memory pressure)."""
+    _STORES.clear()
+
+
+class GraphStore:
+    """Call graph, function index, and definitions for one project/language."""
+
+    def __init__(self, root: str, language: str):
+        self.root = Path(root)
+        self.language = language
+        self.func_index: dict = {}
+        self.definitions: dict[str, list[dict]] = {}
+        self.edges_by_file: dict[str, set[tuple[str, str, str, str]]] = {}
+        self.mtimes: dict[str, int] = {}
+        self.mtime_hash = ""
+        # describe_symbol summaries keyed by (file, qualified name, line).
+        self.summaries: dict[tuple[str, str, int], dict] = {}
+
+    # --- queries -----------------------------------------------------------
+
+    def edges(self) -> set[tuple[str, str, str, str]]:
+        """All edges as (src_file, src_func, dst_file, dst_func)."""
+        out: set[tuple[str, str, str, str]] = set()
+        for edges in self.edges_by_file.values():
+            out |= edges
+        return out
+
+    def definition_list(self, max_files: int = 0) -> list[dict]:
+        """Flat definition list in file order, optionally capped by file count."""
+        files = sorted(self.definitions)
+        if max_files:
+            files = files[:max_files]
+        return [d for f in files for d in self.definitions[f]]
+
+    def is_current(self) -> bool:
+        """True if no scanned file was added, removed, or touched since the last sync."""
+        return self._scan_mtimes() == self.mtimes
+
+    # --- updates -----------------------------------------------------------
+
+    def rebuild(self) -> dict:
+        """Full rebuild of index, definitions, and edges."""
+        workspace_config = load_workspace_config(self.root)
+        self.func_index = build_function_index(self.root, self.language, workspace_config)
+        graph = build_project_call_graph(self.root, self.language, func_index=self.func_index)
+
+        self.edges_by_file = {}
+        for edge in graph.edges:
+            self.edges_by_file.setdefault(edge[0], set()).add(edge)
+
+        self.mtimes = self._scan_mtimes()
+        self.summaries = {}
+        self.definitions = {}
+        for rel in self.mtimes:
+            self.definitions[rel] = definitions_for_file(self.root / rel, Path(rel), self.language)
+
+        return {"mode": "full", "files": len(self.mtimes), "edge_count": sum(len(e) for e in self.edges_by_file.values())}
+
+    def update(self, changed: list[str], mtime_hash: str = "") -> dict:
+        """Patch the store for changed project-relative files.
+
+        Returns a summary with the mode used ("unchanged", "incremental", or
+        "full"), the files re-parsed, edge deltas, and any drifted files.
+        """
+        if mtime_hash and mtime_hash == self.mtime_hash:
+            return {"mode": "unchanged", "reparsed": [], "edges_added": 0, "edges_removed": 0, "drift": []}
+
+        current = self._scan_mtimes()
+        changed_set = {c for c in changed if c in current or c in self.mtimes}
+
+        drift = sorted(
+            rel for rel in set(current) | set(self.mtimes)
+            if current.get(rel) != self.mtimes.get(rel) and rel not in changed_set
+        )
+        if drift:
+            summary = self.rebuild()
+            summary.update({"reparsed": [], "edges_added": 0, "edges_removed": 0, "drift": drift})
+            self.mtime_hash = mtime_hash
+            return summary
+
+        # Callers of changed files may resolve differently once definitions
+        # move, so re-resolve their outgoing calls too.
+        dependents = {
+            src for src, edges in self.edges_by_file.items()
+            if any(e[2] in changed_set for e in edges)
+        }
+        reparse = (changed_set | dependents) & set(current)
+
+        self.summaries = {k: v for k, v in self.summaries.items() if k[0] not in changed_set}
+        for rel in changed_set:
+            for key in [k for k, v in self.func_index.items() if v == rel]:
+                del self.func_index[key]
+            self.definitions.pop(rel, None)
+        for rel in changed_set & set(current):
+            index_file(self.root / rel, Path(rel), self.language, self.func_index)
+            self.definitions[rel] = definitions_for_file(self.root / rel, Path(rel), self.language)
+
+        before = sum(len(self.edges_by_file.get(rel, ())) for rel in changed_set | reparse)
+        for rel in changed_set | reparse:
+            self.edges_by_file.pop(rel, None)
+        graph = build_project_call_graph(
+            self.root, self.language, only_files=reparse, func_index=self.func_index,
+        )
+        for edge in graph.edges:
+            self.edges_by_file.setdefault(edge[0], set()).add(edge)
+        after = sum(len(self.edges_by_file.get(rel, ())) for rel in reparse)
+
+        self.mtimes = current
+        self.mtime_hash = mtime_hash
+        return {
+            "mode": "incremental",
+            "reparsed": sorted(reparse),
+            "edges_added": max(after - before, 0),
+            "edges_removed": max(before - after, 0),
+            "drift": [],
+        }
+
+    def sync(self) -> dict:
+        """Patch the store for every file added, removed, or touched since the last sync."""
+        current = self._scan_mtimes()
+        changed = [rel for rel in set(current) | set(self.mtimes) if current.get(rel) != self.mtimes.get(rel)]
+        return self.update(changed)
+
+    def _scan_mtimes(self) -> dict[str, int]:
+        out: dict[str, int] = {}
+        workspace_config = load_workspace_config(self.root)
+        for f in scan_project(self.root, self.language, workspace_config):
+            try:
+                out[str(Path(f).relative_to(self.root))] = os.stat(f).st_mtime_ns
+            except OSError:
+                continue
+        return out
+
+
+def index_update(project: str, language: str, files: list[str] | None = None,
+                 baseline: str = "HEAD", mtime_hash: str = "") -> dict:
+    """Sync the store for project, using git changes when files is not given."""
+    store = get_store(project, language, create=False)
+    if store is None:
+        store = get_store(project, language)
+        summary = {"mode": "full", "reparsed": [], "edges_added": 0, "edges_removed": 0, "drift": []}
+        store.mtime_hash = mtime_hash
+    else:
+        if files is None:
+            from .live_changes import get_live_changes
+            changes = get_live_changes(project, baseline=baseline, language=language)
+            files = [c["file"] for c in changes.get("changes", [])]
+        summary = store.update(files, mtime_hash=mtime_hash)
+
+    summary.update({
+        "project": str(store.root),
+        "language": language,
+        "files": len(store.mtimes),
+        "edge_count": len(store.edges()),
+    })
+    return summary
@@ -0,0 +1,169 @@
+"""Test-results history: per-test-file duration and flakiness.
+
+Two input formats are accepted:
+
+- JUnit XML: a single file or a directory of ``*.xml`` files. Each file is
+  one run. Test cases are mapped to files via the ``file`` attribute, or by
+  resolving ``classname`` (dotted module, or Go import path) under the project.
+- JSON ledger: a list of records (or ``{"runs": [...]}``) of the form
+  ``{"run": "ci-123", "file": "tests/test_a.py", "test": "test_x",
+  "duration": 1.2, "outcome": "passed" | "failed" | "skipped"}``.
+
+A test is flaky when it both passed and failed across runs; one that always
+fails is broken, not flaky.
+"""
+
+import json
+import logging
+import xml.etree.ElementTree as ET
+from collections import defaultdict
+from pathlib import Path
+
+logger = logging.getLogger(__name__)
+
+
+def load_history(path: str, project_path: str) -> dict[str, dict]:
+    """Load history and aggregate it per project-relative test file.
+
+    Returns {file: {"runs", "avg_duration", "failure_rate", "flaky",
+    "flaky_tests"}}.
+    """
+    src = Path(path)
+    project = Path(project_path).resolve()
+    if src.is_dir():
+        records = []
+        for xml_file in sorted(src.glob("*.xml")):
+            records.extend(_junit_records(xml_file, xml_file.name, project))
+    elif src.suffix == ".xml":
+        records = _junit_records(src, src.name, project)
+    else:
+        records = _ledger_records(src)
+    return aggregate(records)
+
+
+def aggregate(records: list[dict]) -> dict[str, dict]:
+    """Aggregate test records into per-file stats."""
+    # file -> run -> duration; file -> test -> outcomes
+    durations: dict[str, dict[str, float]] = defaultdict(lambda: defaultdict(float))
+    outcomes: dict[str, dict[str, set]] = defaultdict(lambda: defaultdict(set))
+    run_fail: dict[str, dict[str, bool]] = defaultdict(dict)
+
+    for r in records:
+        f, run = r.get("file"), str(r.get("run", ""))
+        if not f:
+            continue
+        outcome = r.get("outcome", "passed")
+        if outcome == "skipped":
+            continue
+        durations[f][run] += float(r.get("duration") or 0)
+        outcomes[f][r.get("test", "")].add(outcome)
+        run_fail[f][run] = run_fail[f].get(run, False) or outcome == "failed"
+
+    out = {}
+    for f, by_run in durations.items():
+        runs = len(by_run)
+        flaky_tests = sorted(t for t, o in outcomes[f].items() if {"passed", "failed"} <= o)
+        out[f] = {
+            "runs": runs,
+            "avg_duration": round(sum(by_run.values()) / runs, 3),
+            "failure_rate": round(sum(run_fail[f].values()) / runs, 3),
+            "flaky": bool(flaky_tests),
+            "flaky_tests": flaky_tests,
+        }
+    return out
+
+
+def order_tests(tests: list[str], metadata: dict[str, dict]) -> list[str]:
+    """Order tests fastest-first, with unknown tests after known stable ones
+    and flaky tests last."""
+    def key(t):
+        m = metadata.get(t)
+        if m is None:
+            return (1, 0.0, t)
+        return (2 if m["flaky"] else 0, m["avg_duration"], t)
+    return sorted(tests, key=key)
+
+
+def _ledger_records(path: Path) -> list[dict]:
+    try:
+        data = json.loads(path.read_text(encoding="utf-8"))
+    except (OSError, ValueError) as e:
+        raise ValueError(f"read test history {path}: {e}") from e
+    if isinstance(data, dict):
+        data = data.get("runs", [])
+    return [r for r in data if isinstance(r, dict)]
+
+
+def _junit_records(path: Path, run: str, project: Path) -> list[dict]:
+    try:
+        root = ET.parse(path).getroot()
+    except (OSError, ET.ParseError) as e:
+        logger.debug("junit history: %s: %s", path, e)
+        return []
+    resolver = _ClassnameResolver(project)
+    records = []
+    for case in root.iter("testcase"):
+        f = case.get("file") or resolver.resolve(case.get("classname", ""), case.get("name", ""))
+        if not f:
+            continue
+        if case.find("skipped") is not None:
+            outcome = "skipped"
+        elif case.find("failure") is not None or case.find("error") is not None:
+            outcome = "failed"
+        else:
+            outcome = "passed"
+        records.append({
+            "run": run,
+            "file": Path(f).as_posix(),
+            "test": case.get("name", ""),
+            "duration": float(case.get("time") or 0),
+            "outcome": outcome,
+        })
+    return records
+
+
+class _ClassnameResolver:
+    """Map JUnit classnames to project-relative test files."""
+
+    def __init__(self, project: Path):
+        self.project = project
+        self.cache: dict[tuple[str, str], str | None] = {}
+        self.go_sources: dict[Path, list[tuple[str, str]]] = {}
+
+    def resolve(self, classname: str, name: str) -> str | None:
+        key = (classname, name.split("/")[0])
+        if key not in self.cache:
+            self.cache[key] = self._resolve(*key)
+        return self.cache[key]
+
+    def _resolve(self, classname: str, func: str) -> str | None:
+        if not classname:
+            return None
+        # pytest: "tests.test_a" or "tests.test_a.TestCls"
+        parts = classname.split(".")
+        for n in range(len(parts), 0, -1):
+            candidate = Path(*parts[:n]).with_suffix(".py")
+            if (self.project / candidate).is_file():
+                return candidate.as_posix()
+        # go-junit-report: classname is the package import path. Take the
+        # longest suffix naming a project directory, then the _test.go file
+        # in it that declares the test function.
+        segs = classname.split("/")
+        dirs = [self.project / Path(*segs[i:]) for i in range(len(segs))]
+        for d in dirs + [self.project]:
+            if d.is_dir():
+                decl = f"func {func}("
+                for rel, src in self._go_tests(d):
+                    if decl in src:
+                        return rel
+                if d != self.project:
+                    return None
+        return None
+
+    def _go_tests(self, d: Path) -> list[tuple[str, str]]:
+        if d not in self.go_sources:
+            self.go_sources[d] = [
+                (p.relative_to(self.project).as_posix(), p.read_text(encoding="utf-8", errors="replace"))
+                for p in sorted(d.glob("*_test.go"))
+            ]
+        return self.go_sources[d]
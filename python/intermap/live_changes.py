@@ -1,4 +1,8 @@
-"""Live change awareness - git-diff based change detection with structural annotation."""
+"""Live change awareness - VCS-diff based change detection with structural annotation.
+
+Git repositories are diffed with git directly; Jujutsu and Mercurial
+repositories go through the vcs module, which produces git-format patches.
+"""
 
 import ast
 import logging
@@ -9,6 +13,7 @@ from collections import OrderedDict
 from collections.abc import Callable
 from pathlib import Path
 
+from . import vcs
 from .extractors import DefaultExtractor
 
 logger = logging.getLogger(__name__)
@@ -32,18 +37,20 @@ def get_live_changes(
 ) -> dict:
     """Detect changes since baseline and annotate with affected symbols.
 
-    Uses git diff to find changed files, then extracts which functions/classes
-    were affected by the changes (not just line numbers).
+    Uses the VCS diff to find changed files, then extracts which
+    functions/classes were affected by the changes (not just line numbers).
 
     Args:
-        project_path: Project root (must be in a git repo)
-        baseline: Git ref to diff against (HEAD, branch name, commit SHA)
+        project_path: Project root (must be in a git, jj, or hg repo)
+        baseline: Ref to diff against (HEAD, branch name, commit SHA); git's
+            HEAD forms are translated for jj and hg
         language: Language hint for extraction (auto-detects if "auto")
 
     Returns:
         Dict with project, baseline, changes list, and counts.
     """
     del language  # Reserved for future language-specific extraction controls.
+    vcs.check_ref(baseline)
 
     mode_raw = os.getenv("INTERMAP_LIVE_CHANGES_MODE", "optimized").strip().lower()
     if mode_raw not in _VALID_MODES:
@@ -57,11 +64,12 @@ def get_live_changes(
 
     optimized_mode = mode == "optimized"
     baseline_identity: str | None = None
-    changes = (
-        _get_git_diff_optimized(project_path, baseline)
-        if optimized_mode
-        else _get_git_diff_legacy(project_path, baseline)
-    )
+    if vcs.kind(project_path) in (vcs.JUJUTSU, vcs.MERCURIAL):
+        changes = _get_vcs_diff(project_path, baseline)
+    elif optimized_mode:
+        changes = _get_git_diff_optimized(project_path, baseline)
+    else:
+        changes = _get_git_diff_legacy(project_path, baseline)
 
     total_symbols = 0
     fallback_extractor: DefaultExtractor | None = None
@@ -318,6 +326,43 @@ def _get_git_diff_optimized(project_path: str, baseline: str) -> list[dict]:
         return []
 
 
+def _get_vcs_diff(project_path: str, baseline: str) -> list[dict]:
+    """Diff a jj or hg working copy and parse the git-format patch."""
+    patch = vcs.diff(project_path, baseline)
+    if patch is None:
+        _log_git_diff_failure("vcs", "patch", project_path, baseline)
+        return []
+    return _parse_git_patch(patch)
+
+
+def _parse_git_patch(patch: str) -> list[dict]:
+    """Parse a git-format patch, reading file status from its extended headers."""
+    files: dict[str, dict] = {}
+    current: dict | None = None
+    for line in patch.split("\n"):
+        if line.startswith("diff --git "):
+            match = re.match(r"diff --git a/(.*) b/(.*)$", line)
+            current = None
+            if match:
+                current = {"file": match.group(2), "status": "modified", "hunks": []}
+                files[current["file"]] = current
+            continue
+        if current is None:
+            continue
+        if line.startswith("new file mode"):
+            current["status"] = "added"
+        elif line.startswith("deleted file mode"):
+            current["status"] = "deleted"
+        elif line.startswith("rename from "):
+            current["status"] = "renamed"
+            current["old_file"] = line[len("rename from "):]
+        elif line.startswith("@@ "):
+            parsed_hunk = _parse_hunk_header(line)
+            if parsed_hunk is not None:
+                current["hunks"].append(parsed_hunk)
+    return list(files.values())
+
+
 def _parse_hunk_header(line: str) -> dict | None:
     old_match = re.search(r"-(\d+)(?:,(\d+))?", line)
     new_match = re.search(r"\+(\d+)(?:,(\d+))?", line)
@@ -639,6 +684,17 @@ def _extract_python_symbol_ranges_from_baseline(
         _BASELINE_SYMBOL_CACHE.move_to_end(cache_key)
         return cached[0]
 
+    if vcs.kind(project_path) in (vcs.JUJUTSU, vcs.MERCURIAL):
+        source = vcs.show(project_path, baseline_identity, rel_path)
+        if source is None:
+            return []
+        symbols = _extract_python_symbol_ranges_from_source(
+            source.decode("utf-8", errors="replace"),
+            f"{rel_path}@{baseline_identity}",
+        )
+        _put_baseline_symbol_cache_entry(cache_key, symbols)
+        return symbols
+
     try:
         result = subprocess.run(
             ["git", "show", f"{baseline_identity}:{rel_path}"],
@@ -646,6 +702,7 @@ def _extract_python_symbol_ranges_from_baseline(
             text=False,
             cwd=project_path,
             timeout=10,
+            env=vcs.no_fetch_env(),
         )
     except (subprocess.TimeoutExpired, FileNotFoundError) as e:
         logger.debug(
@@ -739,6 +796,8 @@ def _extract_python_symbol_ranges_from_source(source: str, filename: str) -> lis
 
 def _resolve_baseline_identity(project_path: str, baseline: str) -> str:
     """Resolve baseline ref to immutable commit identity for cache keying."""
+    if vcs.kind(project_path) in (vcs.JUJUTSU, vcs.MERCURIAL):
+        return vcs.resolve(project_path, baseline) or baseline
     try:
         result = subprocess.run(
             ["git", "rev-parse", f"{baseline}^{{commit}}"],
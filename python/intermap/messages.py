@@ -0,0 +1,175 @@
+"""User-facing message inventory: log messages, errors, CLI help, i18n keys.
+
+Python is parsed with ``ast``; Go, TypeScript, and JavaScript are matched
+with per-call regexes over string literals. Formatted strings keep their
+placeholders (``{name}`` for f-strings, ``%s``/``%v`` verbs as written), so
+the same message built in two places still compares equal.
+
+Kinds: ``log``, ``error``, ``cli_help``, ``i18n``.
+"""
+
+import ast
+import re
+from collections import defaultdict
+from pathlib import Path
+
+from .change_impact import _scan_project_files, is_test_file
+
+KINDS = ("log", "error", "cli_help", "i18n")
+
+_PY_LOG_METHODS = {"debug", "info", "warning", "warn", "error", "exception", "critical", "fatal"}
+_PY_LOG_OWNERS = {"logging", "logger", "log", "_logger", "_log", "LOGGER", "LOG"}
+_PY_I18N = {"_", "gettext", "ngettext", "pgettext", "gettext_lazy", "ugettext", "lazy_gettext"}
+
+# A Go string literal: interpreted or raw.
+_GO_STR = r'(?:"((?:[^"\\\n]|\\.)*)"|`([^`]*)`)'
+_GO_PATTERNS = [
+    ("log", re.compile(r"\b(?:log|slog|logger)\.(?:Print|Printf|Println|Fatal|Fatalf|Fatalln|Panic|Panicf|Debug|Info|Warn|Error|DebugContext|InfoContext|WarnContext|ErrorContext)\(\s*(?:ctx\s*,\s*)?" + _GO_STR)),
+    ("error", re.compile(r"\b(?:errors\.New|fmt\.Errorf|ValidationError|WrapError|NotFoundError)\(\s*" + _GO_STR)),
+    ("cli_help", re.compile(r"\bflag\.(?:String|Bool|Int|Int64|Uint|Float64|Duration)(?:Var)?\([^\n]*?,\s*" + _GO_STR + r"\s*\)\s*$", re.MULTILINE)),
+    ("cli_help", re.compile(r"\b(?:Short|Long|Usage)\s*:\s*" + _GO_STR)),
+    ("cli_help", re.compile(r"\bmcp\.(?:WithDescription|Description)\(\s*" + _GO_STR)),
+]
+
+# A JS/TS string literal: single, double, or template without expressions.
+_JS_STR = r"""(?:"((?:[^"\\\n]|\\.)*)"|'((?:[^'\\\n]|\\.)*)'|`([^`]*)`)"""
+_JS_PATTERNS = [
+    ("log", re.compile(r"\b(?:console|logger|log)\.(?:log|debug|info|warn|error|trace)\(\s*" + _JS_STR)),
+    ("error", re.compile(r"\bnew\s+\w*Error\(\s*" + _JS_STR)),
+    ("cli_help", re.compile(r"\.(?:description|option|requiredOption|command|usage)\(\s*(?:" + _JS_STR + r"\s*,\s*)?" + _JS_STR)),
+    ("i18n", re.compile(r"(?:\bi18n\.t|\bi18next\.t|\$t|\bt)\(\s*" + _JS_STR)),
+]
+
+
+def inventory_messages(
+    project_path: str,
+    language: str = "python",
+    kinds: list[str] | None = None,
+    max_results: int = 2000,
+) -> dict:
+    """Extract user-facing strings from non-test source files.
+
+    Returns {"messages", "count", "by_kind", "duplicates", "truncated"}; each
+    message is {"file", "line", "kind", "text"} and duplicates lists texts
+    that occur at more than one location.
+    """
+    project = Path(project_path).resolve()
+    wanted = set(kinds or KINDS)
+    messages = []
+    for path in _scan_project_files(str(project), language=language):
+        rel = Path(path).relative_to(project).as_posix()
+        if is_test_file(rel):
+            continue
+        try:
+            src = Path(path).read_text(encoding="utf-8", errors="replace")
+        except OSError:
+            continue
+        if path.endswith(".py"):
+            found = _python_messages(src)
+        elif path.endswith(".go"):
+            found = _regex_messages(src, _GO_PATTERNS)
+        elif path.endswith((".js", ".jsx", ".ts", ".tsx")):
+            found = _regex_messages(src, _JS_PATTERNS)
+        else:
+            continue
+        for line, kind, text in found:
+            if kind in wanted and text.strip():
+                messages.append({"file": rel, "line": line, "kind": kind, "text": text})
+
+    messages.sort(key=lambda m: (m["file"], m["line"]))
+    by_kind = defaultdict(int)
+    locations = defaultdict(list)
+    for m in messages:
+        by_kind[m["kind"]] += 1
+        locations[(m["kind"], m["text"])].append(f"{m['file']}:{m['line']}")
+    duplicates = [
+        {"kind": kind, "text": text, "locations": locs}
+        for (kind, text), locs in sorted(locations.items())
+        if len(locs) > 1
+    ]
+    return {
+        "messages": messages[:max_results],
+        "count": len(messages),
+        "by_kind": dict(sorted(by_kind.items())),
+        "duplicates": duplicates,
+        "truncated": len(messages) > max_results,
+    }
+
+
+def _regex_messages(src: str, patterns) -> list[tuple[int, str, str]]:
+    out = []
+    seen = set()
+    for kind, pattern in patterns:
+        for m in pattern.finditer(src):
+            # The message is the last literal group that matched.
+            text = next((g for g in reversed(m.groups()) if g is not None), None)
+            if text is None:
+                continue
+            line = src.count("\n", 0, m.start()) + 1
+            if (line, text) in seen:
+                continue
+            seen.add((line, text))
+            out.append((line, kind, text))
+    return out
+
+
+def _python_messages(src: str) -> list[tuple[int, str, str]]:
+    try:
+        tree = ast.parse(src)
+    except SyntaxError:
+        return []
+    out = []
+    for node in ast.walk(tree):
+        if isinstance(node, ast.Raise) and isinstance(node.exc, ast.Call):
+            text = _first_str(node.exc.args)
+            if text is not None:
+                out.append((node.lineno, "error", text))
+        if not isinstance(node, ast.Call):
+            continue
+        func = node.func
+        if isinstance(func, ast.Attribute):
+            owner = func.value.id if isinstance(func.value, ast.Name) else getattr(func.value, "attr", "")
+            if func.attr in _PY_LOG_METHODS and owner in _PY_LOG_OWNERS:
+                text = _first_str(node.args)
+                if text is not None:
+                    out.append((node.lineno, "log", text))
+                continue
+            name = func.attr
+        elif isinstance(func, ast.Name):
+            name = func.id
+        else:
+            continue
+        if name in _PY_I18N:
+            text = _first_str(node.args)
+            if text is not None:
+                out.append((node.lineno, "i18n", text))
+            continue
+        # argparse add_argument/ArgumentParser/add_parser and click options.
+        for kw in node.keywords:
+            if kw.arg in ("help", "description", "epilog", "usage") and name in (
+                "add_argument", "ArgumentParser", "add_parser", "add_argument_group",
+                "option", "argument", "command", "group",
+            ):
+                text = _str_value(kw.value)
+                if text is not None:
+                    out.append((kw.value.lineno, "cli_help", text))
+    return out
+
+
+def _first_str(args) -> str | None:
+    return _str_value(args[0]) if args else None
+
+
+def _str_value(node) -> str | None:
+    """Render a string constant or f-string; None for anything else."""
+    if isinstance(node, ast.Constant) and isinstance(node.value, str):
+        return node.value
+    if isinstance(node, ast.JoinedStr):
+        parts = []
+        for v in node.values:
+            if isinstance(v, ast.Constant):
+                parts.append(str(v.value))
+            elif isinstance(v, ast.FormattedValue):
+                parts.append("{" + ast.unparse(v.value) + "}")
+        return "".join(parts)
+    return None
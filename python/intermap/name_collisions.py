@@ -0,0 +1,171 @@
+"""Identically named exported symbols across workspace projects.
+
+Three projects each exporting a ``Client`` type are harmless until one
+file imports two of them. find_name_collisions() lists the exported names
+(see api_surface) defined by more than one project and ranks each by how
+close the defining projects sit in the import graph (see consumers):
+
+- ``imports``: one of the projects imports another, so both names are in
+  reach from the same code;
+- ``shared_importer``: a third project imports both;
+- ``unrelated``: no import connects them.
+
+Only the first two are reported unless scope is "all".
+"""
+
+from __future__ import annotations
+
+import os
+
+from .api_surface import api_surface
+from .code_search import iter_sources, owner
+from .consumers import _EXTS, _import_names, _imports, _under
+from .cross_project import _discover_projects
+
+# Kind classes, from api_surface symbol kinds. Methods, modules, and
+# export lists are never compared.
+KIND_CLASSES = {
+    "type": {"class", "struct", "interface", "type", "enum", "trait", "union", "namespace"},
+    "function": {"function"},
+    "value": {"const", "var", "variable"},
+}
+
+_RELATIONS = ("imports", "shared_importer", "unrelated")
+
+# Languages api_surface reads.
+_LANGUAGES = {"go", "python", "typescript", "javascript", "rust"}
+
+
+def find_name_collisions(
+    root: str,
+    kinds: list[str] | None = None,
+    scope: str = "neighborhood",
+    languages: dict[str, str] | None = None,
+    ignore: list[str] | None = None,
+    max_depth: int = 4,
+) -> dict:
+    """Find exported names defined by more than one project under root.
+
+    Args:
+        root: Workspace root
+        kinds: Kind classes compared: type, function, value (default type)
+        scope: "neighborhood" for collisions between projects linked by
+            imports, "all" to include unrelated projects
+        languages: Project language by path (default: detected from
+            manifests)
+        ignore: Names never reported
+        max_depth: Directory levels below root searched for projects
+
+    Returns:
+        Dict with collisions ({name, kind, relation, projects: [{project,
+        language, definitions: [{package, kind, file, line, signature}]}],
+        pairs: [{a, b, relation, importers}]}), projects (scanned
+        projects), and total.
+    """
+    kinds = kinds or ["type"]
+    unknown = [k for k in kinds if k not in KIND_CLASSES]
+    if unknown:
+        raise ValueError(f"unknown kind class {unknown[0]!r}")
+    if scope not in ("neighborhood", "all"):
+        raise ValueError(f"unknown scope {scope!r}")
+    wanted = {kind: cls for cls in kinds for kind in KIND_CLASSES[cls]}
+    skip = set(ignore or ())
+
+    root = os.path.abspath(root)
+    projects = _discover_projects(root, max_depth)
+    languages = languages or {}
+
+    # name -> kind class -> project -> definitions
+    by_name: dict[str, dict[str, dict[str, list[dict]]]] = {}
+    scanned = []
+    for p in projects:
+        lang = languages.get(p["path"]) or _project_language(p["path"])
+        if lang not in _LANGUAGES:
+            continue
+        p["language"] = lang
+        scanned.append(p["name"])
+        for pkg in api_surface(p["path"], language=lang)["packages"]:
+            for sym in pkg["symbols"]:
+                cls = wanted.get(sym["kind"])
+                if cls is None or "." in sym["name"] or sym["name"] in skip:
+                    continue
+                by_name.setdefault(sym["name"], {}).setdefault(cls, {}).setdefault(p["name"], []).append({
+                    "package": pkg["package"],
+                    "kind": sym["kind"],
+                    "file": sym["file"],
+                    "line": sym["line"],
+                    "signature": sym["signature"],
+                })
+
+    imports = _import_graph(root, projects)
+    languages_by_name = {p["name"]: p.get("language", "") for p in projects}
+
+    collisions = []
+    for name, classes in by_name.items():
+        for cls, defs in classes.items():
+            if len(defs) < 2:
+                continue
+            owners = sorted(defs)
+            pairs = [_relate(a, b, imports) for i, a in enumerate(owners) for b in owners[i + 1:]]
+            relation = min((pr["relation"] for pr in pairs), key=_RELATIONS.index)
+            if scope == "neighborhood" and relation == "unrelated":
+                continue
+            collisions.append({
+                "name": name,
+                "kind": cls,
+                "relation": relation,
+                "projects": [
+                    {"project": o, "language": languages_by_name.get(o, ""), "definitions": defs[o]}
+                    for o in owners
+                ],
+                "pairs": [pr for pr in pairs if pr["relation"] != "unrelated"] if scope == "neighborhood" else pairs,
+            })
+
+    collisions.sort(key=lambda c: (_RELATIONS.index(c["relation"]), -len(c["projects"]), c["name"], c["kind"]))
+    return {
+        "root": root,
+        "collisions": collisions,
+        "projects": sorted(scanned),
+        "total": len(collisions),
+    }
+
+
+def _import_graph(root: str, projects: list[dict]) -> dict[str, set[str]]:
+    """Projects each project imports, from its source files."""
+    names = {p["name"]: _import_names(p["path"]) for p in projects}
+    everything = [n for ns in names.values() for n in ns]
+    graph: dict[str, set[str]] = {p["name"]: set() for p in projects}
+    if not everything:
+        return graph
+    for path, _, text in iter_sources(root, _EXTS):
+        home = owner(projects, str(path))["name"]
+        if not home:
+            continue
+        for _, _, pkg, _ in _imports(path.suffix, text, everything):
+            sep = "::" if path.suffix == ".rs" else "." if path.suffix == ".py" else "/"
+            for target, ns in names.items():
+                if target != home and _under(pkg, ns, sep):
+                    graph[home].add(target)
+    return graph
+
+
+def _relate(a: str, b: str, imports: dict[str, set[str]]) -> dict:
+    if b in imports.get(a, ()) or a in imports.get(b, ()):
+        relation, importers = "imports", []
+    else:
+        importers = sorted(p for p, deps in imports.items() if a in deps and b in deps)
+        relation = "shared_importer" if importers else "unrelated"
+    return {"a": a, "b": b, "relation": relation, "importers": importers}
+
+
+def _project_language(path: str) -> str:
+    for marker, lang in (
+        ("go.mod", "go"),
+        ("Cargo.toml", "rust"),
+        ("pyproject.toml", "python"),
+        ("setup.py", "python"),
+        ("package.json", "typescript"),
+    ):
+        if os.path.isfile(os.path.join(path, marker)):
+            return lang
+    return ""
@@ -0,0 +1,235 @@
+"""What releasing a project means for the rest of the workspace.
+
+release_impact() diffs the project's public API (see api_surface) between
+its last tag and the working tree, then walks its consumers (see consumers)
+and reports which of them reference a symbol the release removes or
+changes. A changed symbol is breaking unless it is a struct that only
+gained members; added symbols never are.
+"""
+
+import io
+import os
+import re
+import subprocess
+import tarfile
+import tempfile
+from pathlib import Path
+
+from .api_surface import api_surface
+from .consumers import _find_project, _import_names, find_consumers
+from .cross_project import _discover_projects
+
+
+def release_impact(
+    root: str,
+    project: str,
+    language: str = "python",
+    since: str | None = None,
+    max_depth: int = 4,
+) -> dict:
+    """Assess a pending release of project against its workspace consumers.
+
+    Args:
+        root: Workspace root
+        project: Project name or path
+        language: The project's language, for its API surface
+        since: Ref of the previous release (default: the latest tag
+            reachable from HEAD)
+        max_depth: Directory levels below root searched for projects
+
+    Returns:
+        Dict with project, path, language, since (None without a tag),
+        published_as ([{ecosystem, name}] for manifest pins), api
+        ({removed, changed, extended, added, breaking} or None without a
+        tag), consumers ([{project, path, files, packages, breaking_uses:
+        [{symbol, file, line}]}]), and declared_only.
+    """
+    projects = _discover_projects(root, max_depth)
+    target = _find_project(projects, project)
+    if target is None:
+        raise LookupError(f"project {project!r} not found under {root}")
+    path = target["path"]
+    since = since or _latest_tag(path)
+
+    api = None
+    if since:
+        head = api_surface(path, language=language)
+        with tempfile.TemporaryDirectory(prefix="intermap-release-") as tmp:
+            base_dir = _checkout(path, since, tmp)
+            base = api_surface(base_dir, language=language)
+        api = _diff_surfaces(base, head)
+
+    used = find_consumers(root, target["name"], max_depth=max_depth)
+    names = _import_names(path)
+    broken_by_package: dict[str, list[dict]] = {}
+    if api:
+        for entry in api["removed"] + api["changed"]:
+            broken_by_package.setdefault(entry["package"], []).append(entry)
+
+    consumers = {}
+    paths = {p["name"]: p["path"] for p in projects}
+    for pkg in used["packages"]:
+        broken = [
+            s for api_pkg, syms in broken_by_package.items()
+            if _same_package(api_pkg, pkg["package"], names, language) for s in syms
+        ]
+        for use in pkg["consumers"]:
+            c = consumers.setdefault(use["project"], {
+                "project": use["project"],
+                "path": paths.get(use["project"], ""),
+                "files": set(),
+                "packages": set(),
+                "breaking_uses": [],
+            })
+            c["files"].add(use["file"])
+            c["packages"].add(pkg["package"])
+            if broken:
+                c["breaking_uses"].extend(_references(os.path.join(c["path"], use["file"]), use["file"], broken))
+
+    out = []
+    for c in consumers.values():
+        seen = set()
+        uses = []
+        for u in sorted(c["breaking_uses"], key=lambda u: (u["file"], u["line"], u["symbol"])):
+            key = (u["symbol"], u["file"], u["line"])
+            if key not in seen:
+                seen.add(key)
+                uses.append(u)
+        out.append({
+            "project": c["project"],
+            "path": c["path"],
+            "files": len(c["files"]),
+            "packages": sorted(c["packages"]),
+            "breaking_uses": uses,
+        })
+    out.sort(key=lambda c: (-len(c["breaking_uses"]), -c["files"], c["project"]))
+
+    return {
+        "project": target["name"],
+        "path": path,
+        "language": language,
+        "since": since,
+        "published_as": _published_as(path),
+        "api": api,
+        "consumers": out,
+        "declared_only": used["declared_only"],
+    }
+
+
+def _latest_tag(path: str) -> str | None:
+    result = subprocess.run(
+        ["git", "describe", "--tags", "--abbrev=0", "HEAD"],
+        capture_output=True, text=True, cwd=path, timeout=10,
+    )
+    if result.returncode != 0:
+        return None
+    return result.stdout.strip() or None
+
+
+def _checkout(path: str, ref: str, dest: str) -> str:
+    """Extract the project's tree at ref into dest and return its root there.
+
+    Run from a subdirectory of the repository, git archive writes paths
+    relative to it, so dest is the project's root even inside a monorepo.
+    """
+    result = subprocess.run(["git", "archive", "--format=tar", ref, "--", "."], capture_output=True, cwd=path, timeout=60)
+    if result.returncode != 0:
+        message = result.stderr.decode("utf-8", errors="replace").strip()
+        raise ValueError(f"git archive {ref} failed: {message}")
+    with tarfile.open(fileobj=io.BytesIO(result.stdout)) as tar:
+        if hasattr(tarfile, "data_filter"):
+            tar.extractall(dest, filter="data")
+        else:
+            tar.extractall(dest)
+    return dest
+
+
+def _diff_surfaces(base: dict, head: dict) -> dict:
+    def index(surface: dict) -> dict[tuple[str, str], dict]:
+        return {(p["package"], s["name"]): s for p in surface["packages"] for s in p["symbols"]}
+
+    old, new = index(base), index(head)
+    removed, changed, extended, added = [], [], [], []
+    for key in sorted(old.keys() | new.keys()):
+        package, name = key
+        before, after = old.get(key), new.get(key)
+        if after is None:
+            removed.append({"package": package, "symbol": name, "kind": before["kind"], "signature": before["signature"]})
+        elif before is None:
+            added.append({"package": package, "symbol": name, "kind": after["kind"], "signature": after["signature"]})
+        elif before["id"] != after["id"]:
+            entry = {"package": package, "symbol": name, "kind": after["kind"], "before": before["signature"], "after": after["signature"]}
+            old_members, new_members = set(before.get("members", ())), set(after.get("members", ()))
+            if old_members - new_members:
+                entry["removed_members"] = sorted(old_members - new_members)
+            if new_members - old_members:
+                entry["added_members"] = sorted(new_members - old_members)
+            grew_only = (
+                after["kind"] == "struct"
+                and before["signature"] == after["signature"]
+                and old_members <= new_members
+            )
+            (extended if grew_only else changed).append(entry)
+    return {
+        "removed": removed,
+        "changed": changed,
+        "extended": extended,
+        "added": added,
+        "breaking": bool(removed or changed),
+    }
+
+
+def _same_package(api_pkg: str, imported: str, names: list[tuple[str, str]], language: str) -> bool:
+    """Whether an imported package (a consumer's import spec) is the
+    api_surface package api_pkg (a project-relative directory or module)."""
+    if language == "python":
+        return api_pkg == imported or api_pkg.endswith("." + imported)
+    for name, sep in names:
+        if imported == name:
+            return api_pkg == "" or language != "go"
+        if imported.startswith(name + sep):
+            rel = imported[len(name) + len(sep):].replace(sep, "/")
+            return api_pkg == rel or api_pkg.endswith("/" + rel)
+    return False
+
+
+def _references(path: str, rel: str, broken: list[dict]) -> list[dict]:
+    """Lines of a consumer file naming a broken symbol. Methods match on
+    ".Method", everything else on the bare name."""
+    try:
+        text = Path(path).read_text(encoding="utf-8", errors="replace")
+    except OSError:
+        return []
+    out = []
+    for sym in broken:
+        name = sym["symbol"]
+        pattern = r"\." + re.escape(name.rsplit(".", 1)[1]) + r"\b" if "." in name else r"\b" + re.escape(name) + r"\b"
+        for m in re.finditer(pattern, text):
+            out.append({"symbol": name, "package": sym["package"], "file": rel, "line": text.count("\n", 0, m.start()) + 1})
+    return out
+
+
+def _published_as(path: str) -> list[dict]:
+    """The names other projects pin this project by, per ecosystem."""
+    root = Path(path)
+    out = []
+
+    def manifest(name: str) -> str:
+        try:
+            return (root / name).read_text(errors="replace")
+        except OSError:
+            return ""
+
+    m = re.search(r"^module\s+(\S+)", manifest("go.mod"), re.M)
+    if m:
+        out.append({"ecosystem": "golang", "name": m.group(1)})
+    m = re.search(r'"name"\s*:\s*"([^"]+)"', manifest("package.json"))
+    if m:
+        out.append({"ecosystem": "npm", "name": m.group(1)})
+    m = re.search(r'^\[package\][^\[]*?^name\s*=\s*"([^"]+)"', manifest("Cargo.toml"), re.M | re.S)
+    if m:
+        out.append({"ecosystem": "cargo", "name": m.group(1)})
+    m = re.search(r'^\[(?:project|tool\.poetry)\][^\[]*?^name\s*=\s*"([^"]+)"', manifest("pyproject.toml"), re.M | re.S)
+    if m:
+        out.append({"ecosystem": "pypi", "name": re.sub(r"[-_.]+", "-", m.group(1)).lower()})
+    return out
@@ -0,0 +1,392 @@
+"""Mechanically safe renames: identifiers and import paths.
+
+``rename_check`` computes every edit a rename needs and decides whether it
+is safe to apply without a human in the loop:
+
+- identifier: every whole-word occurrence of the old name outside comments
+  and string literals, in the project's files of the language (or the given
+  files). The match is textual, so a rename is only safe when renaming
+  every occurrence cannot reach a different symbol. It is unsafe when the
+  new name is not a plain identifier or is a keyword, already appears as an
+  identifier in the project (the rename could capture or shadow it), or,
+  for Go, flips the name between exported and unexported. It is also
+  unsafe when the old name is used as a member (``x.old``, whose type or
+  package text cannot tell), is visible outside the project (Go exported,
+  Python public module-level), is predeclared or a builtin, is imported
+  from outside the project or passed as a Python keyword argument, or
+  appears in files the ``files`` filter leaves out. Renames in other
+  languages are never safe: review the diff and apply it by hand.
+- import: import specs equal to the old path or below it (Go import paths,
+  Python dotted modules, JS/TS specifiers). Rust ``use`` paths are not
+  supported.
+
+The result always carries a unified diff. ``apply_rename`` writes the edits
+only when the check is safe; the Go side gates it behind
+``INTERMAP_ALLOW_WRITES=1``.
+"""
+
+from __future__ import annotations
+
+import ast
+import builtins
+import difflib
+import keyword
+import os
+import re
+import tempfile
+from pathlib import Path
+
+from .code_search import _LANG_EXTS, iter_sources
+from .consumers import _GO_IMPORT_BLOCK, _GO_IMPORT_LINE, _GO_SPEC, _JS_IMPORT
+
+KINDS = ("identifier", "import")
+
+_IDENT = re.compile(r"^[A-Za-z_$][\w$]*$")
+_GO_KEYWORDS = {
+    "break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough",
+    "for", "func", "go", "goto", "if", "import", "interface", "map", "package", "range",
+    "return", "select", "struct", "switch", "type", "var",
+}
+_JS_KEYWORDS = {
+    "break", "case", "catch", "class", "const", "continue", "debugger", "default", "delete",
+    "do", "else", "export", "extends", "finally", "for", "function", "if", "import", "in",
+    "instanceof", "let", "new", "return", "super", "switch", "this", "throw", "try",
+    "typeof", "var", "void", "while", "with", "yield", "await", "enum",
+}
+_RUST_KEYWORDS = {
+    "as", "break", "const", "continue", "crate", "else", "enum", "extern", "false", "fn",
+    "for", "if", "impl", "in", "let", "loop", "match", "mod", "move", "mut", "pub", "ref",
+    "return", "self", "Self", "static", "struct", "super", "trait", "true", "type",
+    "unsafe", "use", "where", "while", "async", "await", "dyn",
+}
+_GO_PREDECLARED = {
+    "any", "bool", "byte", "comparable", "complex64", "complex128", "error", "float32",
+    "float64", "int", "int8", "int16", "int32", "int64", "rune", "string", "uint", "uint8",
+    "uint16", "uint32", "uint64", "uintptr", "true", "false", "iota", "nil", "append", "cap",
+    "clear", "close", "complex", "copy", "delete", "imag", "len", "make", "max", "min", "new",
+    "panic", "print", "println", "real", "recover",
+}
+# Languages whose identifier renames the scope checks cover.
+_CHECKED = ("go", "python")
+_MAX_CONFLICTS = 20
+
+
+def rename_check(
+    project: str,
+    kind: str,
+    old: str,
+    new: str,
+    language: str = "python",
+    files: list[str] | None = None,
+) -> dict:
+    """Compute the edits for a rename and whether it is safe to apply.
+
+    Args:
+        project: Project root
+        kind: "identifier" or "import"
+        old: Identifier or import path to rename
+        new: Replacement
+        language: Language whose files are edited
+        files: Only these project-relative files (identifier renames)
+
+    Returns:
+        Dict with kind, old, new, language, safe, conflicts ({file, line,
+        reason}), edits ({file, line, column}), files, edit_count, and diff.
+    """
+    if kind not in KINDS:
+        raise ValueError(f"unknown kind {kind!r} (want identifier or import)")
+    if language not in _LANG_EXTS:
+        raise ValueError(f"unknown language {language!r}")
+    if not old or not new or old == new:
+        raise ValueError("old and new must be set and differ")
+    if kind == "import" and language not in ("go", "python", "typescript", "javascript"):
+        raise ValueError(f"import renames are not supported for {language}")
+
+    root = Path(project).resolve()
+    wanted = {f.replace(os.sep, "/") for f in files} if files else None
+    conflicts: list[dict] = []
+    if kind == "identifier":
+        conflicts.extend(_name_problems(old, new, language))
+
+    sources = {}
+    skipped = {}
+    for path, rel, text in iter_sources(str(root), _LANG_EXTS[language]):
+        if wanted is None or rel in wanted:
+            sources[rel] = (path, text)
+        else:
+            skipped[rel] = text
+
+    def conflict(rel: str, text: str, pos: int, reason: str) -> None:
+        if len(conflicts) < _MAX_CONFLICTS:
+            conflicts.append({"file": rel, "line": text.count("\n", 0, pos) + 1, "reason": reason})
+
+    edits: dict[str, list[tuple[int, int, str]]] = {}
+    for rel, (_, text) in sorted(sources.items()):
+        if kind == "identifier":
+            masked = _mask(text, language)
+            spans = [(m.start(), m.end(), new) for m in _word(old).finditer(masked)]
+            for m in _word(new).finditer(masked):
+                conflict(rel, text, m.start(), f"{new} is already used here")
+            for start, _, _ in spans:
+                if masked[:start].rstrip().endswith("."):
+                    conflict(rel, text, start, f"{old} is used as a member here; its type or package is not resolved")
+            if language == "python":
+                for line, reason in _python_scope_problems(text, old, root):
+                    conflict(rel, text, _line_start(text, line), reason)
+        else:
+            spans = _import_spans(text, language, old, new)
+        if spans:
+            edits[rel] = spans
+
+    if kind == "identifier":
+        for rel, text in sorted(skipped.items()):
+            if m := _word(old).search(_mask(text, language)):
+                conflict(rel, text, m.start(), f"{old} is also used here, outside the files being renamed")
+
+    diff = []
+    edit_list = []
+    for rel, spans in edits.items():
+        text = sources[rel][1]
+        for start, _, _ in spans:
+            line = text.count("\n", 0, start) + 1
+            edit_list.append({"file": rel, "line": line, "column": start - text.rfind("\n", 0, start)})
+        diff.extend(difflib.unified_diff(
+            text.splitlines(keepends=True),
+            _apply_spans(text, spans).splitlines(keepends=True),
+            fromfile=f"a/{rel}",
+            tofile=f"b/{rel}",
+        ))
+
+    return {
+        "kind": kind,
+        "old": old,
+        "new": new,
+        "language": language,
+        "safe": not conflicts and bool(edits),
+        "conflicts": conflicts,
+        "edits": edit_list,
+        "files": sorted(edits),
+        "edit_count": len(edit_list),
+        "diff": "".join(diff),
+    }
+
+
+def apply_rename(
+    project: str,
+    kind: str,
+    old: str,
+    new: str,
+    language: str = "python",
+    files: list[str] | None = None,
+    apply: bool = False,
+) -> dict:
+    """Run rename_check and, when apply is set and the check is safe, write
+    the edits. The result is rename_check's with applied added."""
+    result = rename_check(project, kind, old, new, language, files)
+    result["applied"] = False
+    if not apply or not result["safe"]:
+        return result
+
+    root = Path(project).resolve()
+    for rel in result["files"]:
+        path = root / rel
+        text = path.read_bytes().decode("utf-8")
+        if kind == "identifier":
+            spans = [(m.start(), m.end(), new) for m in _word(old).finditer(_mask(text, language))]
+        else:
+            spans = _import_spans(text, language, old, new)
+        _write_atomic(path, _apply_spans(text, spans))
+    result["applied"] = True
+    return result
+
+
+def _name_problems(old: str, new: str, language: str) -> list[dict]:
+    problems = []
+    if not _IDENT.match(old) or not _IDENT.match(new) or ("$" in new and language not in ("typescript", "javascript")):
+        problems.append({"file": "", "line": 0, "reason": "old and new must be plain identifiers"})
+    reserved = {
+        "python": set(keyword.kwlist),
+        "go": _GO_KEYWORDS,
+        "typescript": _JS_KEYWORDS,
+        "javascript": _JS_KEYWORDS,
+        "rust": _RUST_KEYWORDS,
+    }.get(language, set())
+    if new in reserved:
+        problems.append({"file": "", "line": 0, "reason": f"{new} is a {language} keyword"})
+    if language == "go" and old[:1].isupper() != new[:1].isupper():
+        problems.append({"file": "", "line": 0, "reason": "rename changes whether the Go name is exported"})
+    if language not in _CHECKED:
+        problems.append({"file": "", "line": 0, "reason": f"{language} renames match text, not symbols; review the diff"})
+    elif language == "go" and old[:1].isupper():
+        problems.append({"file": "", "line": 0, "reason": f"{old} is exported; uses in other modules are not checked"})
+    elif language == "go" and old in _GO_PREDECLARED:
+        problems.append({"file": "", "line": 0, "reason": f"{old} is predeclared in Go"})
+    elif language == "python" and hasattr(builtins, old):
+        problems.append({"file": "", "line": 0, "reason": f"{old} is a Python builtin"})
+    return problems
+
+
+def _python_scope_problems(text: str, old: str, root: Path) -> list[tuple[int, str]]:
+    """(line, reason) for Python uses of old a text rename cannot follow:
+    a public module-level definition, a binding imported from outside the
+    project, and a keyword argument to an unresolved callee."""
+    try:
+        tree = ast.parse(text)
+    except SyntaxError:
+        return [(1, "file does not parse; uses of the name cannot be checked")]
+    problems = []
+    if not old.startswith("_"):
+        for node in tree.body:
+            if _binds(node, old):
+                problems.append((node.lineno, f"{old} is a public module-level name; uses outside the project are not checked"))
+    for node in ast.walk(tree):
+        if isinstance(node, ast.Import):
+            for alias in node.names:
+                if (alias.asname or alias.name.split(".")[0]) == old:
+                    problems.append((node.lineno, f"{old} names an imported module"))
+        elif isinstance(node, ast.ImportFrom) and not node.level and node.module:
+            top = node.module.split(".")[0]
+            local = (root / top).is_dir() or (root / f"{top}.py").is_file()
+            if not local and any(a.name == old for a in node.names):
+                problems.append((node.lineno, f"{old} is imported from {node.module}, outside the project"))
+        elif isinstance(node, ast.keyword) and node.arg == old:
+            problems.append((getattr(node, "lineno", 1), f"{old} is passed as a keyword argument; the callee is not resolved"))
+    return problems
+
+
+def _binds(node: ast.stmt, name: str) -> bool:
+    if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef)):
+        return node.name == name
+    if isinstance(node, ast.Assign):
+        return any(isinstance(n, ast.Name) and n.id == name for t in node.targets for n in ast.walk(t))
+    if isinstance(node, (ast.AnnAssign, ast.AugAssign)):
+        return isinstance(node.target, ast.Name) and node.target.id == name
+    return False
+
+
+def _line_start(text: str, line: int) -> int:
+    pos = 0
+    for _ in range(line - 1):
+        pos = text.find("\n", pos) + 1
+    return pos
+
+
+def _word(name: str) -> re.Pattern:
+    return re.compile(r"(?<![\w$])" + re.escape(name) + r"(?![\w$])")
+
+
+def _import_spans(text: str, language: str, old: str, new: str) -> list[tuple[int, int, str]]:
+    """(start, end, replacement) for the old path in import specs."""
+    spans = []
+    if language == "go":
+        starts = [block.start(1) + m.start(1) for block in _GO_IMPORT_BLOCK.finditer(text) for m in _GO_SPEC.finditer(block.group(1))]
+        starts += [m.start(1) for m in _GO_IMPORT_LINE.finditer(text)]
+        for start in sorted(set(starts)):
+            end = text.index('"', start)
+            spec = text[start:end]
+            if spec == old or spec.startswith(old + "/"):
+                spans.append((start, start + len(old), new))
+    elif language == "python":
+        try:
+            tree = ast.parse(text)
+        except SyntaxError:
+            return []
+        line_starts = [0] + [i + 1 for i, ch in enumerate(text) if ch == "\n"]
+        pattern = re.compile(r"(?<![\w.])" + re.escape(old) + r"(?![\w])")
+        for node in ast.walk(tree):
+            if isinstance(node, ast.Import):
+                mods = [a.name for a in node.names]
+            elif isinstance(node, ast.ImportFrom) and node.module and not node.level:
+                mods = [node.module]
+            else:
+                continue
+            if not any(m == old or m.startswith(old + ".") for m in mods):
+                continue
+            start = line_starts[node.lineno - 1] + node.col_offset
+            end = line_starts[node.end_lineno - 1] + node.end_col_offset
+            segment = text[start:end]
+            if isinstance(node, ast.ImportFrom):
+                # Only the module between "from" and "import" is a path.
+                segment = segment[:segment.index(" import")] if " import" in segment else segment
+            masked = _mask(segment, "python")
+            spans.extend((start + m.start(), start + m.end(), new) for m in pattern.finditer(masked))
+    else:
+        for m in _JS_IMPORT.finditer(text):
+            spec = m.group(1)
+            if spec == old or spec.startswith(old + "/"):
+                spans.append((m.start(1), m.start(1) + len(old), new))
+    return sorted(set(spans))
+
+
+def _apply_spans(text: str, spans: list[tuple[int, int, str]]) -> str:
+    out = []
+    pos = 0
+    for start, end, replacement in sorted(spans):
+        out.append(text[pos:start])
+        out.append(replacement)
+        pos = end
+    out.append(text[pos:])
+    return "".join(out)
+
+
+def _write_atomic(path: Path, text: str) -> None:
+    fd, tmp = tempfile.mkstemp(dir=path.parent, prefix=f".{path.name}.")
+    try:
+        with os.fdopen(fd, "w", encoding="utf-8", newline="") as f:
+            f.write(text)
+        os.chmod(tmp, path.stat().st_mode & 0o7777)
+        os.replace(tmp, path)
+    except BaseException:
+        os.unlink(tmp)
+        raise
+
+
+def _mask(text: str, language: str) -> str:
+    """Blank out comments and string literal contents, keeping offsets and
+    newlines, so word matches only hit code."""
+    out = list(text)
+    n = len(text)
+    i = 0
+    hash_comments = language in ("python", "ruby", "shell")
+    slash_comments = not hash_comments
+    quotes = {"python": "'\"", "go": "\"'`", "rust": "\"", "typescript": "'\"`", "javascript": "'\"`"}.get(language, "'\"")
+
+    def blank(a: int, b: int) -> None:
+        for k in range(a, min(b, n)):
+            if out[k] != "\n":
+                out[k] = " "
+
+    while i < n:
+        ch = text[i]
+        if hash_comments and ch == "#" or slash_comments and text.startswith("//", i):
+            end = text.find("\n", i)
+            end = n if end < 0 else end
+            blank(i, end)
+            i = end
+        elif slash_comments and text.startswith("/*", i):
+            end = text.find("*/", i + 2)
+            end = n if end < 0 else end + 2
+            blank(i, end)
+            i = end
+        elif language == "rust" and ch == "'":
+            m = re.compile(r"'(?:\\.|[^\\'\n])'").match(text, i)
+            if m:
+                blank(i + 1, m.end() - 1)
+                i = m.end()
+            else:
+                i += 1  # a lifetime
+        elif ch in quotes:
+            delim = text[i:i + 3] if language == "python" and text[i:i + 3] in ('"""', "'''") else ch
+            j = i + len(delim)
+            while j < n and not text.startswith(delim, j):
+                if text[j] == "\\" and delim != "`":
+                    j += 1
+                elif text[j] == "\n" and len(delim) == 1 and delim != "`":
+                    break
+                j += 1
+            end = min(j + len(delim), n)
+            blank(i + len(delim), j)
+            i = end
+        else:
+            i += 1
+    return "".join(out)
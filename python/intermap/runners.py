@@ -0,0 +1,121 @@
+"""Format selected test files as ready-to-run commands for common runners.
+
+``change_impact`` reports affected test *files*. This module turns them into
+runner-specific selectors:
+
+- ``pytest``: node IDs (``tests/test_x.py::TestCls::test_y``) and a
+  ``pytest <files>`` command.
+- ``gotest``: one ``go test ./pkg -run '^(TestA|TestB)$'`` per package, with
+  import paths resolved from go.mod.
+- ``jest``: ``npx jest --runTestsByPath <files>``.
+"""
+
+import ast
+import logging
+import re
+import shlex
+from pathlib import Path
+
+logger = logging.getLogger(__name__)
+
+RUNNERS = ("pytest", "gotest", "jest")
+
+_GO_TEST_FUNC = re.compile(r"^func\s+((?:Test|Example|Fuzz)\w*)\s*\(", re.MULTILINE)
+_GO_MODULE = re.compile(r"^module\s+(\S+)", re.MULTILINE)
+
+
+def format_tests(project_path: str, tests: list[str], runner: str) -> dict:
+    """Build runner selectors and commands for project-relative test files.
+
+    Returns {"runner", "selectors", "commands", "command_lines"}, where
+    commands are argv lists and command_lines their shell-quoted form.
+    """
+    if runner not in RUNNERS:
+        raise ValueError(f"unknown output {runner!r} (want one of {', '.join(RUNNERS)})")
+    project = Path(project_path).resolve()
+    if runner == "pytest":
+        selectors, commands = _pytest(project, tests)
+    elif runner == "gotest":
+        selectors, commands = _gotest(project, tests)
+    else:
+        selectors, commands = _jest(tests)
+    return {
+        "runner": runner,
+        "selectors": selectors,
+        "commands": commands,
+        "command_lines": [shlex.join(c) for c in commands],
+    }
+
+
+def _pytest(project: Path, tests: list[str]) -> tuple[list[str], list[list[str]]]:
+    files = [t for t in tests if t.endswith(".py")]
+    selectors = []
+    for rel in files:
+        ids = pytest_node_ids(project / rel, rel)
+        selectors.extend(ids or [rel])
+    commands = [["pytest", *files]] if files else []
+    return selectors, commands
+
+
+def pytest_node_ids(path: Path, rel: str) -> list[str]:
+    """Collect pytest node IDs for test functions and Test* class methods."""
+    try:
+        tree = ast.parse(path.read_text(encoding="utf-8", errors="replace"))
+    except (OSError, SyntaxError) as e:
+        logger.debug("pytest_node_ids: %s: %s", rel, e)
+        return []
+    ids = []
+    for node in tree.body:
+        if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)) and node.name.startswith("test"):
+            ids.append(f"{rel}::{node.name}")
+        elif isinstance(node, ast.ClassDef) and node.name.startswith("Test"):
+            for item in node.body:
+                if isinstance(item, (ast.FunctionDef, ast.AsyncFunctionDef)) and item.name.startswith("test"):
+                    ids.append(f"{rel}::{node.name}::{item.name}")
+    return ids
+
+
+def _gotest(project: Path, tests: list[str]) -> tuple[list[str], list[list[str]]]:
+    module = _go_module(project)
+    by_pkg: dict[str, list[str]] = {}
+    for rel in tests:
+        if not rel.endswith("_test.go"):
+            continue
+        pkg = Path(rel).parent.as_posix()
+        names = by_pkg.setdefault(pkg, [])
+        try:
+            src = (project / rel).read_text(encoding="utf-8", errors="replace")
+        except OSError as e:
+            logger.debug("gotest: %s: %s", rel, e)
+            continue
+        for name in _GO_TEST_FUNC.findall(src):
+            if name not in names:
+                names.append(name)
+
+    selectors, commands = [], []
+    for pkg in sorted(by_pkg):
+        local = "." if pkg == "." else f"./{pkg}"
+        import_path = module if pkg == "." else f"{module}/{pkg}" if module else local
+        names = sorted(by_pkg[pkg])
+        cmd = ["go", "test", local]
+        if names:
+            cmd += ["-run", "^(" + "|".join(names) + ")$"]
+            selectors.extend(f"{import_path}.{n}" for n in names)
+        else:
+            selectors.append(import_path)
+        commands.append(cmd)
+    return selectors, commands
+
+
+def _go_module(project: Path) -> str:
+    try:
+        m = _GO_MODULE.search((project / "go.mod").read_text(encoding="utf-8"))
+    except OSError:
+        return ""
+    return m.group(1) if m else ""
+
+
+def _jest(tests: list[str]) -> tuple[list[str], list[list[str]]]:
+    files = [t for t in tests if re.search(r"\.[cm]?[jt]sx?$", t)]
+    commands = [["npx", "jest", "--runTestsByPath", *files]] if files else []
+    return files, commands
@@ -0,0 +1,381 @@
+"""Invocation edges between shell scripts, Makefiles, and project binaries.
+
+Operational glue (deploy scripts, Makefile targets, bin/ wrappers) calls
+other scripts and the binaries projects build, which import analysis never
+sees. scan_scripts() reads every shell script and Makefile under a root and
+reports, per command:
+
+- "script" edges: a script run by path (./x.sh, bash x.sh, source x.sh,
+  "$(dirname "$0")/x.sh"), resolved against the calling file's directory
+  and then the root;
+- "make" edges: make -C dir / $(MAKE) -C dir, to dir's Makefile;
+- "binary" edges: a command word naming a binary some project builds
+  (Go cmd/<name> directories and root main packages, [project.scripts] in
+  pyproject.toml, package.json "bin", Cargo [[bin]] and package names),
+  or go run of a main package directory.
+
+Parsing is line-based and deliberately shallow: heredoc bodies, functions
+defined in the script, and commands assembled from variables are not
+followed.
+"""
+
+import json
+import os
+import re
+import shlex
+
+from .cross_project import _SKIP_DIRS, _discover_projects
+
+# Bytes read from extensionless files to check for a shell shebang.
+_SHEBANG_BYTES = 128
+
+# Largest file parsed.
+_MAX_FILE_BYTES = 1 << 20
+
+_SHELL_EXTS = {".sh", ".bash", ".zsh", ".ksh"}
+_MAKEFILE_NAMES = {"Makefile", "makefile", "GNUmakefile"}
+_SHEBANG_RE = re.compile(rb"^#!\s*(?:\S*/)?(?:env\s+)?(?:ba|z|k|da)?sh\b")
+
+# Words that precede the real command.
+_PREFIX_WORDS = {
+    "exec", "sudo", "time", "nohup", "command", "env", "nice", "xargs",
+    "then", "do", "else", "elif", "if", "while", "until", "!", "{", "(",
+}
+_SHELL_RUNNERS = {"bash", "sh", "zsh", "ksh", "dash", "source", "."}
+_MAKE_WORDS = {"make", "gmake"}
+_SEPARATOR_RE = re.compile(r"&&|\|\||[;|&`]|\$\(")
+_ASSIGNMENT_RE = re.compile(r"^[A-Za-z_][A-Za-z0-9_]*=")
+# $(dirname "$0") and $(dirname "${BASH_SOURCE[0]}") name the script's own
+# directory; they are rewritten to a variable before commands are split.
+_DIRNAME_RE = re.compile(
+    r"""\$\(\s*dirname\s+["']?\$\{?(?:0|BASH_SOURCE(?:\[0\])?)\}?["']?\s*\)"""
+)
+# A leading "$DIR", "${SCRIPT_DIR}", or "${BASH_SOURCE%/*}" path component
+# conventionally means the script's own directory too.
+_SELF_DIR_RE = re.compile(r"^\$\{?[A-Za-z_]\w*(?:%/\*)?\}?/")
+
+
+def scan_scripts(root: str, max_depth: int = 4, projects: list[dict] | None = None) -> dict:
+    """Scan root for shell scripts and Makefiles and their invocation edges.
+
+    Args:
+        root: Workspace or project root
+        max_depth: Directory levels below root searched for projects
+        projects: Already discovered projects ({name, path}), instead of
+            searching again
+
+    Returns:
+        Dict with files (each script or Makefile with its project, empty
+        for workspace-level glue outside every project), edges ({from,
+        from_project, line, kind, to, to_project}), and binaries (name ->
+        building project).
+    """
+    root = os.path.abspath(root)
+    projects = list(projects) if projects is not None else _discover_projects(root, max_depth)
+    if not projects:
+        projects = [{"name": os.path.basename(root), "path": root, "group": ""}]
+    binaries = _project_binaries(projects)
+
+    def owner(path: str) -> str:
+        best, best_len = "", -1
+        for p in projects:
+            if (path == p["path"] or path.startswith(p["path"] + os.sep)) and len(p["path"]) > best_len:
+                best, best_len = p["name"], len(p["path"])
+        return best
+
+    files = []
+    edges = []
+    for path, kind in _iter_scripts(root, [p["path"] for p in projects]):
+        rel = os.path.relpath(path, root).replace(os.sep, "/")
+        files.append({"file": rel, "kind": kind, "project": owner(path)})
+        for line_no, command in _commands(path, kind):
+            edge = _classify(command, os.path.dirname(path), root, binaries)
+            if edge is None:
+                continue
+            to_kind, target, to_project = edge
+            if to_kind != "binary":
+                to_project = owner(target)
+                target = os.path.relpath(target, root).replace(os.sep, "/")
+            if target == rel:
+                continue
+            edges.append({
+                "from": rel,
+                "from_project": owner(path),
+                "line": line_no,
+                "kind": to_kind,
+                "to": target,
+                "to_project": to_project,
+            })
+
+    return {
+        "root": root,
+        "files": files,
+        "edges": edges,
+        "binaries": dict(sorted(binaries.items())),
+        "total_files": len(files),
+        "total_edges": len(edges),
+    }
+
+
+def _iter_scripts(root: str, project_paths: list[str]):
+    """Yield (path, "shell" | "make") for scripts and Makefiles under root.
+
+    Only project directories and the directories leading to them are
+    searched, so a workspace root full of unrelated trees stays cheap.
+    """
+    def wanted(path: str) -> bool:
+        return any(
+            path == p or path.startswith(p + os.sep) or p.startswith(path + os.sep)
+            for p in project_paths
+        )
+
+    for dirpath, dirnames, filenames in os.walk(root):
+        dirnames[:] = sorted(
+            d for d in dirnames
+            if d not in _SKIP_DIRS and not d.startswith(".") and wanted(os.path.join(dirpath, d))
+        )
+        for name in sorted(filenames):
+            path = os.path.join(dirpath, name)
+            if name in _MAKEFILE_NAMES or name.endswith(".mk"):
+                yield path, "make"
+            elif os.path.splitext(name)[1] in _SHELL_EXTS:
+                yield path, "shell"
+            elif "." not in name and _has_shell_shebang(path):
+                yield path, "shell"
+
+
+def _has_shell_shebang(path: str) -> bool:
+    try:
+        with open(path, "rb") as f:
+            return bool(_SHEBANG_RE.match(f.read(_SHEBANG_BYTES)))
+    except OSError:
+        return False
+
+
+def _commands(path: str, kind: str):
+    """Yield (line number, command text) for each simple command in a file.
+
+    For Makefiles only recipe lines (tab-indented) are commands; their @, -,
+    and + prefixes are dropped. Backslash continuations are joined and
+    reported at their first line.
+    """
+    try:
+        if os.path.getsize(path) > _MAX_FILE_BYTES:
+            return
+        with open(path, encoding="utf-8", errors="replace") as f:
+            lines = f.read().splitlines()
+    except OSError:
+        return
+
+    pending, start = "", 0
+    for i, line in enumerate(lines, 1):
+        if kind == "make" and not pending:
+            if not line.startswith("\t"):
+                continue
+            line = line.lstrip("\t").lstrip("@-+ ")
+        if line.rstrip().endswith("\\"):
+            if not pending:
+                start = i
+            pending += line.rstrip()[:-1] + " "
+            continue
+        if pending:
+            line, i = pending + line, start
+            pending = ""
+        text = _strip_comment(line).strip()
+        if not text:
+            continue
+        text = _DIRNAME_RE.sub("${SELF_DIR}", text)
+        if kind == "make":
+            text = text.replace("$(MAKE)", "make").replace("${MAKE}", "make").replace("$$", "$")
+        for part in _SEPARATOR_RE.split(text):
+            part = part.strip().rstrip(")").strip()
+            if part:
+                yield i, part
+
+
+def _strip_comment(line: str) -> str:
+    """Drop a trailing # comment that is not inside quotes."""
+    quote = None
+    for i, ch in enumerate(line):
+        if quote:
+            if ch == quote:
+                quote = None
+        elif ch in "'\"":
+            quote = ch
+        elif ch == "#" and (i == 0 or line[i - 1] in " \t;"):
+            return line[:i]
+    return line
+
+
+def _words(command: str) -> list[str]:
+    try:
+        return shlex.split(command, posix=True)
+    except ValueError:
+        return command.split()
+
+
+def _classify(command: str, base_dir: str, root: str, binaries: dict):
+    """Return (kind, target, binary project) for a command, or None.
+
+    target is an absolute path for script and make edges and a binary name
+    for binary edges.
+    """
+    words = _words(command)
+    while words and (words[0] in _PREFIX_WORDS or _ASSIGNMENT_RE.match(words[0])):
+        words = words[1:]
+    if not words:
+        return None
+    cmd = words[0]
+
+    if cmd in _MAKE_WORDS:
+        for i, w in enumerate(words[1:], 1):
+            target = None
+            if w == "-C" and i + 1 < len(words):
+                target = words[i + 1]
+            elif w.startswith("-C") and len(w) > 2:
+                target = w[2:]
+            elif w.startswith("--directory="):
+                target = w[len("--directory="):]
+            if target is not None:
+                path = _resolve(target, base_dir, root)
+                if path and os.path.isdir(path):
+                    for name in sorted(_MAKEFILE_NAMES):
+                        if os.path.isfile(os.path.join(path, name)):
+                            return "make", os.path.join(path, name), ""
+                return None
+        return None
+
+    if cmd == "go" and len(words) > 2 and words[1] == "run":
+        args = [w for w in words[2:] if not w.startswith("-")]
+        path = _resolve(args[0], base_dir, root) if args else None
+        if path and os.path.isdir(path):
+            name = os.path.basename(path)
+            return "binary", name, binaries.get(name, "")
+        return None
+
+    if cmd in _SHELL_RUNNERS:
+        args = [w for w in words[1:] if not w.startswith("-")]
+        if not args:
+            return None
+        cmd = args[0]
+
+    if "/" in cmd or cmd.endswith(tuple(_SHELL_EXTS)):
+        path = _resolve(cmd, base_dir, root)
+        if path and os.path.isfile(path):
+            return "script", path, ""
+        return None
+
+    if cmd in binaries:
+        return "binary", cmd, binaries[cmd]
+    return None
+
+
+def _resolve(word: str, base_dir: str, root: str) -> str | None:
+    """Resolve a path word against the calling file's directory, then root."""
+    word = _SELF_DIR_RE.sub("./", word, count=1)
+    if "$" in word or "*" in word:
+        return None
+    if os.path.isabs(word):
+        return os.path.normpath(word)
+    for base in (base_dir, root):
+        path = os.path.normpath(os.path.join(base, word))
+        if os.path.exists(path):
+            return path
+    return None
+
+
+def _project_binaries(projects: list[dict]) -> dict[str, str]:
+    """Map each binary name the projects build to the building project.
+
+    A name claimed by two projects goes to the first in discovery order.
+    """
+    binaries: dict[str, str] = {}
+    for p in projects:
+        for name in _binaries_of(p["path"], p["name"]):
+            binaries.setdefault(name, p["name"])
+    return binaries
+
+
+def _binaries_of(path: str, project_name: str) -> list[str]:
+    names = []
+
+    if os.path.isfile(os.path.join(path, "go.mod")):
+        cmd_dir = os.path.join(path, "cmd")
+        if os.path.isdir(cmd_dir):
+            for entry in sorted(os.listdir(cmd_dir)):
+                if _has_go_files(os.path.join(cmd_dir, entry)):
+                    names.append(entry)
+        if _is_go_main(path):
+            names.append(project_name)
+
+    pyproject = os.path.join(path, "pyproject.toml")
+    if os.path.isfile(pyproject):
+        names += _toml_section_keys(pyproject, ("project.scripts", "tool.poetry.scripts"))
+
+    package_json = os.path.join(path, "package.json")
+    if os.path.isfile(package_json):
+        try:
+            with open(package_json, encoding="utf-8") as f:
+                manifest = json.load(f)
+        except (OSError, json.JSONDecodeError):
+            manifest = {}
+        bin_field = manifest.get("bin") if isinstance(manifest, dict) else None
+        if isinstance(bin_field, dict):
+            names += list(bin_field)
+        elif isinstance(bin_field, str) and manifest.get("name"):
+            names.append(manifest["name"].rsplit("/", 1)[-1])
+
+    cargo = os.path.join(path, "Cargo.toml")
+    if os.path.isfile(cargo):
+        try:
+            with open(cargo, encoding="utf-8", errors="replace") as f:
+                content = f.read()
+        except OSError:
+            content = ""
+        for block in re.findall(r"^\[\[bin\]\]\s*\n((?:(?!\[).*\n?)*)", content, re.M):
+            m = re.search(r'^\s*name\s*=\s*"([^"]+)"', block, re.M)
+            if m:
+                names.append(m.group(1))
+        if os.path.isfile(os.path.join(path, "src", "main.rs")):
+            m = re.search(r'^\[package\]\s*\n(?:(?!\[).*\n)*?\s*name\s*=\s*"([^"]+)"', content, re.M)
+            if m:
+                names.append(m.group(1))
+
+    return names
+
+
+def _has_go_files(path: str) -> bool:
+    try:
+        return os.path.isdir(path) and any(n.endswith(".go") for n in os.listdir(path))
+    except OSError:
+        return False
+
+
+def _is_go_main(path: str) -> bool:
+    main = os.path.join(path, "main.go")
+    try:
+        with open(main, encoding="utf-8", errors="replace") as f:
+            return re.search(r"^package\s+main\b", f.read(4096), re.M) is not None
+    except OSError:
+        return False
+
+
+def _toml_section_keys(path: str, sections: tuple[str, ...]) -> list[str]:
+    """Keys of the named TOML tables, read line by line."""
+    try:
+        with open(path, encoding="utf-8", errors="replace") as f:
+            lines = f.read().splitlines()
+    except OSError:
+        return []
+    keys = []
+    current = None
+    for line in lines:
+        stripped = line.strip()
+        if stripped.startswith("["):
+            current = stripped.strip("[]").strip()
+            continue
+        if current in sections:
+            m = re.match(r'^"?([\w.-]+)"?\s*=', stripped)
+            if m:
+                keys.append(m.group(1))
+    return keys
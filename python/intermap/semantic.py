@@ -0,0 +1,307 @@
+"""Semantic code search over embedded symbol chunks in local SQLite.
+
+Source files are cut into chunks: one per function, class, or method (its
+line range from the extractor, capped at ``_CHUNK_LINES``), module-level
+code before the first symbol, and fixed windows for files without
+symbols. Each chunk is embedded with the configured provider and stored
+with a hash of its text, so re-indexing only embeds chunks that changed
+and unchanged files (by mtime) are not even re-read.
+
+Providers:
+
+- ``openai``: POST to an OpenAI-compatible embeddings endpoint (OpenAI,
+  Ollama's ``/v1/embeddings``, vLLM, ...), with a bearer token taken from
+  the environment variable named by ``api_key_env``.
+- ``local``: a sentence-transformers model, if the package is installed.
+
+Vectors are stored unit-normalized as float32 blobs, keyed by the absolute
+file path and ``provider:model``, so one database serves every project and
+switching models re-embeds instead of mixing vector spaces.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import heapq
+import json
+import math
+import os
+import sqlite3
+import urllib.request
+from array import array
+from pathlib import Path
+from typing import Callable
+
+from .code_search import file_symbols, iter_sources, owner
+from .cross_project import _discover_projects
+from .extractors import DefaultExtractor
+
+_SCHEMA = """
+CREATE TABLE IF NOT EXISTS files (
+    model TEXT NOT NULL,
+    path TEXT NOT NULL,
+    mtime INTEGER NOT NULL,
+    PRIMARY KEY (model, path)
+);
+CREATE TABLE IF NOT EXISTS chunks (
+    id INTEGER PRIMARY KEY,
+    model TEXT NOT NULL,
+    path TEXT NOT NULL,
+    project TEXT,
+    start_line INTEGER NOT NULL,
+    end_line INTEGER NOT NULL,
+    symbol TEXT,
+    symbol_id TEXT,
+    kind TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    vector BLOB
+);
+CREATE INDEX IF NOT EXISTS chunks_model_path ON chunks(model, path);
+"""
+
+_CHUNK_LINES = 80
+_MAX_CHUNK_CHARS = 4000
+_BATCH = 64
+
+Embedder = Callable[[list[str]], list[list[float]]]
+
+
+def semantic_search(
+    db_path: str,
+    path: str,
+    query: str,
+    provider: str = "openai",
+    endpoint: str = "",
+    model: str = "",
+    api_key_env: str = "",
+    top_k: int = 10,
+    max_embed: int = 2000,
+    max_depth: int = 4,
+    embed: Embedder | None = None,
+) -> dict:
+    """Bring the index for path up to date, then rank chunks against query.
+
+    Args:
+        db_path: SQLite database holding the vectors
+        path: Project or workspace root to search
+        query: Natural-language query
+        provider, endpoint, model, api_key_env: Embedding provider settings
+        top_k: Number of results
+        max_embed: Embed at most this many new chunks per call; the rest
+            are reported as pending and indexed by later calls
+        max_depth: Directory levels below path searched for projects
+        embed: Embedding function overriding the provider (tests)
+
+    Returns:
+        Dict with query, model, index ({files, chunks, embedded, pending}),
+        and results ({file, project, line, end_line, symbol, symbol_id,
+        kind, score, preview}).
+    """
+    if not query.strip():
+        raise ValueError("query is required")
+    embed = embed or embedder(provider, endpoint, model, api_key_env)
+    model_key = f"{provider}:{model}"
+    root = str(Path(path).resolve())
+
+    conn = _connect(db_path)
+    try:
+        index = _sync(conn, root, model_key, embed, max_embed, max_depth)
+        [qvec] = embed([query])
+        qvec = _normalize(qvec)
+
+        rows = conn.execute(
+            "SELECT path, project, start_line, end_line, symbol, symbol_id, kind, vector FROM chunks"
+            " WHERE model = ? AND vector IS NOT NULL AND (path = ? OR path LIKE ? ESCAPE '\\')",
+            (model_key, root, _like_prefix(root)),
+        )
+        scored = heapq.nlargest(
+            top_k,
+            ((_dot(qvec, _unpack(row[7])), row) for row in rows),
+            key=lambda item: item[0],
+        )
+    finally:
+        conn.close()
+
+    results = []
+    for score, (abs_path, project, start, end, symbol, symbol_id, kind, _) in scored:
+        results.append({
+            "file": os.path.relpath(abs_path, root).replace(os.sep, "/"),
+            "project": project,
+            "line": start,
+            "end_line": end,
+            "symbol": symbol,
+            "symbol_id": symbol_id,
+            "kind": kind,
+            "score": round(score, 4),
+            "preview": _preview(abs_path, start),
+        })
+    return {"root": root, "query": query, "model": model_key, "index": index, "results": results}
+
+
+def embedder(provider: str, endpoint: str, model: str, api_key_env: str = "") -> Embedder:
+    """Return an embedding function for the configured provider."""
+    if provider == "local":
+        try:
+            from sentence_transformers import SentenceTransformer
+        except ImportError as e:
+            raise RuntimeError("semantic_search provider \"local\" needs the sentence-transformers package") from e
+        st = SentenceTransformer(model or "all-MiniLM-L6-v2")
+        return lambda texts: [list(map(float, v)) for v in st.encode(texts)]
+    if provider != "openai":
+        raise ValueError(f"unknown embedding provider {provider!r} (want openai or local)")
+    if not endpoint:
+        raise ValueError("semantic_search.endpoint is required for the openai provider")
+
+    headers = {"Content-Type": "application/json"}
+    key = os.environ.get(api_key_env, "") if api_key_env else ""
+    if key:
+        headers["Authorization"] = f"Bearer {key}"
+
+    def embed(texts: list[str]) -> list[list[float]]:
+        body = json.dumps({"model": model, "input": texts}).encode()
+        req = urllib.request.Request(endpoint, data=body, headers=headers, method="POST")
+        with urllib.request.urlopen(req, timeout=50) as resp:
+            data = json.load(resp)["data"]
+        return [d["embedding"] for d in sorted(data, key=lambda d: d.get("index", 0))]
+
+    return embed
+
+
+def _connect(db_path: str) -> sqlite3.Connection:
+    Path(db_path).parent.mkdir(parents=True, exist_ok=True)
+    conn = sqlite3.connect(db_path)
+    conn.executescript(_SCHEMA)
+    return conn
+
+
+def _sync(conn: sqlite3.Connection, root: str, model_key: str, embed: Embedder, max_embed: int, max_depth: int) -> dict:
+    """Re-chunk changed files under root and embed up to max_embed new chunks."""
+    projects = _discover_projects(root, max_depth) or [{"name": os.path.basename(root), "path": root}]
+    extractor = DefaultExtractor()
+    known = dict(conn.execute(
+        "SELECT path, mtime FROM files WHERE model = ? AND (path = ? OR path LIKE ? ESCAPE '\\')",
+        (model_key, root, _like_prefix(root)),
+    ).fetchall())
+
+    seen = set()
+    for file_path, _rel, text in iter_sources(root):
+        abs_path = str(file_path)
+        seen.add(abs_path)
+        try:
+            mtime = file_path.stat().st_mtime_ns
+        except OSError:
+            continue
+        if known.get(abs_path) == mtime:
+            continue
+        project = owner(projects, abs_path)
+        _replace_chunks(conn, model_key, abs_path, project, _chunks(extractor, file_path, project, text))
+        conn.execute("INSERT OR REPLACE INTO files (model, path, mtime) VALUES (?, ?, ?)", (model_key, abs_path, mtime))
+
+    for gone in set(known) - seen:
+        conn.execute("DELETE FROM chunks WHERE model = ? AND path = ?", (model_key, gone))
+        conn.execute("DELETE FROM files WHERE model = ? AND path = ?", (model_key, gone))
+    conn.commit()
+
+    pending = conn.execute(
+        "SELECT id, path, start_line, end_line, symbol, kind FROM chunks"
+        " WHERE model = ? AND vector IS NULL AND (path = ? OR path LIKE ? ESCAPE '\\') ORDER BY path, start_line",
+        (model_key, root, _like_prefix(root)),
+    ).fetchall()
+    embedded = 0
+    for i in range(0, min(len(pending), max_embed), _BATCH):
+        batch = pending[i:min(i + _BATCH, max_embed)]
+        texts = [_chunk_text(root, *row[1:]) for row in batch]
+        vectors = embed(texts)
+        conn.executemany(
+            "UPDATE chunks SET vector = ? WHERE id = ?",
+            [(_pack(_normalize(v)), row[0]) for row, v in zip(batch, vectors)],
+        )
+        conn.commit()
+        embedded += len(batch)
+
+    files, chunks = conn.execute(
+        "SELECT COUNT(DISTINCT path), COUNT(*) FROM chunks WHERE model = ? AND (path = ? OR path LIKE ? ESCAPE '\\')",
+        (model_key, root, _like_prefix(root)),
+    ).fetchone()
+    return {"files": files, "chunks": chunks, "embedded": embedded, "pending": len(pending) - embedded}
+
+
+def _chunks(extractor: DefaultExtractor, file_path: Path, project: dict, text: str) -> list[dict]:
+    """Cut a file into symbol chunks, leading module code, or line windows."""
+    lines = text.splitlines()
+    symbols = file_symbols(extractor, file_path, project, len(lines))
+    chunks = []
+    for s in symbols:
+        end = min(s["end_line"], s["line"] + _CHUNK_LINES - 1)
+        chunks.append({"start": s["line"], "end": end, "symbol": s["name"], "symbol_id": s["id"], "kind": s["kind"]})
+
+    first = min((s["line"] for s in symbols), default=len(lines) + 1)
+    for start in range(1, first, _CHUNK_LINES):
+        end = min(first - 1, start + _CHUNK_LINES - 1)
+        if sum(1 for line in lines[start - 1:end] if line.strip()) >= 3:
+            chunks.append({"start": start, "end": end, "symbol": None, "symbol_id": None, "kind": "module"})
+
+    for c in chunks:
+        c["hash"] = hashlib.sha1("\n".join(lines[c["start"] - 1:c["end"]]).encode()).hexdigest()
+    return chunks
+
+
+def _replace_chunks(conn: sqlite3.Connection, model_key: str, path: str, project: dict, chunks: list[dict]) -> None:
+    """Store a file's chunks, keeping vectors of chunks whose text is unchanged."""
+    vectors = dict(conn.execute(
+        "SELECT hash, vector FROM chunks WHERE model = ? AND path = ? AND vector IS NOT NULL",
+        (model_key, path),
+    ).fetchall())
+    conn.execute("DELETE FROM chunks WHERE model = ? AND path = ?", (model_key, path))
+    conn.executemany(
+        "INSERT INTO chunks (model, path, project, start_line, end_line, symbol, symbol_id, kind, hash, vector)"
+        " VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+        [
+            (model_key, path, project["name"], c["start"], c["end"], c["symbol"], c["symbol_id"], c["kind"], c["hash"], vectors.get(c["hash"]))
+            for c in chunks
+        ],
+    )
+
+
+def _chunk_text(root: str, path: str, start: int, end: int, symbol: str | None, kind: str) -> str:
+    """The text embedded for a chunk: its location and name, then its code."""
+    try:
+        lines = Path(path).read_text(encoding="utf-8", errors="replace").splitlines()
+    except OSError:
+        lines = []
+    header = os.path.relpath(path, root).replace(os.sep, "/")
+    if symbol:
+        header += f"\n{kind} {symbol}"
+    return (header + "\n" + "\n".join(lines[start - 1:end]))[:_MAX_CHUNK_CHARS]
+
+
+def _preview(path: str, line: int, count: int = 3) -> str:
+    try:
+        lines = Path(path).read_text(encoding="utf-8", errors="replace").splitlines()
+    except OSError:
+        return ""
+    return "\n".join(lines[line - 1:line - 1 + count])
+
+
+def _like_prefix(root: str) -> str:
+    escaped = root.replace("\\", "\\\\").replace("%", "\\%").replace("_", "\\_")
+    return escaped.rstrip(os.sep) + os.sep + "%"
+
+
+def _normalize(vec: list[float]) -> list[float]:
+    norm = math.sqrt(sum(x * x for x in vec)) or 1.0
+    return [x / norm for x in vec]
+
+
+def _dot(a: list[float], b: list[float]) -> float:
+    return sum(x * y for x, y in zip(a, b))
+
+
+def _pack(vec: list[float]) -> bytes:
+    return array("f", vec).tobytes()
+
+
+def _unpack(blob: bytes) -> array:
+    vec = array("f")
+    vec.frombytes(blob)
+    return vec
@@ -0,0 +1,160 @@
+"""Runtime service dependencies between deployable projects.
+
+The import graph says which code links against which; it says nothing
+about which running service calls which. build_service_graph() infers
+that from client code:
+
+- HTTP and gRPC clients resolved by boundaries (URL literals naming a
+  project or a port it listens on, generated gRPC stubs, gRPC dials);
+- environment variables that name a sibling's endpoint, read through
+  os.Getenv, os.environ/os.getenv, process.env, or std::env::var:
+  INTERMUTE_URL or INTERMUTE_GRPC_ADDR point at the intermute project;
+- endpoint variables assigned a URL in a project's .env files.
+
+A project is a deployable unit when it listens on a port, ships a
+Dockerfile, or builds a binary; edges only connect different projects.
+"""
+
+import os
+import re
+
+from .boundaries import _iter_sources, _language, _line, _read_all, _resolve_host, scan_boundaries
+from .cross_project import _discover_projects
+from .script_map import _project_binaries
+
+# Suffixes marking an environment variable as a service endpoint; the
+# rest of the name, lowercased, names the target project.
+_ENDPOINT_SUFFIXES = (
+    "_GRPC_ADDR", "_GRPC_URL", "_GRPC_TARGET",
+    "_API_URL", "_BASE_URL", "_SERVICE_URL", "_URL", "_ADDR", "_ADDRESS",
+    "_HOST", "_ENDPOINT",
+)
+
+_ENV_RES = {
+    "go": re.compile(r'\bos\.(?:Getenv|LookupEnv)\(\s*"(\w+)"'),
+    "py": re.compile(r"\bos\.(?:environ(?:\.get)?\s*[\[(]|getenv\()\s*[\"'](\w+)[\"']"),
+    "js": re.compile(r"\bprocess\.env(?:\.(\w+)|\[\s*['\"](\w+)['\"]\s*\])"),
+    "rs": re.compile(r'\benv::var(?:_os)?\(\s*"(\w+)"'),
+}
+
+_DOTENV_RE = re.compile(r"^[ \t]*(?:export\s+)?(\w+)[ \t]*=[ \t]*[\"']?([^\s\"'#]+)", re.M)
+_ADDRESS_RE = re.compile(r"^(?:(\w+)://)?([A-Za-z0-9_.-]+)(?::(\d+))?")
+
+
+def build_service_graph(root: str, max_depth: int = 4) -> dict:
+    """Infer which projects under root call which at runtime.
+
+    Args:
+        root: Workspace root
+        max_depth: Directory levels below root searched for projects
+
+    Returns:
+        Dict with services (one per project: deployable, ports, binaries,
+        has_dockerfile, calls, called_by), edges ({from, to, protocols,
+        sites: [{file, line, protocol, via}]}), and totals.
+    """
+    root = os.path.abspath(root)
+    projects = _discover_projects(root, max_depth)
+    if not projects:
+        projects = [{"name": os.path.basename(root), "path": root, "group": ""}]
+    names = {p["name"] for p in projects}
+
+    def owner(path: str) -> str:
+        best, best_len = "", -1
+        for p in projects:
+            if (path == p["path"] or path.startswith(p["path"] + os.sep)) and len(p["path"]) > best_len:
+                best, best_len = p["name"], len(p["path"])
+        return best
+
+    scanned = scan_boundaries(root, projects=projects, kinds=["http", "grpc"])
+    edges: dict[tuple[str, str], dict] = {}
+
+    def add(src: str, dst: str, site: dict) -> None:
+        if not src or not dst or src == dst:
+            return
+        edge = edges.setdefault((src, dst), {"from": src, "to": dst, "protocols": [], "sites": []})
+        if site["protocol"] not in edge["protocols"]:
+            edge["protocols"].append(site["protocol"])
+        edge["sites"].append(site)
+
+    for b in scanned["boundaries"]:
+        add(b["project"], b["to_project"], {"file": b["file"], "line": b["line"], "protocol": b["kind"], "via": b["target"]})
+
+    files = list(_iter_sources(root, [p["path"] for p in projects]))
+    dockerfiles = {owner(f) for f in files if os.path.basename(f).startswith("Dockerfile")}
+    for path, text in _read_all(files):
+        lang = _language(path)
+        if lang not in _ENV_RES:
+            continue
+        rel = os.path.relpath(path, root).replace(os.sep, "/")
+        for m in _ENV_RES[lang].finditer(text):
+            var = next(g for g in m.groups() if g)
+            target = _env_target(var, names)
+            if target:
+                add(owner(path), target, {"file": rel, "line": _line(text, m.start()), "protocol": _env_protocol(var), "via": f"${var}"})
+
+    for p in projects:
+        for path, text in _read_all(_dotenv_files(p["path"])):
+            rel = os.path.relpath(path, root).replace(os.sep, "/")
+            for m in _DOTENV_RE.finditer(text):
+                var, value = m.groups()
+                address = _ADDRESS_RE.match(value)
+                if not var.endswith(_ENDPOINT_SUFFIXES) or address is None:
+                    continue
+                scheme, host, port = address.groups()
+                target = _resolve_host(host, port, names, scanned["ports"]) or _env_target(var, names)
+                if target:
+                    protocol = "grpc" if (scheme or "").startswith("grpc") else _env_protocol(var)
+                    add(p["name"], target, {"file": rel, "line": _line(text, m.start()), "protocol": protocol, "via": f"{var}={value}"})
+
+    binaries = _project_binaries(projects)
+    services = []
+    for p in projects:
+        name = p["name"]
+        ports = sorted((port for port, proj in scanned["ports"].items() if proj == name), key=int)
+        built = sorted(b for b, proj in binaries.items() if proj == name)
+        services.append({
+            "project": name,
+            "deployable": bool(ports or built or name in dockerfiles),
+            "ports": ports,
+            "binaries": built,
+            "has_dockerfile": name in dockerfiles,
+            "calls": sorted(dst for src, dst in edges if src == name),
+            "called_by": sorted(src for src, dst in edges if dst == name),
+        })
+
+    ordered = [edges[k] for k in sorted(edges)]
+    return {
+        "root": root,
+        "services": services,
+        "edges": ordered,
+        "total_deployable": sum(1 for s in services if s["deployable"]),
+        "total_edges": len(ordered),
+    }
+
+
+def _env_target(var: str, names: set[str]) -> str:
+    """The project an endpoint variable names: INTERMUTE_URL -> intermute."""
+    for suffix in _ENDPOINT_SUFFIXES:
+        if var.endswith(suffix) and len(var) > len(suffix):
+            stem = var[: -len(suffix)].lower()
+            for candidate in (stem, stem.replace("_", "-")):
+                if candidate in names:
+                    return candidate
+    return ""
+
+
+def _env_protocol(var: str) -> str:
+    return "grpc" if "_GRPC_" in var else "http"
+
+
+def _dotenv_files(project_path: str) -> list[str]:
+    """.env and .env.* files at a project's top level."""
+    try:
+        entries = sorted(os.listdir(project_path))
+    except OSError:
+        return []
+    return [
+        os.path.join(project_path, e) for e in entries
+        if (e == ".env" or e.startswith(".env.")) and os.path.isfile(os.path.join(project_path, e))
+    ]
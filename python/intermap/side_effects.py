@@ -0,0 +1,158 @@
+"""Known side-effecting APIs, by language and capability.
+
+Calls (and, for environment access, attribute references) are matched by
+their dotted name as written, after resolving Python import aliases.
+Method calls are only matched where the receiver name makes the API
+unambiguous (``db.Query``, ``cursor.execute``), so ``once.Do`` or
+``url.Query()`` are not mistaken for network or database access.
+
+Capabilities: ``filesystem``, ``network``, ``subprocess``, ``env``, ``database``.
+
+For ``taint_paths`` there are two narrower tables: input sources (``http``
+request data, ``cli`` arguments, ``env`` variables) and sensitive sinks
+(``exec`` of processes or code, ``sql`` statements, ``file_write``).
+"""
+
+from __future__ import annotations
+
+import re
+
+CAPABILITIES = ("filesystem", "network", "subprocess", "env", "database")
+
+_DB_RECEIVER = r"(?:^|\.)(?:db|DB|tx|Tx|conn|Conn|connection|cur|cursor|stmt|pool|session)"
+
+_SINKS: dict[str, list[tuple[str, str]]] = {
+    "python": [
+        ("filesystem", r"^(?:open|io\.open|os\.(?:remove|unlink|rename|replace|makedirs|mkdir|rmdir|removedirs|listdir|scandir|walk|chmod|chown|symlink|link|truncate)|shutil\.\w+|tempfile\.\w+|glob\.i?glob)$"
+                       r"|\.(?:read_text|write_text|read_bytes|write_bytes|unlink|mkdir|rmdir|touch|iterdir|rglob)$"),
+        ("network", r"^(?:requests|httpx|aiohttp|socket|urllib\.request|http\.client|smtplib|ftplib|grpc|websockets?|paramiko)\.\w+|^urllib\.request\.urlopen$"),
+        ("subprocess", r"^(?:subprocess\.\w+|os\.(?:system|popen|exec\w*|spawn\w*|fork)|asyncio\.create_subprocess_\w+|pty\.spawn)$"),
+        ("env", r"^os\.(?:environ|getenv|putenv|unsetenv|environb)\b"),
+        ("database", r"^(?:sqlite3|psycopg2?|pymysql|MySQLdb|sqlalchemy|pymongo|redis|asyncpg)\.\w+"
+                     + "|" + _DB_RECEIVER + r"\.(?:execute|executemany|executescript|commit|rollback|query)$"),
+    ],
+    "go": [
+        ("filesystem", r"^(?:os\.(?:Open|OpenFile|Create|CreateTemp|ReadFile|WriteFile|Remove|RemoveAll|Mkdir|MkdirAll|MkdirTemp|Rename|ReadDir|Stat|Lstat|Chmod|Chown|Symlink|Link|Truncate|DirFS)"
+                       r"|ioutil\.\w+|filepath\.(?:Walk|WalkDir|Glob)|fs\.(?:ReadFile|ReadDir|WalkDir|Glob|Stat))$"),
+        ("network", r"^(?:http\.(?:Get|Head|Post|PostForm|NewRequest|NewRequestWithContext|ListenAndServe|ListenAndServeTLS|Serve)"
+                    r"|net\.(?:Dial|DialTimeout|DialTCP|DialUDP|DialUnix|Listen|ListenTCP|ListenUDP|ListenPacket|LookupHost|LookupIP|LookupAddr)"
+                    r"|grpc\.(?:Dial|DialContext|NewClient)|tls\.(?:Dial|Listen)|smtp\.\w+|websocket\.\w+)$"
+                    r"|(?:^|\.)(?:Client|client|httpClient|DefaultClient)\.(?:Do|Get|Post|Head)$"),
+        ("subprocess", r"^(?:exec\.(?:Command|CommandContext)|syscall\.(?:Exec|ForkExec)|os\.StartProcess)$"),
+        ("env", r"^os\.(?:Getenv|LookupEnv|Setenv|Unsetenv|Environ|ExpandEnv|Clearenv)$"),
+        ("database", r"^sql\.(?:Open|OpenDB)$|^(?:pgx|pgxpool|gorm|mongo|redis)\.\w+$"
+                     + "|" + _DB_RECEIVER + r"\.(?:Query|QueryContext|QueryRow|QueryRowContext|Exec|ExecContext|Prepare|PrepareContext|Begin|BeginTx)$"),
+    ],
+    "javascript": [
+        ("filesystem", r"^(?:fs|fsPromises|fse|fs\.promises)\.\w+$|^(?:readFileSync|writeFileSync|appendFileSync|mkdirSync|rmSync|unlinkSync|existsSync|readdirSync)$"),
+        ("network", r"^(?:fetch|axios(?:\.\w+)?|https?\.(?:request|get|createServer)|net\.(?:connect|createConnection|createServer)|WebSocket|dgram\.createSocket)$"),
+        ("subprocess", r"^(?:child_process\.\w+|execSync|execFile|execFileSync|spawn|spawnSync|fork|Bun\.spawn|Deno\.run)$"),
+        ("env", r"^(?:process\.env|Deno\.env|import\.meta\.env)\b"),
+        ("database", r"^(?:knex|prisma|mongoose|sequelize)\b" + "|" + _DB_RECEIVER + r"\.(?:query|execute|exec|run|all|prepare)$"),
+    ],
+    "rust": [
+        ("filesystem", r"^(?:std::)?fs::\w+$|^(?:std::fs::)?(?:File|OpenOptions)::(?:open|create|new)$|^tokio::fs::\w+$"),
+        ("network", r"^(?:reqwest::\w+|(?:std::net::|tokio::net::)?(?:TcpStream::connect|TcpListener::bind|UdpSocket::bind)|hyper::\w+)$|(?:^|\.)client\.(?:get|post|execute)$"),
+        ("subprocess", r"^(?:std::process::|tokio::process::)?Command::new$"),
+        ("env", r"^(?:std::)?env::(?:var|var_os|vars|set_var|remove_var|args)$"),
+        ("database", r"^(?:sqlx::\w+|rusqlite::Connection::open|Connection::open|diesel::\w+)$" + "|" + _DB_RECEIVER + r"\.(?:execute|query|query_row|prepare)$"),
+    ],
+}
+_SINKS["typescript"] = _SINKS["javascript"]
+
+SOURCE_KINDS = ("http", "cli", "env")
+SINK_KINDS = ("exec", "sql", "file_write")
+
+_SOURCES: dict[str, list[tuple[str, str]]] = {
+    "python": [
+        ("http", r"(?:^|\.)request\.(?:args|form|values|json|data|files|cookies|headers|get_json|query_params|path_params|GET|POST|body|stream)\b"),
+        ("cli", r"^(?:sys\.argv|input|sys\.stdin)\b|\.parse_args$|^click\.(?:argument|option)$"),
+        ("env", r"^os\.(?:environ|getenv)\b"),
+    ],
+    "go": [
+        ("http", r"(?:^|\.)(?:r|req|request)\.(?:FormValue|PostFormValue|URL\.Query|URL\.Path|Form|PostForm|Body|Header\.Get|Cookie|PathValue|MultipartForm|FormFile)\b"
+                 r"|^mux\.Vars$|(?:^|\.)(?:c|ctx)\.(?:Param|Query|DefaultQuery|PostForm|FormValue|Bind\w*|ShouldBind\w*)$"),
+        ("cli", r"^(?:os\.Args|os\.Stdin|flag\.(?:Arg|Args|String|Int|Bool|Parse|StringVar|IntVar|BoolVar))\b"),
+        ("env", r"^os\.(?:Getenv|LookupEnv|Environ)$"),
+    ],
+    "javascript": [
+        ("http", r"(?:^|\.)(?:req|request|ctx\.request)\.(?:query|body|params|headers|cookies)\b|^(?:ctx|c)\.(?:query|params)\b"),
+        ("cli", r"^(?:process\.argv|process\.stdin)\b|^(?:yargs|commander|program)\.\w+|\.parse(?:Args)?$"),
+        ("env", r"^(?:process\.env|Deno\.env)\b"),
+    ],
+    "rust": [
+        ("http", r"^(?:web|axum::extract)::(?:Query|Json|Form|Path)\b|(?:^|\.)(?:req|request)\.(?:uri|body|headers|match_info|query_string)$"),
+        ("cli", r"^(?:std::)?env::args\b|^(?:std::io::)?stdin\b|^\w+::parse$"),
+        ("env", r"^(?:std::)?env::(?:var|var_os|vars)$"),
+    ],
+}
+_SOURCES["typescript"] = _SOURCES["javascript"]
+
+_TAINT_SINKS: dict[str, list[tuple[str, str]]] = {
+    "python": [
+        ("exec", r"^(?:subprocess\.\w+|os\.(?:system|popen|exec\w*|spawn\w*)|asyncio\.create_subprocess_\w+|eval|exec|compile)$"),
+        ("sql", _DB_RECEIVER + r"\.(?:execute|executemany|executescript|raw|query)$|^sqlalchemy\.text$"),
+        ("file_write", r"\.(?:write_text|write_bytes|unlink|rmdir|mkdir|touch)$|^(?:os\.(?:remove|unlink|rename|replace|makedirs|mkdir|rmdir|chmod|chown|symlink)|shutil\.\w+)$"),
+    ],
+    "go": [
+        ("exec", r"^(?:exec\.(?:Command|CommandContext)|syscall\.(?:Exec|ForkExec)|os\.StartProcess)$"),
+        ("sql", _DB_RECEIVER + r"\.(?:Query|QueryContext|QueryRow|QueryRowContext|Exec|ExecContext|Prepare|PrepareContext)$"),
+        ("file_write", r"^(?:os\.(?:Create|CreateTemp|WriteFile|OpenFile|Remove|RemoveAll|Mkdir|MkdirAll|Rename|Chmod|Chown|Symlink|Truncate)|ioutil\.WriteFile)$"),
+    ],
+    "javascript": [
+        ("exec", r"^(?:child_process\.\w+|execSync|execFile|execFileSync|spawn|spawnSync|eval|Function|vm\.run\w*)$"),
+        ("sql", _DB_RECEIVER + r"\.(?:query|execute|exec|run|all|prepare|raw)$|^(?:knex\.raw|prisma\.\$queryRawUnsafe|prisma\.\$executeRawUnsafe)$"),
+        ("file_write", r"^(?:fs|fsPromises|fse|fs\.promises)\.(?:write\w*|append\w*|unlink\w*|rm\w*|mkdir\w*|rename\w*|copyFile\w*|chmod\w*)$|^(?:writeFileSync|appendFileSync|rmSync|unlinkSync|mkdirSync)$"),
+    ],
+    "rust": [
+        ("exec", r"^(?:std::process::|tokio::process::)?Command::new$"),
+        ("sql", r"^sqlx::query\w*$" + "|" + _DB_RECEIVER + r"\.(?:execute|query|query_row|prepare)$"),
+        ("file_write", r"^(?:std::)?fs::(?:write|remove_file|remove_dir\w*|create_dir\w*|rename|copy|set_permissions)$|^(?:std::fs::)?File::create$"),
+    ],
+}
+_TAINT_SINKS["typescript"] = _TAINT_SINKS["javascript"]
+
+
+def _compile(table: dict[str, list[tuple[str, str]]]) -> dict[str, list[tuple[str, re.Pattern]]]:
+    return {lang: [(kind, re.compile(p)) for kind, p in entries] for lang, entries in table.items()}
+
+
+_COMPILED = _compile(_SINKS)
+_COMPILED_SOURCES = _compile(_SOURCES)
+_COMPILED_TAINT_SINKS = _compile(_TAINT_SINKS)
+
+
+def classify(name: str, language: str) -> str | None:
+    """Return the capability a dotted call or reference name exercises, if any."""
+    return _match(_COMPILED, name, language)
+
+
+def effects(names, language: str) -> dict[str, list[str]]:
+    """Group the side-effecting names by capability, sorted and de-duplicated."""
+    return _group(_COMPILED, names, language, CAPABILITIES)
+
+
+def taint_sources(names, language: str) -> dict[str, list[str]]:
+    """Group the names that read untrusted input by source kind."""
+    return _group(_COMPILED_SOURCES, names, language, SOURCE_KINDS)
+
+
+def taint_sinks(names, language: str) -> dict[str, list[str]]:
+    """Group the names that are sensitive sinks by sink kind."""
+    return _group(_COMPILED_TAINT_SINKS, names, language, SINK_KINDS)
+
+
+def _match(table, name: str, language: str) -> str | None:
+    for kind, pattern in table.get(language, ()):
+        if pattern.search(name):
+            return kind
+    return None
+
+
+def _group(table, names, language: str, order: tuple[str, ...]) -> dict[str, list[str]]:
+    out: dict[str, set[str]] = {}
+    for name in names:
+        kind = _match(table, name, language)
+        if kind:
+            out.setdefault(kind, set()).add(name)
+    return {kind: sorted(out[kind]) for kind in order if kind in out}
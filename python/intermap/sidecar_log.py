@@ -0,0 +1,61 @@
+"""Structured log frames for sidecar mode.
+
+In sidecar mode, log records are written to stdout as protocol frames
+alongside responses, so the Go bridge can turn them into slog records
+instead of interleaving raw text with its own stderr:
+
+    {"log": {"level": "warning", "logger": "intermap.vcs", "message": "...",
+             "request_id": 7, "command": "live_changes"}}
+
+The level threshold comes from INTERMAP_LOG_LEVEL (default "info").
+"""
+
+from __future__ import annotations
+
+import logging
+import os
+
+# The sidecar handles one request at a time, so records from any thread
+# belong to the current one.
+_request_id = None
+_command = ""
+
+
+def set_request(request_id, command: str) -> None:
+    """Tag subsequent records with the request being handled."""
+    global _request_id, _command
+    _request_id = request_id
+    _command = command
+
+
+class FrameHandler(logging.Handler):
+    """Logging handler that emits records as log frames via write."""
+
+    def __init__(self, write):
+        super().__init__()
+        self._write = write
+
+    def emit(self, record: logging.LogRecord) -> None:
+        try:
+            frame = {
+                "level": record.levelname.lower(),
+                "logger": record.name,
+                "message": record.getMessage(),
+                "request_id": _request_id,
+                "command": _command,
+            }
+            if record.exc_info:
+                frame["exception"] = logging.Formatter().formatException(record.exc_info)
+            self._write({"log": frame})
+        except Exception:
+            self.handleError(record)
+
+
+def install(write) -> None:
+    """Route the root logger through a FrameHandler."""
+    root = logging.getLogger()
+    for h in list(root.handlers):
+        root.removeHandler(h)
+    root.addHandler(FrameHandler(write))
+    level = os.environ.get("INTERMAP_LOG_LEVEL", "info").upper()
+    root.setLevel(getattr(logging, level, logging.INFO))
@@ -0,0 +1,146 @@
+"""Stable symbol IDs shared across analyses.
+
+A symbol ID has the form ``<package>#<qualified_name>@<sighash>``:
+
+  package         — Go: directory relative to the project root;
+                    Python: dotted module path; others: file path without
+                    extension.
+  qualified_name  — ``Scope.name`` for methods, ``name`` otherwise.
+  sighash         — first 8 hex chars of sha1 over the whitespace-normalized
+                    declaration header. Omitted when the declaration cannot
+                    be located.
+
+IDs survive moves between files of the same Go package and reorderings
+within a file, and distinguish same-named symbols in different packages.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import re
+from pathlib import Path, PurePosixPath
+
+_WS = re.compile(r"\s+")
+
+
+def package_path(rel_file: str, language: str) -> str:
+    """Return the package component of a symbol ID for a project-relative file."""
+    p = PurePosixPath(str(rel_file).replace("\\", "/"))
+    if language == "go":
+        parent = str(p.parent)
+        return "" if parent == "." else parent
+    if language == "python":
+        parts = list(p.with_suffix("").parts)
+        if parts and parts[-1] == "__init__":
+            parts = parts[:-1]
+        return ".".join(parts)
+    return str(p.with_suffix(""))
+
+
+def signature_hash(signature: str) -> str:
+    """Hash a declaration header, ignoring whitespace differences."""
+    normalized = _WS.sub(" ", signature).strip()
+    return hashlib.sha1(normalized.encode("utf-8")).hexdigest()[:8]
+
+
+def make_symbol_id(package: str, qualified_name: str, signature: str = "") -> str:
+    """Build a symbol ID from its components."""
+    sid = f"{package}#{qualified_name}"
+    if signature:
+        sid += "@" + signature_hash(signature)
+    return sid
+
+
+def parse_symbol_id(symbol_id: str) -> tuple[str, str, str]:
+    """Split a symbol ID into (package, qualified_name, sighash)."""
+    package, _, rest = symbol_id.partition("#")
+    name, _, sighash = rest.partition("@")
+    return package, name, sighash
+
+
+def read_signature(path: str | Path, line: int, max_lines: int = 10) -> str:
+    """Read the declaration header starting at a 1-based line.
+
+    Collects lines until parentheses balance, then cuts at the body opener
+    (``{`` or a trailing ``:``).
+    """
+    try:
+        lines = Path(path).read_text(errors="replace").splitlines()
+    except OSError:
+        return ""
+    if line < 1 or line > len(lines):
+        return ""
+
+    header = []
+    depth = 0
+    seen_paren = False
+    for text in lines[line - 1:line - 1 + max_lines]:
+        header.append(text)
+        depth += text.count("(") - text.count(")")
+        seen_paren = seen_paren or "(" in text
+        if depth <= 0 and (seen_paren or "{" in text or text.rstrip().endswith(":")):
+            break
+
+    sig = _WS.sub(" ", " ".join(header))
+    brace = sig.find("{")
+    if brace >= 0:
+        sig = sig[:brace]
+    sig = sig.rstrip()
+    if sig.endswith(":"):
+        sig = sig[:-1]
+    return sig.strip()
+
+
+def qualified_name(definition: dict) -> str:
+    """Return Scope.name for scoped definitions, name otherwise."""
+    scope = definition.get("scope") or ""
+    return f"{scope}.{definition['name']}" if scope else definition["name"]
+
+
+def annotate_definitions(root: str | Path, definitions: list[dict], language: str) -> list[dict]:
+    """Add an ``id`` field to each definition dict in place."""
+    root = Path(root)
+    for d in definitions:
+        sig = read_signature(root / d["file"], d.get("line", 0))
+        d["id"] = make_symbol_id(package_path(d["file"], language), qualified_name(d), sig)
+    return definitions
+
+
+class SymbolIdResolver:
+    """Resolve (file, qualified_name) to symbol IDs, parsing files lazily."""
+
+    def __init__(self, root: str | Path, language: str):
+        self.root = Path(root).resolve()
+        self.language = language
+        self._by_file: dict[str, dict[str, str]] = {}
+
+    def seed(self, definitions: list[dict]) -> None:
+        """Prime the resolver with definitions that already carry IDs."""
+        for d in definitions:
+            if "id" in d:
+                names = self._by_file.setdefault(d["file"], {})
+                names.setdefault(qualified_name(d), d["id"])
+                names.setdefault(d["name"], d["id"])
+
+    def resolve(self, rel_file: str, name: str) -> str:
+        """Return the symbol ID for name in rel_file."""
+        names = self._by_file.get(rel_file)
+        if names is None:
+            names = self._load(rel_file)
+        if name in names:
+            return names[name]
+        return make_symbol_id(package_path(rel_file, self.language), name)
+
+    def _load(self, rel_file: str) -> dict[str, str]:
+        from .cross_file_calls import definitions_for_file
+
+        names: dict[str, str] = {}
+        src = self.root / rel_file
+        if src.is_file():
+            defs = definitions_for_file(src, Path(rel_file), self.language)
+            annotate_definitions(self.root, defs, self.language)
+            for d in defs:
+                names.setdefault(qualified_name(d), d["id"])
+                names.setdefault(d["name"], d["id"])
+        self._by_file[rel_file] = names
+        return names
@@ -0,0 +1,459 @@
+"""Deterministic structural summaries of functions and methods.
+
+``describe_symbol`` answers "what does this function take, return, call,
+and touch" without an LLM and without reading the whole file: parameters
+with types and defaults, return types, raised exceptions, callees (project
+functions resolved through the call graph, plus external calls by name),
+callers, and side effects from ``side_effects`` (filesystem, network,
+subprocess, env, database), plus the untrusted inputs read and sensitive
+sinks used, for ``taint_paths``.
+
+Python is read with ``ast``. Go, TypeScript/JavaScript, and Rust are read
+from the declaration header and the brace-matched body, with comments and
+string literals blanked out first.
+
+Summaries are cached on the project's ``GraphStore`` and dropped when
+``index_update`` re-parses the file, so repeated calls are cheap.
+"""
+
+from __future__ import annotations
+
+import ast
+import re
+from pathlib import Path
+
+from .extractors import DefaultExtractor
+from .graph_store import get_store
+from .side_effects import effects, taint_sinks, taint_sources
+from .symbol_ids import make_symbol_id, package_path, parse_symbol_id, qualified_name, read_signature
+
+_MAX_MATCHES = 10
+_MAX_CALLERS = 25
+
+_CALL = re.compile(r"(?<![\w.:])([A-Za-z_]\w*(?:(?:\.|::)[A-Za-z_]\w*)*)\s*(?:::<[^>]*>)?\s*\(")
+_REF = re.compile(r"(?<![\w.:])([A-Za-z_]\w*(?:(?:\.|::)[A-Za-z_]\w*)+)")
+_KEYWORDS = {
+    "if", "for", "while", "switch", "return", "func", "function", "catch", "match",
+    "select", "go", "defer", "fn", "new", "typeof", "await", "async", "else", "loop",
+    "make", "len", "cap", "append", "copy", "delete", "panic", "recover", "print", "println",
+    "super", "sizeof", "Some", "Ok", "Err", "Box::new", "Vec::new", "String::from", "vec", "format",
+}
+_BRANCH = re.compile(r"\b(?:if|for|while|case|catch|match)\b|&&|\|\|")
+
+
+def describe_symbol(project: str, symbol: str, language: str, file: str | None = None) -> dict:
+    """Summarize the function or method named by symbol.
+
+    Args:
+        project: Project root
+        symbol: Name, Scope.name, or symbol ID
+        language: Project language
+        file: Project-relative file to disambiguate same-named symbols
+
+    Returns:
+        Dict with symbols (one summary per matching definition) and, when
+        more than _MAX_MATCHES match, truncated.
+    """
+    store = get_store(project, language)
+    if not store.is_current():
+        store.sync()
+
+    package = ""
+    name = symbol
+    if "#" in symbol:
+        package, name, _ = parse_symbol_id(symbol)
+
+    matches = []
+    for rel, defs in sorted(definitions(store).items()):
+        if file and rel != file:
+            continue
+        if package and package_path(rel, language) != package:
+            continue
+        for d in defs:
+            if is_function(d) and name in (qualified_name(d), d["name"]):
+                matches.append((rel, d))
+    if not matches:
+        raise LookupError(f"symbol {symbol!r} not found in {store.root}")
+
+    edges = store.edges()
+    summaries = []
+    for rel, d in matches[:_MAX_MATCHES]:
+        qual = qualified_name(d)
+        cached = (rel, qual, d["line"]) in store.summaries
+        [summary] = file_summaries(store, rel, [d], language)
+        summaries.append({
+            **summary,
+            "callees": {
+                "project": sorted({f"{e[2]}:{e[3]}" for e in edges if e[0] == rel and e[1] == qual}),
+                "external": summary["callees"],
+            },
+            "callers": sorted({f"{e[0]}:{e[1]}" for e in edges if e[2] == rel and e[3] == qual})[:_MAX_CALLERS],
+            "cached": cached,
+        })
+    return {"symbols": summaries, "truncated": len(matches) > _MAX_MATCHES}
+
+
+def project_summaries(store, language: str) -> dict[tuple[str, str], dict]:
+    """Summaries of every function in the store, keyed by (file, qualified name)."""
+    out: dict[tuple[str, str], dict] = {}
+    for rel, defs in sorted(definitions(store).items()):
+        funcs = [d for d in defs if is_function(d)]
+        if funcs:
+            for d, summary in zip(funcs, file_summaries(store, rel, funcs, language)):
+                out.setdefault((rel, qualified_name(d)), summary)
+    return out
+
+
+def is_function(d: dict) -> bool:
+    return d.get("kind") in ("func", "method", "function")
+
+
+def file_summaries(store, rel: str, defs: list[dict], language: str) -> list[dict]:
+    """Summaries of defs in rel, from the store's cache or one parse of the file."""
+    keys = [(rel, qualified_name(d), d["line"]) for d in defs]
+    missing = [(k, d) for k, d in zip(keys, defs) if k not in store.summaries]
+    if missing:
+        path = store.root / rel
+        source = path.read_text(encoding="utf-8", errors="replace")
+        if language == "python":
+            try:
+                tree = ast.parse(source)
+            except SyntaxError:
+                tree = ast.Module(body=[], type_ignores=[])
+            aliases = _import_aliases(tree)
+        else:
+            code = _blank(source, language)
+        for key, d in missing:
+            if language == "python":
+                detail = _summarize_python(tree, aliases, d["line"])
+            else:
+                detail = _summarize_braced(source, code, d["line"], language)
+            store.summaries[key] = {**_header(path, rel, d, language), **detail}
+    return [store.summaries[k] for k in keys]
+
+
+def definitions(store) -> dict[str, list[dict]]:
+    """Store definitions, or extractor symbols when tree-sitter is missing."""
+    if any(store.definitions.values()):
+        return store.definitions
+    extractor = DefaultExtractor()
+    out = {}
+    for rel in store.mtimes:
+        try:
+            info = extractor.extract(str(store.root / rel))
+        except Exception:
+            continue
+        defs = [{"name": f.name, "scope": "", "line": f.line_number, "kind": "func"} for f in info.functions]
+        for c in info.classes:
+            defs += [{"name": m.name, "scope": c.name, "line": m.line_number, "kind": "method"} for m in c.methods]
+        out[rel] = defs
+    return out
+
+
+def _header(path: Path, rel: str, d: dict, language: str) -> dict:
+    qual = qualified_name(d)
+    signature = read_signature(path, d["line"])
+    return {
+        "symbol": qual,
+        "id": make_symbol_id(package_path(rel, language), qual, signature),
+        "file": rel,
+        "line": d["line"],
+        "kind": d.get("kind", "func"),
+        "signature": signature,
+    }
+
+
+# --- Python ---------------------------------------------------------------
+
+
+def _summarize_python(tree: ast.Module, aliases: dict[str, str], line: int) -> dict:
+    node = next(
+        (n for n in ast.walk(tree) if isinstance(n, (ast.FunctionDef, ast.AsyncFunctionDef)) and n.lineno == line),
+        None,
+    )
+    if node is None:
+        return {"end_line": line, "params": [], "returns": {}, "raises": [], "callees": [], "effects": {}, "sources": {}, "sinks": {}}
+
+    params = []
+    args = node.args
+    positional = args.posonlyargs + args.args
+    defaults = [None] * (len(positional) - len(args.defaults)) + list(args.defaults)
+    for a, default in zip(positional, defaults):
+        params.append(_param(a, default))
+    if args.vararg:
+        params.append(_param(args.vararg, None, "*"))
+    for a, default in zip(args.kwonlyargs, args.kw_defaults):
+        params.append(_param(a, default))
+    if args.kwarg:
+        params.append(_param(args.kwarg, None, "**"))
+    if params and params[0]["name"] in ("self", "cls"):
+        params = params[1:]
+
+    calls, refs, raises = [], set(), set()
+    returns_value = yields = False
+    state = []
+    branches = 0
+    for n in ast.walk(node):
+        if isinstance(n, ast.Call):
+            name = _resolve(_dotted(n.func), aliases)
+            if name:
+                calls.append(name)
+        elif isinstance(n, ast.Attribute):
+            name = _resolve(_dotted(n), aliases)
+            if name:
+                refs.add(name)
+        elif isinstance(n, ast.Raise) and n.exc is not None:
+            exc = n.exc.func if isinstance(n.exc, ast.Call) else n.exc
+            raises.add(_dotted(exc) or ast.unparse(exc))
+        elif isinstance(n, ast.Return) and n.value is not None:
+            returns_value = True
+        elif isinstance(n, (ast.Yield, ast.YieldFrom)):
+            yields = True
+        elif isinstance(n, (ast.Global, ast.Nonlocal)):
+            state.extend(n.names)
+        if isinstance(n, (ast.If, ast.For, ast.AsyncFor, ast.While, ast.ExceptHandler, ast.IfExp, ast.comprehension, ast.match_case)):
+            branches += 1
+        elif isinstance(n, ast.BoolOp):
+            branches += len(n.values) - 1
+
+    returns = {"type": ast.unparse(node.returns) if node.returns else None, "value": returns_value}
+    if yields:
+        returns["generator"] = True
+    summary = {
+        "end_line": node.end_lineno,
+        "doc": _first_line(ast.get_docstring(node) or ""),
+        "async": isinstance(node, ast.AsyncFunctionDef),
+        "decorators": [ast.unparse(dec) for dec in node.decorator_list],
+        "params": params,
+        "returns": returns,
+        "raises": sorted(raises),
+        "callees": sorted(set(calls)),
+        "effects": effects(set(calls) | refs, "python"),
+        "sources": taint_sources(set(calls) | refs, "python"),
+        "sinks": taint_sinks(set(calls) | refs, "python"),
+        "complexity": branches + 1,
+    }
+    if state:
+        summary["globals"] = sorted(set(state))
+    return summary
+
+
+def _param(a: ast.arg, default, prefix: str = "") -> dict:
+    p = {"name": prefix + a.arg, "type": ast.unparse(a.annotation) if a.annotation else None}
+    if default is not None:
+        p["default"] = ast.unparse(default)
+    return p
+
+
+def _import_aliases(tree: ast.Module) -> dict[str, str]:
+    """Map local names bound by module-level imports to their full names."""
+    aliases = {}
+    for node in tree.body:
+        if isinstance(node, ast.Import):
+            for a in node.names:
+                if a.asname:
+                    aliases[a.asname] = a.name
+        elif isinstance(node, ast.ImportFrom) and node.module and not node.level:
+            for a in node.names:
+                aliases[a.asname or a.name] = f"{node.module}.{a.name}"
+    return aliases
+
+
+def _resolve(name: str, aliases: dict[str, str]) -> str:
+    head, dot, rest = name.partition(".")
+    if head in aliases:
+        return aliases[head] + dot + rest
+    return name
+
+
+def _dotted(node) -> str:
+    if isinstance(node, ast.Name):
+        return node.id
+    if isinstance(node, ast.Attribute):
+        inner = _dotted(node.value)
+        return f"{inner}.{node.attr}" if inner else ""
+    return ""
+
+
+def _first_line(text: str) -> str:
+    return text.strip().splitlines()[0] if text.strip() else ""
+
+
+# --- Brace languages --------------------------------------------------------
+
+
+def _summarize_braced(source: str, code: str, line: int, language: str) -> dict:
+    """Summarize the declaration at line; code is source after _blank."""
+    lines = source.splitlines(keepends=True)
+    start = sum(len(l) for l in lines[:line - 1])
+
+    open_paren = code.find("(", start)
+    if language == "go" and code[start:open_paren].strip() == "func":
+        # Method receiver: skip "(r *T)" to the parameter list.
+        open_paren = code.find("(", _close(code, open_paren) + 1)
+    body = code.find("{", start)
+    end = _close(code, body) if body >= 0 else -1
+    if open_paren < 0 or (0 <= body < open_paren):
+        params_text, header_rest = "", code[start:body if body >= 0 else len(code)]
+    else:
+        close_paren = _close(code, open_paren)
+        params_text = code[open_paren + 1:close_paren]
+        header_rest = code[close_paren + 1:body if body >= 0 else len(code)]
+    body_text = code[body + 1:end] if end > body >= 0 else ""
+
+    calls = sorted({m.group(1) for m in _CALL.finditer(body_text) if m.group(1) not in _KEYWORDS})
+    refs = {m.group(1) for m in _REF.finditer(body_text)}
+    raises = []
+    if language == "go" and re.search(r"\bpanic\s*\(", body_text):
+        raises.append("panic")
+    elif language in ("typescript", "javascript"):
+        raises = sorted(set(re.findall(r"\bthrow\s+new\s+(\w+)", body_text)))
+    elif language == "rust" and re.search(r"\b(?:panic!|unwrap\(\)|expect\()", body_text):
+        raises.append("panic")
+
+    end_line = source.count("\n", 0, end) + 1 if end >= 0 else line
+    return {
+        "end_line": end_line,
+        "doc": _leading_comment(lines, line),
+        "params": _params(params_text, language),
+        "returns": _returns(header_rest, language),
+        "raises": raises,
+        "callees": calls,
+        "effects": effects(set(calls) | refs, language),
+        "sources": taint_sources(set(calls) | refs, language),
+        "sinks": taint_sinks(set(calls) | refs, language),
+        "complexity": len(_BRANCH.findall(body_text)) + 1,
+    }
+
+
+def _blank(source: str, language: str) -> str:
+    """Replace comments and string contents with spaces, keeping offsets."""
+    out = list(source)
+    quotes = "\"`" if language in ("go", "rust") else "\"'`"
+    i, n = 0, len(source)
+    while i < n:
+        ch = source[i]
+        if source.startswith("//", i):
+            j = source.find("\n", i)
+            j = n if j < 0 else j
+        elif source.startswith("/*", i):
+            j = source.find("*/", i + 2)
+            j = n if j < 0 else j + 2
+        elif ch in quotes:
+            j = i + 1
+            while j < n and source[j] != ch:
+                if source[j] == "\\" and ch != "`":
+                    j += 1
+                elif source[j] == "\n" and ch != "`":
+                    break
+                j += 1
+            for k in range(i + 1, min(j, n)):
+                if out[k] != "\n":
+                    out[k] = " "
+            i = j + 1
+            continue
+        else:
+            i += 1
+            continue
+        for k in range(i, j):
+            if out[k] != "\n":
+                out[k] = " "
+        i = j
+    return "".join(out)
+
+
+def _close(code: str, open_at: int) -> int:
+    """Index of the bracket closing the one at open_at (len(code) if none)."""
+    pairs = {"(": ")", "{": "}", "[": "]"}
+    stack = []
+    for i in range(open_at, len(code)):
+        ch = code[i]
+        if ch in pairs:
+            stack.append(pairs[ch])
+        elif stack and ch == stack[-1]:
+            stack.pop()
+            if not stack:
+                return i
+    return len(code)
+
+
+def _split_top(text: str) -> list[str]:
+    parts, depth, cur = [], 0, []
+    for ch in text:
+        if ch in "([{<":
+            depth += 1
+        elif ch in ")]}>":
+            depth -= 1
+        if ch == "," and depth == 0:
+            parts.append("".join(cur).strip())
+            cur = []
+        else:
+            cur.append(ch)
+    if "".join(cur).strip():
+        parts.append("".join(cur).strip())
+    return [p for p in parts if p]
+
+
+def _params(text: str, language: str) -> list[dict]:
+    pieces = _split_top(" ".join(text.split()))
+    if language == "go":
+        parsed = []
+        for piece in pieces:
+            name, _, typ = piece.partition(" ")
+            parsed.append([name, typ.strip() or None])
+        if all(t is None for _, t in parsed):
+            # Unnamed parameters: every piece is a type.
+            return [{"name": "", "type": n} for n, _ in parsed]
+        for i in range(len(parsed) - 2, -1, -1):
+            if parsed[i][1] is None:
+                parsed[i][1] = parsed[i + 1][1]
+        return [{"name": n, "type": t} for n, t in parsed]
+
+    params = []
+    for piece in pieces:
+        if language == "rust" and re.fullmatch(r"&?(?:'\w+\s+)?(?:mut\s+)?self", piece):
+            continue
+        piece, _, default = piece.partition("=")
+        name, _, typ = piece.partition(":")
+        p = {"name": name.strip(), "type": typ.strip() or None}
+        if default.strip():
+            p["default"] = default.strip()
+        params.append(p)
+    return params
+
+
+def _returns(text: str, language: str) -> dict:
+    text = " ".join(text.split())
+    if language == "go":
+        text = text.strip()
+        if text.startswith("(") and text.endswith(")"):
+            types = [p.split(" ")[-1] if " " in p and not p.startswith(("[]", "*", "map[", "func")) else p for p in _split_top(text[1:-1])]
+        else:
+            types = [text] if text else []
+        return {"types": types, "error": "error" in types}
+    if language == "rust":
+        _, arrow, rest = text.partition("->")
+        rest = re.split(r"\bwhere\b", rest)[0].strip()
+        return {"type": rest or None} if arrow else {"type": None}
+    # TypeScript/JavaScript: "): Type {" or "): Type =>".
+    rest = text.strip()
+    if rest.startswith(":"):
+        return {"type": rest[1:].split("=>")[0].strip() or None}
+    return {"type": None}
+
+
+def _leading_comment(lines: list[str], line: int) -> str:
+    """First line of the comment block just above a declaration."""
+    block = []
+    i = line - 2
+    while i >= 0:
+        text = lines[i].strip()
+        if text.startswith("#["):  # Rust attribute
+            i -= 1
+            continue
+        if not text.startswith(("//", "/*", "*")):
+            break
+        block.append(text.strip("/*! "))
+        i -= 1
+    block = [b for b in reversed(block) if b]
+    return block[0] if block else ""
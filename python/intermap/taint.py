@@ -0,0 +1,108 @@
+"""Call paths from untrusted input sources to sensitive sinks.
+
+A lightweight flow check, not a SAST: a function is a source when its
+body reads untrusted input (HTTP request data, CLI arguments, environment
+variables) and a sink when it calls a sensitive API (process or code
+execution, SQL, file writes), per the tables in ``side_effects``. A flow
+is a shortest call path from a source function down to a sink function,
+including a function that is both. Data flow inside functions is not
+tracked, so a flow means "worth a look", not "exploitable".
+"""
+
+from __future__ import annotations
+
+from collections import deque
+
+from .graph_store import get_store
+from .side_effects import SINK_KINDS, SOURCE_KINDS
+from .symbol_summary import project_summaries
+
+
+def find_taint_paths(
+    project: str,
+    language: str,
+    sources: list[str] | None = None,
+    sinks: list[str] | None = None,
+    files: list[str] | None = None,
+    max_depth: int = 6,
+    max_results: int = 500,
+) -> dict:
+    """Find call paths from input-reading functions to sink-calling functions.
+
+    Args:
+        project: Project root
+        language: Project language
+        sources: Only these source kinds (http, cli, env; default all)
+        sinks: Only these sink kinds (exec, sql, file_write; default all)
+        files: Only flows passing through these project-relative files
+        max_depth: Maximum calls between source and sink
+        max_results: Maximum flows to return
+
+    Returns:
+        Dict with flows ({source, sink, path, length}, shortest first),
+        by_kind ("<source>-><sink>" counts), and truncated.
+    """
+    source_kinds = [k for k in SOURCE_KINDS if not sources or k in sources]
+    sink_kinds = [k for k in SINK_KINDS if not sinks or k in sinks]
+    store = get_store(project, language)
+    if not store.is_current():
+        store.sync()
+
+    nodes = project_summaries(store, language)
+    callees: dict[tuple[str, str], set[tuple[str, str]]] = {}
+    for src_file, src_func, dst_file, dst_func in store.edges():
+        src, dst = (src_file, src_func), (dst_file, dst_func)
+        if src in nodes and dst in nodes and src != dst:
+            callees.setdefault(src, set()).add(dst)
+
+    def picked(found: dict, kinds: list[str]) -> dict:
+        return {k: found[k] for k in kinds if k in found}
+
+    flows = []
+    for start in sorted(nodes):
+        read = picked(nodes[start].get("sources", {}), source_kinds)
+        if not read:
+            continue
+        # Breadth-first, so each sink function is reached by a shortest path.
+        parent = {start: None}
+        queue = deque([(start, 0)])
+        while queue:
+            key, depth = queue.popleft()
+            used = picked(nodes[key].get("sinks", {}), sink_kinds)
+            if used:
+                path = []
+                cur = key
+                while cur is not None:
+                    path.append(cur)
+                    cur = parent[cur]
+                path.reverse()
+                if not files or any(p[0] in files for p in path):
+                    flows.append({
+                        "source": _endpoint(start, nodes[start], read),
+                        "sink": _endpoint(key, nodes[key], used),
+                        "path": [f"{f}:{s}" for f, s in path],
+                        "length": len(path) - 1,
+                    })
+            if depth >= max_depth:
+                continue
+            for nxt in sorted(callees.get(key, ())):
+                if nxt not in parent:
+                    parent[nxt] = key
+                    queue.append((nxt, depth + 1))
+
+    flows.sort(key=lambda f: (f["length"], f["path"]))
+    by_kind: dict[str, int] = {}
+    for f in flows:
+        for src in f["source"]["kinds"]:
+            for snk in f["sink"]["kinds"]:
+                by_kind[f"{src}->{snk}"] = by_kind.get(f"{src}->{snk}", 0) + 1
+    return {
+        "flows": flows[:max_results],
+        "by_kind": dict(sorted(by_kind.items())),
+        "count": len(flows),
+        "truncated": len(flows) > max_results,
+    }
+
+
+def _endpoint(key: tuple[str, str], summary: dict, kinds: dict) -> dict:
+    return {"function": key[1], "file": key[0], "line": summary["line"], "id": summary["id"], "kinds": kinds}
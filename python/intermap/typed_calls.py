@@ -0,0 +1,191 @@
+"""Type-aware Python impact analysis via jedi.
+
+The heuristic call graph in cross_file_calls matches call names textually,
+so it misses attribute calls whose receiver is only known through
+inference: vcs.checkout_state() through a module alias, self.store.save()
+through an annotated attribute, a method on a value returned by another
+call. Here the callers of a function come from jedi's project-wide
+reference search, which follows imports, annotations, and assignments to
+the definition each name resolves to.
+
+jedi is optional; analyze_typed_impact reports an error when it is missing.
+"""
+
+import ast
+import logging
+from dataclasses import dataclass
+from pathlib import Path
+
+from .analysis import FunctionRef, _build_caller_tree
+from .workspace import iter_workspace_files
+
+logger = logging.getLogger(__name__)
+
+try:
+    import jedi
+    JEDI_AVAILABLE = True
+except ImportError:
+    JEDI_AVAILABLE = False
+
+
+@dataclass(frozen=True)
+class _Def:
+    """A function definition: its project-relative file, dotted qualified
+    name (Class.method, outer.inner), and the 1-based line, 0-based column,
+    and last line of its name and body."""
+
+    file: str
+    qualname: str
+    line: int
+    column: int
+    end_line: int
+
+    @property
+    def ref(self) -> FunctionRef:
+        return FunctionRef(file=self.file, name=self.qualname)
+
+
+class _Project:
+    """Lazily parsed function definitions and jedi state for one project."""
+
+    def __init__(self, root: Path):
+        self.root = root
+        self.jedi_project = jedi.Project(str(root))
+        self._defs: dict[str, list[_Def]] = {}
+
+    def definitions(self, rel: str) -> list[_Def]:
+        if rel not in self._defs:
+            self._defs[rel] = _parse_definitions(self.root, rel)
+        return self._defs[rel]
+
+    def find(self, name: str, target_file: str | None) -> list[_Def]:
+        """Definitions whose bare or qualified name is name."""
+        bare = name.rsplit(".", 1)[-1]
+        found = []
+        for path in iter_workspace_files(self.root, extensions={".py"}):
+            rel = path.relative_to(self.root).as_posix()
+            if target_file is not None and rel != target_file and not rel.endswith("/" + target_file):
+                continue
+            try:
+                if bare not in path.read_text(errors="replace"):
+                    continue
+            except OSError:
+                continue
+            found += [
+                d for d in self.definitions(rel)
+                if d.qualname == name or d.qualname.rsplit(".", 1)[-1] == name
+            ]
+        return found
+
+    def enclosing(self, rel: str, line: int) -> _Def | None:
+        """The innermost function whose body contains line."""
+        best = None
+        for d in self.definitions(rel):
+            if d.line < line <= d.end_line and (best is None or d.line > best.line):
+                best = d
+        return best
+
+    def callers(self, d: _Def) -> list[_Def]:
+        """Functions referring to d, in file and line order."""
+        try:
+            script = jedi.Script(path=str(self.root / d.file), project=self.jedi_project)
+            refs = script.get_references(d.line, d.column, scope="project")
+        except Exception as e:
+            logger.debug("typed_calls.references_failed", extra={"file": d.file, "error": str(e)})
+            return []
+        callers: dict[_Def, None] = {}
+        for ref in sorted(refs, key=lambda r: (str(r.module_path), r.line or 0)):
+            if ref.is_definition() or ref.module_path is None or ref.line is None:
+                continue
+            try:
+                rel = Path(ref.module_path).resolve().relative_to(self.root).as_posix()
+            except ValueError:
+                continue
+            caller = self.enclosing(rel, ref.line)
+            # Module-level references (imports, top-level calls) have no
+            # calling function.
+            if caller is not None and caller != d:
+                callers[caller] = None
+        return list(callers)
+
+
+def _parse_definitions(root: Path, rel: str) -> list[_Def]:
+    try:
+        source = (root / rel).read_text(errors="replace")
+        tree = ast.parse(source)
+    except (OSError, SyntaxError, ValueError):
+        return []
+    lines = source.splitlines()
+    defs: list[_Def] = []
+
+    def visit(node: ast.AST, scope: str) -> None:
+        for child in ast.iter_child_nodes(node):
+            if isinstance(child, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                qualname = f"{scope}.{child.name}" if scope else child.name
+                text = lines[child.lineno - 1] if child.lineno <= len(lines) else ""
+                column = text.find(child.name, child.col_offset)
+                if column >= 0:
+                    defs.append(_Def(rel, qualname, child.lineno, column, child.end_lineno or child.lineno))
+                visit(child, qualname)
+            elif isinstance(child, ast.ClassDef):
+                visit(child, f"{scope}.{child.name}" if scope else child.name)
+            else:
+                visit(child, scope)
+
+    visit(tree, "")
+    return defs
+
+
+def analyze_typed_impact(
+    path: str,
+    target_func: str,
+    max_depth: int = 3,
+    target_file: str | None = None,
+) -> dict:
+    """Find all callers of a function with jedi's type inference.
+
+    Returns the same shape as analysis.impact_analysis. Functions are named
+    by qualified name (Class.method) so same-named methods stay apart; each
+    node also carries it as "qualified", with "function" the bare name.
+    """
+    if not JEDI_AVAILABLE:
+        return {"error": "precision 'typed' requires jedi (pip install jedi)"}
+    if target_file is None and ":" in target_func:
+        target_file, target_func = target_func.split(":", 1)
+    if target_file is not None:
+        target_file = Path(target_file).as_posix()
+
+    project = _Project(Path(path).resolve())
+    targets = project.find(target_func, target_file)
+    if not targets:
+        return {"error": f"Function '{target_func}' not found in call graph"}
+
+    # Query callers one level past max_depth so the truncated leaves of the
+    # tree still report their caller_count.
+    reverse: dict[FunctionRef, list[FunctionRef]] = {}
+    frontier = list(targets)
+    seen = set(targets)
+    for _ in range(max_depth + 1):
+        next_frontier = []
+        for d in frontier:
+            callers = project.callers(d)
+            reverse[d.ref] = [c.ref for c in callers]
+            for c in callers:
+                if c not in seen:
+                    seen.add(c)
+                    next_frontier.append(c)
+        frontier = next_frontier
+
+    results = {}
+    for d in targets:
+        tree = _build_caller_tree(d.ref, reverse, max_depth, set())
+        _split_names(tree)
+        results[str(d.ref)] = tree
+    return {"targets": results, "total_targets": len(targets)}
+
+
+def _split_names(tree: dict) -> None:
+    tree["qualified"] = tree["function"]
+    tree["function"] = tree["function"].rsplit(".", 1)[-1]
+    for caller in tree["callers"]:
+        _split_names(caller)
@@ -0,0 +1,34 @@
+"""Per-request cost counters reported to the Go side with each response.
+
+The sidecar resets the counters before dispatching a request and returns
+them as the response's "usage" entry, which the Go server adds to the
+calling session's totals (see usage_stats).
+"""
+
+from __future__ import annotations
+
+import time
+
+_files_parsed = 0
+_cpu_start = 0.0
+
+
+def start() -> None:
+    """Reset the counters for a new request."""
+    global _files_parsed, _cpu_start
+    _files_parsed = 0
+    _cpu_start = time.process_time()
+
+
+def count_parse() -> None:
+    """Record that one source file was parsed."""
+    global _files_parsed
+    _files_parsed += 1
+
+
+def snapshot() -> dict:
+    """CPU seconds and files parsed since start()."""
+    return {
+        "cpu_seconds": round(time.process_time() - _cpu_start, 6),
+        "files_parsed": _files_parsed,
+    }
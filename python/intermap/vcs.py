@@ -0,0 +1,324 @@
+"""Version control abstraction for git, Jujutsu (jj), and Mercurial (hg).
+
+Mirrors the Go internal/vcs package: a directory's repository kind comes
+from its nearest .jj, .git, or .hg marker (jj first, so colocated jj repos
+use jj), and git's HEAD, HEAD^, and HEAD~N refs are translated for jj and hg
+so callers can keep their git-style defaults.
+"""
+
+import logging
+import os
+import re
+import subprocess
+
+logger = logging.getLogger(__name__)
+
+GIT = "git"
+JUJUTSU = "jj"
+MERCURIAL = "hg"
+
+_MARKERS = ((".jj", JUJUTSU), (".git", GIT), (".hg", MERCURIAL))
+_TIMEOUT = 10
+_HEAD_RE = re.compile(r"HEAD(?:(\^)|~(\d*))?")
+
+
+def no_fetch_env() -> dict[str, str]:
+    """Environment for VCS commands.
+
+    Keeps hg output stable regardless of user config, and stops git from
+    fetching missing objects of a partial clone over the network, so
+    reading an old revision fails fast instead of hanging offline.
+    """
+    return {**os.environ, "HGPLAIN": "1", "GIT_NO_LAZY_FETCH": "1"}
+
+
+def marker(path: str) -> str | None:
+    """Return the kind of repository rooted at path, or None."""
+    for name, vcs_kind in _MARKERS:
+        if os.path.exists(os.path.join(path, name)):
+            return vcs_kind
+    return None
+
+
+def detect(path: str) -> tuple[str, str] | None:
+    """Return (kind, root) of the nearest working copy containing path."""
+    current = os.path.abspath(path)
+    while True:
+        vcs_kind = marker(current)
+        if vcs_kind:
+            return vcs_kind, current
+        parent = os.path.dirname(current)
+        if parent == current:
+            return None
+        current = parent
+
+
+def kind(path: str) -> str | None:
+    """Return the repository kind containing path, or None."""
+    found = detect(path)
+    return found[0] if found else None
+
+
+def translate_ref(vcs_kind: str | None, ref: str) -> str:
+    """Translate git's HEAD forms to a jj or hg revision.
+
+    jj has no uncommitted state (edits amend the working-copy commit @), so
+    git's HEAD is jj's @-. Other refs are passed through unchanged.
+    """
+    match = _HEAD_RE.fullmatch(ref)
+    if vcs_kind == GIT or match is None:
+        return ref
+    if match.group(1):
+        n = 1
+    elif match.group(2) is not None:
+        n = int(match.group(2) or 1)
+    else:
+        n = 0
+    if vcs_kind == JUJUTSU:
+        return "@" + "-" * (n + 1)
+    if vcs_kind == MERCURIAL:
+        return "." if n == 0 else f".~{n}"
+    return ref
+
+
+def check_ref(ref: str) -> None:
+    """Reject a ref a VCS command would parse as an option.
+
+    Refs from callers (``git_base``, ``baseline``) go on command lines, so
+    ``--output=<file>`` would make git write anywhere. Every function here
+    that takes a ref checks it first; git commands also pass it after
+    ``--end-of-options``. Mirrors vcs.CheckRef in Go.
+    """
+    if not ref or ref.startswith("-"):
+        raise ValueError(f"invalid ref {ref!r}")
+
+
+def changed_files(path: str, base: str = "HEAD~1") -> list[str]:
+    """List files under path changed between base and the working copy.
+
+    Paths are relative to path, like ``git diff --name-only --relative``.
+    Raises ValueError for a base that looks like an option.
+    """
+    check_ref(base)
+    vcs_kind = kind(path)
+    rev = translate_ref(vcs_kind, base)
+    if vcs_kind == JUJUTSU:
+        args = ["jj", "diff", "--name-only", "--from", rev, "--to", "@", "."]
+    elif vcs_kind == MERCURIAL:
+        # The "." pattern limits status to path and prints paths relative to it.
+        args = ["hg", "status", "-mard", "-n", "--rev", rev, "."]
+    else:
+        args = ["git", "diff", "--name-only", "--relative", "--end-of-options", base, "--"]
+    out = _run(args, path)
+    if out is None:
+        return []
+    return [line.strip() for line in out.decode("utf-8", errors="replace").splitlines() if line.strip()]
+
+
+def recent_files(path: str, commits: int = 200) -> list[str]:
+    """List files under path touched by the last commits, most recent first.
+
+    Paths are relative to path; each appears once, at its latest change.
+    """
+    vcs_kind = kind(path)
+    if vcs_kind == JUJUTSU:
+        args = [
+            "jj", "log", "--no-graph", "-r", "::@", "-n", str(commits),
+            "-T", 'self.diff(".").files().map(|f| f.path().display()).join("\\n") ++ "\\n"',
+        ]
+    elif vcs_kind == MERCURIAL:
+        args = ["hg", "log", "-l", str(commits), "-T", "{join(files, '\\n')}\\n", "."]
+    elif vcs_kind == GIT:
+        args = ["git", "log", "-n", str(commits), "--name-only", "--format=", "--relative", "--", "."]
+    else:
+        return []
+    out = _run(args, path)
+    if out is None:
+        return []
+    # hg prints paths relative to the repository root.
+    prefix = ""
+    if vcs_kind == MERCURIAL:
+        found = detect(path)
+        rel = os.path.relpath(os.path.abspath(path), found[1]) if found else "."
+        prefix = "" if rel == "." else rel.replace(os.sep, "/") + "/"
+    seen: dict[str, None] = {}
+    for line in out.decode("utf-8", errors="replace").splitlines():
+        line = line.strip()
+        if not line or not line.startswith(prefix):
+            continue
+        line = line[len(prefix):]
+        if line not in seen:
+            seen[line] = None
+    return list(seen)
+
+
+def diff(path: str, baseline: str, unified: int = 0) -> str | None:
+    """Return a git-format patch from baseline to the working copy.
+
+    File names are relative to the repository root. Returns None if the
+    VCS command fails.
+    """
+    check_ref(baseline)
+    vcs_kind = kind(path)
+    rev = translate_ref(vcs_kind, baseline)
+    if vcs_kind == JUJUTSU:
+        args = ["jj", "diff", "--git", "--context", str(unified), "--from", rev, "--to", "@"]
+    elif vcs_kind == MERCURIAL:
+        args = ["hg", "diff", "--git", "-U", str(unified), "-r", rev]
+    else:
+        args = ["git", "diff", f"--unified={unified}", "--end-of-options", baseline, "--"]
+    out = _run(args, path)
+    if out is None:
+        return None
+    return out.decode("utf-8", errors="replace")
+
+
+def resolve(path: str, ref: str) -> str | None:
+    """Resolve ref to an immutable commit ID, or None."""
+    check_ref(ref)
+    vcs_kind = kind(path)
+    rev = translate_ref(vcs_kind, ref)
+    if vcs_kind == JUJUTSU:
+        args = ["jj", "log", "--no-graph", "-r", rev, "-T", "commit_id"]
+    elif vcs_kind == MERCURIAL:
+        args = ["hg", "log", "-r", rev, "-T", "{node}"]
+    else:
+        args = ["git", "rev-parse", f"{ref}^{{commit}}"]
+    out = _run(args, path, timeout=5)
+    if out is None:
+        return None
+    return out.decode("utf-8", errors="replace").strip() or None
+
+
+def show(path: str, rev: str, rel_path: str) -> bytes | None:
+    """Return rel_path (relative to the repository root) as of rev."""
+    check_ref(rev)
+    vcs_kind = kind(path)
+    rev = translate_ref(vcs_kind, rev)
+    if vcs_kind == JUJUTSU:
+        args = ["jj", "file", "show", "-r", rev, f'root:"{rel_path}"']
+    elif vcs_kind == MERCURIAL:
+        args = ["hg", "cat", "-r", rev, f"path:{rel_path}"]
+    else:
+        args = ["git", "show", f"{rev}:{rel_path}"]
+    return _run(args, path)
+
+
+def checkout_state(path: str) -> dict | None:
+    """Describe an incomplete working copy containing path.
+
+    Returns ``{"vcs", "sparse", "partial_clone"}`` for git sparse-checkouts
+    and partial clones (including jj repos colocated with them), hg sparse
+    checkouts, and hg narrow clones; None for complete working copies.
+    Mirrors vcs.CheckoutOf in Go.
+    """
+    found = detect(path)
+    if found is None:
+        return None
+    vcs_kind, root = found
+    sparse = partial = False
+    if vcs_kind == MERCURIAL:
+        sparse = _non_empty(os.path.join(root, ".hg", "sparse"))
+        partial = _non_empty(os.path.join(root, ".hg", "store", "narrowspec"))
+    elif os.path.exists(os.path.join(root, ".git")):
+        git_dir = _git_dir(root)
+        common = git_dir
+        try:
+            with open(os.path.join(git_dir, "commondir"), encoding="utf-8") as f:
+                common = os.path.join(git_dir, f.read().strip())
+        except OSError:
+            pass
+        cfg = _read_git_config(os.path.join(common, "config"))
+        cfg.update(_read_git_config(os.path.join(git_dir, "config.worktree")))
+        sparse = _is_true(cfg.get("core.sparsecheckout", "")) and _non_empty(
+            os.path.join(git_dir, "info", "sparse-checkout")
+        )
+        partial = bool(cfg.get("extensions.partialclone")) or any(
+            k.startswith("remote.") and k.endswith(".promisor") and _is_true(v)
+            for k, v in cfg.items()
+        )
+    if not sparse and not partial:
+        return None
+    return {"vcs": vcs_kind, "sparse": sparse, "partial_clone": partial}
+
+
+def _git_dir(root: str) -> str:
+    """Resolve the git directory, following a worktree's .git file."""
+    dot_git = os.path.join(root, ".git")
+    if os.path.isdir(dot_git):
+        return dot_git
+    try:
+        with open(dot_git, encoding="utf-8") as f:
+            content = f.read().strip()
+    except OSError:
+        return dot_git
+    if not content.startswith("gitdir: "):
+        return dot_git
+    return os.path.join(root, content[len("gitdir: "):])
+
+
+def _read_git_config(path: str) -> dict[str, str]:
+    """Parse git config into lowercased "section.subsection.key" names."""
+    cfg: dict[str, str] = {}
+    try:
+        with open(path, encoding="utf-8", errors="replace") as f:
+            lines = f.read().splitlines()
+    except OSError:
+        return cfg
+    section = ""
+    for raw in lines:
+        line = raw.strip()
+        if not line or line[0] in "#;":
+            continue
+        if line.startswith("["):
+            header = line[1:line.find("]")] if "]" in line else ""
+            name, _, sub = header.partition(" ")
+            section = name.lower()
+            sub = sub.strip().strip('"')
+            if sub:
+                section += "." + sub.lower()
+            continue
+        key, eq, value = line.partition("=")
+        cfg[f"{section}.{key.strip().lower()}"] = value.strip().strip('"') if eq else "true"
+    return cfg
+
+
+def _is_true(value: str) -> bool:
+    return value.lower() in ("true", "yes", "on", "1")
+
+
+def _non_empty(path: str) -> bool:
+    try:
+        return os.path.getsize(path) > 0
+    except OSError:
+        return False
+
+
+def _run(args: list[str], cwd: str, timeout: int = _TIMEOUT) -> bytes | None:
+    """Run a VCS command, returning stdout or None on failure."""
+    try:
+        result = subprocess.run(
+            args,
+            capture_output=True,
+            cwd=cwd,
+            timeout=timeout,
+            env=no_fetch_env(),
+        )
+    except (subprocess.TimeoutExpired, FileNotFoundError, NotADirectoryError) as e:
+        logger.debug(
+            "vcs.command_error",
+            extra={"command": args[:2], "cwd": cwd, "error_type": type(e).__name__},
+        )
+        return None
+    if result.returncode != 0:
+        logger.debug(
+            "vcs.command_failed",
+            extra={
+                "command": args[:2],
+                "cwd": cwd,
+                "returncode": result.returncode,
+                "stderr": (result.stderr or b"").decode("utf-8", errors="replace").strip()[:500],
+            },
+        )
+        return None
+    return result.stdout
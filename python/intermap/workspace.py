@@ -222,6 +222,8 @@ def iter_workspace_files(
 ) -> Iterator[Path]:
     """Iterate files in a workspace with .tldrsignore and workspace filtering.
 
+    Go files outside the active build context (see build_context) are skipped.
+
     Args:
         root: Project root directory
         extensions: Optional set of extensions to include (e.g., {".py"})
@@ -234,8 +236,10 @@ def iter_workspace_files(
     Yields:
         Absolute Path objects for matching files
     """
+    from .build_context import active
     from .ignore import load_ignore_patterns, should_ignore
 
+    build = active()
     root_path = Path(root).resolve()
     config = workspace_config
     if use_workspace_config and config is None:
@@ -279,4 +283,7 @@ def iter_workspace_files(
             if config and not should_include_path(str(rel_path), config):
                 continue
 
+            if build and file_path.suffix == ".go" and not build.matches(file_path):
+                continue
+
             yield file_path
@@ -0,0 +1,62 @@
+"""Tests for agent_map snapshot history."""
+
+from intermap.agent_history import agent_timeline, record_snapshot
+
+
+def _agent(agent_id, project, reservations=(), task=None):
+    return {
+        "agent_id": agent_id,
+        "name": agent_id.upper(),
+        "status": "active",
+        "project": project,
+        "project_path": f"/ws/{project}",
+        "current_task": task,
+        "reservations": list(reservations),
+    }
+
+
+def test_timeline_segments_and_events(tmp_path):
+    # retain_days=0 keeps these fixed past timestamps from being pruned.
+    db = str(tmp_path / "history.db")
+    record_snapshot(db, "/ws", [_agent("a1", "api", ["*.go"]), _agent("a2", "web")], retain_days=0, taken_at="2026-01-01T10:00:00Z")
+    record_snapshot(db, "/ws", [_agent("a1", "api", ["*.go"]), _agent("a2", "web")], retain_days=0, taken_at="2026-01-01T10:05:00Z")
+    record_snapshot(db, "/ws", [_agent("a1", "web", ["ui/**"], task="Fix nav")], retain_days=0, taken_at="2026-01-01T10:10:00Z")
+    record_snapshot(db, "/ws", [_agent("a1", "web", ["ui/**"], task="Fix nav"), _agent("a2", "web")], retain_days=0, taken_at="2026-01-01T10:15:00Z")
+    record_snapshot(db, "/other", [_agent("a9", "x")], retain_days=0, taken_at="2026-01-01T10:15:00Z")
+
+    result = agent_timeline(db, "/ws", since="2026-01-01T00:00:00Z", until="2026-01-02T00:00:00Z")
+    assert result["snapshots"] == 4
+    agents = {a["agent_id"]: a for a in result["agents"]}
+    assert set(agents) == {"a1", "a2"}
+
+    a1 = agents["a1"]["segments"]
+    assert [(s["project"], s["from"], s["to"], s["snapshots"]) for s in a1] == [
+        ("api", "2026-01-01T10:00:00Z", "2026-01-01T10:05:00Z", 2),
+        ("web", "2026-01-01T10:10:00Z", "2026-01-01T10:15:00Z", 2),
+    ]
+    # a2 is absent from the third snapshot, so its presence splits in two.
+    assert len(agents["a2"]["segments"]) == 2
+
+    kinds = [(e["agent_id"], e["kind"]) for e in result["events"]]
+    assert ("a1", "moved") in kinds and ("a1", "task") in kinds
+    assert ("a1", "reserved") in kinds and ("a1", "released") in kinds
+    assert kinds.count(("a2", "joined")) == 2 and ("a2", "left") in kinds
+
+
+def test_timeline_filters(tmp_path):
+    db = str(tmp_path / "history.db")
+    record_snapshot(db, "/ws", [_agent("a1", "api"), _agent("a2", "web")], retain_days=0, taken_at="2026-01-01T10:00:00Z")
+
+    result = agent_timeline(db, "/ws", since="2026-01-01T00:00:00Z", until="2026-01-02T00:00:00Z", project="web")
+    assert [a["agent_id"] for a in result["agents"]] == ["a2"]
+    result = agent_timeline(db, "/ws", since="2026-01-01T00:00:00Z", until="2026-01-02T00:00:00Z", agent="A1")
+    assert [a["agent_id"] for a in result["agents"]] == ["a1"]
+    result = agent_timeline(db, "/ws", since="2026-01-01T11:00:00Z", until="2026-01-02T00:00:00Z")
+    assert result["snapshots"] == 0 and result["agents"] == []
+
+
+def test_record_prunes_old_snapshots(tmp_path):
+    db = str(tmp_path / "history.db")
+    record_snapshot(db, "/ws", [_agent("a1", "api")], retain_days=0, taken_at="2000-01-01T00:00:00Z")
+    out = record_snapshot(db, "/ws", [_agent("a1", "api")], retain_days=30)
+    assert out["pruned"] == 1
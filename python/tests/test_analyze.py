@@ -1,6 +1,10 @@
 """Tests for intermap analyze dispatcher."""
 
 import os
+import shutil
+import subprocess
+
+import pytest
 
 from intermap.analyze import dispatch
 
@@ -50,3 +54,72 @@ def test_dispatch_call_graph():
     assert "edges" in result
     assert "edge_count" in result
     assert isinstance(result["edges"], list)
+
+
+def test_dispatch_reference_edges_file_edges(tmp_path):
+    # config holds only constants, so it has no definitions and only the
+    # import links it.
+    pkg = tmp_path / "app"
+    pkg.mkdir()
+    (pkg / "__init__.py").write_text("")
+    (pkg / "config.py").write_text("DEBUG = True\nTIMEOUT = 30\n")
+    (pkg / "util.py").write_text("def helper():\n    return 1\n")
+    (pkg / "main.py").write_text(
+        "from app import config\nfrom app.util import helper\n\n\ndef run():\n    return helper(), config.DEBUG\n"
+    )
+    result = dispatch("reference_edges", str(tmp_path), {"language": "python"})
+    assert os.path.join("app", "config.py") not in {d["file"] for d in result["definitions"]}
+    file_edges = {(e["src_file"], e["dst_file"]) for e in result["file_edges"]}
+    assert (os.path.join("app", "main.py"), os.path.join("app", "config.py")) in file_edges
+    assert (os.path.join("app", "main.py"), os.path.join("app", "util.py")) in file_edges
+
+
+def _sparse_repo(path):
+    """Fake a git sparse-checkout: only config and patterns are read."""
+    (path / ".git" / "info").mkdir(parents=True)
+    (path / ".git" / "config").write_text("[core]\n\tsparseCheckout = true\n")
+    (path / ".git" / "info" / "sparse-checkout").write_text("/src/\n")
+
+
+def test_dispatch_sparse_checkout_annotates(tmp_path):
+    _sparse_repo(tmp_path)
+    (tmp_path / "src").mkdir()
+    (tmp_path / "src" / "a.py").write_text("def a():\n    pass\n")
+
+    result = dispatch("structure", str(tmp_path), {"language": "python"})
+    assert result["checkout"] == {"vcs": "git", "sparse": True, "partial_clone": False}
+    assert "files" in result
+
+
+def test_dispatch_sparse_checkout_skips_missing(tmp_path):
+    _sparse_repo(tmp_path)
+    missing = str(tmp_path / "lib" / "outside.py")
+
+    result = dispatch("extract", str(tmp_path), {"file": missing})
+    assert result["skipped"] is True
+    assert result["missing_path"] == missing
+    assert result["checkout"]["sparse"] is True
+
+
+@pytest.mark.skipif(shutil.which("git") is None, reason="git not installed")
+def test_dispatch_change_impact_sparse_checkout(tmp_path):
+    def git(*args):
+        subprocess.run(
+            ["git", "-c", "user.email=t@t.com", "-c", "user.name=T", *args],
+            cwd=str(tmp_path), capture_output=True, check=True,
+        )
+
+    git("init", "-q")
+    for rel in ("src/a.py", "lib/b.py"):
+        (tmp_path / rel).parent.mkdir(exist_ok=True)
+        (tmp_path / rel).write_text("def f():\n    return 1\n")
+    git("add", ".")
+    git("commit", "-q", "-m", "one")
+    for rel in ("src/a.py", "lib/b.py"):
+        (tmp_path / rel).write_text("def f():\n    return 2\n")
+    git("commit", "-q", "-am", "two")
+    git("sparse-checkout", "set", "--no-cone", "/src/")
+
+    result = dispatch("change_impact", str(tmp_path), {"use_git": True})
+    assert result["skipped_missing"] == ["lib/b.py"]
+    assert result["checkout"]["sparse"] is True
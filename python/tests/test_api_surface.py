@@ -0,0 +1,102 @@
+"""Tests for public API extraction."""
+
+from intermap.api_surface import _public_symbols, api_surface
+
+
+def test_python_surface(tmp_path):
+    pkg = tmp_path / "lib"
+    pkg.mkdir()
+    (pkg / "__init__.py").write_text('from .core import run\n\n__all__ = ["run", "VERSION"]\nVERSION = "1.0"\nDEBUG = False\n')
+    (pkg / "core.py").write_text(
+        "LIMIT: int = 10\n\n\n"
+        "def run(a: int, *, b=2) -> int:\n    return a\n\n\n"
+        "def _helper():\n    pass\n\n\n"
+        "class Client(Base):\n"
+        "    def get(self, key):\n        pass\n\n"
+        "    def _raw(self):\n        pass\n"
+    )
+    (pkg / "_vendored.py").write_text("def hidden():\n    pass\n")
+    (tmp_path / "test_core.py").write_text("def test_run():\n    pass\n")
+
+    result = api_surface(str(tmp_path), "python")
+    got = {p["package"]: [(s["name"], s["kind"], s["signature"]) for s in p["symbols"]] for p in result["packages"]}
+    assert got == {
+        "lib": [("VERSION", "variable", "VERSION")],
+        "lib.core": [
+            ("Client", "class", "class Client(Base)"),
+            ("Client.get", "method", "def get(self, key)"),
+            ("LIMIT", "variable", "LIMIT: int"),
+            ("run", "function", "def run(a: int, *, b=2) -> int"),
+        ],
+    }
+    assert result["count"] == 5
+    assert result["packages"][1]["symbols"][3]["id"].startswith("lib.core#run@")
+    assert [p["package"] for p in api_surface(str(tmp_path), "python", packages=["lib"])["packages"]] == ["lib"]
+
+
+def test_go_surface(tmp_path):
+    (tmp_path / "go.mod").write_text("module example.com/s\n\ngo 1.22\n")
+    (tmp_path / "store").mkdir()
+    (tmp_path / "store" / "store.go").write_text(
+        "package store\n\n"
+        "type Store struct {\n\tName string // shown\n\tcache map[string]int\n\t*Base\n}\n\n"
+        "type Getter interface {\n\tGet(key string) (string, error)\n}\n\n"
+        "const (\n\tMaxSize = 10 // bytes\n\tminSize = 1\n)\n\n"
+        "var ErrMissing = errors.New(\"missing\")\n\n"
+        "func New(name string,\n\topts ...Option) (*Store, error) {\n\treturn nil, nil\n}\n\n"
+        "func (s *Store) Get(k string) string { return \"\" }\n\n"
+        "func (s *store) Hidden() {}\n\nfunc helper() {}\n"
+    )
+    (tmp_path / "internal").mkdir()
+    (tmp_path / "internal" / "x.go").write_text("package internal\n\nfunc Secret() {}\n")
+    (tmp_path / "main.go").write_text("package main\n\nfunc Run() {}\n")
+
+    result = api_surface(str(tmp_path), "go")
+    [pkg] = result["packages"]
+    assert pkg["package"] == "store"
+    got = {s["name"]: s["signature"] for s in pkg["symbols"]}
+    assert got == {
+        "ErrMissing": 'var ErrMissing = errors.New("missing")',
+        "Getter": "type Getter interface",
+        "MaxSize": "const MaxSize = 10",
+        "New": "func New(name string, opts ...Option) (*Store, error)",
+        "Store": "type Store struct",
+        "Store.Get": "func (s *Store) Get(k string) string",
+    }
+    members = {s["name"]: s.get("members") for s in pkg["symbols"]}
+    assert members["Store"] == ["Name string", "*Base"]
+    assert members["Getter"] == ["Get(key string) (string, error)"]
+
+    everything = api_surface(str(tmp_path), "go", include_internal=True)
+    assert [p["package"] for p in everything["packages"]] == ["", "internal", "store"]
+
+
+def test_ts_and_rust_symbols():
+    ts = (
+        "export function add(a: number, b: number): number {\n  return a + b;\n}\n"
+        "export const mul = (a: number, b: number): number => a * b;\n"
+        "export type Mode = 'a' | 'b';\n"
+        "export default class Client extends Base {\n}\n"
+        "export { helper, other as alias };\n"
+        "function priv() {}\n"
+    )
+    assert [(s["name"], s["kind"], " ".join(s["signature"].split())) for s in _public_symbols(ts, "typescript")] == [
+        ("add", "function", "export function add(a: number, b: number): number"),
+        ("mul", "function", "export const mul = (a: number, b: number): number =>"),
+        ("Mode", "type", "export type Mode = 'a' | 'b'"),
+        ("Client", "class", "export default class Client extends Base"),
+        ("helper", "export", "export { helper }"),
+        ("alias", "export", "export { other as alias }"),
+    ]
+    rust = (
+        "pub fn parse(s: &str) -> Result<Config, Error> {\n}\n"
+        "pub(crate) fn hidden() {}\n"
+        "pub struct Config {\n    pub name: String,\n}\n"
+        "impl Config {\n    pub fn load(p: &Path)\n        -> Result<Self> {\n    }\n}\n"
+        "impl Display for Config {\n    fn fmt(&self) {}\n}\n"
+    )
+    assert [(s["name"], s["kind"], " ".join(s["signature"].split())) for s in _public_symbols(rust, "rust")] == [
+        ("parse", "function", "pub fn parse(s: &str) -> Result<Config, Error>"),
+        ("Config", "struct", "pub struct Config"),
+        ("Config.load", "method", "pub fn load(p: &Path) -> Result<Self>"),
+    ]
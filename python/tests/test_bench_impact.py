@@ -0,0 +1,41 @@
+"""Tests for benchmark impact detection."""
+
+from intermap.bench_impact import analyze_bench_impact
+
+
+def test_go_benchmarks_by_name(tmp_path):
+    (tmp_path / "go.mod").write_text("module example.com/gb\n\ngo 1.22\n")
+    pkg = tmp_path / "pkg"
+    pkg.mkdir()
+    (pkg / "parse.go").write_text(
+        "package pkg\n\nfunc Parse(s string) int { return len(s) }\n\nfunc Other() {}\n"
+    )
+    (pkg / "parse_test.go").write_text(
+        "package pkg\n\nimport \"testing\"\n\n"
+        "func BenchmarkParse(b *testing.B) {\n\tfor i := 0; i < b.N; i++ {\n\t\tParse(\"x\")\n\t}\n}\n\n"
+        "func BenchmarkOther(b *testing.B) { Unrelated() }\n\n"
+        "func TestParse(t *testing.T) { Parse(\"y\") }\n"
+    )
+    result = analyze_bench_impact(str(tmp_path), files=["pkg/parse.go"], language="go")
+    assert [b["function"] for b in result["benchmarks"]] == ["BenchmarkParse"]
+    assert result["benchmarks"][0]["via"] == ["Parse"]
+    assert result["commands"] == [["go", "test", "./pkg", "-run", "^$", "-bench", "^(BenchmarkParse)$"]]
+
+
+def test_pytest_benchmarks_through_callers(tmp_path):
+    (tmp_path / "lib.py").write_text(
+        "def core(x):\n    return x\n\n\ndef wrapper(x):\n    return core(x)\n"
+    )
+    (tmp_path / "tests").mkdir()
+    (tmp_path / "tests" / "test_perf.py").write_text(
+        "from lib import wrapper\n\n\n"
+        "def test_wrapper_speed(benchmark):\n    benchmark(wrapper, 1)\n\n\n"
+        "def test_plain():\n    wrapper(1)\n\n\n"
+        "class TestPerf:\n    def test_other(self, benchmark):\n        benchmark(len, [])\n"
+    )
+    result = analyze_bench_impact(str(tmp_path), files=["lib.py"], language="python")
+    names = [b["function"] for b in result["benchmarks"]]
+    assert "test_wrapper_speed" in names
+    assert "test_plain" not in names
+    assert "TestPerf::test_other" not in names
+    assert result["command_lines"][0].startswith("pytest --benchmark-only tests/test_perf.py::test_wrapper_speed")
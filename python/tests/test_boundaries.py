@@ -0,0 +1,108 @@
+"""Tests for cross-language boundary detection."""
+
+import pytest
+
+from intermap.boundaries import scan_boundaries
+from intermap.cross_project import scan_cross_project_deps
+
+
+def _write(root, rel, content):
+    path = root / rel
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content)
+
+
+def _workspace(tmp_path):
+    # native builds a C library, a pybind11 module, and a gRPC service;
+    # api is a Go HTTP server; client calls into all of them.
+    _write(tmp_path, "native/.git/HEAD", "ref: refs/heads/main\n")
+    _write(tmp_path, "native/include/fast.h", "int fast(int);\n")
+    _write(tmp_path, "native/src/bindings.cpp", "PYBIND11_MODULE(fastmath, m) {}\n")
+    _write(tmp_path, "native/proto/rank.proto", 'syntax = "proto3";\nservice Ranker {\n  rpc Rank(Req) returns (Resp);\n}\n')
+    _write(tmp_path, "api/.git/HEAD", "ref: refs/heads/main\n")
+    _write(tmp_path, "api/go.mod", "module example.com/api\n")
+    _write(tmp_path, "api/cmd/apiserver/main.go", (
+        'package main\n\nimport "net/http"\n\nfunc main() {\n\thttp.ListenAndServe(":8081", nil)\n}\n'
+    ))
+    _write(tmp_path, "client/.git/HEAD", "ref: refs/heads/main\n")
+    _write(tmp_path, "client/go.mod", "module example.com/client\n")
+    _write(tmp_path, "client/wrap/wrap.go", (
+        "package wrap\n\n"
+        "// #cgo CFLAGS: -I${SRCDIR}/../../native/include\n"
+        "// #cgo LDFLAGS: -lfast\n"
+        '// #include "fast.h"\n'
+        'import "C"\n'
+    ))
+    _write(tmp_path, "client/sys/sys.go", '// #cgo LDFLAGS: -lz\nimport "C"\n')
+    _write(tmp_path, "client/run/run.go", (
+        'package run\n\nimport "os/exec"\n\n'
+        'func Start() {\n\texec.Command("apiserver", "--port", "8081").Run()\n'
+        '\texec.Command("ls").Run()\n}\n'
+    ))
+    _write(tmp_path, "client/rank/rank.go", (
+        "package rank\n\n"
+        "func Dial() {\n"
+        '\tconn, _ := grpc.NewClient("native:50051")\n'
+        "\t_ = pb.NewRankerClient(conn)\n}\n"
+    ))
+    _write(tmp_path, "client/app/main.py", (
+        "import json\n"
+        "from native_py import fastmath\n"
+        "import requests\n\n"
+        'requests.get("http://localhost:8081/healthz")\n'
+        'requests.get("https://api.internal/v1/items")\n'
+        'requests.get("https://example.com/")\n'
+    ))
+    _write(tmp_path, "client/app/ranker_pb2_grpc.py", "class RankerStub(object):\n    pass\n")
+    return tmp_path
+
+
+def test_scan_boundaries(tmp_path):
+    root = _workspace(tmp_path)
+    result = scan_boundaries(str(root))
+
+    assert result["services"] == {"Ranker": "native"}
+    assert result["ports"] == {"8081": "api"}
+    assert result["extensions"] == [
+        {"module": "fastmath", "binding": "pybind11", "file": "native/src/bindings.cpp", "project": "native"},
+    ]
+
+    found = {(b["file"], b["line"], b["kind"], b["target"], b["to_project"]) for b in result["boundaries"]}
+    assert found == {
+        ("client/app/main.py", 2, "python_extension", "fastmath", "native"),
+        ("client/app/main.py", 5, "http", "http://localhost:8081/healthz", "api"),
+        ("client/app/main.py", 6, "http", "https://api.internal/v1/items", "api"),
+        ("client/rank/rank.go", 4, "grpc", "native:50051", "native"),
+        ("client/rank/rank.go", 5, "grpc", "Ranker", "native"),
+        ("client/run/run.go", 6, "subprocess", "apiserver", "api"),
+        ("client/sys/sys.go", 2, "cgo", "-lz", ""),
+        ("client/wrap/wrap.go", 3, "cgo", "native/include", "native"),
+    }
+    assert result["by_kind"] == {"cgo": 2, "python_extension": 1, "subprocess": 1, "http": 2, "grpc": 2}
+
+
+def test_scan_boundaries_kinds_filter(tmp_path):
+    root = _workspace(tmp_path)
+    result = scan_boundaries(str(root), kinds=["cgo"])
+    assert {b["kind"] for b in result["boundaries"]} == {"cgo"}
+    assert result["by_kind"] == {"cgo": 2}
+
+    with pytest.raises(ValueError, match="unknown boundary kinds"):
+        scan_boundaries(str(root), kinds=["ffi"])
+
+
+def test_cross_project_deps_include_boundaries(tmp_path):
+    root = _workspace(tmp_path)
+    result = scan_cross_project_deps(str(root))
+
+    client = next(p for p in result["projects"] if p["project"] == "client")
+    deps = sorted((d["project"], d["type"]) for d in client["depends_on"])
+    assert deps == [
+        ("api", "http"),
+        ("api", "subprocess"),
+        ("native", "cgo"),
+        ("native", "grpc"),
+        ("native", "python_extension"),
+    ]
+    via = next(d["via"] for d in client["depends_on"] if d["type"] == "cgo")
+    assert via == "client/wrap/wrap.go:3 native/include"
@@ -0,0 +1,75 @@
+"""Tests for Go build context filtering."""
+
+from intermap.analyze import dispatch
+from intermap.build_context import BuildContext, active, from_args, using
+from intermap.workspace import iter_workspace_files
+
+LINUX = BuildContext("linux", "amd64")
+
+
+def test_file_name_rules():
+    assert LINUX.matches_name("open_linux.go")
+    assert LINUX.matches_name("open_linux_amd64_test.go")
+    assert LINUX.matches_name("open_unix.go")
+    assert LINUX.matches_name("linux.go")
+    assert not LINUX.matches_name("open_windows.go")
+    assert not LINUX.matches_name("open_linux_arm64.go")
+    assert not LINUX.matches_name("asm_arm64.go")
+    assert not LINUX.matches_name("open_darwin_test.go")
+    assert BuildContext("android", "arm64").matches_name("open_linux.go")
+
+
+def test_build_expressions():
+    assert LINUX.matches_header("//go:build linux && !cgo\n\npackage x\n")
+    assert not LINUX.matches_header("//go:build darwin || windows\n\npackage x\n")
+    assert LINUX.matches_header("//go:build unix && (amd64 || arm64)\n\npackage x\n")
+    assert not LINUX.matches_header("//go:build ignore\n\npackage x\n")
+    assert LINUX.matches_header("//go:build go1.21\n\npackage x\n")
+    assert not LINUX.matches_header("//go:build integration\n\npackage x\n")
+    assert BuildContext("linux", "amd64", ("integration",)).matches_header("//go:build integration\n\npackage x\n")
+    # A constraint after the package clause is just a comment.
+    assert LINUX.matches_header("package x\n\n//go:build windows\n")
+
+
+def test_legacy_plus_build():
+    assert LINUX.matches_header("// +build linux,amd64 darwin\n\npackage x\n")
+    assert not LINUX.matches_header("// +build !linux\n\npackage x\n")
+    assert not LINUX.matches_header("// +build linux\n// +build arm\n\npackage x\n")
+    # //go:build wins over +build lines.
+    assert LINUX.matches_header("//go:build linux\n// +build windows\n\npackage x\n")
+
+
+def _go_project(tmp_path):
+    (tmp_path / "go.mod").write_text("module example.com/p\n")
+    (tmp_path / "open.go").write_text("package p\n\nfunc Run() { open() }\n")
+    (tmp_path / "open_linux.go").write_text("package p\n\nfunc open() {}\n")
+    (tmp_path / "open_windows.go").write_text("package p\n\nfunc open() {}\n")
+    (tmp_path / "e2e.go").write_text("//go:build e2e\n\npackage p\n\nfunc e2e() {}\n")
+    return tmp_path
+
+
+def test_active_context_filters_enumeration(tmp_path):
+    _go_project(tmp_path)
+    every = {p.name for p in iter_workspace_files(tmp_path, {".go"})}
+    assert every == {"open.go", "open_linux.go", "open_windows.go", "e2e.go"}
+    with using(LINUX):
+        assert active() == LINUX
+        assert {p.name for p in iter_workspace_files(tmp_path, {".go"})} == {"open.go", "open_linux.go"}
+    assert active() is None
+
+
+def test_dispatch_reports_excluded(tmp_path):
+    _go_project(tmp_path)
+    go_build = {"goos": "windows", "goarch": "amd64", "tags": ["e2e"]}
+    result = dispatch("structure", str(tmp_path), {"language": "go", "go_build": go_build})
+    assert sorted(f["path"] for f in result["files"]) == ["e2e.go", "open.go", "open_windows.go"]
+    assert result["build_context"] == {
+        "goos": "windows", "goarch": "amd64", "tags": ["e2e"], "excluded": ["open_linux.go"],
+    }
+    assert "build_context" not in dispatch("structure", str(tmp_path), {"language": "go"})
+
+
+def test_from_args():
+    assert from_args(None) is None
+    assert from_args({"goos": "linux"}) is None
+    assert from_args({"goos": "linux", "goarch": "arm64", "tags": ["b", "a", "b"]}) == BuildContext("linux", "arm64", ("a", "b"))
@@ -0,0 +1,83 @@
+"""Tests for regex, literal, and structural code search."""
+
+import pytest
+
+from intermap.code_search import search_code
+
+
+def _project(tmp_path):
+    (tmp_path / "svc.py").write_text(
+        "import logging\n\n\n"
+        "class Store:\n"
+        "    def get(self, key):\n"
+        "        return self.cache.get(key, default(key))\n\n"
+        "    def put(self, key, value):\n"
+        "        self.cache.set(key, value)\n\n\n"
+        "def load(path):\n"
+        "    return open(path).read()\n"
+    )
+    (tmp_path / "main.go").write_text(
+        "package main\n\n"
+        "func run(a, b int) int {\n"
+        "\treturn add(a, b) + add(b, a)\n"
+        "}\n"
+    )
+    (tmp_path / "node_modules" / "dep").mkdir(parents=True)
+    (tmp_path / "node_modules" / "dep" / "index.js").write_text("cache.get(x)\n")
+    return tmp_path
+
+
+def test_regex_with_symbol_context(tmp_path):
+    result = search_code(str(_project(tmp_path)), r"cache\.\w+")
+    got = [(m["file"], m["line"], m["text"], m["symbol"]["name"], m["symbol"]["kind"]) for m in result["matches"]]
+    assert got == [
+        ("svc.py", 6, "cache.get", "Store.get", "method"),
+        ("svc.py", 9, "cache.set", "Store.put", "method"),
+    ]
+    first = result["matches"][0]
+    assert first["column"] == 21
+    assert first["project"] == tmp_path.name
+    assert first["symbol"]["id"].startswith("svc#Store.get@")
+    assert result["files_searched"] == 2
+
+
+def test_literal_and_filters(tmp_path):
+    _project(tmp_path)
+    result = search_code(str(tmp_path), "add(", mode="literal", language="go")
+    assert [(m["line"], m["symbol"]["name"]) for m in result["matches"]] == [(4, "run"), (4, "run")]
+    assert search_code(str(tmp_path), "add(", mode="literal", glob="*.py")["count"] == 0
+
+    truncated = search_code(str(tmp_path), "key", max_results=2)
+    assert truncated["count"] == 2 and truncated["truncated"]
+
+
+def test_structural_holes(tmp_path):
+    _project(tmp_path)
+    result = search_code(str(tmp_path), "self.cache.get(:[k], :[d])", mode="structural")
+    [m] = result["matches"]
+    assert m["holes"] == {"k": "key", "d": "default(key)"}
+    assert m["text"] == "self.cache.get(key, default(key))"
+
+    # A repeated hole must bind the same text both times.
+    swapped = search_code(str(tmp_path), "add(:[x], :[y]) + add(:[y], :[x])", mode="structural")
+    assert [m["holes"] for m in swapped["matches"]] == [{"x": "a", "y": "b"}]
+    assert search_code(str(tmp_path), "add(:[x], :[x])", mode="structural")["count"] == 0
+
+    defs = search_code(str(tmp_path), "def :[[name]](self, ...):", mode="structural")
+    assert [m["holes"]["name"] for m in defs["matches"]] == ["get", "put"]
+
+
+def test_structural_spans_lines(tmp_path):
+    (tmp_path / "a.go").write_text("func f() {\n\tcall(\n\t\tx,\n\t\t\"a)\",\n\t)\n}\n")
+    [m] = search_code(str(tmp_path), "call(:[args])", mode="structural")["matches"]
+    assert (m["line"], m["end_line"]) == (2, 5)
+    assert m["holes"]["args"] == 'x, "a)",'
+
+
+def test_invalid_patterns(tmp_path):
+    with pytest.raises(ValueError):
+        search_code(str(tmp_path), "(", mode="regex")
+    with pytest.raises(ValueError):
+        search_code(str(tmp_path), ":[x] + 1", mode="structural")
+    with pytest.raises(ValueError):
+        search_code(str(tmp_path), "x", mode="fuzzy")
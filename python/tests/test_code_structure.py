@@ -47,3 +47,57 @@ def test_code_structure_nonexistent_language():
     # Falls back to {".py"}, so it finds Python files
     assert result["language"] == "cobol"
     assert isinstance(result["files"], list)
+
+
+def _sample_project(tmp_path):
+    files = {
+        "app/__main__.py": "from app import core\n",
+        "app/core.py": "def run():\n    pass\n",
+        "app/util.py": "from app import core\n",
+        "app/extra.py": "from . import core, util\n",
+        "lib/a.py": "import app.util\n",
+        "lib/b.py": "def b():\n    pass\n",
+        "docs/gen.py": "def gen():\n    pass\n",
+    }
+    for rel, content in files.items():
+        path = tmp_path / rel
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(content)
+    return tmp_path
+
+
+def test_code_structure_sample(tmp_path):
+    root = _sample_project(tmp_path)
+    result = get_code_structure(str(root), language="python", max_results=4, sample=True)
+
+    assert result["sampled"] is True
+    entries = {f["path"]: f for f in result["files"]}
+    assert result["files"][0]["path"] == "app/__main__.py"
+    assert result["files"][0]["reason"] == "entry_point"
+    assert entries["app/core.py"]["reason"] == "fan_in"
+    assert entries["app/core.py"]["fan_in"] == 3
+    # The remaining slot goes to a directory not yet represented.
+    assert {f["reason"] for f in result["files"][3:]} == {"directory"}
+
+    cov = result["coverage"]
+    assert cov["total_files"] == 7
+    assert cov["sampled_files"] == 4
+    assert cov["directories"] == 3
+    assert cov["by_reason"]["entry_point"] == 1
+
+
+def test_code_structure_sample_covers_directories(tmp_path):
+    root = _sample_project(tmp_path)
+    result = get_code_structure(str(root), language="python", max_results=7, sample=True)
+
+    assert len(result["files"]) == 7
+    assert result["coverage"]["sampled_directories"] == 3
+    assert result["coverage"]["file_ratio"] == 1.0
+
+
+def test_code_structure_small_project_not_sampled(tmp_path):
+    root = _sample_project(tmp_path)
+    result = get_code_structure(str(root), language="python", max_results=10)
+
+    assert "sampled" not in result
+    assert all("reason" not in f for f in result["files"])
@@ -0,0 +1,92 @@
+"""Tests for cross-project codemod planning."""
+
+import pytest
+
+from intermap.codemod_plan import _order, _owner_match, codemod_plan, parse_pattern
+
+
+def _project(root, name, files):
+    proj = root / name
+    proj.mkdir(parents=True)
+    (proj / ".git").mkdir()
+    for rel, text in files.items():
+        path = proj / rel
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(text)
+    return proj
+
+
+def _workspace(tmp_path):
+    _project(tmp_path, "client", {
+        "go.mod": "module example.com/client\n",
+        "client.go": "package client\n\nfunc run(c *Client) {\n\tc.ListAgents(ctx, \"all\")\n}\n",
+    })
+    _project(tmp_path, "api", {
+        "go.mod": "module example.com/api\n\nreplace example.com/client => ../client\n",
+        "CODEOWNERS": "* @api-team\n/handlers/ @handlers\n",
+        "handlers/h.go": "package handlers\n\nfunc h() {\n\tclient.ListAgents(ctx, opts)\n\tclient.ListAgents(ctx)\n}\n",
+    })
+    _project(tmp_path, "web", {
+        "go.mod": "module example.com/web\n\nreplace example.com/api => ../api\n",
+        "main.go": "package main\n\nfunc main() { client.ListAgents(nil) }\n",
+    })
+    (tmp_path / "CODEOWNERS").write_text("web/ @web-team\n")
+    return tmp_path
+
+
+def test_parse_pattern():
+    assert parse_pattern("replace calls to client.ListAgents with client.Agents.List") == (
+        "client.ListAgents(:[args])", "client.Agents.List(:[args])",
+    )
+    assert parse_pattern("replace foo(:[x]) with bar(:[x])") == ("foo(:[x])", "bar(:[x])")
+    assert parse_pattern("foo(:[x])") == ("foo(:[x])", None)
+    assert parse_pattern("replace a with b", rewrite="c") == ("replace a with b", "c")
+
+
+def test_plan_orders_and_groups(tmp_path):
+    result = codemod_plan(str(_workspace(tmp_path)), "replace calls to client.ListAgents with client.Agents.List")
+    assert result["total_sites"] == 3
+    assert result["total_files"] == 2
+    assert [s["projects"] for s in result["steps"]] == [["api"], ["web"]]
+
+    api, web = result["projects"]
+    assert (api["project"], api["step"], api["depends_on"]) == ("api", 1, [])
+    assert (web["project"], web["step"], web["depends_on"]) == ("web", 2, ["api"])
+    assert [(s["file"], s["line"], s["replacement"]) for s in api["sites"]] == [
+        ("api/handlers/h.go", 4, "client.Agents.List(ctx, opts)"),
+        ("api/handlers/h.go", 5, "client.Agents.List(ctx)"),
+    ]
+    assert api["owners"] == ["@handlers"]
+    assert web["owners"] == ["@web-team"]
+    assert result["owners"] == [
+        {"owner": "@handlers", "projects": ["api"], "sites": 2},
+        {"owner": "@web-team", "projects": ["web"], "sites": 1},
+    ]
+
+
+def test_plan_filters_projects(tmp_path):
+    result = codemod_plan(str(_workspace(tmp_path)), "client.ListAgents(:[args])", projects=["web"])
+    assert [p["project"] for p in result["projects"]] == ["web"]
+    assert "replacement" not in result["projects"][0]["sites"][0]
+
+
+def test_rewrite_hole_must_exist(tmp_path):
+    with pytest.raises(ValueError, match="other"):
+        codemod_plan(str(tmp_path), "f(:[x])", rewrite="g(:[other])")
+
+
+def test_order_cycles():
+    deps = {"a": {"b"}, "b": {"a"}, "c": {"a", "b"}, "d": set()}
+    assert _order(set(deps), deps) == [
+        {"step": 1, "projects": ["d"], "cycle": False},
+        {"step": 2, "projects": ["a", "b"], "cycle": True},
+        {"step": 3, "projects": ["c"], "cycle": False},
+    ]
+
+
+def test_owner_match():
+    assert _owner_match("*.go", "pkg/x.go")
+    assert _owner_match("docs/", "docs/a/b.md")
+    assert _owner_match("/cmd/", "cmd/main.go")
+    assert not _owner_match("/cmd/", "internal/cmd/main.go")
+    assert _owner_match("cmd", "internal/cmd/main.go")
@@ -0,0 +1,91 @@
+"""Tests for reverse import lookup across workspace projects."""
+
+import pytest
+
+from intermap.consumers import find_consumers
+
+
+def _project(root, name, files):
+    proj = root / name
+    proj.mkdir(parents=True)
+    (proj / ".git").mkdir()
+    for rel, text in files.items():
+        path = proj / rel
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(text)
+    return proj
+
+
+def _workspace(tmp_path):
+    _project(tmp_path, "lib", {
+        "go.mod": "module example.com/lib\n",
+        "store/store.go": "package store\n",
+        "cache/cache.go": "package cache\n\nimport \"example.com/lib/store\"\n",
+    })
+    _project(tmp_path, "api", {
+        "go.mod": "module example.com/api\n\nrequire example.com/lib v0.0.0\n\nreplace example.com/lib => ../lib\n",
+        "main.go": (
+            "package main\n\n"
+            "import (\n\t\"fmt\"\n\n\tlibstore \"example.com/lib/store\"\n\t\"example.com/lib/cache\"\n)\n"
+        ),
+        "h/h.go": "package h\n\nimport \"example.com/lib/store\"\n",
+    })
+    _project(tmp_path, "tools", {
+        "go.mod": "module example.com/tools\n\nreplace example.com/lib => ../lib\n",
+        "t.go": "package tools\n\nimport \"fmt\"\n",
+    })
+    _project(tmp_path, "pylib", {
+        "pyproject.toml": '[project]\nname = "py-lib"\n',
+        "src/pylib/__init__.py": "",
+        "src/pylib/store.py": "",
+    })
+    _project(tmp_path, "web", {
+        "package.json": '{"name": "@acme/web"}',
+        "app.py": "import os\nfrom pylib import store\nimport pylib.store as s\n",
+    })
+    _project(tmp_path, "ui", {
+        "index.ts": "import { h } from '@acme/web/render';\nconst x = require(\"@acme/web\");\n",
+    })
+    return tmp_path
+
+
+def test_go_consumers(tmp_path):
+    result = find_consumers(str(_workspace(tmp_path)), "lib")
+    assert result["names"] == ["example.com/lib"]
+    got = {p["package"]: [(c["project"], c["file"], c["line"]) for c in p["consumers"]] for p in result["packages"]}
+    assert got == {
+        "example.com/lib/cache": [("api", "main.go", 7)],
+        "example.com/lib/store": [("api", "h/h.go", 3), ("api", "main.go", 6)],
+    }
+    assert result["projects"] == [
+        {"project": "api", "files": 2, "packages": ["example.com/lib/cache", "example.com/lib/store"]},
+    ]
+    assert result["total_files"] == 2
+    assert result["declared_only"] == ["tools"]
+
+
+def test_package_filter(tmp_path):
+    _workspace(tmp_path)
+    by_dir = find_consumers(str(tmp_path), "lib", package="cache")
+    assert [p["package"] for p in by_dir["packages"]] == ["example.com/lib/cache"]
+    by_path = find_consumers(str(tmp_path), "lib", package="example.com/lib/store")
+    assert by_path["total_files"] == 2
+
+    py = find_consumers(str(tmp_path), str(tmp_path / "pylib"), package="pylib.store")
+    assert py["names"] == ["py_lib", "pylib"]
+    [pkg] = py["packages"]
+    assert pkg["package"] == "pylib.store"
+    assert [(c["line"], c["import"]) for c in pkg["consumers"]] == [(2, "from pylib import store"), (3, "pylib.store")]
+
+
+def test_js_consumers(tmp_path):
+    _workspace(tmp_path)
+    result = find_consumers(str(tmp_path), "web")
+    assert [(p["package"], p["consumers"][0]["line"]) for p in result["packages"]] == [
+        ("@acme/web", 2), ("@acme/web/render", 1),
+    ]
+
+
+def test_unknown_project(tmp_path):
+    with pytest.raises(LookupError):
+        find_consumers(str(tmp_path), "missing")
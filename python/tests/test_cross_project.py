@@ -155,6 +155,34 @@ def test_skips_dirs_without_git(tmp_path):
     assert "no_git" not in names
 
 
+def test_nested_groups(tmp_path):
+    """Finds projects below nested groups and aggregates edges per group."""
+    def make(rel, gomod=None):
+        proj = tmp_path / rel
+        proj.mkdir(parents=True)
+        (proj / ".git").mkdir()
+        if gomod:
+            (proj / "go.mod").write_text(gomod)
+
+    make("platform/services/auth", "module auth\n\nreplace x/billing => ../billing\n")
+    make("platform/services/billing", "module billing\n\nreplace x/lib => ../../../libs/lib\n")
+    make("libs/lib")
+    make("platform/node_modules/skipped")
+
+    result = scan_cross_project_deps(str(tmp_path))
+    groups = {p["project"]: p["group"] for p in result["projects"]}
+    assert groups == {"auth": "platform/services", "billing": "platform/services", "lib": "libs"}
+
+    by_group = {g["group"]: g for g in result["groups"]}
+    assert set(by_group) == {"libs", "platform", "platform/services"}
+    assert by_group["platform/services"]["projects"] == 2
+    assert by_group["platform/services"]["internal_edges"] == 1
+    assert by_group["platform"]["depends_on"] == [{"group": "libs", "edges": 1}]
+
+    shallow = scan_cross_project_deps(str(tmp_path), max_depth=2)
+    assert [p["project"] for p in shallow["projects"]] == ["lib"]
+
+
 # --- Live monorepo test (runs only when Demarch root exists) ---
 
 
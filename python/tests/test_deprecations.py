@@ -0,0 +1,80 @@
+"""Tests for the deprecation tracker."""
+
+import pytest
+
+from intermap.deprecations import find_deprecations
+
+
+def _project(root, name, files):
+    proj = root / name
+    proj.mkdir(parents=True)
+    (proj / ".git").mkdir()
+    for rel, text in files.items():
+        path = proj / rel
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(text)
+    return proj
+
+
+def _workspace(tmp_path):
+    _project(tmp_path, "lib", {
+        "store/store.go": (
+            "package store\n\n"
+            "// OldGet reads a key.\n//\n// Deprecated: use Get.\n"
+            "func OldGet(k string) string { return Get(k) }\n\n"
+            "func Get(k string) string { return k }\n"
+        ),
+        "legacy.py": (
+            "import warnings\nfrom typing_extensions import deprecated\n\n\n"
+            "@deprecated(\"use load\")\ndef old_load():\n    return load()\n\n\n"
+            "def old_save():\n    warnings.warn(\"use save\", DeprecationWarning, stacklevel=2)\n\n\n"
+            "def load():\n    pass\n"
+        ),
+    })
+    _project(tmp_path, "app", {
+        "main.go": "package main\n\nfunc main() {\n\tstore.OldGet(\"a\")\n\t// store.OldGet in a comment\n\tstore.OldGet(\"b\")\n}\n",
+        "run.py": "from lib.legacy import old_load\n\nold_load()\n# old_save()\n",
+        "ui.ts": (
+            "/**\n * Render a card.\n * @deprecated use renderCard\n */\n"
+            "export function render(x: string) {}\n\nrender(\"a\");\n"
+        ),
+    })
+    return tmp_path
+
+
+def test_finds_deprecated_symbols(tmp_path):
+    result = find_deprecations(str(_workspace(tmp_path)))
+    got = {(s["name"], s["kind"], s["project"], s["file"], s["line"], s["message"]) for s in result["symbols"]}
+    assert got == {
+        ("OldGet", "function", "lib", "store/store.go", 6, "use Get."),
+        ("old_load", "function", "lib", "legacy.py", 6, "use load"),
+        ("old_save", "function", "lib", "legacy.py", 10, "use save"),
+        ("render", "function", "app", "ui.ts", 5, "use renderCard"),
+    }
+    assert result["total_symbols"] == 4
+
+
+def test_counts_remaining_uses(tmp_path):
+    result = find_deprecations(str(_workspace(tmp_path)))
+    by_name = {s["name"]: s for s in result["symbols"]}
+    assert by_name["OldGet"]["remaining"] == 2
+    assert by_name["OldGet"]["sites"] == [
+        {"project": "app", "file": "main.go", "line": 4},
+        {"project": "app", "file": "main.go", "line": 6},
+    ]
+    assert by_name["old_load"]["projects"] == {"app": 2}
+    assert by_name["old_save"]["remaining"] == 0
+    assert by_name["render"]["remaining"] == 1
+    assert result["symbols"][0]["name"] in {"OldGet", "old_load"}
+    assert result["total_remaining"] == 5
+
+    assert [(p["project"], p["remaining"]) for p in result["projects"]] == [("app", 5)]
+    assert result["projects"][0]["symbols"][0]["uses"] == 2
+
+
+def test_project_filter_and_site_limit(tmp_path):
+    result = find_deprecations(str(_workspace(tmp_path)), project="app", max_sites=0)
+    assert [s["name"] for s in result["symbols"]] == ["render"]
+    assert result["symbols"][0]["sites"] == []
+    with pytest.raises(LookupError):
+        find_deprecations(str(tmp_path), project="missing")
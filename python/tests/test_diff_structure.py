@@ -0,0 +1,104 @@
+"""Tests for diff-scoped structure outlines."""
+
+import subprocess
+
+import pytest
+
+from intermap.diff_structure import structure_of_diff
+
+
+def _git(path, *args):
+    subprocess.run(["git", *args], cwd=str(path), capture_output=True, check=True)
+
+
+@pytest.fixture
+def repo(tmp_path):
+    _git(tmp_path, "init")
+    _git(tmp_path, "config", "user.email", "test@test.com")
+    _git(tmp_path, "config", "user.name", "Test")
+    (tmp_path / "mod.py").write_text(
+        "def first():\n    return 1\n\n\nclass Box:\n    def put(self):\n        return 2\n\n    def take(self):\n        return 3\n"
+    )
+    (tmp_path / "main.go").write_text(
+        "package main\n\nfunc alpha() int {\n\treturn 1\n}\n\nfunc beta() int {\n\treturn 2\n}\n"
+    )
+    (tmp_path / "gone.py").write_text("def bye():\n    pass\n")
+    (tmp_path / "same.py").write_text("def untouched():\n    pass\n")
+    _git(tmp_path, "add", ".")
+    _git(tmp_path, "commit", "-m", "init")
+    return tmp_path
+
+
+def _by_path(result):
+    return {f["path"]: f for f in result["files"]}
+
+
+def test_working_tree_changes(repo):
+    (repo / "mod.py").write_text(
+        "def first():\n    return 1\n\n\nclass Box:\n    def put(self):\n        return 20\n\n    def take(self):\n        return 3\n"
+    )
+    (repo / "main.go").write_text(
+        "package main\n\nfunc alpha() int {\n\treturn 1\n}\n\nfunc beta() int {\n\treturn 22\n}\n"
+    )
+    (repo / "gone.py").unlink()
+
+    result = structure_of_diff(str(repo))
+    files = _by_path(result)
+    assert set(files) == {"mod.py", "main.go", "gone.py"}
+    assert result["head"] == "working tree"
+
+    mod = files["mod.py"]
+    assert mod["changed_symbols"] == ["Box", "Box.put"]
+    assert not mod["approximate"]
+    take = next(s for s in mod["symbols"] if s["name"] == "Box.take")
+    assert take["end"] == 10 and not take["changed"]
+
+    go = files["main.go"]
+    assert go["changed_symbols"] == ["beta"]
+    assert go["approximate"]
+
+    assert files["gone.py"]["status"] == "deleted"
+    assert files["gone.py"]["symbols"] == []
+    assert result["total_changed_symbols"] == 3
+
+
+def test_ref_range_reads_head_revision(repo):
+    (repo / "mod.py").write_text(
+        "def first():\n    return 1\n\n\ndef added():\n    return 4\n"
+    )
+    _git(repo, "commit", "-am", "rewrite")
+    (repo / "mod.py").write_text("uncommitted = True\n")
+
+    result = structure_of_diff(str(repo), base="HEAD~1", head="HEAD", files=["mod.py"])
+    mod = _by_path(result)["mod.py"]
+    assert [s["name"] for s in mod["symbols"]] == ["first", "added"]
+    assert mod["changed_symbols"] == ["added"]
+
+
+def test_pure_deletion_marks_enclosing_symbol(repo):
+    (repo / "same.py").write_text("def untouched():\n    a = 1\n    b = 2\n    return a\n")
+    _git(repo, "commit", "-am", "grow")
+    (repo / "same.py").write_text("def untouched():\n    a = 1\n    return a\n")
+    result = structure_of_diff(str(repo))
+    assert _by_path(result)["same.py"]["changed_symbols"] == ["untouched"]
+
+
+def test_max_files_truncates(repo):
+    (repo / "mod.py").write_text("x = 1\n")
+    (repo / "main.go").write_text("package main\n")
+    result = structure_of_diff(str(repo), max_files=1)
+    assert result["total_files"] == 1
+    assert result["truncated"]
+
+
+def test_bad_ref(repo):
+    with pytest.raises(ValueError, match="git diff"):
+        structure_of_diff(str(repo), base="no-such-ref")
+
+
+def test_option_like_refs_are_rejected(repo):
+    out = repo / "leak.txt"
+    for kwargs in ({"base": f"--output={out}"}, {"head": "-p"}, {"base": ""}):
+        with pytest.raises(ValueError, match="invalid git ref"):
+            structure_of_diff(str(repo), **kwargs)
+    assert not out.exists()
@@ -0,0 +1,47 @@
+"""Tests for documentation coverage."""
+
+from intermap.doc_coverage import analyze_doc_coverage
+
+
+def test_python_coverage_and_ranking(tmp_path):
+    (tmp_path / "lib.py").write_text(
+        '"""Module."""\n\n\n'
+        'def documented():\n    """Has a docstring."""\n\n\n'
+        "def popular():\n    return 1\n\n\n"
+        "def lonely():\n    return 2\n\n\n"
+        "def _private():\n    pass\n\n\n"
+        "class Thing:\n    def run(self):\n        pass\n\n    def _hidden(self):\n        pass\n"
+    )
+    (tmp_path / "app.py").write_text(
+        "from lib import popular\n\n\n"
+        "def main():\n    \"\"\"Entry.\"\"\"\n    popular()\n    popular()\n"
+    )
+    (tmp_path / "tests").mkdir()
+    (tmp_path / "tests" / "test_lib.py").write_text("def test_x():\n    pass\n")
+
+    result = analyze_doc_coverage(str(tmp_path), language="python")
+    assert result["total"] == 6
+    assert result["documented"] == 2
+    assert result["files"]["lib.py"] == {"total": 5, "documented": 1}
+    names = [s["name"] for s in result["undocumented"]]
+    assert set(names) == {"popular", "lonely", "Thing", "Thing.run"}
+    assert names[0] == "popular"
+    assert result["undocumented"][0]["call_sites"] == 2
+
+
+def test_go_doc_comments(tmp_path):
+    (tmp_path / "go.mod").write_text("module example.com/d\n\ngo 1.22\n")
+    (tmp_path / "d.go").write_text(
+        "package d\n\n"
+        "// Parse parses.\n//\n//go:noinline\nfunc Parse() {}\n\n"
+        "func Bare() {}\n\n"
+        "func private() {}\n\n"
+        "type Server struct{}\n\n"
+        "// Start starts.\nfunc (s *Server) Start() {}\n\n"
+        "func (s *Server) Stop() {}\n\n"
+        "type state int\n\n"
+        "func (s state) String() string { return \"\" }\n"
+    )
+    result = analyze_doc_coverage(str(tmp_path), language="go")
+    assert result["total"] == 5
+    assert sorted(s["name"] for s in result["undocumented"]) == ["Bare", "Server", "Server.Stop"]
@@ -0,0 +1,56 @@
+"""Tests for capability classification by call-graph reachability."""
+
+from intermap.effects_analysis import analyze_effects
+from intermap.graph_store import clear_stores
+
+
+def _project(tmp_path):
+    (tmp_path / "io_utils.py").write_text(
+        "import os\n"
+        "import requests\n\n\n"
+        "def fetch(url):\n"
+        "    return requests.get(url)\n\n\n"
+        "def save(path, data):\n"
+        "    with open(path, 'w') as f:\n"
+        "        f.write(data)\n\n\n"
+        "def token():\n"
+        "    return os.environ['TOKEN']\n"
+    )
+    (tmp_path / "service.py").write_text(
+        "from io_utils import fetch, save, token\n\n\n"
+        "def sync(url, path):\n"
+        "    save(path, fetch(url + token()))\n\n\n"
+        "def handler(req):\n"
+        "    return sync(req.url, req.path)\n\n\n"
+        "def add(a, b):\n"
+        "    return a + b\n"
+    )
+    return tmp_path
+
+
+def test_direct_and_transitive(tmp_path):
+    clear_stores()
+    result = analyze_effects(str(_project(tmp_path)), "python")
+    by_name = {f["symbol"]: f for f in result["functions"]}
+
+    assert by_name["fetch"]["direct"] == {"network": ["requests.get"]}
+    assert by_name["token"]["direct"] == {"env": ["os.environ"]}
+    handler = by_name["handler"]
+    assert handler["capabilities"] == ["filesystem", "network", "env"]
+    assert handler["direct"] == {}
+    assert handler["transitive"]["network"] == {"via": ["service.py:sync", "io_utils.py:fetch"], "apis": ["requests.get"]}
+    assert "add" not in by_name
+    assert result["by_capability"]["network"] == {"direct": 1, "transitive": 2}
+    assert result["analyzed"] == 6
+
+
+def test_filters(tmp_path):
+    clear_stores()
+    _project(tmp_path)
+    result = analyze_effects(str(tmp_path), "python", capabilities=["env"], files=["service.py"], include_pure=True)
+    assert [(f["symbol"], f["capabilities"]) for f in result["functions"]] == [
+        ("add", []),
+        ("handler", ["env"]),
+        ("sync", ["env"]),
+    ]
+    assert list(result["by_capability"]) == ["env"]
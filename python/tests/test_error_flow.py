@@ -0,0 +1,132 @@
+"""Tests for error site mapping."""
+
+from intermap.error_flow import _scan, error_flow
+from intermap.graph_store import clear_stores
+
+
+def _project(tmp_path):
+    (tmp_path / "store.py").write_text(
+        "import json\n"
+        "import logging\n"
+        "import sys\n\n\n"
+        "class NotFound(Exception):\n"
+        "    pass\n\n\n"
+        "def load(path):\n"
+        "    try:\n"
+        "        return json.loads(open(path).read())\n"
+        "    except ValueError as e:\n"
+        "        raise NotFound(path) from e\n\n\n"
+        "def find(path, key):\n"
+        "    data = load(path)\n"
+        "    if key not in data:\n"
+        "        raise KeyError(key)\n"
+        "    return data[key]\n\n\n"
+        "def quiet(path):\n"
+        "    try:\n"
+        "        return find(path, 'x')\n"
+        "    except Exception:\n"
+        "        pass\n\n\n"
+        "def logged(path):\n"
+        "    try:\n"
+        "        return find(path, 'y')\n"
+        "    except KeyError:\n"
+        "        logging.warning('missing')\n\n\n"
+        "def orphan():\n"
+        "    try:\n"
+        "        return 1\n"
+        "    except OSError:\n"
+        "        return None\n"
+    )
+    (tmp_path / "cli.py").write_text(
+        "import sys\n"
+        "from store import quiet, logged\n\n\n"
+        "def main():\n"
+        "    try:\n"
+        "        quiet(sys.argv[1])\n"
+        "        logged(sys.argv[1])\n"
+        "    except OSError:\n"
+        "        sys.exit(1)\n"
+    )
+    return tmp_path
+
+
+def test_python_sites(tmp_path):
+    clear_stores()
+    result = error_flow(str(_project(tmp_path)), "python", entry_points=["main"])
+    got = [(s["kind"], s["function"], s["detail"]) for s in result["sites"]]
+    assert got == [
+        ("panic", "main", "except OSError: sys.exit()"),
+        ("wrapped", "load", "raise NotFound from e"),
+        ("created", "find", "raise KeyError"),
+    ]
+    swallowed = [(s["function"], s["reachable"], s.get("path")) for s in result["swallowed"]]
+    assert swallowed == [
+        ("quiet", True, ["cli.py:main", "store.py:quiet"]),
+        ("orphan", False, None),
+    ]
+    assert result["by_kind"] == {"created": 1, "wrapped": 1, "swallowed": 2, "panic": 1}
+    assert result["entry_points"] == 1
+
+
+def test_filters(tmp_path):
+    clear_stores()
+    _project(tmp_path)
+    result = error_flow(str(tmp_path), "python", kinds=["swallowed"], files=["store.py"])
+    assert result["sites"] == []
+    assert [s["function"] for s in result["swallowed"]] == ["orphan", "quiet"]
+    assert result["by_kind"] == {"swallowed": 2}
+
+
+def test_go_sites():
+    source = (
+        "func Load(p string) (*Config, error) {\n"
+        "\tb, err := os.ReadFile(p)\n"
+        "\tif err != nil {\n"
+        "\t\treturn nil, fmt.Errorf(\"read %s: %w\", p, err)\n"
+        "\t}\n"
+        "\tif len(b) == 0 {\n"
+        "\t\treturn nil, errors.New(\"empty\")\n"
+        "\t}\n"
+        "\tif err := json.Unmarshal(b, &c); err != nil {\n"
+        "\t\treturn nil, nil\n"
+        "\t}\n"
+        "\tif err := validate(c); err != nil {\n"
+        "\t\tpanic(err)\n"
+        "\t}\n"
+        "\treturn c, nil\n"
+        "}\n"
+    )
+    assert sorted(_scan(source, "go")) == [
+        (4, "wrapped", 'fmt.Errorf("read %s: %w", p, err)'),
+        (7, "created", 'errors.New("empty")'),
+        (9, "swallowed", "if err != nil { return nil, nil }"),
+        (13, "panic", "panic(err)"),
+    ]
+
+
+def test_js_and_rust_sites():
+    js = (
+        "function load(p) {\n"
+        "  try {\n"
+        "    return JSON.parse(read(p));\n"
+        "  } catch (e) {\n"
+        "    return null;\n"
+        "  }\n"
+        "  fetch(p).catch(() => {});\n"
+        "  throw new Error('bad', { cause: err });\n"
+        "}\n"
+    )
+    assert [(line, kind) for line, kind, _ in sorted(_scan(js, "javascript"))] == [
+        (4, "swallowed"), (7, "swallowed"), (8, "wrapped"),
+    ]
+    rust = (
+        "fn load(p: &Path) -> Result<Config> {\n"
+        "    let _ = fs::remove_file(p);\n"
+        "    let text = fs::read_to_string(p).context(\"read\")?;\n"
+        "    let n: u32 = text.parse().unwrap();\n"
+        "    if n == 0 { return Err(anyhow!(\"zero\")); }\n"
+        "}\n"
+    )
+    assert [(line, kind) for line, kind, _ in sorted(_scan(rust, "rust"))] == [
+        (2, "swallowed"), (3, "wrapped"), (4, "panic"), (5, "created"),
+    ]
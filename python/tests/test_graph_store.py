@@ -0,0 +1,70 @@
+"""Tests for the incrementally maintained graph store."""
+
+import os
+import subprocess
+
+from intermap.graph_store import clear_stores, get_store, index_update
+
+
+def _init_project(path):
+    """Create a committed two-file Python project."""
+    subprocess.run(["git", "init"], cwd=str(path), capture_output=True, check=True)
+    subprocess.run(["git", "config", "user.email", "test@test.com"], cwd=str(path), capture_output=True, check=True)
+    subprocess.run(["git", "config", "user.name", "Test"], cwd=str(path), capture_output=True, check=True)
+    pkg = path / "pkg"
+    pkg.mkdir()
+    (pkg / "__init__.py").write_text("")
+    (pkg / "a.py").write_text("def helper(x):\n    return x\n")
+    (pkg / "b.py").write_text("from pkg.a import helper\n\ndef main():\n    return helper(1)\n")
+    subprocess.run(["git", "add", "."], cwd=str(path), capture_output=True, check=True)
+    subprocess.run(["git", "commit", "-m", "init"], cwd=str(path), capture_output=True, check=True)
+
+
+def _touch(path):
+    st = os.stat(path)
+    os.utime(path, ns=(st.st_atime_ns, st.st_mtime_ns + 1_000_000))
+
+
+def test_first_update_builds_full(tmp_path):
+    clear_stores()
+    _init_project(tmp_path)
+    result = index_update(str(tmp_path), "python")
+    assert result["mode"] == "full"
+    assert result["edge_count"] == 1
+
+
+def test_incremental_patch_from_git_diff(tmp_path):
+    clear_stores()
+    _init_project(tmp_path)
+    index_update(str(tmp_path), "python")
+
+    a = tmp_path / "pkg" / "a.py"
+    a.write_text("def helper(x):\n    return x\n\ndef other():\n    return helper(2)\n")
+    _touch(a)
+
+    result = index_update(str(tmp_path), "python")
+    assert result["mode"] == "incremental"
+    assert "pkg/a.py" in result["reparsed"]
+    assert "pkg/b.py" in result["reparsed"]  # caller of a changed file
+    edges = get_store(str(tmp_path), "python").edges()
+    assert ("pkg/a.py", "other", "pkg/a.py", "helper") in edges
+    assert ("pkg/b.py", "main", "pkg/a.py", "helper") in edges
+
+
+def test_drift_triggers_full_rebuild(tmp_path):
+    clear_stores()
+    _init_project(tmp_path)
+    index_update(str(tmp_path), "python")
+
+    (tmp_path / "pkg" / "c.py").write_text("x = 1\n")  # untracked: not in the diff
+    result = index_update(str(tmp_path), "python", files=[])
+    assert result["mode"] == "full"
+    assert result["drift"] == ["pkg/c.py"]
+
+
+def test_unchanged_mtime_hash_short_circuits(tmp_path):
+    clear_stores()
+    _init_project(tmp_path)
+    index_update(str(tmp_path), "python", mtime_hash="h1")
+    result = index_update(str(tmp_path), "python", mtime_hash="h1")
+    assert result["mode"] == "unchanged"
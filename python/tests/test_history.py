@@ -0,0 +1,66 @@
+"""Tests for test-results history ingestion."""
+
+import json
+
+from intermap.history import aggregate, load_history, order_tests
+
+
+def test_aggregate_flaky_and_duration():
+    records = [
+        {"run": "1", "file": "tests/test_a.py", "test": "t1", "duration": 1.0, "outcome": "passed"},
+        {"run": "1", "file": "tests/test_a.py", "test": "t2", "duration": 1.0, "outcome": "passed"},
+        {"run": "2", "file": "tests/test_a.py", "test": "t1", "duration": 3.0, "outcome": "failed"},
+        {"run": "2", "file": "tests/test_a.py", "test": "t2", "duration": 1.0, "outcome": "passed"},
+        {"run": "1", "file": "tests/test_b.py", "test": "t", "duration": 0.5, "outcome": "failed"},
+        {"run": "2", "file": "tests/test_b.py", "test": "t", "duration": 0.5, "outcome": "failed"},
+    ]
+    m = aggregate(records)
+    assert m["tests/test_a.py"]["avg_duration"] == 3.0
+    assert m["tests/test_a.py"]["failure_rate"] == 0.5
+    assert m["tests/test_a.py"]["flaky_tests"] == ["t1"]
+    # Always failing is broken, not flaky.
+    assert m["tests/test_b.py"]["flaky"] is False
+    assert m["tests/test_b.py"]["failure_rate"] == 1.0
+
+
+def test_order_tests():
+    m = {
+        "slow.py": {"flaky": False, "avg_duration": 9.0},
+        "fast.py": {"flaky": False, "avg_duration": 0.1},
+        "flaky.py": {"flaky": True, "avg_duration": 0.1},
+    }
+    assert order_tests(["flaky.py", "unknown.py", "slow.py", "fast.py"], m) == [
+        "fast.py", "slow.py", "unknown.py", "flaky.py",
+    ]
+
+
+def test_load_junit_dir(tmp_path):
+    project = tmp_path / "proj"
+    (project / "tests").mkdir(parents=True)
+    (project / "tests" / "test_a.py").write_text("def test_x(): pass\n")
+    (project / "pkg").mkdir()
+    (project / "pkg" / "a_test.go").write_text("package pkg\n\nfunc TestA(t *testing.T) {}\n")
+
+    runs = tmp_path / "runs"
+    runs.mkdir()
+    for i, fail in enumerate([False, True]):
+        failure = "<failure message='boom'/>" if fail else ""
+        (runs / f"run{i}.xml").write_text(
+            "<testsuites><testsuite>"
+            f"<testcase classname='tests.test_a' name='test_x' time='2.0'>{failure}</testcase>"
+            "<testcase classname='example.com/m/pkg' name='TestA/sub' time='0.5'/>"
+            "</testsuite></testsuites>"
+        )
+    m = load_history(str(runs), str(project))
+    assert m["tests/test_a.py"]["runs"] == 2
+    assert m["tests/test_a.py"]["flaky"] is True
+    assert m["pkg/a_test.go"]["avg_duration"] == 0.5
+
+
+def test_load_json_ledger(tmp_path):
+    ledger = tmp_path / "ledger.json"
+    ledger.write_text(json.dumps({"runs": [
+        {"run": "a", "file": "tests/test_a.py", "test": "t", "duration": 4, "outcome": "passed"},
+    ]}))
+    m = load_history(str(ledger), str(tmp_path))
+    assert m["tests/test_a.py"]["avg_duration"] == 4.0
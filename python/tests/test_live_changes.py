@@ -770,3 +770,35 @@ def test_symbol_annotation_body_only_edit_with_line_shift(tmp_path):
     assert "alpha" in symbol_names, f"Expected alpha in {symbol_names}"
     # beta should NOT be affected — it didn't change, just shifted
     assert "beta" not in symbol_names, f"beta should not be affected: {symbol_names}"
+
+
+def test_parse_git_patch_statuses():
+    """Git-format patches from jj and hg yield statuses from extended headers."""
+    from intermap.live_changes import _parse_git_patch
+
+    patch = (
+        "diff --git a/kept.py b/kept.py\n"
+        "--- a/kept.py\n"
+        "+++ b/kept.py\n"
+        "@@ -2,0 +3,2 @@\n"
+        "+x = 1\n"
+        "+y = 2\n"
+        "diff --git a/new.py b/new.py\n"
+        "new file mode 100644\n"
+        "--- /dev/null\n"
+        "+++ b/new.py\n"
+        "@@ -0,0 +1 @@\n"
+        "+z = 3\n"
+        "diff --git a/gone.py b/gone.py\n"
+        "deleted file mode 100644\n"
+        "diff --git a/old.py b/moved.py\n"
+        "rename from old.py\n"
+        "rename to moved.py\n"
+    )
+    changes = {c["file"]: c for c in _parse_git_patch(patch)}
+    assert changes["kept.py"]["status"] == "modified"
+    assert changes["kept.py"]["hunks"][0]["new_start"] == 3
+    assert changes["new.py"]["status"] == "added"
+    assert changes["gone.py"]["status"] == "deleted"
+    assert changes["moved.py"]["status"] == "renamed"
+    assert changes["moved.py"]["old_file"] == "old.py"
@@ -0,0 +1,48 @@
+"""Tests for the user-facing message inventory."""
+
+from intermap.messages import inventory_messages
+
+
+def test_python_messages(tmp_path):
+    (tmp_path / "cli.py").write_text(
+        "import argparse\nimport logging\n\nlogger = logging.getLogger(__name__)\n\n\n"
+        "def main(path):\n"
+        "    parser = argparse.ArgumentParser(description='Frobnicate files')\n"
+        "    parser.add_argument('--dry-run', help='Do not write')\n"
+        "    logger.info('loading %s', path)\n"
+        "    print(_('Welcome'))\n"
+        "    if not path:\n"
+        "        raise ValueError(f'missing path: {path!r}')\n"
+        "    logger.info('loading %s', path)\n"
+    )
+    (tmp_path / "test_cli.py").write_text("import logging\nlogging.info('ignored')\n")
+
+    result = inventory_messages(str(tmp_path), language="python")
+    got = {(m["kind"], m["text"]) for m in result["messages"]}
+    assert got == {
+        ("cli_help", "Frobnicate files"),
+        ("cli_help", "Do not write"),
+        ("log", "loading %s"),
+        ("i18n", "Welcome"),
+        ("error", "missing path: {path}"),
+    }
+    assert result["by_kind"]["log"] == 2
+    assert result["duplicates"] == [{"kind": "log", "text": "loading %s", "locations": ["cli.py:10", "cli.py:14"]}]
+
+    errors_only = inventory_messages(str(tmp_path), language="python", kinds=["error"])
+    assert [m["kind"] for m in errors_only["messages"]] == ["error"]
+
+
+def test_go_messages(tmp_path):
+    (tmp_path / "go.mod").write_text("module example.com/m\n\ngo 1.22\n")
+    (tmp_path / "main.go").write_text(
+        "package main\n\n"
+        "var dry = flag.Bool(\"dry-run\", false, \"Do not write\")\n\n"
+        "func run() error {\n"
+        "\tslog.Info(\"starting\", \"port\", 80)\n"
+        "\treturn fmt.Errorf(\"open %s: %w\", p, err)\n"
+        "}\n"
+    )
+    result = inventory_messages(str(tmp_path), language="go")
+    got = [(m["line"], m["kind"], m["text"]) for m in result["messages"]]
+    assert got == [(3, "cli_help", "Do not write"), (6, "log", "starting"), (7, "error", "open %s: %w")]
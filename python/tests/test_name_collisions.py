@@ -0,0 +1,73 @@
+"""Tests for cross-project name collisions."""
+
+import pytest
+
+from intermap.name_collisions import find_name_collisions
+
+
+def _write(root, rel, content):
+    path = root / rel
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content)
+
+
+def _project(root, name, module):
+    _write(root, f"{name}/.git/HEAD", "ref: refs/heads/main\n")
+    _write(root, f"{name}/go.mod", f"module {module}\n")
+
+
+def _workspace(tmp_path):
+    # app imports httpx and grpcx, which both export Client; store exports
+    # a Client too but nothing links it to the others.
+    _project(tmp_path, "httpx", "example.com/httpx")
+    _write(tmp_path, "httpx/client.go", "package httpx\n\ntype Client struct{}\n\nfunc New() *Client { return nil }\n")
+    _project(tmp_path, "grpcx", "example.com/grpcx")
+    _write(tmp_path, "grpcx/client.go", "package grpcx\n\ntype Client interface{}\n\nfunc New() Client { return nil }\n")
+    _project(tmp_path, "store", "example.com/store")
+    _write(tmp_path, "store/client.go", "package store\n\ntype Client struct{}\n\ntype Options struct{}\n")
+    _project(tmp_path, "app", "example.com/app")
+    _write(tmp_path, "app/lib/lib.go", (
+        "package lib\n\n"
+        'import (\n\t"example.com/grpcx"\n\t"example.com/httpx"\n)\n\n'
+        "type Options struct{}\n\n"
+        "var _ = httpx.New\nvar _ = grpcx.New\n"
+    ))
+    return tmp_path
+
+
+def test_neighborhood_collisions(tmp_path):
+    result = find_name_collisions(str(_workspace(tmp_path)))
+
+    assert result["projects"] == ["app", "grpcx", "httpx", "store"]
+    assert [(c["name"], c["kind"], c["relation"]) for c in result["collisions"]] == [
+        ("Client", "type", "shared_importer"),
+    ]
+    client = result["collisions"][0]
+    assert [p["project"] for p in client["projects"]] == ["grpcx", "httpx", "store"]
+    assert client["projects"][0]["definitions"][0]["kind"] == "interface"
+    assert client["pairs"] == [{"a": "grpcx", "b": "httpx", "relation": "shared_importer", "importers": ["app"]}]
+
+
+def test_scope_all_includes_unrelated(tmp_path):
+    result = find_name_collisions(str(_workspace(tmp_path)), scope="all")
+
+    relations = {c["name"]: c["relation"] for c in result["collisions"]}
+    assert relations == {"Client": "shared_importer", "Options": "unrelated"}
+    client = result["collisions"][0]
+    assert len(client["pairs"]) == 3
+
+
+def test_direct_import_and_kinds(tmp_path):
+    root = _workspace(tmp_path)
+    result = find_name_collisions(str(root), kinds=["function"], scope="all")
+
+    assert [(c["name"], c["relation"]) for c in result["collisions"]] == [("New", "shared_importer")]
+
+    _write(root, "httpx/wrap.go", 'package httpx\n\nimport "example.com/store"\n\nvar _ store.Client\n')
+    result = find_name_collisions(str(root), ignore=["Options"])
+    assert [(c["name"], c["relation"]) for c in result["collisions"]] == [("Client", "imports")]
+
+
+def test_rejects_unknown_kind(tmp_path):
+    with pytest.raises(ValueError):
+        find_name_collisions(str(tmp_path), kinds=["method"])
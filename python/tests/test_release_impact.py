@@ -0,0 +1,116 @@
+"""Tests for release impact on workspace consumers."""
+
+import subprocess
+from pathlib import Path
+
+import pytest
+
+from intermap.release_impact import _checkout, release_impact
+
+
+def _write(root, rel, content):
+    path = root / rel
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content)
+
+
+def _git(path, *args):
+    subprocess.run(["git", *args], cwd=str(path), capture_output=True, check=True)
+
+
+def _workspace(tmp_path):
+    lib = tmp_path / "lib"
+    lib.mkdir()
+    _git(lib, "init")
+    _git(lib, "config", "user.email", "test@test.com")
+    _git(lib, "config", "user.name", "Test")
+    _write(lib, "go.mod", "module example.com/lib\n\ngo 1.22\n")
+    _write(lib, "client/client.go", (
+        "package client\n\n"
+        "type Options struct {\n\tTimeout int\n}\n\n"
+        "func Dial(addr string) error { return nil }\n\n"
+        "func Close() {}\n"
+    ))
+    _write(lib, "util/util.go", "package util\n\nfunc Trim(s string) string { return s }\n")
+    _git(lib, "add", ".")
+    _git(lib, "commit", "-m", "v1")
+    _git(lib, "tag", "v1.0.0")
+
+    # Unreleased: Dial gains a parameter, Close is gone, Options grows.
+    _write(lib, "client/client.go", (
+        "package client\n\n"
+        "type Options struct {\n\tTimeout int\n\tRetries int\n}\n\n"
+        "func Dial(addr string, opts Options) error { return nil }\n\n"
+        "func Ping() {}\n"
+    ))
+
+    _write(tmp_path, "app/.git/HEAD", "ref: refs/heads/main\n")
+    _write(tmp_path, "app/go.mod", "module example.com/app\n\nrequire example.com/lib v1.0.0\n")
+    _write(tmp_path, "app/main.go", (
+        "package main\n\n"
+        'import "example.com/lib/client"\n\n'
+        "func main() {\n"
+        '\tclient.Dial("x")\n'
+        "\tdefer client.Close()\n"
+        "}\n"
+    ))
+    _write(tmp_path, "tools/.git/HEAD", "ref: refs/heads/main\n")
+    _write(tmp_path, "tools/go.mod", "module example.com/tools\n")
+    _write(tmp_path, "tools/fmt.go", 'package tools\n\nimport "example.com/lib/util"\n\nvar _ = util.Trim\n')
+    return tmp_path
+
+
+def test_release_impact(tmp_path):
+    root = _workspace(tmp_path)
+    result = release_impact(str(root), "lib", language="go")
+
+    assert result["since"] == "v1.0.0"
+    assert result["published_as"] == [{"ecosystem": "golang", "name": "example.com/lib"}]
+
+    api = result["api"]
+    assert api["breaking"]
+    assert [(e["package"], e["symbol"]) for e in api["removed"]] == [("client", "Close")]
+    assert [e["symbol"] for e in api["changed"]] == ["Dial"]
+    assert [e["symbol"] for e in api["extended"]] == ["Options"]
+    assert api["extended"][0]["added_members"] == ["Retries int"]
+    assert [e["symbol"] for e in api["added"]] == ["Ping"]
+
+    consumers = [c["project"] for c in result["consumers"]]
+    assert consumers == ["app", "tools"]
+    app = result["consumers"][0]
+    assert [(u["symbol"], u["line"]) for u in app["breaking_uses"]] == [("Dial", 6), ("Close", 7)]
+    assert result["consumers"][1]["breaking_uses"] == []
+
+
+def test_release_impact_without_tag(tmp_path):
+    root = _workspace(tmp_path)
+    _git(root / "lib", "tag", "-d", "v1.0.0")
+    result = release_impact(str(root), "lib", language="go")
+    assert result["since"] is None and result["api"] is None
+    assert [c["project"] for c in result["consumers"]] == ["app", "tools"]
+
+
+def test_release_impact_unknown_project(tmp_path):
+    with pytest.raises(LookupError):
+        release_impact(str(_workspace(tmp_path)), "nope", language="go")
+
+
+def test_checkout_project_below_repository_root(tmp_path):
+    repo = tmp_path / "mono"
+    repo.mkdir()
+    _git(repo, "init")
+    _git(repo, "config", "user.email", "test@test.com")
+    _git(repo, "config", "user.name", "Test")
+    _write(repo, "README", "monorepo\n")
+    _write(repo, "libs/lib/go.mod", "module example.com/lib\n\ngo 1.22\n")
+    _write(repo, "libs/lib/client/client.go", "package client\n\nfunc Dial() {}\n")
+    _git(repo, "add", ".")
+    _git(repo, "commit", "-m", "v1")
+    _write(repo, "libs/lib/client/client.go", "package client\n\nfunc Dial(addr string) {}\n")
+
+    dest = tmp_path / "base"
+    dest.mkdir()
+    base = _checkout(str(repo / "libs" / "lib"), "HEAD", str(dest))
+    assert (Path(base) / "go.mod").is_file()
+    assert (Path(base) / "client" / "client.go").read_text() == "package client\n\nfunc Dial() {}\n"
+    assert not (Path(base) / "README").exists()
@@ -0,0 +1,104 @@
+"""Tests for mechanically safe renames."""
+
+import pytest
+
+from intermap.rename import _mask, apply_rename, rename_check
+
+
+def _write(root, files):
+    for rel, text in files.items():
+        path = root / rel
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(text)
+
+
+def test_identifier_rename_skips_comments_and_strings(tmp_path):
+    _write(tmp_path, {
+        "a.py": "def _fetch(x):\n    # _fetch the thing\n    return '_fetch'\n",
+        "b.py": "from a import _fetch\n\nvalue = _fetch(1)  # _fetch\nprefetch = 2\n",
+    })
+    result = rename_check(str(tmp_path), "identifier", "_fetch", "_load", "python")
+    assert result["safe"]
+    assert [(e["file"], e["line"], e["column"]) for e in result["edits"]] == [
+        ("a.py", 1, 5), ("b.py", 1, 15), ("b.py", 3, 9),
+    ]
+    assert "-value = _fetch(1)  # _fetch\n+value = _load(1)  # _fetch\n" in result["diff"]
+    assert "applied" not in result
+    assert (tmp_path / "a.py").read_text().startswith("def _fetch")
+
+
+def test_conflicts_block_apply(tmp_path):
+    _write(tmp_path, {
+        "x.go": "package x\n\nfunc Fetch() {}\nfunc Load() {}\n",
+        "y.go": "package x\n\nfunc use() { Fetch() }\n",
+    })
+    result = apply_rename(str(tmp_path), "identifier", "Fetch", "Load", "go", apply=True)
+    assert not result["safe"] and not result["applied"]
+    assert result["conflicts"] == [
+        {"file": "", "line": 0, "reason": "Fetch is exported; uses in other modules are not checked"},
+        {"file": "x.go", "line": 4, "reason": "Load is already used here"},
+    ]
+    assert "Fetch" in (tmp_path / "y.go").read_text()
+
+    unexport = rename_check(str(tmp_path), "identifier", "Fetch", "fetchAll", "go")
+    assert "rename changes whether the Go name is exported" in [c["reason"] for c in unexport["conflicts"]]
+    assert not rename_check(str(tmp_path), "identifier", "Fetch", "func", "go")["safe"]
+
+
+def test_apply_writes_files(tmp_path):
+    _write(tmp_path, {"a.go": "package a\n\nfunc fetch() {}\n\nvar s = `fetch` + fetch()\n"})
+    result = apply_rename(str(tmp_path), "identifier", "fetch", "load", "go", apply=True)
+    assert result["applied"]
+    assert (tmp_path / "a.go").read_text() == "package a\n\nfunc load() {}\n\nvar s = `fetch` + load()\n"
+
+
+def test_text_only_renames_are_unsafe(tmp_path):
+    _write(tmp_path, {
+        "f.go": "package f\n\ntype file struct{}\n\nfunc (f *file) flush() {}\n\nfunc run(f *file, c conn) {\n\tf.flush()\n\tc.flush()\n}\n",
+        "g.go": "package f\n\nfunc helper() {}\n",
+        "h.go": "package f\n\nfunc use() { helper() }\n",
+        "a.ts": "function fetch() {}\nfetch();\n",
+        "m.py": "import json\nfrom os import sep\n\ndef run(_x):\n    return json.dumps(sep, _x=1)\n",
+    })
+    member = apply_rename(str(tmp_path), "identifier", "flush", "sync", "go", apply=True)
+    assert not member["safe"] and not member["applied"]
+    assert {c["line"] for c in member["conflicts"]} == {8, 9}
+    assert "f.flush()" in (tmp_path / "f.go").read_text()
+
+    partial = rename_check(str(tmp_path), "identifier", "helper", "assist", "go", files=["g.go"])
+    assert not partial["safe"]
+    assert partial["conflicts"][0]["file"] == "h.go"
+    assert rename_check(str(tmp_path), "identifier", "helper", "assist", "go")["safe"]
+
+    assert not rename_check(str(tmp_path), "identifier", "fetch", "load", "typescript")["safe"]
+    assert not rename_check(str(tmp_path), "identifier", "len", "size", "go")["safe"]
+    for name in ("json", "sep", "_x", "run", "print"):
+        assert not rename_check(str(tmp_path), "identifier", name, "_renamed", "python")["safe"], name
+
+
+def test_import_rename(tmp_path):
+    _write(tmp_path, {
+        "main.go": 'package main\n\nimport (\n\t"fmt"\n\tst "example.com/old/store"\n)\n\nvar s = "example.com/old"\n',
+        "one.go": 'package main\n\nimport "example.com/old"\n',
+        "app.py": "import oldpkg.sub as s\nfrom oldpkg import thing\nimport oldpkgx\nname = 'oldpkg'\n",
+        "web.js": "import a from 'old-lib/x';\nconst b = require(\"old-lib\");\nconst c = 'old-lib';\n",
+    })
+    go = apply_rename(str(tmp_path), "import", "example.com/old", "example.com/new", "go", apply=True)
+    assert go["files"] == ["main.go", "one.go"]
+    assert (tmp_path / "main.go").read_text().count("example.com/new") == 1
+    assert '"example.com/new"' in (tmp_path / "one.go").read_text()
+
+    py = rename_check(str(tmp_path), "import", "oldpkg", "newpkg", "python")
+    assert [(e["line"], e["column"]) for e in py["edits"]] == [(1, 8), (2, 6)]
+
+    js = rename_check(str(tmp_path), "import", "old-lib", "new-lib", "javascript")
+    assert [e["line"] for e in js["edits"]] == [1, 2]
+
+    with pytest.raises(ValueError):
+        rename_check(str(tmp_path), "import", "a", "b", "rust")
+
+
+def test_mask():
+    assert _mask('x = "a#b"  # c\ny', "python") == 'x = "   "     \ny'
+    assert _mask("a /* b */ 'c' // d\n", "go") == "a         ' '     \n"
+    assert _mask("fn f<'a>(x: &'a str) -> char { 'z' }", "rust") == "fn f<'a>(x: &'a str) -> char { ' ' }"
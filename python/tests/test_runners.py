@@ -0,0 +1,46 @@
+"""Tests for runner-specific test selection output."""
+
+import pytest
+
+from intermap.change_impact import is_test_file
+from intermap.runners import format_tests
+
+
+def test_pytest_node_ids(tmp_path):
+    (tmp_path / "tests").mkdir()
+    (tmp_path / "tests" / "test_a.py").write_text(
+        "def test_one():\n    pass\n\n"
+        "def helper():\n    pass\n\n"
+        "class TestThing:\n    def test_two(self):\n        pass\n"
+    )
+    sel = format_tests(str(tmp_path), ["tests/test_a.py"], "pytest")
+    assert sel["selectors"] == ["tests/test_a.py::test_one", "tests/test_a.py::TestThing::test_two"]
+    assert sel["commands"] == [["pytest", "tests/test_a.py"]]
+
+
+def test_gotest_groups_by_package(tmp_path):
+    (tmp_path / "go.mod").write_text("module example.com/m\n\ngo 1.22\n")
+    (tmp_path / "pkg").mkdir()
+    (tmp_path / "pkg" / "a_test.go").write_text(
+        "package pkg\n\nfunc TestB(t *testing.T) {}\nfunc TestA(t *testing.T) {}\nfunc helper() {}\n"
+    )
+    (tmp_path / "root_test.go").write_text("package m\n\nfunc ExampleRun() {}\n")
+    sel = format_tests(str(tmp_path), ["pkg/a_test.go", "root_test.go"], "gotest")
+    assert sel["commands"] == [
+        ["go", "test", ".", "-run", "^(ExampleRun)$"],
+        ["go", "test", "./pkg", "-run", "^(TestA|TestB)$"],
+    ]
+    assert "example.com/m/pkg.TestA" in sel["selectors"]
+    assert sel["command_lines"][1] == "go test ./pkg -run '^(TestA|TestB)$'"
+
+
+def test_jest_and_unknown_runner(tmp_path):
+    sel = format_tests(str(tmp_path), ["src/a.test.ts", "README.md"], "jest")
+    assert sel["commands"] == [["npx", "jest", "--runTestsByPath", "src/a.test.ts"]]
+    with pytest.raises(ValueError):
+        format_tests(str(tmp_path), [], "mocha")
+
+
+def test_go_test_files_detected():
+    assert is_test_file("pkg/a_test.go")
+    assert not is_test_file("pkg/a.go")
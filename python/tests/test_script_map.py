@@ -0,0 +1,78 @@
+"""Tests for shell script and Makefile invocation edges."""
+
+from intermap.cross_project import scan_cross_project_deps
+from intermap.script_map import scan_scripts
+
+
+def _write(root, rel, content, mode=None):
+    path = root / rel
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content)
+    if mode:
+        path.chmod(mode)
+
+
+def _workspace(tmp_path):
+    # api builds a Go binary; worker declares a console script; ops is glue.
+    _write(tmp_path, "api/.git/HEAD", "ref: refs/heads/main\n")
+    _write(tmp_path, "api/go.mod", "module example.com/api\n")
+    _write(tmp_path, "api/cmd/apiserver/main.go", "package main\n")
+    _write(tmp_path, "api/scripts/migrate.sh", "#!/bin/sh\necho migrating\n")
+    _write(tmp_path, "api/Makefile", "build:\n\tgo build ./...\n")
+    _write(tmp_path, "worker/.git/HEAD", "ref: refs/heads/main\n")
+    _write(tmp_path, "worker/pyproject.toml", '[project]\nname = "worker"\n\n[project.scripts]\nrun-worker = "worker.main:run"\n')
+    _write(tmp_path, "ops/.git/HEAD", "ref: refs/heads/main\n")
+    _write(tmp_path, "ops/deploy", (
+        "#!/usr/bin/env bash\n"
+        "set -e\n"
+        "# ../api/scripts/migrate.sh is run below, not here\n"
+        'DIR="$(cd "$(dirname "$0")" && pwd)"\n'
+        '"$(dirname "$0")/lib/common.sh" --check\n'
+        "source ${DIR}/lib/common.sh\n"
+        "FOO=1 apiserver --port 80 && run-worker \\\n"
+        "    --queue default\n"
+        "bash ../api/scripts/migrate.sh\n"
+        "echo done | tee log.txt\n"
+    ))
+    _write(tmp_path, "ops/lib/common.sh", "log() { echo \"$@\"; }\n")
+    _write(tmp_path, "ops/Makefile", (
+        "deploy: build\n"
+        "\t@./deploy\n"
+        "build:\n"
+        "\t$(MAKE) -C ../api build\n"
+        "\t-go run ../api/cmd/apiserver --version\n"
+    ))
+    return tmp_path
+
+
+def test_scan_scripts_edges(tmp_path):
+    root = _workspace(tmp_path)
+    result = scan_scripts(str(root))
+
+    assert result["binaries"] == {"apiserver": "api", "run-worker": "worker"}
+    kinds = {f["file"]: (f["kind"], f["project"]) for f in result["files"]}
+    assert kinds["ops/deploy"] == ("shell", "ops")
+    assert kinds["ops/Makefile"] == ("make", "ops")
+
+    edges = {(e["from"], e["line"], e["kind"], e["to"], e["to_project"]) for e in result["edges"]}
+    assert edges == {
+        ("ops/deploy", 5, "script", "ops/lib/common.sh", "ops"),
+        ("ops/deploy", 6, "script", "ops/lib/common.sh", "ops"),
+        ("ops/deploy", 7, "binary", "apiserver", "api"),
+        ("ops/deploy", 7, "binary", "run-worker", "worker"),
+        ("ops/deploy", 9, "script", "api/scripts/migrate.sh", "api"),
+        ("ops/Makefile", 2, "script", "ops/deploy", "ops"),
+        ("ops/Makefile", 4, "make", "api/Makefile", "api"),
+        ("ops/Makefile", 5, "binary", "apiserver", "api"),
+    }
+
+
+def test_cross_project_deps_include_scripts(tmp_path):
+    root = _workspace(tmp_path)
+    result = scan_cross_project_deps(str(root))
+
+    ops = next(p for p in result["projects"] if p["project"] == "ops")
+    script_deps = sorted(d["project"] for d in ops["depends_on"] if d["type"] == "script")
+    assert script_deps == ["api", "worker"]
+    via = next(d["via"] for d in ops["depends_on"] if d["project"] == "worker")
+    assert via == "ops/deploy:7 binary run-worker"
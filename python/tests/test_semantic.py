@@ -0,0 +1,96 @@
+"""Tests for semantic search over the embedding index."""
+
+import re
+import zlib
+
+import pytest
+
+from intermap.semantic import semantic_search
+
+
+class FakeEmbedder:
+    """Bag-of-words hashing embedder that records what it embeds."""
+
+    def __init__(self):
+        self.calls = []
+
+    def __call__(self, texts):
+        self.calls.append(texts)
+        out = []
+        for text in texts:
+            vec = [0.0] * 64
+            for word in re.findall(r"[a-z]+", text.lower()):
+                vec[zlib.crc32(word.encode()) % 64] += 1.0
+            out.append(vec)
+        return out
+
+
+def _project(tmp_path):
+    proj = tmp_path / "proj"
+    proj.mkdir()
+    (proj / "reserve.py").write_text(
+        "def validate_reservation_pattern(pattern):\n"
+        "    if not pattern or pattern.startswith('/'):\n"
+        "        raise ValueError('reservation pattern must be relative')\n"
+        "    return pattern\n\n\n"
+        "def send_email(to, body):\n"
+        "    smtp.send(to, body)\n"
+    )
+    (proj / "util.go").write_text("package util\n\nfunc Retry(n int) {\n\tfor i := 0; i < n; i++ {\n\t}\n}\n")
+    return proj
+
+
+def test_ranks_and_reindexes_incrementally(tmp_path):
+    proj = _project(tmp_path)
+    db = str(tmp_path / "vec.db")
+    embed = FakeEmbedder()
+
+    result = semantic_search(db, str(proj), "where do we validate reservation patterns", embed=embed)
+    top = result["results"][0]
+    assert (top["file"], top["symbol"], top["kind"], top["line"]) == ("reserve.py", "validate_reservation_pattern", "function", 1)
+    assert top["symbol_id"].startswith("reserve#validate_reservation_pattern@")
+    assert top["preview"].startswith("def validate_reservation_pattern")
+    assert result["index"] == {"files": 2, "chunks": 3, "embedded": 3, "pending": 0}
+
+    # Unchanged files embed nothing but the query.
+    embed.calls.clear()
+    again = semantic_search(db, str(proj), "retry loop", embed=embed)
+    assert embed.calls == [["retry loop"]]
+    assert again["results"][0]["symbol"] == "Retry"
+
+    # An edited file re-embeds only the chunk whose text changed.
+    (proj / "reserve.py").write_text((proj / "reserve.py").read_text().replace("smtp.send", "mailer.send"))
+    embed.calls.clear()
+    semantic_search(db, str(proj), "email", embed=embed)
+    assert len(embed.calls[0]) == 1 and "send_email" in embed.calls[0][0]
+
+    (proj / "util.go").unlink()
+    assert semantic_search(db, str(proj), "retry", embed=embed)["index"]["files"] == 1
+
+
+def test_max_embed_leaves_pending(tmp_path):
+    proj = _project(tmp_path)
+    db = str(tmp_path / "vec.db")
+    partial = semantic_search(db, str(proj), "email", max_embed=1, embed=FakeEmbedder())
+    assert partial["index"]["embedded"] == 1 and partial["index"]["pending"] == 2
+    assert len(partial["results"]) == 1
+
+    rest = semantic_search(db, str(proj), "email", embed=FakeEmbedder())
+    assert rest["index"]["embedded"] == 2 and rest["index"]["pending"] == 0
+
+
+def test_scope_and_validation(tmp_path):
+    proj = _project(tmp_path)
+    other = tmp_path / "other"
+    other.mkdir()
+    (other / "a.py").write_text("def validate_reservation_pattern():\n    pass\n")
+    db = str(tmp_path / "vec.db")
+
+    semantic_search(db, str(tmp_path), "reservation", embed=FakeEmbedder())
+    scoped = semantic_search(db, str(other), "reservation", embed=FakeEmbedder())
+    assert [r["file"] for r in scoped["results"]] == ["a.py"]
+
+    with pytest.raises(ValueError):
+        semantic_search(db, str(proj), "  ", embed=FakeEmbedder())
+    with pytest.raises(ValueError):
+        semantic_search(db, str(proj), "x", provider="openai", endpoint="")
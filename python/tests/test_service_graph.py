@@ -0,0 +1,68 @@
+"""Tests for the runtime service graph."""
+
+from intermap.service_graph import build_service_graph
+
+
+def _write(root, rel, content):
+    path = root / rel
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(content)
+
+
+def _workspace(tmp_path):
+    # intermute serves HTTP; ranker serves gRPC from a container; intermap
+    # reaches both, and a shared library is not deployable at all.
+    _write(tmp_path, "intermute/.git/HEAD", "ref: refs/heads/main\n")
+    _write(tmp_path, "intermute/go.mod", "module example.com/intermute\n")
+    _write(tmp_path, "intermute/main.go", 'package main\n\nfunc main() {\n\thttp.ListenAndServe(":7338", nil)\n}\n')
+    _write(tmp_path, "ranker/.git/HEAD", "ref: refs/heads/main\n")
+    _write(tmp_path, "ranker/Dockerfile", "FROM python:3.12\nEXPOSE 50051\n")
+    _write(tmp_path, "ranker/rank.proto", "service Ranker {\n}\n")
+    _write(tmp_path, "intermap/.git/HEAD", "ref: refs/heads/main\n")
+    _write(tmp_path, "intermap/go.mod", "module example.com/intermap\n")
+    _write(tmp_path, "intermap/cmd/intermap-mcp/main.go", (
+        "package main\n\n"
+        "func main() {\n"
+        '\tbase := os.Getenv("INTERMUTE_URL")\n'
+        '\ttoken := os.Getenv("INTERMUTE_TOKEN")\n'
+        "\t_ = pb.NewRankerClient(conn)\n}\n"
+    ))
+    _write(tmp_path, "intermap/.env.example", "INTERMUTE_URL=http://localhost:7338\nRANKER_GRPC_ADDR=ranker:50051\nLOG_LEVEL=debug\n")
+    _write(tmp_path, "intermap/tools/probe.py", (
+        "import os\n"
+        'url = os.environ.get("INTERMUTE_API_URL")\n'
+        'requests.get("http://localhost:7338/health")\n'
+    ))
+    _write(tmp_path, "shared/.git/HEAD", "ref: refs/heads/main\n")
+    _write(tmp_path, "shared/util.py", 'HOME = os.environ["HOME"]\n')
+    return tmp_path
+
+
+def test_build_service_graph(tmp_path):
+    result = build_service_graph(str(_workspace(tmp_path)))
+
+    services = {s["project"]: s for s in result["services"]}
+    assert services["intermute"]["deployable"] and services["intermute"]["ports"] == ["7338"]
+    assert services["ranker"]["deployable"] and services["ranker"]["has_dockerfile"]
+    assert services["intermap"]["binaries"] == ["intermap-mcp"]
+    assert not services["shared"]["deployable"]
+    assert services["intermap"]["calls"] == ["intermute", "ranker"]
+    assert services["intermute"]["called_by"] == ["intermap"]
+    assert result["total_deployable"] == 3
+
+    edges = {(e["from"], e["to"]): e for e in result["edges"]}
+    assert set(edges) == {("intermap", "intermute"), ("intermap", "ranker")}
+
+    intermute = edges[("intermap", "intermute")]
+    assert intermute["protocols"] == ["http"]
+    sites = {(s["file"], s["line"], s["via"]) for s in intermute["sites"]}
+    assert sites == {
+        ("intermap/cmd/intermap-mcp/main.go", 4, "$INTERMUTE_URL"),
+        ("intermap/tools/probe.py", 2, "$INTERMUTE_API_URL"),
+        ("intermap/tools/probe.py", 3, "http://localhost:7338/health"),
+        ("intermap/.env.example", 1, "INTERMUTE_URL=http://localhost:7338"),
+    }
+
+    ranker = edges[("intermap", "ranker")]
+    assert ranker["protocols"] == ["grpc"]
+    assert {s["via"] for s in ranker["sites"]} == {"Ranker", "RANKER_GRPC_ADDR=ranker:50051"}
@@ -1,10 +1,13 @@
 """Tests for intermap sidecar mode."""
 
 import json
+import logging
 import os
 import subprocess
 import sys
 
+from intermap import sidecar_log
+
 _TESTS_DIR = os.path.dirname(os.path.abspath(__file__))
 PYTHON_DIR = os.path.normpath(os.path.join(_TESTS_DIR, "../.."))
 INTERMAP_ROOT = PYTHON_DIR
@@ -163,3 +166,117 @@ def test_sidecar_internal_error_is_not_recoverable():
     finally:
         proc.stdin.close()
         proc.wait(timeout=5)
+
+
+def test_sidecar_length_framing():
+    """The bridge can switch to length-prefixed frames after the handshake."""
+    proc = subprocess.Popen(
+        [sys.executable, "-u", "-m", "intermap", "--sidecar"],
+        stdin=subprocess.PIPE,
+        stdout=subprocess.PIPE,
+        stderr=subprocess.PIPE,
+        env={**os.environ, "PYTHONPATH": os.path.join(PYTHON_DIR, "python")},
+    )
+
+    def read_frame():
+        size = int(proc.stdout.readline())
+        return json.loads(proc.stdout.read(size))
+
+    def write_frame(frame):
+        data = json.dumps(frame).encode()
+        proc.stdin.write(b"%d\n" % len(data) + data)
+        proc.stdin.flush()
+
+    try:
+        ready = json.loads(proc.stdout.readline())
+        assert ready == {"status": "ready", "framing": ["length", "ndjson"], "handoff": True}
+        proc.stdin.write(b'{"framing": "length"}\n')
+        proc.stdin.flush()
+        assert read_frame() == {"framing": "length"}
+
+        write_frame({"id": 1, "command": "structure", "project": INTERMAP_ROOT,
+                     "args": {"language": "python", "max_results": 2}})
+        resp = read_frame()
+        assert resp["id"] == 1
+        assert "files" in resp["result"]
+
+        proc.stdin.write(b"8\nnot json")
+        proc.stdin.flush()
+        assert read_frame()["error"]["type"] == "InvalidJSON"
+    finally:
+        proc.stdin.close()
+        proc.wait(timeout=5)
+
+
+def test_sidecar_handoff(tmp_path):
+    """Responses over min_bytes are written to a file and sent by reference."""
+    proc = subprocess.Popen(
+        [sys.executable, "-u", "-m", "intermap", "--sidecar"],
+        stdin=subprocess.PIPE,
+        stdout=subprocess.PIPE,
+        stderr=subprocess.PIPE,
+        env={**os.environ, "PYTHONPATH": os.path.join(PYTHON_DIR, "python")},
+    )
+
+    def read_frame():
+        size = int(proc.stdout.readline())
+        return json.loads(proc.stdout.read(size))
+
+    def write_frame(frame):
+        data = json.dumps(frame).encode()
+        proc.stdin.write(b"%d\n" % len(data) + data)
+        proc.stdin.flush()
+
+    try:
+        proc.stdout.readline()
+        sel = {"framing": "length", "handoff": {"dir": str(tmp_path), "min_bytes": 200}}
+        proc.stdin.write(json.dumps(sel).encode() + b"\n")
+        proc.stdin.flush()
+        assert read_frame() == {"framing": "length"}
+
+        write_frame({"id": 1, "command": "structure", "project": INTERMAP_ROOT,
+                     "args": {"language": "python", "max_results": 2}})
+        ref = read_frame()
+        assert ref["id"] == 1
+        path = ref["handoff"]["path"]
+        assert os.path.dirname(path) == str(tmp_path)
+        assert os.path.basename(path).startswith(f"intermap-result-{proc.pid}-")
+        with open(path, "rb") as f:
+            data = f.read()
+        assert len(data) == ref["handoff"]["bytes"]
+        resp = json.loads(data)
+        assert resp["id"] == 1
+        assert "files" in resp["result"]
+
+        # Small responses still go over the pipe.
+        write_frame({"id": 2, "command": "no_such_command", "project": INTERMAP_ROOT})
+        assert read_frame()["id"] == 2
+    finally:
+        proc.stdin.close()
+        proc.wait(timeout=5)
+
+
+def test_log_frames():
+    """Log records become frames tagged with the current request."""
+    frames = []
+    logger = logging.getLogger("intermap.test_log_frames")
+    logger.addHandler(sidecar_log.FrameHandler(frames.append))
+    logger.propagate = False
+    try:
+        sidecar_log.set_request(7, "live_changes")
+        logger.warning("no repo at %s", "/tmp/x")
+        try:
+            raise ValueError("boom")
+        except ValueError:
+            logger.exception("failed")
+    finally:
+        sidecar_log.set_request(None, "")
+    assert frames[0] == {"log": {
+        "level": "warning",
+        "logger": "intermap.test_log_frames",
+        "message": "no repo at /tmp/x",
+        "request_id": 7,
+        "command": "live_changes",
+    }}
+    assert frames[1]["log"]["level"] == "error"
+    assert "ValueError: boom" in frames[1]["log"]["exception"]
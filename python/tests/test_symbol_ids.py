@@ -0,0 +1,64 @@
+"""Tests for stable symbol IDs."""
+
+from intermap.symbol_ids import (
+    SymbolIdResolver,
+    annotate_definitions,
+    make_symbol_id,
+    package_path,
+    parse_symbol_id,
+    read_signature,
+)
+
+
+def test_package_path_by_language():
+    assert package_path("internal/tools/tools.go", "go") == "internal/tools"
+    assert package_path("main.go", "go") == ""
+    assert package_path("intermap/analysis.py", "python") == "intermap.analysis"
+    assert package_path("intermap/__init__.py", "python") == "intermap"
+    assert package_path("src/lib.rs", "rust") == "src/lib"
+
+
+def test_make_and_parse_roundtrip():
+    sid = make_symbol_id("pkg/a", "Server.Run", "func (s *Server) Run(ctx context.Context) error")
+    package, name, sighash = parse_symbol_id(sid)
+    assert package == "pkg/a"
+    assert name == "Server.Run"
+    assert len(sighash) == 8
+
+
+def test_signature_hash_ignores_whitespace():
+    a = make_symbol_id("p", "f", "def f(a,  b)")
+    b = make_symbol_id("p", "f", "def f(a, b)")
+    assert a == b
+
+
+def test_read_signature_multiline(tmp_path):
+    src = tmp_path / "m.py"
+    src.write_text("def f(\n    a,\n    b,\n):\n    return a\n")
+    assert read_signature(src, 1) == "def f( a, b, )"
+
+
+def test_read_signature_go_brace(tmp_path):
+    src = tmp_path / "m.go"
+    src.write_text("package m\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n")
+    assert read_signature(src, 3) == "func Add(a, b int) int"
+
+
+def test_ids_stable_across_line_moves(tmp_path):
+    pkg = tmp_path / "pkg"
+    pkg.mkdir()
+    (pkg / "a.py").write_text("def helper(x):\n    return x\n")
+    defs1 = annotate_definitions(tmp_path, [{"file": "pkg/a.py", "name": "helper", "line": 1, "scope": ""}], "python")
+
+    (pkg / "a.py").write_text("import os\n\n\ndef helper(x):\n    return x\n")
+    defs2 = annotate_definitions(tmp_path, [{"file": "pkg/a.py", "name": "helper", "line": 4, "scope": ""}], "python")
+
+    assert defs1[0]["id"] == defs2[0]["id"]
+    assert defs1[0]["id"].startswith("pkg.a#helper@")
+
+
+def test_resolver_scoped_method(tmp_path):
+    (tmp_path / "svc.py").write_text("class Svc:\n    def run(self):\n        pass\n")
+    resolver = SymbolIdResolver(tmp_path, "python")
+    assert resolver.resolve("svc.py", "Svc.run").startswith("svc#Svc.run@")
+    assert resolver.resolve("svc.py", "missing") == "svc#missing"
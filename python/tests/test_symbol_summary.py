@@ -0,0 +1,97 @@
+"""Tests for describe_symbol structural summaries."""
+
+import pytest
+
+from intermap.graph_store import clear_stores
+from intermap.symbol_summary import describe_symbol
+
+
+def test_python_summary(tmp_path):
+    clear_stores()
+    (tmp_path / "store.py").write_text(
+        "import subprocess\n"
+        "from os import environ\n"
+        "import requests as rq\n\n\n"
+        "def helper(x):\n"
+        "    return x\n\n\n"
+        "class Syncer:\n"
+        "    def push(self, path: str, retries: int = 3, *, force=False) -> bool:\n"
+        '        """Upload path to the server.\n\n        Details.\n        """\n'
+        "        token = environ.get('TOKEN')\n"
+        "        with open(path) as f:\n"
+        "            data = helper(f.read())\n"
+        "        if not token or retries < 0:\n"
+        "            raise ValueError('no token')\n"
+        "        rq.post('https://example.com', data=data)\n"
+        "        subprocess.run(['sync'])\n"
+        "        return True\n"
+    )
+
+    result = describe_symbol(str(tmp_path), "Syncer.push", "python")
+    [s] = result["symbols"]
+    assert (s["file"], s["line"], s["end_line"], s["kind"]) == ("store.py", 11, 23, "method")
+    assert s["id"].startswith("store#Syncer.push@")
+    assert s["doc"] == "Upload path to the server."
+    assert s["params"] == [
+        {"name": "path", "type": "str"},
+        {"name": "retries", "type": "int", "default": "3"},
+        {"name": "force", "type": None, "default": "False"},
+    ]
+    assert s["returns"] == {"type": "bool", "value": True}
+    assert s["raises"] == ["ValueError"]
+    assert s["effects"] == {
+        "filesystem": ["open"],
+        "network": ["requests.post"],
+        "subprocess": ["subprocess.run"],
+        "env": ["os.environ.get"],
+    }
+    assert s["callees"]["project"] == ["store.py:helper"]
+    assert describe_symbol(str(tmp_path), "helper", "python")["symbols"][0]["callers"] == ["store.py:Syncer.push"]
+    assert s["complexity"] == 3
+    assert not s["cached"]
+
+    assert describe_symbol(str(tmp_path), "Syncer.push", "python")["symbols"][0]["cached"]
+
+    # Editing the file drops the cached summary.
+    src = (tmp_path / "store.py").read_text()
+    (tmp_path / "store.py").write_text(src.replace("        subprocess.run(['sync'])\n", ""))
+    again = describe_symbol(str(tmp_path), s["id"].split("@")[0], "python")["symbols"][0]
+    assert not again["cached"] and "subprocess" not in again["effects"]
+
+    with pytest.raises(LookupError):
+        describe_symbol(str(tmp_path), "missing", "python")
+
+
+def test_go_summary(tmp_path):
+    clear_stores()
+    (tmp_path / "go.mod").write_text("module example.com/m\n\ngo 1.22\n")
+    (tmp_path / "load.go").write_text(
+        "package m\n\n"
+        "// Load reads the config at path.\n"
+        "func (l *Loader) Load(ctx context.Context, path, name string, n int) (cfg *Config, err error) {\n"
+        "\tdata, err := os.ReadFile(path) // os.Remove(path) is not called\n"
+        "\tif err != nil {\n"
+        "\t\treturn nil, fmt.Errorf(\"read {%s}: %w\", path, err)\n"
+        "\t}\n"
+        "\tif os.Getenv(\"STRICT\") != \"\" && len(data) == 0 {\n"
+        "\t\tpanic(\"empty\")\n"
+        "\t}\n"
+        "\tonce.Do(func() {})\n"
+        "\treturn parse(data)\n"
+        "}\n"
+    )
+
+    [s] = describe_symbol(str(tmp_path), "Load", "go")["symbols"]
+    assert s["end_line"] == 14
+    assert s["doc"] == "Load reads the config at path."
+    assert s["params"] == [
+        {"name": "ctx", "type": "context.Context"},
+        {"name": "path", "type": "string"},
+        {"name": "name", "type": "string"},
+        {"name": "n", "type": "int"},
+    ]
+    assert s["returns"] == {"types": ["*Config", "error"], "error": True}
+    assert s["raises"] == ["panic"]
+    assert s["effects"] == {"filesystem": ["os.ReadFile"], "env": ["os.Getenv"]}
+    assert s["callees"]["external"] == ["fmt.Errorf", "once.Do", "os.Getenv", "os.ReadFile", "parse"]
+    assert s["complexity"] == 4
@@ -0,0 +1,52 @@
+"""Tests for source-to-sink call paths."""
+
+from intermap.graph_store import clear_stores
+from intermap.taint import find_taint_paths
+
+
+def _project(tmp_path):
+    (tmp_path / "db.py").write_text(
+        "def run_query(cursor, sql):\n"
+        "    cursor.execute(sql)\n\n\n"
+        "def archive(name):\n"
+        "    import subprocess\n"
+        "    subprocess.run(['tar', name])\n"
+    )
+    (tmp_path / "views.py").write_text(
+        "import os\n"
+        "import sys\n"
+        "from flask import request\n"
+        "from db import run_query, archive\n\n\n"
+        "def search(cursor):\n"
+        "    term = request.args.get('q')\n"
+        "    return lookup(cursor, term)\n\n\n"
+        "def lookup(cursor, term):\n"
+        "    return run_query(cursor, 'SELECT ' + term)\n\n\n"
+        "def main():\n"
+        "    archive(sys.argv[1])\n\n\n"
+        "def cleanup():\n"
+        "    os.remove(os.environ['TMP_FILE'])\n"
+    )
+    return tmp_path
+
+
+def test_flows(tmp_path):
+    clear_stores()
+    result = find_taint_paths(str(_project(tmp_path)), "python")
+    got = [(f["source"]["function"], f["sink"]["function"], f["path"], list(f["sink"]["kinds"])) for f in result["flows"]]
+    assert got == [
+        ("cleanup", "cleanup", ["views.py:cleanup"], ["file_write"]),
+        ("main", "archive", ["views.py:main", "db.py:archive"], ["exec"]),
+        ("search", "run_query", ["views.py:search", "views.py:lookup", "db.py:run_query"], ["sql"]),
+    ]
+    assert result["flows"][2]["source"]["kinds"] == {"http": ["flask.request.args", "flask.request.args.get"]}
+    assert result["by_kind"] == {"cli->exec": 1, "env->file_write": 1, "http->sql": 1}
+
+
+def test_filters(tmp_path):
+    clear_stores()
+    _project(tmp_path)
+    assert [f["sink"]["function"] for f in find_taint_paths(str(tmp_path), "python", sinks=["sql"])["flows"]] == ["run_query"]
+    assert find_taint_paths(str(tmp_path), "python", sources=["http"], max_depth=1)["flows"] == []
+    through_db = find_taint_paths(str(tmp_path), "python", files=["db.py"])
+    assert [f["source"]["function"] for f in through_db["flows"]] == ["main", "search"]
@@ -0,0 +1,79 @@
+"""Tests for type-aware Python impact analysis."""
+
+import pytest
+
+from intermap.analysis import analyze_impact
+from intermap.typed_calls import JEDI_AVAILABLE
+
+pytestmark = pytest.mark.skipif(not JEDI_AVAILABLE, reason="jedi not installed")
+
+
+def _project(tmp_path):
+    files = {
+        "store.py": (
+            "class Disk:\n"
+            "    def save(self):\n"
+            "        pass\n"
+            "\n"
+            "\n"
+            "class Memory:\n"
+            "    def save(self):\n"
+            "        pass\n"
+        ),
+        "service.py": (
+            "import store as backend\n"
+            "\n"
+            "\n"
+            "class Service:\n"
+            "    def __init__(self, disk: backend.Disk):\n"
+            "        self.disk = disk\n"
+            "\n"
+            "    def persist(self):\n"
+            "        self.disk.save()\n"
+            "\n"
+            "\n"
+            "def run():\n"
+            "    Service(backend.Disk()).persist()\n"
+            "\n"
+            "\n"
+            "def scratch():\n"
+            "    backend.Memory().save()\n"
+        ),
+    }
+    for rel, content in files.items():
+        (tmp_path / rel).write_text(content)
+    return tmp_path
+
+
+def test_typed_impact_follows_inferred_attribute_calls(tmp_path):
+    root = _project(tmp_path)
+    result = analyze_impact(str(root), "Disk.save", max_depth=3, precision="typed")
+
+    assert result["total_targets"] == 1
+    tree = result["targets"]["store.py:Disk.save"]
+    assert tree["function"] == "save"
+    assert tree["qualified"] == "Disk.save"
+    assert [c["qualified"] for c in tree["callers"]] == ["Service.persist"]
+    persist = tree["callers"][0]
+    assert persist["file"] == "service.py"
+    assert persist["id"].startswith("service#Service.persist")
+    assert [c["function"] for c in persist["callers"]] == ["run"]
+
+
+def test_typed_impact_bare_name_matches_each_method(tmp_path):
+    root = _project(tmp_path)
+    result = analyze_impact(str(root), "store.py:save", max_depth=1, precision="typed")
+
+    callers = {
+        key: [c["qualified"] for c in tree["callers"]]
+        for key, tree in result["targets"].items()
+    }
+    assert callers == {
+        "store.py:Disk.save": ["Service.persist"],
+        "store.py:Memory.save": ["scratch"],
+    }
+
+
+def test_typed_impact_rejects_other_languages(tmp_path):
+    result = analyze_impact(str(tmp_path), "f", language="go", precision="typed")
+    assert "only available for python" in result["error"]
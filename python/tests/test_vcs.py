@@ -0,0 +1,125 @@
+"""Tests for VCS detection and ref translation."""
+
+import shutil
+import subprocess
+
+import pytest
+
+from intermap import vcs
+
+
+def _git(path, *args):
+    subprocess.run(
+        ["git", "-c", "user.email=test@test.com", "-c", "user.name=Test", *args],
+        cwd=str(path), capture_output=True, check=True,
+    )
+
+
+def test_detect_prefers_jj_when_colocated(tmp_path):
+    (tmp_path / "colocated" / ".git").mkdir(parents=True)
+    (tmp_path / "colocated" / ".jj").mkdir()
+    (tmp_path / "hg" / ".hg").mkdir(parents=True)
+    (tmp_path / "hg" / "src").mkdir()
+
+    assert vcs.detect(str(tmp_path / "colocated")) == (vcs.JUJUTSU, str(tmp_path / "colocated"))
+    assert vcs.detect(str(tmp_path / "hg" / "src")) == (vcs.MERCURIAL, str(tmp_path / "hg"))
+
+
+def test_translate_ref():
+    for ref, jj, hg in [
+        ("HEAD", "@-", "."),
+        ("HEAD~1", "@--", ".~1"),
+        ("HEAD^", "@--", ".~1"),
+        ("HEAD~3", "@----", ".~3"),
+        ("main", "main", "main"),
+    ]:
+        assert vcs.translate_ref(vcs.JUJUTSU, ref) == jj
+        assert vcs.translate_ref(vcs.MERCURIAL, ref) == hg
+        assert vcs.translate_ref(vcs.GIT, ref) == ref
+
+
+@pytest.mark.skipif(shutil.which("git") is None, reason="git not installed")
+def test_git_changed_files_relative(tmp_path):
+    _git(tmp_path, "init", "-q")
+    (tmp_path / "sub").mkdir()
+    (tmp_path / "a.py").write_text("a = 1\n")
+    (tmp_path / "sub" / "b.py").write_text("b = 1\n")
+    _git(tmp_path, "add", ".")
+    _git(tmp_path, "commit", "-q", "-m", "one")
+    (tmp_path / "a.py").write_text("a = 2\n")
+    (tmp_path / "sub" / "b.py").write_text("b = 2\n")
+
+    assert sorted(vcs.changed_files(str(tmp_path), "HEAD")) == ["a.py", "sub/b.py"]
+    assert vcs.changed_files(str(tmp_path / "sub"), "HEAD") == ["b.py"]
+    assert vcs.resolve(str(tmp_path), "HEAD")
+    assert vcs.show(str(tmp_path), "HEAD", "a.py") == b"a = 1\n"
+
+
+@pytest.mark.skipif(shutil.which("git") is None, reason="git not installed")
+def test_option_like_refs_are_rejected(tmp_path):
+    _git(tmp_path, "init", "-q")
+    (tmp_path / "a.py").write_text("a = 1\n")
+    _git(tmp_path, "add", ".")
+    _git(tmp_path, "commit", "-q", "-m", "one")
+    leak = tmp_path / "leak.txt"
+    for call in (
+        lambda: vcs.changed_files(str(tmp_path), f"--output={leak}"),
+        lambda: vcs.diff(str(tmp_path), f"--output={leak}"),
+        lambda: vcs.resolve(str(tmp_path), "-h"),
+        lambda: vcs.changed_files(str(tmp_path), ""),
+    ):
+        with pytest.raises(ValueError, match="invalid ref"):
+            call()
+    assert not leak.exists()
+
+
+@pytest.mark.skipif(shutil.which("hg") is None, reason="hg not installed")
+def test_hg_changed_files(tmp_path):
+    subprocess.run(["hg", "init"], cwd=str(tmp_path), check=True, capture_output=True)
+    (tmp_path / "a.py").write_text("a = 1\n")
+    subprocess.run(["hg", "add", "a.py"], cwd=str(tmp_path), check=True, capture_output=True)
+    subprocess.run(
+        ["hg", "commit", "-u", "test", "-m", "one"],
+        cwd=str(tmp_path), check=True, capture_output=True,
+    )
+    (tmp_path / "a.py").write_text("a = 2\n")
+
+    assert vcs.changed_files(str(tmp_path), "HEAD") == ["a.py"]
+    assert vcs.show(str(tmp_path), "HEAD", "a.py") == b"a = 1\n"
+
+
+@pytest.mark.skipif(shutil.which("jj") is None, reason="jj not installed")
+def test_jj_changed_files(tmp_path):
+    subprocess.run(["jj", "git", "init"], cwd=str(tmp_path), check=True, capture_output=True)
+    (tmp_path / "a.py").write_text("a = 1\n")
+    subprocess.run(["jj", "commit", "-m", "one"], cwd=str(tmp_path), check=True, capture_output=True)
+    (tmp_path / "a.py").write_text("a = 2\n")
+
+    assert vcs.changed_files(str(tmp_path), "HEAD") == ["a.py"]
+    assert vcs.show(str(tmp_path), "HEAD", "a.py") == b"a = 1\n"
+
+
+def test_checkout_state(tmp_path):
+    def write(rel, content):
+        path = tmp_path / rel
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(content)
+
+    write("plain/.git/config", "[core]\n\tbare = false\n")
+    write("sparse/.git/config", "[core]\n\tsparseCheckout = true\n")
+    write("sparse/.git/info/sparse-checkout", "/src/\n")
+    write("partial/.git/config", '[remote "origin"]\n\tpromisor = true\n')
+    write("main/.git/config", "[extensions]\n\tpartialClone = origin\n")
+    write("main/.git/worktrees/wt/commondir", "../..\n")
+    write("wt/.git", f"gitdir: {tmp_path / 'main/.git/worktrees/wt'}\n")
+    write("narrow/.hg/store/narrowspec", "[include]\npath:src\n")
+
+    assert vcs.checkout_state(str(tmp_path / "plain")) is None
+    assert vcs.checkout_state(str(tmp_path / "sparse" / "src")) == {
+        "vcs": "git", "sparse": True, "partial_clone": False,
+    }
+    assert vcs.checkout_state(str(tmp_path / "partial"))["partial_clone"] is True
+    assert vcs.checkout_state(str(tmp_path / "wt"))["partial_clone"] is True
+    assert vcs.checkout_state(str(tmp_path / "narrow")) == {
+        "vcs": "hg", "sparse": False, "partial_clone": True,
+    }
@@ -0,0 +1,149 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Marker maps a file in a project root to a language. File may be an exact
+// name ("go.mod") or a glob ("*.tf").
+type Marker struct {
+	File     string
+	Language string
+}
+
+var builtinMarkers = []Marker{
+	{"go.mod", "go"},
+	{"pyproject.toml", "python"},
+	{"setup.py", "python"},
+	{"package.json", "typescript"},
+	{"Cargo.toml", "rust"},
+	{"build.gradle", "java"},
+	{"pom.xml", "java"},
+}
+
+var builtinExtensions = map[string]string{
+	".go":    "go",
+	".py":    "python",
+	".ts":    "typescript",
+	".tsx":   "typescript",
+	".js":    "typescript",
+	".rs":    "rust",
+	".java":  "java",
+	".c":     "c",
+	".h":     "c",
+	".cpp":   "cpp",
+	".rb":    "ruby",
+	".php":   "php",
+	".kt":    "kotlin",
+	".swift": "swift",
+	".cs":    "csharp",
+	".scala": "scala",
+	".lua":   "lua",
+	".ex":    "elixir",
+	".exs":   "elixir",
+}
+
+var (
+	langMu          sync.RWMutex
+	extraMarkers    []Marker
+	extraExtensions map[string]string
+)
+
+// SetLanguageConfig installs user-defined markers (checked before the built-in
+// table) and extension mappings (merged over the built-in extension table).
+func SetLanguageConfig(markers []Marker, extensions map[string]string) {
+	langMu.Lock()
+	defer langMu.Unlock()
+	extraMarkers = append([]Marker(nil), markers...)
+	extraExtensions = make(map[string]string, len(extensions))
+	for ext, lang := range extensions {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		extraExtensions[strings.ToLower(ext)] = lang
+	}
+}
+
+// DetectLanguage returns the primary language of a project. Marker files are
+// checked first (user-defined, then built-in); if none match, the most common
+// known source extension in the project root and its immediate subdirectories
+// wins. Returns "unknown" if nothing matches.
+func DetectLanguage(projectPath string) string {
+	langMu.RLock()
+	markers := append(append([]Marker(nil), extraMarkers...), builtinMarkers...)
+	extra := extraExtensions
+	langMu.RUnlock()
+
+	for _, m := range markers {
+		if matchMarker(projectPath, m.File) {
+			return m.Language
+		}
+	}
+	if lang := detectByExtension(projectPath, extra); lang != "" {
+		return lang
+	}
+	return "unknown"
+}
+
+func matchMarker(projectPath, file string) bool {
+	if strings.ContainsAny(file, "*?[") {
+		matches, err := filepath.Glob(filepath.Join(projectPath, file))
+		return err == nil && len(matches) > 0
+	}
+	_, err := os.Stat(filepath.Join(projectPath, file))
+	return err == nil
+}
+
+func detectByExtension(projectPath string, extra map[string]string) string {
+	lookup := func(name string) string {
+		ext := strings.ToLower(filepath.Ext(name))
+		if lang, ok := extra[ext]; ok {
+			return lang
+		}
+		return builtinExtensions[ext]
+	}
+
+	counts := make(map[string]int)
+	entries, err := os.ReadDir(projectPath)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if !e.IsDir() {
+			if lang := lookup(name); lang != "" {
+				counts[lang]++
+			}
+			continue
+		}
+		if name == "vendor" || name == "node_modules" || name == "__pycache__" || name == "venv" {
+			continue
+		}
+		subEntries, err := os.ReadDir(filepath.Join(projectPath, name))
+		if err != nil {
+			continue
+		}
+		for _, sub := range subEntries {
+			if sub.IsDir() {
+				continue
+			}
+			if lang := lookup(sub.Name()); lang != "" {
+				counts[lang]++
+			}
+		}
+	}
+
+	best, bestCount := "", 0
+	for lang, n := range counts {
+		if n > bestCount || (n == bestCount && lang < best) {
+			best, bestCount = lang, n
+		}
+	}
+	return best
+}
@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetectLanguage_BuiltinMarker(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"))
+	if got := DetectLanguage(dir); got != "go" {
+		t.Errorf("expected go, got %s", got)
+	}
+}
+
+func TestDetectLanguage_CustomMarkers(t *testing.T) {
+	t.Cleanup(func() { SetLanguageConfig(nil, nil) })
+	SetLanguageConfig([]Marker{
+		{File: "mix.exs", Language: "elixir"},
+		{File: "*.tf", Language: "terraform"},
+	}, nil)
+
+	elixir := t.TempDir()
+	writeFile(t, filepath.Join(elixir, "mix.exs"))
+	if got := DetectLanguage(elixir); got != "elixir" {
+		t.Errorf("expected elixir, got %s", got)
+	}
+
+	tf := t.TempDir()
+	writeFile(t, filepath.Join(tf, "main.tf"))
+	if got := DetectLanguage(tf); got != "terraform" {
+		t.Errorf("expected terraform, got %s", got)
+	}
+}
+
+func TestDetectLanguage_ExtensionFallback(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "src", "a.rs"))
+	writeFile(t, filepath.Join(dir, "src", "b.rs"))
+	writeFile(t, filepath.Join(dir, "build.py"))
+	if got := DetectLanguage(dir); got != "rust" {
+		t.Errorf("expected rust, got %s", got)
+	}
+}
+
+func TestDetectLanguage_CustomExtension(t *testing.T) {
+	t.Cleanup(func() { SetLanguageConfig(nil, nil) })
+	SetLanguageConfig(nil, map[string]string{"zig": "zig"})
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.zig"))
+	if got := DetectLanguage(dir); got != "zig" {
+		t.Errorf("expected zig, got %s", got)
+	}
+}
+
+func TestDetectLanguage_Unknown(t *testing.T) {
+	if got := DetectLanguage(t.TempDir()); got != "unknown" {
+		t.Errorf("expected unknown, got %s", got)
+	}
+}
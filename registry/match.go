@@ -0,0 +1,163 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Match kinds, from strongest to weakest.
+const (
+	MatchExact  = "exact"  // name or group/name equal, ignoring case
+	MatchName   = "name"   // name equal, but the query's group differs
+	MatchPrefix = "prefix" // query is a prefix of the name
+	MatchFuzzy  = "fuzzy"  // small edit distance, or name contains query
+)
+
+// Match is a project that a lookup query matched.
+type Match struct {
+	Project  Project `json:"project"`
+	Kind     string  `json:"kind"`
+	Distance int     `json:"distance"`
+}
+
+// qualifiedMatch reports whether query names the project whose group/name
+// is pqual: equal to it, a path ending in it, or its trailing segments
+// ("services/auth" for "platform/services/auth").
+func qualifiedMatch(query, pqual string) bool {
+	return query == pqual || strings.HasSuffix(query, "/"+pqual) || strings.HasSuffix(pqual, "/"+query)
+}
+
+// Lookup matches query, a project name, group/name (the group may be
+// nested), or a path ending in one, against projects. It returns only the
+// strongest tier that matched (exact, name, prefix, then fuzzy), closest
+// first. A group/name query or path whose group does not match, such as a
+// path from another checkout, is only a name match. A single result is an
+// unambiguous match; several are candidates to offer the caller.
+func Lookup(projects []Project, query string) []Match {
+	query = strings.ToLower(strings.Trim(filepath.ToSlash(query), "/"))
+	if query == "" {
+		return nil
+	}
+	name := query
+	if i := strings.LastIndex(query, "/"); i >= 0 {
+		name = query[i+1:]
+	}
+	qualified := strings.Contains(query, "/")
+
+	var exact, named, prefix, fuzzy []Match
+	for _, p := range projects {
+		pname := strings.ToLower(p.Name)
+		pqual := strings.ToLower(p.Group + "/" + p.Name)
+		switch {
+		case qualified && p.Group != "" && qualifiedMatch(query, pqual):
+			// A matching group outranks a bare-name match.
+			exact = append(exact, Match{Project: p, Kind: MatchExact})
+		case pname == name && !qualified:
+			exact = append(exact, Match{Project: p, Kind: MatchExact, Distance: 1})
+		case pname == name:
+			named = append(named, Match{Project: p, Kind: MatchName})
+		case strings.HasPrefix(pname, name):
+			prefix = append(prefix, Match{Project: p, Kind: MatchPrefix, Distance: len(pname) - len(name)})
+		default:
+			d := editDistance(pname, name)
+			if d <= max(1, len(name)/4) {
+				fuzzy = append(fuzzy, Match{Project: p, Kind: MatchFuzzy, Distance: d})
+			} else if len(name) >= 3 && strings.Contains(pname, name) {
+				fuzzy = append(fuzzy, Match{Project: p, Kind: MatchFuzzy, Distance: len(pname) - len(name)})
+			}
+		}
+	}
+
+	for _, tier := range [][]Match{exact, named, prefix, fuzzy} {
+		if len(tier) == 0 {
+			continue
+		}
+		sort.Slice(tier, func(i, j int) bool {
+			if tier[i].Distance != tier[j].Distance {
+				return tier[i].Distance < tier[j].Distance
+			}
+			return tier[i].Project.Path < tier[j].Project.Path
+		})
+		if tier[0].Kind == MatchExact {
+			// A group/name hit wins over bare-name hits.
+			n := 1
+			for n < len(tier) && tier[n].Distance == tier[0].Distance {
+				n++
+			}
+			return tier[:n]
+		}
+		return tier
+	}
+	return nil
+}
+
+// ErrNotFound is returned by Find when no project matches.
+var ErrNotFound = errors.New("no path or workspace project matches")
+
+// MaxSuggestions bounds the candidates an AmbiguousError lists.
+const MaxSuggestions = 5
+
+// AmbiguousError is returned by Find when a query matches several projects
+// equally well.
+type AmbiguousError struct {
+	Query   string
+	Matches []Match
+}
+
+func (e *AmbiguousError) Error() string {
+	names := make([]string, 0, MaxSuggestions)
+	for _, m := range e.Matches[:min(len(e.Matches), MaxSuggestions)] {
+		names = append(names, m.Project.Group+"/"+m.Project.Name)
+	}
+	more := ""
+	if len(e.Matches) > MaxSuggestions {
+		more = fmt.Sprintf(" (+%d more)", len(e.Matches)-MaxSuggestions)
+	}
+	return fmt.Sprintf("project %q is ambiguous; did you mean: %s%s", e.Query, strings.Join(names, ", "), more)
+}
+
+// Find resolves query to a single project with Lookup. It returns an error
+// wrapping ErrNotFound when nothing matches, and an *AmbiguousError when
+// several projects do.
+func Find(projects []Project, query string) (Project, error) {
+	m, err := FindMatch(projects, query)
+	return m.Project, err
+}
+
+// FindMatch is Find, returning the match so callers can tell an exact hit
+// from a prefix or fuzzy one.
+func FindMatch(projects []Project, query string) (Match, error) {
+	matches := Lookup(projects, query)
+	switch len(matches) {
+	case 0:
+		return Match{}, fmt.Errorf("project %q not found (%w)", query, ErrNotFound)
+	case 1:
+		return matches[0], nil
+	}
+	return Match{}, &AmbiguousError{Query: query, Matches: matches}
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
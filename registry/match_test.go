@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	projects := []Project{
+		{Name: "intermap", Group: "interverse", Path: "/ws/interverse/intermap"},
+		{Name: "intermute", Group: "core", Path: "/ws/core/intermute"},
+		{Name: "interlock", Group: "interverse", Path: "/ws/interverse/interlock"},
+		{Name: "api", Group: "core", Path: "/ws/core/api"},
+		{Name: "api", Group: "apps", Path: "/ws/apps/api"},
+		{Name: "auth", Group: "platform/services", Path: "/ws/platform/services/auth"},
+		{Name: "auth", Group: "legacy", Path: "/ws/legacy/auth"},
+	}
+
+	for _, tc := range []struct {
+		query string
+		kind  string
+		want  []string // paths
+	}{
+		{"intermap", MatchExact, []string{"/ws/interverse/intermap"}},
+		{"InterMap", MatchExact, []string{"/ws/interverse/intermap"}},
+		{"core/api", MatchExact, []string{"/ws/core/api"}},
+		{"/old/ws/core/api", MatchExact, []string{"/ws/core/api"}},
+		{"api", MatchExact, []string{"/ws/apps/api", "/ws/core/api"}},
+		{"platform/services/auth", MatchExact, []string{"/ws/platform/services/auth"}},
+		{"services/auth", MatchExact, []string{"/ws/platform/services/auth"}},
+		{"/old/ws/platform/services/auth", MatchExact, []string{"/ws/platform/services/auth"}},
+		{"/old/checkout/intermap", MatchName, []string{"/ws/interverse/intermap"}},
+		{"/old/checkout/api", MatchName, []string{"/ws/apps/api", "/ws/core/api"}},
+		{"legacy/api", MatchName, []string{"/ws/apps/api", "/ws/core/api"}},
+		{"intermu", MatchPrefix, []string{"/ws/core/intermute"}},
+		{"inter", MatchPrefix, []string{"/ws/interverse/intermap", "/ws/core/intermute", "/ws/interverse/interlock"}},
+		{"intermapp", MatchFuzzy, []string{"/ws/interverse/intermap"}},
+		{"intrlock", MatchFuzzy, []string{"/ws/interverse/interlock"}},
+		{"termut", MatchFuzzy, []string{"/ws/core/intermute"}},
+		{"zzz", "", nil},
+	} {
+		got := Lookup(projects, tc.query)
+		if len(got) != len(tc.want) {
+			t.Errorf("Lookup(%q) = %v, want %v", tc.query, got, tc.want)
+			continue
+		}
+		for i, m := range got {
+			if m.Project.Path != tc.want[i] || m.Kind != tc.kind {
+				t.Errorf("Lookup(%q)[%d] = %s (%s), want %s (%s)", tc.query, i, m.Project.Path, m.Kind, tc.want[i], tc.kind)
+			}
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	projects := []Project{
+		{Name: "api", Group: "core", Path: "/ws/core/api"},
+		{Name: "api", Group: "apps", Path: "/ws/apps/api"},
+		{Name: "intermap", Group: "interverse", Path: "/ws/interverse/intermap"},
+	}
+
+	p, err := Find(projects, "intermapp")
+	if err != nil || p.Path != "/ws/interverse/intermap" {
+		t.Errorf("Find(intermapp) = %v, %v", p, err)
+	}
+	if _, err := Find(projects, "zzz"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Find(zzz) error = %v, want ErrNotFound", err)
+	}
+	_, err = Find(projects, "api")
+	var amb *AmbiguousError
+	if !errors.As(err, &amb) || len(amb.Matches) != 2 {
+		t.Fatalf("Find(api) error = %v, want AmbiguousError with 2 matches", err)
+	}
+	if want := `project "api" is ambiguous; did you mean: apps/api, core/api`; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
@@ -0,0 +1,436 @@
+// Package registry discovers the projects in a workspace and resolves
+// paths and names to them.
+//
+// This package is part of intermap's public Go API and follows semantic
+// versioning: exported identifiers are not removed or changed incompatibly
+// within a major version.
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mistakeknot/intermap/internal/stats"
+	"github.com/mistakeknot/intermap/internal/vcs"
+	"github.com/mistakeknot/intermap/internal/xxhash"
+)
+
+// Checkout describes a sparse checkout or partial clone.
+type Checkout = vcs.Checkout
+
+// FileCounts are a project's file and line counts; see WithStats.
+type FileCounts = stats.FileCounts
+
+// Project represents a discovered project in the workspace.
+type Project struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Language string `json:"language"`
+	Group    string `json:"group"`
+	// VCS is the version control kind: "git", "jj", or "hg".
+	VCS string `json:"vcs,omitempty"`
+	// GitBranch is the current branch, or bookmark for jj and hg.
+	GitBranch string `json:"git_branch"`
+	// Checkout is set for sparse checkouts and partial clones, where files
+	// the project references may be missing locally.
+	Checkout *Checkout `json:"checkout,omitempty"`
+	// Stats is filled only on request; see WithStats.
+	Stats *FileCounts `json:"stats,omitempty"`
+}
+
+// DefaultScanWorkers is the number of directories Scan reads concurrently
+// unless SetScanWorkers overrides it.
+const DefaultScanWorkers = 16
+
+var scanWorkers atomic.Int32
+
+func init() {
+	scanWorkers.Store(DefaultScanWorkers)
+	maxDepth.Store(DefaultMaxDepth)
+}
+
+// SetScanWorkers sets how many directories Scan reads concurrently. Values
+// below 1 restore DefaultScanWorkers; 1 scans serially.
+func SetScanWorkers(n int) {
+	if n < 1 {
+		n = DefaultScanWorkers
+	}
+	scanWorkers.Store(int32(n))
+}
+
+// DefaultMaxDepth is how many directory levels below the root Scan looks
+// for projects unless SetMaxDepth overrides it. The classic layout,
+// <root>/<group>/<project>, needs 2; nested monorepo groups such as
+// platform/services/auth need more.
+const DefaultMaxDepth = 4
+
+var maxDepth atomic.Int32
+
+// SetMaxDepth sets how deep Scan looks for projects. Values below 1
+// restore DefaultMaxDepth.
+func SetMaxDepth(n int) {
+	if n < 1 {
+		n = DefaultMaxDepth
+	}
+	maxDepth.Store(int32(n))
+}
+
+// MaxDepth returns the current scan depth.
+func MaxDepth() int {
+	return int(maxDepth.Load())
+}
+
+// skipGroupDirs are never searched for projects: they hold dependencies
+// or build output, and can be huge.
+var skipGroupDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "target": true, "dist": true,
+	"build": true, "__pycache__": true, "venv": true,
+}
+
+// Scan walks root looking for git, jj, and hg working copies, returning a
+// Project for each. A project's Group is the slash-separated path from the
+// root to its parent ("platform/services" for platform/services/auth, ""
+// for a project directly under the root). Projects are not searched for
+// nested projects, except that top-level directories are always searched,
+// so a group directory that is itself a repository still yields the
+// projects inside it. Directories are read level by level by a bounded
+// pool of workers (see SetScanWorkers), which matters on network
+// filesystems where each stat is a round trip.
+func Scan(root string) ([]Project, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("abs root: %w", err)
+	}
+	if _, err := os.ReadDir(absRoot); err != nil {
+		return nil, fmt.Errorf("read root: %w", err)
+	}
+	workers := int(scanWorkers.Load())
+
+	found, _ := walkWorkspace(absRoot, workers)
+	projects := make([]Project, len(found))
+	for i, rel := range found {
+		group := filepath.ToSlash(filepath.Dir(rel))
+		if group == "." {
+			group = ""
+		}
+		projects[i] = Project{
+			Name:  filepath.Base(rel),
+			Path:  filepath.Join(absRoot, rel),
+			Group: group,
+		}
+	}
+	forEach(workers, len(projects), func(i int) {
+		p := &projects[i]
+		p.Language = DetectLanguage(p.Path)
+		setVCS(p)
+	})
+
+	// Also check if root itself is a project
+	if vcs.Marker(absRoot) != "" {
+		p := Project{
+			Name:     filepath.Base(absRoot),
+			Path:     absRoot,
+			Language: DetectLanguage(absRoot),
+			Group:    "",
+		}
+		setVCS(&p)
+		projects = append([]Project{p}, projects...)
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Group != projects[j].Group {
+			return projects[i].Group < projects[j].Group
+		}
+		return projects[i].Name < projects[j].Name
+	})
+
+	return projects, nil
+}
+
+// walkWorkspace searches absRoot to MaxDepth and returns the root-relative
+// paths of the projects found and of the directories it listed (the root
+// as ".").
+func walkWorkspace(absRoot string, workers int) (projects, listed []string) {
+	depthLimit := MaxDepth()
+	level := []string{"."}
+	for depth := 1; depth <= depthLimit && len(level) > 0; depth++ {
+		listed = append(listed, level...)
+
+		// List the subdirectories of this level.
+		children := make([][]string, len(level))
+		forEach(workers, len(level), func(i int) {
+			entries, err := os.ReadDir(filepath.Join(absRoot, level[i]))
+			if err != nil {
+				return
+			}
+			for _, e := range entries {
+				name := e.Name()
+				if e.IsDir() && !strings.HasPrefix(name, ".") && !skipGroupDirs[name] {
+					children[i] = append(children[i], filepath.Join(level[i], name))
+				}
+			}
+		})
+		var candidates []string
+		for _, c := range children {
+			candidates = append(candidates, c...)
+		}
+
+		// Keep the ones that are repositories; search the rest next.
+		isProject := make([]bool, len(candidates))
+		forEach(workers, len(candidates), func(i int) {
+			isProject[i] = vcs.Marker(filepath.Join(absRoot, candidates[i])) != ""
+		})
+		level = nil
+		for i, c := range candidates {
+			if isProject[i] {
+				projects = append(projects, c)
+			}
+			if (!isProject[i] || depth == 1) && depth < depthLimit {
+				level = append(level, c)
+			}
+		}
+	}
+	return projects, listed
+}
+
+// WithStats returns a copy of projects with Stats filled in, counting the
+// projects concurrently with the scan worker pool.
+func WithStats(projects []Project) []Project {
+	out := make([]Project, len(projects))
+	copy(out, projects)
+	forEach(int(scanWorkers.Load()), len(out), func(i int) {
+		counts := stats.CountFiles(out[i].Path)
+		out[i].Stats = &counts
+	})
+	return out
+}
+
+// forEach calls fn(i) for i in [0, n) on at most workers goroutines.
+func forEach(workers, n int, fn func(i int)) {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// Resolve walks up from path to find the nearest git, jj, or hg working
+// copy root.
+func Resolve(path string) (*Project, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("abs path: %w", err)
+	}
+	repo, err := vcs.Find(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("path %q is not within any git, jj, or hg project", path)
+	}
+	current := repo.Root()
+	p := &Project{
+		Name:     filepath.Base(current),
+		Path:     current,
+		Language: DetectLanguage(current),
+	}
+	setVCS(p)
+	// Try to detect group from parent dir name
+	parent := filepath.Dir(current)
+	if parent != current {
+		p.Group = filepath.Base(parent)
+	}
+	return p, nil
+}
+
+// setVCS fills in p's VCS kind, branch, and checkout state.
+func setVCS(p *Project) {
+	if repo := vcs.Open(p.Path); repo != nil {
+		p.VCS = repo.Kind()
+		p.GitBranch = repo.Branch(context.Background())
+		if c := vcs.CheckoutOf(p.Path); !c.Complete() {
+			p.Checkout = &c
+		}
+	}
+}
+
+// scanListing is the directories a walk of a root listed, and the
+// fingerprint they gave.
+type scanListing struct {
+	depth       int
+	listed      []string
+	fingerprint string
+}
+
+// scanListings caches a scanListing per absolute root.
+var scanListings sync.Map
+
+// ScanFingerprint hashes the mtimes of root and of every group directory
+// Scan would search. Adding, removing, or renaming a project changes its
+// parent directory's mtime, so the fingerprint changes whenever Scan's
+// project list would. It does not notice branch switches or language
+// changes inside projects. Only the directories the last walk of root
+// listed are stat'ed; the workspace is walked again when one of them
+// changed, or on the first call, so an unchanged workspace costs one stat
+// per group directory.
+func ScanFingerprint(root string) (string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("abs root: %w", err)
+	}
+	if _, err := os.Stat(absRoot); err != nil {
+		return "", err
+	}
+	depth := MaxDepth()
+	if v, ok := scanListings.Load(absRoot); ok {
+		last := v.(scanListing)
+		if last.depth == depth && mtimeFingerprint(absRoot, last.listed) == last.fingerprint {
+			return last.fingerprint, nil
+		}
+	}
+	_, listed := walkWorkspace(absRoot, int(scanWorkers.Load()))
+	sort.Strings(listed)
+	fingerprint := mtimeFingerprint(absRoot, listed)
+	scanListings.Store(absRoot, scanListing{depth: depth, listed: listed, fingerprint: fingerprint})
+	return fingerprint, nil
+}
+
+// mtimeFingerprint hashes the mtimes of the sorted root-relative dirs.
+// A directory that is gone is hashed as such.
+func mtimeFingerprint(absRoot string, dirs []string) string {
+	h := sha256.New()
+	for _, rel := range dirs {
+		info, err := os.Stat(filepath.Join(absRoot, rel))
+		if err != nil {
+			fmt.Fprintf(h, "%s:-\n", filepath.ToSlash(rel))
+			continue
+		}
+		fmt.Fprintf(h, "%s:%d\n", filepath.ToSlash(rel), info.ModTime().UnixNano())
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// MtimeHash computes a hash of all source file mtimes in a project for cache invalidation.
+func MtimeHash(projectPath string) (string, error) {
+	files, err := sourceFiles(projectPath)
+	if err != nil {
+		return "", err
+	}
+	entries := make([]string, len(files))
+	for i, f := range files {
+		entries[i] = fmt.Sprintf("%s:%d", f.path, f.mtime)
+	}
+	return hashEntries(entries), nil
+}
+
+// ContentHash computes a hash of the contents of the same files MtimeHash
+// covers, so it is unaffected by checkouts and tools that touch mtimes
+// without changing bytes, and notices edits that preserve mtimes. Files
+// are hashed with XXH64 by ScanWorkers goroutines.
+func ContentHash(projectPath string) (string, error) {
+	files, err := sourceFiles(projectPath)
+	if err != nil {
+		return "", err
+	}
+	entries := make([]string, len(files))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(int(scanWorkers.Load()), max(len(files), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				data, err := os.ReadFile(files[i].path)
+				if err != nil {
+					// Unreadable files hash as absent rather than failing.
+					entries[i] = files[i].path + ":-"
+					continue
+				}
+				entries[i] = fmt.Sprintf("%s:%016x", files[i].path, xxhash.Sum64(data))
+			}
+		}()
+	}
+	for i := range files {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return hashEntries(entries), nil
+}
+
+type sourceFile struct {
+	path  string
+	mtime int64
+}
+
+// sourceFiles lists the project's source files, skipping hidden, vendored,
+// and virtualenv directories.
+func sourceFiles(projectPath string) ([]sourceFile, error) {
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []sourceFile
+	err = filepath.WalkDir(absPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip errors
+		}
+		name := d.Name()
+		// Skip hidden dirs, vendor, node_modules, __pycache__, .git
+		if d.IsDir() && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "__pycache__" || name == "venv") {
+			return filepath.SkipDir
+		}
+		if d.IsDir() {
+			return nil
+		}
+		// Only hash source files
+		ext := filepath.Ext(name)
+		switch ext {
+		case ".py", ".go", ".ts", ".js", ".rs", ".java", ".c", ".h", ".cpp", ".hpp":
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			files = append(files, sourceFile{path: path, mtime: info.ModTime().UnixNano()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func hashEntries(entries []string) string {
+	sort.Strings(entries)
+	h := sha256.New()
+	for _, e := range entries {
+		h.Write([]byte(e))
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -0,0 +1,351 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScan_Interverse(t *testing.T) {
+	// Test against actual Interverse structure
+	root := findDemarchRoot(t)
+	projects, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(projects) == 0 {
+		t.Fatal("expected at least one project")
+	}
+
+	// Verify known projects exist
+	found := make(map[string]bool)
+	for _, p := range projects {
+		found[p.Name] = true
+		if p.Path == "" {
+			t.Errorf("project %q has empty path", p.Name)
+		}
+		if p.Language == "" {
+			t.Errorf("project %q has empty language", p.Name)
+		}
+	}
+
+	// Interverse should have interlock, intermute, clavain, etc.
+	for _, name := range []string{"interlock", "clavain"} {
+		if !found[name] {
+			t.Errorf("expected project %q in scan results", name)
+		}
+	}
+}
+
+func TestScan_LanguageDetection(t *testing.T) {
+	root := findDemarchRoot(t)
+	projects, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	for _, p := range projects {
+		switch p.Name {
+		case "interlock":
+			if p.Language != "go" {
+				t.Errorf("interlock: expected go, got %s", p.Language)
+			}
+		case "intermute":
+			if p.Language != "go" {
+				t.Errorf("intermute: expected go, got %s", p.Language)
+			}
+		}
+	}
+}
+
+func TestScan_WorkerCounts(t *testing.T) {
+	root := t.TempDir()
+	for g := 0; g < 5; g++ {
+		for p := 0; p < 8; p++ {
+			dir := filepath.Join(root, fmt.Sprintf("group%d", g), fmt.Sprintf("proj%d", p))
+			writeFile(t, filepath.Join(dir, ".git", "HEAD"))
+			writeFile(t, filepath.Join(dir, "go.mod"))
+		}
+		writeFile(t, filepath.Join(root, fmt.Sprintf("group%d", g), "notgit", "README"))
+	}
+	t.Cleanup(func() { SetScanWorkers(0) })
+
+	SetScanWorkers(1)
+	serial, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(serial) != 40 {
+		t.Fatalf("serial scan found %d projects, want 40", len(serial))
+	}
+	SetScanWorkers(7)
+	parallel, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(serial, parallel) {
+		t.Error("parallel scan differs from serial scan")
+	}
+}
+
+func TestScan_NestedGroups(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		"core/api",
+		"platform/services/auth",
+		"platform/services/billing",
+		"platform/services/billing/plugins/nested", // inside a project: not searched
+		"platform/a/b/c/too-deep",
+		"tool",     // a top-level repository...
+		"tool/sub", // ...is still searched
+		"web/node_modules/pkg",
+	} {
+		writeFile(t, filepath.Join(root, dir, ".git", "HEAD"))
+	}
+	t.Cleanup(func() { SetMaxDepth(0) })
+
+	projects, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range projects {
+		got = append(got, p.Group+"|"+p.Name)
+	}
+	want := []string{"|tool", "core|api", "platform/services|auth", "platform/services|billing", "tool|sub"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("projects = %v, want %v", got, want)
+	}
+
+	SetMaxDepth(5)
+	if projects, _ := Scan(root); len(projects) != 6 {
+		t.Errorf("depth 5 found %d projects, want 6", len(projects))
+	}
+	SetMaxDepth(2)
+	if projects, _ := Scan(root); len(projects) != 3 {
+		t.Errorf("depth 2 found %d projects, want 3 (tool, api, sub)", len(projects))
+	}
+}
+
+func TestScan_OtherVCS(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "core", "gitproj", ".git", "HEAD"))
+	writeFile(t, filepath.Join(root, "core", "hgproj", ".hg", "branch"))
+	os.WriteFile(filepath.Join(root, "core", "hgproj", ".hg", "branch"), []byte("stable\n"), 0o644)
+	writeFile(t, filepath.Join(root, "core", "jjproj", ".jj", "repo", "store"))
+
+	projects, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, p := range projects {
+		got[p.Name] = p.VCS
+	}
+	want := map[string]string{"gitproj": "git", "hgproj": "hg", "jjproj": "jj"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("VCS = %v, want %v", got, want)
+	}
+	for _, p := range projects {
+		if p.Name == "hgproj" && p.GitBranch != "stable" {
+			t.Errorf("hg branch = %q, want stable", p.GitBranch)
+		}
+	}
+}
+
+func TestScan_SparseCheckout(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "core", "full", ".git", "HEAD"))
+	sparse := filepath.Join(root, "core", "sparse", ".git")
+	writeFile(t, filepath.Join(sparse, "HEAD"))
+	os.WriteFile(filepath.Join(sparse, "config"), []byte("[core]\n\tsparseCheckout = true\n"), 0o644)
+	os.MkdirAll(filepath.Join(sparse, "info"), 0o755)
+	os.WriteFile(filepath.Join(sparse, "info", "sparse-checkout"), []byte("/src/\n"), 0o644)
+
+	projects, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range projects {
+		switch {
+		case p.Name == "full" && p.Checkout != nil:
+			t.Errorf("full checkout = %+v, want nil", *p.Checkout)
+		case p.Name == "sparse" && (p.Checkout == nil || !p.Checkout.Sparse):
+			t.Errorf("sparse checkout = %+v, want sparse", p.Checkout)
+		}
+	}
+}
+
+func TestScanFingerprint(t *testing.T) {
+	root := t.TempDir()
+	group := filepath.Join(root, "core")
+	writeFile(t, filepath.Join(group, "api", ".git", "HEAD"))
+	// Backdate so the next change is visible on coarse-mtime filesystems.
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(group, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := ScanFingerprint(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := ScanFingerprint(root); again != before {
+		t.Error("fingerprint changed without a change")
+	}
+	writeFile(t, filepath.Join(group, "web", ".git", "HEAD"))
+	after, _ := ScanFingerprint(root)
+	if after == before {
+		t.Error("fingerprint did not change after adding a project")
+	}
+
+	nested := filepath.Join(root, "platform", "services")
+	writeFile(t, filepath.Join(nested, "auth", ".git", "HEAD"))
+	if err := os.Chtimes(nested, past, past); err != nil {
+		t.Fatal(err)
+	}
+	before, _ = ScanFingerprint(root)
+	writeFile(t, filepath.Join(nested, "billing", ".git", "HEAD"))
+	after, _ = ScanFingerprint(root)
+	if after == before {
+		t.Error("fingerprint did not change after adding a project to a nested group")
+	}
+
+	// Unchanged, the fingerprint stats what the last walk listed.
+	v, _ := scanListings.Load(root)
+	if got, want := v.(scanListing).listed, []string{".", "core", "platform", "platform/services"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("listed = %v, want %v", got, want)
+	}
+	scanListings.Delete(root)
+	if walked, _ := ScanFingerprint(root); walked != after {
+		t.Error("a fresh walk gave a different fingerprint")
+	}
+}
+
+func TestWithStats(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "core", "api", ".git", "HEAD"))
+	writeFile(t, filepath.Join(root, "core", "api", "main.go"))
+	writeFile(t, filepath.Join(root, "core", "api", "main_test.go"))
+
+	projects, err := Scan(root)
+	if err != nil || len(projects) != 1 {
+		t.Fatalf("Scan = %v, %v", projects, err)
+	}
+	withStats := WithStats(projects)
+	if projects[0].Stats != nil {
+		t.Error("WithStats should not modify its input")
+	}
+	s := withStats[0].Stats
+	if s == nil || s.Files != 2 || s.TestFiles != 1 || s.Extensions[".go"].Files != 2 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	root := findDemarchRoot(t)
+	interlockPath := filepath.Join(root, "interverse", "interlock")
+	if _, err := os.Stat(interlockPath); err != nil {
+		t.Skip("interlock directory not found")
+	}
+
+	p, err := Resolve(filepath.Join(interlockPath, "internal", "tools", "tools.go"))
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if p.Name != "interlock" {
+		t.Errorf("expected interlock, got %s", p.Name)
+	}
+	if p.Language != "go" {
+		t.Errorf("expected go, got %s", p.Language)
+	}
+}
+
+func TestResolve_NotInProject(t *testing.T) {
+	_, err := Resolve("/tmp")
+	if err == nil {
+		t.Error("expected error for path not in any project")
+	}
+}
+
+func TestMtimeHash(t *testing.T) {
+	root := findDemarchRoot(t)
+	interlockPath := filepath.Join(root, "interverse", "interlock")
+	if _, err := os.Stat(interlockPath); err != nil {
+		t.Skip("interlock directory not found")
+	}
+
+	hash1, err := MtimeHash(interlockPath)
+	if err != nil {
+		t.Fatalf("MtimeHash: %v", err)
+	}
+	if hash1 == "" {
+		t.Error("expected non-empty hash")
+	}
+
+	// Same path should give same hash
+	hash2, err := MtimeHash(interlockPath)
+	if err != nil {
+		t.Fatalf("MtimeHash second call: %v", err)
+	}
+	if hash1 != hash2 {
+		t.Error("expected identical hashes for unchanged directory")
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(src, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644)
+	hash := func() (string, string) {
+		t.Helper()
+		c, err := ContentHash(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := MtimeHash(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c, m
+	}
+	content1, mtime1 := hash()
+
+	// Touching the file moves the mtime hash but not the content hash.
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(src, later, later)
+	content2, mtime2 := hash()
+	if content2 != content1 || mtime2 == mtime1 {
+		t.Errorf("touch: content changed %v, mtime changed %v", content2 != content1, mtime2 != mtime1)
+	}
+
+	// An edit that restores the mtime is still seen by the content hash.
+	os.WriteFile(src, []byte("package main\n\nfunc main() {}\n"), 0o644)
+	os.Chtimes(src, later, later)
+	content3, mtime3 := hash()
+	if content3 == content2 || mtime3 != mtime2 {
+		t.Errorf("mtime-preserving edit: content changed %v, mtime changed %v", content3 != content2, mtime3 != mtime2)
+	}
+}
+
+func findDemarchRoot(t *testing.T) string {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Skipf("cannot get working directory: %v", err)
+	}
+	for dir != "/" {
+		if _, err := os.Stat(filepath.Join(dir, "interverse")); err == nil {
+			return dir
+		}
+		dir = filepath.Dir(dir)
+	}
+	t.Skip("not running inside Demarch monorepo")
+	return ""
+}